@@ -0,0 +1,13 @@
+package main
+
+import (
+	"log"
+
+	tool "github.com/sandeepkv93/everything-backend-starter-kit/internal/tools/keys"
+)
+
+func main() {
+	if err := tool.NewRootCommand().Execute(); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"net/mail"
+	"net/url"
 	"os"
 	"regexp"
 	"strconv"
@@ -13,7 +15,20 @@ import (
 	"time"
 )
 
-var redisNamespacePattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_-]*$`)
+var (
+	redisNamespacePattern  = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_-]*$`)
+	oauthProviderNameRegex = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+	emailLocalePattern     = regexp.MustCompile(`^[a-zA-Z]{2,8}(-[a-zA-Z0-9]{1,8})*$`)
+)
+
+type OIDCProviderConfig struct {
+	Name         string
+	DiscoveryURL string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+}
 
 type Config struct {
 	Env      string
@@ -27,6 +42,9 @@ type Config struct {
 	JWTRefreshSecret                  string
 	JWTAccessTTL                      time.Duration
 	JWTRefreshTTL                     time.Duration
+	JWTSigningAlg                     string
+	JWTSigningKeyEncryptionKey        string
+	JWTKeyringRefreshInterval         time.Duration
 	RefreshTokenPepper                string
 	StateSigningSecret                string
 	CookieDomain                      string
@@ -37,6 +55,17 @@ type Config struct {
 	GoogleClientSecret                string
 	GoogleRedirectURL                 string
 	AuthGoogleEnabled                 bool
+	GitHubClientID                    string
+	GitHubClientSecret                string
+	GitHubRedirectURL                 string
+	AuthGitHubEnabled                 bool
+	MicrosoftClientID                 string
+	MicrosoftClientSecret             string
+	MicrosoftRedirectURL              string
+	MicrosoftTenant                   string
+	AuthMicrosoftEnabled              bool
+	OIDCProviders                     []OIDCProviderConfig
+	AuthOAuthAutoLinkByEmail          bool
 	AuthLocalEnabled                  bool
 	AuthLocalRequireEmailVerification bool
 	AuthEmailVerifyTokenTTL           time.Duration
@@ -44,6 +73,63 @@ type Config struct {
 	AuthPasswordResetTokenTTL         time.Duration
 	AuthPasswordResetBaseURL          string
 	AuthPasswordForgotRateLimitPerMin int
+	PasswordMinLength                 int
+	PasswordMaxLength                 int
+	PasswordRequiredClasses           []string
+	PasswordRejectPersonalInfo        bool
+	PasswordHistoryCount              int
+	PasswordMaxAge                    time.Duration
+	PasswordBreachedListFile          string
+	AuthMagicLinkEnabled              bool
+	AuthMagicLinkTokenTTL             time.Duration
+	AuthMagicLinkBaseURL              string
+	AuthEmailChangeBaseURL            string
+	AuthAccountDeletionGracePeriod    time.Duration
+	AuthAccountDeletionSweepInterval  time.Duration
+	EmailNotifier                     string
+	EmailTemplateDir                  string
+	EmailDefaultLocale                string
+	SMTPHost                          string
+	SMTPPort                          int
+	SMTPUsername                      string
+	SMTPPassword                      string
+	SMTPFrom                          string
+	SMTPTLSMode                       string
+	SMTPTLSInsecureSkipVerify         bool
+	SMTPDialTimeout                   time.Duration
+	SMTPSendTimeout                   time.Duration
+	NotificationOutboxEnabled         bool
+	NotificationOutboxPollInterval    time.Duration
+	NotificationOutboxBatchSize       int
+	NotificationOutboxMaxAttempts     int
+	NotificationOutboxBaseBackoff     time.Duration
+	NotificationOutboxMaxBackoff      time.Duration
+	NotificationOutboxLease           time.Duration
+	NotificationOutboxRetention       time.Duration
+	AuthMFAEncryptionKey              string
+	NotificationOutboxEncryptionKey   string
+	AuthMFAIssuer                     string
+	AuthMFAChallengeTTL               time.Duration
+	AuthMFARequireForAdmin            bool
+	AuthWebAuthnEnabled               bool
+	AuthWebAuthnRPID                  string
+	AuthWebAuthnRPDisplayName         string
+	AuthWebAuthnRPOrigins             []string
+	AuthWebAuthnChallengeTTL          time.Duration
+	AuthWebAuthnRedisPrefix           string
+	AuthAccessRevocationRedisEnabled  bool
+	AuthAccessRevocationRedisPrefix   string
+	AuthAPIKeysEnabled                bool
+	AuthAPIKeyMaxPerUser              int
+	AuthServiceAccountsEnabled        bool
+	AuthServiceAccountTokenTTL        time.Duration
+	AuthImpersonationEnabled          bool
+	AuthImpersonationTTL              time.Duration
+	AuthReauthMaxAge                  time.Duration
+	OrganizationsEnabled              bool
+	AccessPoliciesEnabled             bool
+	AccessPolicyCacheTTL              time.Duration
+	AccessPolicyTrustedProxyCIDRs     []string
 	RBACProtectedRoles                []string
 	RBACProtectedPermissions          []string
 	BootstrapAdminEmail               string
@@ -83,6 +169,7 @@ type Config struct {
 	RBACPermissionCacheEnabled   bool
 	RBACPermissionCacheTTL       time.Duration
 	RBACPermissionCacheRedisPref string
+	RBACRoleExpirySweepInterval  time.Duration
 	RateLimitRedisEnabled        bool
 	IdempotencyEnabled           bool
 	IdempotencyRedisEnabled      bool
@@ -156,6 +243,8 @@ func Load() (cfg *Config, err error) {
 		JWTAudience:                       getEnv("JWT_AUDIENCE", "everything-backend-starter-kit-api"),
 		JWTAccessSecret:                   os.Getenv("JWT_ACCESS_SECRET"),
 		JWTRefreshSecret:                  os.Getenv("JWT_REFRESH_SECRET"),
+		JWTSigningAlg:                     strings.TrimSpace(getEnv("JWT_SIGNING_ALG", "HS256")),
+		JWTSigningKeyEncryptionKey:        os.Getenv("JWT_SIGNING_KEY_ENCRYPTION_KEY"),
 		RefreshTokenPepper:                os.Getenv("REFRESH_TOKEN_PEPPER"),
 		StateSigningSecret:                os.Getenv("OAUTH_STATE_SECRET"),
 		CookieDomain:                      os.Getenv("COOKIE_DOMAIN"),
@@ -166,13 +255,64 @@ func Load() (cfg *Config, err error) {
 		GoogleClientSecret:                googleClientSecret,
 		GoogleRedirectURL:                 getEnv("GOOGLE_OAUTH_REDIRECT_URL", "http://localhost:8080/api/v1/auth/google/callback"),
 		AuthGoogleEnabled:                 googleEnabled,
+		GitHubClientID:                    os.Getenv("GITHUB_OAUTH_CLIENT_ID"),
+		GitHubClientSecret:                os.Getenv("GITHUB_OAUTH_CLIENT_SECRET"),
+		GitHubRedirectURL:                 getEnv("GITHUB_OAUTH_REDIRECT_URL", "http://localhost:8080/api/v1/auth/github/callback"),
+		AuthGitHubEnabled:                 getEnvBool("AUTH_GITHUB_ENABLED", false),
+		MicrosoftClientID:                 os.Getenv("MICROSOFT_OAUTH_CLIENT_ID"),
+		MicrosoftClientSecret:             os.Getenv("MICROSOFT_OAUTH_CLIENT_SECRET"),
+		MicrosoftRedirectURL:              getEnv("MICROSOFT_OAUTH_REDIRECT_URL", "http://localhost:8080/api/v1/auth/microsoft/callback"),
+		MicrosoftTenant:                   strings.TrimSpace(getEnv("MICROSOFT_OAUTH_TENANT", "common")),
+		AuthMicrosoftEnabled:              getEnvBool("AUTH_MICROSOFT_ENABLED", false),
+		OIDCProviders:                     loadOIDCProviders(splitCSV(getEnv("AUTH_OIDC_PROVIDERS", ""))),
+		AuthOAuthAutoLinkByEmail:          getEnvBool("AUTH_OAUTH_AUTO_LINK_BY_EMAIL", true),
 		AuthLocalEnabled:                  getEnvBool("AUTH_LOCAL_ENABLED", true),
 		AuthLocalRequireEmailVerification: getEnvBool("AUTH_LOCAL_REQUIRE_EMAIL_VERIFICATION", false),
 		AuthEmailVerifyBaseURL:            strings.TrimSpace(os.Getenv("AUTH_EMAIL_VERIFY_BASE_URL")),
 		AuthPasswordResetBaseURL:          strings.TrimSpace(os.Getenv("AUTH_PASSWORD_RESET_BASE_URL")),
 		AuthPasswordForgotRateLimitPerMin: getEnvInt("AUTH_PASSWORD_FORGOT_RATE_LIMIT_PER_MIN", 5),
+		PasswordMinLength:                 getEnvInt("PASSWORD_MIN_LENGTH", 12),
+		PasswordMaxLength:                 getEnvInt("PASSWORD_MAX_LENGTH", 128),
+		PasswordRequiredClasses:           splitCSV(strings.ToLower(getEnv("PASSWORD_REQUIRED_CLASSES", "upper,lower,digit,symbol"))),
+		PasswordRejectPersonalInfo:        getEnvBool("PASSWORD_REJECT_PERSONAL_INFO", true),
+		PasswordHistoryCount:              getEnvInt("PASSWORD_HISTORY_COUNT", 5),
+		PasswordBreachedListFile:          strings.TrimSpace(os.Getenv("PASSWORD_BREACHED_LIST_FILE")),
+		AuthMagicLinkEnabled:              getEnvBool("AUTH_MAGIC_LINK_ENABLED", false),
+		AuthMagicLinkBaseURL:              strings.TrimSpace(os.Getenv("AUTH_MAGIC_LINK_BASE_URL")),
+		AuthEmailChangeBaseURL:            strings.TrimSpace(os.Getenv("AUTH_EMAIL_CHANGE_BASE_URL")),
+		AuthMFAEncryptionKey:              os.Getenv("AUTH_MFA_ENCRYPTION_KEY"),
+		NotificationOutboxEncryptionKey:   os.Getenv("NOTIFICATION_OUTBOX_ENCRYPTION_KEY"),
+		EmailNotifier:                     strings.ToLower(strings.TrimSpace(getEnv("EMAIL_NOTIFIER", "dev"))),
+		EmailTemplateDir:                  strings.TrimSpace(os.Getenv("EMAIL_TEMPLATE_DIR")),
+		EmailDefaultLocale:                strings.TrimSpace(getEnv("EMAIL_DEFAULT_LOCALE", "en")),
+		SMTPHost:                          strings.TrimSpace(os.Getenv("SMTP_HOST")),
+		SMTPPort:                          getEnvInt("SMTP_PORT", 587),
+		SMTPUsername:                      strings.TrimSpace(os.Getenv("SMTP_USERNAME")),
+		SMTPPassword:                      os.Getenv("SMTP_PASSWORD"),
+		SMTPFrom:                          strings.TrimSpace(os.Getenv("SMTP_FROM")),
+		SMTPTLSMode:                       strings.ToLower(strings.TrimSpace(getEnv("SMTP_TLS_MODE", "starttls"))),
+		SMTPTLSInsecureSkipVerify:         getEnvBool("SMTP_TLS_INSECURE_SKIP_VERIFY", false),
+		NotificationOutboxEnabled:         getEnvBool("NOTIFICATION_OUTBOX_ENABLED", true),
+		NotificationOutboxBatchSize:       getEnvInt("NOTIFICATION_OUTBOX_BATCH_SIZE", 50),
+		NotificationOutboxMaxAttempts:     getEnvInt("NOTIFICATION_OUTBOX_MAX_ATTEMPTS", 8),
+		AuthMFAIssuer:                     strings.TrimSpace(getEnv("AUTH_MFA_ISSUER", "everything-backend-starter-kit")),
+		AuthMFARequireForAdmin:            getEnvBool("AUTH_MFA_REQUIRE_FOR_ADMIN", !isLocalLikeEnv(env)),
+		AuthWebAuthnEnabled:               getEnvBool("AUTH_WEBAUTHN_ENABLED", false),
+		AuthWebAuthnRPID:                  strings.TrimSpace(getEnv("AUTH_WEBAUTHN_RP_ID", "localhost")),
+		AuthWebAuthnRPDisplayName:         strings.TrimSpace(getEnv("AUTH_WEBAUTHN_RP_DISPLAY_NAME", "Everything Backend Starter Kit")),
+		AuthWebAuthnRPOrigins:             splitCSV(getEnv("AUTH_WEBAUTHN_RP_ORIGINS", "http://localhost:3000")),
+		AuthWebAuthnRedisPrefix:           getEnv("AUTH_WEBAUTHN_REDIS_PREFIX", "webauthn"),
+		AuthAccessRevocationRedisEnabled:  getEnvBool("AUTH_ACCESS_REVOCATION_REDIS_ENABLED", false),
+		AuthAccessRevocationRedisPrefix:   getEnv("AUTH_ACCESS_REVOCATION_REDIS_PREFIX", "access_revoked"),
+		AuthAPIKeysEnabled:                getEnvBool("AUTH_API_KEYS_ENABLED", true),
+		AuthAPIKeyMaxPerUser:              getEnvInt("AUTH_API_KEY_MAX_PER_USER", 10),
+		AuthServiceAccountsEnabled:        getEnvBool("AUTH_SERVICE_ACCOUNTS_ENABLED", true),
+		AuthImpersonationEnabled:          getEnvBool("AUTH_IMPERSONATION_ENABLED", true),
+		OrganizationsEnabled:              getEnvBool("ORGANIZATIONS_ENABLED", true),
+		AccessPoliciesEnabled:             getEnvBool("ACCESS_POLICIES_ENABLED", true),
+		AccessPolicyTrustedProxyCIDRs:     splitCSV(getEnv("ACCESS_POLICY_TRUSTED_PROXY_CIDRS", "")),
 		RBACProtectedRoles:                splitCSV(getEnv("RBAC_PROTECTED_ROLES", "admin,user")),
-		RBACProtectedPermissions:          splitCSV(getEnv("RBAC_PROTECTED_PERMISSIONS", "users:read,users:write,roles:read,roles:write,permissions:read,permissions:write")),
+		RBACProtectedPermissions:          splitCSV(getEnv("RBAC_PROTECTED_PERMISSIONS", "users:read,users:write,users:impersonate,roles:read,roles:write,permissions:read,permissions:write,orgs:read,orgs:write,policies:read,policies:write")),
 		BootstrapAdminEmail:               strings.TrimSpace(strings.ToLower(os.Getenv("BOOTSTRAP_ADMIN_EMAIL"))),
 		AuthRateLimitPerMin:               getEnvInt("AUTH_RATE_LIMIT_PER_MIN", 30),
 		APIRateLimitPerMin:                getEnvInt("API_RATE_LIMIT_PER_MIN", 120),
@@ -245,6 +385,12 @@ func Load() (cfg *Config, err error) {
 	}
 	cfg.JWTRefreshTTL = refreshTTL
 
+	keyringRefresh, err := time.ParseDuration(getEnv("JWT_KEYRING_REFRESH_INTERVAL", "1m"))
+	if err != nil {
+		return nil, fmt.Errorf("parse JWT_KEYRING_REFRESH_INTERVAL: %w", err)
+	}
+	cfg.JWTKeyringRefreshInterval = keyringRefresh
+
 	verifyTTL, err := time.ParseDuration(getEnv("AUTH_EMAIL_VERIFY_TOKEN_TTL", "30m"))
 	if err != nil {
 		return nil, fmt.Errorf("parse AUTH_EMAIL_VERIFY_TOKEN_TTL: %w", err)
@@ -257,6 +403,108 @@ func Load() (cfg *Config, err error) {
 	}
 	cfg.AuthPasswordResetTokenTTL = resetTTL
 
+	magicLinkTTL, err := time.ParseDuration(getEnv("AUTH_MAGIC_LINK_TOKEN_TTL", "15m"))
+	if err != nil {
+		return nil, fmt.Errorf("parse AUTH_MAGIC_LINK_TOKEN_TTL: %w", err)
+	}
+	cfg.AuthMagicLinkTokenTTL = magicLinkTTL
+
+	deletionGrace, err := time.ParseDuration(getEnv("AUTH_ACCOUNT_DELETION_GRACE_PERIOD", "168h"))
+	if err != nil {
+		return nil, fmt.Errorf("parse AUTH_ACCOUNT_DELETION_GRACE_PERIOD: %w", err)
+	}
+	cfg.AuthAccountDeletionGracePeriod = deletionGrace
+
+	deletionSweepInterval, err := time.ParseDuration(getEnv("AUTH_ACCOUNT_DELETION_SWEEP_INTERVAL", "1h"))
+	if err != nil {
+		return nil, fmt.Errorf("parse AUTH_ACCOUNT_DELETION_SWEEP_INTERVAL: %w", err)
+	}
+	cfg.AuthAccountDeletionSweepInterval = deletionSweepInterval
+
+	smtpDialTimeout, err := time.ParseDuration(getEnv("SMTP_DIAL_TIMEOUT", "5s"))
+	if err != nil {
+		return nil, fmt.Errorf("parse SMTP_DIAL_TIMEOUT: %w", err)
+	}
+	cfg.SMTPDialTimeout = smtpDialTimeout
+
+	smtpSendTimeout, err := time.ParseDuration(getEnv("SMTP_SEND_TIMEOUT", "15s"))
+	if err != nil {
+		return nil, fmt.Errorf("parse SMTP_SEND_TIMEOUT: %w", err)
+	}
+	cfg.SMTPSendTimeout = smtpSendTimeout
+
+	passwordMaxAge, err := time.ParseDuration(getEnv("PASSWORD_MAX_AGE", "0s"))
+	if err != nil {
+		return nil, fmt.Errorf("parse PASSWORD_MAX_AGE: %w", err)
+	}
+	cfg.PasswordMaxAge = passwordMaxAge
+
+	outboxPollInterval, err := time.ParseDuration(getEnv("NOTIFICATION_OUTBOX_POLL_INTERVAL", "5s"))
+	if err != nil {
+		return nil, fmt.Errorf("parse NOTIFICATION_OUTBOX_POLL_INTERVAL: %w", err)
+	}
+	cfg.NotificationOutboxPollInterval = outboxPollInterval
+
+	outboxBaseBackoff, err := time.ParseDuration(getEnv("NOTIFICATION_OUTBOX_BASE_BACKOFF", "30s"))
+	if err != nil {
+		return nil, fmt.Errorf("parse NOTIFICATION_OUTBOX_BASE_BACKOFF: %w", err)
+	}
+	cfg.NotificationOutboxBaseBackoff = outboxBaseBackoff
+
+	outboxMaxBackoff, err := time.ParseDuration(getEnv("NOTIFICATION_OUTBOX_MAX_BACKOFF", "1h"))
+	if err != nil {
+		return nil, fmt.Errorf("parse NOTIFICATION_OUTBOX_MAX_BACKOFF: %w", err)
+	}
+	cfg.NotificationOutboxMaxBackoff = outboxMaxBackoff
+
+	outboxLease, err := time.ParseDuration(getEnv("NOTIFICATION_OUTBOX_LEASE", "1m"))
+	if err != nil {
+		return nil, fmt.Errorf("parse NOTIFICATION_OUTBOX_LEASE: %w", err)
+	}
+	cfg.NotificationOutboxLease = outboxLease
+
+	outboxRetention, err := time.ParseDuration(getEnv("NOTIFICATION_OUTBOX_RETENTION", "168h"))
+	if err != nil {
+		return nil, fmt.Errorf("parse NOTIFICATION_OUTBOX_RETENTION: %w", err)
+	}
+	cfg.NotificationOutboxRetention = outboxRetention
+
+	mfaChallengeTTL, err := time.ParseDuration(getEnv("AUTH_MFA_CHALLENGE_TTL", "5m"))
+	if err != nil {
+		return nil, fmt.Errorf("parse AUTH_MFA_CHALLENGE_TTL: %w", err)
+	}
+	cfg.AuthMFAChallengeTTL = mfaChallengeTTL
+
+	webauthnChallengeTTL, err := time.ParseDuration(getEnv("AUTH_WEBAUTHN_CHALLENGE_TTL", "5m"))
+	if err != nil {
+		return nil, fmt.Errorf("parse AUTH_WEBAUTHN_CHALLENGE_TTL: %w", err)
+	}
+	cfg.AuthWebAuthnChallengeTTL = webauthnChallengeTTL
+
+	serviceAccountTokenTTL, err := time.ParseDuration(getEnv("AUTH_SERVICE_ACCOUNT_TOKEN_TTL", "15m"))
+	if err != nil {
+		return nil, fmt.Errorf("parse AUTH_SERVICE_ACCOUNT_TOKEN_TTL: %w", err)
+	}
+	cfg.AuthServiceAccountTokenTTL = serviceAccountTokenTTL
+
+	impersonationTTL, err := time.ParseDuration(getEnv("AUTH_IMPERSONATION_TTL", "15m"))
+	if err != nil {
+		return nil, fmt.Errorf("parse AUTH_IMPERSONATION_TTL: %w", err)
+	}
+	cfg.AuthImpersonationTTL = impersonationTTL
+
+	reauthMaxAge, err := time.ParseDuration(getEnv("AUTH_REAUTH_MAX_AGE", "15m"))
+	if err != nil {
+		return nil, fmt.Errorf("parse AUTH_REAUTH_MAX_AGE: %w", err)
+	}
+	cfg.AuthReauthMaxAge = reauthMaxAge
+
+	accessPolicyCacheTTL, err := time.ParseDuration(getEnv("ACCESS_POLICY_CACHE_TTL", "30s"))
+	if err != nil {
+		return nil, fmt.Errorf("parse ACCESS_POLICY_CACHE_TTL: %w", err)
+	}
+	cfg.AccessPolicyCacheTTL = accessPolicyCacheTTL
+
 	metricsInterval, err := time.ParseDuration(getEnv("OTEL_METRICS_EXPORT_INTERVAL", "10s"))
 	if err != nil {
 		return nil, fmt.Errorf("parse OTEL_METRICS_EXPORT_INTERVAL: %w", err)
@@ -299,6 +547,12 @@ func Load() (cfg *Config, err error) {
 	}
 	cfg.RBACPermissionCacheTTL = rbacPermissionCacheTTL
 
+	roleExpirySweepInterval, err := time.ParseDuration(getEnv("RBAC_ROLE_EXPIRY_SWEEP_INTERVAL", "1m"))
+	if err != nil {
+		return nil, fmt.Errorf("parse RBAC_ROLE_EXPIRY_SWEEP_INTERVAL: %w", err)
+	}
+	cfg.RBACRoleExpirySweepInterval = roleExpirySweepInterval
+
 	rateLimitSustainedWindow, err := time.ParseDuration(getEnv("RATE_LIMIT_SUSTAINED_WINDOW", "1m"))
 	if err != nil {
 		return nil, fmt.Errorf("parse RATE_LIMIT_SUSTAINED_WINDOW: %w", err)
@@ -409,7 +663,7 @@ func (c *Config) Validate() error {
 	if len(c.StateSigningSecret) < 16 {
 		errs = append(errs, "OAUTH_STATE_SECRET must be at least 16 chars")
 	}
-	if !c.AuthLocalEnabled && !c.AuthGoogleEnabled {
+	if !c.AuthLocalEnabled && !c.AuthGoogleEnabled && !c.AuthGitHubEnabled && !c.AuthMicrosoftEnabled && len(c.OIDCProviders) == 0 {
 		errs = append(errs, "at least one auth provider must be enabled")
 	}
 	if c.AuthGoogleEnabled && c.GoogleClientID == "" {
@@ -418,6 +672,40 @@ func (c *Config) Validate() error {
 	if c.AuthGoogleEnabled && c.GoogleClientSecret == "" {
 		errs = append(errs, "GOOGLE_OAUTH_CLIENT_SECRET is required when AUTH_GOOGLE_ENABLED=true")
 	}
+	if c.AuthGitHubEnabled && (c.GitHubClientID == "" || c.GitHubClientSecret == "") {
+		errs = append(errs, "GITHUB_OAUTH_CLIENT_ID and GITHUB_OAUTH_CLIENT_SECRET are required when AUTH_GITHUB_ENABLED=true")
+	}
+	if c.AuthMicrosoftEnabled && (c.MicrosoftClientID == "" || c.MicrosoftClientSecret == "") {
+		errs = append(errs, "MICROSOFT_OAUTH_CLIENT_ID and MICROSOFT_OAUTH_CLIENT_SECRET are required when AUTH_MICROSOFT_ENABLED=true")
+	}
+	if c.AuthMicrosoftEnabled && c.MicrosoftTenant == "" {
+		errs = append(errs, "MICROSOFT_OAUTH_TENANT must not be empty when AUTH_MICROSOFT_ENABLED=true")
+	}
+	seenOIDC := map[string]struct{}{}
+	for _, p := range c.OIDCProviders {
+		prefix := oidcEnvPrefix(p.Name)
+		switch {
+		case !oauthProviderNameRegex.MatchString(p.Name):
+			errs = append(errs, fmt.Sprintf("AUTH_OIDC_PROVIDERS entry %q must match ^[a-z0-9][a-z0-9_-]*$", p.Name))
+			continue
+		case isReservedOAuthProviderName(p.Name):
+			errs = append(errs, fmt.Sprintf("AUTH_OIDC_PROVIDERS entry %q is reserved", p.Name))
+			continue
+		}
+		if _, dup := seenOIDC[p.Name]; dup {
+			errs = append(errs, fmt.Sprintf("AUTH_OIDC_PROVIDERS entry %q is duplicated", p.Name))
+			continue
+		}
+		seenOIDC[p.Name] = struct{}{}
+		if p.DiscoveryURL == "" {
+			errs = append(errs, prefix+"DISCOVERY_URL is required")
+		} else if c.isProdLike() && !strings.HasPrefix(strings.ToLower(p.DiscoveryURL), "https://") {
+			errs = append(errs, prefix+"DISCOVERY_URL must use https in production/staging")
+		}
+		if p.ClientID == "" || p.ClientSecret == "" {
+			errs = append(errs, prefix+"CLIENT_ID and "+prefix+"CLIENT_SECRET are required")
+		}
+	}
 	if c.AuthLocalRequireEmailVerification && !c.AuthLocalEnabled {
 		errs = append(errs, "AUTH_LOCAL_REQUIRE_EMAIL_VERIFICATION requires AUTH_LOCAL_ENABLED=true")
 	}
@@ -427,9 +715,158 @@ func (c *Config) Validate() error {
 	if c.AuthPasswordResetTokenTTL <= 0 || c.AuthPasswordResetTokenTTL > (24*time.Hour) {
 		errs = append(errs, "AUTH_PASSWORD_RESET_TOKEN_TTL must be between 1s and 24h")
 	}
+	if c.AuthMagicLinkEnabled && (c.AuthMagicLinkTokenTTL <= 0 || c.AuthMagicLinkTokenTTL > time.Hour) {
+		errs = append(errs, "AUTH_MAGIC_LINK_TOKEN_TTL must be between 1s and 1h")
+	}
+	if c.AuthAccountDeletionGracePeriod < 0 || c.AuthAccountDeletionGracePeriod > 90*24*time.Hour {
+		errs = append(errs, "AUTH_ACCOUNT_DELETION_GRACE_PERIOD must be between 0 and 2160h")
+	}
+	if c.AuthAccountDeletionSweepInterval < 0 || c.AuthAccountDeletionSweepInterval > 24*time.Hour {
+		errs = append(errs, "AUTH_ACCOUNT_DELETION_SWEEP_INTERVAL must be between 0 and 24h")
+	}
+	switch c.EmailNotifier {
+	case "", "dev":
+	case "smtp":
+		if c.SMTPHost == "" {
+			errs = append(errs, "SMTP_HOST is required when EMAIL_NOTIFIER=smtp")
+		}
+		if c.SMTPPort < 1 || c.SMTPPort > 65535 {
+			errs = append(errs, "SMTP_PORT must be between 1 and 65535")
+		}
+		if _, err := mail.ParseAddress(c.SMTPFrom); err != nil {
+			errs = append(errs, "SMTP_FROM must be a valid address when EMAIL_NOTIFIER=smtp")
+		}
+		if (c.SMTPUsername == "") != (c.SMTPPassword == "") {
+			errs = append(errs, "SMTP_USERNAME and SMTP_PASSWORD must be set together")
+		}
+		switch c.SMTPTLSMode {
+		case "none", "starttls", "tls":
+		default:
+			errs = append(errs, "SMTP_TLS_MODE must be one of none, starttls, tls")
+		}
+		if c.SMTPTLSMode == "none" && c.SMTPTLSInsecureSkipVerify {
+			errs = append(errs, "SMTP_TLS_INSECURE_SKIP_VERIFY requires SMTP_TLS_MODE=starttls or tls")
+		}
+		if c.SMTPDialTimeout < (100*time.Millisecond) || c.SMTPDialTimeout > time.Minute {
+			errs = append(errs, "SMTP_DIAL_TIMEOUT must be between 100ms and 1m")
+		}
+		if c.SMTPSendTimeout < time.Second || c.SMTPSendTimeout > (5*time.Minute) {
+			errs = append(errs, "SMTP_SEND_TIMEOUT must be between 1s and 5m")
+		}
+	default:
+		errs = append(errs, "EMAIL_NOTIFIER must be dev or smtp")
+	}
+	if c.NotificationOutboxEnabled {
+		if c.NotificationOutboxPollInterval < (100*time.Millisecond) || c.NotificationOutboxPollInterval > time.Hour {
+			errs = append(errs, "NOTIFICATION_OUTBOX_POLL_INTERVAL must be between 100ms and 1h")
+		}
+		if c.NotificationOutboxBatchSize < 1 || c.NotificationOutboxBatchSize > 1000 {
+			errs = append(errs, "NOTIFICATION_OUTBOX_BATCH_SIZE must be between 1 and 1000")
+		}
+		if c.NotificationOutboxMaxAttempts < 1 || c.NotificationOutboxMaxAttempts > 50 {
+			errs = append(errs, "NOTIFICATION_OUTBOX_MAX_ATTEMPTS must be between 1 and 50")
+		}
+		if c.NotificationOutboxBaseBackoff < time.Second || c.NotificationOutboxBaseBackoff > c.NotificationOutboxMaxBackoff {
+			errs = append(errs, "NOTIFICATION_OUTBOX_BASE_BACKOFF must be at least 1s and not exceed NOTIFICATION_OUTBOX_MAX_BACKOFF")
+		}
+		if c.NotificationOutboxMaxBackoff > 24*time.Hour {
+			errs = append(errs, "NOTIFICATION_OUTBOX_MAX_BACKOFF must be at most 24h")
+		}
+		// A lease shorter than one send would let another replica reclaim a
+		// message that is still being delivered.
+		if c.NotificationOutboxLease < time.Second || c.NotificationOutboxLease <= c.SMTPSendTimeout+c.SMTPDialTimeout || c.NotificationOutboxLease > time.Hour {
+			errs = append(errs, "NOTIFICATION_OUTBOX_LEASE must exceed SMTP_DIAL_TIMEOUT+SMTP_SEND_TIMEOUT and be at most 1h")
+		}
+		if c.NotificationOutboxRetention < time.Hour {
+			errs = append(errs, "NOTIFICATION_OUTBOX_RETENTION must be at least 1h")
+		}
+		if len(c.NotificationOutboxEncryptionKey) < 32 {
+			errs = append(errs, "NOTIFICATION_OUTBOX_ENCRYPTION_KEY must be at least 32 chars when NOTIFICATION_OUTBOX_ENABLED=true")
+		}
+	}
+	if c.EmailTemplateDir != "" {
+		if info, err := os.Stat(c.EmailTemplateDir); err != nil || !info.IsDir() {
+			errs = append(errs, "EMAIL_TEMPLATE_DIR must be a readable directory")
+		}
+	}
+	if c.EmailDefaultLocale != "" && !emailLocalePattern.MatchString(c.EmailDefaultLocale) {
+		errs = append(errs, "EMAIL_DEFAULT_LOCALE must be a language tag such as en or pt-BR")
+	}
+	if c.PasswordMinLength != 0 && (c.PasswordMinLength < 8 || c.PasswordMinLength > 128) {
+		errs = append(errs, "PASSWORD_MIN_LENGTH must be between 8 and 128")
+	}
+	if c.PasswordMaxLength != 0 && (c.PasswordMaxLength < c.PasswordMinLength || c.PasswordMaxLength > 1024) {
+		errs = append(errs, "PASSWORD_MAX_LENGTH must be between PASSWORD_MIN_LENGTH and 1024")
+	}
+	for _, class := range c.PasswordRequiredClasses {
+		switch class {
+		case "upper", "lower", "digit", "symbol", "none":
+		default:
+			errs = append(errs, "PASSWORD_REQUIRED_CLASSES must list upper, lower, digit, symbol, or be none")
+		}
+	}
+	if c.PasswordHistoryCount < 0 || c.PasswordHistoryCount > 24 {
+		errs = append(errs, "PASSWORD_HISTORY_COUNT must be between 0 and 24")
+	}
+	if c.PasswordMaxAge != 0 && (c.PasswordMaxAge < 24*time.Hour || c.PasswordMaxAge > 5*365*24*time.Hour) {
+		errs = append(errs, "PASSWORD_MAX_AGE must be 0 (disabled) or between 24h and 43800h")
+	}
+	if c.PasswordBreachedListFile != "" {
+		if info, err := os.Stat(c.PasswordBreachedListFile); err != nil || info.IsDir() {
+			errs = append(errs, "PASSWORD_BREACHED_LIST_FILE must be a readable file")
+		}
+	}
 	if c.AuthPasswordForgotRateLimitPerMin <= 0 {
 		errs = append(errs, "AUTH_PASSWORD_FORGOT_RATE_LIMIT_PER_MIN must be > 0")
 	}
+	if c.AuthMFAEncryptionKey != "" && len(c.AuthMFAEncryptionKey) < 32 {
+		errs = append(errs, "AUTH_MFA_ENCRYPTION_KEY must be at least 32 chars")
+	}
+	if c.AuthMFARequireForAdmin && c.AuthMFAEncryptionKey == "" {
+		errs = append(errs, "AUTH_MFA_REQUIRE_FOR_ADMIN requires AUTH_MFA_ENCRYPTION_KEY")
+	}
+	if c.AuthMFAChallengeTTL <= 0 || c.AuthMFAChallengeTTL > (15*time.Minute) {
+		errs = append(errs, "AUTH_MFA_CHALLENGE_TTL must be between 1s and 15m")
+	}
+	if c.AuthWebAuthnEnabled {
+		if c.AuthWebAuthnRPID == "" {
+			errs = append(errs, "AUTH_WEBAUTHN_RP_ID is required when AUTH_WEBAUTHN_ENABLED=true")
+		}
+		if len(c.AuthWebAuthnRPOrigins) == 0 {
+			errs = append(errs, "AUTH_WEBAUTHN_RP_ORIGINS is required when AUTH_WEBAUTHN_ENABLED=true")
+		}
+		for _, origin := range c.AuthWebAuthnRPOrigins {
+			u, err := url.Parse(origin)
+			if err != nil || u.Scheme == "" || u.Host == "" {
+				errs = append(errs, "AUTH_WEBAUTHN_RP_ORIGINS entries must be absolute origins")
+				break
+			}
+		}
+		if c.AuthWebAuthnChallengeTTL <= 0 || c.AuthWebAuthnChallengeTTL > (10*time.Minute) {
+			errs = append(errs, "AUTH_WEBAUTHN_CHALLENGE_TTL must be between 1s and 10m")
+		}
+	}
+	if c.AuthAPIKeysEnabled && (c.AuthAPIKeyMaxPerUser < 1 || c.AuthAPIKeyMaxPerUser > 100) {
+		errs = append(errs, "AUTH_API_KEY_MAX_PER_USER must be between 1 and 100")
+	}
+	if c.AuthServiceAccountsEnabled && (c.AuthServiceAccountTokenTTL <= 0 || c.AuthServiceAccountTokenTTL > time.Hour) {
+		errs = append(errs, "AUTH_SERVICE_ACCOUNT_TOKEN_TTL must be between 1s and 1h")
+	}
+	if c.AuthImpersonationEnabled && (c.AuthImpersonationTTL <= 0 || c.AuthImpersonationTTL > c.JWTAccessTTL) {
+		errs = append(errs, "AUTH_IMPERSONATION_TTL must be between 1s and JWT_ACCESS_TTL")
+	}
+	if c.AuthReauthMaxAge < 0 || c.AuthReauthMaxAge > 24*time.Hour {
+		errs = append(errs, "AUTH_REAUTH_MAX_AGE must be between 0 (disabled) and 24h")
+	}
+	if c.AccessPolicyCacheTTL < 0 || c.AccessPolicyCacheTTL > 10*time.Minute {
+		errs = append(errs, "ACCESS_POLICY_CACHE_TTL must be between 0 (no caching) and 10m")
+	}
+	for _, cidr := range c.AccessPolicyTrustedProxyCIDRs {
+		if _, _, err := net.ParseCIDR(strings.TrimSpace(cidr)); err != nil {
+			errs = append(errs, "ACCESS_POLICY_TRUSTED_PROXY_CIDRS must contain valid CIDR values")
+			break
+		}
+	}
 	for _, token := range c.RBACProtectedPermissions {
 		parts := strings.SplitN(strings.TrimSpace(token), ":", 2)
 		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" || strings.TrimSpace(parts[1]) == "" {
@@ -443,6 +880,18 @@ func (c *Config) Validate() error {
 	if c.JWTRefreshTTL <= 0 || c.JWTRefreshTTL > (30*24*time.Hour) {
 		errs = append(errs, "JWT_REFRESH_TTL must be between 1s and 30d")
 	}
+	switch c.JWTSigningAlg {
+	case "", "HS256":
+	case "RS256", "ES256", "EdDSA":
+		if len(c.JWTSigningKeyEncryptionKey) < 32 {
+			errs = append(errs, "JWT_SIGNING_KEY_ENCRYPTION_KEY must be at least 32 chars when JWT_SIGNING_ALG is asymmetric")
+		}
+		if c.JWTKeyringRefreshInterval < time.Second || c.JWTKeyringRefreshInterval > (10*time.Minute) {
+			errs = append(errs, "JWT_KEYRING_REFRESH_INTERVAL must be between 1s and 10m")
+		}
+	default:
+		errs = append(errs, "JWT_SIGNING_ALG must be one of HS256, RS256, ES256, EdDSA")
+	}
 	if c.AuthRateLimitPerMin <= 0 {
 		errs = append(errs, "AUTH_RATE_LIMIT_PER_MIN must be > 0")
 	}
@@ -521,6 +970,9 @@ func (c *Config) Validate() error {
 	if c.RBACPermissionCacheEnabled && (c.RBACPermissionCacheTTL <= 0 || c.RBACPermissionCacheTTL > (30*time.Minute)) {
 		errs = append(errs, "RBAC_PERMISSION_CACHE_TTL must be between 1s and 30m when rbac permission cache is enabled")
 	}
+	if c.RBACRoleExpirySweepInterval < 0 || c.RBACRoleExpirySweepInterval > time.Hour {
+		errs = append(errs, "RBAC_ROLE_EXPIRY_SWEEP_INTERVAL must be between 0 and 1h")
+	}
 	if c.IdempotencyTTL <= 0 || c.IdempotencyTTL > (7*24*time.Hour) {
 		errs = append(errs, "IDEMPOTENCY_TTL must be between 1s and 168h")
 	}
@@ -624,6 +1076,12 @@ func (c *Config) Validate() error {
 		errs = append(errs, "OTEL_LOG_LEVEL must be one of debug, info, warn, error")
 	}
 	if c.isProdLike() {
+		if !c.AuthMFARequireForAdmin {
+			errs = append(errs, "AUTH_MFA_REQUIRE_FOR_ADMIN must be true in production/staging")
+		}
+		if c.EmailNotifier == "smtp" && (c.SMTPTLSMode == "none" || c.SMTPTLSInsecureSkipVerify) {
+			errs = append(errs, "SMTP must use verified TLS in production/staging")
+		}
 		if !c.CookieSecure {
 			errs = append(errs, "COOKIE_SECURE must be true in production/staging")
 		}
@@ -642,7 +1100,9 @@ func (c *Config) Validate() error {
 			errs = append(errs, "OTEL_TRACE_SAMPLING_RATIO must be <= 0.2 in production/staging")
 		}
 		if looksPlaceholder(c.JWTAccessSecret) || looksPlaceholder(c.JWTRefreshSecret) ||
-			looksPlaceholder(c.RefreshTokenPepper) || looksPlaceholder(c.StateSigningSecret) {
+			looksPlaceholder(c.RefreshTokenPepper) || looksPlaceholder(c.StateSigningSecret) ||
+			looksPlaceholder(c.AuthMFAEncryptionKey) || looksPlaceholder(c.JWTSigningKeyEncryptionKey) ||
+			looksPlaceholder(c.NotificationOutboxEncryptionKey) {
 			errs = append(errs, "secrets must not use placeholder values in production/staging")
 		}
 		if strings.EqualFold(c.RateLimitOutagePolicyAuth, stringFailOpen()) {
@@ -693,6 +1153,36 @@ func isLocalLikeEnv(env string) bool {
 	}
 }
 
+func loadOIDCProviders(names []string) []OIDCProviderConfig {
+	out := make([]OIDCProviderConfig, 0, len(names))
+	for _, name := range names {
+		name = strings.ToLower(name)
+		prefix := oidcEnvPrefix(name)
+		out = append(out, OIDCProviderConfig{
+			Name:         name,
+			DiscoveryURL: strings.TrimSpace(os.Getenv(prefix + "DISCOVERY_URL")),
+			ClientID:     os.Getenv(prefix + "CLIENT_ID"),
+			ClientSecret: os.Getenv(prefix + "CLIENT_SECRET"),
+			RedirectURL:  getEnv(prefix+"REDIRECT_URL", "http://localhost:8080/api/v1/auth/"+name+"/callback"),
+			Scopes:       splitCSV(getEnv(prefix+"SCOPES", "openid,email,profile")),
+		})
+	}
+	return out
+}
+
+func oidcEnvPrefix(name string) string {
+	return "OIDC_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_")) + "_"
+}
+
+func isReservedOAuthProviderName(name string) bool {
+	switch name {
+	case "google", "github", "microsoft", "local":
+		return true
+	default:
+		return false
+	}
+}
+
 func isValidLogLevel(v string) bool {
 	switch strings.ToLower(v) {
 	case "debug", "info", "warn", "error":
@@ -715,6 +1205,17 @@ func stringFailOpen() string {
 	return "fail_open"
 }
 
+// LongestAccessTokenTTL is the longest lifetime of any access token signed
+// with the JWT keys. A rotated-out key must stay published at least this long.
+// Impersonation tokens are capped at JWTAccessTTL by Validate.
+func (c *Config) LongestAccessTokenTTL() time.Duration {
+	ttl := c.JWTAccessTTL
+	if c.AuthServiceAccountsEnabled && c.AuthServiceAccountTokenTTL > ttl {
+		ttl = c.AuthServiceAccountTokenTTL
+	}
+	return ttl
+}
+
 func (c *Config) isProdLike() bool {
 	switch strings.ToLower(strings.TrimSpace(c.Env)) {
 	case "production", "prod", "staging", "stage", "preprod":
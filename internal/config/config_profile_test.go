@@ -20,6 +20,7 @@ func TestValidateProdProfileStrictRules(t *testing.T) {
 		JWTRefreshTTL:                     24 * time.Hour,
 		AuthEmailVerifyTokenTTL:           30 * time.Minute,
 		AuthPasswordResetTokenTTL:         15 * time.Minute,
+		AuthMFAChallengeTTL:               5 * time.Minute,
 		AuthPasswordForgotRateLimitPerMin: 5,
 		RBACProtectedRoles:                []string{"admin", "user"},
 		RBACProtectedPermissions:          []string{"roles:write", "permissions:write"},
@@ -80,6 +81,7 @@ func TestValidateDevelopmentProfileAllowsRelaxedSettings(t *testing.T) {
 		JWTRefreshTTL:                     24 * time.Hour,
 		AuthEmailVerifyTokenTTL:           30 * time.Minute,
 		AuthPasswordResetTokenTTL:         15 * time.Minute,
+		AuthMFAChallengeTTL:               5 * time.Minute,
 		AuthPasswordForgotRateLimitPerMin: 5,
 		RBACProtectedRoles:                []string{"admin", "user"},
 		RBACProtectedPermissions:          []string{"roles:write", "permissions:write"},
@@ -140,6 +142,7 @@ func TestValidateTrustedActorBypassRequiresAllowlist(t *testing.T) {
 		JWTRefreshTTL:                     24 * time.Hour,
 		AuthEmailVerifyTokenTTL:           30 * time.Minute,
 		AuthPasswordResetTokenTTL:         15 * time.Minute,
+		AuthMFAChallengeTTL:               5 * time.Minute,
 		AuthPasswordForgotRateLimitPerMin: 5,
 		RBACProtectedRoles:                []string{"admin", "user"},
 		RBACProtectedPermissions:          []string{"roles:write", "permissions:write"},
@@ -201,6 +204,7 @@ func TestValidateTrustedActorBypassAcceptsCIDR(t *testing.T) {
 		JWTRefreshTTL:                     24 * time.Hour,
 		AuthEmailVerifyTokenTTL:           30 * time.Minute,
 		AuthPasswordResetTokenTTL:         15 * time.Minute,
+		AuthMFAChallengeTTL:               5 * time.Minute,
 		AuthPasswordForgotRateLimitPerMin: 5,
 		RBACProtectedRoles:                []string{"admin", "user"},
 		RBACProtectedPermissions:          []string{"roles:write", "permissions:write"},
@@ -263,6 +267,7 @@ func TestValidateRedisPoolSettings(t *testing.T) {
 		JWTRefreshTTL:                     24 * time.Hour,
 		AuthEmailVerifyTokenTTL:           30 * time.Minute,
 		AuthPasswordResetTokenTTL:         15 * time.Minute,
+		AuthMFAChallengeTTL:               5 * time.Minute,
 		AuthPasswordForgotRateLimitPerMin: 5,
 		RBACProtectedRoles:                []string{"admin", "user"},
 		RBACProtectedPermissions:          []string{"roles:write", "permissions:write"},
@@ -329,6 +334,7 @@ func TestValidateNonLocalRedisRequiresACLAndTLS(t *testing.T) {
 		JWTRefreshTTL:                     24 * time.Hour,
 		AuthEmailVerifyTokenTTL:           30 * time.Minute,
 		AuthPasswordResetTokenTTL:         15 * time.Minute,
+		AuthMFAChallengeTTL:               5 * time.Minute,
 		AuthPasswordForgotRateLimitPerMin: 5,
 		RBACProtectedRoles:                []string{"admin", "user"},
 		RBACProtectedPermissions:          []string{"roles:write", "permissions:write"},
@@ -402,6 +408,7 @@ func TestValidateRedisNamespacePattern(t *testing.T) {
 		JWTRefreshTTL:                     24 * time.Hour,
 		AuthEmailVerifyTokenTTL:           30 * time.Minute,
 		AuthPasswordResetTokenTTL:         15 * time.Minute,
+		AuthMFAChallengeTTL:               5 * time.Minute,
 		AuthPasswordForgotRateLimitPerMin: 5,
 		RBACProtectedRoles:                []string{"admin", "user"},
 		RBACProtectedPermissions:          []string{"roles:write", "permissions:write"},
@@ -510,6 +517,8 @@ func TestValidateProdProfileDisallowsFailOpenForSensitiveRateLimitScopes(t *test
 	cfg.RedisTLSEnabled = true
 	cfg.RedisTLSServerName = "redis.internal"
 	cfg.RateLimitRedisEnabled = true
+	cfg.AuthMFARequireForAdmin = true
+	cfg.AuthMFAEncryptionKey = "mfa-encryption-key-0123456789abcdef"
 
 	cfg.RateLimitOutagePolicyAuth = "fail_open"
 	cfg.RateLimitOutagePolicyForgot = "fail_open"
@@ -544,6 +553,323 @@ func TestValidateProdProfileDisallowsFailOpenForSensitiveRateLimitScopes(t *test
 	}
 }
 
+func TestValidateMFASettings(t *testing.T) {
+	cfg := newValidConfigForProfileTests()
+	cfg.AuthMFARequireForAdmin = true
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "AUTH_MFA_REQUIRE_FOR_ADMIN requires AUTH_MFA_ENCRYPTION_KEY") {
+		t.Fatalf("expected missing mfa key validation error, got %v", err)
+	}
+	cfg.AuthMFAEncryptionKey = "too-short"
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "AUTH_MFA_ENCRYPTION_KEY must be at least 32 chars") {
+		t.Fatalf("expected short mfa key validation error, got %v", err)
+	}
+	cfg.AuthMFAEncryptionKey = "mfa-encryption-key-0123456789abcdef"
+	cfg.AuthMFAChallengeTTL = time.Hour
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "AUTH_MFA_CHALLENGE_TTL") {
+		t.Fatalf("expected mfa challenge ttl validation error, got %v", err)
+	}
+	cfg.AuthMFAChallengeTTL = 5 * time.Minute
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("expected valid mfa settings, got %v", err)
+	}
+
+	cfg.Env = "production"
+	cfg.AuthMFARequireForAdmin = false
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "AUTH_MFA_REQUIRE_FOR_ADMIN must be true in production/staging") {
+		t.Fatalf("expected production mfa enforcement error, got %v", err)
+	}
+}
+
+func TestValidateWebAuthnSettings(t *testing.T) {
+	cfg := newValidConfigForProfileTests()
+	cfg.AuthWebAuthnEnabled = true
+	cfg.AuthWebAuthnRPID = ""
+	cfg.AuthWebAuthnRPOrigins = []string{"localhost:3000"}
+	cfg.AuthWebAuthnChallengeTTL = time.Hour
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatal("expected webauthn validation errors")
+	}
+	for _, token := range []string{"AUTH_WEBAUTHN_RP_ID", "AUTH_WEBAUTHN_RP_ORIGINS", "AUTH_WEBAUTHN_CHALLENGE_TTL"} {
+		if !strings.Contains(err.Error(), token) {
+			t.Fatalf("expected validation error to include %s, got: %v", token, err)
+		}
+	}
+
+	cfg.AuthWebAuthnRPID = "localhost"
+	cfg.AuthWebAuthnRPOrigins = []string{"http://localhost:3000"}
+	cfg.AuthWebAuthnChallengeTTL = 5 * time.Minute
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("expected valid webauthn settings, got %v", err)
+	}
+}
+
+func TestValidateJWTSigningSettings(t *testing.T) {
+	cfg := newValidConfigForProfileTests()
+	cfg.JWTSigningAlg = "PS512"
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "JWT_SIGNING_ALG") {
+		t.Fatalf("expected unsupported algorithm error, got %v", err)
+	}
+
+	cfg.JWTSigningAlg = "ES256"
+	cfg.JWTKeyringRefreshInterval = time.Minute
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "JWT_SIGNING_KEY_ENCRYPTION_KEY") {
+		t.Fatalf("expected missing key encryption key error, got %v", err)
+	}
+	cfg.JWTSigningKeyEncryptionKey = "0123456789abcdef0123456789abcdef"
+	cfg.JWTKeyringRefreshInterval = 0
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "JWT_KEYRING_REFRESH_INTERVAL") {
+		t.Fatalf("expected refresh interval error, got %v", err)
+	}
+	cfg.JWTKeyringRefreshInterval = time.Minute
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("expected valid asymmetric signing settings, got %v", err)
+	}
+}
+
+func TestValidateAPIKeySettings(t *testing.T) {
+	cfg := newValidConfigForProfileTests()
+	cfg.AuthAPIKeysEnabled = true
+	cfg.AuthAPIKeyMaxPerUser = 0
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "AUTH_API_KEY_MAX_PER_USER") {
+		t.Fatalf("expected api key limit validation error, got %v", err)
+	}
+	cfg.AuthAPIKeyMaxPerUser = 10
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("expected valid api key settings, got %v", err)
+	}
+}
+
+func TestValidateServiceAccountSettings(t *testing.T) {
+	cfg := newValidConfigForProfileTests()
+	cfg.AuthServiceAccountsEnabled = true
+	cfg.AuthServiceAccountTokenTTL = 2 * time.Hour
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "AUTH_SERVICE_ACCOUNT_TOKEN_TTL") {
+		t.Fatalf("expected service account token ttl validation error, got %v", err)
+	}
+	cfg.AuthServiceAccountTokenTTL = 15 * time.Minute
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("expected valid service account settings, got %v", err)
+	}
+}
+
+func TestValidateImpersonationSettings(t *testing.T) {
+	cfg := newValidConfigForProfileTests()
+	cfg.AuthImpersonationEnabled = true
+	cfg.AuthImpersonationTTL = cfg.JWTAccessTTL + time.Minute
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "AUTH_IMPERSONATION_TTL") {
+		t.Fatalf("expected impersonation ttl validation error, got %v", err)
+	}
+	cfg.AuthImpersonationTTL = cfg.JWTAccessTTL
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("expected valid impersonation settings, got %v", err)
+	}
+}
+
+func TestValidateReauthMaxAge(t *testing.T) {
+	cfg := newValidConfigForProfileTests()
+	cfg.AuthReauthMaxAge = 25 * time.Hour
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "AUTH_REAUTH_MAX_AGE") {
+		t.Fatalf("expected reauth max age validation error, got %v", err)
+	}
+	cfg.AuthReauthMaxAge = 0
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("expected a zero max age to disable step-up, got %v", err)
+	}
+}
+
+func TestValidateMagicLinkSettings(t *testing.T) {
+	cfg := newValidConfigForProfileTests()
+	cfg.AuthMagicLinkEnabled = true
+	cfg.AuthMagicLinkTokenTTL = 2 * time.Hour
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "AUTH_MAGIC_LINK_TOKEN_TTL") {
+		t.Fatalf("expected magic link token ttl validation error, got %v", err)
+	}
+	cfg.AuthMagicLinkTokenTTL = 15 * time.Minute
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("expected valid magic link settings, got %v", err)
+	}
+}
+
+func TestValidateAccountDeletionSettings(t *testing.T) {
+	cfg := newValidConfigForProfileTests()
+	cfg.AuthAccountDeletionGracePeriod = 100 * 24 * time.Hour
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "AUTH_ACCOUNT_DELETION_GRACE_PERIOD") {
+		t.Fatalf("expected deletion grace period validation error, got %v", err)
+	}
+	cfg.AuthAccountDeletionGracePeriod = 7 * 24 * time.Hour
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("expected valid deletion settings, got %v", err)
+	}
+}
+
+func TestValidateSMTPNotifierSettings(t *testing.T) {
+	cfg := newValidConfigForProfileTests()
+	cfg.EmailNotifier = "smtp"
+	err := cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "SMTP_HOST") || !strings.Contains(err.Error(), "SMTP_FROM") {
+		t.Fatalf("expected smtp host and from validation errors, got %v", err)
+	}
+	cfg.SMTPHost = "smtp.example.com"
+	cfg.SMTPPort = 587
+	cfg.SMTPFrom = "Accounts <no-reply@example.com>"
+	cfg.SMTPTLSMode = "starttls"
+	cfg.SMTPDialTimeout = 5 * time.Second
+	cfg.SMTPSendTimeout = 15 * time.Second
+	cfg.SMTPUsername = "mailer"
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "SMTP_USERNAME and SMTP_PASSWORD") {
+		t.Fatalf("expected smtp credential pairing validation error, got %v", err)
+	}
+	cfg.SMTPPassword = "mailer-password"
+	cfg.SMTPTLSMode = "ssl"
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "SMTP_TLS_MODE") {
+		t.Fatalf("expected smtp tls mode validation error, got %v", err)
+	}
+	cfg.SMTPTLSMode = "tls"
+	cfg.EmailDefaultLocale = "en_US"
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "EMAIL_DEFAULT_LOCALE") {
+		t.Fatalf("expected locale validation error, got %v", err)
+	}
+	cfg.EmailDefaultLocale = "pt-BR"
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("expected valid smtp settings, got %v", err)
+	}
+	cfg.EmailNotifier = "sendgrid"
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "EMAIL_NOTIFIER") {
+		t.Fatalf("expected notifier validation error, got %v", err)
+	}
+}
+
+func TestValidateSMTPRequiresVerifiedTLSInProduction(t *testing.T) {
+	cfg := newValidConfigForProfileTests()
+	cfg.Env = "production"
+	cfg.EmailNotifier = "smtp"
+	cfg.SMTPHost = "smtp.example.com"
+	cfg.SMTPPort = 25
+	cfg.SMTPFrom = "no-reply@example.com"
+	cfg.SMTPTLSMode = "none"
+	cfg.SMTPDialTimeout = 5 * time.Second
+	cfg.SMTPSendTimeout = 15 * time.Second
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "SMTP must use verified TLS") {
+		t.Fatalf("expected production smtp tls validation error, got %v", err)
+	}
+}
+
+func TestValidateNotificationOutboxSettings(t *testing.T) {
+	cfg := newValidConfigForProfileTests()
+	cfg.NotificationOutboxEnabled = true
+	cfg.NotificationOutboxPollInterval = 5 * time.Second
+	cfg.NotificationOutboxBatchSize = 50
+	cfg.NotificationOutboxMaxAttempts = 8
+	cfg.NotificationOutboxBaseBackoff = 30 * time.Second
+	cfg.NotificationOutboxMaxBackoff = time.Hour
+	cfg.NotificationOutboxLease = time.Minute
+	cfg.NotificationOutboxRetention = 168 * time.Hour
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "NOTIFICATION_OUTBOX_ENCRYPTION_KEY") {
+		t.Fatalf("expected the outbox to require an encryption key, got %v", err)
+	}
+	cfg.NotificationOutboxEncryptionKey = "outbox-encryption-key-0123456789abcdef"
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("expected valid outbox settings, got %v", err)
+	}
+	cfg.NotificationOutboxBaseBackoff = 2 * time.Hour
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "NOTIFICATION_OUTBOX_BASE_BACKOFF") {
+		t.Fatalf("expected backoff ordering validation error, got %v", err)
+	}
+	cfg.NotificationOutboxBaseBackoff = 30 * time.Second
+	cfg.SMTPDialTimeout = 5 * time.Second
+	cfg.SMTPSendTimeout = time.Minute
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "NOTIFICATION_OUTBOX_LEASE") {
+		t.Fatalf("expected lease shorter than a send to be rejected, got %v", err)
+	}
+	cfg.NotificationOutboxEnabled = false
+	cfg.NotificationOutboxBatchSize = 0
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("expected outbox settings to be ignored when disabled, got %v", err)
+	}
+}
+
+func TestValidatePasswordPolicySettings(t *testing.T) {
+	cfg := newValidConfigForProfileTests()
+	cfg.PasswordMinLength = 12
+	cfg.PasswordMaxLength = 8
+	cfg.PasswordRequiredClasses = []string{"upper", "emoji"}
+	cfg.PasswordHistoryCount = 30
+	cfg.PasswordMaxAge = time.Hour
+	cfg.PasswordBreachedListFile = t.TempDir()
+	err := cfg.Validate()
+	for _, key := range []string{"PASSWORD_MAX_LENGTH", "PASSWORD_REQUIRED_CLASSES", "PASSWORD_HISTORY_COUNT", "PASSWORD_MAX_AGE", "PASSWORD_BREACHED_LIST_FILE"} {
+		if err == nil || !strings.Contains(err.Error(), key) {
+			t.Fatalf("expected %s validation error, got %v", key, err)
+		}
+	}
+	cfg.PasswordMaxLength = 128
+	cfg.PasswordRequiredClasses = []string{"none"}
+	cfg.PasswordHistoryCount = 5
+	cfg.PasswordMaxAge = 90 * 24 * time.Hour
+	cfg.PasswordBreachedListFile = ""
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("expected valid password policy settings, got %v", err)
+	}
+}
+
+func TestValidateOAuthProviderSettings(t *testing.T) {
+	cfg := newValidConfigForProfileTests()
+	cfg.AuthGitHubEnabled = true
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "GITHUB_OAUTH_CLIENT_ID") {
+		t.Fatalf("expected github credentials validation error, got %v", err)
+	}
+	cfg.GitHubClientID = "gh-client"
+	cfg.GitHubClientSecret = "gh-secret"
+
+	cfg.OIDCProviders = []OIDCProviderConfig{
+		{Name: "okta", DiscoveryURL: "https://okta.example/.well-known/openid-configuration", ClientID: "c", ClientSecret: "s"},
+		{Name: "okta", DiscoveryURL: "https://okta.example/.well-known/openid-configuration", ClientID: "c", ClientSecret: "s"},
+	}
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "duplicated") {
+		t.Fatalf("expected duplicate oidc provider validation error, got %v", err)
+	}
+
+	cfg.OIDCProviders = []OIDCProviderConfig{{Name: "google", DiscoveryURL: "https://accounts.example", ClientID: "c", ClientSecret: "s"}}
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "reserved") {
+		t.Fatalf("expected reserved oidc provider name validation error, got %v", err)
+	}
+
+	cfg.OIDCProviders = []OIDCProviderConfig{{Name: "corp-sso", ClientID: "c"}}
+	err := cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "OIDC_CORP_SSO_DISCOVERY_URL is required") || !strings.Contains(err.Error(), "OIDC_CORP_SSO_CLIENT_SECRET") {
+		t.Fatalf("expected oidc discovery/secret validation errors, got %v", err)
+	}
+
+	cfg.OIDCProviders = []OIDCProviderConfig{{Name: "corp-sso", DiscoveryURL: "https://sso.example/.well-known/openid-configuration", ClientID: "c", ClientSecret: "s"}}
+	cfg.AuthLocalEnabled = false
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("expected sso-only provider config to pass validation: %v", err)
+	}
+}
+
+func TestLoadOIDCProvidersFromEnv(t *testing.T) {
+	t.Setenv("OIDC_CORP_SSO_DISCOVERY_URL", " https://sso.example/.well-known/openid-configuration ")
+	t.Setenv("OIDC_CORP_SSO_CLIENT_ID", "client")
+	t.Setenv("OIDC_CORP_SSO_CLIENT_SECRET", "secret")
+	t.Setenv("OIDC_CORP_SSO_SCOPES", "openid,email")
+
+	providers := loadOIDCProviders([]string{"Corp-SSO"})
+	if len(providers) != 1 {
+		t.Fatalf("expected one provider, got %d", len(providers))
+	}
+	p := providers[0]
+	if p.Name != "corp-sso" || p.DiscoveryURL != "https://sso.example/.well-known/openid-configuration" || p.ClientID != "client" || p.ClientSecret != "secret" {
+		t.Fatalf("unexpected provider config: %+v", p)
+	}
+	if p.RedirectURL != "http://localhost:8080/api/v1/auth/corp-sso/callback" {
+		t.Fatalf("unexpected default redirect url %q", p.RedirectURL)
+	}
+	if len(p.Scopes) != 2 || p.Scopes[1] != "email" {
+		t.Fatalf("unexpected scopes %v", p.Scopes)
+	}
+}
+
 func newValidConfigForProfileTests() *Config {
 	return &Config{
 		Env:                               "development",
@@ -558,6 +884,7 @@ func newValidConfigForProfileTests() *Config {
 		JWTRefreshTTL:                     24 * time.Hour,
 		AuthEmailVerifyTokenTTL:           30 * time.Minute,
 		AuthPasswordResetTokenTTL:         15 * time.Minute,
+		AuthMFAChallengeTTL:               5 * time.Minute,
 		AuthPasswordForgotRateLimitPerMin: 5,
 		RBACProtectedRoles:                []string{"admin", "user"},
 		RBACProtectedPermissions:          []string{"roles:write", "permissions:write"},
@@ -15,14 +15,29 @@ func Migrate(db *gorm.DB) error {
 	err := db.AutoMigrate(
 		&domain.User{},
 		&domain.LocalCredential{},
+		&domain.PasswordHistory{},
 		&domain.Role{},
 		&domain.Permission{},
 		&domain.UserRole{},
 		&domain.RolePermission{},
+		&domain.RoleParent{},
+		&domain.Organization{},
+		&domain.OrganizationMember{},
+		&domain.OrganizationRoleBinding{},
+		&domain.AccessPolicy{},
 		&domain.OAuthAccount{},
 		&domain.Session{},
 		&domain.VerificationToken{},
 		&domain.IdempotencyRecord{},
+		&domain.MFATOTPCredential{},
+		&domain.MFARecoveryCode{},
+		&domain.WebAuthnCredential{},
+		&domain.WebAuthnChallenge{},
+		&domain.JWTSigningKey{},
+		&domain.APIKey{},
+		&domain.ServiceAccount{},
+		&domain.OutboxMessage{},
+		&domain.LoginEvent{},
 	)
 	observability.RecordDatabaseStartupDuration(context.Background(), "migrate", time.Since(start))
 	if err != nil {
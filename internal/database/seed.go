@@ -15,10 +15,17 @@ import (
 var defaultPermissions = []domain.Permission{
 	{Resource: "users", Action: "read"},
 	{Resource: "users", Action: "write"},
+	{Resource: "users", Action: "impersonate"},
 	{Resource: "roles", Action: "read"},
 	{Resource: "roles", Action: "write"},
 	{Resource: "permissions", Action: "read"},
 	{Resource: "permissions", Action: "write"},
+	{Resource: "orgs", Action: "read"},
+	{Resource: "orgs", Action: "write"},
+	{Resource: "members", Action: "read"},
+	{Resource: "members", Action: "write"},
+	{Resource: "policies", Action: "read"},
+	{Resource: "policies", Action: "write"},
 }
 
 type RBACSyncReport struct {
@@ -72,7 +79,7 @@ func SeedSync(db *gorm.DB, bootstrapAdminEmail string) (*RBACSyncReport, error)
 	}
 
 	var perms []domain.Permission
-	if err := db.Where("resource IN ?", []string{"users", "roles", "permissions"}).Find(&perms).Error; err != nil {
+	if err := db.Where("resource IN ?", []string{"users", "roles", "permissions", "orgs", "members", "policies"}).Find(&perms).Error; err != nil {
 		observability.RecordDatabaseStartupEvent(context.Background(), "seed", "error")
 		return nil, err
 	}
@@ -50,6 +50,15 @@ var RepositorySet = wire.NewSet(
 	repository.NewOAuthRepository,
 	repository.NewLocalCredentialRepository,
 	repository.NewVerificationTokenRepository,
+	repository.NewMFARepository,
+	repository.NewWebAuthnCredentialRepository,
+	repository.NewJWTSigningKeyRepository,
+	repository.NewAPIKeyRepository,
+	repository.NewServiceAccountRepository,
+	repository.NewOrganizationRepository,
+	repository.NewAccessPolicyRepository,
+	repository.NewOutboxRepository,
+	repository.NewLoginEventRepository,
 )
 
 var SecuritySet = wire.NewSet(
@@ -58,26 +67,63 @@ var SecuritySet = wire.NewSet(
 )
 
 var ServiceSet = wire.NewSet(
+	service.NewJWTKeyService,
 	service.NewRBACService,
 	service.NewUserService,
+	service.NewUserStatusService,
+	service.NewEmailChangeService,
+	service.NewAccountDataService,
+	provideAccessTokenRevocationStore,
+	provideAccessTokenRevoker,
 	provideSessionService,
 	provideTokenService,
-	service.NewGoogleOAuthProvider,
-	service.NewDevEmailVerificationNotifier,
-	wire.Bind(new(service.EmailVerificationNotifier), new(*service.DevEmailVerificationNotifier)),
-	wire.Bind(new(service.PasswordResetNotifier), new(*service.DevEmailVerificationNotifier)),
-	wire.Bind(new(service.OAuthProvider), new(*service.GoogleOAuthProvider)),
+	service.NewConfiguredOAuthProviderRegistry,
+	provideEmailNotifier,
+	service.NewNotificationOutbox,
+	wire.Bind(new(service.EmailVerificationNotifier), new(service.EmailNotifier)),
+	wire.Bind(new(service.PasswordResetNotifier), new(service.EmailNotifier)),
+	wire.Bind(new(service.MagicLinkNotifier), new(service.EmailNotifier)),
+	wire.Bind(new(service.EmailChangeNotifier), new(service.EmailNotifier)),
 	service.NewOAuthService,
+	service.NewMFAService,
+	service.NewPasswordPolicy,
+	provideWebAuthnChallengeStore,
+	service.NewWebAuthnService,
 	service.NewAuthService,
+	service.NewAPIKeyService,
+	service.NewServiceAccountService,
+	service.NewImpersonationService,
+	service.NewOrganizationService,
+	provideAccessPolicyService,
+	provideRoleBindingExpiryService,
 	wire.Bind(new(service.UserServiceInterface), new(*service.UserService)),
+	wire.Bind(new(service.UserStatusManager), new(*service.UserStatusService)),
+	wire.Bind(new(service.EmailChangeManager), new(*service.EmailChangeService)),
+	wire.Bind(new(service.AccountDataManager), new(*service.AccountDataService)),
 	wire.Bind(new(service.SessionServiceInterface), new(*service.SessionService)),
 	wire.Bind(new(service.AuthServiceInterface), new(*service.AuthService)),
+	wire.Bind(new(service.WebAuthnServiceInterface), new(*service.WebAuthnService)),
 	wire.Bind(new(service.RBACAuthorizer), new(*service.RBACService)),
+	wire.Bind(new(service.APIKeyServiceInterface), new(*service.APIKeyService)),
+	wire.Bind(new(service.APIKeyAuthenticator), new(*service.APIKeyService)),
+	wire.Bind(new(service.ServiceAccountServiceInterface), new(*service.ServiceAccountService)),
+	wire.Bind(new(service.ImpersonationManager), new(*service.ImpersonationService)),
+	wire.Bind(new(service.OrganizationServiceInterface), new(*service.OrganizationService)),
+	wire.Bind(new(service.OrganizationMembershipChecker), new(*service.OrganizationService)),
+	wire.Bind(new(service.OrganizationPermissionSource), new(*service.OrganizationService)),
+	wire.Bind(new(service.AccessPolicyServiceInterface), new(*service.AccessPolicyService)),
 )
 
 var HTTPSet = wire.NewSet(
 	provideRequestBypassEvaluator,
 	provideAuthHandler,
+	provideWebAuthnHandler,
+	provideAPIKeyHandler,
+	provideServiceAccountHandler,
+	provideImpersonationHandler,
+	provideOrganizationHandler,
+	provideAccessPolicyHandler,
+	provideJWKSHandler,
 	provideAuthAbuseGuard,
 	handler.NewUserHandler,
 	provideRBACPermissionCacheStore,
@@ -151,7 +197,8 @@ func provideRedisClient(cfg *config.Config) redis.UniversalClient {
 		(!cfg.IdempotencyEnabled || !cfg.IdempotencyRedisEnabled) &&
 		!cfg.AdminListCacheEnabled &&
 		!cfg.NegativeLookupCacheEnabled &&
-		!cfg.RBACPermissionCacheEnabled {
+		!cfg.RBACPermissionCacheEnabled &&
+		!cfg.AuthAccessRevocationRedisEnabled {
 		return nil
 	}
 	options := &redis.Options{
@@ -222,8 +269,27 @@ func provideRBACPermissionCacheStore(cfg *config.Config, redisClient redis.Unive
 	return service.NewRedisRBACPermissionCacheStore(redisClient, composeRedisPrefix(cfg.RedisKeyNamespace, cfg.RBACPermissionCacheRedisPref))
 }
 
-func providePermissionResolver(cfg *config.Config, userSvc service.UserServiceInterface, store service.RBACPermissionCacheStore) service.PermissionResolver {
-	return service.NewCachedPermissionResolver(store, userSvc, cfg.RBACPermissionCacheTTL)
+func providePermissionResolver(cfg *config.Config, userSvc service.UserServiceInterface, orgs service.OrganizationPermissionSource, store service.RBACPermissionCacheStore) service.PermissionResolver {
+	if !cfg.OrganizationsEnabled {
+		orgs = nil
+	}
+	return service.NewCachedPermissionResolver(store, userSvc, orgs, cfg.RBACPermissionCacheTTL)
+}
+
+func provideAccessPolicyService(cfg *config.Config, repo repository.AccessPolicyRepository, users repository.UserRepository, orgs repository.OrganizationRepository) *service.AccessPolicyService {
+	if !cfg.OrganizationsEnabled {
+		orgs = nil
+	}
+	svc := service.NewAccessPolicyService(repo, cfg.AccessPolicyCacheTTL)
+	svc.RegisterFetcher(service.AccessPolicyResourceUser, service.NewUserAttributeFetcher(users, orgs))
+	return svc
+}
+
+func provideRoleBindingExpiryService(cfg *config.Config, users repository.UserRepository, resolver service.PermissionResolver) *service.RoleBindingExpiryService {
+	if cfg.RBACRoleExpirySweepInterval <= 0 {
+		return nil
+	}
+	return service.NewRoleBindingExpiryService(users, resolver, cfg.RBACRoleExpirySweepInterval)
 }
 
 func provideAdminListCacheStore(cfg *config.Config, redisClient redis.UniversalClient) service.AdminListCacheStore {
@@ -266,20 +332,55 @@ func provideIdempotencyMiddlewareFactory(cfg *config.Config, store service.Idemp
 	}
 }
 
-func provideJWTManager(cfg *config.Config) *security.JWTManager {
-	return security.NewJWTManager(cfg.JWTIssuer, cfg.JWTAudience, cfg.JWTAccessSecret, cfg.JWTRefreshSecret)
+func provideWebAuthnChallengeStore(cfg *config.Config, db *gorm.DB, redisClient redis.UniversalClient) service.WebAuthnChallengeStore {
+	if redisClient != nil {
+		return service.NewRedisWebAuthnChallengeStore(redisClient, composeRedisPrefix(cfg.RedisKeyNamespace, cfg.AuthWebAuthnRedisPrefix))
+	}
+	return service.NewDBWebAuthnChallengeStore(db)
+}
+
+func provideJWTManager(cfg *config.Config, keys *service.JWTKeyService) (*security.JWTManager, error) {
+	if !keys.Enabled() {
+		return security.NewJWTManager(cfg.JWTIssuer, cfg.JWTAudience, cfg.JWTAccessSecret, cfg.JWTRefreshSecret), nil
+	}
+	if err := keys.Bootstrap(context.Background()); err != nil {
+		return nil, err
+	}
+	return security.NewJWTManagerWithKeyring(cfg.JWTIssuer, cfg.JWTAudience, cfg.JWTRefreshSecret, keys.Keyring()), nil
 }
 
 func provideCookieManager(cfg *config.Config) *security.CookieManager {
 	return security.NewCookieManager(cfg.CookieDomain, cfg.CookieSecure, cfg.CookieSameSite)
 }
 
-func provideTokenService(cfg *config.Config, jwt *security.JWTManager, sessionRepo repository.SessionRepository) *service.TokenService {
-	return service.NewTokenService(jwt, sessionRepo, cfg.RefreshTokenPepper, cfg.JWTAccessTTL, cfg.JWTRefreshTTL)
+func provideAccessTokenRevocationStore(cfg *config.Config, redisClient redis.UniversalClient) service.AccessTokenRevocationStore {
+	if cfg.AuthAccessRevocationRedisEnabled && redisClient != nil {
+		return service.NewRedisAccessTokenRevocationStore(redisClient, composeRedisPrefix(cfg.RedisKeyNamespace, cfg.AuthAccessRevocationRedisPrefix))
+	}
+	return service.NewInMemoryAccessTokenRevocationStore()
+}
+
+func provideAccessTokenRevoker(cfg *config.Config, store service.AccessTokenRevocationStore, sessionRepo repository.SessionRepository) *service.AccessTokenRevoker {
+	return service.NewAccessTokenRevoker(store, sessionRepo, cfg.JWTAccessTTL)
 }
 
-func provideSessionService(cfg *config.Config, sessionRepo repository.SessionRepository) *service.SessionService {
-	return service.NewSessionService(sessionRepo, cfg.RefreshTokenPepper)
+func provideTokenService(cfg *config.Config, jwt *security.JWTManager, sessionRepo repository.SessionRepository, revoker *service.AccessTokenRevoker) *service.TokenService {
+	return service.NewTokenService(jwt, sessionRepo, cfg.RefreshTokenPepper, cfg.JWTAccessTTL, cfg.JWTRefreshTTL, revoker)
+}
+
+func provideSessionService(cfg *config.Config, sessionRepo repository.SessionRepository, revoker *service.AccessTokenRevoker) *service.SessionService {
+	return service.NewSessionService(sessionRepo, cfg.RefreshTokenPepper, revoker)
+}
+
+func provideEmailNotifier(cfg *config.Config, logger *slog.Logger) (service.EmailNotifier, error) {
+	if cfg.EmailNotifier != "smtp" {
+		return service.NewDevEmailVerificationNotifier(logger), nil
+	}
+	templates, err := service.NewEmailTemplates(cfg.EmailTemplateDir, cfg.EmailDefaultLocale)
+	if err != nil {
+		return nil, err
+	}
+	return service.NewSMTPEmailNotifier(cfg, templates)
 }
 
 func provideAuthAbuseGuard(cfg *config.Config, redisClient redis.UniversalClient) service.AuthAbuseGuard {
@@ -309,6 +410,63 @@ func provideAuthHandler(
 	return handler.NewAuthHandler(authSvc, abuseGuard, cookieMgr, bypassEvaluator, cfg.StateSigningSecret, cfg.JWTRefreshTTL)
 }
 
+func provideWebAuthnHandler(
+	cfg *config.Config,
+	webauthnSvc service.WebAuthnServiceInterface,
+	authSvc service.AuthServiceInterface,
+	cookieMgr *security.CookieManager,
+) *handler.WebAuthnHandler {
+	if !cfg.AuthWebAuthnEnabled {
+		return nil
+	}
+	return handler.NewWebAuthnHandler(webauthnSvc, authSvc, cookieMgr, cfg.JWTRefreshTTL)
+}
+
+func provideAPIKeyHandler(cfg *config.Config, apiKeySvc service.APIKeyServiceInterface) *handler.APIKeyHandler {
+	if !cfg.AuthAPIKeysEnabled {
+		return nil
+	}
+	return handler.NewAPIKeyHandler(apiKeySvc)
+}
+
+func provideServiceAccountHandler(cfg *config.Config, svc service.ServiceAccountServiceInterface) *handler.ServiceAccountHandler {
+	if !cfg.AuthServiceAccountsEnabled {
+		return nil
+	}
+	return handler.NewServiceAccountHandler(svc)
+}
+
+func provideImpersonationHandler(
+	cfg *config.Config,
+	svc service.ImpersonationManager,
+	authSvc service.AuthServiceInterface,
+	permissionResolver service.PermissionResolver,
+	cookieMgr *security.CookieManager,
+) *handler.ImpersonationHandler {
+	if !cfg.AuthImpersonationEnabled {
+		return nil
+	}
+	return handler.NewImpersonationHandler(svc, authSvc, permissionResolver, cookieMgr, cfg.JWTRefreshTTL)
+}
+
+func provideOrganizationHandler(cfg *config.Config, svc service.OrganizationServiceInterface, permissionResolver service.PermissionResolver) *handler.OrganizationHandler {
+	if !cfg.OrganizationsEnabled {
+		return nil
+	}
+	return handler.NewOrganizationHandler(svc, permissionResolver)
+}
+
+func provideAccessPolicyHandler(cfg *config.Config, svc service.AccessPolicyServiceInterface) *handler.AccessPolicyHandler {
+	if !cfg.AccessPoliciesEnabled {
+		return nil
+	}
+	return handler.NewAccessPolicyHandler(svc)
+}
+
+func provideJWKSHandler(jwt *security.JWTManager) *handler.JWKSHandler {
+	return handler.NewJWKSHandler(jwt.Keyring())
+}
+
 func provideRequestBypassEvaluator(cfg *config.Config, jwt *security.JWTManager) middleware.BypassEvaluator {
 	return middleware.NewRequestBypassEvaluator(middleware.RequestBypassConfig{
 		EnableInternalProbeBypass: cfg.BypassInternalProbes,
@@ -512,7 +670,18 @@ func provideRouterDependencies(
 	authHandler *handler.AuthHandler,
 	userHandler *handler.UserHandler,
 	adminHandler *handler.AdminHandler,
+	webauthnHandler *handler.WebAuthnHandler,
+	apiKeyHandler *handler.APIKeyHandler,
+	serviceAccountHandler *handler.ServiceAccountHandler,
+	impersonationHandler *handler.ImpersonationHandler,
+	organizationHandler *handler.OrganizationHandler,
+	organizationMembership service.OrganizationMembershipChecker,
+	accessPolicyHandler *handler.AccessPolicyHandler,
+	accessPolicies *service.AccessPolicyService,
+	jwksHandler *handler.JWKSHandler,
 	jwt *security.JWTManager,
+	revocations service.AccessTokenRevocationStore,
+	apiKeys service.APIKeyAuthenticator,
 	rbac service.RBACAuthorizer,
 	permissionResolver service.PermissionResolver,
 	globalRateLimiter router.GlobalRateLimiterFunc,
@@ -521,15 +690,41 @@ func provideRouterDependencies(
 	routePolicies router.RouteRateLimitPolicies,
 	idempotencyFactory router.IdempotencyMiddlewareFactory,
 	readiness *health.ProbeRunner,
+	mfaSvc *service.MFAService,
 	cfg *config.Config,
 ) router.Dependencies {
+	var adminMFAChecker service.MFAStatusChecker
+	if cfg.AuthMFARequireForAdmin {
+		adminMFAChecker = mfaSvc
+	}
+	if !cfg.AuthAPIKeysEnabled {
+		apiKeys = nil
+	}
+	var policyEvaluator service.AccessPolicyEvaluator
+	if cfg.AccessPoliciesEnabled && accessPolicies != nil {
+		policyEvaluator = accessPolicies
+	}
 	return router.Dependencies{
 		AuthHandler:                authHandler,
 		UserHandler:                userHandler,
 		AdminHandler:               adminHandler,
+		WebAuthnHandler:            webauthnHandler,
+		APIKeyHandler:              apiKeyHandler,
+		ServiceAccountHandler:      serviceAccountHandler,
+		ImpersonationHandler:       impersonationHandler,
+		OrganizationHandler:        organizationHandler,
+		OrganizationMembership:     organizationMembership,
+		AccessPolicyHandler:        accessPolicyHandler,
+		AccessPolicies:             policyEvaluator,
+		TrustedProxyCIDRs:          cfg.AccessPolicyTrustedProxyCIDRs,
+		JWKSHandler:                jwksHandler,
 		JWTManager:                 jwt,
+		AccessTokenRevocations:     revocations,
+		APIKeyAuthenticator:        apiKeys,
 		RBACService:                rbac,
 		PermissionResolver:         permissionResolver,
+		AdminMFAChecker:            adminMFAChecker,
+		ReauthMaxAge:               cfg.AuthReauthMaxAge,
 		CORSOrigins:                cfg.CORSAllowedOrigins,
 		AuthRateLimitRPM:           cfg.AuthRateLimitPerMin,
 		PasswordForgotRateLimitRPM: cfg.AuthPasswordForgotRateLimitPerMin,
@@ -560,7 +755,7 @@ func provideReadinessProbeRunner(cfg *config.Config, db *gorm.DB, redisClient re
 	if c := health.NewDBChecker(db); c != nil {
 		checkers = append(checkers, c)
 	}
-	if cfg.RateLimitRedisEnabled || (cfg.IdempotencyEnabled && cfg.IdempotencyRedisEnabled) {
+	if cfg.RateLimitRedisEnabled || (cfg.IdempotencyEnabled && cfg.IdempotencyRedisEnabled) || cfg.AuthAccessRevocationRedisEnabled {
 		if c := health.NewRedisChecker(redisClient); c != nil {
 			checkers = append(checkers, c)
 		}
@@ -577,11 +772,74 @@ func provideApp(
 	redisClient redis.UniversalClient,
 	readiness *health.ProbeRunner,
 	idempotencyStore service.IdempotencyStore,
+	jwtKeys *service.JWTKeyService,
+	accountSvc *service.AccountDataService,
+	outbox *service.NotificationOutbox,
+	roleExpiry *service.RoleBindingExpiryService,
 ) *app.App {
-	stopBackgroundTasks := startDBIdempotencyCleanup(cfg, logger, idempotencyStore)
+	stopBackgroundTasks := combineStopFuncs(
+		startDBIdempotencyCleanup(cfg, logger, idempotencyStore),
+		startJWTKeyringRefresh(logger, jwtKeys),
+		startAccountDeletionSweep(logger, accountSvc),
+		startNotificationOutboxDispatcher(cfg, logger, outbox),
+		startRoleBindingExpirySweep(logger, roleExpiry),
+	)
 	return app.New(cfg, logger, server, runtime, db, redisClient, readiness, stopBackgroundTasks)
 }
 
+func startJWTKeyringRefresh(logger *slog.Logger, keys *service.JWTKeyService) func() {
+	if !keys.Enabled() {
+		return nil
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	go keys.RunRefreshLoop(ctx, logger)
+	return cancel
+}
+
+func startAccountDeletionSweep(logger *slog.Logger, accountSvc *service.AccountDataService) func() {
+	if accountSvc == nil {
+		return nil
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	go accountSvc.RunPurgeLoop(ctx, logger)
+	return cancel
+}
+
+func startNotificationOutboxDispatcher(cfg *config.Config, logger *slog.Logger, outbox *service.NotificationOutbox) func() {
+	if !cfg.NotificationOutboxEnabled || outbox == nil {
+		return nil
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	go outbox.RunDispatchLoop(ctx, logger)
+	return cancel
+}
+
+func startRoleBindingExpirySweep(logger *slog.Logger, roleExpiry *service.RoleBindingExpiryService) func() {
+	if roleExpiry == nil {
+		return nil
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	go roleExpiry.RunSweepLoop(ctx, logger)
+	return cancel
+}
+
+func combineStopFuncs(stops ...func()) func() {
+	active := make([]func(), 0, len(stops))
+	for _, stop := range stops {
+		if stop != nil {
+			active = append(active, stop)
+		}
+	}
+	if len(active) == 0 {
+		return nil
+	}
+	return func() {
+		for _, stop := range active {
+			stop()
+		}
+	}
+}
+
 func startDBIdempotencyCleanup(
 	cfg *config.Config,
 	logger *slog.Logger,
@@ -20,6 +20,7 @@ import (
 	"github.com/sandeepkv93/everything-backend-starter-kit/internal/http/middleware"
 	"github.com/sandeepkv93/everything-backend-starter-kit/internal/http/router"
 	"github.com/sandeepkv93/everything-backend-starter-kit/internal/observability"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/repository"
 	"github.com/sandeepkv93/everything-backend-starter-kit/internal/security"
 	"github.com/sandeepkv93/everything-backend-starter-kit/internal/service"
 )
@@ -37,7 +38,7 @@ func TestProvideHTTPServer(t *testing.T) {
 
 func TestProvideRouterDependencies(t *testing.T) {
 	cfg := &config.Config{CORSAllowedOrigins: []string{"http://localhost:3000"}, AuthRateLimitPerMin: 10, APIRateLimitPerMin: 100, OTELMetricsEnabled: true}
-	dep := provideRouterDependencies(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, cfg)
+	dep := provideRouterDependencies(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, cfg)
 	if dep.AuthRateLimitRPM != 10 || dep.APIRateLimitRPM != 100 {
 		t.Fatalf("unexpected rate limits: %+v", dep)
 	}
@@ -425,7 +426,7 @@ func TestProvideApp(t *testing.T) {
 	srv := &http.Server{Addr: ":8080", ReadHeaderTimeout: time.Second}
 	runtime := &observability.Runtime{}
 
-	app := provideApp(cfg, logger, srv, runtime, nil, nil, nil, nil)
+	app := provideApp(cfg, logger, srv, runtime, nil, nil, nil, nil, nil, nil, nil, nil)
 	if app == nil {
 		t.Fatal("expected app")
 	}
@@ -467,6 +468,68 @@ func TestStartDBIdempotencyCleanupDisabledWhenRedisStoreUsed(t *testing.T) {
 	}
 }
 
+func TestStartNotificationOutboxDispatcher(t *testing.T) {
+	db := newDIUnitTestDB(t)
+	cfg := &config.Config{
+		NotificationOutboxEnabled:      true,
+		NotificationOutboxPollInterval: 10 * time.Millisecond,
+		NotificationOutboxBatchSize:    10,
+	}
+	outbox := service.NewNotificationOutbox(cfg, repository.NewOutboxRepository(db), service.NewDevEmailVerificationNotifier(slog.Default()))
+	stop := startNotificationOutboxDispatcher(cfg, slog.Default(), outbox)
+	if stop == nil {
+		t.Fatal("expected dispatcher stop function when the outbox is enabled")
+	}
+	stop()
+
+	cfg.NotificationOutboxEnabled = false
+	if stop := startNotificationOutboxDispatcher(cfg, slog.Default(), outbox); stop != nil {
+		t.Fatal("expected no dispatcher when the outbox is disabled")
+	}
+}
+
+func TestProvideJWTManagerBootstrapsAsymmetricKeyring(t *testing.T) {
+	db := newDIUnitTestDB(t)
+	if err := db.AutoMigrate(&domain.JWTSigningKey{}); err != nil {
+		t.Fatalf("migrate jwt signing keys: %v", err)
+	}
+	cfg := &config.Config{
+		JWTIssuer:                  "iss",
+		JWTAudience:                "aud",
+		JWTRefreshSecret:           "abcdefghijklmnopqrstuvwxyz654321",
+		JWTSigningAlg:              "EdDSA",
+		JWTSigningKeyEncryptionKey: "0123456789abcdef0123456789abcdef",
+		JWTAccessTTL:               time.Minute,
+		JWTKeyringRefreshInterval:  time.Minute,
+	}
+	keys, err := service.NewJWTKeyService(cfg, repository.NewJWTSigningKeyRepository(db))
+	if err != nil {
+		t.Fatalf("new jwt key service: %v", err)
+	}
+	jwt, err := provideJWTManager(cfg, keys)
+	if err != nil {
+		t.Fatalf("provide jwt manager: %v", err)
+	}
+	if jwt.Keyring() == nil || len(jwt.Keyring().JWKS().Keys) != 1 {
+		t.Fatal("expected bootstrapped keyring with one published key")
+	}
+	if stop := startJWTKeyringRefresh(slog.Default(), keys); stop == nil {
+		t.Fatal("expected keyring refresh loop for asymmetric signing")
+	} else {
+		stop()
+	}
+
+	cfg.JWTSigningAlg = "HS256"
+	cfg.JWTAccessSecret = "abcdefghijklmnopqrstuvwxyz123456"
+	jwt, err = provideJWTManager(cfg, keys)
+	if err != nil || jwt.Keyring() != nil {
+		t.Fatalf("expected HS256 manager without keyring, err=%v", err)
+	}
+	if stop := startJWTKeyringRefresh(slog.Default(), keys); stop != nil {
+		t.Fatal("expected no refresh loop in HS256 mode")
+	}
+}
+
 func newDIUnitTestDB(t *testing.T) *gorm.DB {
 	t.Helper()
 	dsn := fmt.Sprintf("file:%s?mode=memory&cache=shared", strings.ReplaceAll(t.Name(), "/", "_"))
@@ -564,6 +627,20 @@ func TestProvideRedisClientEnabledForAdminListCache(t *testing.T) {
 	if client == nil {
 		t.Fatal("expected redis client when negative lookup cache is enabled")
 	}
+
+	cfg.NegativeLookupCacheEnabled = false
+	cfg.AuthAccessRevocationRedisEnabled = true
+	client = provideRedisClient(cfg)
+	if client == nil {
+		t.Fatal("expected redis client when access token revocation uses redis")
+	}
+	if _, ok := provideAccessTokenRevocationStore(cfg, client).(*service.RedisAccessTokenRevocationStore); !ok {
+		t.Fatal("expected redis access token revocation store")
+	}
+	cfg.AuthAccessRevocationRedisEnabled = false
+	if _, ok := provideAccessTokenRevocationStore(cfg, nil).(*service.InMemoryAccessTokenRevocationStore); !ok {
+		t.Fatal("expected in-memory access token revocation store without redis")
+	}
 }
 
 func TestProvideAuthAbuseGuard(t *testing.T) {
@@ -588,6 +665,37 @@ func TestProvideAuthAbuseGuard(t *testing.T) {
 	}
 }
 
+func TestProvideEmailNotifier(t *testing.T) {
+	cfg := &config.Config{EmailNotifier: "dev"}
+	notifier, err := provideEmailNotifier(cfg, slog.Default())
+	if err != nil {
+		t.Fatalf("provide dev notifier: %v", err)
+	}
+	if _, ok := notifier.(*service.DevEmailVerificationNotifier); !ok {
+		t.Fatalf("expected dev notifier, got %T", notifier)
+	}
+
+	cfg = &config.Config{
+		EmailNotifier: "smtp",
+		SMTPHost:      "localhost",
+		SMTPPort:      2525,
+		SMTPFrom:      "Accounts <no-reply@example.com>",
+		SMTPTLSMode:   "starttls",
+	}
+	notifier, err = provideEmailNotifier(cfg, slog.Default())
+	if err != nil {
+		t.Fatalf("provide smtp notifier: %v", err)
+	}
+	if _, ok := notifier.(*service.SMTPEmailNotifier); !ok {
+		t.Fatalf("expected smtp notifier, got %T", notifier)
+	}
+
+	cfg.EmailTemplateDir = t.TempDir() + "/missing"
+	if _, err := provideEmailNotifier(cfg, slog.Default()); err == nil {
+		t.Fatal("expected unreadable template directory to fail")
+	}
+}
+
 func TestProvideRequestBypassEvaluator(t *testing.T) {
 	cfg := &config.Config{
 		BypassInternalProbes:    true,
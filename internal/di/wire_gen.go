@@ -27,7 +27,7 @@ func InitializeApp() (*app.App, error) {
 		return nil, err
 	}
 	logger := provideAppLogger(configConfig, runtime)
-	googleOAuthProvider := service.NewGoogleOAuthProvider(configConfig)
+	oAuthProviderRegistry := service.NewConfiguredOAuthProviderRegistry(configConfig)
 	db, err := provideRuntimeDB(configConfig)
 	if err != nil {
 		return nil, err
@@ -35,29 +35,77 @@ func InitializeApp() (*app.App, error) {
 	userRepository := repository.NewUserRepository(db)
 	oAuthRepository := repository.NewOAuthRepository(db)
 	roleRepository := repository.NewRoleRepository(db)
-	oAuthService := service.NewOAuthService(googleOAuthProvider, userRepository, oAuthRepository, roleRepository)
-	jwtManager := provideJWTManager(configConfig)
+	oAuthService := service.NewOAuthService(configConfig, oAuthProviderRegistry, userRepository, oAuthRepository, roleRepository)
+	jwtSigningKeyRepository := repository.NewJWTSigningKeyRepository(db)
+	jwtKeyService, err := service.NewJWTKeyService(configConfig, jwtSigningKeyRepository)
+	if err != nil {
+		return nil, err
+	}
+	jwtManager, err := provideJWTManager(configConfig, jwtKeyService)
+	if err != nil {
+		return nil, err
+	}
 	sessionRepository := repository.NewSessionRepository(db)
-	tokenService := provideTokenService(configConfig, jwtManager, sessionRepository)
+	universalClient := provideRedisClient(configConfig)
+	accessTokenRevocationStore := provideAccessTokenRevocationStore(configConfig, universalClient)
+	accessTokenRevoker := provideAccessTokenRevoker(configConfig, accessTokenRevocationStore, sessionRepository)
+	tokenService := provideTokenService(configConfig, jwtManager, sessionRepository, accessTokenRevoker)
 	rbacService := service.NewRBACService()
 	userService := service.NewUserService(userRepository, rbacService)
 	localCredentialRepository := repository.NewLocalCredentialRepository(db)
 	verificationTokenRepository := repository.NewVerificationTokenRepository(db)
-	devEmailVerificationNotifier := service.NewDevEmailVerificationNotifier(logger)
-	authService := service.NewAuthService(configConfig, oAuthService, tokenService, userService, roleRepository, localCredentialRepository, verificationTokenRepository, devEmailVerificationNotifier, devEmailVerificationNotifier)
-	universalClient := provideRedisClient(configConfig)
+	emailNotifier, err := provideEmailNotifier(configConfig, logger)
+	if err != nil {
+		return nil, err
+	}
+	mfaRepository := repository.NewMFARepository(db)
+	mfaService, err := service.NewMFAService(configConfig, mfaRepository, verificationTokenRepository)
+	if err != nil {
+		return nil, err
+	}
+	webAuthnCredentialRepository := repository.NewWebAuthnCredentialRepository(db)
+	webAuthnChallengeStore := provideWebAuthnChallengeStore(configConfig, db, universalClient)
+	webAuthnService, err := service.NewWebAuthnService(configConfig, webAuthnCredentialRepository, userRepository, webAuthnChallengeStore)
+	if err != nil {
+		return nil, err
+	}
+	passwordPolicy, err := service.NewPasswordPolicy(configConfig)
+	if err != nil {
+		return nil, err
+	}
+	loginEventRepository := repository.NewLoginEventRepository(db)
+	authService := service.NewAuthService(configConfig, oAuthService, tokenService, userService, roleRepository, localCredentialRepository, verificationTokenRepository, emailNotifier, emailNotifier, emailNotifier, mfaService, webAuthnService, passwordPolicy, loginEventRepository)
 	authAbuseGuard := provideAuthAbuseGuard(configConfig, universalClient)
 	cookieManager := provideCookieManager(configConfig)
 	bypassEvaluator := provideRequestBypassEvaluator(configConfig, jwtManager)
 	authHandler := provideAuthHandler(authService, authAbuseGuard, cookieManager, bypassEvaluator, configConfig)
-	sessionService := provideSessionService(configConfig, sessionRepository)
-	userHandler := handler.NewUserHandler(userService, sessionService)
+	sessionService := provideSessionService(configConfig, sessionRepository, accessTokenRevoker)
+	emailChangeService := service.NewEmailChangeService(configConfig, userRepository, verificationTokenRepository, emailNotifier)
+	accountDataService := service.NewAccountDataService(configConfig, userRepository, localCredentialRepository, oAuthRepository, sessionRepository, loginEventRepository, tokenService)
+	userHandler := handler.NewUserHandler(userService, sessionService, emailChangeService, accountDataService)
+	userStatusService := service.NewUserStatusService(userRepository, tokenService)
 	permissionRepository := repository.NewPermissionRepository(db)
+	organizationRepository := repository.NewOrganizationRepository(db)
+	organizationService := service.NewOrganizationService(organizationRepository, userRepository, roleRepository, rbacService)
 	rbacPermissionCacheStore := provideRBACPermissionCacheStore(configConfig, universalClient)
-	permissionResolver := providePermissionResolver(configConfig, userService, rbacPermissionCacheStore)
+	permissionResolver := providePermissionResolver(configConfig, userService, organizationService, rbacPermissionCacheStore)
 	adminListCacheStore := provideAdminListCacheStore(configConfig, universalClient)
 	negativeLookupCacheStore := provideNegativeLookupCacheStore(configConfig, universalClient)
-	adminHandler := handler.NewAdminHandler(userService, userRepository, roleRepository, permissionRepository, rbacService, permissionResolver, adminListCacheStore, negativeLookupCacheStore, db, configConfig)
+	adminHandler := handler.NewAdminHandler(userService, userStatusService, accountDataService, userRepository, roleRepository, permissionRepository, rbacService, permissionResolver, adminListCacheStore, negativeLookupCacheStore, db, configConfig)
+	webAuthnHandler := provideWebAuthnHandler(configConfig, webAuthnService, authService, cookieManager)
+	apiKeyRepository := repository.NewAPIKeyRepository(db)
+	apiKeyService := service.NewAPIKeyService(configConfig, apiKeyRepository, userService)
+	apiKeyHandler := provideAPIKeyHandler(configConfig, apiKeyService)
+	serviceAccountRepository := repository.NewServiceAccountRepository(db)
+	serviceAccountService := service.NewServiceAccountService(configConfig, serviceAccountRepository, rbacService, jwtManager)
+	serviceAccountHandler := provideServiceAccountHandler(configConfig, serviceAccountService)
+	impersonationService := service.NewImpersonationService(configConfig, userService, tokenService, rbacService)
+	impersonationHandler := provideImpersonationHandler(configConfig, impersonationService, authService, permissionResolver, cookieManager)
+	organizationHandler := provideOrganizationHandler(configConfig, organizationService, permissionResolver)
+	accessPolicyRepository := repository.NewAccessPolicyRepository(db)
+	accessPolicyService := provideAccessPolicyService(configConfig, accessPolicyRepository, userRepository, organizationRepository)
+	accessPolicyHandler := provideAccessPolicyHandler(configConfig, accessPolicyService)
+	jwksHandler := provideJWKSHandler(jwtManager)
 	globalRateLimiterFunc := provideGlobalRateLimiter(configConfig, universalClient, jwtManager, bypassEvaluator)
 	authRateLimiterFunc := provideAuthRateLimiter(configConfig, universalClient, bypassEvaluator)
 	forgotRateLimiterFunc := provideForgotRateLimiter(configConfig, universalClient, bypassEvaluator)
@@ -65,10 +113,13 @@ func InitializeApp() (*app.App, error) {
 	idempotencyStore := provideIdempotencyStore(configConfig, db, universalClient)
 	idempotencyMiddlewareFactory := provideIdempotencyMiddlewareFactory(configConfig, idempotencyStore)
 	probeRunner := provideReadinessProbeRunner(configConfig, db, universalClient)
-	dependencies := provideRouterDependencies(authHandler, userHandler, adminHandler, jwtManager, rbacService, permissionResolver, globalRateLimiterFunc, authRateLimiterFunc, forgotRateLimiterFunc, routeRateLimitPolicies, idempotencyMiddlewareFactory, probeRunner, configConfig)
+	dependencies := provideRouterDependencies(authHandler, userHandler, adminHandler, webAuthnHandler, apiKeyHandler, serviceAccountHandler, impersonationHandler, organizationHandler, organizationService, accessPolicyHandler, accessPolicyService, jwksHandler, jwtManager, accessTokenRevocationStore, apiKeyService, rbacService, permissionResolver, globalRateLimiterFunc, authRateLimiterFunc, forgotRateLimiterFunc, routeRateLimitPolicies, idempotencyMiddlewareFactory, probeRunner, mfaService, configConfig)
 	httpHandler := router.NewRouter(dependencies)
 	server := provideHTTPServer(configConfig, httpHandler)
-	appApp := provideApp(configConfig, logger, server, runtime, db, universalClient, probeRunner, idempotencyStore)
+	outboxRepository := repository.NewOutboxRepository(db)
+	notificationOutbox := service.NewNotificationOutbox(configConfig, outboxRepository, emailNotifier)
+	roleBindingExpiryService := provideRoleBindingExpiryService(configConfig, userRepository, permissionResolver)
+	appApp := provideApp(configConfig, logger, server, runtime, db, universalClient, probeRunner, idempotencyStore, jwtKeyService, accountDataService, notificationOutbox, roleBindingExpiryService)
 	return appApp, nil
 }
 
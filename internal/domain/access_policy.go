@@ -0,0 +1,29 @@
+package domain
+
+import "time"
+
+const (
+	// AccessPolicyEffectRequire denies the request unless Condition holds.
+	AccessPolicyEffectRequire = "require"
+	// AccessPolicyEffectDeny denies the request when Condition holds.
+	AccessPolicyEffectDeny = "deny"
+)
+
+// AccessPolicy narrows a permission check with an attribute-based condition.
+// It applies whenever Permission covers the permission a route requires, and
+// is only consulted after the caller is known to hold that permission.
+type AccessPolicy struct {
+	ID          uint   `gorm:"primaryKey" json:"id"`
+	Name        string `gorm:"uniqueIndex;size:64;not null" json:"name"`
+	Description string `gorm:"size:255" json:"description"`
+	Permission  string `gorm:"size:128;not null;index" json:"permission"`
+	Effect      string `gorm:"size:16;not null" json:"effect"`
+	Condition   string `gorm:"size:2048;not null" json:"condition"`
+	// ResourceType selects the attribute fetcher that backs resource.*;
+	// ResourceParam names the path parameter holding the resource ID.
+	ResourceType  string    `gorm:"size:64" json:"resource_type,omitempty"`
+	ResourceParam string    `gorm:"size:64" json:"resource_param,omitempty"`
+	Enabled       bool      `gorm:"not null" json:"enabled"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
@@ -0,0 +1,19 @@
+package domain
+
+import "time"
+
+// APIKey is a user-owned credential for scripts and CI. Only a hash of the key
+// is stored; Prefix identifies the key in listings without revealing it.
+type APIKey struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	UserID     uint       `gorm:"index;not null" json:"user_id"`
+	Name       string     `gorm:"size:128;not null" json:"name"`
+	Prefix     string     `gorm:"size:32;uniqueIndex;not null" json:"prefix"`
+	KeyHash    string     `gorm:"size:128;uniqueIndex;not null" json:"-"`
+	Scopes     string     `gorm:"type:text;not null" json:"-"`
+	ExpiresAt  *time.Time `gorm:"index" json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `gorm:"index" json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
@@ -0,0 +1,16 @@
+package domain
+
+import "time"
+
+// JWTSigningKey is one asymmetric access-token signing key. The private key is
+// stored as sealed PKCS#8 PEM; verifiers fetch the public half from the JWKS.
+type JWTSigningKey struct {
+	ID                   uint       `gorm:"primaryKey" json:"id"`
+	KID                  string     `gorm:"size:64;uniqueIndex;not null" json:"kid"`
+	Algorithm            string     `gorm:"size:16;not null" json:"algorithm"`
+	PrivateKeyCiphertext string     `gorm:"type:text;not null" json:"-"`
+	ActivatesAt          time.Time  `gorm:"index;not null" json:"activates_at"`
+	RetiresAt            *time.Time `gorm:"index" json:"retires_at,omitempty"`
+	CreatedAt            time.Time  `json:"created_at"`
+	UpdatedAt            time.Time  `json:"updated_at"`
+}
@@ -3,11 +3,30 @@ package domain
 import "time"
 
 type LocalCredential struct {
-	ID              uint       `gorm:"primaryKey" json:"id"`
-	UserID          uint       `gorm:"uniqueIndex;not null" json:"user_id"`
-	PasswordHash    string     `gorm:"size:1024;not null" json:"-"`
-	EmailVerified   bool       `gorm:"not null;default:false" json:"email_verified"`
-	EmailVerifiedAt *time.Time `json:"email_verified_at,omitempty"`
-	CreatedAt       time.Time  `json:"created_at"`
-	UpdatedAt       time.Time  `json:"updated_at"`
+	ID                uint       `gorm:"primaryKey" json:"id"`
+	UserID            uint       `gorm:"uniqueIndex;not null" json:"user_id"`
+	PasswordHash      string     `gorm:"size:1024;not null" json:"-"`
+	PasswordChangedAt *time.Time `json:"password_changed_at,omitempty"`
+	EmailVerified     bool       `gorm:"not null;default:false" json:"email_verified"`
+	EmailVerifiedAt   *time.Time `json:"email_verified_at,omitempty"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+}
+
+// PasswordChangedOrCreatedAt is when the current password was set. Rows
+// created before PasswordChangedAt existed fall back to CreatedAt.
+func (c LocalCredential) PasswordChangedOrCreatedAt() time.Time {
+	if c.PasswordChangedAt != nil {
+		return *c.PasswordChangedAt
+	}
+	return c.CreatedAt
+}
+
+// PasswordHistory holds hashes of passwords a user has replaced so
+// PASSWORD_HISTORY_COUNT can block their reuse.
+type PasswordHistory struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	UserID       uint      `gorm:"index;not null" json:"user_id"`
+	PasswordHash string    `gorm:"size:1024;not null" json:"-"`
+	CreatedAt    time.Time `json:"created_at"`
 }
@@ -0,0 +1,38 @@
+package domain
+
+import "time"
+
+// Login event kinds and outcomes. MFA challenge events carry the factor used
+// ("totp" or "recovery_code") as the method.
+const (
+	LoginEventLogin          = "login"
+	LoginEventMFAChallenge   = "mfa_challenge"
+	LoginEventReauth         = "reauth"
+	LoginEventPasswordChange = "password_change"
+	LoginEventPasswordReset  = "password_reset"
+
+	LoginMethodPassword  = "password"
+	LoginMethodOAuth     = "oauth"
+	LoginMethodMagicLink = "magic_link"
+	LoginMethodPasskey   = "passkey"
+
+	LoginOutcomeSuccess     = "success"
+	LoginOutcomeFailure     = "failure"
+	LoginOutcomeMFARequired = "mfa_required"
+)
+
+// LoginEvent is the per-user record of a sign-in related outcome: logins,
+// MFA challenges, step-up re-authentication and password changes. It is kept
+// next to the audit log so the user's own history can be exported and erased
+// with the account.
+type LoginEvent struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	UserID    uint      `gorm:"index;not null" json:"user_id"`
+	Event     string    `gorm:"size:64;not null" json:"event"`
+	Method    string    `gorm:"size:32" json:"method,omitempty"`
+	Outcome   string    `gorm:"size:32;not null" json:"outcome"`
+	Reason    string    `gorm:"size:64" json:"reason,omitempty"`
+	IP        string    `gorm:"size:64" json:"ip,omitempty"`
+	UserAgent string    `gorm:"size:512" json:"user_agent,omitempty"`
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+}
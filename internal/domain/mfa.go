@@ -0,0 +1,22 @@
+package domain
+
+import "time"
+
+type MFATOTPCredential struct {
+	ID               uint       `gorm:"primaryKey" json:"id"`
+	UserID           uint       `gorm:"uniqueIndex;not null" json:"user_id"`
+	SecretCiphertext string     `gorm:"size:512;not null" json:"-"`
+	ConfirmedAt      *time.Time `gorm:"index" json:"confirmed_at,omitempty"`
+	LastUsedStep     int64      `gorm:"not null;default:0" json:"-"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+}
+
+type MFARecoveryCode struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	UserID    uint       `gorm:"index;not null" json:"user_id"`
+	CodeHash  string     `gorm:"size:128;uniqueIndex;not null" json:"-"`
+	UsedAt    *time.Time `gorm:"index" json:"used_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
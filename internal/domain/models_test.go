@@ -44,6 +44,25 @@ func TestUserModelTagsAndDefaults(t *testing.T) {
 	}
 }
 
+func TestUserStatusLifecycle(t *testing.T) {
+	for _, status := range []string{UserStatusActive, UserStatusSuspended, UserStatusDisabled, UserStatusPending} {
+		if !IsValidUserStatus(status) {
+			t.Fatalf("expected %q to be a valid status", status)
+		}
+	}
+	if IsValidUserStatus("banned") || IsValidUserStatus("") {
+		t.Fatal("expected unknown and empty statuses to be invalid")
+	}
+	if !(&User{}).IsActive() || !(&User{Status: UserStatusActive}).IsActive() {
+		t.Fatal("expected unset and active statuses to be active")
+	}
+	for _, status := range []string{UserStatusSuspended, UserStatusDisabled, UserStatusPending} {
+		if (&User{Status: status}).IsActive() {
+			t.Fatalf("expected %q to be inactive", status)
+		}
+	}
+}
+
 func TestRoleAndPermissionModelContracts(t *testing.T) {
 	roleType := reflect.TypeOf(Role{})
 	name, ok := roleType.FieldByName("Name")
@@ -86,10 +105,18 @@ func TestSensitiveFieldsAreHiddenFromJSON(t *testing.T) {
 		field    string
 	}{
 		{typeName: "LocalCredential", typ: reflect.TypeOf(LocalCredential{}), field: "PasswordHash"},
+		{typeName: "PasswordHistory", typ: reflect.TypeOf(PasswordHistory{}), field: "PasswordHash"},
 		{typeName: "Session", typ: reflect.TypeOf(Session{}), field: "RefreshTokenHash"},
 		{typeName: "Session", typ: reflect.TypeOf(Session{}), field: "TokenID"},
 		{typeName: "VerificationToken", typ: reflect.TypeOf(VerificationToken{}), field: "TokenHash"},
 		{typeName: "IdempotencyRecord", typ: reflect.TypeOf(IdempotencyRecord{}), field: "Scope"},
+		{typeName: "MFATOTPCredential", typ: reflect.TypeOf(MFATOTPCredential{}), field: "SecretCiphertext"},
+		{typeName: "MFARecoveryCode", typ: reflect.TypeOf(MFARecoveryCode{}), field: "CodeHash"},
+		{typeName: "WebAuthnCredential", typ: reflect.TypeOf(WebAuthnCredential{}), field: "PublicKey"},
+		{typeName: "WebAuthnChallenge", typ: reflect.TypeOf(WebAuthnChallenge{}), field: "SessionData"},
+		{typeName: "JWTSigningKey", typ: reflect.TypeOf(JWTSigningKey{}), field: "PrivateKeyCiphertext"},
+		{typeName: "APIKey", typ: reflect.TypeOf(APIKey{}), field: "KeyHash"},
+		{typeName: "ServiceAccount", typ: reflect.TypeOf(ServiceAccount{}), field: "SecretHash"},
 	}
 
 	for _, tc := range cases {
@@ -146,4 +173,28 @@ func TestAssociationJoinModelsHaveCompositePrimaryKeys(t *testing.T) {
 
 	checkCompositePK("UserRole", reflect.TypeOf(UserRole{}), "UserID", "RoleID")
 	checkCompositePK("RolePermission", reflect.TypeOf(RolePermission{}), "RoleID", "PermissionID")
+	checkCompositePK("RoleParent", reflect.TypeOf(RoleParent{}), "RoleID", "ParentID")
+	checkCompositePK("OrganizationMember", reflect.TypeOf(OrganizationMember{}), "OrganizationID", "UserID")
+	checkCompositePK("OrganizationRoleBinding", reflect.TypeOf(OrganizationRoleBinding{}), "OrganizationID", "UserID", "RoleID")
+}
+
+func TestRoleAncestorIDs(t *testing.T) {
+	parents := map[uint][]uint{3: {2}, 2: {1}, 4: {1, 2}}
+	if got := RoleAncestorIDs(parents, 3); !reflect.DeepEqual(got, []uint{2, 1}) {
+		t.Fatalf("expected [2 1], got %v", got)
+	}
+	if got := RoleAncestorIDs(parents, 4); !reflect.DeepEqual(got, []uint{1, 2}) {
+		t.Fatalf("expected shared ancestors once, got %v", got)
+	}
+	if got := RoleAncestorIDs(parents, 1); len(got) != 0 {
+		t.Fatalf("expected a root role to have no ancestors, got %v", got)
+	}
+	parents[1] = []uint{3}
+	found := false
+	for _, id := range RoleAncestorIDs(parents, 3) {
+		found = found || id == 3
+	}
+	if !found {
+		t.Fatal("expected a cycle to surface the role among its own ancestors")
+	}
 }
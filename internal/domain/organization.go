@@ -0,0 +1,31 @@
+package domain
+
+import "time"
+
+// Organization is a tenant. Users join organizations as members and are
+// granted roles per organization, on top of their global roles.
+type Organization struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	Slug      string    `gorm:"uniqueIndex;size:64;not null" json:"slug"`
+	Name      string    `gorm:"size:255;not null" json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type OrganizationMember struct {
+	OrganizationID uint      `gorm:"primaryKey" json:"organization_id"`
+	UserID         uint      `gorm:"primaryKey;index" json:"user_id"`
+	CreatedAt      time.Time `json:"created_at"`
+	// Roles are the member's bindings in this organization. They are
+	// resolved by the repository and never stored on this row.
+	Roles []Role `gorm:"-" json:"roles,omitempty"`
+}
+
+// OrganizationRoleBinding grants RoleID to UserID only while a request is
+// scoped to OrganizationID.
+type OrganizationRoleBinding struct {
+	OrganizationID uint      `gorm:"primaryKey"`
+	UserID         uint      `gorm:"primaryKey;index"`
+	RoleID         uint      `gorm:"primaryKey;index"`
+	CreatedAt      time.Time `json:"created_at"`
+}
@@ -0,0 +1,30 @@
+package domain
+
+import "time"
+
+const (
+	OutboxStatusPending = "pending"
+	OutboxStatusSending = "sending"
+	OutboxStatusSent    = "sent"
+	OutboxStatusDead    = "dead"
+)
+
+// OutboxMessage is a notification waiting for background delivery. Payload
+// holds the serialized notification, raw token included, so it is cleared
+// once the message is sent or can no longer be used.
+type OutboxMessage struct {
+	ID            uint       `gorm:"primaryKey" json:"id"`
+	Kind          string     `gorm:"size:64;index;not null" json:"kind"`
+	UserID        uint       `gorm:"index" json:"user_id"`
+	Payload       string     `gorm:"type:text" json:"-"`
+	Status        string     `gorm:"size:16;index:idx_outbox_status_next,priority:1;not null" json:"status"`
+	Attempts      int        `gorm:"not null;default:0" json:"attempts"`
+	NextAttemptAt time.Time  `gorm:"index:idx_outbox_status_next,priority:2;not null" json:"next_attempt_at"`
+	ExpiresAt     *time.Time `json:"expires_at,omitempty"`
+	LockedBy      string     `gorm:"size:64" json:"locked_by,omitempty"`
+	LockedUntil   *time.Time `json:"locked_until,omitempty"`
+	LastError     string     `gorm:"size:1024" json:"last_error,omitempty"`
+	SentAt        *time.Time `json:"sent_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
@@ -7,12 +7,48 @@ type Role struct {
 	Name        string       `gorm:"uniqueIndex;size:64;not null" json:"name"`
 	Description string       `gorm:"size:255" json:"description"`
 	Permissions []Permission `gorm:"many2many:role_permissions" json:"permissions,omitempty"`
-	CreatedAt   time.Time    `json:"created_at"`
-	UpdatedAt   time.Time    `json:"updated_at"`
+	Parents     []Role       `gorm:"many2many:role_parents;joinForeignKey:RoleID;joinReferences:ParentID" json:"parents,omitempty"`
+	// InheritedPermissions are the permissions of every ancestor role. They
+	// are resolved by the repositories and never stored.
+	InheritedPermissions []Permission `gorm:"-" json:"inherited_permissions,omitempty"`
+	CreatedAt            time.Time    `json:"created_at"`
+	UpdatedAt            time.Time    `json:"updated_at"`
 }
 
+// UserRole binds a role to a user. A binding with ExpiresAt set stops
+// granting anything once that time passes; the expiry sweeper deletes it
+// later.
 type UserRole struct {
-	UserID    uint      `gorm:"primaryKey"`
+	UserID    uint       `gorm:"primaryKey" json:"user_id"`
+	RoleID    uint       `gorm:"primaryKey" json:"role_id"`
+	ExpiresAt *time.Time `gorm:"index" json:"expires_at,omitempty"`
+	Reason    string     `gorm:"size:255" json:"reason,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// RoleParent makes RoleID inherit every permission of ParentID.
+type RoleParent struct {
 	RoleID    uint      `gorm:"primaryKey"`
+	ParentID  uint      `gorm:"primaryKey;index"`
 	CreatedAt time.Time `json:"created_at"`
 }
+
+// RoleAncestorIDs walks parents, a role ID to parent IDs map, and returns
+// every role roleID inherits from. roleID itself is only included when the
+// graph has a cycle through it.
+func RoleAncestorIDs(parents map[uint][]uint, roleID uint) []uint {
+	seen := map[uint]struct{}{}
+	var out []uint
+	queue := append([]uint(nil), parents[roleID]...)
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		out = append(out, id)
+		queue = append(queue, parents[id]...)
+	}
+	return out
+}
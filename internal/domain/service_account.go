@@ -0,0 +1,18 @@
+package domain
+
+import "time"
+
+// ServiceAccount is a non-human principal for backend jobs. It authenticates
+// with the client_credentials grant and is granted roles like a user.
+type ServiceAccount struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	Name        string     `gorm:"uniqueIndex;size:128;not null" json:"name"`
+	Description string     `gorm:"size:255" json:"description"`
+	ClientID    string     `gorm:"uniqueIndex;size:64;not null" json:"client_id"`
+	SecretHash  string     `gorm:"size:128;not null" json:"-"`
+	DisabledAt  *time.Time `json:"disabled_at,omitempty"`
+	LastUsedAt  *time.Time `json:"last_used_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	Roles       []Role     `gorm:"many2many:service_account_roles" json:"roles,omitempty"`
+}
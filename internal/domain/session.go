@@ -15,6 +15,20 @@ type Session struct {
 	RevokedAt        *time.Time `gorm:"index" json:"revoked_at,omitempty"`
 	RevokedReason    *string    `gorm:"size:64" json:"revoked_reason,omitempty"`
 	ReuseDetectedAt  *time.Time `gorm:"index" json:"reuse_detected_at,omitempty"`
-	CreatedAt        time.Time  `json:"created_at"`
-	UpdatedAt        time.Time  `json:"updated_at"`
+	ImpersonatorID   *uint      `gorm:"index" json:"impersonator_id,omitempty"`
+	AuthTime         *time.Time `json:"auth_time,omitempty"`
+	// AccessIssuedAt is when the latest access token carrying TokenID was
+	// signed. Reauthentication re-signs it without creating a session.
+	AccessIssuedAt *time.Time `gorm:"index" json:"-"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// AccessTokenIssuedAt falls back to CreatedAt for rows written before
+// AccessIssuedAt existed.
+func (s Session) AccessTokenIssuedAt() time.Time {
+	if s.AccessIssuedAt != nil {
+		return *s.AccessIssuedAt
+	}
+	return s.CreatedAt
 }
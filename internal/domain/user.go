@@ -1,15 +1,53 @@
 package domain
 
-import "time"
+import (
+	"fmt"
+	"time"
+)
+
+// User lifecycle states. Only active users may sign in or refresh sessions.
+const (
+	UserStatusActive    = "active"
+	UserStatusSuspended = "suspended"
+	UserStatusDisabled  = "disabled"
+	UserStatusPending   = "pending"
+	// UserStatusPendingDeletion holds a self-deleted account until its grace
+	// period ends; UserStatusDeleted marks the anonymized row left behind.
+	UserStatusPendingDeletion = "pending_deletion"
+	UserStatusDeleted         = "deleted"
+)
 
 type User struct {
-	ID          uint      `gorm:"primaryKey" json:"id"`
-	Email       string    `gorm:"uniqueIndex;size:255;not null" json:"email"`
-	Name        string    `gorm:"size:255;not null" json:"name"`
-	AvatarURL   string    `gorm:"size:1024" json:"avatar_url"`
-	Status      string    `gorm:"size:32;not null;default:active;index:idx_users_status" json:"status"`
-	LastLoginAt time.Time `json:"last_login_at"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
-	Roles       []Role    `gorm:"many2many:user_roles" json:"roles,omitempty"`
+	ID                  uint       `gorm:"primaryKey" json:"id"`
+	Email               string     `gorm:"uniqueIndex;size:255;not null" json:"email"`
+	Name                string     `gorm:"size:255;not null" json:"name"`
+	AvatarURL           string     `gorm:"size:1024" json:"avatar_url"`
+	Status              string     `gorm:"size:32;not null;default:active;index:idx_users_status" json:"status"`
+	LastLoginAt         time.Time  `json:"last_login_at"`
+	DeletionScheduledAt *time.Time `gorm:"index" json:"deletion_scheduled_at,omitempty"`
+	CreatedAt           time.Time  `json:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at"`
+	Roles               []Role     `gorm:"many2many:user_roles" json:"roles,omitempty"`
+	// RolesExpireAt is the earliest expiry among the loaded role bindings,
+	// nil when none of them expire. It is never stored.
+	RolesExpireAt *time.Time `gorm:"-" json:"-"`
+}
+
+// IsActive treats an unset status as active, matching the column default.
+func (u *User) IsActive() bool {
+	return u.Status == "" || u.Status == UserStatusActive
+}
+
+func IsValidUserStatus(status string) bool {
+	switch status {
+	case UserStatusActive, UserStatusSuspended, UserStatusDisabled, UserStatusPending, UserStatusPendingDeletion, UserStatusDeleted:
+		return true
+	}
+	return false
+}
+
+// ErasedUserEmail is the placeholder address an erased user keeps so the
+// unique email column stays satisfied and the address can be reused.
+func ErasedUserEmail(userID uint) string {
+	return fmt.Sprintf("erased-%d@erased.invalid", userID)
 }
@@ -7,6 +7,7 @@ type VerificationToken struct {
 	UserID    uint       `gorm:"index;not null" json:"user_id"`
 	TokenHash string     `gorm:"size:128;uniqueIndex;not null" json:"-"`
 	Purpose   string     `gorm:"size:32;index;not null" json:"purpose"`
+	NewEmail  string     `gorm:"size:255" json:"-"`
 	ExpiresAt time.Time  `gorm:"index;not null" json:"expires_at"`
 	UsedAt    *time.Time `gorm:"index" json:"used_at,omitempty"`
 	CreatedAt time.Time  `json:"created_at"`
@@ -0,0 +1,27 @@
+package domain
+
+import "time"
+
+type WebAuthnCredential struct {
+	ID              uint       `gorm:"primaryKey" json:"id"`
+	UserID          uint       `gorm:"index;not null" json:"user_id"`
+	CredentialID    string     `gorm:"size:512;uniqueIndex;not null" json:"credential_id"`
+	PublicKey       []byte     `gorm:"not null" json:"-"`
+	AttestationType string     `gorm:"size:64" json:"-"`
+	AAGUID          []byte     `json:"-"`
+	SignCount       uint32     `gorm:"not null;default:0" json:"-"`
+	Transports      string     `gorm:"size:255" json:"transports"`
+	Flags           uint8      `gorm:"not null;default:0" json:"-"`
+	Name            string     `gorm:"size:128" json:"name"`
+	LastUsedAt      *time.Time `json:"last_used_at,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+type WebAuthnChallenge struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	ChallengeID string    `gorm:"size:128;uniqueIndex;not null" json:"-"`
+	SessionData []byte    `gorm:"not null" json:"-"`
+	ExpiresAt   time.Time `gorm:"index;not null" json:"-"`
+	CreatedAt   time.Time `json:"created_at"`
+}
@@ -0,0 +1,184 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/domain"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/http/response"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/observability"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/service"
+)
+
+type AccessPolicyHandler struct {
+	svc service.AccessPolicyServiceInterface
+}
+
+func NewAccessPolicyHandler(svc service.AccessPolicyServiceInterface) *AccessPolicyHandler {
+	return &AccessPolicyHandler{svc: svc}
+}
+
+type accessPolicyRequest struct {
+	Name          *string `json:"name"`
+	Description   *string `json:"description"`
+	Permission    *string `json:"permission"`
+	Effect        *string `json:"effect"`
+	Condition     *string `json:"condition"`
+	ResourceType  *string `json:"resource_type"`
+	ResourceParam *string `json:"resource_param"`
+	Enabled       *bool   `json:"enabled"`
+}
+
+// input overlays the fields present in the request on base.
+func (req accessPolicyRequest) input(base domain.AccessPolicy) service.AccessPolicyInput {
+	in := service.AccessPolicyInput{
+		Name:          base.Name,
+		Description:   base.Description,
+		Permission:    base.Permission,
+		Effect:        base.Effect,
+		Condition:     base.Condition,
+		ResourceType:  base.ResourceType,
+		ResourceParam: base.ResourceParam,
+		Enabled:       req.Enabled,
+	}
+	overlayString(&in.Name, req.Name)
+	overlayString(&in.Description, req.Description)
+	overlayString(&in.Permission, req.Permission)
+	overlayString(&in.Effect, req.Effect)
+	overlayString(&in.Condition, req.Condition)
+	overlayString(&in.ResourceType, req.ResourceType)
+	overlayString(&in.ResourceParam, req.ResourceParam)
+	return in
+}
+
+func overlayString(dst, src *string) {
+	if src != nil {
+		*dst = *src
+	}
+}
+
+func (h *AccessPolicyHandler) List(w http.ResponseWriter, r *http.Request) {
+	policies, err := h.svc.List()
+	if err != nil {
+		response.Error(w, r, http.StatusInternalServerError, "INTERNAL", "failed to list access policies", nil)
+		return
+	}
+	response.JSON(w, r, http.StatusOK, policies)
+}
+
+func (h *AccessPolicyHandler) Get(w http.ResponseWriter, r *http.Request) {
+	id, err := parsePathID(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, r, http.StatusBadRequest, "BAD_REQUEST", "invalid policy id", nil)
+		return
+	}
+	p, err := h.svc.Get(id)
+	if err != nil {
+		writeAccessPolicyError(w, r, err)
+		return
+	}
+	response.JSON(w, r, http.StatusOK, p)
+}
+
+func (h *AccessPolicyHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var body accessPolicyRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		response.Error(w, r, http.StatusBadRequest, "BAD_REQUEST", "invalid payload", nil)
+		return
+	}
+	p, err := h.svc.Create(body.input(domain.AccessPolicy{}))
+	if err != nil {
+		observability.RecordAdminRBACMutation(r.Context(), "access_policy", "create", accessPolicyMutationStatus(err))
+		writeAccessPolicyError(w, r, err)
+		return
+	}
+	h.audit(r, "admin.policy.create", "create", "policy_created", p)
+	observability.RecordAdminRBACMutation(r.Context(), "access_policy", "create", "success")
+	response.JSON(w, r, http.StatusCreated, p)
+}
+
+func (h *AccessPolicyHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id, err := parsePathID(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, r, http.StatusBadRequest, "BAD_REQUEST", "invalid policy id", nil)
+		return
+	}
+	var body accessPolicyRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		response.Error(w, r, http.StatusBadRequest, "BAD_REQUEST", "invalid payload", nil)
+		return
+	}
+	current, err := h.svc.Get(id)
+	if err != nil {
+		observability.RecordAdminRBACMutation(r.Context(), "access_policy", "update", accessPolicyMutationStatus(err))
+		writeAccessPolicyError(w, r, err)
+		return
+	}
+	p, err := h.svc.Update(id, body.input(*current))
+	if err != nil {
+		observability.RecordAdminRBACMutation(r.Context(), "access_policy", "update", accessPolicyMutationStatus(err))
+		writeAccessPolicyError(w, r, err)
+		return
+	}
+	h.audit(r, "admin.policy.update", "update", "policy_updated", p)
+	observability.RecordAdminRBACMutation(r.Context(), "access_policy", "update", "success")
+	response.JSON(w, r, http.StatusOK, p)
+}
+
+func (h *AccessPolicyHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := parsePathID(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, r, http.StatusBadRequest, "BAD_REQUEST", "invalid policy id", nil)
+		return
+	}
+	current, err := h.svc.Get(id)
+	if err == nil {
+		err = h.svc.Delete(id)
+	}
+	if err != nil {
+		observability.RecordAdminRBACMutation(r.Context(), "access_policy", "delete", accessPolicyMutationStatus(err))
+		writeAccessPolicyError(w, r, err)
+		return
+	}
+	h.audit(r, "admin.policy.delete", "delete", "policy_deleted", current)
+	observability.RecordAdminRBACMutation(r.Context(), "access_policy", "delete", "success")
+	response.JSON(w, r, http.StatusOK, map[string]any{"id": id, "status": "deleted"})
+}
+
+func (h *AccessPolicyHandler) audit(r *http.Request, event, action, reason string, p *domain.AccessPolicy) {
+	observability.EmitAudit(r, observability.AuditInput{
+		EventName:   event,
+		ActorUserID: adminActorID(r),
+		TargetType:  "access_policy",
+		TargetID:    strconv.FormatUint(uint64(p.ID), 10),
+		Action:      action,
+		Outcome:     "success",
+		Reason:      reason,
+	}, "name", p.Name, "permission", p.Permission, "effect", p.Effect, "enabled", p.Enabled)
+}
+
+func writeAccessPolicyError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidAccessPolicy):
+		response.Error(w, r, http.StatusBadRequest, "BAD_REQUEST", err.Error(), nil)
+	case errors.Is(err, service.ErrAccessPolicyConflict):
+		response.Error(w, r, http.StatusConflict, "CONFLICT", "access policy name already exists", nil)
+	case errors.Is(err, service.ErrAccessPolicyNotFound):
+		response.Error(w, r, http.StatusNotFound, "NOT_FOUND", "access policy not found", nil)
+	default:
+		response.Error(w, r, http.StatusInternalServerError, "INTERNAL", "access policy operation failed", nil)
+	}
+}
+
+func accessPolicyMutationStatus(err error) string {
+	switch {
+	case errors.Is(err, service.ErrInvalidAccessPolicy), errors.Is(err, service.ErrAccessPolicyConflict),
+		errors.Is(err, service.ErrAccessPolicyNotFound):
+		return "rejected"
+	}
+	return "error"
+}
@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/domain"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/service"
+)
+
+type stubAccessPolicyService struct {
+	current  domain.AccessPolicy
+	updateFn func(id uint, input service.AccessPolicyInput) (*domain.AccessPolicy, error)
+}
+
+func (s *stubAccessPolicyService) List() ([]domain.AccessPolicy, error) { return nil, nil }
+
+func (s *stubAccessPolicyService) Get(id uint) (*domain.AccessPolicy, error) {
+	if id != s.current.ID {
+		return nil, service.ErrAccessPolicyNotFound
+	}
+	p := s.current
+	return &p, nil
+}
+
+func (s *stubAccessPolicyService) Create(service.AccessPolicyInput) (*domain.AccessPolicy, error) {
+	return nil, service.ErrInvalidAccessPolicy
+}
+
+func (s *stubAccessPolicyService) Update(id uint, input service.AccessPolicyInput) (*domain.AccessPolicy, error) {
+	return s.updateFn(id, input)
+}
+
+func (s *stubAccessPolicyService) Delete(uint) error { return nil }
+
+func newAccessPolicyRequest(method, id, body string) *http.Request {
+	req := httptest.NewRequest(method, "/api/v1/admin/policies/"+id, strings.NewReader(body))
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("id", id)
+	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+}
+
+func TestAccessPolicyHandlerUpdateKeepsOmittedFields(t *testing.T) {
+	var got service.AccessPolicyInput
+	svc := &stubAccessPolicyService{
+		current: domain.AccessPolicy{ID: 3, Name: "office-network", Permission: "users:write", Effect: "require", Condition: `cidr(request.ip, "10.0.0.0/8")`, Enabled: true},
+		updateFn: func(id uint, input service.AccessPolicyInput) (*domain.AccessPolicy, error) {
+			got = input
+			return &domain.AccessPolicy{ID: id, Name: input.Name}, nil
+		},
+	}
+	rr := httptest.NewRecorder()
+	NewAccessPolicyHandler(svc).Update(rr, newAccessPolicyRequest(http.MethodPatch, "3", `{"effect":"deny","enabled":false}`))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d body=%s", rr.Code, rr.Body.String())
+	}
+	if got.Name != "office-network" || got.Permission != "users:write" || got.Condition != `cidr(request.ip, "10.0.0.0/8")` {
+		t.Fatalf("expected omitted fields to be preserved, got %+v", got)
+	}
+	if got.Effect != "deny" || got.Enabled == nil || *got.Enabled {
+		t.Fatalf("expected provided fields to be applied, got %+v", got)
+	}
+}
+
+func TestAccessPolicyHandlerErrors(t *testing.T) {
+	svc := &stubAccessPolicyService{
+		current: domain.AccessPolicy{ID: 3, Name: "office-network"},
+		updateFn: func(uint, service.AccessPolicyInput) (*domain.AccessPolicy, error) {
+			return nil, service.ErrAccessPolicyConflict
+		},
+	}
+	h := NewAccessPolicyHandler(svc)
+	cases := []struct {
+		name   string
+		call   func(http.ResponseWriter, *http.Request)
+		req    *http.Request
+		status int
+	}{
+		{"invalid create", h.Create, newAccessPolicyRequest(http.MethodPost, "", `{"name":"x"}`), http.StatusBadRequest},
+		{"malformed body", h.Create, newAccessPolicyRequest(http.MethodPost, "", `{`), http.StatusBadRequest},
+		{"unknown policy", h.Update, newAccessPolicyRequest(http.MethodPatch, "9", `{}`), http.StatusNotFound},
+		{"conflict", h.Update, newAccessPolicyRequest(http.MethodPatch, "3", `{"name":"taken"}`), http.StatusConflict},
+		{"bad id", h.Delete, newAccessPolicyRequest(http.MethodDelete, "abc", ``), http.StatusBadRequest},
+	}
+	for _, tc := range cases {
+		rr := httptest.NewRecorder()
+		tc.call(rr, tc.req)
+		if rr.Code != tc.status {
+			t.Fatalf("%s: expected %d, got %d body=%s", tc.name, tc.status, rr.Code, rr.Body.String())
+		}
+	}
+}
@@ -27,7 +27,10 @@ import (
 	"github.com/sandeepkv93/everything-backend-starter-kit/internal/service"
 )
 
-var permissionPartRe = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+var (
+	permissionResourceRe = regexp.MustCompile(`^(\*|[a-zA-Z0-9_-]+(\.[a-zA-Z0-9_-]+)*)$`)
+	permissionActionRe   = regexp.MustCompile(`^(\*|[a-zA-Z0-9_-]+)$`)
+)
 
 const (
 	roleNegativeLookupNamespace       = "admin.role.not_found"
@@ -36,6 +39,8 @@ const (
 
 type AdminHandler struct {
 	userSvc              service.UserServiceInterface
+	userStatusSvc        service.UserStatusManager
+	accountSvc           service.AccountDataManager
 	userRepo             repository.UserRepository
 	roleRepo             repository.RoleRepository
 	permRepo             repository.PermissionRepository
@@ -54,6 +59,8 @@ type AdminHandler struct {
 
 func NewAdminHandler(
 	userSvc service.UserServiceInterface,
+	userStatusSvc service.UserStatusManager,
+	accountSvc service.AccountDataManager,
 	userRepo repository.UserRepository,
 	roleRepo repository.RoleRepository,
 	permRepo repository.PermissionRepository,
@@ -80,6 +87,8 @@ func NewAdminHandler(
 	}
 	return &AdminHandler{
 		userSvc:              userSvc,
+		userStatusSvc:        userStatusSvc,
+		accountSvc:           accountSvc,
 		userRepo:             userRepo,
 		roleRepo:             roleRepo,
 		permRepo:             permRepo,
@@ -134,16 +143,24 @@ func (h *AdminHandler) ListUsers(w http.ResponseWriter, r *http.Request) {
 
 	filterEmail := strings.TrimSpace(r.URL.Query().Get("email"))
 	filterStatus := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("status")))
+	if filterStatus != "" && !domain.IsValidUserStatus(filterStatus) {
+		status = "bad_request"
+		response.Error(w, r, http.StatusBadRequest, "BAD_REQUEST", "invalid status filter", nil)
+		return
+	}
 	filterRole := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("role")))
+	// At organization scope the list is limited to its members.
+	orgID, _ := service.OrganizationFromContext(r.Context())
 	sfKey := cacheNamespace + "|" + cacheKey
 	result, err, shared := h.adminListSingleGroup.Do(sfKey, func() (interface{}, error) {
 		usersPage, err := h.userRepo.ListPaged(repository.UserListQuery{
-			PageRequest: pageReq,
-			SortBy:      sortBy,
-			SortOrder:   sortOrder,
-			Email:       filterEmail,
-			Status:      filterStatus,
-			Role:        filterRole,
+			PageRequest:    pageReq,
+			SortBy:         sortBy,
+			SortOrder:      sortOrder,
+			Email:          filterEmail,
+			Status:         filterStatus,
+			Role:           filterRole,
+			OrganizationID: orgID,
 		})
 		if err != nil {
 			return nil, err
@@ -172,6 +189,12 @@ func (h *AdminHandler) ListUsers(w http.ResponseWriter, r *http.Request) {
 	response.JSON(w, r, http.StatusOK, payload)
 }
 
+type userRoleBindingRequest struct {
+	RoleID    uint       `json:"role_id"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	Reason    string     `json:"reason,omitempty"`
+}
+
 func (h *AdminHandler) SetUserRoles(w http.ResponseWriter, r *http.Request) {
 	idParam := chi.URLParam(r, "id")
 	var userID uint
@@ -180,17 +203,33 @@ func (h *AdminHandler) SetUserRoles(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	var body struct {
-		RoleIDs []uint `json:"role_ids"`
+		RoleIDs  []uint                   `json:"role_ids"`
+		Bindings []userRoleBindingRequest `json:"bindings"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
 		response.Error(w, r, http.StatusBadRequest, "BAD_REQUEST", "invalid payload", nil)
 		return
 	}
-	if err := h.userSvc.SetRoles(userID, body.RoleIDs); err != nil {
+	if body.RoleIDs == nil {
+		body.RoleIDs = []uint{}
+	}
+	bindings, err := userRoleBindings(body.RoleIDs, body.Bindings, time.Now().UTC())
+	if err != nil {
+		observability.RecordAdminRBACMutation(r.Context(), "user_role", "set_user_roles", "rejected")
+		response.Error(w, r, http.StatusBadRequest, "BAD_REQUEST", err.Error(), nil)
+		return
+	}
+	if err := h.userSvc.SetRoleBindings(userID, bindings); err != nil {
 		observability.RecordAdminRBACMutation(r.Context(), "user_role", "set_user_roles", "error")
 		response.Error(w, r, http.StatusInternalServerError, "INTERNAL", "failed to set roles", nil)
 		return
 	}
+	temporary := make([]userRoleBindingRequest, 0, len(body.Bindings))
+	for _, b := range bindings {
+		if b.ExpiresAt != nil || b.Reason != "" {
+			temporary = append(temporary, userRoleBindingRequest{RoleID: b.RoleID, ExpiresAt: b.ExpiresAt, Reason: b.Reason})
+		}
+	}
 	observability.EmitAudit(r, observability.AuditInput{
 		EventName:   "admin.user_roles.update",
 		ActorUserID: adminActorID(r),
@@ -199,11 +238,214 @@ func (h *AdminHandler) SetUserRoles(w http.ResponseWriter, r *http.Request) {
 		Action:      "set_roles",
 		Outcome:     "success",
 		Reason:      "roles_updated",
-	}, "role_ids", body.RoleIDs)
+	}, "role_ids", body.RoleIDs, "bindings", temporary)
 	observability.RecordAdminRBACMutation(r.Context(), "user_role", "set_user_roles", "success")
 	h.invalidateRBACPermissionCacheUser(r, userID)
 	h.invalidateAdminListCaches(r, "admin.users.list")
-	response.JSON(w, r, http.StatusOK, map[string]any{"user_id": userID, "role_ids": body.RoleIDs})
+	response.JSON(w, r, http.StatusOK, map[string]any{"user_id": userID, "role_ids": body.RoleIDs, "bindings": temporary})
+}
+
+// userRoleBindings merges the permanent roleIDs and the per-binding entries
+// into one set. role_ids keeps its lenient handling of repeats; a bindings
+// entry must name a role exactly once and expire in the future.
+func userRoleBindings(roleIDs []uint, requested []userRoleBindingRequest, now time.Time) ([]domain.UserRole, error) {
+	permanent := make(map[uint]struct{}, len(roleIDs))
+	out := make([]domain.UserRole, 0, len(roleIDs)+len(requested))
+	for _, id := range roleIDs {
+		if _, ok := permanent[id]; ok {
+			continue
+		}
+		permanent[id] = struct{}{}
+		out = append(out, domain.UserRole{RoleID: id})
+	}
+	fromBindings := make(map[uint]struct{}, len(requested))
+	for _, b := range requested {
+		if b.RoleID == 0 {
+			return nil, errors.New("bindings role_id is required")
+		}
+		if _, ok := fromBindings[b.RoleID]; ok {
+			return nil, fmt.Errorf("role %d is listed more than once in bindings", b.RoleID)
+		}
+		if _, ok := permanent[b.RoleID]; ok {
+			return nil, fmt.Errorf("role %d is in both role_ids and bindings", b.RoleID)
+		}
+		fromBindings[b.RoleID] = struct{}{}
+		reason := strings.TrimSpace(b.Reason)
+		if len(reason) > 255 {
+			return nil, errors.New("binding reason must be at most 255 characters")
+		}
+		binding := domain.UserRole{RoleID: b.RoleID, Reason: reason}
+		if b.ExpiresAt != nil {
+			expiresAt := b.ExpiresAt.UTC()
+			if !expiresAt.After(now) {
+				return nil, fmt.Errorf("expires_at for role %d must be in the future", b.RoleID)
+			}
+			binding.ExpiresAt = &expiresAt
+		}
+		out = append(out, binding)
+	}
+	return out, nil
+}
+
+func (h *AdminHandler) SuspendUser(w http.ResponseWriter, r *http.Request) {
+	h.changeUserStatus(w, r, "suspend")
+}
+
+func (h *AdminHandler) ReactivateUser(w http.ResponseWriter, r *http.Request) {
+	h.changeUserStatus(w, r, "reactivate")
+}
+
+func (h *AdminHandler) changeUserStatus(w http.ResponseWriter, r *http.Request, action string) {
+	outcome := "success"
+	defer func() {
+		observability.RecordAdminUserStatusChange(r.Context(), action, outcome)
+	}()
+	userID, err := parsePathID(chi.URLParam(r, "id"))
+	if err != nil {
+		outcome = "bad_request"
+		response.Error(w, r, http.StatusBadRequest, "BAD_REQUEST", "invalid user id", nil)
+		return
+	}
+	var body struct {
+		Reason string `json:"reason"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		outcome = "bad_request"
+		response.Error(w, r, http.StatusBadRequest, "BAD_REQUEST", "invalid payload", nil)
+		return
+	}
+	reason := strings.TrimSpace(body.Reason)
+	if reason == "" || len(reason) > 512 {
+		outcome = "bad_request"
+		response.Error(w, r, http.StatusBadRequest, "BAD_REQUEST", "reason is required and must be at most 512 characters", nil)
+		return
+	}
+	targetID := strconv.FormatUint(uint64(userID), 10)
+	if action == "suspend" && !isServiceAccountCaller(r) {
+		if actorID, err := actorIDFromRequest(r); err == nil && actorID == userID {
+			outcome = "rejected"
+			response.Error(w, r, http.StatusForbidden, "FORBIDDEN", "cannot suspend own account", nil)
+			return
+		}
+	}
+
+	change := h.userStatusSvc.Suspend
+	if action == "reactivate" {
+		change = h.userStatusSvc.Reactivate
+	}
+	user, previous, err := change(userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			outcome = "not_found"
+			response.Error(w, r, http.StatusNotFound, "NOT_FOUND", "user not found", nil)
+		case errors.Is(err, service.ErrInvalidUserStatusTransition):
+			outcome = "rejected"
+			observability.EmitAudit(r, observability.AuditInput{
+				EventName:   "admin.user." + action,
+				ActorUserID: adminActorID(r),
+				TargetType:  "user",
+				TargetID:    targetID,
+				Action:      action,
+				Outcome:     "rejected",
+				Reason:      reason,
+			}, "previous_status", previous)
+			response.Error(w, r, http.StatusConflict, "CONFLICT", fmt.Sprintf("cannot %s a %s account", action, previous), nil)
+		default:
+			outcome = "error"
+			observability.EmitAudit(r, observability.AuditInput{
+				EventName:   "admin.user." + action,
+				ActorUserID: adminActorID(r),
+				TargetType:  "user",
+				TargetID:    targetID,
+				Action:      action,
+				Outcome:     "failure",
+				Reason:      reason,
+			}, "error", err.Error())
+			response.Error(w, r, http.StatusInternalServerError, "INTERNAL", "failed to update user status", nil)
+		}
+		return
+	}
+	observability.EmitAudit(r, observability.AuditInput{
+		EventName:   "admin.user." + action,
+		ActorUserID: adminActorID(r),
+		TargetType:  "user",
+		TargetID:    targetID,
+		Action:      action,
+		Outcome:     "success",
+		Reason:      reason,
+	}, "previous_status", previous, "status", user.Status)
+	h.invalidateAdminListCaches(r, "admin.users.list")
+	response.JSON(w, r, http.StatusOK, map[string]any{"user_id": userID, "status": user.Status, "previous_status": previous})
+}
+
+// EraseUser anonymizes an account immediately, skipping the grace period a
+// self-service deletion waits out.
+func (h *AdminHandler) EraseUser(w http.ResponseWriter, r *http.Request) {
+	outcome := "success"
+	defer func() {
+		observability.RecordUserAccountEvent(r.Context(), "erase", outcome)
+	}()
+	userID, err := parsePathID(chi.URLParam(r, "id"))
+	if err != nil {
+		outcome = "bad_request"
+		response.Error(w, r, http.StatusBadRequest, "BAD_REQUEST", "invalid user id", nil)
+		return
+	}
+	var body struct {
+		Reason string `json:"reason"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		outcome = "bad_request"
+		response.Error(w, r, http.StatusBadRequest, "BAD_REQUEST", "invalid payload", nil)
+		return
+	}
+	reason := strings.TrimSpace(body.Reason)
+	if reason == "" || len(reason) > 512 {
+		outcome = "bad_request"
+		response.Error(w, r, http.StatusBadRequest, "BAD_REQUEST", "reason is required and must be at most 512 characters", nil)
+		return
+	}
+	targetID := strconv.FormatUint(uint64(userID), 10)
+	if !isServiceAccountCaller(r) {
+		if actorID, err := actorIDFromRequest(r); err == nil && actorID == userID {
+			outcome = "rejected"
+			response.Error(w, r, http.StatusForbidden, "FORBIDDEN", "cannot erase own account; use DELETE /me", nil)
+			return
+		}
+	}
+
+	user, previous, err := h.accountSvc.Erase(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			outcome = "not_found"
+			response.Error(w, r, http.StatusNotFound, "NOT_FOUND", "user not found", nil)
+			return
+		}
+		outcome = "error"
+		observability.EmitAudit(r, observability.AuditInput{
+			EventName:   "admin.user.erase",
+			ActorUserID: adminActorID(r),
+			TargetType:  "user",
+			TargetID:    targetID,
+			Action:      "erase",
+			Outcome:     "failure",
+			Reason:      reason,
+		}, "error", err.Error())
+		response.Error(w, r, http.StatusInternalServerError, "INTERNAL", "failed to erase user", nil)
+		return
+	}
+	observability.EmitAudit(r, observability.AuditInput{
+		EventName:   "admin.user.erase",
+		ActorUserID: adminActorID(r),
+		TargetType:  "user",
+		TargetID:    targetID,
+		Action:      "erase",
+		Outcome:     "success",
+		Reason:      reason,
+	}, "previous_status", previous)
+	h.invalidateAdminListCaches(r, "admin.users.list")
+	response.JSON(w, r, http.StatusOK, map[string]any{"user_id": userID, "status": user.Status, "previous_status": previous})
 }
 
 func (h *AdminHandler) ListRoles(w http.ResponseWriter, r *http.Request) {
@@ -274,6 +516,7 @@ func (h *AdminHandler) CreateRole(w http.ResponseWriter, r *http.Request) {
 		Name        string   `json:"name"`
 		Description string   `json:"description"`
 		Permissions []string `json:"permissions"`
+		ParentIDs   []uint   `json:"parent_ids"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
 		response.Error(w, r, http.StatusBadRequest, "BAD_REQUEST", "invalid payload", nil)
@@ -299,12 +542,15 @@ func (h *AdminHandler) CreateRole(w http.ResponseWriter, r *http.Request) {
 		permIDs = append(permIDs, p.ID)
 	}
 	role := &domain.Role{Name: strings.TrimSpace(body.Name), Description: strings.TrimSpace(body.Description)}
-	if err := h.roleRepo.Create(role, permIDs); err != nil {
+	if err := h.roleRepo.Create(role, permIDs, body.ParentIDs); err != nil {
 		if isConflictError(err) {
 			observability.RecordAdminRBACMutation(r.Context(), "role", "create", "rejected")
 			response.Error(w, r, http.StatusConflict, "CONFLICT", "role already exists", nil)
 			return
 		}
+		if writeRoleHierarchyError(w, r, "create", err) {
+			return
+		}
 		observability.RecordAdminRBACMutation(r.Context(), "role", "create", "error")
 		response.Error(w, r, http.StatusBadRequest, "BAD_REQUEST", "failed to create role", nil)
 		return
@@ -317,7 +563,7 @@ func (h *AdminHandler) CreateRole(w http.ResponseWriter, r *http.Request) {
 		Action:      "create",
 		Outcome:     "success",
 		Reason:      "role_created",
-	}, "role_name", role.Name, "after_permissions", body.Permissions)
+	}, "role_name", role.Name, "after_permissions", body.Permissions, "after_parent_ids", body.ParentIDs)
 	observability.RecordAdminRBACMutation(r.Context(), "role", "create", "success")
 	h.invalidateRBACPermissionCacheAll(r)
 	h.invalidateAdminListCaches(r, "admin.roles.list", "admin.users.list")
@@ -370,6 +616,7 @@ func (h *AdminHandler) UpdateRole(w http.ResponseWriter, r *http.Request) {
 		Name        *string  `json:"name"`
 		Description *string  `json:"description"`
 		Permissions []string `json:"permissions"`
+		ParentIDs   []uint   `json:"parent_ids"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
 		response.Error(w, r, http.StatusBadRequest, "BAD_REQUEST", "invalid payload", nil)
@@ -410,24 +657,32 @@ func (h *AdminHandler) UpdateRole(w http.ResponseWriter, r *http.Request) {
 			permIDs = append(permIDs, p.ID)
 		}
 	}
+	parentIDs := roleIDs(before.Parents)
+	if body.ParentIDs != nil {
+		parentIDs = body.ParentIDs
+	}
 
+	serviceAccount := isServiceAccountCaller(r)
 	actorID, err := actorIDFromRequest(r)
-	if err != nil {
+	if err != nil && !serviceAccount {
 		response.Error(w, r, http.StatusUnauthorized, "UNAUTHORIZED", "invalid actor", nil)
 		return
 	}
-	if h.wouldLockOutRoleMutation(actorID, roleID, requiredPermissionForPath(r.URL.Path), newPermissions) {
+	if !serviceAccount && h.wouldLockOutRoleMutation(actorID, roleID, requiredPermissionForPath(r.URL.Path), newPermissions, parentIDs) {
 		observability.RecordAdminRBACMutation(r.Context(), "role", "update", "rejected")
 		response.Error(w, r, http.StatusForbidden, "FORBIDDEN", "mutation would remove caller required permission", nil)
 		return
 	}
 
-	if err := h.roleRepo.Update(&domain.Role{ID: roleID, Name: newRole.Name, Description: newRole.Description}, permIDs); err != nil {
+	if err := h.roleRepo.Update(&domain.Role{ID: roleID, Name: newRole.Name, Description: newRole.Description}, permIDs, parentIDs); err != nil {
 		if isConflictError(err) {
 			observability.RecordAdminRBACMutation(r.Context(), "role", "update", "rejected")
 			response.Error(w, r, http.StatusConflict, "CONFLICT", "role name already exists", nil)
 			return
 		}
+		if writeRoleHierarchyError(w, r, "update", err) {
+			return
+		}
 		observability.RecordAdminRBACMutation(r.Context(), "role", "update", "error")
 		response.Error(w, r, http.StatusInternalServerError, "INTERNAL", "failed to update role", nil)
 		return
@@ -447,6 +702,8 @@ func (h *AdminHandler) UpdateRole(w http.ResponseWriter, r *http.Request) {
 		"after_name", updated.Name,
 		"before_permissions", permissionsToStrings(before.Permissions),
 		"after_permissions", permissionsToStrings(updated.Permissions),
+		"before_parent_ids", roleIDs(before.Parents),
+		"after_parent_ids", roleIDs(updated.Parents),
 	)
 	observability.RecordAdminRBACMutation(r.Context(), "role", "update", "success")
 	h.invalidateRBACPermissionCacheAll(r)
@@ -495,12 +752,13 @@ func (h *AdminHandler) DeleteRole(w http.ResponseWriter, r *http.Request) {
 		response.Error(w, r, http.StatusForbidden, "FORBIDDEN", "protected role cannot be deleted", nil)
 		return
 	}
+	serviceAccount := isServiceAccountCaller(r)
 	actorID, err := actorIDFromRequest(r)
-	if err != nil {
+	if err != nil && !serviceAccount {
 		response.Error(w, r, http.StatusUnauthorized, "UNAUTHORIZED", "invalid actor", nil)
 		return
 	}
-	if h.wouldLockOutRoleDeletion(actorID, roleID, requiredPermissionForPath(r.URL.Path)) {
+	if !serviceAccount && h.wouldLockOutRoleDeletion(actorID, roleID, requiredPermissionForPath(r.URL.Path)) {
 		observability.RecordAdminRBACMutation(r.Context(), "role", "delete", "rejected")
 		response.Error(w, r, http.StatusForbidden, "FORBIDDEN", "mutation would remove caller required permission", nil)
 		return
@@ -510,6 +768,9 @@ func (h *AdminHandler) DeleteRole(w http.ResponseWriter, r *http.Request) {
 			response.Error(w, r, http.StatusNotFound, "NOT_FOUND", "role not found", nil)
 			return
 		}
+		if writeRoleHierarchyError(w, r, "delete", err) {
+			return
+		}
 		observability.RecordAdminRBACMutation(r.Context(), "role", "delete", "error")
 		response.Error(w, r, http.StatusInternalServerError, "INTERNAL", "failed to delete role", nil)
 		return
@@ -691,13 +952,14 @@ func (h *AdminHandler) UpdatePermission(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	serviceAccount := isServiceAccountCaller(r)
 	actorID, err := actorIDFromRequest(r)
-	if err != nil {
+	if err != nil && !serviceAccount {
 		response.Error(w, r, http.StatusUnauthorized, "UNAUTHORIZED", "invalid actor", nil)
 		return
 	}
 	required := requiredPermissionForPath(r.URL.Path)
-	if h.wouldLockOutPermissionMutation(actorID, before.Resource+":"+before.Action, resource+":"+action, required) {
+	if !serviceAccount && h.wouldLockOutPermissionMutation(actorID, before.Resource+":"+before.Action, resource+":"+action, required) {
 		observability.RecordAdminRBACMutation(r.Context(), "permission", "update", "rejected")
 		response.Error(w, r, http.StatusForbidden, "FORBIDDEN", "mutation would remove caller required permission", nil)
 		return
@@ -775,13 +1037,14 @@ func (h *AdminHandler) DeletePermission(w http.ResponseWriter, r *http.Request)
 		response.Error(w, r, http.StatusForbidden, "FORBIDDEN", "protected permission cannot be deleted", nil)
 		return
 	}
+	serviceAccount := isServiceAccountCaller(r)
 	actorID, err := actorIDFromRequest(r)
-	if err != nil {
+	if err != nil && !serviceAccount {
 		response.Error(w, r, http.StatusUnauthorized, "UNAUTHORIZED", "invalid actor", nil)
 		return
 	}
 	required := requiredPermissionForPath(r.URL.Path)
-	if h.wouldLockOutPermissionDeletion(actorID, permToken, required) {
+	if !serviceAccount && h.wouldLockOutPermissionDeletion(actorID, permToken, required) {
 		observability.RecordAdminRBACMutation(r.Context(), "permission", "delete", "rejected")
 		response.Error(w, r, http.StatusForbidden, "FORBIDDEN", "mutation would remove caller required permission", nil)
 		return
@@ -818,10 +1081,9 @@ func (h *AdminHandler) SyncRBAC(w http.ResponseWriter, r *http.Request) {
 		response.Error(w, r, http.StatusInternalServerError, "INTERNAL", "rbac sync failed", nil)
 		return
 	}
-	actorID, _ := actorIDFromRequest(r)
 	observability.EmitAudit(r, observability.AuditInput{
 		EventName:   "admin.rbac.sync",
-		ActorUserID: observability.ActorUserID(actorID),
+		ActorUserID: adminActorID(r),
 		TargetType:  "rbac",
 		TargetID:    "seed",
 		Action:      "sync",
@@ -853,7 +1115,9 @@ func (h *AdminHandler) isProtectedPermission(token string) bool {
 	return ok
 }
 
-func (h *AdminHandler) wouldLockOutRoleMutation(actorID, roleID uint, requiredPerm string, newRolePermissions []string) bool {
+// wouldLockOutRoleMutation replays the change on the whole role graph, since
+// roles inheriting from roleID change along with it.
+func (h *AdminHandler) wouldLockOutRoleMutation(actorID, roleID uint, requiredPerm string, newRolePermissions []string, newParentIDs []uint) bool {
 	if requiredPerm == "" {
 		return false
 	}
@@ -864,24 +1128,13 @@ func (h *AdminHandler) wouldLockOutRoleMutation(actorID, roleID uint, requiredPe
 	if !h.rbac.HasPermission(perms, requiredPerm) {
 		return false
 	}
-	next := make(map[string]struct{})
-	newSet := make(map[string]struct{}, len(newRolePermissions))
-	for _, p := range newRolePermissions {
-		newSet[strings.ToLower(strings.TrimSpace(p))] = struct{}{}
-	}
-	for _, role := range actor.Roles {
-		if role.ID == roleID {
-			for p := range newSet {
-				next[p] = struct{}{}
-			}
-			continue
-		}
-		for _, p := range role.Permissions {
-			next[strings.ToLower(p.Resource+":"+p.Action)] = struct{}{}
-		}
+	graph, err := h.loadRoleGraph(actor.Roles)
+	if err != nil {
+		return false
 	}
-	_, ok := next[strings.ToLower(requiredPerm)]
-	return !ok
+	graph.permissions[roleID] = newRolePermissions
+	graph.parents[roleID] = newParentIDs
+	return !h.rbac.HasPermission(graph.resolve(roleIDs(actor.Roles)), requiredPerm)
 }
 
 func (h *AdminHandler) wouldLockOutRoleDeletion(actorID, roleID uint, requiredPerm string) bool {
@@ -895,17 +1148,80 @@ func (h *AdminHandler) wouldLockOutRoleDeletion(actorID, roleID uint, requiredPe
 	if !h.rbac.HasPermission(perms, requiredPerm) {
 		return false
 	}
-	next := make(map[string]struct{})
+	graph, err := h.loadRoleGraph(actor.Roles)
+	if err != nil {
+		return false
+	}
+	delete(graph.permissions, roleID)
+	delete(graph.parents, roleID)
+	remaining := make([]uint, 0, len(actor.Roles))
 	for _, role := range actor.Roles {
-		if role.ID == roleID {
-			continue
+		if role.ID != roleID {
+			remaining = append(remaining, role.ID)
 		}
-		for _, p := range role.Permissions {
-			next[strings.ToLower(p.Resource+":"+p.Action)] = struct{}{}
+	}
+	return !h.rbac.HasPermission(graph.resolve(remaining), requiredPerm)
+}
+
+// roleGraph holds each role's direct permissions and parent IDs.
+type roleGraph struct {
+	permissions map[uint][]string
+	parents     map[uint][]uint
+}
+
+func (h *AdminHandler) loadRoleGraph(assigned []domain.Role) (roleGraph, error) {
+	graph := roleGraph{permissions: map[uint][]string{}, parents: map[uint][]uint{}}
+	for _, role := range assigned {
+		graph.permissions[role.ID] = permissionsToStrings(role.Permissions)
+	}
+	roles, err := h.roleRepo.List()
+	if err != nil {
+		return graph, err
+	}
+	for _, role := range roles {
+		graph.permissions[role.ID] = permissionsToStrings(role.Permissions)
+		graph.parents[role.ID] = roleIDs(role.Parents)
+	}
+	return graph, nil
+}
+
+// resolve returns the permissions held through ids and all their ancestors.
+func (g roleGraph) resolve(ids []uint) []string {
+	var out []string
+	for _, id := range ids {
+		out = append(out, g.permissions[id]...)
+		for _, ancestor := range domain.RoleAncestorIDs(g.parents, id) {
+			out = append(out, g.permissions[ancestor]...)
 		}
 	}
-	_, ok := next[strings.ToLower(requiredPerm)]
-	return !ok
+	return out
+}
+
+func roleIDs(roles []domain.Role) []uint {
+	ids := make([]uint, 0, len(roles))
+	for _, role := range roles {
+		ids = append(ids, role.ID)
+	}
+	return ids
+}
+
+// writeRoleHierarchyError answers inheritance errors from the role
+// repository and reports whether it handled err.
+func writeRoleHierarchyError(w http.ResponseWriter, r *http.Request, action string, err error) bool {
+	switch {
+	case errors.Is(err, repository.ErrRoleParentNotFound):
+		observability.RecordAdminRBACMutation(r.Context(), "role", action, "rejected")
+		response.Error(w, r, http.StatusBadRequest, "BAD_REQUEST", "one or more parent roles do not exist", nil)
+	case errors.Is(err, repository.ErrRoleCycle):
+		observability.RecordAdminRBACMutation(r.Context(), "role", action, "rejected")
+		response.Error(w, r, http.StatusConflict, "CONFLICT", "role inheritance would create a cycle", nil)
+	case errors.Is(err, repository.ErrRoleHasChildren):
+		observability.RecordAdminRBACMutation(r.Context(), "role", action, "rejected")
+		response.Error(w, r, http.StatusConflict, "CONFLICT", "role is inherited by other roles", nil)
+	default:
+		return false
+	}
+	return true
 }
 
 func (h *AdminHandler) wouldLockOutPermissionMutation(actorID uint, before, after, requiredPerm string) bool {
@@ -916,14 +1232,11 @@ func (h *AdminHandler) wouldLockOutPermissionMutation(actorID uint, before, afte
 	if err != nil {
 		return false
 	}
-	next := make(map[string]struct{}, len(perms))
-	for _, p := range perms {
-		next[strings.ToLower(p)] = struct{}{}
+	next := withoutPermission(perms, before)
+	if len(next) < len(perms) {
+		next = append(next, after)
 	}
-	delete(next, strings.ToLower(before))
-	next[strings.ToLower(after)] = struct{}{}
-	_, ok := next[strings.ToLower(requiredPerm)]
-	return !ok
+	return !h.rbac.HasPermission(next, requiredPerm)
 }
 
 func (h *AdminHandler) wouldLockOutPermissionDeletion(actorID uint, permToken, requiredPerm string) bool {
@@ -934,13 +1247,19 @@ func (h *AdminHandler) wouldLockOutPermissionDeletion(actorID uint, permToken, r
 	if err != nil {
 		return false
 	}
-	next := make(map[string]struct{}, len(perms))
+	return !h.rbac.HasPermission(withoutPermission(perms, permToken), requiredPerm)
+}
+
+// withoutPermission drops the exact token, not the grants it implies: the
+// other entries of perms are separate permission rows and survive.
+func withoutPermission(perms []string, token string) []string {
+	out := make([]string, 0, len(perms))
 	for _, p := range perms {
-		next[strings.ToLower(p)] = struct{}{}
+		if !strings.EqualFold(strings.TrimSpace(p), strings.TrimSpace(token)) {
+			out = append(out, p)
+		}
 	}
-	delete(next, strings.ToLower(permToken))
-	_, ok := next[strings.ToLower(requiredPerm)]
-	return !ok
+	return out
 }
 
 func requiredPermissionForPath(path string) string {
@@ -986,7 +1305,7 @@ func parsePermissionPairs(perms []string) ([][2]string, error) {
 func validatePermissionParts(resource, action string) (string, string, error) {
 	resource = strings.ToLower(strings.TrimSpace(resource))
 	action = strings.ToLower(strings.TrimSpace(action))
-	if !permissionPartRe.MatchString(resource) || !permissionPartRe.MatchString(action) {
+	if !permissionResourceRe.MatchString(resource) || !permissionActionRe.MatchString(action) {
 		return "", "", fmt.Errorf("permission format must be resource:action")
 	}
 	return resource, action, nil
@@ -1004,7 +1323,19 @@ func actorIDFromRequest(r *http.Request) (uint, error) {
 	return uint(id64), nil
 }
 
+// isServiceAccountCaller reports whether the request was made with a
+// client_credentials token. Lockout checks only apply to human callers, whose
+// permissions come from their own roles.
+func isServiceAccountCaller(r *http.Request) bool {
+	claims, ok := middleware.ClaimsFromContext(r.Context())
+	return ok && claims.IsServiceAccount()
+}
+
 func adminActorID(r *http.Request) string {
+	if isServiceAccountCaller(r) {
+		claims, _ := middleware.ClaimsFromContext(r.Context())
+		return claims.Subject
+	}
 	actorID, err := actorIDFromRequest(r)
 	if err != nil {
 		return "anonymous"
@@ -1090,6 +1421,9 @@ func paginatedData[T any](items []T, page, pageSize int, total int64, totalPages
 func (h *AdminHandler) adminListCacheKey(r *http.Request, namespace string) string {
 	actor := adminActorID(r)
 	query := normalizeQueryValues(r.URL.Query())
+	if orgID, ok := service.OrganizationFromContext(r.Context()); ok {
+		actor += "|org=" + strconv.FormatUint(uint64(orgID), 10)
+	}
 	return namespace + "|actor=" + actor + "|query=" + query
 }
 
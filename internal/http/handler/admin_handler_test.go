@@ -14,12 +14,13 @@ import (
 	"github.com/sandeepkv93/everything-backend-starter-kit/internal/domain"
 	"github.com/sandeepkv93/everything-backend-starter-kit/internal/repository"
 	"github.com/sandeepkv93/everything-backend-starter-kit/internal/security"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/service"
 	"gorm.io/gorm"
 )
 
 type stubAdminUserService struct {
-	setRolesFn func(userID uint, roleIDs []uint) error
-	getByIDFn  func(id uint) (*domain.User, []string, error)
+	setRoleBindingsFn func(userID uint, bindings []domain.UserRole) error
+	getByIDFn         func(id uint) (*domain.User, []string, error)
 }
 
 func (s *stubAdminUserService) GetByID(id uint) (*domain.User, []string, error) {
@@ -31,22 +32,36 @@ func (s *stubAdminUserService) GetByID(id uint) (*domain.User, []string, error)
 
 func (s *stubAdminUserService) List() ([]domain.User, error) { return nil, nil }
 
-func (s *stubAdminUserService) SetRoles(userID uint, roleIDs []uint) error {
-	if s.setRolesFn != nil {
-		return s.setRolesFn(userID, roleIDs)
+func (s *stubAdminUserService) SetRoleBindings(userID uint, bindings []domain.UserRole) error {
+	if s.setRoleBindingsFn != nil {
+		return s.setRoleBindingsFn(userID, bindings)
 	}
 	return nil
 }
 
+type stubUserStatusService struct {
+	suspendFn    func(userID uint) (*domain.User, string, error)
+	reactivateFn func(userID uint) (*domain.User, string, error)
+}
+
+func (s *stubUserStatusService) Suspend(userID uint) (*domain.User, string, error) {
+	if s.suspendFn != nil {
+		return s.suspendFn(userID)
+	}
+	return &domain.User{ID: userID, Status: domain.UserStatusSuspended}, domain.UserStatusActive, nil
+}
+
+func (s *stubUserStatusService) Reactivate(userID uint) (*domain.User, string, error) {
+	if s.reactivateFn != nil {
+		return s.reactivateFn(userID)
+	}
+	return &domain.User{ID: userID, Status: domain.UserStatusActive}, domain.UserStatusSuspended, nil
+}
+
 type stubRBAC struct{}
 
 func (s *stubRBAC) HasPermission(perms []string, required string) bool {
-	for _, p := range perms {
-		if p == required {
-			return true
-		}
-	}
-	return false
+	return service.NewRBACService().HasPermission(perms, required)
 }
 
 type stubPermissionResolver struct {
@@ -120,14 +135,31 @@ func (s *stubUserRepoForAdmin) FindByID(id uint) (*domain.User, error) {
 func (s *stubUserRepoForAdmin) FindByEmail(email string) (*domain.User, error) {
 	return nil, gorm.ErrRecordNotFound
 }
-func (s *stubUserRepoForAdmin) Create(user *domain.User) error { return nil }
-func (s *stubUserRepoForAdmin) Update(user *domain.User) error { return nil }
-func (s *stubUserRepoForAdmin) List() ([]domain.User, error)   { return nil, nil }
+func (s *stubUserRepoForAdmin) Create(user *domain.User) error                   { return nil }
+func (s *stubUserRepoForAdmin) Update(user *domain.User) error                   { return nil }
+func (s *stubUserRepoForAdmin) UpdateStatus(userID uint, status string) error    { return nil }
+func (s *stubUserRepoForAdmin) UpdateEmail(userID uint, email string) error      { return nil }
+func (s *stubUserRepoForAdmin) ScheduleDeletion(userID uint, at time.Time) error { return nil }
+func (s *stubUserRepoForAdmin) CancelDeletion(userID uint) error                 { return nil }
+func (s *stubUserRepoForAdmin) ListDueForDeletion(now time.Time, limit int) ([]uint, error) {
+	return nil, nil
+}
+func (s *stubUserRepoForAdmin) Erase(userID uint) error      { return nil }
+func (s *stubUserRepoForAdmin) List() ([]domain.User, error) { return nil, nil }
 func (s *stubUserRepoForAdmin) ListPaged(query repository.UserListQuery) (repository.PageResult[domain.User], error) {
 	return repository.PageResult[domain.User]{}, nil
 }
 func (s *stubUserRepoForAdmin) SetRoles(userID uint, roleIDs []uint) error { return nil }
-func (s *stubUserRepoForAdmin) AddRole(userID, roleID uint) error          { return nil }
+func (s *stubUserRepoForAdmin) SetRoleBindings(userID uint, bindings []domain.UserRole) error {
+	return nil
+}
+func (s *stubUserRepoForAdmin) AddRole(userID, roleID uint) error { return nil }
+func (s *stubUserRepoForAdmin) ListExpiredRoleBindings(now time.Time, limit int) ([]domain.UserRole, error) {
+	return nil, nil
+}
+func (s *stubUserRepoForAdmin) DeleteExpiredRoleBinding(userID, roleID uint, now time.Time) (bool, error) {
+	return false, nil
+}
 
 type stubRoleRepo struct {
 	rolesByID   map[uint]*domain.Role
@@ -136,6 +168,7 @@ type stubRoleRepo struct {
 	createFn    func(role *domain.Role, permissionIDs []uint) error
 	updateFn    func(role *domain.Role, permissionIDs []uint) error
 	deleteFn    func(id uint) error
+	listFn      func() ([]domain.Role, error)
 }
 
 func (s *stubRoleRepo) FindByID(id uint) (*domain.Role, error) {
@@ -157,13 +190,18 @@ func (s *stubRoleRepo) FindByName(name string) (*domain.Role, error) {
 	return nil, repository.ErrRoleNotFound
 }
 
-func (s *stubRoleRepo) List() ([]domain.Role, error) { return nil, nil }
+func (s *stubRoleRepo) List() ([]domain.Role, error) {
+	if s.listFn != nil {
+		return s.listFn()
+	}
+	return nil, nil
+}
 
 func (s *stubRoleRepo) ListPaged(req repository.PageRequest, sortBy, sortOrder, name string) (repository.PageResult[domain.Role], error) {
 	return repository.PageResult[domain.Role]{}, nil
 }
 
-func (s *stubRoleRepo) Create(role *domain.Role, permissionIDs []uint) error {
+func (s *stubRoleRepo) Create(role *domain.Role, permissionIDs, parentIDs []uint) error {
 	if s.createFn != nil {
 		return s.createFn(role, permissionIDs)
 	}
@@ -177,7 +215,7 @@ func (s *stubRoleRepo) Create(role *domain.Role, permissionIDs []uint) error {
 	return nil
 }
 
-func (s *stubRoleRepo) Update(role *domain.Role, permissionIDs []uint) error {
+func (s *stubRoleRepo) Update(role *domain.Role, permissionIDs, parentIDs []uint) error {
 	if s.updateFn != nil {
 		return s.updateFn(role, permissionIDs)
 	}
@@ -303,6 +341,8 @@ func newAdminHandlerFixture() (*AdminHandler, *stubPermissionResolver, *stubAdmi
 
 	h := NewAdminHandler(
 		userSvc,
+		&stubUserStatusService{},
+		&stubAccountDataSvc{},
 		&stubUserRepoForAdmin{},
 		roleRepo,
 		permRepo,
@@ -358,11 +398,46 @@ func TestAdminHandlerNegativeLookupStaleFalsePositiveRoleUpdate(t *testing.T) {
 	}
 }
 
+func TestAdminHandlerSetUserRolesBindings(t *testing.T) {
+	h, _, _, _, _, _, userSvc := newAdminHandlerFixture()
+	var got []domain.UserRole
+	userSvc.setRoleBindingsFn = func(userID uint, bindings []domain.UserRole) error {
+		got = bindings
+		return nil
+	}
+	expiresAt := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+	body := `{"role_ids":[1,1],"bindings":[{"role_id":2,"expires_at":"` + expiresAt + `","reason":" INC-42 "}]}`
+	req := withURLParam(httptest.NewRequest(http.MethodPatch, "/api/v1/admin/users/10/roles", strings.NewReader(body)), "id", "10")
+	rr := httptest.NewRecorder()
+	h.SetUserRoles(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d body=%s", rr.Code, rr.Body.String())
+	}
+	if len(got) != 2 || got[0].RoleID != 1 || got[0].ExpiresAt != nil || got[1].RoleID != 2 || got[1].ExpiresAt == nil || got[1].Reason != "INC-42" {
+		t.Fatalf("unexpected bindings passed to service: %+v", got)
+	}
+
+	past := time.Now().Add(-time.Minute).UTC().Format(time.RFC3339)
+	for name, body := range map[string]string{
+		"expiry in the past":     `{"bindings":[{"role_id":2,"expires_at":"` + past + `"}]}`,
+		"role in both lists":     `{"role_ids":[2],"bindings":[{"role_id":2}]}`,
+		"repeated binding":       `{"bindings":[{"role_id":2},{"role_id":2}]}`,
+		"binding without a role": `{"bindings":[{"reason":"x"}]}`,
+	} {
+		req := withURLParam(httptest.NewRequest(http.MethodPatch, "/api/v1/admin/users/10/roles", strings.NewReader(body)), "id", "10")
+		rr := httptest.NewRecorder()
+		h.SetUserRoles(rr, req)
+		if rr.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected 400, got %d body=%s", name, rr.Code, rr.Body.String())
+		}
+	}
+}
+
 func TestAdminHandlerMutationCacheInvalidation(t *testing.T) {
 	h, resolver, adminCache, neg, roleRepo, permRepo, userSvc := newAdminHandlerFixture()
 
 	t.Run("set user roles invalidates user cache and user permission cache", func(t *testing.T) {
-		userSvc.setRolesFn = func(userID uint, roleIDs []uint) error { return nil }
+		userSvc.setRoleBindingsFn = func(userID uint, bindings []domain.UserRole) error { return nil }
 		req := withURLParam(httptest.NewRequest(http.MethodPatch, "/api/v1/admin/users/10/roles", strings.NewReader(`{"role_ids":[1,2]}`)), "id", "10")
 		rr := httptest.NewRecorder()
 		h.SetUserRoles(rr, req)
@@ -447,6 +522,81 @@ func TestAdminHandlerMutationCacheInvalidation(t *testing.T) {
 	})
 }
 
+func TestAdminHandlerUserStatusChanges(t *testing.T) {
+	h, _, adminCache, _, _, _, _ := newAdminHandlerFixture()
+	statusSvc := &stubUserStatusService{}
+	h.userStatusSvc = statusSvc
+	call := func(handle http.HandlerFunc, id, body, actor string) *httptest.ResponseRecorder {
+		req := withURLParam(httptest.NewRequest(http.MethodPost, "/api/v1/admin/users/"+id+"/suspend", strings.NewReader(body)), "id", id)
+		req = withClaims(req, actor)
+		rr := httptest.NewRecorder()
+		handle(rr, req)
+		return rr
+	}
+
+	rr := call(h.SuspendUser, "10", `{"reason":"chargeback investigation"}`, "42")
+	if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), `"status":"suspended"`) {
+		t.Fatalf("expected suspend success, got %d body=%s", rr.Code, rr.Body.String())
+	}
+	if adminCache.invalidate["admin.users.list"] == 0 {
+		t.Fatal("expected admin.users.list invalidation")
+	}
+	if rr := call(h.ReactivateUser, "10", `{"reason":"cleared"}`, "42"); rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), `"previous_status":"suspended"`) {
+		t.Fatalf("expected reactivate success, got %d body=%s", rr.Code, rr.Body.String())
+	}
+	if rr := call(h.SuspendUser, "10", `{"reason":"  "}`, "42"); rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected missing reason to be rejected, got %d", rr.Code)
+	}
+	if rr := call(h.SuspendUser, "abc", `{"reason":"x"}`, "42"); rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected invalid id to be rejected, got %d", rr.Code)
+	}
+	if rr := call(h.SuspendUser, "42", `{"reason":"oops"}`, "42"); rr.Code != http.StatusForbidden {
+		t.Fatalf("expected self-suspend to be forbidden, got %d", rr.Code)
+	}
+
+	statusSvc.suspendFn = func(uint) (*domain.User, string, error) { return nil, "", gorm.ErrRecordNotFound }
+	if rr := call(h.SuspendUser, "11", `{"reason":"x"}`, "42"); rr.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for missing user, got %d", rr.Code)
+	}
+	statusSvc.reactivateFn = func(uint) (*domain.User, string, error) {
+		return nil, domain.UserStatusDisabled, service.ErrInvalidUserStatusTransition
+	}
+	if rr := call(h.ReactivateUser, "11", `{"reason":"x"}`, "42"); rr.Code != http.StatusConflict {
+		t.Fatalf("expected 409 for invalid transition, got %d", rr.Code)
+	}
+}
+
+func TestAdminHandlerEraseUser(t *testing.T) {
+	h, _, adminCache, _, _, _, _ := newAdminHandlerFixture()
+	accounts := &stubAccountDataSvc{}
+	h.accountSvc = accounts
+	call := func(id, body, actor string) *httptest.ResponseRecorder {
+		req := withURLParam(httptest.NewRequest(http.MethodPost, "/api/v1/admin/users/"+id+"/erase", strings.NewReader(body)), "id", id)
+		req = withClaims(req, actor)
+		rr := httptest.NewRecorder()
+		h.EraseUser(rr, req)
+		return rr
+	}
+
+	rr := call("10", `{"reason":"GDPR request #42"}`, "42")
+	if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), `"status":"deleted"`) || !strings.Contains(rr.Body.String(), `"previous_status":"active"`) {
+		t.Fatalf("expected erase success, got %d body=%s", rr.Code, rr.Body.String())
+	}
+	if adminCache.invalidate["admin.users.list"] == 0 {
+		t.Fatal("expected admin.users.list invalidation")
+	}
+	if rr := call("10", `{}`, "42"); rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected missing reason to be rejected, got %d", rr.Code)
+	}
+	if rr := call("42", `{"reason":"x"}`, "42"); rr.Code != http.StatusForbidden {
+		t.Fatalf("expected self-erase to be forbidden, got %d", rr.Code)
+	}
+	accounts.eraseFn = func(uint) (*domain.User, string, error) { return nil, "", gorm.ErrRecordNotFound }
+	if rr := call("11", `{"reason":"x"}`, "42"); rr.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for missing user, got %d", rr.Code)
+	}
+}
+
 func TestAdminHandlerLockoutHelpersAndListParserFailures(t *testing.T) {
 	h, _, _, _, _, _, _ := newAdminHandlerFixture()
 
@@ -460,13 +610,13 @@ func TestAdminHandlerLockoutHelpersAndListParserFailures(t *testing.T) {
 				},
 			}, []string{"roles:write"}, nil
 		}
-		if !singleRoleHandler.wouldLockOutRoleMutation(42, 100, "roles:write", []string{"users:read"}) {
+		if !singleRoleHandler.wouldLockOutRoleMutation(42, 100, "roles:write", []string{"users:read"}, nil) {
 			t.Fatal("expected lockout when required permission removed")
 		}
-		if h.wouldLockOutRoleMutation(42, 100, "roles:write", []string{"users:read"}) {
+		if h.wouldLockOutRoleMutation(42, 100, "roles:write", []string{"users:read"}, nil) {
 			t.Fatal("did not expect lockout when another actor role still grants required permission")
 		}
-		if h.wouldLockOutRoleMutation(42, 100, "roles:write", []string{"roles:write"}) {
+		if h.wouldLockOutRoleMutation(42, 100, "roles:write", []string{"roles:write"}, nil) {
 			t.Fatal("did not expect lockout when required permission retained")
 		}
 	})
@@ -483,6 +633,65 @@ func TestAdminHandlerLockoutHelpersAndListParserFailures(t *testing.T) {
 		}
 	})
 
+	t.Run("lockout checks honour wildcard grants", func(t *testing.T) {
+		wildcardHandler, _, _, _, _, _, wildcardSvc := newAdminHandlerFixture()
+		wildcardSvc.getByIDFn = func(id uint) (*domain.User, []string, error) {
+			return &domain.User{
+				ID: id,
+				Roles: []domain.Role{
+					{ID: 100, Name: "admin", Permissions: []domain.Permission{{Resource: "*", Action: "*"}}},
+					{ID: 101, Name: "auditor", Permissions: []domain.Permission{{Resource: "roles", Action: "read"}}},
+				},
+			}, []string{"*:*", "roles:read"}, nil
+		}
+		if wildcardHandler.wouldLockOutRoleMutation(42, 101, "roles:write", nil, nil) {
+			t.Fatal("did not expect lockout while *:* still grants roles:write")
+		}
+		if !wildcardHandler.wouldLockOutRoleMutation(42, 100, "roles:write", []string{"roles:read"}, nil) {
+			t.Fatal("expected lockout when the wildcard role is narrowed")
+		}
+		if wildcardHandler.wouldLockOutRoleMutation(42, 100, "roles:write", []string{"roles:*"}, nil) {
+			t.Fatal("did not expect lockout when roles:* still grants roles:write")
+		}
+		if !wildcardHandler.wouldLockOutRoleDeletion(42, 100, "roles:write") {
+			t.Fatal("expected lockout when deleting the wildcard role")
+		}
+		if !wildcardHandler.wouldLockOutPermissionDeletion(42, "*:*", "permissions:write") {
+			t.Fatal("expected lockout when deleting the only wildcard grant")
+		}
+		if wildcardHandler.wouldLockOutPermissionMutation(42, "*:*", "permissions:*", "permissions:write") {
+			t.Fatal("did not expect lockout when the wildcard is narrowed but still covers the route")
+		}
+	})
+
+	t.Run("lockout checks follow inherited roles", func(t *testing.T) {
+		inheritHandler, _, _, _, inheritRoles, _, inheritSvc := newAdminHandlerFixture()
+		inheritSvc.getByIDFn = func(id uint) (*domain.User, []string, error) {
+			return &domain.User{
+				ID:    id,
+				Roles: []domain.Role{{ID: 200, Name: "support-lead"}},
+			}, []string{"roles:write"}, nil
+		}
+		inheritRoles.listFn = func() ([]domain.Role, error) {
+			return []domain.Role{
+				{ID: 200, Name: "support-lead", Parents: []domain.Role{{ID: 201}}},
+				{ID: 201, Name: "support", Permissions: []domain.Permission{{Resource: "roles", Action: "write"}}},
+			}, nil
+		}
+		if !inheritHandler.wouldLockOutRoleMutation(42, 201, "roles:write", []string{"roles:read"}, nil) {
+			t.Fatal("expected lockout when a parent role loses the required permission")
+		}
+		if !inheritHandler.wouldLockOutRoleMutation(42, 200, "roles:write", nil, nil) {
+			t.Fatal("expected lockout when the actor's role stops inheriting")
+		}
+		if inheritHandler.wouldLockOutRoleMutation(42, 200, "roles:write", nil, []uint{201}) {
+			t.Fatal("did not expect lockout while the parent is kept")
+		}
+		if !inheritHandler.wouldLockOutRoleDeletion(42, 200, "roles:write") {
+			t.Fatal("expected lockout when deleting the role that inherits the permission")
+		}
+	})
+
 	t.Run("list endpoint parser failures", func(t *testing.T) {
 		cases := []struct {
 			name string
@@ -490,6 +699,7 @@ func TestAdminHandlerLockoutHelpersAndListParserFailures(t *testing.T) {
 			url  string
 		}{
 			{name: "list users invalid page", fn: h.ListUsers, url: "/api/v1/admin/users?page=0"},
+			{name: "list users unknown status", fn: h.ListUsers, url: "/api/v1/admin/users?status=banned"},
 			{name: "list roles invalid sort_order", fn: h.ListRoles, url: "/api/v1/admin/roles?sort_order=up"},
 			{name: "list permissions invalid sort_by", fn: h.ListPermissions, url: "/api/v1/admin/permissions?sort_by=nope"},
 		}
@@ -506,6 +716,50 @@ func TestAdminHandlerLockoutHelpersAndListParserFailures(t *testing.T) {
 	})
 }
 
+func TestAdminHandlerRoleHierarchyErrors(t *testing.T) {
+	h, _, _, _, roleRepo, _, _ := newAdminHandlerFixture()
+	roleRepo.rolesByID[300] = &domain.Role{ID: 300, Name: "support"}
+
+	roleRepo.updateFn = func(role *domain.Role, permissionIDs []uint) error { return repository.ErrRoleCycle }
+	req := withClaims(withURLParam(httptest.NewRequest(http.MethodPatch, "/api/v1/admin/roles/300", strings.NewReader(`{"parent_ids":[301]}`)), "id", "300"), "42")
+	rr := httptest.NewRecorder()
+	h.UpdateRole(rr, req)
+	if rr.Code != http.StatusConflict || !strings.Contains(rr.Body.String(), "cycle") {
+		t.Fatalf("expected 409 for a cycle, got %d body=%s", rr.Code, rr.Body.String())
+	}
+
+	roleRepo.createFn = func(role *domain.Role, permissionIDs []uint) error { return repository.ErrRoleParentNotFound }
+	req = withClaims(httptest.NewRequest(http.MethodPost, "/api/v1/admin/roles", strings.NewReader(`{"name":"lead","parent_ids":[999]}`)), "42")
+	rr = httptest.NewRecorder()
+	h.CreateRole(rr, req)
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for a missing parent, got %d body=%s", rr.Code, rr.Body.String())
+	}
+
+	roleRepo.deleteFn = func(id uint) error { return repository.ErrRoleHasChildren }
+	req = withClaims(withURLParam(httptest.NewRequest(http.MethodDelete, "/api/v1/admin/roles/300", nil), "id", "300"), "42")
+	rr = httptest.NewRecorder()
+	h.DeleteRole(rr, req)
+	if rr.Code != http.StatusConflict || !strings.Contains(rr.Body.String(), "inherited") {
+		t.Fatalf("expected 409 for a role with children, got %d body=%s", rr.Code, rr.Body.String())
+	}
+}
+
+func TestValidatePermissionPartsAcceptsWildcardsAndSubResources(t *testing.T) {
+	valid := [][2]string{{"users", "*"}, {"*", "read"}, {"*", "*"}, {" Billing.Invoices ", "read"}}
+	for _, parts := range valid {
+		if _, _, err := validatePermissionParts(parts[0], parts[1]); err != nil {
+			t.Fatalf("expected %q:%q to be valid, got %v", parts[0], parts[1], err)
+		}
+	}
+	invalid := [][2]string{{"billing.*", "read"}, {"users", "re*d"}, {"billing..invoices", "read"}, {".billing", "read"}, {"users", "read.all"}}
+	for _, parts := range invalid {
+		if _, _, err := validatePermissionParts(parts[0], parts[1]); err == nil {
+			t.Fatalf("expected %q:%q to be rejected", parts[0], parts[1])
+		}
+	}
+}
+
 func FuzzParseAdminListPageRequestRobustness(f *testing.F) {
 	f.Add("", "")
 	f.Add("1", "20")
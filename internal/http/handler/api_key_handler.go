@@ -0,0 +1,132 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/http/response"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/observability"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/service"
+)
+
+type APIKeyHandler struct {
+	apiKeySvc service.APIKeyServiceInterface
+}
+
+func NewAPIKeyHandler(apiKeySvc service.APIKeyServiceInterface) *APIKeyHandler {
+	return &APIKeyHandler{apiKeySvc: apiKeySvc}
+}
+
+type createAPIKeyRequest struct {
+	Name      string     `json:"name"`
+	Scopes    []string   `json:"scopes"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+func (h *APIKeyHandler) Create(w http.ResponseWriter, r *http.Request) {
+	outcome := "success"
+	defer func() {
+		observability.RecordAuthAPIKeyEvent(r.Context(), "create", outcome)
+	}()
+	userID, claims, err := authUserIDAndClaims(r)
+	if err != nil {
+		outcome = "unauthorized"
+		response.Error(w, r, http.StatusUnauthorized, "UNAUTHORIZED", "invalid user", nil)
+		return
+	}
+	actor := observability.ActorUserID(userID)
+	var req createAPIKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		outcome = "failure"
+		auditAuth(r, "auth.api_key.create", "create", "failure", "invalid_payload", actor, "user", actor)
+		response.Error(w, r, http.StatusBadRequest, "BAD_REQUEST", "invalid payload", nil)
+		return
+	}
+	input := service.APIKeyInput{Name: req.Name, Scopes: req.Scopes, ExpiresAt: req.ExpiresAt}
+	if claims.TokenType == service.APIKeyClaimsTokenType {
+		input.CallerKeyPermissions = append([]string{}, claims.Permissions...)
+	}
+	key, err := h.apiKeySvc.CreateKey(userID, input)
+	if err != nil {
+		outcome = "failure"
+		auditAuth(r, "auth.api_key.create", "create", "failure", "create_error", actor, "user", actor, "error", err.Error())
+		switch {
+		case errors.Is(err, service.ErrAPIKeysDisabled):
+			outcome = "not_enabled"
+			response.Error(w, r, http.StatusNotFound, "NOT_ENABLED", "api keys are disabled", nil)
+		case errors.Is(err, service.ErrInvalidAPIKeyRequest):
+			outcome = "invalid_request"
+			response.Error(w, r, http.StatusBadRequest, "BAD_REQUEST", "name and at least one scope are required; expires_at must be in the future", nil)
+		case errors.Is(err, service.ErrAPIKeyScopeNotAllowed):
+			outcome = "scope_not_allowed"
+			response.Error(w, r, http.StatusForbidden, "FORBIDDEN", "api key scopes must be a subset of your permissions", nil)
+		case errors.Is(err, service.ErrAPIKeyLimitReached):
+			outcome = "limit_reached"
+			response.Error(w, r, http.StatusConflict, "CONFLICT", "api key limit reached", nil)
+		default:
+			response.Error(w, r, http.StatusInternalServerError, "INTERNAL", "failed to create api key", nil)
+		}
+		return
+	}
+	auditAuth(r, "auth.api_key.create", "create", "success", "api_key_created", actor, "api_key", strconv.FormatUint(uint64(key.ID), 10), "scopes", key.Scopes)
+	response.JSON(w, r, http.StatusCreated, key)
+}
+
+func (h *APIKeyHandler) List(w http.ResponseWriter, r *http.Request) {
+	userID, _, err := authUserIDAndClaims(r)
+	if err != nil {
+		response.Error(w, r, http.StatusUnauthorized, "UNAUTHORIZED", "invalid user", nil)
+		return
+	}
+	keys, err := h.apiKeySvc.ListKeys(userID)
+	if err != nil {
+		response.Error(w, r, http.StatusInternalServerError, "INTERNAL", "failed to list api keys", nil)
+		return
+	}
+	response.JSON(w, r, http.StatusOK, keys)
+}
+
+func (h *APIKeyHandler) Revoke(w http.ResponseWriter, r *http.Request) {
+	outcome := "success"
+	defer func() {
+		observability.RecordAuthAPIKeyEvent(r.Context(), "revoke", outcome)
+	}()
+	userID, _, err := authUserIDAndClaims(r)
+	if err != nil {
+		outcome = "unauthorized"
+		response.Error(w, r, http.StatusUnauthorized, "UNAUTHORIZED", "invalid user", nil)
+		return
+	}
+	actor := observability.ActorUserID(userID)
+	rawID := chi.URLParam(r, "key_id")
+	keyID, err := strconv.ParseUint(rawID, 10, 64)
+	if err != nil {
+		outcome = "failure"
+		response.Error(w, r, http.StatusBadRequest, "BAD_REQUEST", "invalid api key id", nil)
+		return
+	}
+	changed, err := h.apiKeySvc.RevokeKey(userID, uint(keyID))
+	if err != nil {
+		if errors.Is(err, service.ErrAPIKeyNotFound) {
+			outcome = "not_found"
+			response.Error(w, r, http.StatusNotFound, "NOT_FOUND", "api key not found", nil)
+			return
+		}
+		outcome = "failure"
+		auditAuth(r, "auth.api_key.revoke", "revoke", "failure", "revoke_error", actor, "api_key", rawID, "error", err.Error())
+		response.Error(w, r, http.StatusInternalServerError, "INTERNAL", "failed to revoke api key", nil)
+		return
+	}
+	status := "revoked"
+	if !changed {
+		outcome = "already_revoked"
+		status = "already_revoked"
+	}
+	auditAuth(r, "auth.api_key.revoke", "revoke", "success", status, actor, "api_key", rawID)
+	response.JSON(w, r, http.StatusOK, map[string]any{"key_id": keyID, "status": status})
+}
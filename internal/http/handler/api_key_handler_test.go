@@ -0,0 +1,119 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/service"
+)
+
+type stubAPIKeyService struct {
+	createFn func(userID uint, input service.APIKeyInput) (*service.CreatedAPIKey, error)
+	revokeFn func(userID, keyID uint) (bool, error)
+}
+
+func (s *stubAPIKeyService) CreateKey(userID uint, input service.APIKeyInput) (*service.CreatedAPIKey, error) {
+	if s.createFn != nil {
+		return s.createFn(userID, input)
+	}
+	return nil, service.ErrAPIKeysDisabled
+}
+
+func (s *stubAPIKeyService) ListKeys(uint) ([]service.APIKeyView, error) {
+	return nil, nil
+}
+
+func (s *stubAPIKeyService) RevokeKey(userID, keyID uint) (bool, error) {
+	if s.revokeFn != nil {
+		return s.revokeFn(userID, keyID)
+	}
+	return true, nil
+}
+
+func TestAPIKeyHandlerCreateErrorMappings(t *testing.T) {
+	cases := []struct {
+		name     string
+		err      error
+		wantCode int
+		wantErr  string
+	}{
+		{name: "not enabled", err: service.ErrAPIKeysDisabled, wantCode: http.StatusNotFound, wantErr: "NOT_ENABLED"},
+		{name: "invalid request", err: service.ErrInvalidAPIKeyRequest, wantCode: http.StatusBadRequest, wantErr: "BAD_REQUEST"},
+		{name: "scope not allowed", err: service.ErrAPIKeyScopeNotAllowed, wantCode: http.StatusForbidden, wantErr: "FORBIDDEN"},
+		{name: "limit reached", err: service.ErrAPIKeyLimitReached, wantCode: http.StatusConflict, wantErr: "CONFLICT"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := &stubAPIKeyService{
+				createFn: func(uint, service.APIKeyInput) (*service.CreatedAPIKey, error) { return nil, tc.err },
+			}
+			h := NewAPIKeyHandler(svc)
+			req := withClaims(httptest.NewRequest(http.MethodPost, "/api/v1/me/api-keys", strings.NewReader(`{"name":"ci","scopes":["users:read"]}`)), "9")
+			rr := httptest.NewRecorder()
+
+			h.Create(rr, req)
+
+			if rr.Code != tc.wantCode {
+				t.Fatalf("expected %d, got %d", tc.wantCode, rr.Code)
+			}
+			if env := decodeAuthErrorEnvelope(t, rr); env.Error == nil || env.Error.Code != tc.wantErr {
+				t.Fatalf("expected error code %q, got %+v", tc.wantErr, env.Error)
+			}
+		})
+	}
+}
+
+func TestAPIKeyHandlerCreateReturnsKeyOnce(t *testing.T) {
+	var got service.APIKeyInput
+	svc := &stubAPIKeyService{
+		createFn: func(userID uint, input service.APIKeyInput) (*service.CreatedAPIKey, error) {
+			got = input
+			return &service.CreatedAPIKey{APIKeyView: service.APIKeyView{ID: 1, Name: input.Name, Scopes: input.Scopes}, Key: "ebsk_abcd1234_secret"}, nil
+		},
+	}
+	h := NewAPIKeyHandler(svc)
+	req := withClaims(httptest.NewRequest(http.MethodPost, "/api/v1/me/api-keys", strings.NewReader(`{"name":"ci","scopes":["users:read"],"expires_at":"2030-01-01T00:00:00Z"}`)), "9")
+	rr := httptest.NewRecorder()
+
+	h.Create(rr, req)
+
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", rr.Code)
+	}
+	if got.Name != "ci" || got.ExpiresAt == nil || len(got.Scopes) != 1 {
+		t.Fatalf("unexpected service input: %+v", got)
+	}
+	if !strings.Contains(rr.Body.String(), `"key":"ebsk_abcd1234_secret"`) {
+		t.Fatalf("expected plaintext key in create response, got %s", rr.Body.String())
+	}
+}
+
+func TestAPIKeyHandlerRevokeNotFound(t *testing.T) {
+	var gotUser, gotKey uint
+	svc := &stubAPIKeyService{
+		revokeFn: func(userID, keyID uint) (bool, error) {
+			gotUser, gotKey = userID, keyID
+			return false, service.ErrAPIKeyNotFound
+		},
+	}
+	h := NewAPIKeyHandler(svc)
+	req := withClaims(httptest.NewRequest(http.MethodDelete, "/api/v1/me/api-keys/12", nil), "9")
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("key_id", "12")
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+	rr := httptest.NewRecorder()
+
+	h.Revoke(rr, req)
+
+	if rr.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", rr.Code)
+	}
+	if gotUser != 9 || gotKey != 12 {
+		t.Fatalf("expected revoke scoped to user 9 key 12, got user=%d key=%d", gotUser, gotKey)
+	}
+}
@@ -5,9 +5,13 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/go-chi/chi/v5"
+
 	"github.com/sandeepkv93/everything-backend-starter-kit/internal/http/middleware"
 	"github.com/sandeepkv93/everything-backend-starter-kit/internal/http/response"
 	"github.com/sandeepkv93/everything-backend-starter-kit/internal/observability"
@@ -15,6 +19,8 @@ import (
 	"github.com/sandeepkv93/everything-backend-starter-kit/internal/service"
 )
 
+var languageTagPattern = regexp.MustCompile(`^[a-zA-Z]{2,8}(-[a-zA-Z0-9]{1,8})*$`)
+
 type AuthHandler struct {
 	authSvc     service.AuthServiceInterface
 	abuseGuard  service.AuthAbuseGuard
@@ -46,80 +52,287 @@ func NewAuthHandler(
 }
 
 func (h *AuthHandler) GoogleLogin(w http.ResponseWriter, r *http.Request) {
+	h.oauthLogin(w, r, "google", "google")
+}
+
+func (h *AuthHandler) GoogleCallback(w http.ResponseWriter, r *http.Request) {
+	h.oauthCallback(w, r, "google", "google")
+}
+
+func (h *AuthHandler) OAuthLogin(w http.ResponseWriter, r *http.Request) {
+	h.oauthLogin(w, r, strings.ToLower(chi.URLParam(r, "provider")), "oauth")
+}
+
+func (h *AuthHandler) OAuthCallback(w http.ResponseWriter, r *http.Request) {
+	h.oauthCallback(w, r, strings.ToLower(chi.URLParam(r, "provider")), "oauth")
+}
+
+// oauthLogin and oauthCallback serve both the legacy Google routes (flow
+// "google") and the generic /auth/{provider} routes (flow "oauth"); the flow
+// picks the audit event and request-duration endpoint names.
+func (h *AuthHandler) oauthLogin(w http.ResponseWriter, r *http.Request, provider, flow string) {
 	start := time.Now()
 	status := "success"
+	providerLabel := "unknown"
+	eventName := "auth." + flow + ".login"
 	defer func() {
-		observability.RecordAuthRequestDuration(r.Context(), "google_login", status, time.Since(start))
+		observability.RecordAuthRequestDuration(r.Context(), flow+"_login", status, time.Since(start))
 	}()
 
-	state, err := security.NewRandomString(24)
+	if !h.authSvc.OAuthProviderEnabled(provider) {
+		status = "failure"
+		auditAuth(r, eventName, "oauth_login", "rejected", "provider_disabled", "anonymous", "auth_provider", provider)
+		observability.RecordAuthLogin(r.Context(), providerLabel, "failure")
+		response.Error(w, r, http.StatusNotFound, "NOT_ENABLED", fmt.Sprintf("%s auth is disabled", provider), nil)
+		return
+	}
+	providerLabel = provider
+
+	oauthFlow, err := security.NewOAuthFlowState(provider)
 	if err != nil {
 		status = "failure"
-		auditAuth(r, "auth.google.login", "oauth_login", "failure", "state_generation", "anonymous", "auth_provider", "google")
-		observability.RecordAuthLogin(r.Context(), "google", "failure")
+		auditAuth(r, eventName, "oauth_login", "failure", "state_generation", "anonymous", "auth_provider", provider)
+		observability.RecordAuthLogin(r.Context(), providerLabel, "failure")
 		response.Error(w, r, http.StatusInternalServerError, "INTERNAL", "failed to generate oauth state", nil)
 		return
 	}
-	loginURL := h.authSvc.GoogleLoginURL(state)
-	if loginURL == "" {
+	loginURL, err := h.authSvc.OAuthLoginURL(oauthFlow)
+	if err != nil {
 		status = "failure"
-		auditAuth(r, "auth.google.login", "oauth_login", "rejected", "provider_disabled", "anonymous", "auth_provider", "google")
-		observability.RecordAuthLogin(r.Context(), "google", "failure")
-		response.Error(w, r, http.StatusNotFound, "NOT_ENABLED", "google auth is disabled", nil)
+		observability.RecordAuthLogin(r.Context(), providerLabel, "failure")
+		switch {
+		case errors.Is(err, service.ErrGoogleAuthDisabled), errors.Is(err, service.ErrOAuthProviderNotFound):
+			auditAuth(r, eventName, "oauth_login", "rejected", "provider_disabled", "anonymous", "auth_provider", provider)
+			response.Error(w, r, http.StatusNotFound, "NOT_ENABLED", fmt.Sprintf("%s auth is disabled", provider), nil)
+		default:
+			auditAuth(r, eventName, "oauth_login", "failure", "provider_unavailable", "anonymous", "auth_provider", provider)
+			response.Error(w, r, http.StatusServiceUnavailable, "PROVIDER_UNAVAILABLE", "oauth provider is unavailable", nil)
+		}
 		return
 	}
-	signed := security.SignState(state, h.stateKey)
-	http.SetCookie(w, &http.Cookie{Name: "oauth_state", Value: signed, Path: "/api/v1/auth/google", HttpOnly: true, Secure: h.cookieMgr.Secure, SameSite: h.cookieMgr.SameSite, Domain: h.cookieMgr.Domain, MaxAge: 300})
-	auditAuth(r, "auth.google.login", "oauth_login", "success", "redirect_issued", "anonymous", "auth_provider", "google")
+	signed := security.SignOAuthFlowState(oauthFlow, h.stateKey)
+	http.SetCookie(w, &http.Cookie{Name: "oauth_state", Value: signed, Path: oauthStateCookiePath(provider), HttpOnly: true, Secure: h.cookieMgr.Secure, SameSite: h.cookieMgr.SameSite, Domain: h.cookieMgr.Domain, MaxAge: 300})
+	auditAuth(r, eventName, "oauth_login", "success", "redirect_issued", "anonymous", "auth_provider", provider)
 	http.Redirect(w, r, loginURL, http.StatusFound)
 }
 
-func (h *AuthHandler) GoogleCallback(w http.ResponseWriter, r *http.Request) {
+func (h *AuthHandler) oauthCallback(w http.ResponseWriter, r *http.Request, provider, flow string) {
 	start := time.Now()
 	status := "success"
+	providerLabel := "unknown"
+	eventName := "auth." + flow + ".callback"
 	defer func() {
-		observability.RecordAuthRequestDuration(r.Context(), "google_callback", status, time.Since(start))
+		observability.RecordAuthRequestDuration(r.Context(), flow+"_callback", status, time.Since(start))
 	}()
 
+	if h.authSvc.OAuthProviderEnabled(provider) {
+		providerLabel = provider
+	}
 	queryState := r.URL.Query().Get("state")
 	code := r.URL.Query().Get("code")
 	if queryState == "" || code == "" {
 		status = "failure"
-		auditAuth(r, "auth.google.callback", "oauth_callback", "failure", "missing_code_or_state", "anonymous", "auth_provider", "google")
-		observability.RecordAuthLogin(r.Context(), "google", "failure")
+		observability.RecordOAuthError(r.Context(), providerLabel, "callback_params_missing")
+		auditAuth(r, eventName, "oauth_callback", "failure", "missing_code_or_state", "anonymous", "auth_provider", provider)
+		observability.RecordAuthLogin(r.Context(), providerLabel, "failure")
 		response.Error(w, r, http.StatusBadRequest, "BAD_REQUEST", "missing state or code", nil)
 		return
 	}
 	stateCookie := security.GetCookie(r, "oauth_state")
-	state, ok := security.VerifySignedState(stateCookie, h.stateKey)
-	if !ok || state != queryState {
+	oauthFlow, ok := security.VerifyOAuthFlowState(stateCookie, h.stateKey)
+	if !ok || oauthFlow.State != queryState || oauthFlow.Provider != provider {
 		status = "failure"
-		auditAuth(r, "auth.google.callback", "oauth_callback", "failure", "invalid_state", "anonymous", "auth_provider", "google")
-		observability.RecordAuthLogin(r.Context(), "google", "failure")
+		errorClass := "state_invalid"
+		if ok {
+			errorClass = "state_mismatch"
+		}
+		observability.RecordOAuthError(r.Context(), providerLabel, errorClass)
+		auditAuth(r, eventName, "oauth_callback", "failure", "invalid_state", "anonymous", "auth_provider", provider)
+		observability.RecordAuthLogin(r.Context(), providerLabel, "failure")
 		response.Error(w, r, http.StatusUnauthorized, "UNAUTHORIZED", "invalid oauth state", nil)
 		return
 	}
 	// Invalidate one-time state immediately after successful verification.
-	http.SetCookie(w, &http.Cookie{Name: "oauth_state", Value: "", Path: "/api/v1/auth/google", MaxAge: -1, HttpOnly: true, Secure: h.cookieMgr.Secure, SameSite: h.cookieMgr.SameSite, Domain: h.cookieMgr.Domain})
+	http.SetCookie(w, &http.Cookie{Name: "oauth_state", Value: "", Path: oauthStateCookiePath(provider), MaxAge: -1, HttpOnly: true, Secure: h.cookieMgr.Secure, SameSite: h.cookieMgr.SameSite, Domain: h.cookieMgr.Domain})
+	if oauthFlow.LinkUserID != 0 {
+		if !h.linkIdentityCallback(w, r, provider, oauthFlow, code) {
+			status = "failure"
+		}
+		return
+	}
+	if oauthFlow.ReauthUserID != 0 {
+		if !h.reauthCallback(w, r, provider, oauthFlow, code) {
+			status = "failure"
+		}
+		return
+	}
 
-	result, err := h.authSvc.LoginWithGoogleCode(code, r.UserAgent(), clientIP(r))
+	result, err := h.authSvc.LoginWithOAuthCode(oauthFlow, code, r.UserAgent(), clientIP(r))
 	if err != nil {
 		status = "failure"
-		if errors.Is(err, service.ErrGoogleAuthDisabled) {
-			response.Error(w, r, http.StatusNotFound, "NOT_ENABLED", "google auth is disabled", nil)
+		if errors.Is(err, service.ErrGoogleAuthDisabled) || errors.Is(err, service.ErrOAuthProviderNotFound) {
+			response.Error(w, r, http.StatusNotFound, "NOT_ENABLED", fmt.Sprintf("%s auth is disabled", provider), nil)
+			return
+		}
+		if errors.Is(err, service.ErrOAuthLinkRequired) {
+			auditAuth(r, eventName, "oauth_callback", "rejected", "link_required", "anonymous", "auth_provider", provider)
+			observability.RecordAuthLogin(r.Context(), providerLabel, "failure")
+			response.Error(w, r, http.StatusConflict, "LINK_REQUIRED", err.Error(), nil)
+			return
+		}
+		if errors.Is(err, service.ErrAccountInactive) {
+			auditAuth(r, eventName, "oauth_callback", "rejected", "account_inactive", "anonymous", "auth_provider", provider)
+			observability.RecordAuthLogin(r.Context(), providerLabel, "failure")
+			response.Error(w, r, http.StatusForbidden, "ACCOUNT_INACTIVE", "account is not active", nil)
 			return
 		}
-		auditAuth(r, "auth.google.callback", "oauth_callback", "failure", "oauth_exchange_error", "anonymous", "auth_provider", "google", "error", err.Error())
-		observability.RecordAuthLogin(r.Context(), "google", "failure")
+		reason := "oauth_exchange_error"
+		if errors.Is(err, service.ErrOAuthNonceMissing) || errors.Is(err, service.ErrOAuthNonceMismatch) {
+			reason = "nonce_mismatch"
+		}
+		auditAuth(r, eventName, "oauth_callback", "failure", reason, "anonymous", "auth_provider", provider, "error", err.Error())
+		observability.RecordAuthLogin(r.Context(), providerLabel, "failure")
 		response.Error(w, r, http.StatusUnauthorized, "OAUTH_FAILED", err.Error(), nil)
 		return
 	}
+	if result.MFARequired {
+		auditAuth(r, "auth.login", "login", "accepted", "mfa_required", observability.ActorUserID(result.User.ID), "user", observability.ActorUserID(result.User.ID), "provider", provider)
+		writeMFAChallenge(w, r, result)
+		return
+	}
 	h.cookieMgr.SetTokenCookies(w, result.AccessToken, result.RefreshToken, result.CSRFToken, h.refreshTTL)
-	auditAuth(r, "auth.login", "login", "success", "oauth_google", observability.ActorUserID(result.User.ID), "user", observability.ActorUserID(result.User.ID), "provider", "google")
-	observability.RecordAuthLogin(r.Context(), "google", "success")
+	auditAuth(r, "auth.login", "login", "success", "oauth_"+provider, observability.ActorUserID(result.User.ID), "user", observability.ActorUserID(result.User.ID), "provider", provider)
+	observability.RecordAuthLogin(r.Context(), providerLabel, "success")
 	response.JSON(w, r, http.StatusOK, map[string]any{"user": result.User, "csrf_token": result.CSRFToken, "expires_at": result.ExpiresAt})
 }
 
+func (h *AuthHandler) Identities(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.subjectUserID(w, r)
+	if !ok {
+		observability.RecordAuthIdentityEvent(r.Context(), "list", "unauthorized")
+		return
+	}
+	linked, err := h.authSvc.ListIdentities(userID)
+	if err != nil {
+		observability.RecordAuthIdentityEvent(r.Context(), "list", "failure")
+		response.Error(w, r, http.StatusInternalServerError, "INTERNAL", "failed to list identities", nil)
+		return
+	}
+	observability.RecordAuthIdentityEvent(r.Context(), "list", "success")
+	response.JSON(w, r, http.StatusOK, linked)
+}
+
+// LinkIdentity starts an OAuth flow whose callback attaches the provider
+// identity to the caller instead of logging in. It answers with the
+// authorization URL rather than a redirect because it is a CSRF-checked POST.
+func (h *AuthHandler) LinkIdentity(w http.ResponseWriter, r *http.Request) {
+	outcome := "success"
+	defer func() {
+		observability.RecordAuthIdentityEvent(r.Context(), "link_begin", outcome)
+	}()
+	userID, ok := h.subjectUserID(w, r)
+	if !ok {
+		outcome = "unauthorized"
+		return
+	}
+	provider := strings.ToLower(chi.URLParam(r, "provider"))
+	actor := observability.ActorUserID(userID)
+	if !h.authSvc.OAuthProviderEnabled(provider) {
+		outcome = "not_enabled"
+		response.Error(w, r, http.StatusNotFound, "NOT_ENABLED", fmt.Sprintf("%s auth is disabled", provider), nil)
+		return
+	}
+	oauthFlow, err := security.NewOAuthFlowState(provider)
+	if err != nil {
+		outcome = "failure"
+		response.Error(w, r, http.StatusInternalServerError, "INTERNAL", "failed to generate oauth state", nil)
+		return
+	}
+	oauthFlow.LinkUserID = userID
+	loginURL, err := h.authSvc.OAuthLoginURL(oauthFlow)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrGoogleAuthDisabled), errors.Is(err, service.ErrOAuthProviderNotFound):
+			outcome = "not_enabled"
+			response.Error(w, r, http.StatusNotFound, "NOT_ENABLED", fmt.Sprintf("%s auth is disabled", provider), nil)
+		default:
+			outcome = "failure"
+			response.Error(w, r, http.StatusServiceUnavailable, "PROVIDER_UNAVAILABLE", "oauth provider is unavailable", nil)
+		}
+		return
+	}
+	signed := security.SignOAuthFlowState(oauthFlow, h.stateKey)
+	http.SetCookie(w, &http.Cookie{Name: "oauth_state", Value: signed, Path: oauthStateCookiePath(provider), HttpOnly: true, Secure: h.cookieMgr.Secure, SameSite: h.cookieMgr.SameSite, Domain: h.cookieMgr.Domain, MaxAge: 300})
+	auditAuth(r, "auth.identity.link", "link_begin", "success", "redirect_issued", actor, "auth_provider", provider)
+	response.JSON(w, r, http.StatusOK, map[string]any{"provider": provider, "authorization_url": loginURL})
+}
+
+func (h *AuthHandler) UnlinkIdentity(w http.ResponseWriter, r *http.Request) {
+	outcome := "success"
+	defer func() {
+		observability.RecordAuthIdentityEvent(r.Context(), "unlink", outcome)
+	}()
+	userID, ok := h.subjectUserID(w, r)
+	if !ok {
+		outcome = "unauthorized"
+		return
+	}
+	actor := observability.ActorUserID(userID)
+	rawID := chi.URLParam(r, "identity_id")
+	identityID, err := strconv.ParseUint(rawID, 10, 64)
+	if err != nil {
+		outcome = "failure"
+		response.Error(w, r, http.StatusBadRequest, "BAD_REQUEST", "invalid identity id", nil)
+		return
+	}
+	if err := h.authSvc.UnlinkIdentity(userID, uint(identityID)); err != nil {
+		switch {
+		case errors.Is(err, service.ErrIdentityNotFound):
+			outcome = "not_found"
+			response.Error(w, r, http.StatusNotFound, "NOT_FOUND", "identity not found", nil)
+		case errors.Is(err, service.ErrLastCredential):
+			outcome = "last_credential"
+			auditAuth(r, "auth.identity.unlink", "unlink", "rejected", "last_credential", actor, "oauth_account", rawID)
+			response.Error(w, r, http.StatusConflict, "LAST_CREDENTIAL", "cannot remove the last sign-in method", nil)
+		default:
+			outcome = "failure"
+			auditAuth(r, "auth.identity.unlink", "unlink", "failure", "unlink_error", actor, "oauth_account", rawID, "error", err.Error())
+			response.Error(w, r, http.StatusInternalServerError, "INTERNAL", "failed to unlink identity", nil)
+		}
+		return
+	}
+	auditAuth(r, "auth.identity.unlink", "unlink", "success", "identity_unlinked", actor, "oauth_account", rawID)
+	response.JSON(w, r, http.StatusOK, map[string]any{"identity_id": identityID, "status": "unlinked"})
+}
+
+func (h *AuthHandler) linkIdentityCallback(w http.ResponseWriter, r *http.Request, provider string, flow security.OAuthFlowState, code string) bool {
+	outcome := "success"
+	defer func() {
+		observability.RecordAuthIdentityEvent(r.Context(), "link", outcome)
+	}()
+	actor := observability.ActorUserID(flow.LinkUserID)
+	account, err := h.authSvc.LinkOAuthIdentity(flow, code)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrGoogleAuthDisabled), errors.Is(err, service.ErrOAuthProviderNotFound):
+			outcome = "not_enabled"
+			response.Error(w, r, http.StatusNotFound, "NOT_ENABLED", fmt.Sprintf("%s auth is disabled", provider), nil)
+		case errors.Is(err, service.ErrOAuthIdentityLinkedElsewhere):
+			outcome = "linked_elsewhere"
+			auditAuth(r, "auth.identity.link", "link", "rejected", "linked_elsewhere", actor, "auth_provider", provider)
+			response.Error(w, r, http.StatusConflict, "CONFLICT", "this identity is linked to another account", nil)
+		default:
+			outcome = "failure"
+			auditAuth(r, "auth.identity.link", "link", "failure", "oauth_exchange_error", actor, "auth_provider", provider, "error", err.Error())
+			response.Error(w, r, http.StatusUnauthorized, "OAUTH_FAILED", err.Error(), nil)
+		}
+		return false
+	}
+	auditAuth(r, "auth.identity.link", "link", "success", "identity_linked", actor, "oauth_account", strconv.FormatUint(uint64(account.ID), 10), "provider", provider)
+	response.JSON(w, r, http.StatusOK, map[string]any{"status": "linked", "identity": account})
+	return true
+}
+
 func (h *AuthHandler) Refresh(w http.ResponseWriter, r *http.Request) {
 	start := time.Now()
 	status := "success"
@@ -144,6 +357,12 @@ func (h *AuthHandler) Refresh(w http.ResponseWriter, r *http.Request) {
 			reason = "refresh_reuse_detected"
 			metricStatus = "reuse_detected"
 		}
+		if errors.Is(err, service.ErrAccountInactive) {
+			auditAuth(r, "auth.refresh", "refresh", "rejected", "account_inactive", "anonymous", "session", "unknown")
+			observability.RecordAuthRefresh(r.Context(), "account_inactive")
+			response.Error(w, r, http.StatusForbidden, "ACCOUNT_INACTIVE", "account is not active", nil)
+			return
+		}
 		auditAuth(r, "auth.refresh", "refresh", "failure", reason, "anonymous", "session", "unknown")
 		observability.RecordAuthRefresh(r.Context(), metricStatus)
 		response.Error(w, r, http.StatusUnauthorized, "UNAUTHORIZED", "invalid refresh token", nil)
@@ -218,7 +437,7 @@ func (h *AuthHandler) LocalRegister(w http.ResponseWriter, r *http.Request) {
 		case errors.Is(err, service.ErrLocalAuthDisabled):
 			response.Error(w, r, http.StatusNotFound, "NOT_ENABLED", "local auth is disabled", nil)
 		case errors.Is(err, service.ErrWeakPassword):
-			response.Error(w, r, http.StatusBadRequest, "BAD_REQUEST", "password must be 12+ chars and include upper, lower, number, and special char", nil)
+			writeWeakPassword(w, r, err)
 		default:
 			response.Error(w, r, http.StatusBadRequest, "BAD_REQUEST", err.Error(), nil)
 		}
@@ -293,6 +512,10 @@ func (h *AuthHandler) LocalLogin(w http.ResponseWriter, r *http.Request) {
 			response.Error(w, r, http.StatusNotFound, "NOT_ENABLED", "local auth is disabled", nil)
 		case errors.Is(err, service.ErrLocalEmailUnverified):
 			response.Error(w, r, http.StatusForbidden, "EMAIL_UNVERIFIED", "email verification required", nil)
+		case errors.Is(err, service.ErrPasswordExpired):
+			response.Error(w, r, http.StatusForbidden, "PASSWORD_EXPIRED", "password has expired; reset it to sign in", nil)
+		case errors.Is(err, service.ErrAccountInactive):
+			response.Error(w, r, http.StatusForbidden, "ACCOUNT_INACTIVE", "account is not active", nil)
 		case errors.Is(err, service.ErrInvalidCredentials):
 			response.Error(w, r, http.StatusUnauthorized, "UNAUTHORIZED", "invalid credentials", nil)
 		default:
@@ -305,6 +528,11 @@ func (h *AuthHandler) LocalLogin(w http.ResponseWriter, r *http.Request) {
 			auditAuth(r, "auth.local.login", "login", "failure", "abuse_reset_error", observability.ActorUserID(result.User.ID), "user", observability.ActorUserID(result.User.ID), "error", err.Error())
 		}
 	}
+	if result.MFARequired {
+		auditAuth(r, "auth.local.login", "login", "accepted", "mfa_required", observability.ActorUserID(result.User.ID), "user", observability.ActorUserID(result.User.ID))
+		writeMFAChallenge(w, r, result)
+		return
+	}
 	h.cookieMgr.SetTokenCookies(w, result.AccessToken, result.RefreshToken, result.CSRFToken, h.refreshTTL)
 	auditAuth(r, "auth.local.login", "login", "success", "credentials_valid", observability.ActorUserID(result.User.ID), "user", observability.ActorUserID(result.User.ID))
 	observability.RecordAuthLogin(r.Context(), "local", "success")
@@ -329,7 +557,7 @@ func (h *AuthHandler) LocalVerifyRequest(w http.ResponseWriter, r *http.Request)
 		response.Error(w, r, http.StatusBadRequest, "BAD_REQUEST", "invalid payload", nil)
 		return
 	}
-	if err := h.authSvc.RequestLocalEmailVerification(req.Email); err != nil {
+	if err := h.authSvc.RequestLocalEmailVerification(req.Email, requestLocale(r)); err != nil {
 		status = "failure"
 		flowOutcome = "failure"
 		auditAuth(r, "auth.local.verify.request", "verify_request", "failure", "service_error", "anonymous", "user", "unknown", "error", err.Error())
@@ -426,7 +654,7 @@ func (h *AuthHandler) LocalPasswordForgot(w http.ResponseWriter, r *http.Request
 			return
 		}
 	}
-	if err := h.authSvc.ForgotLocalPassword(req.Email); err != nil {
+	if err := h.authSvc.ForgotLocalPassword(req.Email, requestLocale(r)); err != nil {
 		status = "failure"
 		flowOutcome = "failure"
 		auditAuth(r, "auth.local.password.forgot", "password_forgot", "failure", "service_error", "anonymous", "user", "unknown", "error", err.Error())
@@ -489,7 +717,7 @@ func (h *AuthHandler) LocalPasswordReset(w http.ResponseWriter, r *http.Request)
 			response.Error(w, r, http.StatusNotFound, "NOT_ENABLED", "local auth is disabled", nil)
 		case errors.Is(err, service.ErrWeakPassword):
 			flowOutcome = "weak_password"
-			response.Error(w, r, http.StatusBadRequest, "BAD_REQUEST", "password must be 12+ chars and include upper, lower, number, and special char", nil)
+			writeWeakPassword(w, r, err)
 		case errors.Is(err, service.ErrInvalidVerifyToken):
 			flowOutcome = "invalid_token"
 			response.Error(w, r, http.StatusBadRequest, "INVALID_OR_EXPIRED_TOKEN", "invalid or expired token", nil)
@@ -502,6 +730,130 @@ func (h *AuthHandler) LocalPasswordReset(w http.ResponseWriter, r *http.Request)
 	response.JSON(w, r, http.StatusOK, map[string]string{"status": "password_reset"})
 }
 
+func (h *AuthHandler) MagicLinkRequest(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	status := "success"
+	magicOutcome := "accepted"
+	defer func() {
+		observability.RecordAuthRequestDuration(r.Context(), "magic_link_request", status, time.Since(start))
+		observability.RecordAuthMagicLinkEvent(r.Context(), "request", magicOutcome)
+	}()
+	var req struct {
+		Email string `json:"email"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		status = "failure"
+		magicOutcome = "bad_request"
+		auditAuth(r, "auth.magic.request", "magic_link_request", "failure", "invalid_payload", "anonymous", "user", "unknown")
+		response.Error(w, r, http.StatusBadRequest, "BAD_REQUEST", "invalid payload", nil)
+		return
+	}
+	bypassAuthAbuse, bypassReason := h.shouldBypassAuthAbuse(r)
+	if bypassAuthAbuse {
+		observability.RecordSecurityBypassEvent(r.Context(), normalizeBypassReason(bypassReason), "auth.magic_link")
+		observability.RecordAuthAbuseGuardEvent(r.Context(), string(service.AuthAbuseScopeMagicLink), "check", "bypass")
+		auditAuth(r, "auth.magic.request", "magic_link_request", "accepted", "abuse_bypass_"+bypassReason, "anonymous", "user", "unknown")
+	} else {
+		retryAfter, err := h.abuseGuard.Check(r.Context(), service.AuthAbuseScopeMagicLink, req.Email, clientIP(r))
+		if err != nil {
+			status = "failure"
+			magicOutcome = "rate_limited"
+			auditAuth(r, "auth.magic.request", "magic_link_request", "failure", "abuse_check_error", "anonymous", "user", "unknown", "error", err.Error())
+			writeAbuseCooldownHeaders(w, retryAfter)
+			response.Error(w, r, http.StatusTooManyRequests, "RATE_LIMITED", "too many requests", nil)
+			return
+		}
+		if retryAfter > 0 {
+			status = "failure"
+			magicOutcome = "rate_limited"
+			auditAuth(r, "auth.magic.request", "magic_link_request", "rejected", "abuse_cooldown", "anonymous", "user", "unknown")
+			writeAbuseCooldownHeaders(w, retryAfter)
+			response.Error(w, r, http.StatusTooManyRequests, "RATE_LIMITED", "too many requests", nil)
+			return
+		}
+	}
+	if err := h.authSvc.RequestMagicLink(req.Email, requestLocale(r)); err != nil {
+		status = "failure"
+		magicOutcome = "failure"
+		auditAuth(r, "auth.magic.request", "magic_link_request", "failure", "service_error", "anonymous", "user", "unknown", "error", err.Error())
+		switch {
+		case errors.Is(err, service.ErrMagicLinkDisabled):
+			magicOutcome = "not_enabled"
+			response.Error(w, r, http.StatusNotFound, "NOT_ENABLED", "magic link sign-in is disabled", nil)
+		default:
+			response.Error(w, r, http.StatusInternalServerError, "INTERNAL", "magic link request failed", nil)
+		}
+		return
+	}
+	// Every accepted request counts toward the cooldown, whether or not the
+	// account exists, so the limiter itself reveals nothing.
+	if !bypassAuthAbuse {
+		if retryAfter, err := h.abuseGuard.RegisterFailure(r.Context(), service.AuthAbuseScopeMagicLink, req.Email, clientIP(r)); err != nil {
+			status = "failure"
+			magicOutcome = "rate_limited"
+			auditAuth(r, "auth.magic.request", "magic_link_request", "failure", "abuse_record_error", "anonymous", "user", "unknown", "error", err.Error())
+			writeAbuseCooldownHeaders(w, retryAfter)
+			response.Error(w, r, http.StatusTooManyRequests, "RATE_LIMITED", "too many requests", nil)
+			return
+		}
+	}
+	auditAuth(r, "auth.magic.request", "magic_link_request", "accepted", "link_requested", "anonymous", "user", "unknown")
+	response.JSON(w, r, http.StatusOK, map[string]string{"status": "if the account exists, a sign-in link was sent"})
+}
+
+func (h *AuthHandler) MagicLinkConfirm(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	status := "success"
+	magicOutcome := "success"
+	defer func() {
+		observability.RecordAuthRequestDuration(r.Context(), "magic_link_confirm", status, time.Since(start))
+		observability.RecordAuthMagicLinkEvent(r.Context(), "confirm", magicOutcome)
+	}()
+	var req struct {
+		Token string `json:"token"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		status = "failure"
+		magicOutcome = "bad_request"
+		auditAuth(r, "auth.magic.confirm", "magic_link_confirm", "failure", "invalid_payload", "anonymous", "magic_link_token", "unknown")
+		observability.RecordAuthLogin(r.Context(), "magic_link", "failure")
+		response.Error(w, r, http.StatusBadRequest, "BAD_REQUEST", "invalid payload", nil)
+		return
+	}
+	result, err := h.authSvc.ConfirmMagicLink(req.Token, r.UserAgent(), clientIP(r))
+	if err != nil {
+		status = "failure"
+		magicOutcome = "failure"
+		auditAuth(r, "auth.magic.confirm", "magic_link_confirm", "failure", "confirm_error", "anonymous", "magic_link_token", "unknown", "error", err.Error())
+		observability.RecordAuthLogin(r.Context(), "magic_link", "failure")
+		switch {
+		case errors.Is(err, service.ErrMagicLinkDisabled):
+			magicOutcome = "not_enabled"
+			response.Error(w, r, http.StatusNotFound, "NOT_ENABLED", "magic link sign-in is disabled", nil)
+		case errors.Is(err, service.ErrInvalidVerifyToken):
+			magicOutcome = "invalid_token"
+			response.Error(w, r, http.StatusUnauthorized, "INVALID_OR_EXPIRED_TOKEN", "invalid or expired token", nil)
+		case errors.Is(err, service.ErrAccountInactive):
+			magicOutcome = "account_inactive"
+			response.Error(w, r, http.StatusForbidden, "ACCOUNT_INACTIVE", "account is not active", nil)
+		default:
+			response.Error(w, r, http.StatusInternalServerError, "INTERNAL", "magic link sign-in failed", nil)
+		}
+		return
+	}
+	actor := observability.ActorUserID(result.User.ID)
+	if result.MFARequired {
+		magicOutcome = "mfa_required"
+		auditAuth(r, "auth.magic.confirm", "magic_link_confirm", "accepted", "mfa_required", actor, "user", actor)
+		writeMFAChallenge(w, r, result)
+		return
+	}
+	h.cookieMgr.SetTokenCookies(w, result.AccessToken, result.RefreshToken, result.CSRFToken, h.refreshTTL)
+	auditAuth(r, "auth.magic.confirm", "magic_link_confirm", "success", "token_consumed", actor, "user", actor)
+	observability.RecordAuthLogin(r.Context(), "magic_link", "success")
+	response.JSON(w, r, http.StatusOK, map[string]any{"user": result.User, "csrf_token": result.CSRFToken, "expires_at": result.ExpiresAt})
+}
+
 func (h *AuthHandler) LocalChangePassword(w http.ResponseWriter, r *http.Request) {
 	start := time.Now()
 	status := "success"
@@ -545,7 +897,7 @@ func (h *AuthHandler) LocalChangePassword(w http.ResponseWriter, r *http.Request
 			response.Error(w, r, http.StatusNotFound, "NOT_ENABLED", "local auth is disabled", nil)
 		case errors.Is(err, service.ErrWeakPassword):
 			flowOutcome = "weak_password"
-			response.Error(w, r, http.StatusBadRequest, "BAD_REQUEST", "password must be 12+ chars and include upper, lower, number, and special char", nil)
+			writeWeakPassword(w, r, err)
 		case errors.Is(err, service.ErrInvalidCredentials):
 			flowOutcome = "unauthorized"
 			response.Error(w, r, http.StatusUnauthorized, "UNAUTHORIZED", "invalid credentials", nil)
@@ -559,6 +911,403 @@ func (h *AuthHandler) LocalChangePassword(w http.ResponseWriter, r *http.Request
 	response.JSON(w, r, http.StatusOK, map[string]string{"status": "password_changed"})
 }
 
+// Reauth is the step-up endpoint behind REAUTH_REQUIRED. A password answers
+// directly; a provider answers with an authorization URL whose callback
+// completes the reauthentication. Either way only the access token and the
+// session's auth_time change.
+func (h *AuthHandler) Reauth(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.subjectUserID(w, r)
+	if !ok {
+		return
+	}
+	claims, _ := middleware.ClaimsFromContext(r.Context())
+	var req struct {
+		Password string `json:"password"`
+		Provider string `json:"provider"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, r, http.StatusBadRequest, "BAD_REQUEST", "invalid payload", nil)
+		return
+	}
+	switch {
+	case req.Password != "" && req.Provider == "":
+		h.reauthWithPassword(w, r, userID, claims.ID, req.Password)
+	case req.Provider != "" && req.Password == "":
+		h.beginOAuthReauth(w, r, userID, claims.ID, strings.ToLower(strings.TrimSpace(req.Provider)))
+	default:
+		response.Error(w, r, http.StatusBadRequest, "BAD_REQUEST", "exactly one of password or provider is required", nil)
+	}
+}
+
+func (h *AuthHandler) reauthWithPassword(w http.ResponseWriter, r *http.Request, userID uint, tokenID, password string) {
+	outcome := "success"
+	defer func() {
+		observability.RecordReauthEvent(r.Context(), "password", outcome)
+	}()
+	actor := observability.ActorUserID(userID)
+	bypassAuthAbuse, bypassReason := h.shouldBypassAuthAbuse(r)
+	if bypassAuthAbuse {
+		observability.RecordSecurityBypassEvent(r.Context(), normalizeBypassReason(bypassReason), "auth.reauth")
+		observability.RecordAuthAbuseGuardEvent(r.Context(), string(service.AuthAbuseScopeReauth), "check", "bypass")
+	} else {
+		retryAfter, err := h.abuseGuard.Check(r.Context(), service.AuthAbuseScopeReauth, actor, clientIP(r))
+		if err != nil || retryAfter > 0 {
+			outcome = "rate_limited"
+			auditAuth(r, "auth.reauth", "reauth", "rejected", "abuse_cooldown", actor, "user", actor)
+			writeAbuseCooldownHeaders(w, retryAfter)
+			response.Error(w, r, http.StatusTooManyRequests, "RATE_LIMITED", "too many requests", nil)
+			return
+		}
+	}
+	result, err := h.authSvc.ReauthenticateWithPassword(userID, tokenID, password)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrLocalAuthDisabled):
+			outcome = "failure"
+			response.Error(w, r, http.StatusNotFound, "NOT_ENABLED", "local auth is disabled", nil)
+		case errors.Is(err, service.ErrInvalidCredentials):
+			outcome = "invalid_credentials"
+			if !bypassAuthAbuse {
+				if _, abuseErr := h.abuseGuard.RegisterFailure(r.Context(), service.AuthAbuseScopeReauth, actor, clientIP(r)); abuseErr != nil {
+					auditAuth(r, "auth.reauth", "reauth", "failure", "abuse_record_error", actor, "user", actor, "error", abuseErr.Error())
+				}
+			}
+			auditAuth(r, "auth.reauth", "reauth", "failure", "invalid_credentials", actor, "user", actor, "method", "password")
+			response.Error(w, r, http.StatusUnauthorized, "UNAUTHORIZED", "invalid credentials", nil)
+		default:
+			h.writeReauthError(w, r, err, actor, "password", &outcome)
+		}
+		return
+	}
+	if !bypassAuthAbuse {
+		if err := h.abuseGuard.Reset(r.Context(), service.AuthAbuseScopeReauth, actor, clientIP(r)); err != nil {
+			auditAuth(r, "auth.reauth", "reauth", "failure", "abuse_reset_error", actor, "user", actor, "error", err.Error())
+		}
+	}
+	h.cookieMgr.SetAccessTokenCookie(w, result.AccessToken, time.Until(result.ExpiresAt))
+	auditAuth(r, "auth.reauth", "reauth", "success", "credentials_valid", actor, "user", actor, "method", "password")
+	response.JSON(w, r, http.StatusOK, result)
+}
+
+func (h *AuthHandler) beginOAuthReauth(w http.ResponseWriter, r *http.Request, userID uint, tokenID, provider string) {
+	outcome := "success"
+	defer func() {
+		if outcome != "success" {
+			observability.RecordReauthEvent(r.Context(), "oauth", outcome)
+		}
+	}()
+	actor := observability.ActorUserID(userID)
+	if !h.authSvc.OAuthProviderEnabled(provider) {
+		outcome = "failure"
+		response.Error(w, r, http.StatusNotFound, "NOT_ENABLED", fmt.Sprintf("%s auth is disabled", provider), nil)
+		return
+	}
+	oauthFlow, err := security.NewOAuthFlowState(provider)
+	if err != nil {
+		outcome = "failure"
+		response.Error(w, r, http.StatusInternalServerError, "INTERNAL", "failed to generate oauth state", nil)
+		return
+	}
+	loginURL, err := h.authSvc.BeginOAuthReauth(userID, tokenID, &oauthFlow)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrGoogleAuthDisabled), errors.Is(err, service.ErrOAuthProviderNotFound):
+			outcome = "failure"
+			response.Error(w, r, http.StatusNotFound, "NOT_ENABLED", fmt.Sprintf("%s auth is disabled", provider), nil)
+		case errors.Is(err, service.ErrReauthSessionInvalid):
+			h.writeReauthError(w, r, err, actor, "oauth", &outcome)
+		default:
+			outcome = "failure"
+			response.Error(w, r, http.StatusServiceUnavailable, "PROVIDER_UNAVAILABLE", "oauth provider is unavailable", nil)
+		}
+		return
+	}
+	signed := security.SignOAuthFlowState(oauthFlow, h.stateKey)
+	http.SetCookie(w, &http.Cookie{Name: "oauth_state", Value: signed, Path: oauthStateCookiePath(provider), HttpOnly: true, Secure: h.cookieMgr.Secure, SameSite: h.cookieMgr.SameSite, Domain: h.cookieMgr.Domain, MaxAge: 300})
+	auditAuth(r, "auth.reauth", "reauth_begin", "success", "redirect_issued", actor, "auth_provider", provider)
+	response.JSON(w, r, http.StatusOK, map[string]any{"provider": provider, "authorization_url": loginURL})
+}
+
+func (h *AuthHandler) reauthCallback(w http.ResponseWriter, r *http.Request, provider string, flow security.OAuthFlowState, code string) bool {
+	outcome := "success"
+	defer func() {
+		observability.RecordReauthEvent(r.Context(), "oauth", outcome)
+	}()
+	actor := observability.ActorUserID(flow.ReauthUserID)
+	result, err := h.authSvc.ReauthenticateWithOAuthCode(flow, code)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrGoogleAuthDisabled), errors.Is(err, service.ErrOAuthProviderNotFound):
+			outcome = "failure"
+			response.Error(w, r, http.StatusNotFound, "NOT_ENABLED", fmt.Sprintf("%s auth is disabled", provider), nil)
+		case errors.Is(err, service.ErrOAuthIdentityMismatch):
+			outcome = "invalid_credentials"
+			auditAuth(r, "auth.reauth", "reauth", "failure", "identity_mismatch", actor, "auth_provider", provider, "method", "oauth")
+			response.Error(w, r, http.StatusUnauthorized, "UNAUTHORIZED", err.Error(), nil)
+		case errors.Is(err, service.ErrReauthSessionInvalid), errors.Is(err, service.ErrAccountInactive):
+			h.writeReauthError(w, r, err, actor, "oauth", &outcome)
+		default:
+			outcome = "failure"
+			auditAuth(r, "auth.reauth", "reauth", "failure", "oauth_exchange_error", actor, "auth_provider", provider, "error", err.Error())
+			response.Error(w, r, http.StatusUnauthorized, "OAUTH_FAILED", err.Error(), nil)
+		}
+		return false
+	}
+	h.cookieMgr.SetAccessTokenCookie(w, result.AccessToken, time.Until(result.ExpiresAt))
+	auditAuth(r, "auth.reauth", "reauth", "success", "oauth_"+provider, actor, "user", actor, "method", "oauth")
+	response.JSON(w, r, http.StatusOK, result)
+	return true
+}
+
+func (h *AuthHandler) writeReauthError(w http.ResponseWriter, r *http.Request, err error, actor, method string, outcome *string) {
+	switch {
+	case errors.Is(err, service.ErrReauthSessionInvalid):
+		*outcome = "session_invalid"
+		auditAuth(r, "auth.reauth", "reauth", "rejected", "session_invalid", actor, "user", actor, "method", method)
+		response.Error(w, r, http.StatusForbidden, "REAUTH_UNAVAILABLE", "this session cannot be reauthenticated; sign in again", nil)
+	case errors.Is(err, service.ErrAccountInactive):
+		*outcome = "failure"
+		auditAuth(r, "auth.reauth", "reauth", "rejected", "account_inactive", actor, "user", actor, "method", method)
+		response.Error(w, r, http.StatusForbidden, "ACCOUNT_INACTIVE", "account is not active", nil)
+	default:
+		*outcome = "failure"
+		auditAuth(r, "auth.reauth", "reauth", "failure", "reauth_error", actor, "user", actor, "method", method, "error", err.Error())
+		response.Error(w, r, http.StatusInternalServerError, "INTERNAL", "reauthentication failed", nil)
+	}
+}
+
+func (h *AuthHandler) MFAVerify(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	status := "success"
+	mfaOutcome := "success"
+	defer func() {
+		observability.RecordAuthRequestDuration(r.Context(), "mfa_verify", status, time.Since(start))
+		observability.RecordAuthMFAEvent(r.Context(), "verify", mfaOutcome)
+	}()
+	var req struct {
+		MFAToken string `json:"mfa_token"`
+		Code     string `json:"code"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		status = "failure"
+		mfaOutcome = "failure"
+		auditAuth(r, "auth.mfa.verify", "mfa_verify", "failure", "invalid_payload", "anonymous", "user", "unknown")
+		response.Error(w, r, http.StatusBadRequest, "BAD_REQUEST", "invalid payload", nil)
+		return
+	}
+	userID, err := h.authSvc.ResolveMFAChallenge(req.MFAToken)
+	if err != nil {
+		status = "failure"
+		switch {
+		case errors.Is(err, service.ErrMFAUnavailable):
+			mfaOutcome = "not_enabled"
+			auditAuth(r, "auth.mfa.verify", "mfa_verify", "rejected", "mfa_disabled", "anonymous", "user", "unknown")
+			response.Error(w, r, http.StatusNotFound, "NOT_ENABLED", "mfa is disabled", nil)
+		case errors.Is(err, service.ErrInvalidMFAChallenge):
+			mfaOutcome = "invalid_challenge"
+			auditAuth(r, "auth.mfa.verify", "mfa_verify", "failure", "invalid_challenge", "anonymous", "user", "unknown")
+			response.Error(w, r, http.StatusUnauthorized, "INVALID_OR_EXPIRED_TOKEN", "invalid or expired mfa challenge", nil)
+		default:
+			mfaOutcome = "failure"
+			auditAuth(r, "auth.mfa.verify", "mfa_verify", "failure", "challenge_lookup_error", "anonymous", "user", "unknown", "error", err.Error())
+			response.Error(w, r, http.StatusInternalServerError, "INTERNAL", "mfa verification failed", nil)
+		}
+		return
+	}
+	actor := observability.ActorUserID(userID)
+	identity := actor
+	bypassAuthAbuse, bypassReason := h.shouldBypassAuthAbuse(r)
+	if bypassAuthAbuse {
+		observability.RecordSecurityBypassEvent(r.Context(), normalizeBypassReason(bypassReason), "auth.mfa")
+		observability.RecordAuthAbuseGuardEvent(r.Context(), string(service.AuthAbuseScopeMFA), "check", "bypass")
+		auditAuth(r, "auth.mfa.verify", "mfa_verify", "accepted", "abuse_bypass_"+bypassReason, actor, "user", actor)
+	} else {
+		retryAfter, err := h.abuseGuard.Check(r.Context(), service.AuthAbuseScopeMFA, identity, clientIP(r))
+		if err != nil {
+			status = "failure"
+			mfaOutcome = "rate_limited"
+			auditAuth(r, "auth.mfa.verify", "mfa_verify", "failure", "abuse_check_error", actor, "user", actor, "error", err.Error())
+			writeAbuseCooldownHeaders(w, retryAfter)
+			response.Error(w, r, http.StatusTooManyRequests, "RATE_LIMITED", "too many requests", nil)
+			return
+		}
+		if retryAfter > 0 {
+			status = "failure"
+			mfaOutcome = "rate_limited"
+			auditAuth(r, "auth.mfa.verify", "mfa_verify", "rejected", "abuse_cooldown", actor, "user", actor)
+			writeAbuseCooldownHeaders(w, retryAfter)
+			response.Error(w, r, http.StatusTooManyRequests, "RATE_LIMITED", "too many requests", nil)
+			return
+		}
+	}
+	result, err := h.authSvc.VerifyMFALogin(req.MFAToken, req.Code, r.UserAgent(), clientIP(r))
+	if err != nil {
+		status = "failure"
+		mfaOutcome = "failure"
+		if !bypassAuthAbuse && errors.Is(err, service.ErrInvalidMFACode) {
+			if _, abuseErr := h.abuseGuard.RegisterFailure(r.Context(), service.AuthAbuseScopeMFA, identity, clientIP(r)); abuseErr != nil {
+				auditAuth(r, "auth.mfa.verify", "mfa_verify", "failure", "abuse_record_error", actor, "user", actor, "error", abuseErr.Error())
+			}
+		}
+		auditAuth(r, "auth.mfa.verify", "mfa_verify", "failure", "verify_error", actor, "user", actor, "error", err.Error())
+		observability.RecordAuthLogin(r.Context(), "mfa", "failure")
+		switch {
+		case errors.Is(err, service.ErrInvalidMFACode):
+			mfaOutcome = "invalid_code"
+			response.Error(w, r, http.StatusUnauthorized, "INVALID_MFA_CODE", "invalid mfa code", nil)
+		case errors.Is(err, service.ErrInvalidMFAChallenge):
+			mfaOutcome = "invalid_challenge"
+			response.Error(w, r, http.StatusUnauthorized, "INVALID_OR_EXPIRED_TOKEN", "invalid or expired mfa challenge", nil)
+		case errors.Is(err, service.ErrMFANotEnrolled):
+			mfaOutcome = "not_enrolled"
+			response.Error(w, r, http.StatusUnauthorized, "UNAUTHORIZED", "mfa is not enrolled", nil)
+		case errors.Is(err, service.ErrAccountInactive):
+			mfaOutcome = "account_inactive"
+			response.Error(w, r, http.StatusForbidden, "ACCOUNT_INACTIVE", "account is not active", nil)
+		default:
+			response.Error(w, r, http.StatusInternalServerError, "INTERNAL", "mfa verification failed", nil)
+		}
+		return
+	}
+	if !bypassAuthAbuse {
+		if err := h.abuseGuard.Reset(r.Context(), service.AuthAbuseScopeMFA, identity, clientIP(r)); err != nil {
+			auditAuth(r, "auth.mfa.verify", "mfa_verify", "failure", "abuse_reset_error", actor, "user", actor, "error", err.Error())
+		}
+	}
+	if result.MFAMethod == "recovery_code" {
+		mfaOutcome = "recovery_code"
+	}
+	h.cookieMgr.SetTokenCookies(w, result.AccessToken, result.RefreshToken, result.CSRFToken, h.refreshTTL)
+	auditAuth(r, "auth.mfa.verify", "mfa_verify", "success", "mfa_"+result.MFAMethod, actor, "user", actor)
+	observability.RecordAuthLogin(r.Context(), "mfa", "success")
+	response.JSON(w, r, http.StatusOK, map[string]any{"user": result.User, "csrf_token": result.CSRFToken, "expires_at": result.ExpiresAt})
+}
+
+func (h *AuthHandler) MFATOTPSetup(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	status := "success"
+	mfaOutcome := "success"
+	defer func() {
+		observability.RecordAuthRequestDuration(r.Context(), "mfa_totp_setup", status, time.Since(start))
+		observability.RecordAuthMFAEvent(r.Context(), "totp_setup", mfaOutcome)
+	}()
+	userID, ok := h.subjectUserID(w, r)
+	if !ok {
+		status = "failure"
+		mfaOutcome = "unauthorized"
+		return
+	}
+	enrollment, err := h.authSvc.BeginTOTPEnrollment(userID)
+	if err != nil {
+		status = "failure"
+		mfaOutcome = "failure"
+		auditAuth(r, "auth.mfa.totp.setup", "mfa_totp_setup", "failure", "setup_error", observability.ActorUserID(userID), "user", observability.ActorUserID(userID), "error", err.Error())
+		switch {
+		case errors.Is(err, service.ErrMFAUnavailable):
+			mfaOutcome = "not_enabled"
+			response.Error(w, r, http.StatusNotFound, "NOT_ENABLED", "mfa is disabled", nil)
+		case errors.Is(err, service.ErrMFAAlreadyEnabled):
+			mfaOutcome = "already_enabled"
+			response.Error(w, r, http.StatusConflict, "CONFLICT", "mfa is already enabled", nil)
+		default:
+			response.Error(w, r, http.StatusInternalServerError, "INTERNAL", "mfa setup failed", nil)
+		}
+		return
+	}
+	auditAuth(r, "auth.mfa.totp.setup", "mfa_totp_setup", "success", "enrollment_started", observability.ActorUserID(userID), "user", observability.ActorUserID(userID))
+	response.JSON(w, r, http.StatusOK, enrollment)
+}
+
+func (h *AuthHandler) MFATOTPConfirm(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	status := "success"
+	mfaOutcome := "success"
+	defer func() {
+		observability.RecordAuthRequestDuration(r.Context(), "mfa_totp_confirm", status, time.Since(start))
+		observability.RecordAuthMFAEvent(r.Context(), "totp_confirm", mfaOutcome)
+	}()
+	userID, ok := h.subjectUserID(w, r)
+	if !ok {
+		status = "failure"
+		mfaOutcome = "unauthorized"
+		return
+	}
+	var req struct {
+		Code string `json:"code"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		status = "failure"
+		mfaOutcome = "failure"
+		auditAuth(r, "auth.mfa.totp.confirm", "mfa_totp_confirm", "failure", "invalid_payload", observability.ActorUserID(userID), "user", observability.ActorUserID(userID))
+		response.Error(w, r, http.StatusBadRequest, "BAD_REQUEST", "invalid payload", nil)
+		return
+	}
+	codes, err := h.authSvc.ConfirmTOTPEnrollment(userID, req.Code)
+	if err != nil {
+		status = "failure"
+		mfaOutcome = "failure"
+		auditAuth(r, "auth.mfa.totp.confirm", "mfa_totp_confirm", "failure", "confirm_error", observability.ActorUserID(userID), "user", observability.ActorUserID(userID), "error", err.Error())
+		switch {
+		case errors.Is(err, service.ErrMFAUnavailable):
+			mfaOutcome = "not_enabled"
+			response.Error(w, r, http.StatusNotFound, "NOT_ENABLED", "mfa is disabled", nil)
+		case errors.Is(err, service.ErrMFAAlreadyEnabled):
+			mfaOutcome = "already_enabled"
+			response.Error(w, r, http.StatusConflict, "CONFLICT", "mfa is already enabled", nil)
+		case errors.Is(err, service.ErrMFANotEnrolled):
+			mfaOutcome = "not_enrolled"
+			response.Error(w, r, http.StatusBadRequest, "BAD_REQUEST", "mfa setup has not been started", nil)
+		case errors.Is(err, service.ErrInvalidMFACode):
+			mfaOutcome = "invalid_code"
+			response.Error(w, r, http.StatusBadRequest, "INVALID_MFA_CODE", "invalid mfa code", nil)
+		default:
+			response.Error(w, r, http.StatusInternalServerError, "INTERNAL", "mfa confirmation failed", nil)
+		}
+		return
+	}
+	auditAuth(r, "auth.mfa.totp.confirm", "mfa_totp_confirm", "success", "mfa_enabled", observability.ActorUserID(userID), "user", observability.ActorUserID(userID))
+	response.JSON(w, r, http.StatusOK, map[string]any{"status": "mfa_enabled", "recovery_codes": codes})
+}
+
+func (h *AuthHandler) subjectUserID(w http.ResponseWriter, r *http.Request) (uint, bool) {
+	claims, ok := middleware.ClaimsFromContext(r.Context())
+	if !ok {
+		response.Error(w, r, http.StatusUnauthorized, "UNAUTHORIZED", "missing auth context", nil)
+		return 0, false
+	}
+	userID, err := h.authSvc.ParseUserID(claims.Subject)
+	if err != nil {
+		response.Error(w, r, http.StatusUnauthorized, "UNAUTHORIZED", "invalid subject", nil)
+		return 0, false
+	}
+	return userID, true
+}
+
+func writeMFAChallenge(w http.ResponseWriter, r *http.Request, result *service.LoginResult) {
+	observability.RecordAuthMFAEvent(r.Context(), "challenge", "issued")
+	response.JSON(w, r, http.StatusOK, map[string]any{
+		"user":         result.User,
+		"mfa_required": true,
+		"mfa_token":    result.MFAToken,
+		"expires_at":   result.ExpiresAt,
+	})
+}
+
+// writeWeakPassword lists the failed policy rules under details.violations so
+// clients can show each one next to the password field.
+func writeWeakPassword(w http.ResponseWriter, r *http.Request, err error) {
+	var details any
+	var policyErr *service.PasswordPolicyError
+	if errors.As(err, &policyErr) {
+		details = map[string]any{"violations": policyErr.Violations}
+	}
+	response.Error(w, r, http.StatusBadRequest, "BAD_REQUEST", service.ErrWeakPassword.Error(), details)
+}
+
+func oauthStateCookiePath(provider string) string {
+	return "/api/v1/auth/" + provider
+}
+
 func clientIP(r *http.Request) string {
 	xff := r.Header.Get("X-Forwarded-For")
 	if xff != "" {
@@ -568,6 +1317,32 @@ func clientIP(r *http.Request) string {
 	return r.RemoteAddr
 }
 
+// requestLocale returns the caller's most preferred Accept-Language tag for
+// notification mails. Template lookup falls back to the base language and
+// EMAIL_DEFAULT_LOCALE, so an unsupported or missing tag is harmless.
+func requestLocale(r *http.Request) string {
+	best, bestQ := "", 0.0
+	for _, part := range strings.Split(r.Header.Get("Accept-Language"), ",") {
+		tag, params, _ := strings.Cut(part, ";")
+		tag = strings.TrimSpace(tag)
+		if !languageTagPattern.MatchString(tag) {
+			continue
+		}
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if q > bestQ {
+			best, bestQ = strings.ToLower(tag), q
+		}
+	}
+	return best
+}
+
 func normalizeBypassReason(reason string) string {
 	reason = strings.TrimSpace(reason)
 	if reason == "" {
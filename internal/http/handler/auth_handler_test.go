@@ -22,6 +22,9 @@ type authErrorEnvelope struct {
 	Error   *struct {
 		Code    string `json:"code"`
 		Message string `json:"message"`
+		Details struct {
+			Violations []service.PasswordViolation `json:"violations"`
+		} `json:"details"`
 	} `json:"error"`
 }
 
@@ -36,11 +39,91 @@ type stubAuthService struct {
 	forgotFn        func(email string) error
 	resetFn         func(token, newPassword string) error
 	loginLocalFn    func(email, password, ua, ip string) (*service.LoginResult, error)
+	oauthEnabledFn  func(provider string) bool
+	oauthLoginURLFn func(flow security.OAuthFlowState) (string, error)
+	oauthLoginFn    func(flow security.OAuthFlowState, code, ua, ip string) (*service.LoginResult, error)
+	linkIdentityFn  func(flow security.OAuthFlowState, code string) (*domain.OAuthAccount, error)
+	unlinkFn        func(userID, identityID uint) error
+	deletePasskeyFn func(userID, credentialID uint) error
+
+	beginReauthFn    func(userID uint, tokenID string, flow *security.OAuthFlowState) (string, error)
+	reauthOAuthFn    func(flow security.OAuthFlowState, code string) (*service.ReauthResult, error)
+	reauthPasswordFn func(userID uint, tokenID, password string) (*service.ReauthResult, error)
+
+	resolveMFAFn  func(challengeToken string) (uint, error)
+	verifyMFAFn   func(challengeToken, code, ua, ip string) (*service.LoginResult, error)
+	beginTOTPFn   func(userID uint) (*service.TOTPEnrollment, error)
+	confirmTOTPFn func(userID uint, code string) ([]string, error)
+	webauthnFn    func(challengeID string, credential []byte, ua, ip string) (*service.LoginResult, error)
+
+	requestMagicFn func(email, locale string) error
+	confirmMagicFn func(token, ua, ip string) (*service.LoginResult, error)
 }
 
-func (s *stubAuthService) GoogleLoginURL(state string) string { return "" }
+func (s *stubAuthService) OAuthProviderEnabled(provider string) bool {
+	if s.oauthEnabledFn != nil {
+		return s.oauthEnabledFn(provider)
+	}
+	return false
+}
 
-func (s *stubAuthService) LoginWithGoogleCode(code, ua, ip string) (*service.LoginResult, error) {
+func (s *stubAuthService) OAuthLoginURL(flow security.OAuthFlowState) (string, error) {
+	if s.oauthLoginURLFn != nil {
+		return s.oauthLoginURLFn(flow)
+	}
+	return "", service.ErrOAuthProviderNotFound
+}
+
+func (s *stubAuthService) LoginWithOAuthCode(flow security.OAuthFlowState, code, ua, ip string) (*service.LoginResult, error) {
+	if s.oauthLoginFn != nil {
+		return s.oauthLoginFn(flow, code, ua, ip)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (s *stubAuthService) LinkOAuthIdentity(flow security.OAuthFlowState, code string) (*domain.OAuthAccount, error) {
+	if s.linkIdentityFn != nil {
+		return s.linkIdentityFn(flow, code)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (s *stubAuthService) ListIdentities(uint) (*service.LinkedIdentities, error) {
+	return &service.LinkedIdentities{Identities: []domain.OAuthAccount{}}, nil
+}
+
+func (s *stubAuthService) UnlinkIdentity(userID, identityID uint) error {
+	if s.unlinkFn != nil {
+		return s.unlinkFn(userID, identityID)
+	}
+	return nil
+}
+
+func (s *stubAuthService) DeleteWebAuthnCredential(userID, credentialID uint) error {
+	if s.deletePasskeyFn != nil {
+		return s.deletePasskeyFn(userID, credentialID)
+	}
+	return nil
+}
+
+func (s *stubAuthService) BeginOAuthReauth(userID uint, tokenID string, flow *security.OAuthFlowState) (string, error) {
+	if s.beginReauthFn != nil {
+		return s.beginReauthFn(userID, tokenID, flow)
+	}
+	return "", errors.New("not implemented")
+}
+
+func (s *stubAuthService) ReauthenticateWithOAuthCode(flow security.OAuthFlowState, code string) (*service.ReauthResult, error) {
+	if s.reauthOAuthFn != nil {
+		return s.reauthOAuthFn(flow, code)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (s *stubAuthService) ReauthenticateWithPassword(userID uint, tokenID, password string) (*service.ReauthResult, error) {
+	if s.reauthPasswordFn != nil {
+		return s.reauthPasswordFn(userID, tokenID, password)
+	}
 	return nil, errors.New("not implemented")
 }
 
@@ -55,7 +138,7 @@ func (s *stubAuthService) LoginWithLocalPassword(email, password, ua, ip string)
 	return nil, errors.New("not implemented")
 }
 
-func (s *stubAuthService) RequestLocalEmailVerification(email string) error {
+func (s *stubAuthService) RequestLocalEmailVerification(email, _ string) error {
 	if s.requestVerifyFn != nil {
 		return s.requestVerifyFn(email)
 	}
@@ -69,7 +152,7 @@ func (s *stubAuthService) ConfirmLocalEmailVerification(token string) error {
 	return nil
 }
 
-func (s *stubAuthService) ForgotLocalPassword(email string) error {
+func (s *stubAuthService) ForgotLocalPassword(email, _ string) error {
 	if s.forgotFn != nil {
 		return s.forgotFn(email)
 	}
@@ -83,6 +166,20 @@ func (s *stubAuthService) ResetLocalPassword(token, newPassword string) error {
 	return nil
 }
 
+func (s *stubAuthService) RequestMagicLink(email, locale string) error {
+	if s.requestMagicFn != nil {
+		return s.requestMagicFn(email, locale)
+	}
+	return nil
+}
+
+func (s *stubAuthService) ConfirmMagicLink(token, ua, ip string) (*service.LoginResult, error) {
+	if s.confirmMagicFn != nil {
+		return s.confirmMagicFn(token, ua, ip)
+	}
+	return nil, service.ErrMagicLinkDisabled
+}
+
 func (s *stubAuthService) ChangeLocalPassword(userID uint, currentPassword, newPassword string) error {
 	if s.changePassFn != nil {
 		return s.changePassFn(userID, currentPassword, newPassword)
@@ -90,6 +187,41 @@ func (s *stubAuthService) ChangeLocalPassword(userID uint, currentPassword, newP
 	return nil
 }
 
+func (s *stubAuthService) BeginTOTPEnrollment(userID uint) (*service.TOTPEnrollment, error) {
+	if s.beginTOTPFn != nil {
+		return s.beginTOTPFn(userID)
+	}
+	return nil, service.ErrMFAUnavailable
+}
+
+func (s *stubAuthService) ConfirmTOTPEnrollment(userID uint, code string) ([]string, error) {
+	if s.confirmTOTPFn != nil {
+		return s.confirmTOTPFn(userID, code)
+	}
+	return nil, service.ErrMFAUnavailable
+}
+
+func (s *stubAuthService) ResolveMFAChallenge(challengeToken string) (uint, error) {
+	if s.resolveMFAFn != nil {
+		return s.resolveMFAFn(challengeToken)
+	}
+	return 0, service.ErrMFAUnavailable
+}
+
+func (s *stubAuthService) VerifyMFALogin(challengeToken, code, ua, ip string) (*service.LoginResult, error) {
+	if s.verifyMFAFn != nil {
+		return s.verifyMFAFn(challengeToken, code, ua, ip)
+	}
+	return nil, service.ErrMFAUnavailable
+}
+
+func (s *stubAuthService) LoginWithWebAuthn(challengeID string, credential []byte, ua, ip string) (*service.LoginResult, error) {
+	if s.webauthnFn != nil {
+		return s.webauthnFn(challengeID, credential, ua, ip)
+	}
+	return nil, service.ErrWebAuthnDisabled
+}
+
 func (s *stubAuthService) Refresh(refreshToken, ua, ip string) (*service.LoginResult, error) {
 	if s.refreshFn != nil {
 		return s.refreshFn(refreshToken, ua, ip)
@@ -359,7 +491,9 @@ func TestAuthHandlerBypassAndLocalFlowErrorMappings(t *testing.T) {
 			requestVerifyFn: func(email string) error { return service.ErrLocalAuthDisabled },
 			confirmVerifyFn: func(token string) error { return service.ErrInvalidVerifyToken },
 			forgotFn:        func(email string) error { return service.ErrLocalAuthDisabled },
-			resetFn:         func(token, newPassword string) error { return service.ErrWeakPassword },
+			resetFn: func(token, newPassword string) error {
+				return &service.PasswordPolicyError{Violations: []service.PasswordViolation{{Rule: service.PasswordRuleMinLength, Message: "must be at least 12 characters"}}}
+			},
 		}
 		h := NewAuthHandler(authSvc, &stubAuthAbuseGuard{}, cookieMgr, nil, "state", 24*time.Hour)
 
@@ -390,6 +524,10 @@ func TestAuthHandlerBypassAndLocalFlowErrorMappings(t *testing.T) {
 		if rr.Code != http.StatusBadRequest {
 			t.Fatalf("reset expected 400, got %d", rr.Code)
 		}
+		env = decodeAuthErrorEnvelope(t, rr)
+		if env.Error == nil || len(env.Error.Details.Violations) != 1 || env.Error.Details.Violations[0].Rule != service.PasswordRuleMinLength {
+			t.Fatalf("expected the failed rule in error details, got %+v", env.Error)
+		}
 	})
 }
 
@@ -444,3 +582,637 @@ func TestAuthHandlerRefreshAndLogoutCookieSideEffects(t *testing.T) {
 		}
 	})
 }
+
+func TestAuthHandlerOAuthLoginProviderRouting(t *testing.T) {
+	cookieMgr := security.NewCookieManager("", false, "lax")
+	authSvc := &stubAuthService{
+		oauthEnabledFn: func(provider string) bool { return provider == "github" || provider == "okta" },
+		oauthLoginURLFn: func(flow security.OAuthFlowState) (string, error) {
+			if flow.Provider == "okta" {
+				return "", service.ErrOAuthProviderUnavailable
+			}
+			return "https://github.example/login?state=" + flow.State, nil
+		},
+	}
+	h := NewAuthHandler(authSvc, &stubAuthAbuseGuard{}, cookieMgr, nil, "state-signing-key", 24*time.Hour)
+
+	t.Run("enabled provider redirects with provider scoped state cookie", func(t *testing.T) {
+		req := withURLParam(httptest.NewRequest(http.MethodGet, "/api/v1/auth/GitHub/login", nil), "provider", "GitHub")
+		rr := httptest.NewRecorder()
+
+		h.OAuthLogin(rr, req)
+		if rr.Code != http.StatusFound {
+			t.Fatalf("expected 302, got %d", rr.Code)
+		}
+		var stateCookie *http.Cookie
+		for _, c := range rr.Result().Cookies() {
+			if c.Name == "oauth_state" {
+				stateCookie = c
+			}
+		}
+		if stateCookie == nil || stateCookie.Path != "/api/v1/auth/github" {
+			t.Fatalf("expected oauth_state cookie scoped to github, got %+v", stateCookie)
+		}
+	})
+
+	t.Run("unknown provider is not enabled", func(t *testing.T) {
+		req := withURLParam(httptest.NewRequest(http.MethodGet, "/api/v1/auth/gitlab/login", nil), "provider", "gitlab")
+		rr := httptest.NewRecorder()
+
+		h.OAuthLogin(rr, req)
+		if rr.Code != http.StatusNotFound {
+			t.Fatalf("expected 404, got %d", rr.Code)
+		}
+		if env := decodeAuthErrorEnvelope(t, rr); env.Error == nil || env.Error.Code != "NOT_ENABLED" {
+			t.Fatalf("expected NOT_ENABLED, got %+v", env.Error)
+		}
+	})
+
+	t.Run("discovery failure maps to provider unavailable", func(t *testing.T) {
+		req := withURLParam(httptest.NewRequest(http.MethodGet, "/api/v1/auth/okta/login", nil), "provider", "okta")
+		rr := httptest.NewRecorder()
+
+		h.OAuthLogin(rr, req)
+		if rr.Code != http.StatusServiceUnavailable {
+			t.Fatalf("expected 503, got %d", rr.Code)
+		}
+		if env := decodeAuthErrorEnvelope(t, rr); env.Error == nil || env.Error.Code != "PROVIDER_UNAVAILABLE" {
+			t.Fatalf("expected PROVIDER_UNAVAILABLE, got %+v", env.Error)
+		}
+	})
+}
+
+func TestAuthHandlerOAuthCallbackBindsFlowStateToProvider(t *testing.T) {
+	const stateKey = "state-signing-key"
+	cookieMgr := security.NewCookieManager("", false, "lax")
+	var gotFlow security.OAuthFlowState
+	authSvc := &stubAuthService{
+		oauthEnabledFn: func(provider string) bool { return provider == "github" || provider == "okta" },
+		oauthLoginFn: func(flow security.OAuthFlowState, code, ua, ip string) (*service.LoginResult, error) {
+			gotFlow = flow
+			return nil, service.ErrOAuthNonceMismatch
+		},
+	}
+	h := NewAuthHandler(authSvc, &stubAuthAbuseGuard{}, cookieMgr, nil, stateKey, 24*time.Hour)
+
+	flow, err := security.NewOAuthFlowState("github")
+	if err != nil {
+		t.Fatalf("new oauth flow state: %v", err)
+	}
+	signed := security.SignOAuthFlowState(flow, stateKey)
+
+	t.Run("state signed for another provider is rejected", func(t *testing.T) {
+		req := withURLParam(httptest.NewRequest(http.MethodGet, "/api/v1/auth/okta/callback?state="+flow.State+"&code=abc", nil), "provider", "okta")
+		req.AddCookie(&http.Cookie{Name: "oauth_state", Value: signed})
+		rr := httptest.NewRecorder()
+
+		h.OAuthCallback(rr, req)
+		if rr.Code != http.StatusUnauthorized {
+			t.Fatalf("expected 401, got %d", rr.Code)
+		}
+		if env := decodeAuthErrorEnvelope(t, rr); env.Error == nil || env.Error.Code != "UNAUTHORIZED" {
+			t.Fatalf("expected UNAUTHORIZED, got %+v", env.Error)
+		}
+	})
+
+	t.Run("verified flow is passed through and nonce mismatch fails", func(t *testing.T) {
+		req := withURLParam(httptest.NewRequest(http.MethodGet, "/api/v1/auth/github/callback?state="+flow.State+"&code=abc", nil), "provider", "github")
+		req.AddCookie(&http.Cookie{Name: "oauth_state", Value: signed})
+		rr := httptest.NewRecorder()
+
+		h.OAuthCallback(rr, req)
+		if rr.Code != http.StatusUnauthorized {
+			t.Fatalf("expected 401, got %d", rr.Code)
+		}
+		if env := decodeAuthErrorEnvelope(t, rr); env.Error == nil || env.Error.Code != "OAUTH_FAILED" {
+			t.Fatalf("expected OAUTH_FAILED, got %+v", env.Error)
+		}
+		if gotFlow != flow {
+			t.Fatalf("expected verified flow state to reach service, got %+v", gotFlow)
+		}
+	})
+}
+
+func TestAuthHandlerIdentityLinkAndUnlink(t *testing.T) {
+	const stateKey = "state-signing-key"
+	cookieMgr := security.NewCookieManager("", false, "lax")
+	parseUserID := func(subject string) (uint, error) { return 9, nil }
+
+	t.Run("link begin binds the caller into the state cookie", func(t *testing.T) {
+		authSvc := &stubAuthService{
+			parseUserIDFn:  parseUserID,
+			oauthEnabledFn: func(provider string) bool { return provider == "github" },
+			oauthLoginURLFn: func(flow security.OAuthFlowState) (string, error) {
+				return "https://github.example/login?state=" + flow.State, nil
+			},
+		}
+		h := NewAuthHandler(authSvc, &stubAuthAbuseGuard{}, cookieMgr, nil, stateKey, 24*time.Hour)
+		req := withURLParam(withClaims(httptest.NewRequest(http.MethodPost, "/api/v1/me/identities/github/link", nil), "9"), "provider", "github")
+		rr := httptest.NewRecorder()
+
+		h.LinkIdentity(rr, req)
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d", rr.Code)
+		}
+		var stateCookie *http.Cookie
+		for _, c := range rr.Result().Cookies() {
+			if c.Name == "oauth_state" {
+				stateCookie = c
+			}
+		}
+		if stateCookie == nil || stateCookie.Path != "/api/v1/auth/github" {
+			t.Fatalf("expected oauth_state cookie scoped to github, got %+v", stateCookie)
+		}
+		flow, ok := security.VerifyOAuthFlowState(stateCookie.Value, stateKey)
+		if !ok || flow.LinkUserID != 9 {
+			t.Fatalf("expected link flow for user 9, got ok=%v flow=%+v", ok, flow)
+		}
+	})
+
+	t.Run("callback with link flow links instead of logging in", func(t *testing.T) {
+		loggedIn := false
+		var linkErr error
+		authSvc := &stubAuthService{
+			oauthEnabledFn: func(provider string) bool { return provider == "github" },
+			oauthLoginFn: func(security.OAuthFlowState, string, string, string) (*service.LoginResult, error) {
+				loggedIn = true
+				return nil, errors.New("unexpected login")
+			},
+			linkIdentityFn: func(flow security.OAuthFlowState, code string) (*domain.OAuthAccount, error) {
+				if linkErr != nil {
+					return nil, linkErr
+				}
+				return &domain.OAuthAccount{ID: 3, UserID: flow.LinkUserID, Provider: "github"}, nil
+			},
+		}
+		h := NewAuthHandler(authSvc, &stubAuthAbuseGuard{}, cookieMgr, nil, stateKey, 24*time.Hour)
+		flow, err := security.NewOAuthFlowState("github")
+		if err != nil {
+			t.Fatalf("new oauth flow state: %v", err)
+		}
+		flow.LinkUserID = 9
+		signed := security.SignOAuthFlowState(flow, stateKey)
+		callback := func() *httptest.ResponseRecorder {
+			req := withURLParam(httptest.NewRequest(http.MethodGet, "/api/v1/auth/github/callback?state="+flow.State+"&code=abc", nil), "provider", "github")
+			req.AddCookie(&http.Cookie{Name: "oauth_state", Value: signed})
+			rr := httptest.NewRecorder()
+			h.OAuthCallback(rr, req)
+			return rr
+		}
+
+		rr := callback()
+		if rr.Code != http.StatusOK || loggedIn || hasCookie(rr.Result().Cookies(), "access_token") {
+			t.Fatalf("expected link without session, got status=%d loggedIn=%v", rr.Code, loggedIn)
+		}
+
+		linkErr = service.ErrOAuthIdentityLinkedElsewhere
+		rr = callback()
+		if rr.Code != http.StatusConflict {
+			t.Fatalf("expected 409, got %d", rr.Code)
+		}
+		if env := decodeAuthErrorEnvelope(t, rr); env.Error == nil || env.Error.Code != "CONFLICT" {
+			t.Fatalf("expected CONFLICT, got %+v", env.Error)
+		}
+	})
+
+	t.Run("login callback reports link required", func(t *testing.T) {
+		authSvc := &stubAuthService{
+			oauthEnabledFn: func(provider string) bool { return provider == "github" },
+			oauthLoginFn: func(security.OAuthFlowState, string, string, string) (*service.LoginResult, error) {
+				return nil, service.ErrOAuthLinkRequired
+			},
+		}
+		h := NewAuthHandler(authSvc, &stubAuthAbuseGuard{}, cookieMgr, nil, stateKey, 24*time.Hour)
+		flow, err := security.NewOAuthFlowState("github")
+		if err != nil {
+			t.Fatalf("new oauth flow state: %v", err)
+		}
+		req := withURLParam(httptest.NewRequest(http.MethodGet, "/api/v1/auth/github/callback?state="+flow.State+"&code=abc", nil), "provider", "github")
+		req.AddCookie(&http.Cookie{Name: "oauth_state", Value: security.SignOAuthFlowState(flow, stateKey)})
+		rr := httptest.NewRecorder()
+
+		h.OAuthCallback(rr, req)
+
+		if rr.Code != http.StatusConflict {
+			t.Fatalf("expected 409, got %d", rr.Code)
+		}
+		if env := decodeAuthErrorEnvelope(t, rr); env.Error == nil || env.Error.Code != "LINK_REQUIRED" {
+			t.Fatalf("expected LINK_REQUIRED, got %+v", env.Error)
+		}
+	})
+
+	t.Run("unlink error mapping", func(t *testing.T) {
+		cases := []struct {
+			err      error
+			wantCode int
+			wantErr  string
+		}{
+			{err: service.ErrLastCredential, wantCode: http.StatusConflict, wantErr: "LAST_CREDENTIAL"},
+			{err: service.ErrIdentityNotFound, wantCode: http.StatusNotFound, wantErr: "NOT_FOUND"},
+		}
+		for _, tc := range cases {
+			authSvc := &stubAuthService{
+				parseUserIDFn: parseUserID,
+				unlinkFn:      func(uint, uint) error { return tc.err },
+			}
+			h := NewAuthHandler(authSvc, &stubAuthAbuseGuard{}, cookieMgr, nil, stateKey, 24*time.Hour)
+			req := withURLParam(withClaims(httptest.NewRequest(http.MethodDelete, "/api/v1/me/identities/3", nil), "9"), "identity_id", "3")
+			rr := httptest.NewRecorder()
+
+			h.UnlinkIdentity(rr, req)
+
+			if rr.Code != tc.wantCode {
+				t.Fatalf("expected %d, got %d", tc.wantCode, rr.Code)
+			}
+			if env := decodeAuthErrorEnvelope(t, rr); env.Error == nil || env.Error.Code != tc.wantErr {
+				t.Fatalf("expected %s, got %+v", tc.wantErr, env.Error)
+			}
+		}
+	})
+}
+
+func TestAuthHandlerMFAChallengeAndVerify(t *testing.T) {
+	cookieMgr := security.NewCookieManager("", false, "lax")
+
+	t.Run("local login with mfa returns challenge without cookies", func(t *testing.T) {
+		authSvc := &stubAuthService{loginLocalFn: func(email, password, ua, ip string) (*service.LoginResult, error) {
+			return &service.LoginResult{User: &domain.User{ID: 5}, MFARequired: true, MFAToken: "challenge", ExpiresAt: time.Now().Add(5 * time.Minute)}, nil
+		}}
+		h := NewAuthHandler(authSvc, &stubAuthAbuseGuard{}, cookieMgr, nil, "state", 24*time.Hour)
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/local/login", strings.NewReader(`{"email":"a@example.com","password":"pw"}`))
+		rr := httptest.NewRecorder()
+
+		h.LocalLogin(rr, req)
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d", rr.Code)
+		}
+		if hasCookie(rr.Result().Cookies(), "access_token") {
+			t.Fatal("expected no session cookies before mfa verification")
+		}
+		var env struct {
+			Data struct {
+				MFARequired bool   `json:"mfa_required"`
+				MFAToken    string `json:"mfa_token"`
+			} `json:"data"`
+		}
+		if err := json.NewDecoder(rr.Body).Decode(&env); err != nil {
+			t.Fatalf("decode response: %v", err)
+		}
+		if !env.Data.MFARequired || env.Data.MFAToken != "challenge" {
+			t.Fatalf("unexpected challenge payload: %+v", env.Data)
+		}
+	})
+
+	t.Run("invalid code registers abuse failure", func(t *testing.T) {
+		abuse := &stubAuthAbuseGuard{}
+		authSvc := &stubAuthService{
+			resolveMFAFn: func(challengeToken string) (uint, error) { return 5, nil },
+			verifyMFAFn: func(challengeToken, code, ua, ip string) (*service.LoginResult, error) {
+				return nil, service.ErrInvalidMFACode
+			},
+		}
+		h := NewAuthHandler(authSvc, abuse, cookieMgr, nil, "state", 24*time.Hour)
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/mfa/verify", strings.NewReader(`{"mfa_token":"challenge","code":"000000"}`))
+		rr := httptest.NewRecorder()
+
+		h.MFAVerify(rr, req)
+
+		if rr.Code != http.StatusUnauthorized {
+			t.Fatalf("expected 401, got %d", rr.Code)
+		}
+		if env := decodeAuthErrorEnvelope(t, rr); env.Error == nil || env.Error.Code != "INVALID_MFA_CODE" {
+			t.Fatalf("expected INVALID_MFA_CODE, got %+v", env.Error)
+		}
+		if abuse.checkCalls != 1 || abuse.registerCalls != 1 {
+			t.Fatalf("expected abuse check and failure registration, got check=%d register=%d", abuse.checkCalls, abuse.registerCalls)
+		}
+	})
+
+	t.Run("cooldown blocks verification", func(t *testing.T) {
+		verifyCalled := false
+		abuse := &stubAuthAbuseGuard{checkFn: func(ctx context.Context, scope service.AuthAbuseScope, identity, ip string) (time.Duration, error) {
+			if scope != service.AuthAbuseScopeMFA || identity != "5" {
+				t.Fatalf("unexpected abuse scope/identity: %s/%s", scope, identity)
+			}
+			return time.Minute, nil
+		}}
+		authSvc := &stubAuthService{
+			resolveMFAFn: func(challengeToken string) (uint, error) { return 5, nil },
+			verifyMFAFn: func(challengeToken, code, ua, ip string) (*service.LoginResult, error) {
+				verifyCalled = true
+				return nil, nil
+			},
+		}
+		h := NewAuthHandler(authSvc, abuse, cookieMgr, nil, "state", 24*time.Hour)
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/mfa/verify", strings.NewReader(`{"mfa_token":"challenge","code":"000000"}`))
+		rr := httptest.NewRecorder()
+
+		h.MFAVerify(rr, req)
+
+		if rr.Code != http.StatusTooManyRequests || verifyCalled {
+			t.Fatalf("expected 429 without verification, got %d called=%v", rr.Code, verifyCalled)
+		}
+	})
+
+	t.Run("invalid challenge", func(t *testing.T) {
+		authSvc := &stubAuthService{resolveMFAFn: func(challengeToken string) (uint, error) { return 0, service.ErrInvalidMFAChallenge }}
+		h := NewAuthHandler(authSvc, &stubAuthAbuseGuard{}, cookieMgr, nil, "state", 24*time.Hour)
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/mfa/verify", strings.NewReader(`{"mfa_token":"stale","code":"000000"}`))
+		rr := httptest.NewRecorder()
+
+		h.MFAVerify(rr, req)
+
+		if rr.Code != http.StatusUnauthorized {
+			t.Fatalf("expected 401, got %d", rr.Code)
+		}
+	})
+
+	t.Run("success sets session cookies", func(t *testing.T) {
+		abuse := &stubAuthAbuseGuard{}
+		authSvc := &stubAuthService{
+			resolveMFAFn: func(challengeToken string) (uint, error) { return 5, nil },
+			verifyMFAFn: func(challengeToken, code, ua, ip string) (*service.LoginResult, error) {
+				return &service.LoginResult{User: &domain.User{ID: 5}, AccessToken: "a", RefreshToken: "r", CSRFToken: "c", ExpiresAt: time.Now().Add(time.Hour), MFAMethod: "totp"}, nil
+			},
+		}
+		h := NewAuthHandler(authSvc, abuse, cookieMgr, nil, "state", 24*time.Hour)
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/mfa/verify", strings.NewReader(`{"mfa_token":"challenge","code":"123456"}`))
+		rr := httptest.NewRecorder()
+
+		h.MFAVerify(rr, req)
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d", rr.Code)
+		}
+		if !hasCookie(rr.Result().Cookies(), "access_token") || abuse.resetCalls != 1 {
+			t.Fatalf("expected session cookies and abuse reset, reset=%d", abuse.resetCalls)
+		}
+	})
+}
+
+func TestAuthHandlerMFATOTPEnrollmentErrorMappings(t *testing.T) {
+	cookieMgr := security.NewCookieManager("", false, "lax")
+	cases := []struct {
+		name     string
+		err      error
+		wantCode int
+		wantErr  string
+	}{
+		{name: "not enabled", err: service.ErrMFAUnavailable, wantCode: http.StatusNotFound, wantErr: "NOT_ENABLED"},
+		{name: "already enabled", err: service.ErrMFAAlreadyEnabled, wantCode: http.StatusConflict, wantErr: "CONFLICT"},
+		{name: "invalid code", err: service.ErrInvalidMFACode, wantCode: http.StatusBadRequest, wantErr: "INVALID_MFA_CODE"},
+		{name: "not started", err: service.ErrMFANotEnrolled, wantCode: http.StatusBadRequest, wantErr: "BAD_REQUEST"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			authSvc := &stubAuthService{
+				parseUserIDFn: func(subject string) (uint, error) { return 9, nil },
+				confirmTOTPFn: func(userID uint, code string) ([]string, error) { return nil, tc.err },
+			}
+			h := NewAuthHandler(authSvc, &stubAuthAbuseGuard{}, cookieMgr, nil, "state", 24*time.Hour)
+			req := withClaims(httptest.NewRequest(http.MethodPost, "/api/v1/me/mfa/totp/confirm", strings.NewReader(`{"code":"123456"}`)), "9")
+			rr := httptest.NewRecorder()
+
+			h.MFATOTPConfirm(rr, req)
+
+			if rr.Code != tc.wantCode {
+				t.Fatalf("expected %d, got %d", tc.wantCode, rr.Code)
+			}
+			if env := decodeAuthErrorEnvelope(t, rr); env.Error == nil || env.Error.Code != tc.wantErr {
+				t.Fatalf("expected error code %q, got %+v", tc.wantErr, env.Error)
+			}
+		})
+	}
+}
+
+func TestAuthHandlerReauth(t *testing.T) {
+	const stateKey = "state-signing-key"
+	cookieMgr := security.NewCookieManager("", false, "lax")
+	parseUserID := func(subject string) (uint, error) { return 9, nil }
+	reauthRequest := func(body string) *http.Request {
+		req := withClaims(httptest.NewRequest(http.MethodPost, "/api/v1/auth/reauth", strings.NewReader(body)), "9")
+		claims, _ := middleware.ClaimsFromContext(req.Context())
+		claims.ID = "jti-9"
+		return req
+	}
+
+	t.Run("password replaces only the access cookie", func(t *testing.T) {
+		guard := &stubAuthAbuseGuard{}
+		wrong := true
+		authSvc := &stubAuthService{
+			parseUserIDFn: parseUserID,
+			reauthPasswordFn: func(userID uint, tokenID, password string) (*service.ReauthResult, error) {
+				if userID != 9 || tokenID != "jti-9" {
+					t.Fatalf("unexpected reauth target user=%d token=%s", userID, tokenID)
+				}
+				if wrong {
+					return nil, service.ErrInvalidCredentials
+				}
+				return &service.ReauthResult{AccessToken: "fresh-access", AuthTime: time.Now(), ExpiresAt: time.Now().Add(15 * time.Minute)}, nil
+			},
+		}
+		h := NewAuthHandler(authSvc, guard, cookieMgr, nil, stateKey, 24*time.Hour)
+
+		rr := httptest.NewRecorder()
+		h.Reauth(rr, reauthRequest(`{"password":"nope"}`))
+		if rr.Code != http.StatusUnauthorized || guard.registerCalls != 1 {
+			t.Fatalf("expected 401 with a recorded failure, got %d (failures=%d)", rr.Code, guard.registerCalls)
+		}
+
+		wrong = false
+		rr = httptest.NewRecorder()
+		h.Reauth(rr, reauthRequest(`{"password":"Valid#Pass1234"}`))
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d body=%s", rr.Code, rr.Body.String())
+		}
+		cookies := rr.Result().Cookies()
+		if !hasCookie(cookies, "access_token") || hasCookie(cookies, "refresh_token") || hasCookie(cookies, "csrf_token") {
+			t.Fatalf("expected only the access cookie to be replaced, got %+v", cookies)
+		}
+	})
+
+	t.Run("requires exactly one method", func(t *testing.T) {
+		h := NewAuthHandler(&stubAuthService{parseUserIDFn: parseUserID}, &stubAuthAbuseGuard{}, cookieMgr, nil, stateKey, 24*time.Hour)
+		for _, body := range []string{`{}`, `{"password":"x","provider":"github"}`} {
+			rr := httptest.NewRecorder()
+			h.Reauth(rr, reauthRequest(body))
+			if rr.Code != http.StatusBadRequest {
+				t.Fatalf("%s: expected 400, got %d", body, rr.Code)
+			}
+		}
+	})
+
+	t.Run("sessions without a refresh family cannot step up", func(t *testing.T) {
+		authSvc := &stubAuthService{
+			parseUserIDFn: parseUserID,
+			reauthPasswordFn: func(uint, string, string) (*service.ReauthResult, error) {
+				return nil, service.ErrReauthSessionInvalid
+			},
+		}
+		h := NewAuthHandler(authSvc, &stubAuthAbuseGuard{}, cookieMgr, nil, stateKey, 24*time.Hour)
+		rr := httptest.NewRecorder()
+		h.Reauth(rr, reauthRequest(`{"password":"x"}`))
+		if env := decodeAuthErrorEnvelope(t, rr); rr.Code != http.StatusForbidden || env.Error == nil || env.Error.Code != "REAUTH_UNAVAILABLE" {
+			t.Fatalf("expected REAUTH_UNAVAILABLE, got %d %+v", rr.Code, env.Error)
+		}
+	})
+
+	t.Run("oauth round-trip reauthenticates instead of logging in", func(t *testing.T) {
+		authSvc := &stubAuthService{
+			parseUserIDFn:  parseUserID,
+			oauthEnabledFn: func(provider string) bool { return provider == "github" },
+			beginReauthFn: func(userID uint, tokenID string, flow *security.OAuthFlowState) (string, error) {
+				flow.ReauthUserID = userID
+				flow.ReauthFamilyID = "family-" + tokenID
+				return "https://github.example/login?state=" + flow.State, nil
+			},
+			oauthLoginFn: func(security.OAuthFlowState, string, string, string) (*service.LoginResult, error) {
+				t.Fatal("unexpected login")
+				return nil, nil
+			},
+			reauthOAuthFn: func(flow security.OAuthFlowState, code string) (*service.ReauthResult, error) {
+				if flow.ReauthUserID != 9 || flow.ReauthFamilyID != "family-jti-9" {
+					t.Fatalf("unexpected reauth flow %+v", flow)
+				}
+				return &service.ReauthResult{AccessToken: "fresh-access", AuthTime: time.Now(), ExpiresAt: time.Now().Add(15 * time.Minute)}, nil
+			},
+		}
+		h := NewAuthHandler(authSvc, &stubAuthAbuseGuard{}, cookieMgr, nil, stateKey, 24*time.Hour)
+		rr := httptest.NewRecorder()
+		h.Reauth(rr, reauthRequest(`{"provider":"github"}`))
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d body=%s", rr.Code, rr.Body.String())
+		}
+		var stateCookie *http.Cookie
+		for _, c := range rr.Result().Cookies() {
+			if c.Name == "oauth_state" {
+				stateCookie = c
+			}
+		}
+		flow, ok := security.VerifyOAuthFlowState(stateCookie.Value, stateKey)
+		if !ok {
+			t.Fatal("expected a signed reauth flow")
+		}
+
+		req := withURLParam(httptest.NewRequest(http.MethodGet, "/api/v1/auth/github/callback?state="+flow.State+"&code=abc", nil), "provider", "github")
+		req.AddCookie(stateCookie)
+		rr = httptest.NewRecorder()
+		h.OAuthCallback(rr, req)
+		if rr.Code != http.StatusOK || !hasCookie(rr.Result().Cookies(), "access_token") || hasCookie(rr.Result().Cookies(), "refresh_token") {
+			t.Fatalf("expected reauth callback to replace only the access cookie, got %d %+v", rr.Code, rr.Result().Cookies())
+		}
+	})
+}
+
+func TestAuthHandlerMagicLinkRequestAndConfirm(t *testing.T) {
+	cookieMgr := security.NewCookieManager("", false, "lax")
+
+	t.Run("request is enumeration safe and counts toward cooldown", func(t *testing.T) {
+		abuse := &stubAuthAbuseGuard{}
+		var scopes []service.AuthAbuseScope
+		abuse.checkFn = func(ctx context.Context, scope service.AuthAbuseScope, identity, ip string) (time.Duration, error) {
+			scopes = append(scopes, scope)
+			return 0, nil
+		}
+		h := NewAuthHandler(&stubAuthService{}, abuse, cookieMgr, nil, "state", 24*time.Hour)
+		rr := httptest.NewRecorder()
+		h.MagicLinkRequest(rr, httptest.NewRequest(http.MethodPost, "/api/v1/auth/magic/request", strings.NewReader(`{"email":"nobody@example.com"}`)))
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d", rr.Code)
+		}
+		if abuse.checkCalls != 1 || abuse.registerCalls != 1 || len(scopes) != 1 || scopes[0] != service.AuthAbuseScopeMagicLink {
+			t.Fatalf("expected magic_link abuse check and registration, got check=%d register=%d scopes=%v", abuse.checkCalls, abuse.registerCalls, scopes)
+		}
+	})
+
+	t.Run("request passes the preferred Accept-Language tag", func(t *testing.T) {
+		for header, want := range map[string]string{
+			"":                          "",
+			"de-DE":                     "de-de",
+			"en;q=0.5, fr-CA, fr;q=0.9": "fr-ca",
+			"*, es;q=0.2":               "es",
+			"../etc;q=1, pt-BR;q=0.3":   "pt-br",
+			"ja;q=bad":                  "",
+		} {
+			var got string
+			authSvc := &stubAuthService{requestMagicFn: func(email, locale string) error {
+				got = locale
+				return nil
+			}}
+			h := NewAuthHandler(authSvc, &stubAuthAbuseGuard{}, cookieMgr, nil, "state", 24*time.Hour)
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/magic/request", strings.NewReader(`{"email":"u@example.com"}`))
+			req.Header.Set("Accept-Language", header)
+			h.MagicLinkRequest(httptest.NewRecorder(), req)
+			if got != want {
+				t.Fatalf("Accept-Language %q: expected locale %q, got %q", header, want, got)
+			}
+		}
+	})
+
+	t.Run("request cooldown and disabled mapping", func(t *testing.T) {
+		abuse := &stubAuthAbuseGuard{checkFn: func(ctx context.Context, scope service.AuthAbuseScope, identity, ip string) (time.Duration, error) {
+			return 30 * time.Second, nil
+		}}
+		h := NewAuthHandler(&stubAuthService{}, abuse, cookieMgr, nil, "state", 24*time.Hour)
+		rr := httptest.NewRecorder()
+		h.MagicLinkRequest(rr, httptest.NewRequest(http.MethodPost, "/api/v1/auth/magic/request", strings.NewReader(`{"email":"u@example.com"}`)))
+		if rr.Code != http.StatusTooManyRequests || rr.Header().Get("Retry-After") == "" {
+			t.Fatalf("expected 429 with Retry-After, got %d", rr.Code)
+		}
+
+		authSvc := &stubAuthService{requestMagicFn: func(email, locale string) error { return service.ErrMagicLinkDisabled }}
+		h = NewAuthHandler(authSvc, &stubAuthAbuseGuard{}, cookieMgr, nil, "state", 24*time.Hour)
+		rr = httptest.NewRecorder()
+		h.MagicLinkRequest(rr, httptest.NewRequest(http.MethodPost, "/api/v1/auth/magic/request", strings.NewReader(`{"email":"u@example.com"}`)))
+		if rr.Code != http.StatusNotFound {
+			t.Fatalf("expected 404 when disabled, got %d", rr.Code)
+		}
+	})
+
+	t.Run("confirm error mappings", func(t *testing.T) {
+		cases := []struct {
+			err    error
+			status int
+			code   string
+		}{
+			{err: service.ErrInvalidVerifyToken, status: http.StatusUnauthorized, code: "INVALID_OR_EXPIRED_TOKEN"},
+			{err: service.ErrAccountInactive, status: http.StatusForbidden, code: "ACCOUNT_INACTIVE"},
+			{err: service.ErrMagicLinkDisabled, status: http.StatusNotFound, code: "NOT_ENABLED"},
+		}
+		for _, tc := range cases {
+			authSvc := &stubAuthService{confirmMagicFn: func(token, ua, ip string) (*service.LoginResult, error) { return nil, tc.err }}
+			h := NewAuthHandler(authSvc, &stubAuthAbuseGuard{}, cookieMgr, nil, "state", 24*time.Hour)
+			rr := httptest.NewRecorder()
+			h.MagicLinkConfirm(rr, httptest.NewRequest(http.MethodPost, "/api/v1/auth/magic/confirm", strings.NewReader(`{"token":"t"}`)))
+			if rr.Code != tc.status {
+				t.Fatalf("%v: expected %d, got %d", tc.err, tc.status, rr.Code)
+			}
+			if env := decodeAuthErrorEnvelope(t, rr); env.Error == nil || env.Error.Code != tc.code {
+				t.Fatalf("%v: expected %s, got %+v", tc.err, tc.code, env.Error)
+			}
+		}
+	})
+
+	t.Run("confirm sets cookies or returns mfa challenge", func(t *testing.T) {
+		authSvc := &stubAuthService{confirmMagicFn: func(token, ua, ip string) (*service.LoginResult, error) {
+			return &service.LoginResult{User: &domain.User{ID: 5}, AccessToken: "a", RefreshToken: "r", CSRFToken: "c"}, nil
+		}}
+		h := NewAuthHandler(authSvc, &stubAuthAbuseGuard{}, cookieMgr, nil, "state", 24*time.Hour)
+		rr := httptest.NewRecorder()
+		h.MagicLinkConfirm(rr, httptest.NewRequest(http.MethodPost, "/api/v1/auth/magic/confirm", strings.NewReader(`{"token":"t"}`)))
+		if rr.Code != http.StatusOK || !hasCookie(rr.Result().Cookies(), "access_token") {
+			t.Fatalf("expected session cookies, got %d", rr.Code)
+		}
+
+		authSvc.confirmMagicFn = func(token, ua, ip string) (*service.LoginResult, error) {
+			return &service.LoginResult{User: &domain.User{ID: 5}, MFARequired: true, MFAToken: "challenge"}, nil
+		}
+		rr = httptest.NewRecorder()
+		h.MagicLinkConfirm(rr, httptest.NewRequest(http.MethodPost, "/api/v1/auth/magic/confirm", strings.NewReader(`{"token":"t"}`)))
+		if rr.Code != http.StatusOK || hasCookie(rr.Result().Cookies(), "access_token") {
+			t.Fatalf("expected mfa challenge without cookies, got %d", rr.Code)
+		}
+	})
+}
@@ -0,0 +1,152 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"gorm.io/gorm"
+
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/http/middleware"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/http/response"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/observability"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/security"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/service"
+)
+
+// ImpersonationHandler swaps an admin's access cookie for one scoped to
+// another user and back. The admin's refresh and CSRF cookies are left
+// alone, so ending impersonation is a refresh of the admin's own session.
+type ImpersonationHandler struct {
+	svc                service.ImpersonationManager
+	authSvc            service.AuthServiceInterface
+	permissionResolver service.PermissionResolver
+	cookieMgr          *security.CookieManager
+	refreshTTL         time.Duration
+}
+
+func NewImpersonationHandler(
+	svc service.ImpersonationManager,
+	authSvc service.AuthServiceInterface,
+	permissionResolver service.PermissionResolver,
+	cookieMgr *security.CookieManager,
+	refreshTTL time.Duration,
+) *ImpersonationHandler {
+	return &ImpersonationHandler{
+		svc:                svc,
+		authSvc:            authSvc,
+		permissionResolver: permissionResolver,
+		cookieMgr:          cookieMgr,
+		refreshTTL:         refreshTTL,
+	}
+}
+
+func (h *ImpersonationHandler) Start(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.ClaimsFromContext(r.Context())
+	if !ok {
+		response.Error(w, r, http.StatusUnauthorized, "UNAUTHORIZED", "missing auth context", nil)
+		return
+	}
+	userID, err := parsePathID(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, r, http.StatusBadRequest, "BAD_REQUEST", "invalid user id", nil)
+		return
+	}
+	var body struct {
+		Reason string `json:"reason"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		response.Error(w, r, http.StatusBadRequest, "BAD_REQUEST", "invalid payload", nil)
+		return
+	}
+	reason := strings.TrimSpace(body.Reason)
+	if reason == "" || len(reason) > 512 {
+		response.Error(w, r, http.StatusBadRequest, "BAD_REQUEST", "reason is required and must be at most 512 characters", nil)
+		return
+	}
+	targetID := strconv.FormatUint(uint64(userID), 10)
+	audit := func(outcome, auditReason string) {
+		observability.EmitAudit(r, observability.AuditInput{
+			EventName:   "admin.user.impersonate",
+			ActorUserID: claims.Subject,
+			TargetType:  "user",
+			TargetID:    targetID,
+			Action:      "impersonate",
+			Outcome:     outcome,
+			Reason:      auditReason,
+		}, "justification", reason)
+	}
+
+	actorPerms, err := h.permissionResolver.ResolvePermissions(r.Context(), claims)
+	if err != nil {
+		response.Error(w, r, http.StatusServiceUnavailable, "RBAC_UNAVAILABLE", "permission resolution unavailable", nil)
+		return
+	}
+	result, err := h.svc.Start(claims, actorPerms, userID, r.UserAgent(), clientIP(r))
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			response.Error(w, r, http.StatusNotFound, "NOT_FOUND", "user not found", nil)
+		case errors.Is(err, service.ErrImpersonationNotAllowed):
+			audit("rejected", "caller_not_interactive")
+			response.Error(w, r, http.StatusForbidden, "FORBIDDEN", err.Error(), nil)
+		case errors.Is(err, service.ErrImpersonateSelf):
+			audit("rejected", "self")
+			response.Error(w, r, http.StatusForbidden, "FORBIDDEN", err.Error(), nil)
+		case errors.Is(err, service.ErrImpersonationPrivileged):
+			audit("rejected", "target_privileged")
+			response.Error(w, r, http.StatusForbidden, "FORBIDDEN", "cannot impersonate a user with permissions you do not hold", nil)
+		case errors.Is(err, service.ErrAccountInactive):
+			audit("rejected", "account_inactive")
+			response.Error(w, r, http.StatusConflict, "CONFLICT", "cannot impersonate an inactive account", nil)
+		default:
+			audit("error", "internal_error")
+			response.Error(w, r, http.StatusInternalServerError, "INTERNAL", "could not start impersonation", nil)
+		}
+		return
+	}
+	h.cookieMgr.SetAccessTokenCookie(w, result.AccessToken, time.Until(result.ExpiresAt))
+	audit("success", "impersonation_started")
+	response.JSON(w, r, http.StatusOK, result)
+}
+
+// End revokes the impersonation session and, when the admin's refresh
+// cookie is still valid, restores the admin's own session.
+func (h *ImpersonationHandler) End(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.ClaimsFromContext(r.Context())
+	if !ok {
+		response.Error(w, r, http.StatusUnauthorized, "UNAUTHORIZED", "missing auth context", nil)
+		return
+	}
+	actorID, err := h.svc.End(claims)
+	if err != nil {
+		if errors.Is(err, service.ErrNotImpersonating) {
+			response.Error(w, r, http.StatusBadRequest, "NOT_IMPERSONATING", err.Error(), nil)
+			return
+		}
+		auditAuth(r, "auth.impersonation.end", "end_impersonation", "failure", "revoke_error", claims.Act.Subject, "user", claims.Subject)
+		response.Error(w, r, http.StatusInternalServerError, "INTERNAL", "could not end impersonation", nil)
+		return
+	}
+	auditAuth(r, "auth.impersonation.end", "end_impersonation", "success", "session_revoked", observability.ActorUserID(actorID), "user", claims.Subject)
+
+	body := map[string]any{"status": "impersonation_ended"}
+	var result *service.LoginResult
+	if refresh := security.GetCookie(r, "refresh_token"); refresh != "" {
+		result, err = h.authSvc.Refresh(refresh, r.UserAgent(), clientIP(r))
+	}
+	if result == nil || err != nil || result.User.ID != actorID {
+		h.cookieMgr.ClearTokenCookies(w)
+		response.JSON(w, r, http.StatusOK, body)
+		return
+	}
+	h.cookieMgr.SetTokenCookies(w, result.AccessToken, result.RefreshToken, result.CSRFToken, h.refreshTTL)
+	body["user"] = result.User
+	body["csrf_token"] = result.CSRFToken
+	body["expires_at"] = result.ExpiresAt
+	response.JSON(w, r, http.StatusOK, body)
+}
@@ -0,0 +1,26 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/security"
+)
+
+type JWKSHandler struct {
+	keyring *security.Keyring
+}
+
+func NewJWKSHandler(keyring *security.Keyring) *JWKSHandler {
+	return &JWKSHandler{keyring: keyring}
+}
+
+// JWKS serves the raw RFC 7517 key set rather than the API envelope so that
+// off-the-shelf JWT libraries in other services can consume it directly. In
+// HS256 mode the set is empty because there is nothing safe to publish.
+func (h *JWKSHandler) JWKS(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/jwk-set+json")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(h.keyring.JWKS())
+}
@@ -0,0 +1,46 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/security"
+)
+
+func TestJWKSHandlerPublishesPublicKeysOnly(t *testing.T) {
+	priv, err := security.GenerateSigningKey(security.SigningAlgES256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := NewJWKSHandler(security.NewKeyring(security.SigningKey{
+		KID: "k1", Algorithm: security.SigningAlgES256, Private: priv, ActivatesAt: time.Now().Add(-time.Minute),
+	}))
+	rr := httptest.NewRecorder()
+	h.JWKS(rr, httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil))
+
+	if rr.Code != http.StatusOK || rr.Header().Get("Content-Type") != "application/jwk-set+json" {
+		t.Fatalf("unexpected response: status=%d content-type=%q", rr.Code, rr.Header().Get("Content-Type"))
+	}
+	var body map[string][]map[string]any
+	if err := json.Unmarshal(rr.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode jwks: %v", err)
+	}
+	keys := body["keys"]
+	if len(keys) != 1 || keys[0]["kid"] != "k1" || keys[0]["kty"] != "EC" || keys[0]["crv"] != "P-256" {
+		t.Fatalf("unexpected jwks body: %s", rr.Body.String())
+	}
+	if _, ok := keys[0]["d"]; ok {
+		t.Fatal("jwks must not expose private key material")
+	}
+}
+
+func TestJWKSHandlerEmptyWithoutKeyring(t *testing.T) {
+	rr := httptest.NewRecorder()
+	NewJWKSHandler(nil).JWKS(rr, httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil))
+	if rr.Code != http.StatusOK || rr.Body.String() != "{\"keys\":[]}\n" {
+		t.Fatalf("expected empty key set, got status=%d body=%q", rr.Code, rr.Body.String())
+	}
+}
@@ -0,0 +1,228 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/http/middleware"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/http/response"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/observability"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/service"
+)
+
+type OrganizationHandler struct {
+	svc                service.OrganizationServiceInterface
+	permissionResolver service.PermissionResolver
+}
+
+func NewOrganizationHandler(svc service.OrganizationServiceInterface, permissionResolver service.PermissionResolver) *OrganizationHandler {
+	return &OrganizationHandler{svc: svc, permissionResolver: permissionResolver}
+}
+
+type organizationCreateRequest struct {
+	Slug string `json:"slug"`
+	Name string `json:"name"`
+}
+
+// ListMine returns the organizations the caller is a member of.
+func (h *OrganizationHandler) ListMine(w http.ResponseWriter, r *http.Request) {
+	userID, _, err := authUserIDAndClaims(r)
+	if err != nil {
+		response.Error(w, r, http.StatusUnauthorized, "UNAUTHORIZED", "invalid auth context", nil)
+		return
+	}
+	orgs, err := h.svc.ListForUser(userID)
+	if err != nil {
+		response.Error(w, r, http.StatusInternalServerError, "INTERNAL", "failed to list organizations", nil)
+		return
+	}
+	response.JSON(w, r, http.StatusOK, orgs)
+}
+
+func (h *OrganizationHandler) List(w http.ResponseWriter, r *http.Request) {
+	orgs, err := h.svc.List()
+	if err != nil {
+		response.Error(w, r, http.StatusInternalServerError, "INTERNAL", "failed to list organizations", nil)
+		return
+	}
+	response.JSON(w, r, http.StatusOK, orgs)
+}
+
+func (h *OrganizationHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var body organizationCreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		response.Error(w, r, http.StatusBadRequest, "BAD_REQUEST", "invalid payload", nil)
+		return
+	}
+	org, err := h.svc.Create(service.OrganizationInput{Slug: body.Slug, Name: body.Name})
+	if err != nil {
+		observability.RecordAdminRBACMutation(r.Context(), "organization", "create", organizationMutationStatus(err))
+		writeOrganizationError(w, r, err)
+		return
+	}
+	observability.EmitAudit(r, observability.AuditInput{
+		EventName:   "admin.organization.create",
+		ActorUserID: adminActorID(r),
+		TargetType:  "organization",
+		TargetID:    strconv.FormatUint(uint64(org.ID), 10),
+		Action:      "create",
+		Outcome:     "success",
+		Reason:      "organization_created",
+	}, "slug", org.Slug)
+	observability.RecordAdminRBACMutation(r.Context(), "organization", "create", "success")
+	response.JSON(w, r, http.StatusCreated, org)
+}
+
+// Get returns the active organization. It runs behind OrganizationScope, so
+// reaching it means the caller may see the organization.
+func (h *OrganizationHandler) Get(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := service.OrganizationFromContext(r.Context())
+	if !ok {
+		response.Error(w, r, http.StatusBadRequest, "ORGANIZATION_REQUIRED", "organization id is required", nil)
+		return
+	}
+	org, err := h.svc.Get(orgID)
+	if err != nil {
+		writeOrganizationError(w, r, err)
+		return
+	}
+	response.JSON(w, r, http.StatusOK, org)
+}
+
+func (h *OrganizationHandler) ListMembers(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := service.OrganizationFromContext(r.Context())
+	if !ok {
+		response.Error(w, r, http.StatusBadRequest, "ORGANIZATION_REQUIRED", "organization id is required", nil)
+		return
+	}
+	members, err := h.svc.Members(orgID)
+	if err != nil {
+		writeOrganizationError(w, r, err)
+		return
+	}
+	response.JSON(w, r, http.StatusOK, members)
+}
+
+// SetMember adds a user to the active organization, or replaces an existing
+// member's organization roles.
+func (h *OrganizationHandler) SetMember(w http.ResponseWriter, r *http.Request) {
+	orgID, userID, ok := organizationMemberParams(w, r)
+	if !ok {
+		return
+	}
+	var body struct {
+		RoleIDs []uint `json:"role_ids"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		response.Error(w, r, http.StatusBadRequest, "BAD_REQUEST", "invalid payload", nil)
+		return
+	}
+	claims, ok := middleware.ClaimsFromContext(r.Context())
+	if !ok {
+		response.Error(w, r, http.StatusUnauthorized, "UNAUTHORIZED", "missing auth context", nil)
+		return
+	}
+	actorPerms, err := middleware.EffectivePermissions(r.Context(), h.permissionResolver, claims)
+	if err != nil {
+		response.Error(w, r, http.StatusServiceUnavailable, "RBAC_UNAVAILABLE", "permission resolution unavailable", nil)
+		return
+	}
+	member, err := h.svc.SetMember(orgID, userID, body.RoleIDs, actorPerms)
+	if err != nil {
+		observability.RecordAdminRBACMutation(r.Context(), "organization_member", "set", organizationMutationStatus(err))
+		writeOrganizationError(w, r, err)
+		return
+	}
+	observability.EmitAudit(r, observability.AuditInput{
+		EventName:   "org.member.update",
+		ActorUserID: adminActorID(r),
+		TargetType:  "user",
+		TargetID:    strconv.FormatUint(uint64(userID), 10),
+		Action:      "set_roles",
+		Outcome:     "success",
+		Reason:      "member_roles_updated",
+	}, "organization_id", orgID, "role_ids", body.RoleIDs)
+	observability.RecordAdminRBACMutation(r.Context(), "organization_member", "set", "success")
+	h.invalidatePermissionCacheUser(r, userID)
+	response.JSON(w, r, http.StatusOK, member)
+}
+
+func (h *OrganizationHandler) RemoveMember(w http.ResponseWriter, r *http.Request) {
+	orgID, userID, ok := organizationMemberParams(w, r)
+	if !ok {
+		return
+	}
+	if err := h.svc.RemoveMember(orgID, userID); err != nil {
+		observability.RecordAdminRBACMutation(r.Context(), "organization_member", "remove", organizationMutationStatus(err))
+		writeOrganizationError(w, r, err)
+		return
+	}
+	observability.EmitAudit(r, observability.AuditInput{
+		EventName:   "org.member.remove",
+		ActorUserID: adminActorID(r),
+		TargetType:  "user",
+		TargetID:    strconv.FormatUint(uint64(userID), 10),
+		Action:      "remove",
+		Outcome:     "success",
+		Reason:      "member_removed",
+	}, "organization_id", orgID)
+	observability.RecordAdminRBACMutation(r.Context(), "organization_member", "remove", "success")
+	h.invalidatePermissionCacheUser(r, userID)
+	response.JSON(w, r, http.StatusOK, map[string]any{"organization_id": orgID, "user_id": userID, "status": "removed"})
+}
+
+func (h *OrganizationHandler) invalidatePermissionCacheUser(r *http.Request, userID uint) {
+	if h.permissionResolver == nil {
+		return
+	}
+	if err := h.permissionResolver.InvalidateUser(r.Context(), userID); err != nil {
+		observability.RecordRBACPermissionCacheEvent(r.Context(), "invalidate_user_error")
+		slog.Warn("rbac permission cache user invalidation failed", "user_id", userID, "path", r.URL.Path, "error", err)
+	}
+}
+
+func organizationMemberParams(w http.ResponseWriter, r *http.Request) (uint, uint, bool) {
+	orgID, ok := service.OrganizationFromContext(r.Context())
+	if !ok {
+		response.Error(w, r, http.StatusBadRequest, "ORGANIZATION_REQUIRED", "organization id is required", nil)
+		return 0, 0, false
+	}
+	userID, err := parsePathID(chi.URLParam(r, "userID"))
+	if err != nil {
+		response.Error(w, r, http.StatusBadRequest, "BAD_REQUEST", "invalid user id", nil)
+		return 0, 0, false
+	}
+	return orgID, userID, true
+}
+
+func writeOrganizationError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidOrganizationRequest):
+		response.Error(w, r, http.StatusBadRequest, "BAD_REQUEST", "slug and name are required; user and role_ids must reference existing records", nil)
+	case errors.Is(err, service.ErrOrganizationConflict):
+		response.Error(w, r, http.StatusConflict, "CONFLICT", "organization slug already exists", nil)
+	case errors.Is(err, service.ErrOrganizationRoleNotAllowed):
+		response.Error(w, r, http.StatusForbidden, "FORBIDDEN", "cannot bind a role with permissions you do not hold in this organization", nil)
+	case errors.Is(err, service.ErrOrganizationNotFound):
+		response.Error(w, r, http.StatusNotFound, "NOT_FOUND", "organization not found", nil)
+	case errors.Is(err, service.ErrOrganizationMemberNotFound):
+		response.Error(w, r, http.StatusNotFound, "NOT_FOUND", "organization member not found", nil)
+	default:
+		response.Error(w, r, http.StatusInternalServerError, "INTERNAL", "organization operation failed", nil)
+	}
+}
+
+func organizationMutationStatus(err error) string {
+	switch {
+	case errors.Is(err, service.ErrInvalidOrganizationRequest), errors.Is(err, service.ErrOrganizationConflict),
+		errors.Is(err, service.ErrOrganizationRoleNotAllowed), errors.Is(err, service.ErrOrganizationNotFound),
+		errors.Is(err, service.ErrOrganizationMemberNotFound):
+		return "rejected"
+	}
+	return "error"
+}
@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/http/middleware"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/security"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/service"
+)
+
+type stubOrganizationService struct {
+	setMemberFn func(orgID, userID uint, roleIDs []uint, actorPerms []string) (*service.OrganizationMemberView, error)
+}
+
+func (s *stubOrganizationService) Create(service.OrganizationInput) (*service.OrganizationView, error) {
+	return nil, service.ErrInvalidOrganizationRequest
+}
+
+func (s *stubOrganizationService) List() ([]service.OrganizationView, error) { return nil, nil }
+
+func (s *stubOrganizationService) ListForUser(uint) ([]service.OrganizationView, error) {
+	return nil, nil
+}
+
+func (s *stubOrganizationService) Get(uint) (*service.OrganizationView, error) {
+	return nil, service.ErrOrganizationNotFound
+}
+
+func (s *stubOrganizationService) Members(uint) ([]service.OrganizationMemberView, error) {
+	return nil, nil
+}
+
+func (s *stubOrganizationService) SetMember(orgID, userID uint, roleIDs []uint, actorPerms []string) (*service.OrganizationMemberView, error) {
+	return s.setMemberFn(orgID, userID, roleIDs, actorPerms)
+}
+
+func (s *stubOrganizationService) RemoveMember(uint, uint) error {
+	return service.ErrOrganizationMemberNotFound
+}
+
+type scopedPermissionResolver struct {
+	stubPermissionResolver
+}
+
+// ResolvePermissions mimics the real resolver: org bindings only show up when
+// the context is scoped.
+func (s *scopedPermissionResolver) ResolvePermissions(ctx context.Context, _ *security.Claims) ([]string, error) {
+	if _, ok := service.OrganizationFromContext(ctx); ok {
+		return []string{"members:write", "members:read"}, nil
+	}
+	return []string{"members:read"}, nil
+}
+
+func newOrganizationMemberRequest(orgID uint, userID string, body string) *http.Request {
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/orgs/1/members/"+userID, strings.NewReader(body))
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("userID", userID)
+	ctx := context.WithValue(req.Context(), chi.RouteCtxKey, rctx)
+	claims := &security.Claims{}
+	claims.Subject = "7"
+	ctx = context.WithValue(ctx, middleware.ClaimsContextKey, claims)
+	if orgID != 0 {
+		ctx = service.WithOrganization(ctx, orgID)
+	}
+	return req.WithContext(ctx)
+}
+
+func TestOrganizationHandlerSetMemberUsesScopedPermissions(t *testing.T) {
+	resolver := &scopedPermissionResolver{}
+	var gotPerms []string
+	svc := &stubOrganizationService{setMemberFn: func(orgID, userID uint, roleIDs []uint, actorPerms []string) (*service.OrganizationMemberView, error) {
+		if orgID != 1 || userID != 42 || len(roleIDs) != 1 || roleIDs[0] != 3 {
+			t.Fatalf("unexpected set member call org=%d user=%d roles=%v", orgID, userID, roleIDs)
+		}
+		gotPerms = actorPerms
+		return &service.OrganizationMemberView{UserID: userID, Roles: []string{"org-admin"}}, nil
+	}}
+	h := NewOrganizationHandler(svc, resolver)
+
+	rr := httptest.NewRecorder()
+	h.SetMember(rr, newOrganizationMemberRequest(1, "42", `{"role_ids":[3]}`))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d body=%s", rr.Code, rr.Body.String())
+	}
+	if strings.Join(gotPerms, ",") != "members:write,members:read" {
+		t.Fatalf("expected org-scoped actor permissions, got %v", gotPerms)
+	}
+	if len(resolver.invalidateUserCalls) != 1 || resolver.invalidateUserCalls[0] != 42 {
+		t.Fatalf("expected member permission cache to be invalidated, got %v", resolver.invalidateUserCalls)
+	}
+}
+
+func TestOrganizationHandlerSetMemberErrors(t *testing.T) {
+	cases := []struct {
+		name   string
+		orgID  uint
+		userID string
+		err    error
+		status int
+	}{
+		{name: "escalation", orgID: 1, userID: "42", err: service.ErrOrganizationRoleNotAllowed, status: http.StatusForbidden},
+		{name: "unknown role", orgID: 1, userID: "42", err: service.ErrInvalidOrganizationRequest, status: http.StatusBadRequest},
+		{name: "missing scope", orgID: 0, userID: "42", status: http.StatusBadRequest},
+		{name: "bad user id", orgID: 1, userID: "abc", status: http.StatusBadRequest},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := &stubOrganizationService{setMemberFn: func(uint, uint, []uint, []string) (*service.OrganizationMemberView, error) {
+				if tc.err == nil {
+					t.Fatal("expected the handler to reject the request before the service")
+				}
+				return nil, tc.err
+			}}
+			rr := httptest.NewRecorder()
+			NewOrganizationHandler(svc, &scopedPermissionResolver{}).SetMember(rr, newOrganizationMemberRequest(tc.orgID, tc.userID, `{"role_ids":[3]}`))
+			if rr.Code != tc.status {
+				t.Fatalf("expected %d, got %d body=%s", tc.status, rr.Code, rr.Body.String())
+			}
+		})
+	}
+}
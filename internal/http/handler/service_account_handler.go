@@ -0,0 +1,276 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/http/response"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/observability"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/security"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/service"
+)
+
+type ServiceAccountHandler struct {
+	svc service.ServiceAccountServiceInterface
+}
+
+func NewServiceAccountHandler(svc service.ServiceAccountServiceInterface) *ServiceAccountHandler {
+	return &ServiceAccountHandler{svc: svc}
+}
+
+type serviceAccountCreateRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	RoleIDs     []uint `json:"role_ids"`
+}
+
+type serviceAccountUpdateRequest struct {
+	Name        *string `json:"name"`
+	Description *string `json:"description"`
+	Disabled    *bool   `json:"disabled"`
+	RoleIDs     *[]uint `json:"role_ids"`
+}
+
+// Token implements the RFC 6749 client_credentials grant. Like the JWKS
+// endpoint it answers in the standard OAuth shape rather than the API
+// envelope so stock OAuth client libraries work against it.
+func (h *ServiceAccountHandler) Token(w http.ResponseWriter, r *http.Request) {
+	outcome := "success"
+	defer func() {
+		observability.RecordServiceAccountToken(r.Context(), outcome)
+	}()
+	if err := r.ParseForm(); err != nil {
+		outcome = "invalid_request"
+		writeOAuthError(w, http.StatusBadRequest, "invalid_request")
+		return
+	}
+	if r.PostForm.Get("grant_type") != "client_credentials" {
+		outcome = "unsupported_grant_type"
+		writeOAuthError(w, http.StatusBadRequest, "unsupported_grant_type")
+		return
+	}
+	clientID, clientSecret, ok := clientCredentialsFromRequest(r)
+	if !ok {
+		outcome = "invalid_request"
+		writeOAuthError(w, http.StatusBadRequest, "invalid_request")
+		return
+	}
+	token, err := h.svc.IssueToken(r.Context(), clientID, clientSecret, r.PostForm.Get("scope"))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidClient), errors.Is(err, service.ErrServiceAccountsDisabled):
+			outcome = "invalid_client"
+			auditAuth(r, "auth.service_account.token", "client_credentials", "failure", "invalid_client", "anonymous", "service_account", clientID)
+			w.Header().Set("WWW-Authenticate", `Basic realm="oauth"`)
+			writeOAuthError(w, http.StatusUnauthorized, "invalid_client")
+		case errors.Is(err, service.ErrInvalidScope):
+			outcome = "invalid_scope"
+			auditAuth(r, "auth.service_account.token", "client_credentials", "failure", "invalid_scope", "anonymous", "service_account", clientID)
+			writeOAuthError(w, http.StatusBadRequest, "invalid_scope")
+		default:
+			outcome = "error"
+			writeOAuthError(w, http.StatusInternalServerError, "server_error")
+		}
+		return
+	}
+	actor := security.ServiceAccountSubject(token.AccountID)
+	auditAuth(r, "auth.service_account.token", "client_credentials", "success", "token_issued", actor, "service_account", clientID, "scope", token.Scope)
+	writeOAuthJSON(w, http.StatusOK, token)
+}
+
+func (h *ServiceAccountHandler) List(w http.ResponseWriter, r *http.Request) {
+	accounts, err := h.svc.List()
+	if err != nil {
+		response.Error(w, r, http.StatusInternalServerError, "INTERNAL", "failed to list service accounts", nil)
+		return
+	}
+	response.JSON(w, r, http.StatusOK, accounts)
+}
+
+func (h *ServiceAccountHandler) Get(w http.ResponseWriter, r *http.Request) {
+	id, ok := serviceAccountIDParam(w, r)
+	if !ok {
+		return
+	}
+	account, err := h.svc.Get(id)
+	if err != nil {
+		writeServiceAccountError(w, r, err)
+		return
+	}
+	response.JSON(w, r, http.StatusOK, account)
+}
+
+func (h *ServiceAccountHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var body serviceAccountCreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		response.Error(w, r, http.StatusBadRequest, "BAD_REQUEST", "invalid payload", nil)
+		return
+	}
+	created, err := h.svc.Create(service.ServiceAccountInput{Name: body.Name, Description: body.Description, RoleIDs: body.RoleIDs})
+	if err != nil {
+		observability.RecordAdminRBACMutation(r.Context(), "service_account", "create", serviceAccountMutationStatus(err))
+		writeServiceAccountError(w, r, err)
+		return
+	}
+	observability.EmitAudit(r, observability.AuditInput{
+		EventName:   "admin.service_account.create",
+		ActorUserID: adminActorID(r),
+		TargetType:  "service_account",
+		TargetID:    strconv.FormatUint(uint64(created.ID), 10),
+		Action:      "create",
+		Outcome:     "success",
+		Reason:      "service_account_created",
+	}, "client_id", created.ClientID, "roles", created.Roles)
+	observability.RecordAdminRBACMutation(r.Context(), "service_account", "create", "success")
+	response.JSON(w, r, http.StatusCreated, created)
+}
+
+func (h *ServiceAccountHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id, ok := serviceAccountIDParam(w, r)
+	if !ok {
+		return
+	}
+	var body serviceAccountUpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		response.Error(w, r, http.StatusBadRequest, "BAD_REQUEST", "invalid payload", nil)
+		return
+	}
+	updated, err := h.svc.Update(id, service.ServiceAccountUpdate{
+		Name:        body.Name,
+		Description: body.Description,
+		Disabled:    body.Disabled,
+		RoleIDs:     body.RoleIDs,
+	})
+	if err != nil {
+		observability.RecordAdminRBACMutation(r.Context(), "service_account", "update", serviceAccountMutationStatus(err))
+		writeServiceAccountError(w, r, err)
+		return
+	}
+	observability.EmitAudit(r, observability.AuditInput{
+		EventName:   "admin.service_account.update",
+		ActorUserID: adminActorID(r),
+		TargetType:  "service_account",
+		TargetID:    strconv.FormatUint(uint64(id), 10),
+		Action:      "update",
+		Outcome:     "success",
+		Reason:      "service_account_updated",
+	}, "roles", updated.Roles, "disabled", updated.Disabled)
+	observability.RecordAdminRBACMutation(r.Context(), "service_account", "update", "success")
+	response.JSON(w, r, http.StatusOK, updated)
+}
+
+func (h *ServiceAccountHandler) RotateSecret(w http.ResponseWriter, r *http.Request) {
+	id, ok := serviceAccountIDParam(w, r)
+	if !ok {
+		return
+	}
+	rotated, err := h.svc.RotateSecret(id)
+	if err != nil {
+		observability.RecordAdminRBACMutation(r.Context(), "service_account", "rotate_secret", serviceAccountMutationStatus(err))
+		writeServiceAccountError(w, r, err)
+		return
+	}
+	observability.EmitAudit(r, observability.AuditInput{
+		EventName:   "admin.service_account.rotate_secret",
+		ActorUserID: adminActorID(r),
+		TargetType:  "service_account",
+		TargetID:    strconv.FormatUint(uint64(id), 10),
+		Action:      "rotate_secret",
+		Outcome:     "success",
+		Reason:      "secret_rotated",
+	})
+	observability.RecordAdminRBACMutation(r.Context(), "service_account", "rotate_secret", "success")
+	response.JSON(w, r, http.StatusOK, rotated)
+}
+
+func (h *ServiceAccountHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, ok := serviceAccountIDParam(w, r)
+	if !ok {
+		return
+	}
+	if err := h.svc.Delete(id); err != nil {
+		observability.RecordAdminRBACMutation(r.Context(), "service_account", "delete", serviceAccountMutationStatus(err))
+		writeServiceAccountError(w, r, err)
+		return
+	}
+	observability.EmitAudit(r, observability.AuditInput{
+		EventName:   "admin.service_account.delete",
+		ActorUserID: adminActorID(r),
+		TargetType:  "service_account",
+		TargetID:    strconv.FormatUint(uint64(id), 10),
+		Action:      "delete",
+		Outcome:     "success",
+		Reason:      "service_account_deleted",
+	})
+	observability.RecordAdminRBACMutation(r.Context(), "service_account", "delete", "success")
+	response.JSON(w, r, http.StatusOK, map[string]any{"service_account_id": id, "status": "deleted"})
+}
+
+// clientCredentialsFromRequest accepts HTTP Basic (preferred by RFC 6749) or
+// client_id/client_secret form fields, but not both.
+func clientCredentialsFromRequest(r *http.Request) (string, string, bool) {
+	formID, formSecret := r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+	if user, pass, ok := r.BasicAuth(); ok {
+		if formSecret != "" {
+			return "", "", false
+		}
+		id, err := url.QueryUnescape(user)
+		if err != nil {
+			return "", "", false
+		}
+		secret, err := url.QueryUnescape(pass)
+		if err != nil {
+			return "", "", false
+		}
+		return id, secret, id != ""
+	}
+	return formID, formSecret, formID != "" && formSecret != ""
+}
+
+func serviceAccountIDParam(w http.ResponseWriter, r *http.Request) (uint, bool) {
+	id, err := parsePathID(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, r, http.StatusBadRequest, "BAD_REQUEST", "invalid service account id", nil)
+		return 0, false
+	}
+	return id, true
+}
+
+func writeServiceAccountError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, service.ErrServiceAccountsDisabled):
+		response.Error(w, r, http.StatusNotFound, "NOT_ENABLED", "service accounts are disabled", nil)
+	case errors.Is(err, service.ErrInvalidServiceAccountRequest):
+		response.Error(w, r, http.StatusBadRequest, "BAD_REQUEST", "name is required and role_ids must reference existing roles", nil)
+	case errors.Is(err, service.ErrServiceAccountConflict):
+		response.Error(w, r, http.StatusConflict, "CONFLICT", "service account name already exists", nil)
+	case errors.Is(err, service.ErrServiceAccountNotFound):
+		response.Error(w, r, http.StatusNotFound, "NOT_FOUND", "service account not found", nil)
+	default:
+		response.Error(w, r, http.StatusInternalServerError, "INTERNAL", "service account operation failed", nil)
+	}
+}
+
+func serviceAccountMutationStatus(err error) string {
+	if errors.Is(err, service.ErrInvalidServiceAccountRequest) || errors.Is(err, service.ErrServiceAccountConflict) || errors.Is(err, service.ErrServiceAccountNotFound) {
+		return "rejected"
+	}
+	return "error"
+}
+
+func writeOAuthJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Pragma", "no-cache")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeOAuthError(w http.ResponseWriter, status int, code string) {
+	writeOAuthJSON(w, status, map[string]string{"error": code})
+}
@@ -0,0 +1,193 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/service"
+)
+
+type stubServiceAccountService struct {
+	createFn func(input service.ServiceAccountInput) (*service.ServiceAccountCredentials, error)
+	issueFn  func(clientID, clientSecret, scope string) (*service.ServiceAccountToken, error)
+	deleteFn func(id uint) error
+}
+
+func (s *stubServiceAccountService) Create(input service.ServiceAccountInput) (*service.ServiceAccountCredentials, error) {
+	if s.createFn != nil {
+		return s.createFn(input)
+	}
+	return nil, service.ErrServiceAccountsDisabled
+}
+
+func (s *stubServiceAccountService) List() ([]service.ServiceAccountView, error) {
+	return nil, nil
+}
+
+func (s *stubServiceAccountService) Get(uint) (*service.ServiceAccountView, error) {
+	return nil, service.ErrServiceAccountNotFound
+}
+
+func (s *stubServiceAccountService) Update(uint, service.ServiceAccountUpdate) (*service.ServiceAccountView, error) {
+	return nil, service.ErrServiceAccountNotFound
+}
+
+func (s *stubServiceAccountService) RotateSecret(uint) (*service.ServiceAccountCredentials, error) {
+	return nil, service.ErrServiceAccountNotFound
+}
+
+func (s *stubServiceAccountService) Delete(id uint) error {
+	if s.deleteFn != nil {
+		return s.deleteFn(id)
+	}
+	return nil
+}
+
+func (s *stubServiceAccountService) IssueToken(_ context.Context, clientID, clientSecret, scope string) (*service.ServiceAccountToken, error) {
+	if s.issueFn != nil {
+		return s.issueFn(clientID, clientSecret, scope)
+	}
+	return nil, service.ErrInvalidClient
+}
+
+func newTokenRequest(form url.Values) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/oauth/token", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return req
+}
+
+func decodeOAuthBody(t *testing.T, rr *httptest.ResponseRecorder) map[string]any {
+	t.Helper()
+	var body map[string]any
+	if err := json.Unmarshal(rr.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode oauth body: %v", err)
+	}
+	return body
+}
+
+func TestServiceAccountHandlerTokenErrors(t *testing.T) {
+	cases := []struct {
+		name       string
+		form       url.Values
+		basic      bool
+		err        error
+		wantCode   int
+		wantError  string
+		wantHeader bool
+	}{
+		{name: "unsupported grant", form: url.Values{"grant_type": {"password"}}, wantCode: http.StatusBadRequest, wantError: "unsupported_grant_type"},
+		{name: "missing credentials", form: url.Values{"grant_type": {"client_credentials"}}, wantCode: http.StatusBadRequest, wantError: "invalid_request"},
+		{name: "basic and form secret", form: url.Values{"grant_type": {"client_credentials"}, "client_secret": {"s"}}, basic: true, wantCode: http.StatusBadRequest, wantError: "invalid_request"},
+		{name: "invalid client", form: url.Values{"grant_type": {"client_credentials"}, "client_id": {"sa_1"}, "client_secret": {"bad"}}, err: service.ErrInvalidClient, wantCode: http.StatusUnauthorized, wantError: "invalid_client", wantHeader: true},
+		{name: "invalid scope", form: url.Values{"grant_type": {"client_credentials"}, "client_id": {"sa_1"}, "client_secret": {"s"}, "scope": {"users:write"}}, err: service.ErrInvalidScope, wantCode: http.StatusBadRequest, wantError: "invalid_scope"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := &stubServiceAccountService{
+				issueFn: func(string, string, string) (*service.ServiceAccountToken, error) { return nil, tc.err },
+			}
+			req := newTokenRequest(tc.form)
+			if tc.basic {
+				req.SetBasicAuth("sa_1", "s")
+			}
+			rr := httptest.NewRecorder()
+
+			NewServiceAccountHandler(svc).Token(rr, req)
+
+			if rr.Code != tc.wantCode {
+				t.Fatalf("expected %d, got %d body=%s", tc.wantCode, rr.Code, rr.Body.String())
+			}
+			if body := decodeOAuthBody(t, rr); body["error"] != tc.wantError {
+				t.Fatalf("expected oauth error %q, got %v", tc.wantError, body)
+			}
+			if got := rr.Header().Get("WWW-Authenticate") != ""; got != tc.wantHeader {
+				t.Fatalf("unexpected WWW-Authenticate presence %v", got)
+			}
+		})
+	}
+}
+
+func TestServiceAccountHandlerTokenAcceptsBasicAuth(t *testing.T) {
+	var gotID, gotSecret, gotScope string
+	svc := &stubServiceAccountService{
+		issueFn: func(clientID, clientSecret, scope string) (*service.ServiceAccountToken, error) {
+			gotID, gotSecret, gotScope = clientID, clientSecret, scope
+			return &service.ServiceAccountToken{AccountID: 4, AccessToken: "tok", TokenType: "Bearer", ExpiresIn: 900, Scope: scope}, nil
+		},
+	}
+	req := newTokenRequest(url.Values{"grant_type": {"client_credentials"}, "scope": {"users:read"}})
+	req.SetBasicAuth("sa_abc", url.QueryEscape("s3cr:et"))
+	rr := httptest.NewRecorder()
+
+	NewServiceAccountHandler(svc).Token(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d body=%s", rr.Code, rr.Body.String())
+	}
+	if gotID != "sa_abc" || gotSecret != "s3cr:et" || gotScope != "users:read" {
+		t.Fatalf("unexpected credentials id=%q secret=%q scope=%q", gotID, gotSecret, gotScope)
+	}
+	if rr.Header().Get("Cache-Control") != "no-store" {
+		t.Fatalf("expected no-store, got %q", rr.Header().Get("Cache-Control"))
+	}
+	body := decodeOAuthBody(t, rr)
+	if body["access_token"] != "tok" || body["token_type"] != "Bearer" || body["expires_in"] != float64(900) {
+		t.Fatalf("unexpected token body %v", body)
+	}
+	if _, leaked := body["AccountID"]; leaked {
+		t.Fatalf("account id must not be serialized: %v", body)
+	}
+}
+
+func TestServiceAccountHandlerAdminErrorMappings(t *testing.T) {
+	cases := []struct {
+		name     string
+		err      error
+		wantCode int
+		wantErr  string
+	}{
+		{name: "not enabled", err: service.ErrServiceAccountsDisabled, wantCode: http.StatusNotFound, wantErr: "NOT_ENABLED"},
+		{name: "invalid request", err: service.ErrInvalidServiceAccountRequest, wantCode: http.StatusBadRequest, wantErr: "BAD_REQUEST"},
+		{name: "conflict", err: service.ErrServiceAccountConflict, wantCode: http.StatusConflict, wantErr: "CONFLICT"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := &stubServiceAccountService{
+				createFn: func(service.ServiceAccountInput) (*service.ServiceAccountCredentials, error) { return nil, tc.err },
+			}
+			req := withClaims(httptest.NewRequest(http.MethodPost, "/api/v1/admin/service-accounts", strings.NewReader(`{"name":"job"}`)), "9")
+			rr := httptest.NewRecorder()
+
+			NewServiceAccountHandler(svc).Create(rr, req)
+
+			if rr.Code != tc.wantCode {
+				t.Fatalf("expected %d, got %d", tc.wantCode, rr.Code)
+			}
+			if env := decodeAuthErrorEnvelope(t, rr); env.Error == nil || env.Error.Code != tc.wantErr {
+				t.Fatalf("expected error code %q, got %+v", tc.wantErr, env.Error)
+			}
+		})
+	}
+
+	t.Run("delete not found", func(t *testing.T) {
+		svc := &stubServiceAccountService{deleteFn: func(uint) error { return service.ErrServiceAccountNotFound }}
+		req := withClaims(httptest.NewRequest(http.MethodDelete, "/api/v1/admin/service-accounts/5", nil), "9")
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", "5")
+		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+		rr := httptest.NewRecorder()
+
+		NewServiceAccountHandler(svc).Delete(rr, req)
+
+		if rr.Code != http.StatusNotFound {
+			t.Fatalf("expected 404, got %d", rr.Code)
+		}
+	})
+}
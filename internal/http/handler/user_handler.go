@@ -1,12 +1,16 @@
 package handler
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
 
 	"github.com/go-chi/chi/v5"
+	"gorm.io/gorm"
 
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/domain"
 	"github.com/sandeepkv93/everything-backend-starter-kit/internal/http/middleware"
 	"github.com/sandeepkv93/everything-backend-starter-kit/internal/http/response"
 	"github.com/sandeepkv93/everything-backend-starter-kit/internal/observability"
@@ -16,14 +20,23 @@ import (
 )
 
 type UserHandler struct {
-	userSvc    service.UserServiceInterface
-	sessionSvc service.SessionServiceInterface
+	userSvc        service.UserServiceInterface
+	sessionSvc     service.SessionServiceInterface
+	emailChangeSvc service.EmailChangeManager
+	accountSvc     service.AccountDataManager
 }
 
-func NewUserHandler(userSvc service.UserServiceInterface, sessionSvc service.SessionServiceInterface) *UserHandler {
+func NewUserHandler(
+	userSvc service.UserServiceInterface,
+	sessionSvc service.SessionServiceInterface,
+	emailChangeSvc service.EmailChangeManager,
+	accountSvc service.AccountDataManager,
+) *UserHandler {
 	return &UserHandler{
-		userSvc:    userSvc,
-		sessionSvc: sessionSvc,
+		userSvc:        userSvc,
+		sessionSvc:     sessionSvc,
+		emailChangeSvc: emailChangeSvc,
+		accountSvc:     accountSvc,
 	}
 }
 
@@ -167,6 +180,283 @@ func (h *UserHandler) RevokeOtherSessions(w http.ResponseWriter, r *http.Request
 	})
 }
 
+func (h *UserHandler) RequestEmailChange(w http.ResponseWriter, r *http.Request) {
+	userID, _, err := authUserIDAndClaims(r)
+	if err != nil {
+		observability.RecordUserEmailChangeEvent(r.Context(), "request", "unauthorized")
+		response.Error(w, r, http.StatusUnauthorized, "UNAUTHORIZED", "invalid user", nil)
+		return
+	}
+	actor := observability.ActorUserID(userID)
+	var req struct {
+		NewEmail string `json:"new_email"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		observability.RecordUserEmailChangeEvent(r.Context(), "request", "bad_request")
+		response.Error(w, r, http.StatusBadRequest, "BAD_REQUEST", "invalid payload", nil)
+		return
+	}
+	if err := h.emailChangeSvc.RequestEmailChange(userID, req.NewEmail, requestLocale(r)); err != nil {
+		audit := observability.AuditInput{
+			EventName:   "user.email.change.request",
+			ActorUserID: actor,
+			TargetType:  "user",
+			TargetID:    actor,
+			Action:      "email_change_request",
+			Outcome:     "rejected",
+		}
+		switch {
+		case errors.Is(err, service.ErrInvalidEmailChange):
+			audit.Reason = "invalid_email"
+			observability.EmitAudit(r, audit)
+			observability.RecordUserEmailChangeEvent(r.Context(), "request", "bad_request")
+			response.Error(w, r, http.StatusBadRequest, "BAD_REQUEST", "new email must be a valid address that differs from the current one", nil)
+		case errors.Is(err, service.ErrEmailTaken):
+			audit.Reason = "email_taken"
+			observability.EmitAudit(r, audit)
+			observability.RecordUserEmailChangeEvent(r.Context(), "request", "conflict")
+			response.Error(w, r, http.StatusConflict, "CONFLICT", "email already in use", nil)
+		default:
+			audit.Outcome = "failure"
+			audit.Reason = "service_error"
+			observability.EmitAudit(r, audit, "error", err.Error())
+			observability.RecordUserEmailChangeEvent(r.Context(), "request", "error")
+			response.Error(w, r, http.StatusInternalServerError, "INTERNAL", "email change request failed", nil)
+		}
+		return
+	}
+	observability.EmitAudit(r, observability.AuditInput{
+		EventName:   "user.email.change.request",
+		ActorUserID: actor,
+		TargetType:  "user",
+		TargetID:    actor,
+		Action:      "email_change_request",
+		Outcome:     "accepted",
+		Reason:      "confirmation_sent",
+	})
+	observability.RecordUserEmailChangeEvent(r.Context(), "request", "accepted")
+	response.JSON(w, r, http.StatusAccepted, map[string]string{"status": "confirmation sent to the new address"})
+}
+
+func (h *UserHandler) ConfirmEmailChange(w http.ResponseWriter, r *http.Request) {
+	userID, claims, err := authUserIDAndClaims(r)
+	if err != nil {
+		observability.RecordUserEmailChangeEvent(r.Context(), "confirm", "unauthorized")
+		response.Error(w, r, http.StatusUnauthorized, "UNAUTHORIZED", "invalid user", nil)
+		return
+	}
+	actor := observability.ActorUserID(userID)
+	var req struct {
+		Token               string `json:"token"`
+		RevokeOtherSessions bool   `json:"revoke_other_sessions"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		observability.RecordUserEmailChangeEvent(r.Context(), "confirm", "bad_request")
+		response.Error(w, r, http.StatusBadRequest, "BAD_REQUEST", "invalid payload", nil)
+		return
+	}
+	// Resolve the session to keep before the token is spent, so a caller
+	// without one cannot end up with the email moved but nothing revoked.
+	var currentSessionID uint
+	if req.RevokeOtherSessions {
+		currentSessionID, err = h.sessionSvc.ResolveCurrentSessionID(r, claims, userID)
+		if err != nil {
+			observability.RecordUserEmailChangeEvent(r.Context(), "confirm", "unauthorized")
+			response.Error(w, r, http.StatusUnauthorized, "UNAUTHORIZED", "unable to determine current session", nil)
+			return
+		}
+	}
+	result, err := h.emailChangeSvc.ConfirmEmailChange(userID, req.Token)
+	if err != nil {
+		audit := observability.AuditInput{
+			EventName:   "user.email.change.confirm",
+			ActorUserID: actor,
+			TargetType:  "user",
+			TargetID:    actor,
+			Action:      "email_change_confirm",
+			Outcome:     "rejected",
+		}
+		switch {
+		case errors.Is(err, service.ErrInvalidVerifyToken):
+			audit.Reason = "invalid_token"
+			observability.EmitAudit(r, audit)
+			observability.RecordUserEmailChangeEvent(r.Context(), "confirm", "invalid_token")
+			response.Error(w, r, http.StatusBadRequest, "INVALID_OR_EXPIRED_TOKEN", "invalid or expired token", nil)
+		case errors.Is(err, service.ErrEmailTaken):
+			audit.Reason = "email_taken"
+			observability.EmitAudit(r, audit)
+			observability.RecordUserEmailChangeEvent(r.Context(), "confirm", "conflict")
+			response.Error(w, r, http.StatusConflict, "CONFLICT", "email already in use", nil)
+		default:
+			audit.Outcome = "failure"
+			audit.Reason = "service_error"
+			observability.EmitAudit(r, audit, "error", err.Error())
+			observability.RecordUserEmailChangeEvent(r.Context(), "confirm", "error")
+			response.Error(w, r, http.StatusInternalServerError, "INTERNAL", "email change failed", nil)
+		}
+		return
+	}
+	var revokedCount int64
+	if req.RevokeOtherSessions {
+		revokedCount, err = h.sessionSvc.RevokeOtherSessions(userID, currentSessionID)
+		if err != nil {
+			observability.EmitAudit(r, observability.AuditInput{
+				EventName:   "user.email.change.confirm",
+				ActorUserID: actor,
+				TargetType:  "user",
+				TargetID:    actor,
+				Action:      "email_change_confirm",
+				Outcome:     "failure",
+				Reason:      "session_revoke_error",
+			}, "error", err.Error())
+			observability.RecordUserEmailChangeEvent(r.Context(), "confirm", "error")
+			response.Error(w, r, http.StatusInternalServerError, "INTERNAL", "email changed but other sessions could not be revoked", nil)
+			return
+		}
+		observability.RecordSessionRevokedCount(r.Context(), "email_change", revokedCount)
+	}
+	observability.EmitAudit(r, observability.AuditInput{
+		EventName:   "user.email.change.confirm",
+		ActorUserID: actor,
+		TargetType:  "user",
+		TargetID:    actor,
+		Action:      "email_change_confirm",
+		Outcome:     "success",
+		Reason:      "email_changed",
+	}, "revoked_other_sessions", req.RevokeOtherSessions, "revoked_count", revokedCount)
+	observability.RecordUserEmailChangeEvent(r.Context(), "confirm", "success")
+	response.JSON(w, r, http.StatusOK, map[string]any{
+		"user":           result.User,
+		"previous_email": result.PreviousEmail,
+		"revoked_count":  revokedCount,
+	})
+}
+
+func (h *UserHandler) ExportData(w http.ResponseWriter, r *http.Request) {
+	userID, _, err := authUserIDAndClaims(r)
+	if err != nil {
+		observability.RecordUserAccountEvent(r.Context(), "export", "unauthorized")
+		response.Error(w, r, http.StatusUnauthorized, "UNAUTHORIZED", "invalid user", nil)
+		return
+	}
+	actor := observability.ActorUserID(userID)
+	export, err := h.accountSvc.Export(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			observability.RecordUserAccountEvent(r.Context(), "export", "not_found")
+			response.Error(w, r, http.StatusNotFound, "NOT_FOUND", "user not found", nil)
+			return
+		}
+		observability.EmitAudit(r, observability.AuditInput{
+			EventName:   "user.data.export",
+			ActorUserID: actor,
+			TargetType:  "user",
+			TargetID:    actor,
+			Action:      "export",
+			Outcome:     "failure",
+			Reason:      "service_error",
+		}, "error", err.Error())
+		observability.RecordUserAccountEvent(r.Context(), "export", "error")
+		response.Error(w, r, http.StatusInternalServerError, "INTERNAL", "failed to export account data", nil)
+		return
+	}
+	observability.EmitAudit(r, observability.AuditInput{
+		EventName:   "user.data.export",
+		ActorUserID: actor,
+		TargetType:  "user",
+		TargetID:    actor,
+		Action:      "export",
+		Outcome:     "success",
+		Reason:      "export_generated",
+	}, "session_count", len(export.Sessions), "identity_count", len(export.Identities))
+	observability.RecordUserAccountEvent(r.Context(), "export", "success")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="account-export-%d.json"`, userID))
+	w.Header().Set("Cache-Control", "no-store")
+	response.JSON(w, r, http.StatusOK, export)
+}
+
+func (h *UserHandler) DeleteAccount(w http.ResponseWriter, r *http.Request) {
+	userID, claims, err := authUserIDAndClaims(r)
+	if err != nil {
+		observability.RecordUserAccountEvent(r.Context(), "delete_request", "unauthorized")
+		response.Error(w, r, http.StatusUnauthorized, "UNAUTHORIZED", "invalid user", nil)
+		return
+	}
+	actor := observability.ActorUserID(userID)
+	var currentSessionID uint
+	if claims.TokenType != service.APIKeyClaimsTokenType && !claims.IsServiceAccount() {
+		currentSessionID, err = h.sessionSvc.ResolveCurrentSessionID(r, claims, userID)
+		if err != nil && !errors.Is(err, repository.ErrSessionNotFound) {
+			observability.RecordUserAccountEvent(r.Context(), "delete_request", "error")
+			response.Error(w, r, http.StatusInternalServerError, "INTERNAL", "failed to resolve current session", nil)
+			return
+		}
+	}
+	user, err := h.accountSvc.ScheduleDeletion(userID, currentSessionID)
+	if err != nil {
+		audit := observability.AuditInput{
+			EventName:   "user.account.delete",
+			ActorUserID: actor,
+			TargetType:  "user",
+			TargetID:    actor,
+			Action:      "delete",
+			Outcome:     "rejected",
+		}
+		switch {
+		case errors.Is(err, service.ErrReauthRequired):
+			audit.Reason = "reauth_required"
+			observability.EmitAudit(r, audit)
+			observability.RecordUserAccountEvent(r.Context(), "delete_request", "reauth_required")
+			response.Error(w, r, http.StatusForbidden, "REAUTH_REQUIRED", "recent authentication required", map[string]any{
+				"max_age_seconds": int(service.AccountDeletionReauthMaxAge.Seconds()),
+			})
+		case errors.Is(err, service.ErrAccountInactive):
+			audit.Reason = "account_inactive"
+			observability.EmitAudit(r, audit)
+			observability.RecordUserAccountEvent(r.Context(), "delete_request", "rejected")
+			response.Error(w, r, http.StatusForbidden, "ACCOUNT_INACTIVE", "account is not active", nil)
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			observability.RecordUserAccountEvent(r.Context(), "delete_request", "not_found")
+			response.Error(w, r, http.StatusNotFound, "NOT_FOUND", "user not found", nil)
+		default:
+			audit.Outcome = "failure"
+			audit.Reason = "service_error"
+			observability.EmitAudit(r, audit, "error", err.Error())
+			observability.RecordUserAccountEvent(r.Context(), "delete_request", "error")
+			response.Error(w, r, http.StatusInternalServerError, "INTERNAL", "account deletion failed", nil)
+		}
+		return
+	}
+	if user.Status == domain.UserStatusDeleted {
+		observability.EmitAudit(r, observability.AuditInput{
+			EventName:   "user.account.delete",
+			ActorUserID: actor,
+			TargetType:  "user",
+			TargetID:    actor,
+			Action:      "delete",
+			Outcome:     "success",
+			Reason:      "erased",
+		})
+		observability.RecordUserAccountEvent(r.Context(), "delete_request", "success")
+		response.JSON(w, r, http.StatusOK, map[string]any{"status": user.Status})
+		return
+	}
+	observability.EmitAudit(r, observability.AuditInput{
+		EventName:   "user.account.delete",
+		ActorUserID: actor,
+		TargetType:  "user",
+		TargetID:    actor,
+		Action:      "delete",
+		Outcome:     "success",
+		Reason:      "deletion_scheduled",
+	}, "deletion_scheduled_at", user.DeletionScheduledAt)
+	observability.RecordUserAccountEvent(r.Context(), "delete_request", "scheduled")
+	response.JSON(w, r, http.StatusAccepted, map[string]any{
+		"status":                user.Status,
+		"deletion_scheduled_at": user.DeletionScheduledAt,
+	})
+}
+
 func authUserIDAndClaims(r *http.Request) (uint, *security.Claims, error) {
 	claims, ok := middleware.ClaimsFromContext(r.Context())
 	if !ok {
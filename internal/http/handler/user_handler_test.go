@@ -17,6 +17,7 @@ import (
 	"github.com/sandeepkv93/everything-backend-starter-kit/internal/repository"
 	"github.com/sandeepkv93/everything-backend-starter-kit/internal/security"
 	"github.com/sandeepkv93/everything-backend-starter-kit/internal/service"
+	"gorm.io/gorm"
 )
 
 type stubUserSvc struct {
@@ -34,7 +35,7 @@ func (s *stubUserSvc) List() ([]domain.User, error) {
 	return nil, errors.New("not implemented")
 }
 
-func (s *stubUserSvc) SetRoles(userID uint, roleIDs []uint) error {
+func (s *stubUserSvc) SetRoleBindings(userID uint, bindings []domain.UserRole) error {
 	return errors.New("not implemented")
 }
 
@@ -73,6 +74,53 @@ func (s *stubSessionSvc) RevokeOtherSessions(userID, currentSessionID uint) (int
 	return 0, nil
 }
 
+type stubEmailChangeSvc struct {
+	requestFn func(userID uint, newEmail string) error
+	confirmFn func(userID uint, token string) (*service.EmailChangeResult, error)
+}
+
+func (s *stubEmailChangeSvc) RequestEmailChange(userID uint, newEmail, _ string) error {
+	if s.requestFn != nil {
+		return s.requestFn(userID, newEmail)
+	}
+	return nil
+}
+
+func (s *stubEmailChangeSvc) ConfirmEmailChange(userID uint, token string) (*service.EmailChangeResult, error) {
+	if s.confirmFn != nil {
+		return s.confirmFn(userID, token)
+	}
+	return nil, service.ErrInvalidVerifyToken
+}
+
+type stubAccountDataSvc struct {
+	exportFn   func(userID uint) (*service.AccountExport, error)
+	scheduleFn func(userID, currentSessionID uint) (*domain.User, error)
+	eraseFn    func(userID uint) (*domain.User, string, error)
+}
+
+func (s *stubAccountDataSvc) Export(userID uint) (*service.AccountExport, error) {
+	if s.exportFn != nil {
+		return s.exportFn(userID)
+	}
+	return &service.AccountExport{Profile: service.AccountExportProfile{ID: userID}}, nil
+}
+
+func (s *stubAccountDataSvc) ScheduleDeletion(userID, currentSessionID uint) (*domain.User, error) {
+	if s.scheduleFn != nil {
+		return s.scheduleFn(userID, currentSessionID)
+	}
+	at := time.Now().Add(time.Hour)
+	return &domain.User{ID: userID, Status: domain.UserStatusPendingDeletion, DeletionScheduledAt: &at}, nil
+}
+
+func (s *stubAccountDataSvc) Erase(userID uint) (*domain.User, string, error) {
+	if s.eraseFn != nil {
+		return s.eraseFn(userID)
+	}
+	return &domain.User{ID: userID, Status: domain.UserStatusDeleted}, domain.UserStatusActive, nil
+}
+
 func userReqWithClaims(r *http.Request, sub string) *http.Request {
 	claims := &security.Claims{}
 	claims.Subject = sub
@@ -88,7 +136,7 @@ func withURLParam(r *http.Request, key, val string) *http.Request {
 }
 
 func TestUserHandlerMeErrorMapping(t *testing.T) {
-	h := NewUserHandler(&stubUserSvc{}, &stubSessionSvc{})
+	h := NewUserHandler(&stubUserSvc{}, &stubSessionSvc{}, nil, nil)
 
 	req := httptest.NewRequest(http.MethodGet, "/api/v1/me", nil)
 	rr := httptest.NewRecorder()
@@ -99,7 +147,7 @@ func TestUserHandlerMeErrorMapping(t *testing.T) {
 
 	h = NewUserHandler(&stubUserSvc{getByIDFn: func(id uint) (*domain.User, []string, error) {
 		return nil, nil, errors.New("db down")
-	}}, &stubSessionSvc{})
+	}}, &stubSessionSvc{}, nil, nil)
 	req = userReqWithClaims(httptest.NewRequest(http.MethodGet, "/api/v1/me", nil), "7")
 	rr = httptest.NewRecorder()
 	h.Me(rr, req)
@@ -122,7 +170,7 @@ func TestUserHandlerSessionsResolveFallbackAndErrors(t *testing.T) {
 				}
 				return []service.SessionView{{ID: 1}}, nil
 			},
-		})
+		}, nil, nil)
 		req := userReqWithClaims(httptest.NewRequest(http.MethodGet, "/api/v1/sessions", nil), "9")
 		rr := httptest.NewRecorder()
 
@@ -140,7 +188,7 @@ func TestUserHandlerSessionsResolveFallbackAndErrors(t *testing.T) {
 			resolveFn: func(r *http.Request, claims *security.Claims, userID uint) (uint, error) {
 				return 0, errors.New("backend failed")
 			},
-		})
+		}, nil, nil)
 		req := userReqWithClaims(httptest.NewRequest(http.MethodGet, "/api/v1/sessions", nil), "9")
 		rr := httptest.NewRecorder()
 
@@ -155,7 +203,7 @@ func TestUserHandlerRevokeSessionMatrix(t *testing.T) {
 	baseReq := userReqWithClaims(httptest.NewRequest(http.MethodDelete, "/api/v1/sessions/1", nil), "11")
 
 	t.Run("invalid session id", func(t *testing.T) {
-		h := NewUserHandler(&stubUserSvc{}, &stubSessionSvc{})
+		h := NewUserHandler(&stubUserSvc{}, &stubSessionSvc{}, nil, nil)
 		req := withURLParam(baseReq.Clone(baseReq.Context()), "session_id", "not-a-number")
 		rr := httptest.NewRecorder()
 		h.RevokeSession(rr, req)
@@ -167,7 +215,7 @@ func TestUserHandlerRevokeSessionMatrix(t *testing.T) {
 	t.Run("not found", func(t *testing.T) {
 		h := NewUserHandler(&stubUserSvc{}, &stubSessionSvc{revokeFn: func(userID, sessionID uint) (string, error) {
 			return "", repository.ErrSessionNotFound
-		}})
+		}}, nil, nil)
 		req := withURLParam(baseReq.Clone(baseReq.Context()), "session_id", "123")
 		rr := httptest.NewRecorder()
 		h.RevokeSession(rr, req)
@@ -179,7 +227,7 @@ func TestUserHandlerRevokeSessionMatrix(t *testing.T) {
 	t.Run("already revoked", func(t *testing.T) {
 		h := NewUserHandler(&stubUserSvc{}, &stubSessionSvc{revokeFn: func(userID, sessionID uint) (string, error) {
 			return "already_revoked", nil
-		}})
+		}}, nil, nil)
 		req := withURLParam(baseReq.Clone(baseReq.Context()), "session_id", "123")
 		rr := httptest.NewRecorder()
 		h.RevokeSession(rr, req)
@@ -197,7 +245,7 @@ func TestUserHandlerRevokeSessionMatrix(t *testing.T) {
 				t.Fatalf("unexpected args userID=%d sessionID=%d", userID, sessionID)
 			}
 			return "revoked", nil
-		}})
+		}}, nil, nil)
 		req := withURLParam(baseReq.Clone(baseReq.Context()), "session_id", strconv.Itoa(123))
 		rr := httptest.NewRecorder()
 		h.RevokeSession(rr, req)
@@ -209,7 +257,7 @@ func TestUserHandlerRevokeSessionMatrix(t *testing.T) {
 
 func TestUserHandlerRevokeOtherSessionsMatrix(t *testing.T) {
 	t.Run("unauthorized missing claims", func(t *testing.T) {
-		h := NewUserHandler(&stubUserSvc{}, &stubSessionSvc{})
+		h := NewUserHandler(&stubUserSvc{}, &stubSessionSvc{}, nil, nil)
 		rr := httptest.NewRecorder()
 		h.RevokeOtherSessions(rr, httptest.NewRequest(http.MethodPost, "/api/v1/sessions/revoke-others", nil))
 		if rr.Code != http.StatusUnauthorized {
@@ -220,7 +268,7 @@ func TestUserHandlerRevokeOtherSessionsMatrix(t *testing.T) {
 	t.Run("resolve error", func(t *testing.T) {
 		h := NewUserHandler(&stubUserSvc{}, &stubSessionSvc{resolveFn: func(r *http.Request, claims *security.Claims, userID uint) (uint, error) {
 			return 0, errors.New("cannot resolve")
-		}})
+		}}, nil, nil)
 		rr := httptest.NewRecorder()
 		h.RevokeOtherSessions(rr, userReqWithClaims(httptest.NewRequest(http.MethodPost, "/api/v1/sessions/revoke-others", nil), "12"))
 		if rr.Code != http.StatusUnauthorized {
@@ -234,7 +282,7 @@ func TestUserHandlerRevokeOtherSessionsMatrix(t *testing.T) {
 			revokeAll: func(userID, currentSessionID uint) (int64, error) {
 				return 0, errors.New("db error")
 			},
-		})
+		}, nil, nil)
 		rr := httptest.NewRecorder()
 		h.RevokeOtherSessions(rr, userReqWithClaims(httptest.NewRequest(http.MethodPost, "/api/v1/sessions/revoke-others", nil), "12"))
 		if rr.Code != http.StatusInternalServerError {
@@ -251,7 +299,7 @@ func TestUserHandlerRevokeOtherSessionsMatrix(t *testing.T) {
 				}
 				return 3, nil
 			},
-		})
+		}, nil, nil)
 		rr := httptest.NewRecorder()
 		h.RevokeOtherSessions(rr, userReqWithClaims(httptest.NewRequest(http.MethodPost, "/api/v1/sessions/revoke-others", nil), "12"))
 		if rr.Code != http.StatusOK {
@@ -281,3 +329,176 @@ func TestSessionViewJSONShapeSmoke(t *testing.T) {
 		t.Fatal("expected non-empty json")
 	}
 }
+
+func TestUserHandlerEmailChangeMatrix(t *testing.T) {
+	t.Run("request error mapping", func(t *testing.T) {
+		cases := []struct {
+			err    error
+			status int
+		}{
+			{err: nil, status: http.StatusAccepted},
+			{err: service.ErrInvalidEmailChange, status: http.StatusBadRequest},
+			{err: service.ErrEmailTaken, status: http.StatusConflict},
+			{err: errors.New("smtp down"), status: http.StatusInternalServerError},
+		}
+		for _, tc := range cases {
+			h := NewUserHandler(&stubUserSvc{}, &stubSessionSvc{}, &stubEmailChangeSvc{requestFn: func(userID uint, newEmail string) error {
+				if userID != 12 || newEmail != "new@example.com" {
+					t.Fatalf("unexpected args userID=%d newEmail=%q", userID, newEmail)
+				}
+				return tc.err
+			}}, nil)
+			rr := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/me/email/change", strings.NewReader(`{"new_email":"new@example.com"}`))
+			h.RequestEmailChange(rr, userReqWithClaims(req, "12"))
+			if rr.Code != tc.status {
+				t.Fatalf("%v: expected %d, got %d", tc.err, tc.status, rr.Code)
+			}
+		}
+	})
+
+	t.Run("confirm invalid token does not touch sessions", func(t *testing.T) {
+		sessions := &stubSessionSvc{revokeAll: func(userID, currentSessionID uint) (int64, error) {
+			t.Fatal("expected no session revocation on failed confirm")
+			return 0, nil
+		}}
+		h := NewUserHandler(&stubUserSvc{}, sessions, &stubEmailChangeSvc{}, nil)
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/me/email/confirm", strings.NewReader(`{"token":"bad","revoke_other_sessions":true}`))
+		h.ConfirmEmailChange(rr, userReqWithClaims(req, "12"))
+		if rr.Code != http.StatusBadRequest || !strings.Contains(rr.Body.String(), "INVALID_OR_EXPIRED_TOKEN") {
+			t.Fatalf("expected 400 INVALID_OR_EXPIRED_TOKEN, got %d body=%s", rr.Code, rr.Body.String())
+		}
+	})
+
+	t.Run("confirm unresolved session fails before spending token", func(t *testing.T) {
+		emailSvc := &stubEmailChangeSvc{confirmFn: func(userID uint, token string) (*service.EmailChangeResult, error) {
+			t.Fatal("expected confirm not to run without a current session")
+			return nil, nil
+		}}
+		h := NewUserHandler(&stubUserSvc{}, &stubSessionSvc{resolveFn: func(r *http.Request, claims *security.Claims, userID uint) (uint, error) {
+			return 0, repository.ErrSessionNotFound
+		}}, emailSvc, nil)
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/me/email/confirm", strings.NewReader(`{"token":"t","revoke_other_sessions":true}`))
+		h.ConfirmEmailChange(rr, userReqWithClaims(req, "12"))
+		if rr.Code != http.StatusUnauthorized {
+			t.Fatalf("expected 401, got %d", rr.Code)
+		}
+	})
+
+	t.Run("confirm success optionally revokes other sessions", func(t *testing.T) {
+		emailSvc := &stubEmailChangeSvc{confirmFn: func(userID uint, token string) (*service.EmailChangeResult, error) {
+			return &service.EmailChangeResult{User: &domain.User{ID: userID, Email: "new@example.com"}, PreviousEmail: "old@example.com"}, nil
+		}}
+		revoked := 0
+		sessions := &stubSessionSvc{
+			resolveFn: func(r *http.Request, claims *security.Claims, userID uint) (uint, error) { return 444, nil },
+			revokeAll: func(userID, currentSessionID uint) (int64, error) {
+				revoked++
+				if currentSessionID != 444 {
+					t.Fatalf("expected current session to be kept, got %d", currentSessionID)
+				}
+				return 2, nil
+			},
+		}
+		h := NewUserHandler(&stubUserSvc{}, sessions, emailSvc, nil)
+
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/me/email/confirm", strings.NewReader(`{"token":"t"}`))
+		h.ConfirmEmailChange(rr, userReqWithClaims(req, "12"))
+		if rr.Code != http.StatusOK || revoked != 0 {
+			t.Fatalf("expected 200 without revocation, got %d revoked=%d", rr.Code, revoked)
+		}
+
+		rr = httptest.NewRecorder()
+		req = httptest.NewRequest(http.MethodPost, "/api/v1/me/email/confirm", strings.NewReader(`{"token":"t","revoke_other_sessions":true}`))
+		h.ConfirmEmailChange(rr, userReqWithClaims(req, "12"))
+		if rr.Code != http.StatusOK || revoked != 1 {
+			t.Fatalf("expected 200 with revocation, got %d revoked=%d", rr.Code, revoked)
+		}
+		var env struct {
+			Data struct {
+				PreviousEmail string `json:"previous_email"`
+				RevokedCount  int64  `json:"revoked_count"`
+			} `json:"data"`
+		}
+		if err := json.NewDecoder(rr.Body).Decode(&env); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		if env.Data.PreviousEmail != "old@example.com" || env.Data.RevokedCount != 2 {
+			t.Fatalf("unexpected response: %+v", env.Data)
+		}
+	})
+}
+
+func TestUserHandlerAccountDataMatrix(t *testing.T) {
+	t.Run("export", func(t *testing.T) {
+		h := NewUserHandler(&stubUserSvc{}, &stubSessionSvc{}, nil, &stubAccountDataSvc{})
+		rr := httptest.NewRecorder()
+		h.ExportData(rr, userReqWithClaims(httptest.NewRequest(http.MethodGet, "/api/v1/me/export", nil), "12"))
+		if rr.Code != http.StatusOK || !strings.Contains(rr.Header().Get("Content-Disposition"), "account-export-12.json") {
+			t.Fatalf("expected export download, got %d headers=%v", rr.Code, rr.Header())
+		}
+
+		h = NewUserHandler(&stubUserSvc{}, &stubSessionSvc{}, nil, &stubAccountDataSvc{exportFn: func(uint) (*service.AccountExport, error) {
+			return nil, gorm.ErrRecordNotFound
+		}})
+		rr = httptest.NewRecorder()
+		h.ExportData(rr, userReqWithClaims(httptest.NewRequest(http.MethodGet, "/api/v1/me/export", nil), "12"))
+		if rr.Code != http.StatusNotFound {
+			t.Fatalf("expected 404, got %d", rr.Code)
+		}
+	})
+
+	t.Run("delete error mapping", func(t *testing.T) {
+		cases := []struct {
+			err    error
+			status int
+			code   string
+		}{
+			{err: service.ErrReauthRequired, status: http.StatusForbidden, code: "REAUTH_REQUIRED"},
+			{err: service.ErrAccountInactive, status: http.StatusForbidden, code: "ACCOUNT_INACTIVE"},
+			{err: errors.New("db down"), status: http.StatusInternalServerError, code: "INTERNAL"},
+		}
+		for _, tc := range cases {
+			h := NewUserHandler(&stubUserSvc{}, &stubSessionSvc{}, nil, &stubAccountDataSvc{scheduleFn: func(uint, uint) (*domain.User, error) {
+				return nil, tc.err
+			}})
+			rr := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodDelete, "/api/v1/me", nil)
+			h.DeleteAccount(rr, userReqWithClaims(req, "12"))
+			if rr.Code != tc.status || !strings.Contains(rr.Body.String(), tc.code) {
+				t.Fatalf("%v: expected %d %s, got %d body=%s", tc.err, tc.status, tc.code, rr.Code, rr.Body.String())
+			}
+		}
+	})
+
+	t.Run("delete schedules for the caller", func(t *testing.T) {
+		var gotUser, gotSession uint
+		accounts := &stubAccountDataSvc{scheduleFn: func(userID, currentSessionID uint) (*domain.User, error) {
+			gotUser, gotSession = userID, currentSessionID
+			at := time.Now().Add(time.Hour)
+			return &domain.User{ID: userID, Status: domain.UserStatusPendingDeletion, DeletionScheduledAt: &at}, nil
+		}}
+		sessions := &stubSessionSvc{resolveFn: func(*http.Request, *security.Claims, uint) (uint, error) { return 7, nil }}
+		h := NewUserHandler(&stubUserSvc{}, sessions, nil, accounts)
+		rr := httptest.NewRecorder()
+		h.DeleteAccount(rr, userReqWithClaims(httptest.NewRequest(http.MethodDelete, "/api/v1/me", nil), "12"))
+		if rr.Code != http.StatusAccepted || !strings.Contains(rr.Body.String(), "pending_deletion") {
+			t.Fatalf("expected 202 pending_deletion, got %d body=%s", rr.Code, rr.Body.String())
+		}
+		if gotUser != 12 || gotSession != 7 {
+			t.Fatalf("unexpected schedule user=%d session=%d", gotUser, gotSession)
+		}
+
+		accounts.scheduleFn = func(userID, _ uint) (*domain.User, error) {
+			return &domain.User{ID: userID, Status: domain.UserStatusDeleted}, nil
+		}
+		rr = httptest.NewRecorder()
+		h.DeleteAccount(rr, userReqWithClaims(httptest.NewRequest(http.MethodDelete, "/api/v1/me", nil), "12"))
+		if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), `"status":"deleted"`) {
+			t.Fatalf("expected immediate erase with empty body, got %d body=%s", rr.Code, rr.Body.String())
+		}
+	})
+}
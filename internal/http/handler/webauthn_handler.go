@@ -0,0 +1,249 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/http/response"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/observability"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/security"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/service"
+)
+
+type WebAuthnHandler struct {
+	webauthnSvc service.WebAuthnServiceInterface
+	authSvc     service.AuthServiceInterface
+	cookieMgr   *security.CookieManager
+	refreshTTL  time.Duration
+}
+
+func NewWebAuthnHandler(
+	webauthnSvc service.WebAuthnServiceInterface,
+	authSvc service.AuthServiceInterface,
+	cookieMgr *security.CookieManager,
+	refreshTTL time.Duration,
+) *WebAuthnHandler {
+	return &WebAuthnHandler{
+		webauthnSvc: webauthnSvc,
+		authSvc:     authSvc,
+		cookieMgr:   cookieMgr,
+		refreshTTL:  refreshTTL,
+	}
+}
+
+type webauthnFinishRequest struct {
+	ChallengeID string          `json:"challenge_id"`
+	Name        string          `json:"name"`
+	Credential  json.RawMessage `json:"credential"`
+}
+
+func (h *WebAuthnHandler) RegisterBegin(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	status := "success"
+	outcome := "success"
+	defer func() {
+		observability.RecordAuthRequestDuration(r.Context(), "webauthn_register_begin", status, time.Since(start))
+		observability.RecordAuthWebAuthnEvent(r.Context(), "register_begin", outcome)
+	}()
+	userID, _, err := authUserIDAndClaims(r)
+	if err != nil {
+		status = "failure"
+		outcome = "unauthorized"
+		response.Error(w, r, http.StatusUnauthorized, "UNAUTHORIZED", "invalid user", nil)
+		return
+	}
+	actor := observability.ActorUserID(userID)
+	ceremony, err := h.webauthnSvc.BeginRegistration(r.Context(), userID)
+	if err != nil {
+		status = "failure"
+		outcome = "failure"
+		auditAuth(r, "auth.webauthn.register", "webauthn_register_begin", "failure", "begin_error", actor, "user", actor, "error", err.Error())
+		if errors.Is(err, service.ErrWebAuthnDisabled) {
+			outcome = "not_enabled"
+			response.Error(w, r, http.StatusNotFound, "NOT_ENABLED", "webauthn is disabled", nil)
+			return
+		}
+		response.Error(w, r, http.StatusInternalServerError, "INTERNAL", "webauthn registration failed", nil)
+		return
+	}
+	auditAuth(r, "auth.webauthn.register", "webauthn_register_begin", "success", "ceremony_started", actor, "user", actor)
+	response.JSON(w, r, http.StatusOK, ceremony)
+}
+
+func (h *WebAuthnHandler) RegisterFinish(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	status := "success"
+	outcome := "success"
+	defer func() {
+		observability.RecordAuthRequestDuration(r.Context(), "webauthn_register_finish", status, time.Since(start))
+		observability.RecordAuthWebAuthnEvent(r.Context(), "register_finish", outcome)
+	}()
+	userID, _, err := authUserIDAndClaims(r)
+	if err != nil {
+		status = "failure"
+		outcome = "unauthorized"
+		response.Error(w, r, http.StatusUnauthorized, "UNAUTHORIZED", "invalid user", nil)
+		return
+	}
+	actor := observability.ActorUserID(userID)
+	var req webauthnFinishRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || len(req.Credential) == 0 {
+		status = "failure"
+		outcome = "failure"
+		auditAuth(r, "auth.webauthn.register", "webauthn_register_finish", "failure", "invalid_payload", actor, "user", actor)
+		response.Error(w, r, http.StatusBadRequest, "BAD_REQUEST", "invalid payload", nil)
+		return
+	}
+	cred, err := h.webauthnSvc.FinishRegistration(r.Context(), userID, req.ChallengeID, req.Name, req.Credential)
+	if err != nil {
+		status = "failure"
+		outcome = "failure"
+		auditAuth(r, "auth.webauthn.register", "webauthn_register_finish", "failure", "finish_error", actor, "user", actor, "error", err.Error())
+		switch {
+		case errors.Is(err, service.ErrWebAuthnDisabled):
+			outcome = "not_enabled"
+			response.Error(w, r, http.StatusNotFound, "NOT_ENABLED", "webauthn is disabled", nil)
+		case errors.Is(err, service.ErrInvalidWebAuthnChallenge):
+			outcome = "invalid_challenge"
+			response.Error(w, r, http.StatusBadRequest, "INVALID_OR_EXPIRED_TOKEN", "invalid or expired webauthn challenge", nil)
+		case errors.Is(err, service.ErrInvalidWebAuthnCredential):
+			outcome = "invalid_credential"
+			response.Error(w, r, http.StatusBadRequest, "INVALID_CREDENTIAL", "invalid webauthn credential", nil)
+		default:
+			response.Error(w, r, http.StatusInternalServerError, "INTERNAL", "webauthn registration failed", nil)
+		}
+		return
+	}
+	auditAuth(r, "auth.webauthn.register", "webauthn_register_finish", "success", "credential_registered", actor, "webauthn_credential", strconv.FormatUint(uint64(cred.ID), 10))
+	response.JSON(w, r, http.StatusCreated, cred)
+}
+
+func (h *WebAuthnHandler) LoginBegin(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	status := "success"
+	outcome := "success"
+	defer func() {
+		observability.RecordAuthRequestDuration(r.Context(), "webauthn_login_begin", status, time.Since(start))
+		observability.RecordAuthWebAuthnEvent(r.Context(), "login_begin", outcome)
+	}()
+	ceremony, err := h.webauthnSvc.BeginLogin(r.Context())
+	if err != nil {
+		status = "failure"
+		outcome = "failure"
+		auditAuth(r, "auth.webauthn.login", "webauthn_login_begin", "failure", "begin_error", "anonymous", "user", "unknown", "error", err.Error())
+		if errors.Is(err, service.ErrWebAuthnDisabled) {
+			outcome = "not_enabled"
+			response.Error(w, r, http.StatusNotFound, "NOT_ENABLED", "webauthn is disabled", nil)
+			return
+		}
+		response.Error(w, r, http.StatusInternalServerError, "INTERNAL", "webauthn login failed", nil)
+		return
+	}
+	response.JSON(w, r, http.StatusOK, ceremony)
+}
+
+func (h *WebAuthnHandler) LoginFinish(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	status := "success"
+	outcome := "success"
+	defer func() {
+		observability.RecordAuthRequestDuration(r.Context(), "webauthn_login_finish", status, time.Since(start))
+		observability.RecordAuthWebAuthnEvent(r.Context(), "login_finish", outcome)
+	}()
+	var req webauthnFinishRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || len(req.Credential) == 0 {
+		status = "failure"
+		outcome = "failure"
+		auditAuth(r, "auth.webauthn.login", "login", "failure", "invalid_payload", "anonymous", "user", "unknown")
+		observability.RecordAuthLogin(r.Context(), "webauthn", "failure")
+		response.Error(w, r, http.StatusBadRequest, "BAD_REQUEST", "invalid payload", nil)
+		return
+	}
+	result, err := h.authSvc.LoginWithWebAuthn(req.ChallengeID, req.Credential, r.UserAgent(), clientIP(r))
+	if err != nil {
+		status = "failure"
+		outcome = "failure"
+		auditAuth(r, "auth.webauthn.login", "login", "failure", "login_error", "anonymous", "user", "unknown", "error", err.Error())
+		observability.RecordAuthLogin(r.Context(), "webauthn", "failure")
+		switch {
+		case errors.Is(err, service.ErrWebAuthnDisabled):
+			outcome = "not_enabled"
+			response.Error(w, r, http.StatusNotFound, "NOT_ENABLED", "webauthn is disabled", nil)
+		case errors.Is(err, service.ErrInvalidWebAuthnChallenge):
+			outcome = "invalid_challenge"
+			response.Error(w, r, http.StatusUnauthorized, "INVALID_OR_EXPIRED_TOKEN", "invalid or expired webauthn challenge", nil)
+		case errors.Is(err, service.ErrInvalidWebAuthnCredential):
+			outcome = "invalid_credential"
+			response.Error(w, r, http.StatusUnauthorized, "UNAUTHORIZED", "invalid credentials", nil)
+		case errors.Is(err, service.ErrAccountInactive):
+			outcome = "account_inactive"
+			response.Error(w, r, http.StatusForbidden, "ACCOUNT_INACTIVE", "account is not active", nil)
+		default:
+			response.Error(w, r, http.StatusInternalServerError, "INTERNAL", "webauthn login failed", nil)
+		}
+		return
+	}
+	h.cookieMgr.SetTokenCookies(w, result.AccessToken, result.RefreshToken, result.CSRFToken, h.refreshTTL)
+	auditAuth(r, "auth.webauthn.login", "login", "success", "assertion_valid", observability.ActorUserID(result.User.ID), "user", observability.ActorUserID(result.User.ID))
+	observability.RecordAuthLogin(r.Context(), "webauthn", "success")
+	response.JSON(w, r, http.StatusOK, map[string]any{"user": result.User, "csrf_token": result.CSRFToken, "expires_at": result.ExpiresAt})
+}
+
+func (h *WebAuthnHandler) ListCredentials(w http.ResponseWriter, r *http.Request) {
+	userID, _, err := authUserIDAndClaims(r)
+	if err != nil {
+		response.Error(w, r, http.StatusUnauthorized, "UNAUTHORIZED", "invalid user", nil)
+		return
+	}
+	creds, err := h.webauthnSvc.ListCredentials(userID)
+	if err != nil {
+		response.Error(w, r, http.StatusInternalServerError, "INTERNAL", "failed to list passkeys", nil)
+		return
+	}
+	response.JSON(w, r, http.StatusOK, creds)
+}
+
+func (h *WebAuthnHandler) DeleteCredential(w http.ResponseWriter, r *http.Request) {
+	outcome := "success"
+	defer func() {
+		observability.RecordAuthWebAuthnEvent(r.Context(), "credential_delete", outcome)
+	}()
+	userID, _, err := authUserIDAndClaims(r)
+	if err != nil {
+		outcome = "unauthorized"
+		response.Error(w, r, http.StatusUnauthorized, "UNAUTHORIZED", "invalid user", nil)
+		return
+	}
+	actor := observability.ActorUserID(userID)
+	rawID := chi.URLParam(r, "credential_id")
+	credentialID, err := strconv.ParseUint(rawID, 10, 64)
+	if err != nil {
+		outcome = "failure"
+		response.Error(w, r, http.StatusBadRequest, "BAD_REQUEST", "invalid credential id", nil)
+		return
+	}
+	if err := h.authSvc.DeleteWebAuthnCredential(userID, uint(credentialID)); err != nil {
+		if errors.Is(err, service.ErrWebAuthnCredentialNotFound) {
+			outcome = "not_found"
+			response.Error(w, r, http.StatusNotFound, "NOT_FOUND", "passkey not found", nil)
+			return
+		}
+		if errors.Is(err, service.ErrLastCredential) {
+			outcome = "last_credential"
+			auditAuth(r, "auth.webauthn.credential.delete", "delete", "rejected", "last_credential", actor, "webauthn_credential", rawID)
+			response.Error(w, r, http.StatusConflict, "LAST_CREDENTIAL", "cannot remove the last sign-in method", nil)
+			return
+		}
+		outcome = "failure"
+		auditAuth(r, "auth.webauthn.credential.delete", "delete", "failure", "delete_error", actor, "webauthn_credential", rawID, "error", err.Error())
+		response.Error(w, r, http.StatusInternalServerError, "INTERNAL", "failed to delete passkey", nil)
+		return
+	}
+	auditAuth(r, "auth.webauthn.credential.delete", "delete", "success", "credential_deleted", actor, "webauthn_credential", rawID)
+	response.JSON(w, r, http.StatusOK, map[string]any{"credential_id": credentialID, "status": "deleted"})
+}
@@ -0,0 +1,174 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/domain"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/security"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/service"
+)
+
+type stubWebAuthnService struct {
+	finishRegistrationFn func(userID uint, challengeID, name string, response []byte) (*domain.WebAuthnCredential, error)
+}
+
+func (s *stubWebAuthnService) BeginRegistration(context.Context, uint) (*service.WebAuthnCeremony, error) {
+	return nil, service.ErrWebAuthnDisabled
+}
+
+func (s *stubWebAuthnService) FinishRegistration(_ context.Context, userID uint, challengeID, name string, response []byte) (*domain.WebAuthnCredential, error) {
+	if s.finishRegistrationFn != nil {
+		return s.finishRegistrationFn(userID, challengeID, name, response)
+	}
+	return nil, service.ErrWebAuthnDisabled
+}
+
+func (s *stubWebAuthnService) BeginLogin(context.Context) (*service.WebAuthnCeremony, error) {
+	return &service.WebAuthnCeremony{ChallengeID: "challenge", Options: map[string]any{}}, nil
+}
+
+func (s *stubWebAuthnService) ListCredentials(uint) ([]domain.WebAuthnCredential, error) {
+	return nil, nil
+}
+
+func TestWebAuthnHandlerLoginFinish(t *testing.T) {
+	cookieMgr := security.NewCookieManager("", false, "lax")
+
+	t.Run("invalid credential", func(t *testing.T) {
+		authSvc := &stubAuthService{
+			webauthnFn: func(challengeID string, credential []byte, ua, ip string) (*service.LoginResult, error) {
+				return nil, service.ErrInvalidWebAuthnCredential
+			},
+		}
+		h := NewWebAuthnHandler(&stubWebAuthnService{}, authSvc, cookieMgr, 24*time.Hour)
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/webauthn/login/finish", strings.NewReader(`{"challenge_id":"c","credential":{"id":"x"}}`))
+		rr := httptest.NewRecorder()
+
+		h.LoginFinish(rr, req)
+
+		if rr.Code != http.StatusUnauthorized {
+			t.Fatalf("expected 401, got %d", rr.Code)
+		}
+		if len(rr.Result().Cookies()) != 0 {
+			t.Fatal("expected no session cookies on failed assertion")
+		}
+	})
+
+	t.Run("missing credential", func(t *testing.T) {
+		h := NewWebAuthnHandler(&stubWebAuthnService{}, &stubAuthService{}, cookieMgr, 24*time.Hour)
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/webauthn/login/finish", strings.NewReader(`{"challenge_id":"c"}`))
+		rr := httptest.NewRecorder()
+
+		h.LoginFinish(rr, req)
+
+		if rr.Code != http.StatusBadRequest {
+			t.Fatalf("expected 400, got %d", rr.Code)
+		}
+	})
+
+	t.Run("success sets session cookies", func(t *testing.T) {
+		var gotCredential string
+		authSvc := &stubAuthService{
+			webauthnFn: func(challengeID string, credential []byte, ua, ip string) (*service.LoginResult, error) {
+				gotCredential = string(credential)
+				return &service.LoginResult{User: &domain.User{ID: 4}, AccessToken: "a", RefreshToken: "r", CSRFToken: "c"}, nil
+			},
+		}
+		h := NewWebAuthnHandler(&stubWebAuthnService{}, authSvc, cookieMgr, 24*time.Hour)
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/webauthn/login/finish", strings.NewReader(`{"challenge_id":"c","credential":{"id":"x"}}`))
+		rr := httptest.NewRecorder()
+
+		h.LoginFinish(rr, req)
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d", rr.Code)
+		}
+		if gotCredential != `{"id":"x"}` {
+			t.Fatalf("expected raw credential to be passed through, got %s", gotCredential)
+		}
+		names := map[string]bool{}
+		for _, c := range rr.Result().Cookies() {
+			names[c.Name] = true
+		}
+		if !names["access_token"] || !names["refresh_token"] || !names["csrf_token"] {
+			t.Fatalf("expected session cookies, got %v", names)
+		}
+	})
+}
+
+func TestWebAuthnHandlerRegisterFinishErrorMappings(t *testing.T) {
+	cookieMgr := security.NewCookieManager("", false, "lax")
+	cases := []struct {
+		name     string
+		err      error
+		wantCode int
+		wantErr  string
+	}{
+		{name: "not enabled", err: service.ErrWebAuthnDisabled, wantCode: http.StatusNotFound, wantErr: "NOT_ENABLED"},
+		{name: "expired challenge", err: service.ErrInvalidWebAuthnChallenge, wantCode: http.StatusBadRequest, wantErr: "INVALID_OR_EXPIRED_TOKEN"},
+		{name: "bad attestation", err: service.ErrInvalidWebAuthnCredential, wantCode: http.StatusBadRequest, wantErr: "INVALID_CREDENTIAL"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := &stubWebAuthnService{
+				finishRegistrationFn: func(userID uint, challengeID, name string, response []byte) (*domain.WebAuthnCredential, error) {
+					return nil, tc.err
+				},
+			}
+			h := NewWebAuthnHandler(svc, &stubAuthService{}, cookieMgr, 24*time.Hour)
+			req := withClaims(httptest.NewRequest(http.MethodPost, "/api/v1/auth/webauthn/register/finish", strings.NewReader(`{"challenge_id":"c","credential":{"id":"x"}}`)), "9")
+			rr := httptest.NewRecorder()
+
+			h.RegisterFinish(rr, req)
+
+			if rr.Code != tc.wantCode {
+				t.Fatalf("expected %d, got %d", tc.wantCode, rr.Code)
+			}
+			if env := decodeAuthErrorEnvelope(t, rr); env.Error == nil || env.Error.Code != tc.wantErr {
+				t.Fatalf("expected error code %q, got %+v", tc.wantErr, env.Error)
+			}
+		})
+	}
+}
+
+func TestWebAuthnHandlerDeleteCredentialErrors(t *testing.T) {
+	cases := []struct {
+		err      error
+		wantCode int
+		wantErr  string
+	}{
+		{err: service.ErrWebAuthnCredentialNotFound, wantCode: http.StatusNotFound, wantErr: "NOT_FOUND"},
+		{err: service.ErrLastCredential, wantCode: http.StatusConflict, wantErr: "LAST_CREDENTIAL"},
+	}
+	for _, tc := range cases {
+		var gotUser, gotCred uint
+		authSvc := &stubAuthService{
+			deletePasskeyFn: func(userID, credentialID uint) error {
+				gotUser, gotCred = userID, credentialID
+				return tc.err
+			},
+		}
+		h := NewWebAuthnHandler(&stubWebAuthnService{}, authSvc, security.NewCookieManager("", false, "lax"), 24*time.Hour)
+		req := withClaims(httptest.NewRequest(http.MethodDelete, "/api/v1/me/webauthn/credentials/12", nil), "9")
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("credential_id", "12")
+		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+		rr := httptest.NewRecorder()
+
+		h.DeleteCredential(rr, req)
+
+		if rr.Code != tc.wantCode || !strings.Contains(rr.Body.String(), tc.wantErr) {
+			t.Fatalf("%v: expected %d %s, got %d body=%s", tc.err, tc.wantCode, tc.wantErr, rr.Code, rr.Body.String())
+		}
+		if gotUser != 9 || gotCred != 12 {
+			t.Fatalf("expected delete scoped to user 9 credential 12, got user=%d cred=%d", gotUser, gotCred)
+		}
+	}
+}
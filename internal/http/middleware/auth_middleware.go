@@ -2,12 +2,15 @@ package middleware
 
 import (
 	"context"
+	"errors"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/sandeepkv93/everything-backend-starter-kit/internal/http/response"
 	"github.com/sandeepkv93/everything-backend-starter-kit/internal/observability"
 	"github.com/sandeepkv93/everything-backend-starter-kit/internal/security"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/service"
 )
 
 type contextKey string
@@ -16,7 +19,15 @@ const (
 	ClaimsContextKey contextKey = "claims"
 )
 
-func AuthMiddleware(jwtMgr *security.JWTManager) func(http.Handler) http.Handler {
+// AuthMiddleware validates the access token and, when revocations is set,
+// rejects tokens whose jti was denylisted by logout, session revocation or
+// account suspension.
+// It does not look up the user's status: suspension and account deletion
+// denylist live tokens, and a status changed any other way is enforced at
+// the next refresh, within JWT_ACCESS_TTL.
+// When apiKeys is set, bearer credentials carrying the API key prefix are
+// authenticated as API keys instead of JWTs.
+func AuthMiddleware(jwtMgr *security.JWTManager, revocations service.AccessTokenRevocationStore, apiKeys service.APIKeyAuthenticator) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			raw := security.GetCookie(r, "access_token")
@@ -33,19 +44,115 @@ func AuthMiddleware(jwtMgr *security.JWTManager) func(http.Handler) http.Handler
 				response.Error(w, r, http.StatusUnauthorized, "UNAUTHORIZED", "missing access token", nil)
 				return
 			}
+			if apiKeys != nil && source == "bearer" && strings.HasPrefix(raw, service.APIKeyTokenPrefix) {
+				authenticateAPIKey(w, r, next, apiKeys, raw)
+				return
+			}
 			claims, err := jwtMgr.ParseAccessToken(raw)
 			if err != nil {
 				observability.RecordAccessTokenValidation(r.Context(), "invalid", source)
 				response.Error(w, r, http.StatusUnauthorized, "UNAUTHORIZED", "invalid access token", nil)
 				return
 			}
+			if revocations != nil && claims.ID != "" {
+				revoked, err := revocations.IsRevoked(r.Context(), claims.ID)
+				if err != nil {
+					observability.RecordAccessTokenValidation(r.Context(), "revocation_unavailable", source)
+					response.Error(w, r, http.StatusServiceUnavailable, "TOKEN_REVOCATION_UNAVAILABLE", "access token revocation status unavailable", nil)
+					return
+				}
+				if revoked {
+					observability.RecordAccessTokenValidation(r.Context(), "revoked", source)
+					response.Error(w, r, http.StatusUnauthorized, "UNAUTHORIZED", "access token revoked", nil)
+					return
+				}
+			}
 			observability.RecordAccessTokenValidation(r.Context(), "valid", source)
+			if claims.IsServiceAccount() {
+				source = security.PrincipalServiceAccount
+			}
 			ctx := context.WithValue(r.Context(), ClaimsContextKey, claims)
+			ctx = observability.WithAuthSource(ctx, source)
+			if claims.IsImpersonated() {
+				ctx = observability.WithImpersonator(ctx, claims.Act.Subject)
+				annotateRequestLog(ctx, "user_id", claims.Subject, "impersonator_user_id", claims.Act.Subject)
+			}
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
 }
 
+func authenticateAPIKey(w http.ResponseWriter, r *http.Request, next http.Handler, apiKeys service.APIKeyAuthenticator, raw string) {
+	const source = "api_key"
+	ctx := observability.WithAuthSource(r.Context(), source)
+	r = r.WithContext(ctx)
+	principal, err := apiKeys.AuthenticateAPIKey(ctx, raw)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidAPIKey) {
+			observability.RecordAccessTokenValidation(ctx, "invalid", source)
+			observability.RecordAuthAPIKeyEvent(ctx, "authenticate", "invalid")
+			observability.EmitAudit(r, observability.AuditInput{
+				EventName:  "auth.api_key.use",
+				TargetType: "api_key",
+				TargetID:   "unknown",
+				Action:     "authenticate",
+				Outcome:    "failure",
+				Reason:     "invalid_api_key",
+			})
+			response.Error(w, r, http.StatusUnauthorized, "UNAUTHORIZED", "invalid api key", nil)
+			return
+		}
+		if errors.Is(err, service.ErrAccountInactive) {
+			observability.RecordAccessTokenValidation(ctx, "account_inactive", source)
+			observability.RecordAuthAPIKeyEvent(ctx, "authenticate", "account_inactive")
+			response.Error(w, r, http.StatusForbidden, "ACCOUNT_INACTIVE", "account is not active", nil)
+			return
+		}
+		observability.RecordAccessTokenValidation(ctx, "unavailable", source)
+		observability.RecordAuthAPIKeyEvent(ctx, "authenticate", "failure")
+		response.Error(w, r, http.StatusServiceUnavailable, "API_KEY_UNAVAILABLE", "api key verification unavailable", nil)
+		return
+	}
+	observability.RecordAccessTokenValidation(ctx, "valid", source)
+	observability.RecordAuthAPIKeyEvent(ctx, "authenticate", "success")
+	observability.EmitAudit(r, observability.AuditInput{
+		EventName:   "auth.api_key.use",
+		ActorUserID: observability.ActorUserID(principal.UserID),
+		TargetType:  "api_key",
+		TargetID:    strconv.FormatUint(uint64(principal.KeyID), 10),
+		Action:      "authenticate",
+		Outcome:     "success",
+		Reason:      "api_key_valid",
+	}, "method", r.Method, "path", r.URL.Path)
+	ctx = context.WithValue(ctx, ClaimsContextKey, principal.Claims())
+	next.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// DenyAPIKeys keeps API keys off the self-service routes that manage the
+// owner's credentials, sessions and account. Those routes check no
+// permission, so a key's scopes would not limit them. It must run after
+// AuthMiddleware.
+func DenyAPIKeys(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := ClaimsFromContext(r.Context())
+		if !ok || claims.TokenType != service.APIKeyClaimsTokenType {
+			next.ServeHTTP(w, r)
+			return
+		}
+		observability.RecordAuthAPIKeyEvent(r.Context(), "authorize", "self_service_denied")
+		observability.EmitAudit(r, observability.AuditInput{
+			EventName:   "auth.api_key.blocked",
+			ActorUserID: claims.Subject,
+			TargetType:  "route",
+			TargetID:    r.URL.Path,
+			Action:      r.Method,
+			Outcome:     "rejected",
+			Reason:      "self_service_route",
+		})
+		response.Error(w, r, http.StatusForbidden, "API_KEY_FORBIDDEN", "not allowed with an api key", nil)
+	})
+}
+
 func ClaimsFromContext(ctx context.Context) (*security.Claims, bool) {
 	c, ok := ctx.Value(ClaimsContextKey).(*security.Claims)
 	return c, ok
@@ -1,12 +1,16 @@
 package middleware
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 	"time"
 
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/observability"
 	"github.com/sandeepkv93/everything-backend-starter-kit/internal/security"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/service"
 )
 
 func TestAuthMiddlewareMissingTokenReturnsUnauthorized(t *testing.T) {
@@ -16,7 +20,7 @@ func TestAuthMiddlewareMissingTokenReturnsUnauthorized(t *testing.T) {
 		"abcdefghijklmnopqrstuvwxyz123456",
 		"abcdefghijklmnopqrstuvwxyz654321",
 	)
-	h := AuthMiddleware(jwtMgr)(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+	h := AuthMiddleware(jwtMgr, nil, nil)(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
 		w.WriteHeader(http.StatusOK)
 	}))
 
@@ -40,7 +44,7 @@ func TestAuthMiddlewareValidBearerTokenPasses(t *testing.T) {
 	if err != nil {
 		t.Fatalf("sign token: %v", err)
 	}
-	h := AuthMiddleware(jwtMgr)(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+	h := AuthMiddleware(jwtMgr, nil, nil)(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
 		w.WriteHeader(http.StatusNoContent)
 	}))
 
@@ -53,3 +57,104 @@ func TestAuthMiddlewareValidBearerTokenPasses(t *testing.T) {
 		t.Fatalf("expected 204 for valid token, got %d", rr.Code)
 	}
 }
+
+func TestAuthMiddlewareRejectsRevokedToken(t *testing.T) {
+	jwtMgr := security.NewJWTManager(
+		"iss",
+		"aud",
+		"abcdefghijklmnopqrstuvwxyz123456",
+		"abcdefghijklmnopqrstuvwxyz654321",
+	)
+	token, err := jwtMgr.SignAccessTokenWithJTI(42, nil, nil, 15*time.Minute, "jti-1", time.Now())
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+	revocations := service.NewInMemoryAccessTokenRevocationStore()
+	h := AuthMiddleware(jwtMgr, revocations, nil)(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	serve := func() int {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/me", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+		return rr.Code
+	}
+
+	if code := serve(); code != http.StatusNoContent {
+		t.Fatalf("expected 204 before revocation, got %d", code)
+	}
+	if err := revocations.Revoke(context.Background(), "jti-1", time.Minute); err != nil {
+		t.Fatalf("revoke: %v", err)
+	}
+	if code := serve(); code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 for revoked token, got %d", code)
+	}
+}
+
+type stubAPIKeyAuthenticator struct {
+	principal *service.APIKeyPrincipal
+	err       error
+}
+
+func (s stubAPIKeyAuthenticator) AuthenticateAPIKey(context.Context, string) (*service.APIKeyPrincipal, error) {
+	return s.principal, s.err
+}
+
+func TestAuthMiddlewareAPIKey(t *testing.T) {
+	jwtMgr := security.NewJWTManager(
+		"iss",
+		"aud",
+		"abcdefghijklmnopqrstuvwxyz123456",
+		"abcdefghijklmnopqrstuvwxyz654321",
+	)
+	cases := []struct {
+		name     string
+		auth     stubAPIKeyAuthenticator
+		wantCode int
+	}{
+		{name: "valid", auth: stubAPIKeyAuthenticator{principal: &service.APIKeyPrincipal{KeyID: 3, UserID: 42, Permissions: []string{"users:read"}}}, wantCode: http.StatusNoContent},
+		{name: "invalid", auth: stubAPIKeyAuthenticator{err: service.ErrInvalidAPIKey}, wantCode: http.StatusUnauthorized},
+		{name: "store down", auth: stubAPIKeyAuthenticator{err: errors.New("db down")}, wantCode: http.StatusServiceUnavailable},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var gotClaims *security.Claims
+			var gotSource string
+			h := AuthMiddleware(jwtMgr, nil, tc.auth)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotClaims, _ = ClaimsFromContext(r.Context())
+				gotSource = observability.AuthSourceFromContext(r.Context())
+				w.WriteHeader(http.StatusNoContent)
+			}))
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/me", nil)
+			req.Header.Set("Authorization", "Bearer "+service.APIKeyTokenPrefix+"abcd1234_secret")
+			rr := httptest.NewRecorder()
+			h.ServeHTTP(rr, req)
+
+			if rr.Code != tc.wantCode {
+				t.Fatalf("expected %d, got %d", tc.wantCode, rr.Code)
+			}
+			if tc.wantCode != http.StatusNoContent {
+				return
+			}
+			if gotClaims == nil || gotClaims.Subject != "42" || gotClaims.TokenType != service.APIKeyClaimsTokenType || gotSource != "api_key" {
+				t.Fatalf("unexpected api key context: claims=%+v source=%q", gotClaims, gotSource)
+			}
+		})
+	}
+}
+
+func TestDenyAPIKeysBlocksOnlyAPIKeys(t *testing.T) {
+	h := DenyAPIKeys(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	for tokenType, want := range map[string]int{"access": http.StatusNoContent, service.APIKeyClaimsTokenType: http.StatusForbidden} {
+		req := httptest.NewRequest(http.MethodDelete, "/api/v1/me", nil)
+		req = req.WithContext(context.WithValue(req.Context(), ClaimsContextKey, &security.Claims{TokenType: tokenType}))
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+		if rr.Code != want {
+			t.Fatalf("%s: expected %d, got %d body=%s", tokenType, want, rr.Code, rr.Body.String())
+		}
+	}
+}
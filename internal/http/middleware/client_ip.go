@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+)
+
+type policyClientIPKey struct{}
+
+// PolicyClientIP records the address access policies see as request.ip. It
+// must run before chimiddleware.RealIP, which rewrites RemoteAddr from
+// headers any client can send. The socket peer is the client unless it falls
+// inside trustedProxies; then X-Forwarded-For is read right to left, skipping
+// trusted hops, and the first address outside them is the client.
+func PolicyClientIP(trustedProxies []string) func(http.Handler) http.Handler {
+	networks := make([]*net.IPNet, 0, len(trustedProxies))
+	for _, cidr := range trustedProxies {
+		if _, network, err := net.ParseCIDR(strings.TrimSpace(cidr)); err == nil {
+			networks = append(networks, network)
+		}
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if ip := resolveClientIP(r, networks); ip != nil {
+				r = r.WithContext(context.WithValue(r.Context(), policyClientIPKey{}, ip))
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// policyClientIP returns the address recorded by PolicyClientIP, falling back
+// to RemoteAddr for handlers mounted without it.
+func policyClientIP(r *http.Request) net.IP {
+	if ip, ok := r.Context().Value(policyClientIPKey{}).(net.IP); ok {
+		return ip
+	}
+	return parseRequestIP(r)
+}
+
+func resolveClientIP(r *http.Request, trustedProxies []*net.IPNet) net.IP {
+	ip := parseRequestIP(r)
+	if ip == nil || !ipInNetworks(ip, trustedProxies) {
+		return ip
+	}
+	hops := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := net.ParseIP(strings.TrimSpace(hops[i]))
+		if hop == nil {
+			// A malformed entry ends the chain we can vouch for.
+			return ip
+		}
+		ip = hop
+		if !ipInNetworks(hop, trustedProxies) {
+			return hop
+		}
+	}
+	return ip
+}
+
+func ipInNetworks(ip net.IP, networks []*net.IPNet) bool {
+	for _, network := range networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	chimiddleware "github.com/go-chi/chi/v5/middleware"
+)
+
+func TestPolicyClientIPIgnoresForwardingHeadersFromUntrustedPeers(t *testing.T) {
+	tests := []struct {
+		name    string
+		trusted []string
+		peer    string
+		xff     []string
+		want    string
+	}{
+		{name: "no proxies configured", peer: "198.51.100.4:4000", xff: []string{"203.0.113.9"}, want: "198.51.100.4"},
+		{name: "untrusted peer", trusted: []string{"10.0.0.0/8"}, peer: "198.51.100.4:4000", xff: []string{"203.0.113.9"}, want: "198.51.100.4"},
+		{name: "trusted peer", trusted: []string{"10.0.0.0/8"}, peer: "10.0.0.2:4000", xff: []string{"203.0.113.9"}, want: "203.0.113.9"},
+		{name: "client-supplied prefix is skipped", trusted: []string{"10.0.0.0/8"}, peer: "10.0.0.2:4000", xff: []string{"203.0.113.9, 198.51.100.7", "10.0.0.3"}, want: "198.51.100.7"},
+		{name: "malformed hop stops the walk", trusted: []string{"10.0.0.0/8"}, peer: "10.0.0.2:4000", xff: []string{"203.0.113.9, junk, 10.0.0.3"}, want: "10.0.0.3"},
+		{name: "trusted peer without header", trusted: []string{"10.0.0.0/8"}, peer: "10.0.0.2:4000", want: "10.0.0.2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			h := PolicyClientIP(tt.trusted)(chimiddleware.RealIP(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = policyClientIP(r).String()
+			})))
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tt.peer
+			for _, v := range tt.xff {
+				req.Header.Add("X-Forwarded-For", v)
+			}
+			req.Header.Set("X-Real-IP", "192.0.2.1")
+			h.ServeHTTP(httptest.NewRecorder(), req)
+			if got != tt.want {
+				t.Fatalf("expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/http/response"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/observability"
+)
+
+// DenyImpersonation keeps impersonation sessions off routes that change
+// credentials, sessions, roles or account state. It must run after
+// AuthMiddleware.
+func DenyImpersonation(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := ClaimsFromContext(r.Context())
+		if !ok || !claims.IsImpersonated() {
+			next.ServeHTTP(w, r)
+			return
+		}
+		observability.EmitAudit(r, observability.AuditInput{
+			EventName:   "auth.impersonation.blocked",
+			ActorUserID: claims.Subject,
+			TargetType:  "route",
+			TargetID:    r.URL.Path,
+			Action:      r.Method,
+			Outcome:     "rejected",
+			Reason:      "sensitive_route",
+		})
+		response.Error(w, r, http.StatusForbidden, "IMPERSONATION_FORBIDDEN", "not allowed while impersonating a user", nil)
+	})
+}
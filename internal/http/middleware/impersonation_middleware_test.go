@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/observability"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/security"
+)
+
+func TestDenyImpersonationBlocksOnlyImpersonatedSessions(t *testing.T) {
+	jwtMgr := security.NewJWTManager("iss", "aud", "abcdefghijklmnopqrstuvwxyz123456", "abcdefghijklmnopqrstuvwxyz654321")
+	h := AuthMiddleware(jwtMgr, nil, nil)(DenyImpersonation(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})))
+
+	regular, _ := jwtMgr.SignAccessToken(42, nil, nil, time.Minute)
+	impersonated, _ := jwtMgr.SignImpersonationToken(42, 7, nil, nil, time.Minute, "imp-1")
+	for token, want := range map[string]int{regular: http.StatusNoContent, impersonated: http.StatusForbidden} {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/local/change-password", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+		if rr.Code != want {
+			t.Fatalf("expected %d, got %d body=%s", want, rr.Code, rr.Body.String())
+		}
+	}
+}
+
+func TestImpersonatedRequestsAreTagged(t *testing.T) {
+	orig := slog.Default()
+	cap := &captureHandler{}
+	slog.SetDefault(slog.New(cap))
+	t.Cleanup(func() { slog.SetDefault(orig) })
+
+	jwtMgr := security.NewJWTManager("iss", "aud", "abcdefghijklmnopqrstuvwxyz123456", "abcdefghijklmnopqrstuvwxyz654321")
+	var impersonator string
+	r := chi.NewRouter()
+	r.Use(StructuredRequestLogger)
+	r.With(AuthMiddleware(jwtMgr, nil, nil)).Get("/me", func(w http.ResponseWriter, r *http.Request) {
+		impersonator = observability.ImpersonatorFromContext(r.Context())
+		w.WriteHeader(http.StatusOK)
+	})
+
+	token, _ := jwtMgr.SignImpersonationToken(42, 7, nil, nil, time.Minute, "imp-1")
+	req := httptest.NewRequest(http.MethodGet, "/me", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	if impersonator != "7" {
+		t.Fatalf("expected impersonator in request context, got %q", impersonator)
+	}
+	if len(cap.records) != 1 {
+		t.Fatalf("expected one request log, got %d", len(cap.records))
+	}
+	attrs := recordAttrs(cap.records[0])
+	if attrs["impersonator_user_id"] != "7" || attrs["user_id"] != "42" {
+		t.Fatalf("expected impersonation attrs on the request log, got %+v", attrs)
+	}
+}
@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/http/response"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/observability"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/service"
+)
+
+// RequireMFAEnrollment blocks callers that have not confirmed a second factor.
+// It must run after AuthMiddleware so the subject claim is available. Service
+// accounts cannot enroll a second factor and are exempt.
+func RequireMFAEnrollment(checker service.MFAStatusChecker) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claims, ok := ClaimsFromContext(r.Context())
+			if !ok {
+				response.Error(w, r, http.StatusUnauthorized, "UNAUTHORIZED", "missing auth context", nil)
+				return
+			}
+			if claims.IsServiceAccount() {
+				next.ServeHTTP(w, r)
+				return
+			}
+			userID, err := strconv.ParseUint(claims.Subject, 10, 64)
+			if err != nil {
+				response.Error(w, r, http.StatusUnauthorized, "UNAUTHORIZED", "invalid subject", nil)
+				return
+			}
+			enabled, err := checker.MFAEnabled(r.Context(), uint(userID))
+			if err != nil {
+				observability.RecordAuthMFAEvent(r.Context(), "admin_enforcement", "failure")
+				response.Error(w, r, http.StatusServiceUnavailable, "MFA_UNAVAILABLE", "mfa status unavailable", nil)
+				return
+			}
+			if !enabled {
+				observability.RecordAuthMFAEvent(r.Context(), "admin_enforcement", "denied")
+				response.Error(w, r, http.StatusForbidden, "MFA_REQUIRED", "multi-factor authentication enrollment required", nil)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
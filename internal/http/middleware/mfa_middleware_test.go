@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/security"
+)
+
+type testMFAStatusChecker struct {
+	enabled map[uint]bool
+	err     error
+}
+
+func (c testMFAStatusChecker) MFAEnabled(_ context.Context, userID uint) (bool, error) {
+	if c.err != nil {
+		return false, c.err
+	}
+	return c.enabled[userID], nil
+}
+
+func TestRequireMFAEnrollment(t *testing.T) {
+	cases := []struct {
+		name      string
+		subject   string
+		principal string
+		checker   testMFAStatusChecker
+		want      int
+	}{
+		{name: "enrolled", subject: "7", checker: testMFAStatusChecker{enabled: map[uint]bool{7: true}}, want: http.StatusOK},
+		{name: "not enrolled", subject: "8", checker: testMFAStatusChecker{enabled: map[uint]bool{7: true}}, want: http.StatusForbidden},
+		{name: "checker error", subject: "7", checker: testMFAStatusChecker{err: errors.New("db down")}, want: http.StatusServiceUnavailable},
+		{name: "invalid subject", subject: "abc", checker: testMFAStatusChecker{}, want: http.StatusUnauthorized},
+		{name: "service account exempt", subject: "service_account:3", principal: security.PrincipalServiceAccount, checker: testMFAStatusChecker{err: errors.New("db down")}, want: http.StatusOK},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req = req.WithContext(context.WithValue(req.Context(), ClaimsContextKey, &security.Claims{}))
+			claims, _ := ClaimsFromContext(req.Context())
+			claims.Subject = tc.subject
+			claims.PrincipalType = tc.principal
+			rr := httptest.NewRecorder()
+
+			RequireMFAEnrollment(tc.checker)(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})).ServeHTTP(rr, req)
+
+			if rr.Code != tc.want {
+				t.Fatalf("expected status %d, got %d", tc.want, rr.Code)
+			}
+		})
+	}
+}
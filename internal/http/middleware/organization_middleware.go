@@ -0,0 +1,107 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/http/response"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/service"
+)
+
+// OrganizationHeader selects the active organization on routes that do not
+// name one in the path.
+const OrganizationHeader = "X-Organization-ID"
+
+// OrganizationScope resolves the active organization from the {orgID} route
+// parameter, falling back to the X-Organization-ID header, and scopes the
+// request context to it so RequirePermission evaluates org role bindings.
+// Callers must be members unless they hold orgs:write globally; anyone else
+// gets a 404 so organization IDs cannot be probed. It must run after
+// AuthMiddleware.
+func OrganizationScope(orgs service.OrganizationMembershipChecker, rbac service.RBACAuthorizer, resolver service.PermissionResolver) func(http.Handler) http.Handler {
+	return organizationScope(orgs, rbac, resolver, true)
+}
+
+// OptionalOrganizationScope is OrganizationScope for routes that also run at
+// global scope: a request naming no organization passes through unscoped.
+func OptionalOrganizationScope(orgs service.OrganizationMembershipChecker, rbac service.RBACAuthorizer, resolver service.PermissionResolver) func(http.Handler) http.Handler {
+	return organizationScope(orgs, rbac, resolver, false)
+}
+
+// RequireGlobalScope rejects org-scoped requests on routes that manage state
+// shared by every organization, so organization role bindings can never
+// reach it. It must run after OptionalOrganizationScope.
+func RequireGlobalScope(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, scoped := service.OrganizationFromContext(r.Context()); scoped {
+			response.Error(w, r, http.StatusBadRequest, "ORGANIZATION_SCOPE_UNSUPPORTED", "route is only available at global scope", map[string]string{"header": OrganizationHeader})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func organizationScope(orgs service.OrganizationMembershipChecker, rbac service.RBACAuthorizer, resolver service.PermissionResolver, required bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claims, ok := ClaimsFromContext(r.Context())
+			if !ok {
+				response.Error(w, r, http.StatusUnauthorized, "UNAUTHORIZED", "missing auth context", nil)
+				return
+			}
+			raw := chi.URLParam(r, "orgID")
+			if raw == "" {
+				raw = strings.TrimSpace(r.Header.Get(OrganizationHeader))
+			}
+			if raw == "" && !required {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if raw == "" {
+				response.Error(w, r, http.StatusBadRequest, "ORGANIZATION_REQUIRED", "organization id is required", map[string]string{"header": OrganizationHeader})
+				return
+			}
+			id64, err := strconv.ParseUint(raw, 10, 64)
+			if err != nil || id64 == 0 {
+				response.Error(w, r, http.StatusBadRequest, "BAD_REQUEST", "invalid organization id", nil)
+				return
+			}
+			orgID := uint(id64)
+
+			member := false
+			if !claims.IsServiceAccount() {
+				if userID, err := strconv.ParseUint(claims.Subject, 10, 64); err == nil {
+					member, err = orgs.IsMember(orgID, uint(userID))
+					if err != nil {
+						response.Error(w, r, http.StatusInternalServerError, "INTERNAL", "failed to resolve organization", nil)
+						return
+					}
+				}
+			}
+			if !member {
+				// Resolved before the context is scoped, so only global grants
+				// count here.
+				perms, err := EffectivePermissions(r.Context(), resolver, claims)
+				if err != nil {
+					response.Error(w, r, http.StatusServiceUnavailable, "RBAC_UNAVAILABLE", "permission resolution unavailable", nil)
+					return
+				}
+				exists := false
+				if rbac.HasPermission(perms, service.PermissionOrganizationsWrite) {
+					if exists, err = orgs.Exists(orgID); err != nil {
+						response.Error(w, r, http.StatusInternalServerError, "INTERNAL", "failed to resolve organization", nil)
+						return
+					}
+				}
+				if !exists {
+					response.Error(w, r, http.StatusNotFound, "NOT_FOUND", "organization not found", nil)
+					return
+				}
+			}
+			next.ServeHTTP(w, r.WithContext(service.WithOrganization(r.Context(), orgID)))
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/security"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/service"
+)
+
+type testOrganizationMembership struct {
+	orgs    map[uint]bool
+	members map[[2]uint]bool
+}
+
+func (m testOrganizationMembership) Exists(orgID uint) (bool, error) {
+	return m.orgs[orgID], nil
+}
+
+func (m testOrganizationMembership) IsMember(orgID, userID uint) (bool, error) {
+	return m.members[[2]uint{orgID, userID}], nil
+}
+
+func TestOrganizationScope(t *testing.T) {
+	membership := testOrganizationMembership{
+		orgs:    map[uint]bool{1: true, 2: true},
+		members: map[[2]uint]bool{{1, 7}: true},
+	}
+	run := func(t *testing.T, subject string, perms []string, path string, header string) (int, uint) {
+		t.Helper()
+		mw := OrganizationScope(membership, service.NewRBACService(), testPermissionResolver{perms: perms})
+		var scoped uint
+		r := chi.NewRouter()
+		handler := mw(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+			scoped, _ = service.OrganizationFromContext(r.Context())
+		}))
+		r.Handle("/orgs/{orgID}", handler)
+		r.Handle("/org", handler)
+
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if header != "" {
+			req.Header.Set(OrganizationHeader, header)
+		}
+		claims := &security.Claims{}
+		claims.Subject = subject
+		req = req.WithContext(context.WithValue(req.Context(), ClaimsContextKey, claims))
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		return rr.Code, scoped
+	}
+
+	if code, scoped := run(t, "7", nil, "/orgs/1", ""); code != http.StatusOK || scoped != 1 {
+		t.Fatalf("expected member to enter org 1 from the path, got code=%d org=%d", code, scoped)
+	}
+	if code, scoped := run(t, "7", nil, "/org", "1"); code != http.StatusOK || scoped != 1 {
+		t.Fatalf("expected member to enter org 1 from the header, got code=%d org=%d", code, scoped)
+	}
+	if code, _ := run(t, "7", nil, "/orgs/2", ""); code != http.StatusNotFound {
+		t.Fatalf("expected non-member to get 404, got %d", code)
+	}
+	if code, scoped := run(t, "8", []string{"orgs:write"}, "/orgs/2", ""); code != http.StatusOK || scoped != 2 {
+		t.Fatalf("expected global orgs:write to enter any org, got code=%d org=%d", code, scoped)
+	}
+	if code, _ := run(t, "8", []string{"orgs:write"}, "/orgs/3", ""); code != http.StatusNotFound {
+		t.Fatalf("expected unknown org to get 404 even for operators, got %d", code)
+	}
+	if code, _ := run(t, "7", nil, "/org", ""); code != http.StatusBadRequest {
+		t.Fatalf("expected missing org to be rejected, got %d", code)
+	}
+	if code, _ := run(t, "7", nil, "/org", "abc"); code != http.StatusBadRequest {
+		t.Fatalf("expected malformed org id to be rejected, got %d", code)
+	}
+}
+
+func TestOptionalOrganizationScopeAndRequireGlobalScope(t *testing.T) {
+	membership := testOrganizationMembership{
+		orgs:    map[uint]bool{1: true},
+		members: map[[2]uint]bool{{1, 7}: true},
+	}
+	run := func(t *testing.T, header string, globalOnly bool) (int, uint, bool) {
+		t.Helper()
+		var (
+			org    uint
+			scoped bool
+		)
+		var next http.Handler = http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+			org, scoped = service.OrganizationFromContext(r.Context())
+		})
+		if globalOnly {
+			next = RequireGlobalScope(next)
+		}
+		mw := OptionalOrganizationScope(membership, service.NewRBACService(), testPermissionResolver{})
+		req := httptest.NewRequest(http.MethodGet, "/admin/users", nil)
+		if header != "" {
+			req.Header.Set(OrganizationHeader, header)
+		}
+		claims := &security.Claims{}
+		claims.Subject = "7"
+		req = req.WithContext(context.WithValue(req.Context(), ClaimsContextKey, claims))
+		rr := httptest.NewRecorder()
+		mw(next).ServeHTTP(rr, req)
+		return rr.Code, org, scoped
+	}
+
+	if code, _, scoped := run(t, "", false); code != http.StatusOK || scoped {
+		t.Fatalf("expected a request without an org to pass unscoped, got code=%d scoped=%v", code, scoped)
+	}
+	if code, org, _ := run(t, "1", false); code != http.StatusOK || org != 1 {
+		t.Fatalf("expected the header to scope the request, got code=%d org=%d", code, org)
+	}
+	if code, _, _ := run(t, "2", false); code != http.StatusNotFound {
+		t.Fatalf("expected non-member to get 404, got %d", code)
+	}
+	if code, _, _ := run(t, "1", true); code != http.StatusBadRequest {
+		t.Fatalf("expected global-only route to reject org scope, got %d", code)
+	}
+	if code, _, _ := run(t, "", true); code != http.StatusOK {
+		t.Fatalf("expected global-only route to pass at global scope, got %d", code)
+	}
+}
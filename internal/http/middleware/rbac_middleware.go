@@ -1,14 +1,26 @@
 package middleware
 
 import (
+	"context"
+	"log/slog"
 	"net/http"
 
+	"github.com/go-chi/chi/v5"
+
 	"github.com/sandeepkv93/everything-backend-starter-kit/internal/http/response"
 	"github.com/sandeepkv93/everything-backend-starter-kit/internal/observability"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/security"
 	"github.com/sandeepkv93/everything-backend-starter-kit/internal/service"
 )
 
 func RequirePermission(rbac service.RBACAuthorizer, resolver service.PermissionResolver, permission string) func(http.Handler) http.Handler {
+	return RequirePermissionWithPolicies(rbac, resolver, nil, permission)
+}
+
+// RequirePermissionWithPolicies is RequirePermission followed by the access
+// policies that apply to permission. Policies can only narrow access: they
+// are not consulted unless the caller already holds permission.
+func RequirePermissionWithPolicies(rbac service.RBACAuthorizer, resolver service.PermissionResolver, policies service.AccessPolicyEvaluator, permission string) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			claims, ok := ClaimsFromContext(r.Context())
@@ -16,23 +28,77 @@ func RequirePermission(rbac service.RBACAuthorizer, resolver service.PermissionR
 				response.Error(w, r, http.StatusUnauthorized, "UNAUTHORIZED", "missing auth context", nil)
 				return
 			}
-			perms := claims.Permissions
-			if resolver != nil {
-				resolved, err := resolver.ResolvePermissions(r.Context(), claims)
-				if err != nil {
-					observability.RecordRBACAuthorizationEvent(r.Context(), permission, "resolver_error")
-					response.Error(w, r, http.StatusServiceUnavailable, "RBAC_UNAVAILABLE", "permission resolution unavailable", nil)
-					return
-				}
-				perms = resolved
+			perms, err := EffectivePermissions(r.Context(), resolver, claims)
+			if err != nil {
+				observability.RecordRBACAuthorizationEvent(r.Context(), permission, "resolver_error", "resolver_error")
+				response.Error(w, r, http.StatusServiceUnavailable, "RBAC_UNAVAILABLE", "permission resolution unavailable", nil)
+				return
 			}
 			if !rbac.HasPermission(perms, permission) {
-				observability.RecordRBACAuthorizationEvent(r.Context(), permission, "denied")
+				observability.RecordRBACAuthorizationEvent(r.Context(), permission, "denied", "missing_permission")
 				response.Error(w, r, http.StatusForbidden, "FORBIDDEN", "insufficient permission", map[string]string{"required": permission})
 				return
 			}
-			observability.RecordRBACAuthorizationEvent(r.Context(), permission, "allowed")
+			if policies != nil && !enforceAccessPolicies(w, r, policies, claims, perms, permission) {
+				return
+			}
+			observability.RecordRBACAuthorizationEvent(r.Context(), permission, "allowed", "none")
 			next.ServeHTTP(w, r)
 		})
 	}
 }
+
+func enforceAccessPolicies(w http.ResponseWriter, r *http.Request, policies service.AccessPolicyEvaluator, claims *security.Claims, perms []string, permission string) bool {
+	req := service.AccessRequest{
+		Permission:  permission,
+		Claims:      claims,
+		Permissions: perms,
+		Method:      r.Method,
+		Path:        r.URL.Path,
+		Params:      map[string]string{},
+	}
+	if ip := policyClientIP(r); ip != nil {
+		req.IP = ip.String()
+	}
+	if rctx := chi.RouteContext(r.Context()); rctx != nil {
+		for i, key := range rctx.URLParams.Keys {
+			req.Params[key] = rctx.URLParams.Values[i]
+		}
+	}
+	decision, err := policies.Evaluate(r.Context(), req)
+	if err != nil {
+		reason := decision.Policy
+		if reason == "" {
+			reason = "policy_load_error"
+		}
+		observability.RecordRBACAuthorizationEvent(r.Context(), permission, "policy_error", reason)
+		slog.Error("access policy evaluation failed", "policy", decision.Policy, "required_permission", permission, "path", r.URL.Path, "error", err)
+		response.Error(w, r, http.StatusServiceUnavailable, "POLICY_UNAVAILABLE", "access policy evaluation unavailable", nil)
+		return false
+	}
+	if !decision.Allowed {
+		observability.RecordRBACAuthorizationEvent(r.Context(), permission, "policy_denied", decision.Policy)
+		response.Error(w, r, http.StatusForbidden, "FORBIDDEN", "denied by access policy", map[string]string{"required": permission, "policy": decision.Policy})
+		return false
+	}
+	return true
+}
+
+// EffectivePermissions returns the permissions claims may exercise. When ctx
+// is scoped to an organization the resolver includes the caller's bindings
+// there.
+func EffectivePermissions(ctx context.Context, resolver service.PermissionResolver, claims *security.Claims) ([]string, error) {
+	// Service account tokens carry the permissions granted at issuance;
+	// the resolver only knows about users.
+	if resolver == nil || claims.IsServiceAccount() {
+		return claims.Permissions, nil
+	}
+	resolved, err := resolver.ResolvePermissions(ctx, claims)
+	if err != nil {
+		return nil, err
+	}
+	if claims.TokenType == service.APIKeyClaimsTokenType {
+		return service.CapPermissions(resolved, claims.Permissions), nil
+	}
+	return resolved, nil
+}
@@ -7,7 +7,10 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github.com/go-chi/chi/v5"
+
 	"github.com/sandeepkv93/everything-backend-starter-kit/internal/security"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/service"
 )
 
 type testRBACAuthorizer struct {
@@ -85,3 +88,101 @@ func TestRequirePermissionAllowed(t *testing.T) {
 		t.Fatal("expected wrapped handler to be called")
 	}
 }
+
+func TestRequirePermissionCapsAPIKeyToScopes(t *testing.T) {
+	resolver := testPermissionResolver{perms: []string{"users:read", "users:write"}}
+	principal := &service.APIKeyPrincipal{KeyID: 1, UserID: 1, Permissions: []string{"users:read"}}
+	scoped := principal.Claims()
+
+	serve := func(required string) int {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req = req.WithContext(context.WithValue(req.Context(), ClaimsContextKey, scoped))
+		rr := httptest.NewRecorder()
+		RequirePermission(service.NewRBACService(), resolver, required)(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusNoContent)
+		})).ServeHTTP(rr, req)
+		return rr.Code
+	}
+	if code := serve("users:read"); code != http.StatusNoContent {
+		t.Fatalf("expected scoped permission to pass, got %d", code)
+	}
+	if code := serve("users:write"); code != http.StatusForbidden {
+		t.Fatalf("expected owner permission outside key scopes to be denied, got %d", code)
+	}
+}
+
+func TestRequirePermissionUsesServiceAccountTokenPermissions(t *testing.T) {
+	resolver := testPermissionResolver{err: errors.New("resolver only knows users")}
+	claims := &security.Claims{PrincipalType: security.PrincipalServiceAccount, Permissions: []string{"users:read"}}
+	claims.Subject = security.ServiceAccountSubject(3)
+
+	serve := func(required string) int {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req = req.WithContext(context.WithValue(req.Context(), ClaimsContextKey, claims))
+		rr := httptest.NewRecorder()
+		RequirePermission(service.NewRBACService(), resolver, required)(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusNoContent)
+		})).ServeHTTP(rr, req)
+		return rr.Code
+	}
+	if code := serve("users:read"); code != http.StatusNoContent {
+		t.Fatalf("expected granted permission to pass without the resolver, got %d", code)
+	}
+	if code := serve("users:write"); code != http.StatusForbidden {
+		t.Fatalf("expected permission outside the token to be denied, got %d", code)
+	}
+}
+
+type testAccessPolicyEvaluator struct {
+	decision service.AccessDecision
+	err      error
+	got      *service.AccessRequest
+}
+
+func (e *testAccessPolicyEvaluator) Evaluate(_ context.Context, req service.AccessRequest) (service.AccessDecision, error) {
+	e.got = &req
+	return e.decision, e.err
+}
+
+func TestRequirePermissionWithPolicies(t *testing.T) {
+	run := func(t *testing.T, allow bool, evaluator *testAccessPolicyEvaluator) (int, bool) {
+		t.Helper()
+		r := chi.NewRouter()
+		called := false
+		r.With(RequirePermissionWithPolicies(testRBACAuthorizer{allow: allow}, testPermissionResolver{perms: []string{"users:write"}}, evaluator, "users:write")).
+			Patch("/users/{id}", func(http.ResponseWriter, *http.Request) { called = true })
+		req := httptest.NewRequest(http.MethodPatch, "/users/42", nil)
+		req.RemoteAddr = "10.1.2.3:5555"
+		claims := &security.Claims{}
+		claims.Subject = "7"
+		req = req.WithContext(context.WithValue(req.Context(), ClaimsContextKey, claims))
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		return rr.Code, called
+	}
+
+	allowed := &testAccessPolicyEvaluator{decision: service.AccessDecision{Allowed: true}}
+	if code, called := run(t, true, allowed); code != http.StatusOK || !called {
+		t.Fatalf("expected passing policies to allow, got code=%d called=%v", code, called)
+	}
+	got := allowed.got
+	if got == nil || got.Permission != "users:write" || got.IP != "10.1.2.3" || got.Method != http.MethodPatch ||
+		got.Params["id"] != "42" || len(got.Permissions) != 1 || got.Claims.Subject != "7" {
+		t.Fatalf("unexpected access request %+v", got)
+	}
+
+	denied := &testAccessPolicyEvaluator{decision: service.AccessDecision{Policy: "office-network"}}
+	if code, called := run(t, true, denied); code != http.StatusForbidden || called {
+		t.Fatalf("expected policy denial to return 403, got code=%d called=%v", code, called)
+	}
+
+	failing := &testAccessPolicyEvaluator{decision: service.AccessDecision{Policy: "broken"}, err: errors.New("fetch failed")}
+	if code, called := run(t, true, failing); code != http.StatusServiceUnavailable || called {
+		t.Fatalf("expected evaluation errors to fail closed, got code=%d called=%v", code, called)
+	}
+
+	unused := &testAccessPolicyEvaluator{decision: service.AccessDecision{Allowed: true}}
+	if code, _ := run(t, false, unused); code != http.StatusForbidden || unused.got != nil {
+		t.Fatalf("expected policies to be skipped without the permission, got code=%d evaluated=%v", code, unused.got != nil)
+	}
+}
@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/http/response"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/observability"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/service"
+)
+
+// RequireRecentAuth rejects callers whose auth_time is older than maxAge, or
+// missing, with REAUTH_REQUIRED so the client can step up via /auth/reauth.
+// It must run after AuthMiddleware. Service accounts authenticate with their
+// secret for every token and are exempt; API keys carry no auth_time and are
+// refused. A non-positive maxAge disables the check.
+func RequireRecentAuth(maxAge time.Duration) func(http.Handler) http.Handler {
+	return requireRecentAuth(maxAge, false)
+}
+
+// RequireRecentAuthOrAPIKey is RequireRecentAuth for permission-gated admin
+// routes. There an API key is also let through: its scopes already bound what
+// it can do, and the scripts holding it have no way to step up.
+func RequireRecentAuthOrAPIKey(maxAge time.Duration) func(http.Handler) http.Handler {
+	return requireRecentAuth(maxAge, true)
+}
+
+func requireRecentAuth(maxAge time.Duration, allowAPIKeys bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if maxAge <= 0 {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claims, ok := ClaimsFromContext(r.Context())
+			if !ok {
+				response.Error(w, r, http.StatusUnauthorized, "UNAUTHORIZED", "missing auth context", nil)
+				return
+			}
+			if claims.IsServiceAccount() || (allowAPIKeys && claims.TokenType == service.APIKeyClaimsTokenType) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if authTime, ok := claims.AuthenticatedAt(); ok && time.Since(authTime) <= maxAge {
+				next.ServeHTTP(w, r)
+				return
+			}
+			observability.RecordReauthEvent(r.Context(), "check", "required")
+			response.Error(w, r, http.StatusForbidden, "REAUTH_REQUIRED", "recent authentication required", map[string]any{
+				"max_age_seconds": int(maxAge.Seconds()),
+			})
+		})
+	}
+}
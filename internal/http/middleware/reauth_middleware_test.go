@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/security"
+)
+
+func TestRequireRecentAuth(t *testing.T) {
+	at := func(ago time.Duration) *jwt.NumericDate { return jwt.NewNumericDate(time.Now().Add(-ago)) }
+	cases := []struct {
+		name         string
+		claims       *security.Claims
+		maxAge       time.Duration
+		allowAPIKeys bool
+		want         int
+		wantErr      string
+	}{
+		{name: "fresh", claims: &security.Claims{AuthTime: at(time.Minute)}, maxAge: 5 * time.Minute, want: http.StatusOK},
+		{name: "stale", claims: &security.Claims{AuthTime: at(10 * time.Minute)}, maxAge: 5 * time.Minute, want: http.StatusForbidden, wantErr: "REAUTH_REQUIRED"},
+		{name: "missing auth_time", claims: &security.Claims{TokenType: "access"}, maxAge: 5 * time.Minute, want: http.StatusForbidden, wantErr: "REAUTH_REQUIRED"},
+		{name: "api key refused", claims: &security.Claims{TokenType: "api_key"}, maxAge: 5 * time.Minute, want: http.StatusForbidden, wantErr: "REAUTH_REQUIRED"},
+		{name: "api key exempt on admin routes", claims: &security.Claims{TokenType: "api_key"}, maxAge: 5 * time.Minute, allowAPIKeys: true, want: http.StatusOK},
+		{name: "stale session on admin routes", claims: &security.Claims{AuthTime: at(10 * time.Minute)}, maxAge: 5 * time.Minute, allowAPIKeys: true, want: http.StatusForbidden, wantErr: "REAUTH_REQUIRED"},
+		{name: "service account exempt", claims: &security.Claims{PrincipalType: security.PrincipalServiceAccount}, maxAge: 5 * time.Minute, want: http.StatusOK},
+		{name: "disabled", claims: &security.Claims{}, maxAge: 0, want: http.StatusOK},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", nil)
+			req = req.WithContext(context.WithValue(req.Context(), ClaimsContextKey, tc.claims))
+			rr := httptest.NewRecorder()
+
+			mw := RequireRecentAuth(tc.maxAge)
+			if tc.allowAPIKeys {
+				mw = RequireRecentAuthOrAPIKey(tc.maxAge)
+			}
+			mw(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})).ServeHTTP(rr, req)
+
+			if rr.Code != tc.want {
+				t.Fatalf("expected status %d, got %d body=%s", tc.want, rr.Code, rr.Body.String())
+			}
+			if tc.wantErr != "" && !strings.Contains(rr.Body.String(), tc.wantErr) {
+				t.Fatalf("expected %s in body, got %s", tc.wantErr, rr.Body.String())
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"context"
 	"log/slog"
 	"net/http"
 	"time"
@@ -9,12 +10,29 @@ import (
 	chimiddleware "github.com/go-chi/chi/v5/middleware"
 )
 
+type requestLogAttrsKey struct{}
+
+type requestLogAttrs struct {
+	attrs []any
+}
+
+// annotateRequestLog adds attributes to the request's log line. The logger
+// runs outside the auth middleware, so facts learned from the token are
+// handed back through a holder it places in the context.
+func annotateRequestLog(ctx context.Context, attrs ...any) {
+	if holder, ok := ctx.Value(requestLogAttrsKey{}).(*requestLogAttrs); ok {
+		holder.attrs = append(holder.attrs, attrs...)
+	}
+}
+
 // StructuredRequestLogger emits one structured log line per request using slog.
 // This keeps request logs aligned with the app's OTel-enriched logging path.
 func StructuredRequestLogger(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
 		ww := chimiddleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		extra := &requestLogAttrs{}
+		r = r.WithContext(context.WithValue(r.Context(), requestLogAttrsKey{}, extra))
 
 		next.ServeHTTP(ww, r)
 
@@ -40,6 +58,7 @@ func StructuredRequestLogger(next http.Handler) http.Handler {
 			"client_ip", r.RemoteAddr,
 			"user_agent", r.UserAgent(),
 		}
+		attrs = append(attrs, extra.attrs...)
 
 		if status >= http.StatusInternalServerError {
 			slog.ErrorContext(r.Context(), "http.request", attrs...)
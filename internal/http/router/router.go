@@ -20,9 +20,23 @@ type Dependencies struct {
 	AuthHandler                *handler.AuthHandler
 	UserHandler                *handler.UserHandler
 	AdminHandler               *handler.AdminHandler
+	WebAuthnHandler            *handler.WebAuthnHandler
+	APIKeyHandler              *handler.APIKeyHandler
+	ServiceAccountHandler      *handler.ServiceAccountHandler
+	ImpersonationHandler       *handler.ImpersonationHandler
+	OrganizationHandler        *handler.OrganizationHandler
+	OrganizationMembership     service.OrganizationMembershipChecker
+	AccessPolicyHandler        *handler.AccessPolicyHandler
+	AccessPolicies             service.AccessPolicyEvaluator
+	TrustedProxyCIDRs          []string
+	JWKSHandler                *handler.JWKSHandler
 	JWTManager                 *security.JWTManager
+	AccessTokenRevocations     service.AccessTokenRevocationStore
+	APIKeyAuthenticator        service.APIKeyAuthenticator
 	RBACService                service.RBACAuthorizer
 	PermissionResolver         service.PermissionResolver
+	AdminMFAChecker            service.MFAStatusChecker
+	ReauthMaxAge               time.Duration
 	CORSOrigins                []string
 	AuthRateLimitRPM           int
 	PasswordForgotRateLimitRPM int
@@ -51,6 +65,7 @@ const (
 
 func NewRouter(dep Dependencies) http.Handler {
 	r := chi.NewRouter()
+	r.Use(middleware.PolicyClientIP(dep.TrustedProxyCIDRs))
 	r.Use(chimiddleware.RealIP)
 	r.Use(chimiddleware.Recoverer)
 	r.Use(middleware.RequestID)
@@ -64,6 +79,14 @@ func NewRouter(dep Dependencies) http.Handler {
 		r.Use(middleware.NewRateLimiter(dep.APIRateLimitRPM, time.Minute).Middleware())
 	}
 
+	authn := middleware.AuthMiddleware(dep.JWTManager, dep.AccessTokenRevocations, dep.APIKeyAuthenticator)
+	stepUp := middleware.RequireRecentAuth(dep.ReauthMaxAge)
+	adminStepUp := middleware.RequireRecentAuthOrAPIKey(dep.ReauthMaxAge)
+	// requirePermission also applies the access policies attached to
+	// permission, when policies are enabled.
+	requirePermission := func(permission string) func(http.Handler) http.Handler {
+		return middleware.RequirePermissionWithPolicies(dep.RBACService, dep.PermissionResolver, dep.AccessPolicies, permission)
+	}
 	authLimiter := dep.AuthRateLimiter
 	if authLimiter == nil {
 		authLimiter = middleware.NewRateLimiter(dep.AuthRateLimitRPM, time.Minute).Middleware()
@@ -100,10 +123,16 @@ func NewRouter(dep Dependencies) http.Handler {
 		response.Error(w, r, http.StatusServiceUnavailable, "DEPENDENCY_UNREADY", "dependencies are not ready", map[string]any{"checks": results})
 	})
 
+	if dep.JWKSHandler != nil {
+		r.Get("/.well-known/jwks.json", dep.JWKSHandler.JWKS)
+	}
+
 	r.Route("/api/v1", func(r chi.Router) {
 		r.Route("/auth", func(r chi.Router) {
 			r.With(authLimiter).Get("/google/login", dep.AuthHandler.GoogleLogin)
 			r.With(authLimiter).Get("/google/callback", dep.AuthHandler.GoogleCallback)
+			r.With(authLimiter).Get("/{provider}/login", dep.AuthHandler.OAuthLogin)
+			r.With(authLimiter).Get("/{provider}/callback", dep.AuthHandler.OAuthCallback)
 			registerChain := []func(http.Handler) http.Handler{authLimiter}
 			if dep.Idempotency != nil {
 				registerChain = append(registerChain, dep.Idempotency("auth.local.register"))
@@ -118,50 +147,168 @@ func NewRouter(dep Dependencies) http.Handler {
 			}
 			r.With(forgotChain...).Post("/local/password/forgot", dep.AuthHandler.LocalPasswordForgot)
 			r.With(authLimiter).Post("/local/password/reset", dep.AuthHandler.LocalPasswordReset)
+			magicChain := []func(http.Handler) http.Handler{authLimiter}
+			if dep.Idempotency != nil {
+				magicChain = append(magicChain, dep.Idempotency("auth.magic.request"))
+			}
+			r.With(magicChain...).Post("/magic/request", dep.AuthHandler.MagicLinkRequest)
+			r.With(routePolicy(RoutePolicyLogin, authLimiter)).Post("/magic/confirm", dep.AuthHandler.MagicLinkConfirm)
+			r.With(authLimiter).Post("/mfa/verify", dep.AuthHandler.MFAVerify)
+			if dep.WebAuthnHandler != nil {
+				r.With(authLimiter).Post("/webauthn/login/begin", dep.WebAuthnHandler.LoginBegin)
+				r.With(routePolicy(RoutePolicyLogin, authLimiter)).Post("/webauthn/login/finish", dep.WebAuthnHandler.LoginFinish)
+				r.Group(func(r chi.Router) {
+					r.Use(authn)
+					r.Use(middleware.DenyImpersonation)
+					r.Use(middleware.DenyAPIKeys)
+					r.Use(middleware.CSRFMiddleware)
+					r.With(authLimiter, stepUp).Post("/webauthn/register/begin", dep.WebAuthnHandler.RegisterBegin)
+					r.With(authLimiter, stepUp).Post("/webauthn/register/finish", dep.WebAuthnHandler.RegisterFinish)
+				})
+			}
 			r.Group(func(r chi.Router) {
 				r.Use(middleware.CSRFMiddleware)
 				r.With(routePolicy(RoutePolicyRefresh, authLimiter)).Post("/refresh", dep.AuthHandler.Refresh)
-				r.With(middleware.AuthMiddleware(dep.JWTManager)).Post("/logout", dep.AuthHandler.Logout)
-				r.With(middleware.AuthMiddleware(dep.JWTManager), authLimiter).Post("/local/change-password", dep.AuthHandler.LocalChangePassword)
+				r.With(authn, middleware.DenyImpersonation).Post("/logout", dep.AuthHandler.Logout)
+				r.With(authn, middleware.DenyImpersonation, middleware.DenyAPIKeys, authLimiter, stepUp).Post("/local/change-password", dep.AuthHandler.LocalChangePassword)
+				r.With(authn, middleware.DenyImpersonation, middleware.DenyAPIKeys, authLimiter).Post("/reauth", dep.AuthHandler.Reauth)
+				if dep.ImpersonationHandler != nil {
+					r.With(authn).Post("/impersonation/end", dep.ImpersonationHandler.End)
+				}
 			})
 		})
 
-		r.With(middleware.AuthMiddleware(dep.JWTManager)).Get("/me", dep.UserHandler.Me)
-		r.With(middleware.AuthMiddleware(dep.JWTManager)).Get("/me/sessions", dep.UserHandler.Sessions)
+		if dep.ServiceAccountHandler != nil {
+			r.With(authLimiter).Post("/oauth/token", dep.ServiceAccountHandler.Token)
+		}
+
+		r.With(authn).Get("/me", dep.UserHandler.Me)
+		r.With(authn).Get("/me/sessions", dep.UserHandler.Sessions)
+		r.With(authn).Get("/me/identities", dep.AuthHandler.Identities)
+		r.With(authn, middleware.DenyImpersonation, middleware.DenyAPIKeys, authLimiter).Get("/me/export", dep.UserHandler.ExportData)
 		r.Group(func(r chi.Router) {
-			r.Use(middleware.AuthMiddleware(dep.JWTManager))
+			r.Use(authn)
+			r.Use(middleware.DenyImpersonation)
+			r.Use(middleware.DenyAPIKeys)
 			r.Use(middleware.CSRFMiddleware)
-			r.Delete("/me/sessions/{session_id}", dep.UserHandler.RevokeSession)
-			r.Post("/me/sessions/revoke-others", dep.UserHandler.RevokeOtherSessions)
+			r.With(authLimiter, stepUp).Delete("/me", dep.UserHandler.DeleteAccount)
+			r.With(stepUp).Delete("/me/sessions/{session_id}", dep.UserHandler.RevokeSession)
+			r.With(stepUp).Post("/me/sessions/revoke-others", dep.UserHandler.RevokeOtherSessions)
+			r.With(authLimiter, stepUp).Post("/me/email/change", dep.UserHandler.RequestEmailChange)
+			r.With(authLimiter).Post("/me/email/confirm", dep.UserHandler.ConfirmEmailChange)
+			r.With(authLimiter, stepUp).Post("/me/mfa/totp/setup", dep.AuthHandler.MFATOTPSetup)
+			r.With(authLimiter, stepUp).Post("/me/mfa/totp/confirm", dep.AuthHandler.MFATOTPConfirm)
+			r.With(authLimiter, stepUp).Post("/me/identities/{provider}/link", dep.AuthHandler.LinkIdentity)
+			r.With(stepUp).Delete("/me/identities/{identity_id}", dep.AuthHandler.UnlinkIdentity)
+			if dep.WebAuthnHandler != nil {
+				r.With(stepUp).Delete("/me/webauthn/credentials/{credential_id}", dep.WebAuthnHandler.DeleteCredential)
+			}
+			if dep.APIKeyHandler != nil {
+				r.With(stepUp).Post("/me/api-keys", dep.APIKeyHandler.Create)
+				r.Delete("/me/api-keys/{key_id}", dep.APIKeyHandler.Revoke)
+			}
 		})
+		if dep.WebAuthnHandler != nil {
+			r.With(authn).Get("/me/webauthn/credentials", dep.WebAuthnHandler.ListCredentials)
+		}
+		if dep.APIKeyHandler != nil {
+			r.With(authn).Get("/me/api-keys", dep.APIKeyHandler.List)
+		}
 
-		r.Route("/admin", func(r chi.Router) {
-			r.Use(middleware.AuthMiddleware(dep.JWTManager))
-			r.With(middleware.RequirePermission(dep.RBACService, dep.PermissionResolver, "users:read")).Get("/users", dep.AdminHandler.ListUsers)
-			userRoleChain := []func(http.Handler) http.Handler{
-				middleware.RequirePermission(dep.RBACService, dep.PermissionResolver, "users:write"),
-				routePolicy(RoutePolicyAdminWrite, nil),
-			}
-			if dep.Idempotency != nil {
-				userRoleChain = append(userRoleChain, dep.Idempotency("admin.users.roles.patch"))
+		if dep.OrganizationHandler != nil {
+			r.With(authn).Get("/orgs", dep.OrganizationHandler.ListMine)
+			// The same org-scoped routes are reachable with the organization in
+			// the path or, under /org, in the X-Organization-ID header.
+			orgRoutes := func(r chi.Router) {
+				r.Use(authn)
+				r.Use(middleware.OrganizationScope(dep.OrganizationMembership, dep.RBACService, dep.PermissionResolver))
+				r.Get("/", dep.OrganizationHandler.Get)
+				r.Group(func(r chi.Router) {
+					r.Use(middleware.DenyImpersonation)
+					if dep.AdminMFAChecker != nil {
+						r.Use(middleware.RequireMFAEnrollment(dep.AdminMFAChecker))
+					}
+					r.With(requirePermission("members:read")).Get("/members", dep.OrganizationHandler.ListMembers)
+					r.With(requirePermission("members:write"), adminStepUp, routePolicy(RoutePolicyAdminWrite, nil)).Put("/members/{userID}", dep.OrganizationHandler.SetMember)
+					r.With(requirePermission("members:write"), adminStepUp, routePolicy(RoutePolicyAdminWrite, nil)).Delete("/members/{userID}", dep.OrganizationHandler.RemoveMember)
+				})
 			}
-			r.With(userRoleChain...).Patch("/users/{id}/roles", dep.AdminHandler.SetUserRoles)
-			r.With(middleware.RequirePermission(dep.RBACService, dep.PermissionResolver, "roles:read")).Get("/roles", dep.AdminHandler.ListRoles)
-			roleCreateChain := []func(http.Handler) http.Handler{
-				middleware.RequirePermission(dep.RBACService, dep.PermissionResolver, "roles:write"),
-				routePolicy(RoutePolicyAdminWrite, nil),
+			r.Route("/orgs/{orgID}", orgRoutes)
+			r.Route("/org", orgRoutes)
+		}
+
+		r.Route("/admin", func(r chi.Router) {
+			r.Use(authn)
+			r.Use(middleware.DenyImpersonation)
+			if dep.AdminMFAChecker != nil {
+				r.Use(middleware.RequireMFAEnrollment(dep.AdminMFAChecker))
 			}
-			if dep.Idempotency != nil {
-				roleCreateChain = append(roleCreateChain, dep.Idempotency("admin.roles.create"))
+			if dep.OrganizationHandler != nil {
+				// With X-Organization-ID the permission checks below also see
+				// the caller's bindings in that organization.
+				r.Use(middleware.OptionalOrganizationScope(dep.OrganizationMembership, dep.RBACService, dep.PermissionResolver))
 			}
-			r.With(roleCreateChain...).Post("/roles", dep.AdminHandler.CreateRole)
-			r.With(middleware.RequirePermission(dep.RBACService, dep.PermissionResolver, "roles:write"), routePolicy(RoutePolicyAdminWrite, nil)).Patch("/roles/{id}", dep.AdminHandler.UpdateRole)
-			r.With(middleware.RequirePermission(dep.RBACService, dep.PermissionResolver, "roles:write"), routePolicy(RoutePolicyAdminWrite, nil)).Delete("/roles/{id}", dep.AdminHandler.DeleteRole)
-			r.With(middleware.RequirePermission(dep.RBACService, dep.PermissionResolver, "permissions:read")).Get("/permissions", dep.AdminHandler.ListPermissions)
-			r.With(middleware.RequirePermission(dep.RBACService, dep.PermissionResolver, "permissions:write"), routePolicy(RoutePolicyAdminWrite, nil)).Post("/permissions", dep.AdminHandler.CreatePermission)
-			r.With(middleware.RequirePermission(dep.RBACService, dep.PermissionResolver, "permissions:write"), routePolicy(RoutePolicyAdminWrite, nil)).Patch("/permissions/{id}", dep.AdminHandler.UpdatePermission)
-			r.With(middleware.RequirePermission(dep.RBACService, dep.PermissionResolver, "permissions:write"), routePolicy(RoutePolicyAdminWrite, nil)).Delete("/permissions/{id}", dep.AdminHandler.DeletePermission)
-			r.With(middleware.RequirePermission(dep.RBACService, dep.PermissionResolver, "roles:write"), routePolicy(RoutePolicyAdminSync, routePolicy(RoutePolicyAdminWrite, nil))).Post("/rbac/sync", dep.AdminHandler.SyncRBAC)
+			r.With(requirePermission("users:read")).Get("/users", dep.AdminHandler.ListUsers)
+			r.With(requirePermission("roles:read")).Get("/roles", dep.AdminHandler.ListRoles)
+			r.With(requirePermission("permissions:read")).Get("/permissions", dep.AdminHandler.ListPermissions)
+
+			// Everything else reads or changes state shared by every
+			// organization, which organization bindings must not reach.
+			r.Group(func(r chi.Router) {
+				r.Use(middleware.RequireGlobalScope)
+				userRoleChain := []func(http.Handler) http.Handler{
+					requirePermission("users:write"),
+					adminStepUp,
+					routePolicy(RoutePolicyAdminWrite, nil),
+				}
+				if dep.Idempotency != nil {
+					userRoleChain = append(userRoleChain, dep.Idempotency("admin.users.roles.patch"))
+				}
+				r.With(userRoleChain...).Patch("/users/{id}/roles", dep.AdminHandler.SetUserRoles)
+				r.With(requirePermission("users:write"), adminStepUp, routePolicy(RoutePolicyAdminWrite, nil)).Post("/users/{id}/suspend", dep.AdminHandler.SuspendUser)
+				r.With(requirePermission("users:write"), adminStepUp, routePolicy(RoutePolicyAdminWrite, nil)).Post("/users/{id}/reactivate", dep.AdminHandler.ReactivateUser)
+				r.With(requirePermission("users:write"), adminStepUp, routePolicy(RoutePolicyAdminWrite, nil)).Post("/users/{id}/erase", dep.AdminHandler.EraseUser)
+				if dep.ImpersonationHandler != nil {
+					r.With(requirePermission(service.PermissionImpersonate), adminStepUp, routePolicy(RoutePolicyAdminWrite, nil)).Post("/users/{id}/impersonate", dep.ImpersonationHandler.Start)
+				}
+				roleCreateChain := []func(http.Handler) http.Handler{
+					requirePermission("roles:write"),
+					adminStepUp,
+					routePolicy(RoutePolicyAdminWrite, nil),
+				}
+				if dep.Idempotency != nil {
+					roleCreateChain = append(roleCreateChain, dep.Idempotency("admin.roles.create"))
+				}
+				r.With(roleCreateChain...).Post("/roles", dep.AdminHandler.CreateRole)
+				r.With(requirePermission("roles:write"), adminStepUp, routePolicy(RoutePolicyAdminWrite, nil)).Patch("/roles/{id}", dep.AdminHandler.UpdateRole)
+				r.With(requirePermission("roles:write"), adminStepUp, routePolicy(RoutePolicyAdminWrite, nil)).Delete("/roles/{id}", dep.AdminHandler.DeleteRole)
+				r.With(requirePermission("permissions:write"), adminStepUp, routePolicy(RoutePolicyAdminWrite, nil)).Post("/permissions", dep.AdminHandler.CreatePermission)
+				r.With(requirePermission("permissions:write"), adminStepUp, routePolicy(RoutePolicyAdminWrite, nil)).Patch("/permissions/{id}", dep.AdminHandler.UpdatePermission)
+				r.With(requirePermission("permissions:write"), adminStepUp, routePolicy(RoutePolicyAdminWrite, nil)).Delete("/permissions/{id}", dep.AdminHandler.DeletePermission)
+				r.With(requirePermission("roles:write"), adminStepUp, routePolicy(RoutePolicyAdminSync, routePolicy(RoutePolicyAdminWrite, nil))).Post("/rbac/sync", dep.AdminHandler.SyncRBAC)
+				if dep.ServiceAccountHandler != nil {
+					r.With(requirePermission("users:read")).Get("/service-accounts", dep.ServiceAccountHandler.List)
+					r.With(requirePermission("users:read")).Get("/service-accounts/{id}", dep.ServiceAccountHandler.Get)
+					r.With(requirePermission("users:write"), adminStepUp, routePolicy(RoutePolicyAdminWrite, nil)).Post("/service-accounts", dep.ServiceAccountHandler.Create)
+					r.With(requirePermission("users:write"), adminStepUp, routePolicy(RoutePolicyAdminWrite, nil)).Patch("/service-accounts/{id}", dep.ServiceAccountHandler.Update)
+					r.With(requirePermission("users:write"), adminStepUp, routePolicy(RoutePolicyAdminWrite, nil)).Post("/service-accounts/{id}/rotate-secret", dep.ServiceAccountHandler.RotateSecret)
+					r.With(requirePermission("users:write"), adminStepUp, routePolicy(RoutePolicyAdminWrite, nil)).Delete("/service-accounts/{id}", dep.ServiceAccountHandler.Delete)
+				}
+				if dep.AccessPolicyHandler != nil {
+					// Policy management is deliberately exempt from access policies
+					// so that a bad policy cannot lock administrators out of
+					// fixing it.
+					r.With(middleware.RequirePermission(dep.RBACService, dep.PermissionResolver, "policies:read")).Get("/policies", dep.AccessPolicyHandler.List)
+					r.With(middleware.RequirePermission(dep.RBACService, dep.PermissionResolver, "policies:read")).Get("/policies/{id}", dep.AccessPolicyHandler.Get)
+					r.With(middleware.RequirePermission(dep.RBACService, dep.PermissionResolver, "policies:write"), adminStepUp, routePolicy(RoutePolicyAdminWrite, nil)).Post("/policies", dep.AccessPolicyHandler.Create)
+					r.With(middleware.RequirePermission(dep.RBACService, dep.PermissionResolver, "policies:write"), adminStepUp, routePolicy(RoutePolicyAdminWrite, nil)).Patch("/policies/{id}", dep.AccessPolicyHandler.Update)
+					r.With(middleware.RequirePermission(dep.RBACService, dep.PermissionResolver, "policies:write"), adminStepUp, routePolicy(RoutePolicyAdminWrite, nil)).Delete("/policies/{id}", dep.AccessPolicyHandler.Delete)
+				}
+				if dep.OrganizationHandler != nil {
+					r.With(requirePermission("orgs:read")).Get("/orgs", dep.OrganizationHandler.List)
+					r.With(requirePermission(service.PermissionOrganizationsWrite), adminStepUp, routePolicy(RoutePolicyAdminWrite, nil)).Post("/orgs", dep.OrganizationHandler.Create)
+				}
+			})
 		})
 	})
 
@@ -1,6 +1,7 @@
 package observability
 
 import (
+	"context"
 	"log/slog"
 	"net/http"
 	"strconv"
@@ -13,6 +14,32 @@ import (
 
 const auditEventVersion = 1
 
+type authSourceContextKey struct{}
+
+type impersonatorContextKey struct{}
+
+// WithAuthSource records how the caller authenticated (cookie, bearer or
+// api_key) so audit events emitted later in the request can carry it.
+func WithAuthSource(ctx context.Context, source string) context.Context {
+	return context.WithValue(ctx, authSourceContextKey{}, source)
+}
+
+func AuthSourceFromContext(ctx context.Context) string {
+	source, _ := ctx.Value(authSourceContextKey{}).(string)
+	return source
+}
+
+// WithImpersonator marks the request as made by actorUserID through an
+// impersonation session; every audit event emitted for it names the actor.
+func WithImpersonator(ctx context.Context, actorUserID string) context.Context {
+	return context.WithValue(ctx, impersonatorContextKey{}, actorUserID)
+}
+
+func ImpersonatorFromContext(ctx context.Context) string {
+	actor, _ := ctx.Value(impersonatorContextKey{}).(string)
+	return actor
+}
+
 type AuditInput struct {
 	EventName   string
 	ActorUserID string
@@ -24,37 +51,39 @@ type AuditInput struct {
 }
 
 type AuditEvent struct {
-	EventName    string `json:"event_name"`
-	EventVersion int    `json:"event_version"`
-	ActorUserID  string `json:"actor_user_id"`
-	ActorIP      string `json:"actor_ip"`
-	TargetType   string `json:"target_type"`
-	TargetID     string `json:"target_id"`
-	Action       string `json:"action"`
-	Outcome      string `json:"outcome"`
-	Reason       string `json:"reason"`
-	RequestID    string `json:"request_id"`
-	TraceID      string `json:"trace_id"`
-	SpanID       string `json:"span_id"`
-	TS           string `json:"ts"`
+	EventName          string `json:"event_name"`
+	EventVersion       int    `json:"event_version"`
+	ActorUserID        string `json:"actor_user_id"`
+	ActorIP            string `json:"actor_ip"`
+	ImpersonatorUserID string `json:"impersonator_user_id,omitempty"`
+	TargetType         string `json:"target_type"`
+	TargetID           string `json:"target_id"`
+	Action             string `json:"action"`
+	Outcome            string `json:"outcome"`
+	Reason             string `json:"reason"`
+	RequestID          string `json:"request_id"`
+	TraceID            string `json:"trace_id"`
+	SpanID             string `json:"span_id"`
+	TS                 string `json:"ts"`
 }
 
 func BuildAuditEvent(r *http.Request, in AuditInput) AuditEvent {
 	traceID, spanID := traceAndSpanFromContext(r)
 	ev := AuditEvent{
-		EventName:    strings.TrimSpace(in.EventName),
-		EventVersion: auditEventVersion,
-		ActorUserID:  defaultString(strings.TrimSpace(in.ActorUserID), "anonymous"),
-		ActorIP:      actorIP(r),
-		TargetType:   defaultString(strings.TrimSpace(in.TargetType), "none"),
-		TargetID:     defaultString(strings.TrimSpace(in.TargetID), "none"),
-		Action:       defaultString(strings.TrimSpace(in.Action), "unknown"),
-		Outcome:      defaultString(strings.TrimSpace(in.Outcome), "unknown"),
-		Reason:       defaultString(strings.TrimSpace(in.Reason), "none"),
-		RequestID:    requestID(r),
-		TraceID:      traceID,
-		SpanID:       spanID,
-		TS:           time.Now().UTC().Format(time.RFC3339),
+		EventName:          strings.TrimSpace(in.EventName),
+		EventVersion:       auditEventVersion,
+		ActorUserID:        defaultString(strings.TrimSpace(in.ActorUserID), "anonymous"),
+		ActorIP:            actorIP(r),
+		ImpersonatorUserID: ImpersonatorFromContext(r.Context()),
+		TargetType:         defaultString(strings.TrimSpace(in.TargetType), "none"),
+		TargetID:           defaultString(strings.TrimSpace(in.TargetID), "none"),
+		Action:             defaultString(strings.TrimSpace(in.Action), "unknown"),
+		Outcome:            defaultString(strings.TrimSpace(in.Outcome), "unknown"),
+		Reason:             defaultString(strings.TrimSpace(in.Reason), "none"),
+		RequestID:          requestID(r),
+		TraceID:            traceID,
+		SpanID:             spanID,
+		TS:                 time.Now().UTC().Format(time.RFC3339),
 	}
 	return ev
 }
@@ -108,9 +137,38 @@ func errInvalidAuditEvent(msg string) error {
 }
 
 func EmitAudit(r *http.Request, in AuditInput, attrs ...any) {
-	ev := BuildAuditEvent(r, in)
+	emitAuditEvent(r.Context(), BuildAuditEvent(r, in), attrs)
+}
+
+// BuildSystemAuditEvent builds an event for work done outside a request,
+// such as a background sweep. The actor defaults to "system" and the IP and
+// request ID to "internal".
+func BuildSystemAuditEvent(ctx context.Context, in AuditInput) AuditEvent {
+	traceID, spanID := traceAndSpan(ctx)
+	return AuditEvent{
+		EventName:    strings.TrimSpace(in.EventName),
+		EventVersion: auditEventVersion,
+		ActorUserID:  defaultString(strings.TrimSpace(in.ActorUserID), "system"),
+		ActorIP:      "internal",
+		TargetType:   defaultString(strings.TrimSpace(in.TargetType), "none"),
+		TargetID:     defaultString(strings.TrimSpace(in.TargetID), "none"),
+		Action:       defaultString(strings.TrimSpace(in.Action), "unknown"),
+		Outcome:      defaultString(strings.TrimSpace(in.Outcome), "unknown"),
+		Reason:       defaultString(strings.TrimSpace(in.Reason), "none"),
+		RequestID:    "internal",
+		TraceID:      traceID,
+		SpanID:       spanID,
+		TS:           time.Now().UTC().Format(time.RFC3339),
+	}
+}
+
+func EmitSystemAudit(ctx context.Context, in AuditInput, attrs ...any) {
+	emitAuditEvent(ctx, BuildSystemAuditEvent(ctx, in), attrs)
+}
+
+func emitAuditEvent(ctx context.Context, ev AuditEvent, attrs []any) {
 	if err := ev.Validate(); err != nil {
-		slog.ErrorContext(r.Context(), "audit.schema.invalid",
+		slog.ErrorContext(ctx, "audit.schema.invalid",
 			"error", err.Error(),
 			"event_name", ev.EventName,
 			"request_id", ev.RequestID,
@@ -132,8 +190,14 @@ func EmitAudit(r *http.Request, in AuditInput, attrs ...any) {
 		"span_id", ev.SpanID,
 		"ts", ev.TS,
 	}
+	if source := AuthSourceFromContext(ctx); source != "" {
+		base = append(base, "auth_source", source)
+	}
+	if ev.ImpersonatorUserID != "" {
+		base = append(base, "impersonator_user_id", ev.ImpersonatorUserID)
+	}
 	base = append(base, attrs...)
-	slog.InfoContext(r.Context(), "audit.event", base...)
+	slog.InfoContext(ctx, "audit.event", base...)
 }
 
 func ActorUserID(userID uint) string {
@@ -144,7 +208,11 @@ func ActorUserID(userID uint) string {
 }
 
 func traceAndSpanFromContext(r *http.Request) (string, string) {
-	sc := trace.SpanContextFromContext(r.Context())
+	return traceAndSpan(r.Context())
+}
+
+func traceAndSpan(ctx context.Context) (string, string) {
+	sc := trace.SpanContextFromContext(ctx)
 	if !sc.IsValid() {
 		return "", ""
 	}
@@ -1,6 +1,7 @@
 package observability
 
 import (
+	"context"
 	"net/http/httptest"
 	"testing"
 	"time"
@@ -38,6 +39,35 @@ func TestBuildAuditEventIncludesRequiredFields(t *testing.T) {
 	}
 }
 
+func TestBuildAuditEventCarriesImpersonator(t *testing.T) {
+	req := httptest.NewRequest("GET", "/api/v1/me", nil)
+	in := AuditInput{EventName: "user.me.read", ActorUserID: "42", Action: "read", Outcome: "success"}
+	if ev := BuildAuditEvent(req, in); ev.ImpersonatorUserID != "" {
+		t.Fatalf("expected no impersonator, got %q", ev.ImpersonatorUserID)
+	}
+	req = req.WithContext(WithImpersonator(req.Context(), "7"))
+	if ev := BuildAuditEvent(req, in); ev.ImpersonatorUserID != "7" {
+		t.Fatalf("expected impersonator 7, got %q", ev.ImpersonatorUserID)
+	}
+}
+
+func TestBuildSystemAuditEventIsValidWithoutRequest(t *testing.T) {
+	ev := BuildSystemAuditEvent(context.Background(), AuditInput{
+		EventName:  "admin.user_roles.expire",
+		TargetType: "user",
+		TargetID:   "42",
+		Action:     "expire",
+		Outcome:    "success",
+		Reason:     "binding_expired",
+	})
+	if ev.ActorUserID != "system" || ev.ActorIP != "internal" || ev.RequestID != "internal" {
+		t.Fatalf("expected system defaults, got %+v", ev)
+	}
+	if err := ev.Validate(); err != nil {
+		t.Fatalf("expected valid event, got %v", err)
+	}
+}
+
 func TestAuditEventValidateRejectsMissingEventName(t *testing.T) {
 	ev := AuditEvent{
 		EventVersion: 1,
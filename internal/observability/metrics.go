@@ -38,6 +38,21 @@ type AppMetrics struct {
 	sessionRevokedCount          metric.Float64Histogram
 	userProfileCounter           metric.Int64Counter
 	authLocalFlowCounter         metric.Int64Counter
+	authMFACounter               metric.Int64Counter
+	authWebAuthnCounter          metric.Int64Counter
+	accessTokenRevocationCounter metric.Int64Counter
+	authAPIKeyCounter            metric.Int64Counter
+	serviceAccountTokenCounter   metric.Int64Counter
+	authIdentityCounter          metric.Int64Counter
+	adminUserStatusCounter       metric.Int64Counter
+	authMagicLinkCounter         metric.Int64Counter
+	userEmailChangeCounter       metric.Int64Counter
+	userAccountCounter           metric.Int64Counter
+	emailNotificationCounter     metric.Int64Counter
+	notificationOutboxCounter    metric.Int64Counter
+	passwordRehashCounter        metric.Int64Counter
+	impersonationCounter         metric.Int64Counter
+	reauthCounter                metric.Int64Counter
 	adminListReqDuration         metric.Float64Histogram
 	adminListPageSize            metric.Float64Histogram
 	healthCheckResultCounter     metric.Int64Counter
@@ -53,6 +68,8 @@ type AppMetrics struct {
 	obscheckStageCounter         metric.Int64Counter
 	oauthGoogleReqDuration       metric.Float64Histogram
 	oauthGoogleErrorsCounter     metric.Int64Counter
+	oauthReqDuration             metric.Float64Histogram
+	oauthErrorsCounter           metric.Int64Counter
 	rbacAuthorizationCounter     metric.Int64Counter
 	securityBypassCounter        metric.Int64Counter
 	adminRBACSyncReport          metric.Float64Histogram
@@ -197,6 +214,66 @@ func InitMetrics(ctx context.Context, cfg *config.Config, logger *slog.Logger) (
 	if err != nil {
 		return nil, err
 	}
+	authMFACounter, err := meter.Int64Counter("auth.mfa.events")
+	if err != nil {
+		return nil, err
+	}
+	authWebAuthnCounter, err := meter.Int64Counter("auth.webauthn.events")
+	if err != nil {
+		return nil, err
+	}
+	accessTokenRevocationCounter, err := meter.Int64Counter("auth.access_token.revocations")
+	if err != nil {
+		return nil, err
+	}
+	authAPIKeyCounter, err := meter.Int64Counter("auth.api_key.events")
+	if err != nil {
+		return nil, err
+	}
+	serviceAccountTokenCounter, err := meter.Int64Counter("auth.service_account.tokens")
+	if err != nil {
+		return nil, err
+	}
+	authIdentityCounter, err := meter.Int64Counter("auth.identity.events")
+	if err != nil {
+		return nil, err
+	}
+	adminUserStatusCounter, err := meter.Int64Counter("admin.user_status.changes")
+	if err != nil {
+		return nil, err
+	}
+	authMagicLinkCounter, err := meter.Int64Counter("auth.magic_link.events")
+	if err != nil {
+		return nil, err
+	}
+	userEmailChangeCounter, err := meter.Int64Counter("user.email_change.events")
+	if err != nil {
+		return nil, err
+	}
+	userAccountCounter, err := meter.Int64Counter("user.account.events")
+	if err != nil {
+		return nil, err
+	}
+	emailNotificationCounter, err := meter.Int64Counter("email.notifications")
+	if err != nil {
+		return nil, err
+	}
+	notificationOutboxCounter, err := meter.Int64Counter("notification.outbox.events")
+	if err != nil {
+		return nil, err
+	}
+	passwordRehashCounter, err := meter.Int64Counter("auth.password.rehash")
+	if err != nil {
+		return nil, err
+	}
+	impersonationCounter, err := meter.Int64Counter("auth.impersonation.events")
+	if err != nil {
+		return nil, err
+	}
+	reauthCounter, err := meter.Int64Counter("auth.reauth.events")
+	if err != nil {
+		return nil, err
+	}
 	adminListReqDuration, err := meter.Float64Histogram(
 		"admin.list.request.duration",
 		metric.WithUnit("s"),
@@ -283,6 +360,18 @@ func InitMetrics(ctx context.Context, cfg *config.Config, logger *slog.Logger) (
 	if err != nil {
 		return nil, err
 	}
+	oauthReqDuration, err := meter.Float64Histogram(
+		"auth.oauth.request.duration",
+		metric.WithUnit("s"),
+		metric.WithDescription("Duration of OAuth/OIDC provider operations in seconds"),
+	)
+	if err != nil {
+		return nil, err
+	}
+	oauthErrorsCounter, err := meter.Int64Counter("auth.oauth.errors")
+	if err != nil {
+		return nil, err
+	}
 	rbacAuthorizationCounter, err := meter.Int64Counter("auth.rbac.authorization.events")
 	if err != nil {
 		return nil, err
@@ -336,6 +425,21 @@ func InitMetrics(ctx context.Context, cfg *config.Config, logger *slog.Logger) (
 		sessionRevokedCount:          sessionRevokedCount,
 		userProfileCounter:           userProfileCounter,
 		authLocalFlowCounter:         authLocalFlowCounter,
+		authMFACounter:               authMFACounter,
+		authWebAuthnCounter:          authWebAuthnCounter,
+		accessTokenRevocationCounter: accessTokenRevocationCounter,
+		authAPIKeyCounter:            authAPIKeyCounter,
+		serviceAccountTokenCounter:   serviceAccountTokenCounter,
+		authIdentityCounter:          authIdentityCounter,
+		adminUserStatusCounter:       adminUserStatusCounter,
+		authMagicLinkCounter:         authMagicLinkCounter,
+		userEmailChangeCounter:       userEmailChangeCounter,
+		userAccountCounter:           userAccountCounter,
+		emailNotificationCounter:     emailNotificationCounter,
+		notificationOutboxCounter:    notificationOutboxCounter,
+		passwordRehashCounter:        passwordRehashCounter,
+		impersonationCounter:         impersonationCounter,
+		reauthCounter:                reauthCounter,
 		adminListReqDuration:         adminListReqDuration,
 		adminListPageSize:            adminListPageSize,
 		healthCheckResultCounter:     healthCheckResultCounter,
@@ -351,6 +455,8 @@ func InitMetrics(ctx context.Context, cfg *config.Config, logger *slog.Logger) (
 		obscheckStageCounter:         obscheckStageCounter,
 		oauthGoogleReqDuration:       oauthGoogleReqDuration,
 		oauthGoogleErrorsCounter:     oauthGoogleErrorsCounter,
+		oauthReqDuration:             oauthReqDuration,
+		oauthErrorsCounter:           oauthErrorsCounter,
 		rbacAuthorizationCounter:     rbacAuthorizationCounter,
 		securityBypassCounter:        securityBypassCounter,
 		adminRBACSyncReport:          adminRBACSyncReport,
@@ -615,6 +721,198 @@ func RecordAuthLocalFlowEvent(ctx context.Context, flow, outcome string) {
 	))
 }
 
+func RecordAuthMFAEvent(ctx context.Context, action, outcome string) {
+	metricsMu.RLock()
+	m := appMetrics
+	metricsMu.RUnlock()
+	if m == nil {
+		return
+	}
+	m.authMFACounter.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("action", action),
+		attribute.String("outcome", outcome),
+	))
+}
+
+func RecordAuthWebAuthnEvent(ctx context.Context, ceremony, outcome string) {
+	metricsMu.RLock()
+	m := appMetrics
+	metricsMu.RUnlock()
+	if m == nil {
+		return
+	}
+	m.authWebAuthnCounter.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("ceremony", ceremony),
+		attribute.String("outcome", outcome),
+	))
+}
+
+func RecordAccessTokenRevocation(ctx context.Context, reason, outcome string) {
+	metricsMu.RLock()
+	m := appMetrics
+	metricsMu.RUnlock()
+	if m == nil {
+		return
+	}
+	m.accessTokenRevocationCounter.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("reason", reason),
+		attribute.String("outcome", outcome),
+	))
+}
+
+func RecordAuthAPIKeyEvent(ctx context.Context, action, outcome string) {
+	metricsMu.RLock()
+	m := appMetrics
+	metricsMu.RUnlock()
+	if m == nil {
+		return
+	}
+	m.authAPIKeyCounter.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("action", action),
+		attribute.String("outcome", outcome),
+	))
+}
+
+func RecordServiceAccountToken(ctx context.Context, outcome string) {
+	metricsMu.RLock()
+	m := appMetrics
+	metricsMu.RUnlock()
+	if m == nil {
+		return
+	}
+	m.serviceAccountTokenCounter.Add(ctx, 1, metric.WithAttributes(attribute.String("outcome", outcome)))
+}
+
+func RecordAuthIdentityEvent(ctx context.Context, action, outcome string) {
+	metricsMu.RLock()
+	m := appMetrics
+	metricsMu.RUnlock()
+	if m == nil {
+		return
+	}
+	m.authIdentityCounter.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("action", action),
+		attribute.String("outcome", outcome),
+	))
+}
+
+func RecordAdminUserStatusChange(ctx context.Context, action, outcome string) {
+	metricsMu.RLock()
+	m := appMetrics
+	metricsMu.RUnlock()
+	if m == nil {
+		return
+	}
+	m.adminUserStatusCounter.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("action", action),
+		attribute.String("outcome", outcome),
+	))
+}
+
+func RecordAuthMagicLinkEvent(ctx context.Context, action, outcome string) {
+	metricsMu.RLock()
+	m := appMetrics
+	metricsMu.RUnlock()
+	if m == nil {
+		return
+	}
+	m.authMagicLinkCounter.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("action", action),
+		attribute.String("outcome", outcome),
+	))
+}
+
+func RecordUserEmailChangeEvent(ctx context.Context, action, outcome string) {
+	metricsMu.RLock()
+	m := appMetrics
+	metricsMu.RUnlock()
+	if m == nil {
+		return
+	}
+	m.userEmailChangeCounter.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("action", action),
+		attribute.String("outcome", outcome),
+	))
+}
+
+func RecordUserAccountEvent(ctx context.Context, action, outcome string) {
+	metricsMu.RLock()
+	m := appMetrics
+	metricsMu.RUnlock()
+	if m == nil {
+		return
+	}
+	m.userAccountCounter.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("action", action),
+		attribute.String("outcome", outcome),
+	))
+}
+
+func RecordEmailNotification(ctx context.Context, kind, outcome string) {
+	metricsMu.RLock()
+	m := appMetrics
+	metricsMu.RUnlock()
+	if m == nil {
+		return
+	}
+	m.emailNotificationCounter.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("kind", kind),
+		attribute.String("outcome", outcome),
+	))
+}
+
+func RecordNotificationOutboxEvent(ctx context.Context, kind, outcome string) {
+	metricsMu.RLock()
+	m := appMetrics
+	metricsMu.RUnlock()
+	if m == nil {
+		return
+	}
+	m.notificationOutboxCounter.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("kind", kind),
+		attribute.String("outcome", outcome),
+	))
+}
+
+func RecordPasswordRehash(ctx context.Context, from, outcome string) {
+	metricsMu.RLock()
+	m := appMetrics
+	metricsMu.RUnlock()
+	if m == nil {
+		return
+	}
+	m.passwordRehashCounter.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("from", from),
+		attribute.String("outcome", outcome),
+	))
+}
+
+func RecordImpersonationEvent(ctx context.Context, action, outcome string) {
+	metricsMu.RLock()
+	m := appMetrics
+	metricsMu.RUnlock()
+	if m == nil {
+		return
+	}
+	m.impersonationCounter.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("action", action),
+		attribute.String("outcome", outcome),
+	))
+}
+
+func RecordReauthEvent(ctx context.Context, method, outcome string) {
+	metricsMu.RLock()
+	m := appMetrics
+	metricsMu.RUnlock()
+	if m == nil {
+		return
+	}
+	m.reauthCounter.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("method", method),
+		attribute.String("outcome", outcome),
+	))
+}
+
 func RecordAdminListRequestDuration(ctx context.Context, endpoint, status string, duration time.Duration) {
 	metricsMu.RLock()
 	m := appMetrics
@@ -805,7 +1103,43 @@ func RecordGoogleOAuthError(ctx context.Context, errorClass string) {
 	))
 }
 
-func RecordRBACAuthorizationEvent(ctx context.Context, requiredPermission, outcome string) {
+func RecordOAuthRequestDuration(ctx context.Context, provider, operation, status string, duration time.Duration) {
+	metricsMu.RLock()
+	m := appMetrics
+	metricsMu.RUnlock()
+	if m == nil {
+		return
+	}
+	m.oauthReqDuration.Record(ctx, duration.Seconds(), metric.WithAttributes(
+		attribute.String("provider", provider),
+		attribute.String("operation", operation),
+		attribute.String("status", status),
+	))
+	if provider == "google" {
+		RecordGoogleOAuthRequestDuration(ctx, operation, status, duration)
+	}
+}
+
+func RecordOAuthError(ctx context.Context, provider, errorClass string) {
+	metricsMu.RLock()
+	m := appMetrics
+	metricsMu.RUnlock()
+	if m == nil {
+		return
+	}
+	m.oauthErrorsCounter.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("provider", provider),
+		attribute.String("error_class", errorClass),
+	))
+	if provider == "google" {
+		RecordGoogleOAuthError(ctx, errorClass)
+	}
+}
+
+// RecordRBACAuthorizationEvent counts authorization decisions. reason explains
+// a denial or error: "missing_permission", "resolver_error", or the name of
+// the access policy that denied or failed; it is "none" when allowed.
+func RecordRBACAuthorizationEvent(ctx context.Context, requiredPermission, outcome, reason string) {
 	metricsMu.RLock()
 	m := appMetrics
 	metricsMu.RUnlock()
@@ -815,6 +1149,7 @@ func RecordRBACAuthorizationEvent(ctx context.Context, requiredPermission, outco
 	m.rbacAuthorizationCounter.Add(ctx, 1, metric.WithAttributes(
 		attribute.String("required_permission", requiredPermission),
 		attribute.String("outcome", outcome),
+		attribute.String("reason", reason),
 	))
 }
 
@@ -40,6 +40,21 @@ func TestRecordMetricHelpersNoPanicWhenUninitialized(t *testing.T) {
 	RecordSessionRevokedCount(ctx, "revoke_others", 2)
 	RecordUserProfileEvent(ctx, "success")
 	RecordAuthLocalFlowEvent(ctx, "forgot_password", "accepted")
+	RecordAuthMFAEvent(ctx, "verify", "success")
+	RecordAuthWebAuthnEvent(ctx, "login_finish", "success")
+	RecordAccessTokenRevocation(ctx, "logout", "success")
+	RecordAuthAPIKeyEvent(ctx, "authenticate", "success")
+	RecordServiceAccountToken(ctx, "success")
+	RecordAuthIdentityEvent(ctx, "link", "success")
+	RecordAdminUserStatusChange(ctx, "suspend", "success")
+	RecordAuthMagicLinkEvent(ctx, "confirm", "success")
+	RecordUserEmailChangeEvent(ctx, "confirm", "success")
+	RecordUserAccountEvent(ctx, "erase", "success")
+	RecordEmailNotification(ctx, "password_reset", "success")
+	RecordNotificationOutboxEvent(ctx, "password_reset", "sent")
+	RecordPasswordRehash(ctx, "bcrypt", "upgraded")
+	RecordImpersonationEvent(ctx, "start", "success")
+	RecordReauthEvent(ctx, "password", "success")
 	RecordAdminListRequestDuration(ctx, "roles", "success", 20*time.Millisecond)
 	RecordAdminListPageSize(ctx, "roles", 25)
 	RecordHealthCheckResult(ctx, "db", "ready")
@@ -55,7 +70,9 @@ func TestRecordMetricHelpersNoPanicWhenUninitialized(t *testing.T) {
 	RecordObscheckStageEvent(ctx, "traces", "pass")
 	RecordGoogleOAuthRequestDuration(ctx, "exchange", "success", 12*time.Millisecond)
 	RecordGoogleOAuthError(ctx, "token_exchange")
-	RecordRBACAuthorizationEvent(ctx, "users:read", "allow")
+	RecordOAuthRequestDuration(ctx, "github", "exchange", "success", 12*time.Millisecond)
+	RecordOAuthError(ctx, "github", "token_exchange")
+	RecordRBACAuthorizationEvent(ctx, "users:read", "allowed", "none")
 	RecordSecurityBypassEvent(ctx, "trusted_subnet", "login")
 	RecordAdminRBACSyncReport(ctx, "created_roles", 2)
 	RecordMiddlewareValidationEvent(ctx, "csrf", "pass")
@@ -99,6 +116,21 @@ func TestRecordMetricHelpersEmitExpectedLabelCardinality(t *testing.T) {
 	RecordSessionRevokedCount(ctx, "revoke_others", 2)
 	RecordUserProfileEvent(ctx, "success")
 	RecordAuthLocalFlowEvent(ctx, "forgot_password", "accepted")
+	RecordAuthMFAEvent(ctx, "verify", "success")
+	RecordAuthWebAuthnEvent(ctx, "login_finish", "success")
+	RecordAccessTokenRevocation(ctx, "logout", "success")
+	RecordAuthAPIKeyEvent(ctx, "authenticate", "success")
+	RecordServiceAccountToken(ctx, "success")
+	RecordAuthIdentityEvent(ctx, "link", "success")
+	RecordAdminUserStatusChange(ctx, "suspend", "success")
+	RecordAuthMagicLinkEvent(ctx, "confirm", "success")
+	RecordUserEmailChangeEvent(ctx, "confirm", "success")
+	RecordUserAccountEvent(ctx, "erase", "success")
+	RecordEmailNotification(ctx, "password_reset", "success")
+	RecordNotificationOutboxEvent(ctx, "password_reset", "sent")
+	RecordPasswordRehash(ctx, "bcrypt", "upgraded")
+	RecordImpersonationEvent(ctx, "start", "success")
+	RecordReauthEvent(ctx, "password", "success")
 	RecordAdminListRequestDuration(ctx, "roles", "success", 20*time.Millisecond)
 	RecordAdminListPageSize(ctx, "roles", 25)
 	RecordHealthCheckResult(ctx, "db", "ready")
@@ -114,7 +146,9 @@ func TestRecordMetricHelpersEmitExpectedLabelCardinality(t *testing.T) {
 	RecordObscheckStageEvent(ctx, "traces", "pass")
 	RecordGoogleOAuthRequestDuration(ctx, "exchange", "success", 12*time.Millisecond)
 	RecordGoogleOAuthError(ctx, "token_exchange")
-	RecordRBACAuthorizationEvent(ctx, "users:read", "allow")
+	RecordOAuthRequestDuration(ctx, "github", "exchange", "success", 12*time.Millisecond)
+	RecordOAuthError(ctx, "github", "token_exchange")
+	RecordRBACAuthorizationEvent(ctx, "users:read", "allowed", "none")
 	RecordSecurityBypassEvent(ctx, "trusted_subnet", "login")
 	RecordAdminRBACSyncReport(ctx, "created_roles", 2)
 	RecordMiddlewareValidationEvent(ctx, "csrf", "pass")
@@ -146,6 +180,21 @@ func TestRecordMetricHelpersEmitExpectedLabelCardinality(t *testing.T) {
 		"session.revoked.count":               1,
 		"user.profile.events":                 1,
 		"auth.local.flow.events":              2,
+		"auth.mfa.events":                     2,
+		"auth.webauthn.events":                2,
+		"auth.access_token.revocations":       2,
+		"auth.api_key.events":                 2,
+		"auth.service_account.tokens":         1,
+		"auth.identity.events":                2,
+		"admin.user_status.changes":           2,
+		"auth.magic_link.events":              2,
+		"user.email_change.events":            2,
+		"user.account.events":                 2,
+		"email.notifications":                 2,
+		"notification.outbox.events":          2,
+		"auth.password.rehash":                2,
+		"auth.impersonation.events":           2,
+		"auth.reauth.events":                  2,
 		"admin.list.request.duration":         2,
 		"admin.list.page_size":                1,
 		"health.check.results":                2,
@@ -161,7 +210,9 @@ func TestRecordMetricHelpersEmitExpectedLabelCardinality(t *testing.T) {
 		"obscheck.stage.events":               2,
 		"auth.oauth.google.request.duration":  2,
 		"auth.oauth.google.errors":            1,
-		"auth.rbac.authorization.events":      2,
+		"auth.oauth.request.duration":         3,
+		"auth.oauth.errors":                   2,
+		"auth.rbac.authorization.events":      3,
 		"security.bypass.events":              2,
 		"admin.rbac.sync.report":              1,
 		"http.middleware.validation.events":   2,
@@ -237,6 +288,21 @@ func newTestAppMetrics(t *testing.T, provider *sdkmetric.MeterProvider) *AppMetr
 		sessionRevokedCount:          hist("session.revoked.count"),
 		userProfileCounter:           counter("user.profile.events"),
 		authLocalFlowCounter:         counter("auth.local.flow.events"),
+		authMFACounter:               counter("auth.mfa.events"),
+		authWebAuthnCounter:          counter("auth.webauthn.events"),
+		accessTokenRevocationCounter: counter("auth.access_token.revocations"),
+		authAPIKeyCounter:            counter("auth.api_key.events"),
+		serviceAccountTokenCounter:   counter("auth.service_account.tokens"),
+		authIdentityCounter:          counter("auth.identity.events"),
+		adminUserStatusCounter:       counter("admin.user_status.changes"),
+		authMagicLinkCounter:         counter("auth.magic_link.events"),
+		userEmailChangeCounter:       counter("user.email_change.events"),
+		userAccountCounter:           counter("user.account.events"),
+		emailNotificationCounter:     counter("email.notifications"),
+		notificationOutboxCounter:    counter("notification.outbox.events"),
+		passwordRehashCounter:        counter("auth.password.rehash"),
+		impersonationCounter:         counter("auth.impersonation.events"),
+		reauthCounter:                counter("auth.reauth.events"),
 		adminListReqDuration:         hist("admin.list.request.duration"),
 		adminListPageSize:            hist("admin.list.page_size"),
 		healthCheckResultCounter:     counter("health.check.results"),
@@ -252,6 +318,8 @@ func newTestAppMetrics(t *testing.T, provider *sdkmetric.MeterProvider) *AppMetr
 		obscheckStageCounter:         counter("obscheck.stage.events"),
 		oauthGoogleReqDuration:       hist("auth.oauth.google.request.duration"),
 		oauthGoogleErrorsCounter:     counter("auth.oauth.google.errors"),
+		oauthReqDuration:             hist("auth.oauth.request.duration"),
+		oauthErrorsCounter:           counter("auth.oauth.errors"),
 		rbacAuthorizationCounter:     counter("auth.rbac.authorization.events"),
 		securityBypassCounter:        counter("security.bypass.events"),
 		adminRBACSyncReport:          hist("admin.rbac.sync.report"),
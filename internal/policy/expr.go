@@ -0,0 +1,562 @@
+// Package policy implements the condition language evaluated by access
+// policies.
+//
+// A condition is a boolean expression over three attribute roots:
+//
+//	subject.*   the authenticated principal (id, type, roles, permissions, ...)
+//	request.*   the HTTP request (ip, method, path, params.<name>, org_id)
+//	resource.*  attributes of the targeted resource, loaded on demand
+//
+// Supported syntax: || && ! == != < <= > >= in, parentheses, string, number,
+// boolean and null literals, list literals ([1, 2]) and the functions listed
+// in functions.go. References to attributes that do not exist evaluate to
+// null.
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MaxConditionLength bounds stored conditions; it matches the column size of
+// domain.AccessPolicy.Condition.
+const MaxConditionLength = 2048
+
+const maxNestingDepth = 32
+
+var (
+	ErrSyntax = errors.New("policy condition syntax error")
+	ErrEval   = errors.New("policy condition evaluation error")
+)
+
+// Roots are the attribute namespaces a condition may reference.
+var Roots = []string{"subject", "request", "resource"}
+
+// Env resolves attribute references during evaluation. Lookup receives the
+// dotted reference split into segments, e.g. ["request", "params", "id"], and
+// returns (nil, nil) when the attribute is absent.
+type Env interface {
+	Lookup(path []string) (any, error)
+	Now() time.Time
+}
+
+// Program is a compiled condition. It is immutable and safe for concurrent
+// use.
+type Program struct {
+	source string
+	root   node
+}
+
+// Compile parses src and checks that it only references known roots and
+// functions.
+func Compile(src string) (*Program, error) {
+	src = strings.TrimSpace(src)
+	if src == "" {
+		return nil, fmt.Errorf("%w: empty condition", ErrSyntax)
+	}
+	if len(src) > MaxConditionLength {
+		return nil, fmt.Errorf("%w: condition exceeds %d characters", ErrSyntax, MaxConditionLength)
+	}
+	tokens, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	root, err := p.parseOr(0)
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokEOF {
+		return nil, fmt.Errorf("%w: unexpected %q at offset %d", ErrSyntax, tok.text, tok.pos)
+	}
+	return &Program{source: src, root: root}, nil
+}
+
+func (p *Program) String() string { return p.source }
+
+// Eval runs the program against env. Conditions must produce a boolean.
+func (p *Program) Eval(env Env) (bool, error) {
+	v, err := p.root.eval(env)
+	if err != nil {
+		return false, err
+	}
+	b, ok := v.(bool)
+	if !ok {
+		return false, fmt.Errorf("%w: condition produced %s, not a boolean", ErrEval, typeName(v))
+	}
+	return b, nil
+}
+
+type node interface {
+	eval(env Env) (any, error)
+}
+
+type literalNode struct{ value any }
+
+func (n literalNode) eval(Env) (any, error) { return n.value, nil }
+
+type refNode struct{ path []string }
+
+func (n refNode) eval(env Env) (any, error) {
+	v, err := env.Lookup(n.path)
+	if err != nil {
+		return nil, err
+	}
+	return normalize(v), nil
+}
+
+type listNode struct{ items []node }
+
+func (n listNode) eval(env Env) (any, error) {
+	out := make([]any, 0, len(n.items))
+	for _, item := range n.items {
+		v, err := item.eval(env)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, v)
+	}
+	return out, nil
+}
+
+type notNode struct{ operand node }
+
+func (n notNode) eval(env Env) (any, error) {
+	v, err := n.operand.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	b, ok := v.(bool)
+	if !ok {
+		return nil, fmt.Errorf("%w: ! expects a boolean, got %s", ErrEval, typeName(v))
+	}
+	return !b, nil
+}
+
+type logicalNode struct {
+	op          string
+	left, right node
+}
+
+func (n logicalNode) eval(env Env) (any, error) {
+	left, err := evalBool(n.left, env, n.op)
+	if err != nil {
+		return nil, err
+	}
+	if (n.op == "&&" && !left) || (n.op == "||" && left) {
+		return left, nil
+	}
+	return evalBool(n.right, env, n.op)
+}
+
+func evalBool(n node, env Env, op string) (bool, error) {
+	v, err := n.eval(env)
+	if err != nil {
+		return false, err
+	}
+	b, ok := v.(bool)
+	if !ok {
+		return false, fmt.Errorf("%w: %s expects booleans, got %s", ErrEval, op, typeName(v))
+	}
+	return b, nil
+}
+
+type compareNode struct {
+	op          string
+	left, right node
+}
+
+func (n compareNode) eval(env Env) (any, error) {
+	left, err := n.left.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	right, err := n.right.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	switch n.op {
+	case "==":
+		return equal(left, right), nil
+	case "!=":
+		return !equal(left, right), nil
+	case "in":
+		return member(left, right)
+	}
+	return order(n.op, left, right)
+}
+
+type callNode struct {
+	fn   function
+	name string
+	args []node
+}
+
+func (n callNode) eval(env Env) (any, error) {
+	args := make([]any, 0, len(n.args))
+	for _, arg := range n.args {
+		v, err := arg.eval(env)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, v)
+	}
+	v, err := n.fn.call(env, args)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s(): %v", ErrEval, n.name, err)
+	}
+	return v, nil
+}
+
+func equal(a, b any) bool {
+	return reflect.DeepEqual(a, b)
+}
+
+func member(needle, haystack any) (bool, error) {
+	switch h := haystack.(type) {
+	case nil:
+		return false, nil
+	case []any:
+		for _, item := range h {
+			if equal(needle, item) {
+				return true, nil
+			}
+		}
+		return false, nil
+	case string:
+		s, ok := needle.(string)
+		if !ok {
+			return false, fmt.Errorf("%w: in on a string expects a string, got %s", ErrEval, typeName(needle))
+		}
+		return strings.Contains(h, s), nil
+	}
+	return false, fmt.Errorf("%w: in expects a list or string, got %s", ErrEval, typeName(haystack))
+}
+
+func order(op string, a, b any) (bool, error) {
+	var cmp int
+	switch x := a.(type) {
+	case float64:
+		y, ok := b.(float64)
+		if !ok {
+			return false, fmt.Errorf("%w: cannot compare number with %s", ErrEval, typeName(b))
+		}
+		switch {
+		case x < y:
+			cmp = -1
+		case x > y:
+			cmp = 1
+		}
+	case string:
+		y, ok := b.(string)
+		if !ok {
+			return false, fmt.Errorf("%w: cannot compare string with %s", ErrEval, typeName(b))
+		}
+		cmp = strings.Compare(x, y)
+	default:
+		return false, fmt.Errorf("%w: %s does not support %s", ErrEval, op, typeName(a))
+	}
+	switch op {
+	case "<":
+		return cmp < 0, nil
+	case "<=":
+		return cmp <= 0, nil
+	case ">":
+		return cmp > 0, nil
+	}
+	return cmp >= 0, nil
+}
+
+// normalize maps attribute values onto the language's types: null, bool,
+// float64, string and []any.
+func normalize(v any) any {
+	switch x := v.(type) {
+	case nil, bool, float64, string:
+		return x
+	case int:
+		return float64(x)
+	case int64:
+		return float64(x)
+	case uint:
+		return float64(x)
+	case uint64:
+		return float64(x)
+	case []string:
+		out := make([]any, len(x))
+		for i, s := range x {
+			out[i] = s
+		}
+		return out
+	case []uint:
+		out := make([]any, len(x))
+		for i, n := range x {
+			out[i] = float64(n)
+		}
+		return out
+	case []any:
+		out := make([]any, len(x))
+		for i, item := range x {
+			out[i] = normalize(item)
+		}
+		return out
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int8, reflect.Int16, reflect.Int32:
+		return float64(rv.Int())
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return float64(rv.Uint())
+	case reflect.Float32:
+		return rv.Float()
+	case reflect.String:
+		return rv.String()
+	}
+	return fmt.Sprint(v)
+}
+
+func typeName(v any) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "list"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+var comparisonOps = map[string]bool{"==": true, "!=": true, "<": true, "<=": true, ">": true, ">=": true}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token { return p.tokens[p.pos] }
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *parser) expect(kind tokenKind, text string) error {
+	tok := p.next()
+	if tok.kind != kind || tok.text != text {
+		return fmt.Errorf("%w: expected %q at offset %d", ErrSyntax, text, tok.pos)
+	}
+	return nil
+}
+
+func (p *parser) isOp(text string) bool {
+	tok := p.peek()
+	return tok.kind == tokOp && tok.text == text
+}
+
+func (p *parser) parseOr(depth int) (node, error) {
+	left, err := p.parseAnd(depth)
+	if err != nil {
+		return nil, err
+	}
+	for p.isOp("||") {
+		p.next()
+		right, err := p.parseAnd(depth)
+		if err != nil {
+			return nil, err
+		}
+		left = logicalNode{op: "||", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd(depth int) (node, error) {
+	left, err := p.parseUnary(depth)
+	if err != nil {
+		return nil, err
+	}
+	for p.isOp("&&") {
+		p.next()
+		right, err := p.parseUnary(depth)
+		if err != nil {
+			return nil, err
+		}
+		left = logicalNode{op: "&&", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseUnary(depth int) (node, error) {
+	if depth > maxNestingDepth {
+		return nil, fmt.Errorf("%w: condition nests too deeply", ErrSyntax)
+	}
+	if p.isOp("!") {
+		p.next()
+		operand, err := p.parseUnary(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		return notNode{operand: operand}, nil
+	}
+	return p.parseComparison(depth)
+}
+
+func (p *parser) parseComparison(depth int) (node, error) {
+	left, err := p.parsePrimary(depth)
+	if err != nil {
+		return nil, err
+	}
+	tok := p.peek()
+	isCompare := tok.kind == tokOp && comparisonOps[tok.text]
+	if !isCompare && !(tok.kind == tokIdent && tok.text == "in") {
+		return left, nil
+	}
+	p.next()
+	right, err := p.parsePrimary(depth)
+	if err != nil {
+		return nil, err
+	}
+	return compareNode{op: tok.text, left: left, right: right}, nil
+}
+
+func (p *parser) parsePrimary(depth int) (node, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokString:
+		return literalNode{value: tok.text}, nil
+	case tokNumber:
+		n, err := strconv.ParseFloat(tok.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid number %q at offset %d", ErrSyntax, tok.text, tok.pos)
+		}
+		return literalNode{value: n}, nil
+	case tokOp:
+		switch tok.text {
+		case "(":
+			inner, err := p.parseOr(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			return inner, p.expect(tokOp, ")")
+		case "[":
+			return p.parseList(depth + 1)
+		}
+	case tokIdent:
+		switch tok.text {
+		case "true":
+			return literalNode{value: true}, nil
+		case "false":
+			return literalNode{value: false}, nil
+		case "null":
+			return literalNode{value: nil}, nil
+		case "in":
+			return nil, fmt.Errorf("%w: unexpected %q at offset %d", ErrSyntax, tok.text, tok.pos)
+		}
+		if p.isOp("(") {
+			return p.parseCall(tok, depth+1)
+		}
+		return p.parseRef(tok)
+	case tokEOF:
+		return nil, fmt.Errorf("%w: unexpected end of condition", ErrSyntax)
+	}
+	return nil, fmt.Errorf("%w: unexpected %q at offset %d", ErrSyntax, tok.text, tok.pos)
+}
+
+func (p *parser) parseList(depth int) (node, error) {
+	var items []node
+	if p.isOp("]") {
+		p.next()
+		return listNode{}, nil
+	}
+	for {
+		item, err := p.parseOr(depth)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+		if p.isOp(",") {
+			p.next()
+			continue
+		}
+		return listNode{items: items}, p.expect(tokOp, "]")
+	}
+}
+
+func (p *parser) parseCall(name token, depth int) (node, error) {
+	fn, ok := functions[name.text]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown function %q at offset %d", ErrSyntax, name.text, name.pos)
+	}
+	p.next()
+	var args []node
+	if p.isOp(")") {
+		p.next()
+	} else {
+		for {
+			arg, err := p.parseOr(depth)
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+			if p.isOp(",") {
+				p.next()
+				continue
+			}
+			if err := p.expect(tokOp, ")"); err != nil {
+				return nil, err
+			}
+			break
+		}
+	}
+	if len(args) < fn.minArgs || (fn.maxArgs >= 0 && len(args) > fn.maxArgs) {
+		return nil, fmt.Errorf("%w: wrong number of arguments to %s() at offset %d", ErrSyntax, name.text, name.pos)
+	}
+	// Literal arguments are validated up front so that a typo in a CIDR
+	// block or time zone is rejected when the policy is saved.
+	if fn.check != nil {
+		for i, arg := range args {
+			if lit, ok := arg.(literalNode); ok {
+				if err := fn.check(i, lit.value); err != nil {
+					return nil, fmt.Errorf("%w: %s(): %v", ErrSyntax, name.text, err)
+				}
+			}
+		}
+	}
+	return callNode{fn: fn, name: name.text, args: args}, nil
+}
+
+func (p *parser) parseRef(first token) (node, error) {
+	known := false
+	for _, root := range Roots {
+		if first.text == root {
+			known = true
+			break
+		}
+	}
+	if !known {
+		return nil, fmt.Errorf("%w: unknown attribute %q at offset %d", ErrSyntax, first.text, first.pos)
+	}
+	path := []string{first.text}
+	for p.isOp(".") {
+		p.next()
+		seg := p.next()
+		if seg.kind != tokIdent {
+			return nil, fmt.Errorf("%w: expected attribute name at offset %d", ErrSyntax, seg.pos)
+		}
+		path = append(path, seg.text)
+	}
+	if len(path) < 2 {
+		return nil, fmt.Errorf("%w: %q must name an attribute", ErrSyntax, first.text)
+	}
+	return refNode{path: path}, nil
+}
@@ -0,0 +1,142 @@
+package policy
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+type mapEnv struct {
+	attrs   map[string]any
+	now     time.Time
+	lookups []string
+}
+
+func (e *mapEnv) Lookup(path []string) (any, error) {
+	key := strings.Join(path, ".")
+	e.lookups = append(e.lookups, key)
+	if v, ok := e.attrs[key].(error); ok {
+		return nil, v
+	}
+	return e.attrs[key], nil
+}
+
+func (e *mapEnv) Now() time.Time { return e.now }
+
+func TestProgramEval(t *testing.T) {
+	env := &mapEnv{
+		attrs: map[string]any{
+			"subject.id":        uint(7),
+			"subject.roles":     []string{"user", "support"},
+			"subject.org_ids":   []uint{1, 3},
+			"request.ip":        "10.1.2.3",
+			"request.method":    "PATCH",
+			"request.params.id": "42",
+			"resource.org_ids":  []any{uint(3), uint(9)},
+			"resource.status":   "active",
+		},
+		// 2026-03-02 is a Monday; 14:30 UTC is 09:30 in New York.
+		now: time.Date(2026, 3, 2, 14, 30, 0, 0, time.UTC),
+	}
+	cases := []struct {
+		src  string
+		want bool
+	}{
+		{`subject.id == 7`, true},
+		{`subject.id != 7`, false},
+		{`"support" in subject.roles`, true},
+		{`"admin" in subject.roles`, false},
+		{`request.method in ["PUT", "PATCH"]`, true},
+		{`intersects(subject.org_ids, resource.org_ids)`, true},
+		{`intersects(subject.org_ids, resource.missing)`, false},
+		{`cidr(request.ip, "192.168.0.0/16", "10.0.0.0/8")`, true},
+		{`cidr(request.ip, "192.168.0.0/16")`, false},
+		{`cidr(request.missing, "10.0.0.0/8")`, false},
+		{`hour("America/New_York") >= 9 && hour("America/New_York") < 17`, true},
+		{`hour() == 14`, true},
+		{`weekday() >= 1 && weekday() <= 5`, true},
+		{`!(resource.status == "suspended") && resource.status == 'active'`, true},
+		{`resource.missing == null`, true},
+		{`request.params.id == "42" || subject.id == 1`, true},
+		{`startsWith(request.params.id, "4")`, true},
+		{`false || true && false`, false},
+		{`"ctiv" in resource.status`, true},
+		{`[] == []`, true},
+	}
+	for _, tc := range cases {
+		prog, err := Compile(tc.src)
+		if err != nil {
+			t.Fatalf("compile %q: %v", tc.src, err)
+		}
+		got, err := prog.Eval(env)
+		if err != nil {
+			t.Fatalf("eval %q: %v", tc.src, err)
+		}
+		if got != tc.want {
+			t.Fatalf("eval %q = %v, want %v", tc.src, got, tc.want)
+		}
+	}
+}
+
+func TestProgramShortCircuits(t *testing.T) {
+	env := &mapEnv{attrs: map[string]any{"subject.id": uint(1), "resource.owner_id": errors.New("fetch failed")}}
+	prog, err := Compile(`subject.id == 1 || resource.owner_id == subject.id`)
+	if err != nil {
+		t.Fatalf("compile: %v", err)
+	}
+	if ok, err := prog.Eval(env); err != nil || !ok {
+		t.Fatalf("expected short-circuit to skip the resource lookup, got ok=%v err=%v", ok, err)
+	}
+	for _, key := range env.lookups {
+		if strings.HasPrefix(key, "resource.") {
+			t.Fatalf("resource attribute was loaded: %v", env.lookups)
+		}
+	}
+	prog, _ = Compile(`resource.owner_id == subject.id`)
+	if _, err := prog.Eval(env); err == nil || !strings.Contains(err.Error(), "fetch failed") {
+		t.Fatalf("expected lookup error to propagate, got %v", err)
+	}
+}
+
+func TestCompileRejectsInvalidConditions(t *testing.T) {
+	for _, src := range []string{
+		``,
+		`subject.id ==`,
+		`user.id == 1`,
+		`subject == 1`,
+		`subject.id == 1 extra`,
+		`unknown(subject.id)`,
+		`cidr(request.ip)`,
+		`cidr(request.ip, "10.0.0.0/33")`,
+		`hour("Mars/Olympus")`,
+		`"unterminated`,
+		`subject.id = 1`,
+		`(subject.id == 1`,
+		strings.Repeat("!", 64) + `true`,
+		strings.Repeat("x", MaxConditionLength+1),
+	} {
+		if _, err := Compile(src); !errors.Is(err, ErrSyntax) {
+			t.Fatalf("expected %q to be rejected with ErrSyntax, got %v", src, err)
+		}
+	}
+}
+
+func TestProgramEvalTypeErrors(t *testing.T) {
+	env := &mapEnv{attrs: map[string]any{"subject.id": uint(7), "request.ip": "10.0.0.1"}}
+	for _, src := range []string{
+		`subject.id`,
+		`subject.id < "8"`,
+		`subject.id && true`,
+		`!request.ip`,
+		`1 in subject.id`,
+	} {
+		prog, err := Compile(src)
+		if err != nil {
+			t.Fatalf("compile %q: %v", src, err)
+		}
+		if _, err := prog.Eval(env); !errors.Is(err, ErrEval) {
+			t.Fatalf("expected %q to fail evaluation, got %v", src, err)
+		}
+	}
+}
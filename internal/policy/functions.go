@@ -0,0 +1,151 @@
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+// function is a builtin callable from conditions. maxArgs < 0 means
+// variadic. check, when set, validates literal arguments at compile time.
+type function struct {
+	minArgs, maxArgs int
+	check            func(index int, arg any) error
+	call             func(env Env, args []any) (any, error)
+}
+
+// functions available to conditions:
+//
+//	cidr(ip, block...)     true if ip falls within any of the CIDR blocks
+//	hour([tz])             current hour (0-23) in tz, default UTC
+//	weekday([tz])          current weekday (0 = Sunday) in tz, default UTC
+//	intersects(a, b)       true if the lists share an element
+//	startsWith(s, prefix)  string prefix test
+var functions = map[string]function{
+	"cidr": {
+		minArgs: 2, maxArgs: -1,
+		check: func(index int, arg any) error {
+			if index == 0 {
+				return nil
+			}
+			block, ok := arg.(string)
+			if !ok {
+				return errors.New("blocks must be strings")
+			}
+			_, _, err := net.ParseCIDR(block)
+			return err
+		},
+		call: func(_ Env, args []any) (any, error) {
+			raw, _ := args[0].(string)
+			ip := net.ParseIP(raw)
+			if ip == nil {
+				return false, nil
+			}
+			for _, arg := range args[1:] {
+				block, ok := arg.(string)
+				if !ok {
+					return nil, fmt.Errorf("blocks must be strings, got %s", typeName(arg))
+				}
+				_, network, err := net.ParseCIDR(block)
+				if err != nil {
+					return nil, err
+				}
+				if network.Contains(ip) {
+					return true, nil
+				}
+			}
+			return false, nil
+		},
+	},
+	"hour": {
+		minArgs: 0, maxArgs: 1,
+		check: checkLocation,
+		call: func(env Env, args []any) (any, error) {
+			now, err := localNow(env, args)
+			if err != nil {
+				return nil, err
+			}
+			return float64(now.Hour()), nil
+		},
+	},
+	"weekday": {
+		minArgs: 0, maxArgs: 1,
+		check: checkLocation,
+		call: func(env Env, args []any) (any, error) {
+			now, err := localNow(env, args)
+			if err != nil {
+				return nil, err
+			}
+			return float64(now.Weekday()), nil
+		},
+	},
+	"intersects": {
+		minArgs: 2, maxArgs: 2,
+		call: func(_ Env, args []any) (any, error) {
+			a, aok := asList(args[0])
+			b, bok := asList(args[1])
+			if !aok || !bok {
+				return nil, fmt.Errorf("expects lists, got %s and %s", typeName(args[0]), typeName(args[1]))
+			}
+			for _, x := range a {
+				for _, y := range b {
+					if equal(x, y) {
+						return true, nil
+					}
+				}
+			}
+			return false, nil
+		},
+	},
+	"startsWith": {
+		minArgs: 2, maxArgs: 2,
+		call: func(_ Env, args []any) (any, error) {
+			s, sok := args[0].(string)
+			prefix, pok := args[1].(string)
+			if args[0] == nil {
+				return false, nil
+			}
+			if !sok || !pok {
+				return nil, fmt.Errorf("expects strings, got %s and %s", typeName(args[0]), typeName(args[1]))
+			}
+			return strings.HasPrefix(s, prefix), nil
+		},
+	},
+}
+
+func checkLocation(_ int, arg any) error {
+	name, ok := arg.(string)
+	if !ok {
+		return errors.New("time zone must be a string")
+	}
+	_, err := time.LoadLocation(name)
+	return err
+}
+
+func localNow(env Env, args []any) (time.Time, error) {
+	now := env.Now()
+	if len(args) == 0 {
+		return now.UTC(), nil
+	}
+	name, ok := args[0].(string)
+	if !ok {
+		return time.Time{}, fmt.Errorf("time zone must be a string, got %s", typeName(args[0]))
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return now.In(loc), nil
+}
+
+// asList treats null as the empty list so that missing attributes never
+// intersect.
+func asList(v any) ([]any, bool) {
+	if v == nil {
+		return nil, true
+	}
+	list, ok := v.([]any)
+	return list, ok
+}
@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+var ErrAccessPolicyNotFound = errors.New("access policy not found")
+
+type AccessPolicyRepository interface {
+	Create(policy *domain.AccessPolicy) error
+	Update(policy *domain.AccessPolicy) error
+	FindByID(id uint) (*domain.AccessPolicy, error)
+	FindByName(name string) (*domain.AccessPolicy, error)
+	List() ([]domain.AccessPolicy, error)
+	ListEnabled() ([]domain.AccessPolicy, error)
+	DeleteByID(id uint) error
+}
+
+type GormAccessPolicyRepository struct {
+	db *gorm.DB
+}
+
+func NewAccessPolicyRepository(db *gorm.DB) AccessPolicyRepository {
+	return &GormAccessPolicyRepository{db: db}
+}
+
+func (r *GormAccessPolicyRepository) Create(policy *domain.AccessPolicy) error {
+	return r.db.Create(policy).Error
+}
+
+// Update saves every column so that Enabled can be switched off.
+func (r *GormAccessPolicyRepository) Update(policy *domain.AccessPolicy) error {
+	return r.db.Save(policy).Error
+}
+
+func (r *GormAccessPolicyRepository) FindByID(id uint) (*domain.AccessPolicy, error) {
+	return r.findOne(r.db.Where("id = ?", id))
+}
+
+func (r *GormAccessPolicyRepository) FindByName(name string) (*domain.AccessPolicy, error) {
+	return r.findOne(r.db.Where("name = ?", name))
+}
+
+func (r *GormAccessPolicyRepository) findOne(query *gorm.DB) (*domain.AccessPolicy, error) {
+	var policy domain.AccessPolicy
+	if err := query.First(&policy).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAccessPolicyNotFound
+		}
+		return nil, err
+	}
+	return &policy, nil
+}
+
+func (r *GormAccessPolicyRepository) List() ([]domain.AccessPolicy, error) {
+	var policies []domain.AccessPolicy
+	if err := r.db.Order("id ASC").Find(&policies).Error; err != nil {
+		return nil, err
+	}
+	return policies, nil
+}
+
+func (r *GormAccessPolicyRepository) ListEnabled() ([]domain.AccessPolicy, error) {
+	var policies []domain.AccessPolicy
+	if err := r.db.Where("enabled = ?", true).Order("id ASC").Find(&policies).Error; err != nil {
+		return nil, err
+	}
+	return policies, nil
+}
+
+func (r *GormAccessPolicyRepository) DeleteByID(id uint) error {
+	res := r.db.Delete(&domain.AccessPolicy{}, id)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrAccessPolicyNotFound
+	}
+	return nil
+}
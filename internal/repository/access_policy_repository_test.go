@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/domain"
+)
+
+func TestAccessPolicyRepositoryCRUD(t *testing.T) {
+	db := newRepositoryDBForTest(t)
+	repo := NewAccessPolicyRepository(db)
+
+	office := &domain.AccessPolicy{Name: "office-network", Permission: "users:write", Effect: domain.AccessPolicyEffectRequire, Condition: `cidr(request.ip, "10.0.0.0/8")`, Enabled: true}
+	draft := &domain.AccessPolicy{Name: "draft", Permission: "*:*", Effect: domain.AccessPolicyEffectDeny, Condition: `true`}
+	for _, p := range []*domain.AccessPolicy{office, draft} {
+		if err := repo.Create(p); err != nil {
+			t.Fatalf("create policy: %v", err)
+		}
+	}
+	if err := repo.Create(&domain.AccessPolicy{Name: "office-network", Permission: "users:read", Effect: domain.AccessPolicyEffectDeny, Condition: `true`}); err == nil {
+		t.Fatal("expected duplicate policy name to be rejected")
+	}
+
+	enabled, err := repo.ListEnabled()
+	if err != nil || len(enabled) != 1 || enabled[0].Name != "office-network" {
+		t.Fatalf("expected only the enabled policy, got %+v err=%v", enabled, err)
+	}
+
+	office.Enabled = false
+	if err := repo.Update(office); err != nil {
+		t.Fatalf("update policy: %v", err)
+	}
+	if enabled, _ := repo.ListEnabled(); len(enabled) != 0 {
+		t.Fatalf("expected disabling to persist, got %+v", enabled)
+	}
+	found, err := repo.FindByName("office-network")
+	if err != nil || found.ID != office.ID || found.Enabled {
+		t.Fatalf("unexpected lookup result %+v err=%v", found, err)
+	}
+
+	if err := repo.DeleteByID(draft.ID); err != nil {
+		t.Fatalf("delete policy: %v", err)
+	}
+	if err := repo.DeleteByID(draft.ID); !errors.Is(err, ErrAccessPolicyNotFound) {
+		t.Fatalf("expected ErrAccessPolicyNotFound on second delete, got %v", err)
+	}
+	if _, err := repo.FindByID(draft.ID); !errors.Is(err, ErrAccessPolicyNotFound) {
+		t.Fatalf("expected ErrAccessPolicyNotFound, got %v", err)
+	}
+	all, err := repo.List()
+	if err != nil || len(all) != 1 {
+		t.Fatalf("expected one remaining policy, got %+v err=%v", all, err)
+	}
+}
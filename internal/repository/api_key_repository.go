@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+var ErrAPIKeyNotFound = errors.New("api key not found")
+
+type APIKeyRepository interface {
+	Create(key *domain.APIKey) error
+	ListByUserID(userID uint) ([]domain.APIKey, error)
+	CountActiveByUserID(userID uint, now time.Time) (int64, error)
+	FindByHash(hash string) (*domain.APIKey, error)
+	TouchLastUsed(id uint, usedAt time.Time) error
+	RevokeForUser(id, userID uint) (bool, error)
+}
+
+type GormAPIKeyRepository struct {
+	db *gorm.DB
+}
+
+func NewAPIKeyRepository(db *gorm.DB) APIKeyRepository {
+	return &GormAPIKeyRepository{db: db}
+}
+
+func (r *GormAPIKeyRepository) Create(key *domain.APIKey) error {
+	return r.db.Create(key).Error
+}
+
+func (r *GormAPIKeyRepository) ListByUserID(userID uint) ([]domain.APIKey, error) {
+	var keys []domain.APIKey
+	err := r.db.Where("user_id = ?", userID).Order("id ASC").Find(&keys).Error
+	return keys, err
+}
+
+func (r *GormAPIKeyRepository) CountActiveByUserID(userID uint, now time.Time) (int64, error) {
+	var count int64
+	err := r.db.Model(&domain.APIKey{}).
+		Where("user_id = ? AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > ?)", userID, now).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *GormAPIKeyRepository) FindByHash(hash string) (*domain.APIKey, error) {
+	var key domain.APIKey
+	if err := r.db.Where("key_hash = ?", hash).First(&key).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAPIKeyNotFound
+		}
+		return nil, err
+	}
+	return &key, nil
+}
+
+func (r *GormAPIKeyRepository) TouchLastUsed(id uint, usedAt time.Time) error {
+	return r.db.Model(&domain.APIKey{}).
+		Where("id = ?", id).
+		Updates(map[string]any{"last_used_at": usedAt, "updated_at": usedAt}).Error
+}
+
+// RevokeForUser scopes the revoke to the owner; it reports false when the key
+// was already revoked.
+func (r *GormAPIKeyRepository) RevokeForUser(id, userID uint) (bool, error) {
+	var key domain.APIKey
+	if err := r.db.Where("id = ? AND user_id = ?", id, userID).First(&key).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, ErrAPIKeyNotFound
+		}
+		return false, err
+	}
+	now := time.Now().UTC()
+	res := r.db.Model(&domain.APIKey{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", id, userID).
+		Updates(map[string]any{"revoked_at": now, "updated_at": now})
+	return res.RowsAffected > 0, res.Error
+}
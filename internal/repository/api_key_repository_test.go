@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/domain"
+)
+
+func TestAPIKeyRepositoryLifecycle(t *testing.T) {
+	db := newRepositoryDBForTest(t)
+	repo := NewAPIKeyRepository(db)
+	now := time.Now().UTC()
+
+	if _, err := repo.FindByHash("missing"); !errors.Is(err, ErrAPIKeyNotFound) {
+		t.Fatalf("expected ErrAPIKeyNotFound, got %v", err)
+	}
+	key := &domain.APIKey{UserID: 3, Name: "ci", Prefix: "abcd1234", KeyHash: "hash-1", Scopes: "users:read"}
+	if err := repo.Create(key); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	expired := now.Add(-time.Minute)
+	if err := repo.Create(&domain.APIKey{UserID: 3, Name: "old", Prefix: "abcd5678", KeyHash: "hash-2", ExpiresAt: &expired}); err != nil {
+		t.Fatalf("create expired: %v", err)
+	}
+	if err := repo.Create(&domain.APIKey{UserID: 4, Name: "dup", Prefix: "ffff0000", KeyHash: "hash-1"}); err == nil {
+		t.Fatal("expected duplicate key hash to fail")
+	}
+	if count, err := repo.CountActiveByUserID(3, now); err != nil || count != 1 {
+		t.Fatalf("expected one active key, got %d err=%v", count, err)
+	}
+
+	if err := repo.TouchLastUsed(key.ID, now); err != nil {
+		t.Fatalf("touch: %v", err)
+	}
+	found, err := repo.FindByHash("hash-1")
+	if err != nil || found.LastUsedAt == nil || found.Scopes != "users:read" {
+		t.Fatalf("expected key with usage, got %+v err=%v", found, err)
+	}
+
+	if _, err := repo.RevokeForUser(key.ID, 4); !errors.Is(err, ErrAPIKeyNotFound) {
+		t.Fatalf("expected revoke by other user to fail, got %v", err)
+	}
+	if changed, err := repo.RevokeForUser(key.ID, 3); err != nil || !changed {
+		t.Fatalf("expected revoke, got changed=%v err=%v", changed, err)
+	}
+	if changed, err := repo.RevokeForUser(key.ID, 3); err != nil || changed {
+		t.Fatalf("expected second revoke to be a no-op, got changed=%v err=%v", changed, err)
+	}
+	if list, _ := repo.ListByUserID(3); len(list) != 2 || list[0].RevokedAt == nil {
+		t.Fatalf("expected revoked key to remain listed, got %+v", list)
+	}
+}
@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+type JWTSigningKeyRepository interface {
+	Create(key *domain.JWTSigningKey) error
+	List() ([]domain.JWTSigningKey, error)
+	ListUsable(now time.Time) ([]domain.JWTSigningKey, error)
+	Rotate(next *domain.JWTSigningKey, retireAt time.Time) error
+}
+
+type GormJWTSigningKeyRepository struct {
+	db *gorm.DB
+}
+
+func NewJWTSigningKeyRepository(db *gorm.DB) JWTSigningKeyRepository {
+	return &GormJWTSigningKeyRepository{db: db}
+}
+
+func (r *GormJWTSigningKeyRepository) Create(key *domain.JWTSigningKey) error {
+	return r.db.Create(key).Error
+}
+
+func (r *GormJWTSigningKeyRepository) List() ([]domain.JWTSigningKey, error) {
+	var keys []domain.JWTSigningKey
+	err := r.db.Order("activates_at ASC, id ASC").Find(&keys).Error
+	return keys, err
+}
+
+func (r *GormJWTSigningKeyRepository) ListUsable(now time.Time) ([]domain.JWTSigningKey, error) {
+	var keys []domain.JWTSigningKey
+	err := r.db.Where("retires_at IS NULL OR retires_at > ?", now).
+		Order("activates_at ASC, id ASC").
+		Find(&keys).Error
+	return keys, err
+}
+
+// Rotate schedules every existing key to retire no later than retireAt and
+// inserts next in the same transaction, so there is never a window with zero
+// or two open-ended keys.
+func (r *GormJWTSigningKeyRepository) Rotate(next *domain.JWTSigningKey, retireAt time.Time) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&domain.JWTSigningKey{}).
+			Where("retires_at IS NULL OR retires_at > ?", retireAt).
+			Updates(map[string]any{"retires_at": retireAt, "updated_at": time.Now().UTC()}).Error; err != nil {
+			return err
+		}
+		return tx.Create(next).Error
+	})
+}
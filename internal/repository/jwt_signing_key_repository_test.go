@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/domain"
+)
+
+func TestJWTSigningKeyRepositoryRotateRetiresPreviousKeys(t *testing.T) {
+	db := newRepositoryDBForTest(t)
+	repo := NewJWTSigningKeyRepository(db)
+	now := time.Now().UTC()
+
+	if err := repo.Create(&domain.JWTSigningKey{KID: "k1", Algorithm: "ES256", PrivateKeyCiphertext: "c1", ActivatesAt: now.Add(-time.Hour)}); err != nil {
+		t.Fatalf("create k1: %v", err)
+	}
+	earlier := now.Add(5 * time.Minute)
+	if err := repo.Create(&domain.JWTSigningKey{KID: "k0", Algorithm: "ES256", PrivateKeyCiphertext: "c0", ActivatesAt: now.Add(-2 * time.Hour), RetiresAt: &earlier}); err != nil {
+		t.Fatalf("create k0: %v", err)
+	}
+
+	retireAt := now.Add(20 * time.Minute)
+	if err := repo.Rotate(&domain.JWTSigningKey{KID: "k2", Algorithm: "ES256", PrivateKeyCiphertext: "c2", ActivatesAt: now.Add(time.Minute)}, retireAt); err != nil {
+		t.Fatalf("rotate: %v", err)
+	}
+	keys, err := repo.List()
+	if err != nil || len(keys) != 3 {
+		t.Fatalf("expected 3 keys, got %d err=%v", len(keys), err)
+	}
+	byKID := map[string]domain.JWTSigningKey{}
+	for _, k := range keys {
+		byKID[k.KID] = k
+	}
+	if r := byKID["k0"].RetiresAt; r == nil || !r.Equal(earlier) {
+		t.Fatalf("expected earlier retirement to be kept, got %v", r)
+	}
+	if r := byKID["k1"].RetiresAt; r == nil || !r.Equal(retireAt) {
+		t.Fatalf("expected open-ended key to be scheduled for retirement, got %v", r)
+	}
+	if byKID["k2"].RetiresAt != nil {
+		t.Fatalf("expected new key to be open-ended, got %v", byKID["k2"].RetiresAt)
+	}
+
+	usable, err := repo.ListUsable(now.Add(10 * time.Minute))
+	if err != nil || len(usable) != 2 || usable[0].KID != "k1" || usable[1].KID != "k2" {
+		t.Fatalf("expected k1 and k2 to be usable, got %+v err=%v", usable, err)
+	}
+}
@@ -13,7 +13,9 @@ type LocalCredentialRepository interface {
 	Create(credential *domain.LocalCredential) error
 	FindByUserID(userID uint) (*domain.LocalCredential, error)
 	FindByEmail(email string) (*domain.LocalCredential, error)
-	UpdatePassword(userID uint, newHash string) error
+	UpdatePassword(userID uint, newHash string, historyLimit int) error
+	UpgradePasswordHash(userID uint, oldHash, newHash string) (bool, error)
+	ListPasswordHistory(userID uint, limit int) ([]string, error)
 	MarkEmailVerified(userID uint) error
 }
 
@@ -50,9 +52,56 @@ func (r *GormLocalCredentialRepository) FindByEmail(email string) (*domain.Local
 	return &c, nil
 }
 
-func (r *GormLocalCredentialRepository) UpdatePassword(userID uint, newHash string) error {
-	return r.db.Model(&domain.LocalCredential{}).Where("user_id = ?", userID).
-		Updates(map[string]any{"password_hash": newHash, "updated_at": time.Now().UTC()}).Error
+// UpdatePassword replaces the stored hash and, when historyLimit is positive,
+// keeps the replaced hash in the user's password history trimmed to the
+// newest historyLimit entries. A zero limit clears the history.
+func (r *GormLocalCredentialRepository) UpdatePassword(userID uint, newHash string, historyLimit int) error {
+	now := time.Now().UTC()
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var current domain.LocalCredential
+		if err := tx.Where("user_id = ?", userID).First(&current).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&domain.LocalCredential{}).Where("user_id = ?", userID).
+			Updates(map[string]any{"password_hash": newHash, "password_changed_at": now, "updated_at": now}).Error; err != nil {
+			return err
+		}
+		if historyLimit <= 0 {
+			return tx.Where("user_id = ?", userID).Delete(&domain.PasswordHistory{}).Error
+		}
+		if err := tx.Create(&domain.PasswordHistory{UserID: userID, PasswordHash: current.PasswordHash, CreatedAt: now}).Error; err != nil {
+			return err
+		}
+		var keep []uint
+		if err := tx.Model(&domain.PasswordHistory{}).Where("user_id = ?", userID).
+			Order("id DESC").Limit(historyLimit).Pluck("id", &keep).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ? AND id NOT IN ?", userID, keep).Delete(&domain.PasswordHistory{}).Error
+	})
+}
+
+// UpgradePasswordHash swaps in a re-encoding of the same password. It only
+// applies while the stored hash is still oldHash, so a password changed
+// concurrently is never overwritten, and it leaves password_changed_at and
+// the history alone.
+func (r *GormLocalCredentialRepository) UpgradePasswordHash(userID uint, oldHash, newHash string) (bool, error) {
+	res := r.db.Model(&domain.LocalCredential{}).
+		Where("user_id = ? AND password_hash = ?", userID, oldHash).
+		Updates(map[string]any{"password_hash": newHash, "updated_at": time.Now().UTC()})
+	return res.RowsAffected == 1, res.Error
+}
+
+// ListPasswordHistory returns up to limit previous password hashes, newest
+// first.
+func (r *GormLocalCredentialRepository) ListPasswordHistory(userID uint, limit int) ([]string, error) {
+	if limit <= 0 {
+		return nil, nil
+	}
+	var hashes []string
+	err := r.db.Model(&domain.PasswordHistory{}).Where("user_id = ?", userID).
+		Order("id DESC").Limit(limit).Pluck("password_hash", &hashes).Error
+	return hashes, err
 }
 
 func (r *GormLocalCredentialRepository) MarkEmailVerified(userID uint) error {
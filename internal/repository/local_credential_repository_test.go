@@ -29,15 +29,15 @@ func TestLocalCredentialRepositoryFindByEmailJoinAndUpdates(t *testing.T) {
 		t.Fatalf("unexpected credential: %+v", got)
 	}
 
-	if err := repo.UpdatePassword(user.ID, "hash-2"); err != nil {
+	if err := repo.UpdatePassword(user.ID, "hash-2", 2); err != nil {
 		t.Fatalf("update password: %v", err)
 	}
 	updated, err := repo.FindByUserID(user.ID)
 	if err != nil {
 		t.Fatalf("find by user id: %v", err)
 	}
-	if updated.PasswordHash != "hash-2" {
-		t.Fatalf("expected updated hash, got %q", updated.PasswordHash)
+	if updated.PasswordHash != "hash-2" || updated.PasswordChangedAt == nil {
+		t.Fatalf("expected updated hash and change time, got %+v", updated)
 	}
 
 	before := time.Now().UTC().Add(-time.Second)
@@ -60,3 +60,70 @@ func TestLocalCredentialRepositoryFindByEmailJoinAndUpdates(t *testing.T) {
 		t.Fatalf("expected record not found error, got %v", err)
 	}
 }
+
+func TestLocalCredentialRepositoryPasswordHistoryIsTrimmed(t *testing.T) {
+	db := newRepositoryDBForTest(t)
+	repo := NewLocalCredentialRepository(db)
+
+	user := &domain.User{Email: "history@example.com", Name: "History", Status: "active"}
+	if err := db.Create(user).Error; err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	if err := repo.Create(&domain.LocalCredential{UserID: user.ID, PasswordHash: "hash-1"}); err != nil {
+		t.Fatalf("create credential: %v", err)
+	}
+	for _, hash := range []string{"hash-2", "hash-3", "hash-4"} {
+		if err := repo.UpdatePassword(user.ID, hash, 2); err != nil {
+			t.Fatalf("update password to %s: %v", hash, err)
+		}
+	}
+
+	history, err := repo.ListPasswordHistory(user.ID, 10)
+	if err != nil {
+		t.Fatalf("list history: %v", err)
+	}
+	if len(history) != 2 || history[0] != "hash-3" || history[1] != "hash-2" {
+		t.Fatalf("expected the two most recent replaced hashes, got %v", history)
+	}
+
+	if err := repo.UpdatePassword(user.ID, "hash-5", 0); err != nil {
+		t.Fatalf("update without history: %v", err)
+	}
+	var remaining int64
+	if err := db.Model(&domain.PasswordHistory{}).Where("user_id = ?", user.ID).Count(&remaining).Error; err != nil {
+		t.Fatalf("count history: %v", err)
+	}
+	if remaining != 0 {
+		t.Fatalf("expected history to be cleared when disabled, got %d rows", remaining)
+	}
+}
+
+func TestLocalCredentialRepositoryUpgradePasswordHashIsConditional(t *testing.T) {
+	db := newRepositoryDBForTest(t)
+	repo := NewLocalCredentialRepository(db)
+
+	user := &domain.User{Email: "upgrade@example.com", Name: "Upgrade", Status: "active"}
+	if err := db.Create(user).Error; err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	if err := repo.Create(&domain.LocalCredential{UserID: user.ID, PasswordHash: "$2a$10$legacy"}); err != nil {
+		t.Fatalf("create credential: %v", err)
+	}
+
+	if ok, err := repo.UpgradePasswordHash(user.ID, "$2a$10$stale", "$argon2id$new"); err != nil || ok {
+		t.Fatalf("expected a stale hash to leave the row alone, got ok=%v err=%v", ok, err)
+	}
+	if ok, err := repo.UpgradePasswordHash(user.ID, "$2a$10$legacy", "$argon2id$new"); err != nil || !ok {
+		t.Fatalf("expected upgrade to apply, got ok=%v err=%v", ok, err)
+	}
+	cred, err := repo.FindByUserID(user.ID)
+	if err != nil {
+		t.Fatalf("find credential: %v", err)
+	}
+	if cred.PasswordHash != "$argon2id$new" || cred.PasswordChangedAt != nil {
+		t.Fatalf("expected only the hash to change, got %+v", cred)
+	}
+	if history, _ := repo.ListPasswordHistory(user.ID, 5); len(history) != 0 {
+		t.Fatalf("expected an upgrade to skip password history, got %v", history)
+	}
+}
@@ -0,0 +1,30 @@
+package repository
+
+import (
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+type LoginEventRepository interface {
+	Create(event *domain.LoginEvent) error
+	ListByUserID(userID uint) ([]domain.LoginEvent, error)
+}
+
+type GormLoginEventRepository struct {
+	db *gorm.DB
+}
+
+func NewLoginEventRepository(db *gorm.DB) LoginEventRepository {
+	return &GormLoginEventRepository{db: db}
+}
+
+func (r *GormLoginEventRepository) Create(event *domain.LoginEvent) error {
+	return r.db.Create(event).Error
+}
+
+func (r *GormLoginEventRepository) ListByUserID(userID uint) ([]domain.LoginEvent, error) {
+	var events []domain.LoginEvent
+	err := r.db.Where("user_id = ?", userID).Order("created_at ASC, id ASC").Find(&events).Error
+	return events, err
+}
@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/domain"
+)
+
+func TestLoginEventRepositoryListsByUser(t *testing.T) {
+	db := newRepositoryDBForTest(t)
+	repo := NewLoginEventRepository(db)
+
+	for _, event := range []*domain.LoginEvent{
+		{UserID: 5, Event: "login", Method: "password", Outcome: "failure", Reason: "invalid_credentials", IP: "198.51.100.7"},
+		{UserID: 6, Event: "login", Method: "password", Outcome: "success"},
+		{UserID: 5, Event: "login", Method: "password", Outcome: "success", IP: "198.51.100.7"},
+	} {
+		if err := repo.Create(event); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+	}
+
+	events, err := repo.ListByUserID(5)
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("expected two events for user 5, got %+v", events)
+	}
+	if events[0].Outcome != "failure" || events[0].Reason != "invalid_credentials" || events[1].Outcome != "success" {
+		t.Fatalf("expected events in insertion order, got %+v", events)
+	}
+	if events[0].CreatedAt.IsZero() {
+		t.Fatal("expected created_at to be set")
+	}
+}
@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrMFACredentialNotFound   = errors.New("mfa credential not found")
+	ErrMFATOTPStepReused       = errors.New("mfa totp step already used")
+	ErrMFARecoveryCodeNotFound = errors.New("mfa recovery code not found")
+)
+
+type MFARepository interface {
+	FindTOTPByUserID(userID uint) (*domain.MFATOTPCredential, error)
+	SavePendingTOTP(userID uint, secretCiphertext string) error
+	ConfirmTOTP(userID uint, step int64, recoveryCodeHashes []string, now time.Time) error
+	MarkTOTPStepUsed(userID uint, step int64) error
+	ConsumeRecoveryCode(userID uint, codeHash string, now time.Time) error
+	CountActiveRecoveryCodes(userID uint) (int64, error)
+}
+
+type GormMFARepository struct {
+	db *gorm.DB
+}
+
+func NewMFARepository(db *gorm.DB) MFARepository {
+	return &GormMFARepository{db: db}
+}
+
+func (r *GormMFARepository) FindTOTPByUserID(userID uint) (*domain.MFATOTPCredential, error) {
+	var c domain.MFATOTPCredential
+	if err := r.db.Where("user_id = ?", userID).First(&c).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrMFACredentialNotFound
+		}
+		return nil, err
+	}
+	return &c, nil
+}
+
+// SavePendingTOTP replaces any unconfirmed enrollment; a confirmed credential is
+// left untouched and reported as not found so callers cannot overwrite it.
+func (r *GormMFARepository) SavePendingTOTP(userID uint, secretCiphertext string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var existing domain.MFATOTPCredential
+		err := tx.Where("user_id = ?", userID).First(&existing).Error
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return tx.Create(&domain.MFATOTPCredential{UserID: userID, SecretCiphertext: secretCiphertext}).Error
+		case err != nil:
+			return err
+		}
+		res := tx.Model(&domain.MFATOTPCredential{}).
+			Where("id = ? AND confirmed_at IS NULL", existing.ID).
+			Updates(map[string]any{"secret_ciphertext": secretCiphertext, "last_used_step": 0, "updated_at": time.Now().UTC()})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrMFACredentialNotFound
+		}
+		return nil
+	})
+}
+
+func (r *GormMFARepository) ConfirmTOTP(userID uint, step int64, recoveryCodeHashes []string, now time.Time) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&domain.MFATOTPCredential{}).
+			Where("user_id = ? AND confirmed_at IS NULL", userID).
+			Updates(map[string]any{"confirmed_at": now, "last_used_step": step, "updated_at": now})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrMFACredentialNotFound
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&domain.MFARecoveryCode{}).Error; err != nil {
+			return err
+		}
+		codes := make([]domain.MFARecoveryCode, 0, len(recoveryCodeHashes))
+		for _, hash := range recoveryCodeHashes {
+			codes = append(codes, domain.MFARecoveryCode{UserID: userID, CodeHash: hash})
+		}
+		if len(codes) == 0 {
+			return nil
+		}
+		return tx.Create(&codes).Error
+	})
+}
+
+// MarkTOTPStepUsed advances the last accepted step; a code from the same or an
+// earlier step is rejected so each TOTP code can be used only once.
+func (r *GormMFARepository) MarkTOTPStepUsed(userID uint, step int64) error {
+	res := r.db.Model(&domain.MFATOTPCredential{}).
+		Where("user_id = ? AND confirmed_at IS NOT NULL AND last_used_step < ?", userID, step).
+		Updates(map[string]any{"last_used_step": step, "updated_at": time.Now().UTC()})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrMFATOTPStepReused
+	}
+	return nil
+}
+
+func (r *GormMFARepository) ConsumeRecoveryCode(userID uint, codeHash string, now time.Time) error {
+	res := r.db.Model(&domain.MFARecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Updates(map[string]any{"used_at": now, "updated_at": now})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrMFARecoveryCodeNotFound
+	}
+	return nil
+}
+
+func (r *GormMFARepository) CountActiveRecoveryCodes(userID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&domain.MFARecoveryCode{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Count(&count).Error
+	return count, err
+}
@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestMFARepositoryEnrollmentLifecycle(t *testing.T) {
+	db := newRepositoryDBForTest(t)
+	repo := NewMFARepository(db)
+	now := time.Now().UTC()
+
+	if _, err := repo.FindTOTPByUserID(7); !errors.Is(err, ErrMFACredentialNotFound) {
+		t.Fatalf("expected ErrMFACredentialNotFound, got %v", err)
+	}
+	if err := repo.SavePendingTOTP(7, "cipher-1"); err != nil {
+		t.Fatalf("save pending: %v", err)
+	}
+	if err := repo.SavePendingTOTP(7, "cipher-2"); err != nil {
+		t.Fatalf("replace pending: %v", err)
+	}
+	cred, err := repo.FindTOTPByUserID(7)
+	if err != nil || cred.SecretCiphertext != "cipher-2" || cred.ConfirmedAt != nil {
+		t.Fatalf("expected replaced pending credential, got %+v err=%v", cred, err)
+	}
+
+	if err := repo.ConfirmTOTP(7, 100, []string{"h1", "h2"}, now); err != nil {
+		t.Fatalf("confirm: %v", err)
+	}
+	if err := repo.ConfirmTOTP(7, 101, []string{"h3"}, now); !errors.Is(err, ErrMFACredentialNotFound) {
+		t.Fatalf("expected second confirm to fail, got %v", err)
+	}
+	if err := repo.SavePendingTOTP(7, "cipher-3"); !errors.Is(err, ErrMFACredentialNotFound) {
+		t.Fatalf("expected confirmed credential to be immutable, got %v", err)
+	}
+	if n, err := repo.CountActiveRecoveryCodes(7); err != nil || n != 2 {
+		t.Fatalf("expected 2 recovery codes, got %d err=%v", n, err)
+	}
+}
+
+func TestMFARepositoryStepReplayAndRecoveryCodeConsumption(t *testing.T) {
+	db := newRepositoryDBForTest(t)
+	repo := NewMFARepository(db)
+	now := time.Now().UTC()
+
+	if err := repo.SavePendingTOTP(9, "cipher"); err != nil {
+		t.Fatalf("save pending: %v", err)
+	}
+	if err := repo.ConfirmTOTP(9, 100, []string{"rc-1"}, now); err != nil {
+		t.Fatalf("confirm: %v", err)
+	}
+
+	if err := repo.MarkTOTPStepUsed(9, 100); !errors.Is(err, ErrMFATOTPStepReused) {
+		t.Fatalf("expected confirmation step to be burned, got %v", err)
+	}
+	if err := repo.MarkTOTPStepUsed(9, 101); err != nil {
+		t.Fatalf("mark next step: %v", err)
+	}
+	if err := repo.MarkTOTPStepUsed(9, 101); !errors.Is(err, ErrMFATOTPStepReused) {
+		t.Fatalf("expected replayed step to fail, got %v", err)
+	}
+
+	if err := repo.ConsumeRecoveryCode(9, "rc-1", now); err != nil {
+		t.Fatalf("consume recovery code: %v", err)
+	}
+	if err := repo.ConsumeRecoveryCode(9, "rc-1", now); !errors.Is(err, ErrMFARecoveryCodeNotFound) {
+		t.Fatalf("expected recovery code to be single-use, got %v", err)
+	}
+	if err := repo.ConsumeRecoveryCode(10, "rc-1", now); !errors.Is(err, ErrMFARecoveryCodeNotFound) {
+		t.Fatalf("expected other user's recovery code lookup to fail, got %v", err)
+	}
+}
@@ -1,14 +1,20 @@
 package repository
 
 import (
+	"errors"
+
 	"github.com/sandeepkv93/everything-backend-starter-kit/internal/domain"
 
 	"gorm.io/gorm"
 )
 
+var ErrOAuthAccountNotFound = errors.New("oauth account not found")
+
 type OAuthRepository interface {
 	FindByProvider(provider, providerUserID string) (*domain.OAuthAccount, error)
 	Create(account *domain.OAuthAccount) error
+	ListByUserID(userID uint) ([]domain.OAuthAccount, error)
+	DeleteForUser(id, userID uint) error
 }
 
 type GormOAuthRepository struct{ db *gorm.DB }
@@ -27,3 +33,20 @@ func (r *GormOAuthRepository) FindByProvider(provider, providerUserID string) (*
 func (r *GormOAuthRepository) Create(account *domain.OAuthAccount) error {
 	return r.db.Create(account).Error
 }
+
+func (r *GormOAuthRepository) ListByUserID(userID uint) ([]domain.OAuthAccount, error) {
+	var accounts []domain.OAuthAccount
+	err := r.db.Where("user_id = ?", userID).Order("id ASC").Find(&accounts).Error
+	return accounts, err
+}
+
+func (r *GormOAuthRepository) DeleteForUser(id, userID uint) error {
+	res := r.db.Where("id = ? AND user_id = ?", id, userID).Delete(&domain.OAuthAccount{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrOAuthAccountNotFound
+	}
+	return nil
+}
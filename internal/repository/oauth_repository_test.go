@@ -35,3 +35,31 @@ func TestOAuthRepositoryCreateFindAndUniqueness(t *testing.T) {
 		t.Fatalf("expected gorm.ErrRecordNotFound, got %v", err)
 	}
 }
+
+func TestOAuthRepositoryListAndDeleteForUser(t *testing.T) {
+	db := newRepositoryDBForTest(t)
+	repo := NewOAuthRepository(db)
+
+	google := &domain.OAuthAccount{UserID: 1, Provider: "google", ProviderUserID: "g-1", EmailVerified: true}
+	github := &domain.OAuthAccount{UserID: 1, Provider: "github", ProviderUserID: "gh-1", EmailVerified: true}
+	other := &domain.OAuthAccount{UserID: 2, Provider: "google", ProviderUserID: "g-2", EmailVerified: true}
+	for _, account := range []*domain.OAuthAccount{google, github, other} {
+		if err := repo.Create(account); err != nil {
+			t.Fatalf("create account: %v", err)
+		}
+	}
+
+	accounts, err := repo.ListByUserID(1)
+	if err != nil || len(accounts) != 2 || accounts[0].Provider != "google" {
+		t.Fatalf("expected two accounts for user 1, got %+v err=%v", accounts, err)
+	}
+	if err := repo.DeleteForUser(other.ID, 1); !errors.Is(err, ErrOAuthAccountNotFound) {
+		t.Fatalf("expected delete of another user's identity to fail, got %v", err)
+	}
+	if err := repo.DeleteForUser(google.ID, 1); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if accounts, _ := repo.ListByUserID(1); len(accounts) != 1 || accounts[0].Provider != "github" {
+		t.Fatalf("expected only github identity to remain, got %+v", accounts)
+	}
+}
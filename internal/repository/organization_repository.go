@@ -0,0 +1,213 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/domain"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrOrganizationNotFound       = errors.New("organization not found")
+	ErrOrganizationMemberNotFound = errors.New("organization member not found")
+)
+
+type OrganizationRepository interface {
+	Create(org *domain.Organization) error
+	FindByID(id uint) (*domain.Organization, error)
+	FindBySlug(slug string) (*domain.Organization, error)
+	List() ([]domain.Organization, error)
+	ListForUser(userID uint) ([]domain.Organization, error)
+	IsMember(orgID, userID uint) (bool, error)
+	FindMember(orgID, userID uint) (*domain.OrganizationMember, error)
+	ListMembers(orgID uint) ([]domain.OrganizationMember, error)
+	SetMember(orgID, userID uint, roleIDs []uint) error
+	RemoveMember(orgID, userID uint) error
+	MemberRoles(orgID, userID uint) ([]domain.Role, error)
+}
+
+type GormOrganizationRepository struct {
+	db *gorm.DB
+}
+
+func NewOrganizationRepository(db *gorm.DB) OrganizationRepository {
+	return &GormOrganizationRepository{db: db}
+}
+
+func (r *GormOrganizationRepository) Create(org *domain.Organization) error {
+	return r.db.Create(org).Error
+}
+
+func (r *GormOrganizationRepository) FindByID(id uint) (*domain.Organization, error) {
+	return r.findOne(r.db.Where("id = ?", id))
+}
+
+func (r *GormOrganizationRepository) FindBySlug(slug string) (*domain.Organization, error) {
+	return r.findOne(r.db.Where("slug = ?", slug))
+}
+
+func (r *GormOrganizationRepository) findOne(query *gorm.DB) (*domain.Organization, error) {
+	var org domain.Organization
+	if err := query.First(&org).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrOrganizationNotFound
+		}
+		return nil, err
+	}
+	return &org, nil
+}
+
+func (r *GormOrganizationRepository) List() ([]domain.Organization, error) {
+	var orgs []domain.Organization
+	if err := r.db.Order("id ASC").Find(&orgs).Error; err != nil {
+		return nil, err
+	}
+	return orgs, nil
+}
+
+func (r *GormOrganizationRepository) ListForUser(userID uint) ([]domain.Organization, error) {
+	var orgs []domain.Organization
+	err := r.db.
+		Joins("JOIN organization_members ON organization_members.organization_id = organizations.id").
+		Where("organization_members.user_id = ?", userID).
+		Order("organizations.id ASC").
+		Find(&orgs).Error
+	if err != nil {
+		return nil, err
+	}
+	return orgs, nil
+}
+
+func (r *GormOrganizationRepository) IsMember(orgID, userID uint) (bool, error) {
+	var count int64
+	if err := r.db.Model(&domain.OrganizationMember{}).Where("organization_id = ? AND user_id = ?", orgID, userID).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *GormOrganizationRepository) FindMember(orgID, userID uint) (*domain.OrganizationMember, error) {
+	var member domain.OrganizationMember
+	if err := r.db.Where("organization_id = ? AND user_id = ?", orgID, userID).First(&member).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrOrganizationMemberNotFound
+		}
+		return nil, err
+	}
+	roles, err := r.MemberRoles(orgID, userID)
+	if err != nil {
+		return nil, err
+	}
+	member.Roles = roles
+	return &member, nil
+}
+
+func (r *GormOrganizationRepository) ListMembers(orgID uint) ([]domain.OrganizationMember, error) {
+	var members []domain.OrganizationMember
+	if err := r.db.Where("organization_id = ?", orgID).Order("user_id ASC").Find(&members).Error; err != nil {
+		return nil, err
+	}
+	if len(members) == 0 {
+		return members, nil
+	}
+	var bindings []domain.OrganizationRoleBinding
+	if err := r.db.Where("organization_id = ?", orgID).Find(&bindings).Error; err != nil {
+		return nil, err
+	}
+	roleIDs := make([]uint, 0, len(bindings))
+	for _, b := range bindings {
+		roleIDs = append(roleIDs, b.RoleID)
+	}
+	var roles []domain.Role
+	if len(roleIDs) > 0 {
+		if err := r.db.Preload("Permissions").Where("id IN ?", roleIDs).Order("id ASC").Find(&roles).Error; err != nil {
+			return nil, err
+		}
+		if err := attachInheritedPermissions(r.db, roles); err != nil {
+			return nil, err
+		}
+	}
+	rolesByID := make(map[uint]domain.Role, len(roles))
+	for _, role := range roles {
+		rolesByID[role.ID] = role
+	}
+	rolesByUser := make(map[uint][]domain.Role, len(members))
+	for _, b := range bindings {
+		if role, ok := rolesByID[b.RoleID]; ok {
+			rolesByUser[b.UserID] = append(rolesByUser[b.UserID], role)
+		}
+	}
+	for i := range members {
+		members[i].Roles = rolesByUser[members[i].UserID]
+	}
+	return members, nil
+}
+
+// SetMember adds userID to the organization if needed and replaces the
+// member's organization role bindings with roleIDs.
+func (r *GormOrganizationRepository) SetMember(orgID, userID uint, roleIDs []uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var org domain.Organization
+		if err := tx.First(&org, orgID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrOrganizationNotFound
+			}
+			return err
+		}
+		roles, err := findRolesByIDs(tx, roleIDs)
+		if err != nil {
+			return err
+		}
+		member := domain.OrganizationMember{OrganizationID: orgID, UserID: userID}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&member).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("organization_id = ? AND user_id = ?", orgID, userID).Delete(&domain.OrganizationRoleBinding{}).Error; err != nil {
+			return err
+		}
+		if len(roles) == 0 {
+			return nil
+		}
+		bindings := make([]domain.OrganizationRoleBinding, 0, len(roles))
+		for _, role := range roles {
+			bindings = append(bindings, domain.OrganizationRoleBinding{OrganizationID: orgID, UserID: userID, RoleID: role.ID})
+		}
+		return tx.Create(&bindings).Error
+	})
+}
+
+func (r *GormOrganizationRepository) RemoveMember(orgID, userID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("organization_id = ? AND user_id = ?", orgID, userID).Delete(&domain.OrganizationRoleBinding{}).Error; err != nil {
+			return err
+		}
+		res := tx.Where("organization_id = ? AND user_id = ?", orgID, userID).Delete(&domain.OrganizationMember{})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrOrganizationMemberNotFound
+		}
+		return nil
+	})
+}
+
+// MemberRoles returns the roles bound to userID in the organization, with
+// permissions and inherited permissions loaded.
+func (r *GormOrganizationRepository) MemberRoles(orgID, userID uint) ([]domain.Role, error) {
+	roles := []domain.Role{}
+	err := r.db.Preload("Permissions").
+		Joins("JOIN organization_role_bindings ON organization_role_bindings.role_id = roles.id").
+		Where("organization_role_bindings.organization_id = ? AND organization_role_bindings.user_id = ?", orgID, userID).
+		Order("roles.id ASC").
+		Find(&roles).Error
+	if err != nil {
+		return nil, err
+	}
+	if err := attachInheritedPermissions(r.db, roles); err != nil {
+		return nil, err
+	}
+	return roles, nil
+}
@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/domain"
+)
+
+func TestOrganizationRepositoryMembershipAndScopedRoles(t *testing.T) {
+	db := newRepositoryDBForTest(t)
+	repo := NewOrganizationRepository(db)
+	roleRepo := NewRoleRepository(db)
+	permRepo := NewPermissionRepository(db)
+	userRepo := NewUserRepository(db)
+
+	read := &domain.Permission{Resource: "members", Action: "read"}
+	write := &domain.Permission{Resource: "members", Action: "write"}
+	for _, p := range []*domain.Permission{read, write} {
+		if err := permRepo.Create(p); err != nil {
+			t.Fatalf("create permission: %v", err)
+		}
+	}
+	viewer := &domain.Role{Name: "org-viewer"}
+	if err := roleRepo.Create(viewer, []uint{read.ID}, nil); err != nil {
+		t.Fatalf("create viewer: %v", err)
+	}
+	owner := &domain.Role{Name: "org-owner"}
+	if err := roleRepo.Create(owner, []uint{write.ID}, []uint{viewer.ID}); err != nil {
+		t.Fatalf("create owner: %v", err)
+	}
+	user := &domain.User{Email: "member@example.com", Name: "Member", Status: domain.UserStatusActive}
+	if err := userRepo.Create(user); err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+
+	acme := &domain.Organization{Slug: "acme", Name: "Acme"}
+	globex := &domain.Organization{Slug: "globex", Name: "Globex"}
+	for _, org := range []*domain.Organization{acme, globex} {
+		if err := repo.Create(org); err != nil {
+			t.Fatalf("create organization: %v", err)
+		}
+	}
+	if _, err := repo.FindBySlug("missing"); !errors.Is(err, ErrOrganizationNotFound) {
+		t.Fatalf("expected ErrOrganizationNotFound, got %v", err)
+	}
+
+	if err := repo.SetMember(acme.ID, user.ID, []uint{999}); !errors.Is(err, ErrRoleNotFound) {
+		t.Fatalf("expected unknown role to be rejected, got %v", err)
+	}
+	if member, _ := repo.IsMember(acme.ID, user.ID); member {
+		t.Fatal("expected a rejected SetMember to roll back the membership")
+	}
+	if err := repo.SetMember(999, user.ID, nil); !errors.Is(err, ErrOrganizationNotFound) {
+		t.Fatalf("expected unknown organization to be rejected, got %v", err)
+	}
+
+	if err := repo.SetMember(acme.ID, user.ID, []uint{owner.ID}); err != nil {
+		t.Fatalf("set member: %v", err)
+	}
+	if err := repo.SetMember(globex.ID, user.ID, nil); err != nil {
+		t.Fatalf("set member without roles: %v", err)
+	}
+	roles, err := repo.MemberRoles(acme.ID, user.ID)
+	if err != nil {
+		t.Fatalf("member roles: %v", err)
+	}
+	if len(roles) != 1 || roles[0].ID != owner.ID || len(roles[0].InheritedPermissions) != 1 || roles[0].InheritedPermissions[0].ID != read.ID {
+		t.Fatalf("expected owner with inherited members:read, got %+v", roles)
+	}
+	if roles, err := repo.MemberRoles(globex.ID, user.ID); err != nil || len(roles) != 0 {
+		t.Fatalf("expected acme bindings not to apply in globex, got %+v err=%v", roles, err)
+	}
+	orgs, err := repo.ListForUser(user.ID)
+	if err != nil || len(orgs) != 2 {
+		t.Fatalf("expected two organizations for user, got %+v err=%v", orgs, err)
+	}
+
+	if err := repo.SetMember(acme.ID, user.ID, []uint{viewer.ID}); err != nil {
+		t.Fatalf("replace member roles: %v", err)
+	}
+	members, err := repo.ListMembers(acme.ID)
+	if err != nil || len(members) != 1 || len(members[0].Roles) != 1 || members[0].Roles[0].ID != viewer.ID {
+		t.Fatalf("expected bindings to be replaced, got %+v err=%v", members, err)
+	}
+
+	if err := roleRepo.DeleteByID(viewer.ID); !errors.Is(err, ErrRoleHasChildren) {
+		t.Fatalf("expected viewer to be protected by its child role, got %v", err)
+	}
+	if err := roleRepo.DeleteByID(owner.ID); err != nil {
+		t.Fatalf("delete owner: %v", err)
+	}
+
+	if err := repo.RemoveMember(acme.ID, user.ID); err != nil {
+		t.Fatalf("remove member: %v", err)
+	}
+	if err := repo.RemoveMember(acme.ID, user.ID); !errors.Is(err, ErrOrganizationMemberNotFound) {
+		t.Fatalf("expected second removal to miss, got %v", err)
+	}
+	var bindings int64
+	db.Model(&domain.OrganizationRoleBinding{}).Where("organization_id = ?", acme.ID).Count(&bindings)
+	if bindings != 0 {
+		t.Fatalf("expected removal to drop bindings, got %d", bindings)
+	}
+
+	if err := userRepo.Erase(user.ID); err != nil {
+		t.Fatalf("erase user: %v", err)
+	}
+	if member, _ := repo.IsMember(globex.ID, user.ID); member {
+		t.Fatal("expected erase to remove remaining memberships")
+	}
+}
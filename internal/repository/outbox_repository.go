@@ -0,0 +1,200 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/domain"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrOutboxMessageNotFound = errors.New("outbox message not found")
+	ErrOutboxLeaseLost       = errors.New("outbox message is no longer claimed by this worker")
+	ErrOutboxNotReplayable   = errors.New("outbox message cannot be replayed")
+)
+
+type OutboxRepository interface {
+	ClaimDue(now time.Time, limit int, lease time.Duration, worker string) ([]domain.OutboxMessage, error)
+	MarkSent(id uint, worker string, now time.Time) error
+	MarkRetry(id uint, worker, lastErr string, nextAttemptAt, now time.Time) error
+	MarkDead(id uint, worker, lastErr string, clearPayload bool, now time.Time) error
+	FindByID(id uint) (*domain.OutboxMessage, error)
+	List(status string, limit int) ([]domain.OutboxMessage, error)
+	Replay(id uint, now time.Time) error
+	DeleteSentBefore(cutoff time.Time, limit int) (int64, error)
+}
+
+type GormOutboxRepository struct {
+	db *gorm.DB
+}
+
+func NewOutboxRepository(db *gorm.DB) OutboxRepository {
+	return &GormOutboxRepository{db: db}
+}
+
+const outboxClaimableWhere = "(status = ? AND next_attempt_at <= ?) OR (status = ? AND locked_until < ?)"
+
+// ClaimDue leases up to limit due messages to worker. Each row is claimed with
+// its own conditional update, so replicas polling the same table never
+// deliver a message twice while its lease holds; a worker that dies mid-send
+// leaves the row to be reclaimed once the lease expires. The attempt is
+// counted at claim time so such crashes still move towards dead-lettering.
+func (r *GormOutboxRepository) ClaimDue(now time.Time, limit int, lease time.Duration, worker string) ([]domain.OutboxMessage, error) {
+	var candidates []uint
+	if err := r.db.Model(&domain.OutboxMessage{}).
+		Where(outboxClaimableWhere, domain.OutboxStatusPending, now, domain.OutboxStatusSending, now).
+		Order("next_attempt_at ASC, id ASC").
+		Limit(limit).
+		Pluck("id", &candidates).Error; err != nil {
+		return nil, err
+	}
+
+	claimed := make([]uint, 0, len(candidates))
+	lockedUntil := now.Add(lease)
+	for _, id := range candidates {
+		res := r.db.Model(&domain.OutboxMessage{}).
+			Where("id = ?", id).
+			Where(outboxClaimableWhere, domain.OutboxStatusPending, now, domain.OutboxStatusSending, now).
+			Updates(map[string]any{
+				"status":       domain.OutboxStatusSending,
+				"locked_by":    worker,
+				"locked_until": lockedUntil,
+				"attempts":     gorm.Expr("attempts + 1"),
+				"updated_at":   now,
+			})
+		if res.Error != nil {
+			return nil, res.Error
+		}
+		if res.RowsAffected == 1 {
+			claimed = append(claimed, id)
+		}
+	}
+	if len(claimed) == 0 {
+		return nil, nil
+	}
+
+	var messages []domain.OutboxMessage
+	err := r.db.Where("id IN ? AND status = ? AND locked_by = ?", claimed, domain.OutboxStatusSending, worker).
+		Order("next_attempt_at ASC, id ASC").
+		Find(&messages).Error
+	return messages, err
+}
+
+func (r *GormOutboxRepository) MarkSent(id uint, worker string, now time.Time) error {
+	return r.release(id, worker, map[string]any{
+		"status":     domain.OutboxStatusSent,
+		"payload":    "",
+		"last_error": "",
+		"sent_at":    now,
+		"updated_at": now,
+	})
+}
+
+func (r *GormOutboxRepository) MarkRetry(id uint, worker, lastErr string, nextAttemptAt, now time.Time) error {
+	return r.release(id, worker, map[string]any{
+		"status":          domain.OutboxStatusPending,
+		"next_attempt_at": nextAttemptAt,
+		"last_error":      truncateOutboxError(lastErr),
+		"updated_at":      now,
+	})
+}
+
+func (r *GormOutboxRepository) MarkDead(id uint, worker, lastErr string, clearPayload bool, now time.Time) error {
+	updates := map[string]any{
+		"status":     domain.OutboxStatusDead,
+		"last_error": truncateOutboxError(lastErr),
+		"updated_at": now,
+	}
+	if clearPayload {
+		updates["payload"] = ""
+	}
+	return r.release(id, worker, updates)
+}
+
+// release applies a final update for a claimed message, but only while worker
+// still holds it; a worker whose lease was taken over must not overwrite the
+// new owner's outcome.
+func (r *GormOutboxRepository) release(id uint, worker string, updates map[string]any) error {
+	updates["locked_by"] = ""
+	updates["locked_until"] = nil
+	res := r.db.Model(&domain.OutboxMessage{}).
+		Where("id = ? AND status = ? AND locked_by = ?", id, domain.OutboxStatusSending, worker).
+		Updates(updates)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrOutboxLeaseLost
+	}
+	return nil
+}
+
+func (r *GormOutboxRepository) FindByID(id uint) (*domain.OutboxMessage, error) {
+	var msg domain.OutboxMessage
+	if err := r.db.First(&msg, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrOutboxMessageNotFound
+		}
+		return nil, err
+	}
+	return &msg, nil
+}
+
+func (r *GormOutboxRepository) List(status string, limit int) ([]domain.OutboxMessage, error) {
+	q := r.db.Model(&domain.OutboxMessage{})
+	if status != "" {
+		q = q.Where("status = ?", status)
+	}
+	var messages []domain.OutboxMessage
+	err := q.Order("id DESC").Limit(limit).Find(&messages).Error
+	return messages, err
+}
+
+// Replay puts a dead message back in the queue with a fresh attempt budget.
+// Messages whose payload was cleared or whose token has expired cannot be
+// replayed; the user has to request a new mail instead.
+func (r *GormOutboxRepository) Replay(id uint, now time.Time) error {
+	res := r.db.Model(&domain.OutboxMessage{}).
+		Where("id = ? AND status = ? AND payload <> '' AND (expires_at IS NULL OR expires_at > ?)", id, domain.OutboxStatusDead, now).
+		Updates(map[string]any{
+			"status":          domain.OutboxStatusPending,
+			"attempts":        0,
+			"next_attempt_at": now,
+			"updated_at":      now,
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 1 {
+		return nil
+	}
+	if _, err := r.FindByID(id); err != nil {
+		return err
+	}
+	return ErrOutboxNotReplayable
+}
+
+func (r *GormOutboxRepository) DeleteSentBefore(cutoff time.Time, limit int) (int64, error) {
+	var ids []uint
+	if err := r.db.Model(&domain.OutboxMessage{}).
+		Where("status = ? AND sent_at < ?", domain.OutboxStatusSent, cutoff).
+		Order("id ASC").
+		Limit(limit).
+		Pluck("id", &ids).Error; err != nil {
+		return 0, err
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	res := r.db.Where("id IN ?", ids).Delete(&domain.OutboxMessage{})
+	return res.RowsAffected, res.Error
+}
+
+func truncateOutboxError(msg string) string {
+	const max = 1024
+	if len(msg) > max {
+		return msg[:max]
+	}
+	return msg
+}
@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/domain"
+)
+
+func TestVerificationTokenRepositoryCreateWithOutbox(t *testing.T) {
+	db := newRepositoryDBForTest(t)
+	repo := NewVerificationTokenRepository(db)
+	now := time.Now().UTC()
+
+	token := &domain.VerificationToken{UserID: 5, TokenHash: "hash-outbox", Purpose: "password_reset", ExpiresAt: now.Add(time.Hour)}
+	msg := &domain.OutboxMessage{Kind: "password_reset", UserID: 5, Payload: `{"Token":"raw"}`, Status: domain.OutboxStatusPending, NextAttemptAt: now}
+	if err := repo.CreateWithOutbox(token, msg); err != nil {
+		t.Fatalf("create with outbox: %v", err)
+	}
+	if token.ID == 0 || msg.ID == 0 {
+		t.Fatalf("expected both rows to be stored, got token=%d msg=%d", token.ID, msg.ID)
+	}
+
+	dup := &domain.VerificationToken{UserID: 5, TokenHash: "hash-outbox", Purpose: "password_reset", ExpiresAt: now.Add(time.Hour)}
+	orphan := &domain.OutboxMessage{Kind: "password_reset", UserID: 5, Payload: "{}", Status: domain.OutboxStatusPending, NextAttemptAt: now}
+	if err := repo.CreateWithOutbox(dup, orphan); err == nil {
+		t.Fatal("expected duplicate token hash to fail")
+	}
+	var count int64
+	db.Model(&domain.OutboxMessage{}).Count(&count)
+	if count != 1 {
+		t.Fatalf("expected the failed transaction to leave no outbox row, got %d", count)
+	}
+}
+
+func TestOutboxRepositoryClaimLeaseAndRelease(t *testing.T) {
+	db := newRepositoryDBForTest(t)
+	repo := NewOutboxRepository(db)
+	now := time.Now().UTC()
+
+	due := &domain.OutboxMessage{Kind: "magic_link", UserID: 1, Payload: "{}", Status: domain.OutboxStatusPending, NextAttemptAt: now.Add(-time.Second)}
+	later := &domain.OutboxMessage{Kind: "magic_link", UserID: 2, Payload: "{}", Status: domain.OutboxStatusPending, NextAttemptAt: now.Add(time.Hour)}
+	for _, msg := range []*domain.OutboxMessage{due, later} {
+		if err := db.Create(msg).Error; err != nil {
+			t.Fatalf("create message: %v", err)
+		}
+	}
+
+	claimed, err := repo.ClaimDue(now, 10, time.Minute, "worker-a")
+	if err != nil || len(claimed) != 1 || claimed[0].ID != due.ID || claimed[0].Attempts != 1 {
+		t.Fatalf("expected the due message to be claimed once, got %+v err=%v", claimed, err)
+	}
+	if again, err := repo.ClaimDue(now, 10, time.Minute, "worker-b"); err != nil || len(again) != 0 {
+		t.Fatalf("expected a held lease to block other workers, got %+v err=%v", again, err)
+	}
+
+	// worker-a stalls past its lease; worker-b takes over and worker-a's late
+	// outcome is rejected.
+	takeover, err := repo.ClaimDue(now.Add(2*time.Minute), 10, time.Minute, "worker-b")
+	if err != nil || len(takeover) != 1 || takeover[0].Attempts != 2 {
+		t.Fatalf("expected an expired lease to be reclaimed, got %+v err=%v", takeover, err)
+	}
+	if err := repo.MarkSent(due.ID, "worker-a", now); !errors.Is(err, ErrOutboxLeaseLost) {
+		t.Fatalf("expected stale worker to lose the lease, got %v", err)
+	}
+	if err := repo.MarkRetry(due.ID, "worker-b", "smtp dial: refused", now.Add(time.Hour), now); err != nil {
+		t.Fatalf("mark retry: %v", err)
+	}
+	msg, err := repo.FindByID(due.ID)
+	if err != nil || msg.Status != domain.OutboxStatusPending || msg.LockedBy != "" || msg.LastError != "smtp dial: refused" {
+		t.Fatalf("expected released pending message, got %+v err=%v", msg, err)
+	}
+
+	if _, err := repo.FindByID(999); !errors.Is(err, ErrOutboxMessageNotFound) {
+		t.Fatalf("expected ErrOutboxMessageNotFound, got %v", err)
+	}
+}
+
+func TestOutboxRepositoryReplayAndRetention(t *testing.T) {
+	db := newRepositoryDBForTest(t)
+	repo := NewOutboxRepository(db)
+	now := time.Now().UTC()
+	future := now.Add(time.Hour)
+	past := now.Add(-time.Minute)
+	sentAt := now.Add(-48 * time.Hour)
+
+	dead := &domain.OutboxMessage{Kind: "password_reset", Payload: "{}", Status: domain.OutboxStatusDead, Attempts: 8, NextAttemptAt: now, ExpiresAt: &future, LastError: "boom"}
+	expired := &domain.OutboxMessage{Kind: "password_reset", Payload: "{}", Status: domain.OutboxStatusDead, Attempts: 8, NextAttemptAt: now, ExpiresAt: &past}
+	cleared := &domain.OutboxMessage{Kind: "password_reset", Status: domain.OutboxStatusDead, NextAttemptAt: now}
+	sent := &domain.OutboxMessage{Kind: "magic_link", Status: domain.OutboxStatusSent, NextAttemptAt: now, SentAt: &sentAt}
+	for _, msg := range []*domain.OutboxMessage{dead, expired, cleared, sent} {
+		if err := db.Create(msg).Error; err != nil {
+			t.Fatalf("create message: %v", err)
+		}
+	}
+
+	if list, err := repo.List(domain.OutboxStatusDead, 10); err != nil || len(list) != 3 || list[0].ID != cleared.ID {
+		t.Fatalf("expected newest dead messages first, got %+v err=%v", list, err)
+	}
+	for _, msg := range []*domain.OutboxMessage{expired, cleared} {
+		if err := repo.Replay(msg.ID, now); !errors.Is(err, ErrOutboxNotReplayable) {
+			t.Fatalf("expected message %d to be unreplayable, got %v", msg.ID, err)
+		}
+	}
+	if err := repo.Replay(999, now); !errors.Is(err, ErrOutboxMessageNotFound) {
+		t.Fatalf("expected ErrOutboxMessageNotFound, got %v", err)
+	}
+	if err := repo.Replay(dead.ID, now); err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	msg, _ := repo.FindByID(dead.ID)
+	if msg.Status != domain.OutboxStatusPending || msg.Attempts != 0 {
+		t.Fatalf("expected replay to reset the attempt budget, got %+v", msg)
+	}
+
+	if deleted, err := repo.DeleteSentBefore(now.Add(-24*time.Hour), 10); err != nil || deleted != 1 {
+		t.Fatalf("expected old sent message to be purged, got %d err=%v", deleted, err)
+	}
+}
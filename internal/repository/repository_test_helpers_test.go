@@ -23,11 +23,26 @@ func newRepositoryDBForTest(t *testing.T) *gorm.DB {
 	if err := db.AutoMigrate(
 		&domain.Permission{},
 		&domain.Role{},
+		&domain.RoleParent{},
+		&domain.Organization{},
+		&domain.OrganizationMember{},
+		&domain.OrganizationRoleBinding{},
+		&domain.AccessPolicy{},
 		&domain.User{},
+		&domain.UserRole{},
 		&domain.LocalCredential{},
+		&domain.PasswordHistory{},
 		&domain.VerificationToken{},
 		&domain.OAuthAccount{},
 		&domain.Session{},
+		&domain.MFATOTPCredential{},
+		&domain.MFARecoveryCode{},
+		&domain.WebAuthnCredential{},
+		&domain.JWTSigningKey{},
+		&domain.APIKey{},
+		&domain.ServiceAccount{},
+		&domain.OutboxMessage{},
+		&domain.LoginEvent{},
 	); err != nil {
 		t.Fatalf("migrate db: %v", err)
 	}
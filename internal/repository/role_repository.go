@@ -7,17 +7,23 @@ import (
 	"github.com/sandeepkv93/everything-backend-starter-kit/internal/domain"
 	"github.com/sandeepkv93/everything-backend-starter-kit/internal/observability"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
-var ErrRoleNotFound = errors.New("role not found")
+var (
+	ErrRoleNotFound       = errors.New("role not found")
+	ErrRoleParentNotFound = errors.New("parent role not found")
+	ErrRoleCycle          = errors.New("role inheritance cycle")
+	ErrRoleHasChildren    = errors.New("role has child roles")
+)
 
 type RoleRepository interface {
 	FindByID(id uint) (*domain.Role, error)
 	FindByName(name string) (*domain.Role, error)
 	List() ([]domain.Role, error)
 	ListPaged(req PageRequest, sortBy, sortOrder, name string) (PageResult[domain.Role], error)
-	Create(role *domain.Role, permissionIDs []uint) error
-	Update(role *domain.Role, permissionIDs []uint) error
+	Create(role *domain.Role, permissionIDs, parentIDs []uint) error
+	Update(role *domain.Role, permissionIDs, parentIDs []uint) error
 	DeleteByID(id uint) error
 }
 
@@ -27,7 +33,10 @@ func NewRoleRepository(db *gorm.DB) RoleRepository { return &GormRoleRepository{
 
 func (r *GormRoleRepository) FindByID(id uint) (*domain.Role, error) {
 	var role domain.Role
-	err := r.db.Preload("Permissions").First(&role, id).Error
+	err := r.db.Preload("Permissions").Preload("Parents").First(&role, id).Error
+	if err == nil {
+		err = attachRoleInheritedPermissions(r.db, &role)
+	}
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			observability.RecordRepositoryOperation(context.Background(), "role", "find_by_id", "not_found")
@@ -42,7 +51,10 @@ func (r *GormRoleRepository) FindByID(id uint) (*domain.Role, error) {
 
 func (r *GormRoleRepository) FindByName(name string) (*domain.Role, error) {
 	var role domain.Role
-	err := r.db.Preload("Permissions").Where("name = ?", name).First(&role).Error
+	err := r.db.Preload("Permissions").Preload("Parents").Where("name = ?", name).First(&role).Error
+	if err == nil {
+		err = attachRoleInheritedPermissions(r.db, &role)
+	}
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			observability.RecordRepositoryOperation(context.Background(), "role", "find_by_name", "not_found")
@@ -57,7 +69,10 @@ func (r *GormRoleRepository) FindByName(name string) (*domain.Role, error) {
 
 func (r *GormRoleRepository) List() ([]domain.Role, error) {
 	var roles []domain.Role
-	err := r.db.Preload("Permissions").Find(&roles).Error
+	err := r.db.Preload("Permissions").Preload("Parents").Find(&roles).Error
+	if err == nil {
+		err = attachInheritedPermissions(r.db, roles)
+	}
 	if err != nil {
 		observability.RecordRepositoryOperation(context.Background(), "role", "list", "error")
 		return roles, err
@@ -82,7 +97,7 @@ func (r *GormRoleRepository) ListPaged(req PageRequest, sortBy, sortOrder, name
 		return PageResult[domain.Role]{}, err
 	}
 
-	query := base.Preload("Permissions")
+	query := base.Preload("Permissions").Preload("Parents")
 	if sortBy != "" {
 		query = query.Order("roles." + sortBy + " " + sortOrder)
 	}
@@ -92,34 +107,43 @@ func (r *GormRoleRepository) ListPaged(req PageRequest, sortBy, sortOrder, name
 		observability.RecordRepositoryOperation(context.Background(), "role", "list_paged", "error")
 		return PageResult[domain.Role]{}, err
 	}
+	if err := attachInheritedPermissions(r.db, result.Items); err != nil {
+		observability.RecordRepositoryOperation(context.Background(), "role", "list_paged", "error")
+		return PageResult[domain.Role]{}, err
+	}
 	result.TotalPages = calcTotalPages(result.Total, normalized.PageSize)
 	observability.RecordRepositoryOperation(context.Background(), "role", "list_paged", "success")
 	return result, nil
 }
 
-func (r *GormRoleRepository) Create(role *domain.Role, permissionIDs []uint) error {
-	if err := r.db.Create(role).Error; err != nil {
-		observability.RecordRepositoryOperation(context.Background(), "role", "create", "error")
-		return err
-	}
-	if len(permissionIDs) == 0 {
-		observability.RecordRepositoryOperation(context.Background(), "role", "create", "success")
-		return nil
-	}
-	var perms []domain.Permission
-	if err := r.db.Where("id IN ?", permissionIDs).Find(&perms).Error; err != nil {
-		observability.RecordRepositoryOperation(context.Background(), "role", "create", "error")
-		return err
-	}
-	if err := r.db.Model(role).Association("Permissions").Replace(perms); err != nil {
-		observability.RecordRepositoryOperation(context.Background(), "role", "create", "error")
+func (r *GormRoleRepository) Create(role *domain.Role, permissionIDs, parentIDs []uint) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(role).Error; err != nil {
+			return err
+		}
+		if len(permissionIDs) > 0 {
+			var perms []domain.Permission
+			if err := tx.Where("id IN ?", permissionIDs).Find(&perms).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(role).Association("Permissions").Replace(perms); err != nil {
+				return err
+			}
+		}
+		if len(parentIDs) == 0 {
+			return nil
+		}
+		return replaceRoleParents(tx, role.ID, parentIDs)
+	})
+	if err != nil {
+		observability.RecordRepositoryOperation(context.Background(), "role", "create", roleMutationOutcome(err))
 		return err
 	}
 	observability.RecordRepositoryOperation(context.Background(), "role", "create", "success")
 	return nil
 }
 
-func (r *GormRoleRepository) Update(role *domain.Role, permissionIDs []uint) error {
+func (r *GormRoleRepository) Update(role *domain.Role, permissionIDs, parentIDs []uint) error {
 	err := r.db.Transaction(func(tx *gorm.DB) error {
 		var existing domain.Role
 		if err := tx.Preload("Permissions").First(&existing, role.ID).Error; err != nil {
@@ -140,30 +164,175 @@ func (r *GormRoleRepository) Update(role *domain.Role, permissionIDs []uint) err
 				return err
 			}
 		}
-		return tx.Model(&existing).Association("Permissions").Replace(perms)
+		if err := tx.Model(&existing).Association("Permissions").Replace(perms); err != nil {
+			return err
+		}
+		return replaceRoleParents(tx, role.ID, parentIDs)
 	})
 	if err != nil {
-		if errors.Is(err, ErrRoleNotFound) {
-			observability.RecordRepositoryOperation(context.Background(), "role", "update", "not_found")
-		} else {
-			observability.RecordRepositoryOperation(context.Background(), "role", "update", "error")
-		}
+		observability.RecordRepositoryOperation(context.Background(), "role", "update", roleMutationOutcome(err))
 		return err
 	}
 	observability.RecordRepositoryOperation(context.Background(), "role", "update", "success")
 	return nil
 }
 
+// DeleteByID refuses to delete a role other roles inherit from, so removing a
+// role never silently strips permissions from another role's holders.
 func (r *GormRoleRepository) DeleteByID(id uint) error {
-	res := r.db.Delete(&domain.Role{}, id)
-	if res.Error != nil {
-		observability.RecordRepositoryOperation(context.Background(), "role", "delete_by_id", "error")
-		return res.Error
-	}
-	if res.RowsAffected == 0 {
-		observability.RecordRepositoryOperation(context.Background(), "role", "delete_by_id", "not_found")
-		return ErrRoleNotFound
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var children int64
+		if err := tx.Model(&domain.RoleParent{}).Where("parent_id = ?", id).Count(&children).Error; err != nil {
+			return err
+		}
+		if children > 0 {
+			return ErrRoleHasChildren
+		}
+		if err := tx.Where("role_id = ?", id).Delete(&domain.RoleParent{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("role_id = ?", id).Delete(&domain.OrganizationRoleBinding{}).Error; err != nil {
+			return err
+		}
+		res := tx.Delete(&domain.Role{}, id)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrRoleNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		observability.RecordRepositoryOperation(context.Background(), "role", "delete_by_id", roleMutationOutcome(err))
+		return err
 	}
 	observability.RecordRepositoryOperation(context.Background(), "role", "delete_by_id", "success")
 	return nil
 }
+
+func roleMutationOutcome(err error) string {
+	switch {
+	case errors.Is(err, ErrRoleNotFound):
+		return "not_found"
+	case errors.Is(err, ErrRoleParentNotFound), errors.Is(err, ErrRoleCycle), errors.Is(err, ErrRoleHasChildren):
+		return "rejected"
+	default:
+		return "error"
+	}
+}
+
+// replaceRoleParents sets the direct parents of roleID. Every role row is
+// locked first so that two concurrent updates cannot each add half of a
+// cycle.
+func replaceRoleParents(tx *gorm.DB, roleID uint, parentIDs []uint) error {
+	var lockedIDs []uint
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Model(&domain.Role{}).Pluck("id", &lockedIDs).Error; err != nil {
+		return err
+	}
+	existing := make(map[uint]struct{}, len(lockedIDs))
+	for _, id := range lockedIDs {
+		existing[id] = struct{}{}
+	}
+	unique := make([]uint, 0, len(parentIDs))
+	seen := make(map[uint]struct{}, len(parentIDs))
+	for _, id := range parentIDs {
+		if _, dup := seen[id]; dup {
+			continue
+		}
+		if _, ok := existing[id]; !ok {
+			return ErrRoleParentNotFound
+		}
+		seen[id] = struct{}{}
+		unique = append(unique, id)
+	}
+	parents, err := loadRoleParents(tx)
+	if err != nil {
+		return err
+	}
+	parents[roleID] = unique
+	for _, id := range domain.RoleAncestorIDs(parents, roleID) {
+		if id == roleID {
+			return ErrRoleCycle
+		}
+	}
+	if err := tx.Where("role_id = ?", roleID).Delete(&domain.RoleParent{}).Error; err != nil {
+		return err
+	}
+	if len(unique) == 0 {
+		return nil
+	}
+	links := make([]domain.RoleParent, 0, len(unique))
+	for _, id := range unique {
+		links = append(links, domain.RoleParent{RoleID: roleID, ParentID: id})
+	}
+	return tx.Create(&links).Error
+}
+
+func loadRoleParents(db *gorm.DB) (map[uint][]uint, error) {
+	var links []domain.RoleParent
+	if err := db.Find(&links).Error; err != nil {
+		return nil, err
+	}
+	parents := make(map[uint][]uint, len(links))
+	for _, link := range links {
+		parents[link.RoleID] = append(parents[link.RoleID], link.ParentID)
+	}
+	return parents, nil
+}
+
+func attachRoleInheritedPermissions(db *gorm.DB, role *domain.Role) error {
+	roles := []domain.Role{*role}
+	if err := attachInheritedPermissions(db, roles); err != nil {
+		return err
+	}
+	*role = roles[0]
+	return nil
+}
+
+// attachInheritedPermissions fills InheritedPermissions on each role with the
+// permissions of all of its ancestors that it does not hold directly.
+func attachInheritedPermissions(db *gorm.DB, roles []domain.Role) error {
+	if len(roles) == 0 {
+		return nil
+	}
+	parents, err := loadRoleParents(db)
+	if err != nil || len(parents) == 0 {
+		return err
+	}
+	ancestors := make(map[uint][]uint, len(roles))
+	var ancestorIDs []uint
+	for _, role := range roles {
+		ids := domain.RoleAncestorIDs(parents, role.ID)
+		ancestors[role.ID] = ids
+		ancestorIDs = append(ancestorIDs, ids...)
+	}
+	if len(ancestorIDs) == 0 {
+		return nil
+	}
+	var ancestorRoles []domain.Role
+	if err := db.Preload("Permissions").Where("id IN ?", ancestorIDs).Find(&ancestorRoles).Error; err != nil {
+		return err
+	}
+	permsByRole := make(map[uint][]domain.Permission, len(ancestorRoles))
+	for _, role := range ancestorRoles {
+		permsByRole[role.ID] = role.Permissions
+	}
+	for i := range roles {
+		seen := make(map[uint]struct{}, len(roles[i].Permissions))
+		for _, p := range roles[i].Permissions {
+			seen[p.ID] = struct{}{}
+		}
+		roles[i].InheritedPermissions = nil
+		for _, id := range ancestors[roles[i].ID] {
+			for _, p := range permsByRole[id] {
+				if _, ok := seen[p.ID]; ok {
+					continue
+				}
+				seen[p.ID] = struct{}{}
+				roles[i].InheritedPermissions = append(roles[i].InheritedPermissions, p)
+			}
+		}
+	}
+	return nil
+}
@@ -21,7 +21,7 @@ func TestRoleRepositoryCreateUpdateDeleteAndConflict(t *testing.T) {
 	}
 
 	role := &domain.Role{Name: "manager", Description: "can manage users"}
-	if err := roleRepo.Create(role, []uint{permA.ID}); err != nil {
+	if err := roleRepo.Create(role, []uint{permA.ID}, nil); err != nil {
 		t.Fatalf("create role: %v", err)
 	}
 	created, err := roleRepo.FindByID(role.ID)
@@ -32,7 +32,7 @@ func TestRoleRepositoryCreateUpdateDeleteAndConflict(t *testing.T) {
 		t.Fatalf("expected one permission bound on create, got %+v", created.Permissions)
 	}
 
-	if err := roleRepo.Update(&domain.Role{ID: role.ID, Name: "manager-updated", Description: "updated"}, []uint{permB.ID}); err != nil {
+	if err := roleRepo.Update(&domain.Role{ID: role.ID, Name: "manager-updated", Description: "updated"}, []uint{permB.ID}, nil); err != nil {
 		t.Fatalf("update role: %v", err)
 	}
 	updated, err := roleRepo.FindByID(role.ID)
@@ -43,7 +43,7 @@ func TestRoleRepositoryCreateUpdateDeleteAndConflict(t *testing.T) {
 		t.Fatalf("unexpected updated role: %+v", updated)
 	}
 
-	if err := roleRepo.Update(&domain.Role{ID: 999999, Name: "missing"}, nil); !errors.Is(err, ErrRoleNotFound) {
+	if err := roleRepo.Update(&domain.Role{ID: 999999, Name: "missing"}, nil, nil); !errors.Is(err, ErrRoleNotFound) {
 		t.Fatalf("expected ErrRoleNotFound on update missing, got %v", err)
 	}
 	if err := roleRepo.DeleteByID(999999); !errors.Is(err, ErrRoleNotFound) {
@@ -51,7 +51,86 @@ func TestRoleRepositoryCreateUpdateDeleteAndConflict(t *testing.T) {
 	}
 
 	dup := &domain.Role{Name: "manager-updated", Description: "duplicate"}
-	if err := roleRepo.Create(dup, nil); err == nil {
+	if err := roleRepo.Create(dup, nil, nil); err == nil {
 		t.Fatal("expected duplicate role name conflict")
 	}
 }
+
+func TestRoleRepositoryInheritanceResolvesAncestorsAndRejectsCycles(t *testing.T) {
+	db := newRepositoryDBForTest(t)
+	roleRepo := NewRoleRepository(db)
+	permRepo := NewPermissionRepository(db)
+	userRepo := NewUserRepository(db)
+
+	read := &domain.Permission{Resource: "tickets", Action: "read"}
+	write := &domain.Permission{Resource: "tickets", Action: "write"}
+	escalate := &domain.Permission{Resource: "tickets", Action: "escalate"}
+	for _, p := range []*domain.Permission{read, write, escalate} {
+		if err := permRepo.Create(p); err != nil {
+			t.Fatalf("create permission: %v", err)
+		}
+	}
+	viewer := &domain.Role{Name: "viewer"}
+	if err := roleRepo.Create(viewer, []uint{read.ID}, nil); err != nil {
+		t.Fatalf("create viewer: %v", err)
+	}
+	support := &domain.Role{Name: "support"}
+	if err := roleRepo.Create(support, []uint{write.ID}, []uint{viewer.ID}); err != nil {
+		t.Fatalf("create support: %v", err)
+	}
+	lead := &domain.Role{Name: "support-lead"}
+	if err := roleRepo.Create(lead, []uint{escalate.ID, read.ID}, []uint{support.ID}); err != nil {
+		t.Fatalf("create support-lead: %v", err)
+	}
+
+	found, err := roleRepo.FindByID(lead.ID)
+	if err != nil {
+		t.Fatalf("find support-lead: %v", err)
+	}
+	if len(found.Parents) != 1 || found.Parents[0].ID != support.ID {
+		t.Fatalf("expected support as the only direct parent, got %+v", found.Parents)
+	}
+	if len(found.InheritedPermissions) != 1 || found.InheritedPermissions[0].ID != write.ID {
+		t.Fatalf("expected tickets:write inherited and tickets:read not repeated, got %+v", found.InheritedPermissions)
+	}
+
+	user := &domain.User{Email: "lead@example.com", Name: "Lead", Status: domain.UserStatusActive}
+	if err := userRepo.Create(user); err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	if err := userRepo.SetRoles(user.ID, []uint{lead.ID}); err != nil {
+		t.Fatalf("set roles: %v", err)
+	}
+	loaded, err := userRepo.FindByID(user.ID)
+	if err != nil || len(loaded.Roles) != 1 || len(loaded.Roles[0].InheritedPermissions) != 1 {
+		t.Fatalf("expected user roles to carry inherited permissions, got %+v err=%v", loaded, err)
+	}
+
+	if err := roleRepo.Update(&domain.Role{ID: viewer.ID, Name: "viewer"}, []uint{read.ID}, []uint{lead.ID}); !errors.Is(err, ErrRoleCycle) {
+		t.Fatalf("expected ErrRoleCycle, got %v", err)
+	}
+	if err := roleRepo.Update(&domain.Role{ID: viewer.ID, Name: "viewer"}, []uint{read.ID}, []uint{viewer.ID}); !errors.Is(err, ErrRoleCycle) {
+		t.Fatalf("expected ErrRoleCycle for a self parent, got %v", err)
+	}
+	if err := roleRepo.Create(&domain.Role{Name: "orphan"}, nil, []uint{999999}); !errors.Is(err, ErrRoleParentNotFound) {
+		t.Fatalf("expected ErrRoleParentNotFound, got %v", err)
+	}
+	if _, err := roleRepo.FindByName("orphan"); !errors.Is(err, ErrRoleNotFound) {
+		t.Fatalf("expected the rejected role not to be created, got %v", err)
+	}
+
+	if err := roleRepo.DeleteByID(support.ID); !errors.Is(err, ErrRoleHasChildren) {
+		t.Fatalf("expected ErrRoleHasChildren, got %v", err)
+	}
+	if err := roleRepo.DeleteByID(lead.ID); err != nil {
+		t.Fatalf("delete leaf role: %v", err)
+	}
+	if err := roleRepo.DeleteByID(support.ID); err != nil {
+		t.Fatalf("delete support after its child: %v", err)
+	}
+	var links int64
+	db.Model(&domain.RoleParent{}).Count(&links)
+	if links != 0 {
+		t.Fatalf("expected inheritance links to be removed with their roles, got %d", links)
+	}
+}
@@ -0,0 +1,177 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+var ErrServiceAccountNotFound = errors.New("service account not found")
+
+type ServiceAccountRepository interface {
+	Create(account *domain.ServiceAccount, roleIDs []uint) error
+	List() ([]domain.ServiceAccount, error)
+	FindByID(id uint) (*domain.ServiceAccount, error)
+	FindByClientID(clientID string) (*domain.ServiceAccount, error)
+	FindByName(name string) (*domain.ServiceAccount, error)
+	Update(account *domain.ServiceAccount) error
+	SetRoles(id uint, roleIDs []uint) error
+	UpdateSecretHash(id uint, secretHash string) error
+	TouchLastUsed(id uint, usedAt time.Time) error
+	DeleteByID(id uint) error
+}
+
+type GormServiceAccountRepository struct {
+	db *gorm.DB
+}
+
+func NewServiceAccountRepository(db *gorm.DB) ServiceAccountRepository {
+	return &GormServiceAccountRepository{db: db}
+}
+
+func (r *GormServiceAccountRepository) Create(account *domain.ServiceAccount, roleIDs []uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Roles").Create(account).Error; err != nil {
+			return err
+		}
+		roles, err := findRolesByIDs(tx, roleIDs)
+		if err != nil {
+			return err
+		}
+		if len(roles) == 0 {
+			return nil
+		}
+		if err := tx.Model(account).Association("Roles").Replace(roles); err != nil {
+			return err
+		}
+		account.Roles = roles
+		return nil
+	})
+}
+
+func (r *GormServiceAccountRepository) List() ([]domain.ServiceAccount, error) {
+	var accounts []domain.ServiceAccount
+	if err := r.db.Preload("Roles.Permissions").Order("id ASC").Find(&accounts).Error; err != nil {
+		return nil, err
+	}
+	for i := range accounts {
+		if err := attachInheritedPermissions(r.db, accounts[i].Roles); err != nil {
+			return nil, err
+		}
+	}
+	return accounts, nil
+}
+
+func (r *GormServiceAccountRepository) FindByID(id uint) (*domain.ServiceAccount, error) {
+	return r.findOne(r.db.Where("id = ?", id))
+}
+
+func (r *GormServiceAccountRepository) FindByClientID(clientID string) (*domain.ServiceAccount, error) {
+	return r.findOne(r.db.Where("client_id = ?", clientID))
+}
+
+func (r *GormServiceAccountRepository) FindByName(name string) (*domain.ServiceAccount, error) {
+	return r.findOne(r.db.Where("name = ?", name))
+}
+
+func (r *GormServiceAccountRepository) findOne(query *gorm.DB) (*domain.ServiceAccount, error) {
+	var account domain.ServiceAccount
+	if err := query.Preload("Roles.Permissions").First(&account).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrServiceAccountNotFound
+		}
+		return nil, err
+	}
+	if err := attachInheritedPermissions(r.db, account.Roles); err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+// Update writes the mutable profile fields; roles and the secret have their
+// own methods so a profile edit cannot clobber them.
+func (r *GormServiceAccountRepository) Update(account *domain.ServiceAccount) error {
+	res := r.db.Model(&domain.ServiceAccount{}).
+		Where("id = ?", account.ID).
+		Updates(map[string]any{
+			"name":        account.Name,
+			"description": account.Description,
+			"disabled_at": account.DisabledAt,
+			"updated_at":  time.Now().UTC(),
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrServiceAccountNotFound
+	}
+	return nil
+}
+
+func (r *GormServiceAccountRepository) SetRoles(id uint, roleIDs []uint) error {
+	roles, err := findRolesByIDs(r.db, roleIDs)
+	if err != nil {
+		return err
+	}
+	account := domain.ServiceAccount{ID: id}
+	return r.db.Model(&account).Association("Roles").Replace(roles)
+}
+
+func (r *GormServiceAccountRepository) UpdateSecretHash(id uint, secretHash string) error {
+	res := r.db.Model(&domain.ServiceAccount{}).
+		Where("id = ?", id).
+		Updates(map[string]any{"secret_hash": secretHash, "updated_at": time.Now().UTC()})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrServiceAccountNotFound
+	}
+	return nil
+}
+
+func (r *GormServiceAccountRepository) TouchLastUsed(id uint, usedAt time.Time) error {
+	return r.db.Model(&domain.ServiceAccount{}).
+		Where("id = ?", id).
+		UpdateColumn("last_used_at", usedAt).Error
+}
+
+func (r *GormServiceAccountRepository) DeleteByID(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		account := domain.ServiceAccount{ID: id}
+		if err := tx.Model(&account).Association("Roles").Clear(); err != nil {
+			return err
+		}
+		res := tx.Delete(&domain.ServiceAccount{}, id)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrServiceAccountNotFound
+		}
+		return nil
+	})
+}
+
+// findRolesByIDs returns ErrRoleNotFound when any requested role is missing so
+// a typo cannot silently drop a grant.
+func findRolesByIDs(db *gorm.DB, roleIDs []uint) ([]domain.Role, error) {
+	roles := []domain.Role{}
+	if len(roleIDs) == 0 {
+		return roles, nil
+	}
+	unique := make(map[uint]struct{}, len(roleIDs))
+	for _, id := range roleIDs {
+		unique[id] = struct{}{}
+	}
+	if err := db.Where("id IN ?", roleIDs).Find(&roles).Error; err != nil {
+		return nil, err
+	}
+	if len(roles) != len(unique) {
+		return nil, ErrRoleNotFound
+	}
+	return roles, nil
+}
@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/domain"
+)
+
+func TestServiceAccountRepositoryLifecycle(t *testing.T) {
+	db := newRepositoryDBForTest(t)
+	repo := NewServiceAccountRepository(db)
+	roleRepo := NewRoleRepository(db)
+	permRepo := NewPermissionRepository(db)
+
+	perm := &domain.Permission{Resource: "users", Action: "read"}
+	if err := permRepo.Create(perm); err != nil {
+		t.Fatalf("create permission: %v", err)
+	}
+	reader := &domain.Role{Name: "reader"}
+	if err := roleRepo.Create(reader, []uint{perm.ID}, nil); err != nil {
+		t.Fatalf("create role: %v", err)
+	}
+	other := &domain.Role{Name: "other"}
+	if err := roleRepo.Create(other, nil, nil); err != nil {
+		t.Fatalf("create role: %v", err)
+	}
+
+	if err := repo.Create(&domain.ServiceAccount{Name: "bad", ClientID: "sa_bad", SecretHash: "h"}, []uint{999}); !errors.Is(err, ErrRoleNotFound) {
+		t.Fatalf("expected unknown role to fail create, got %v", err)
+	}
+	if _, err := repo.FindByName("bad"); !errors.Is(err, ErrServiceAccountNotFound) {
+		t.Fatalf("expected failed create to roll back, got %v", err)
+	}
+
+	account := &domain.ServiceAccount{Name: "nightly", ClientID: "sa_nightly", SecretHash: "hash-1"}
+	if err := repo.Create(account, []uint{reader.ID}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	found, err := repo.FindByClientID("sa_nightly")
+	if err != nil {
+		t.Fatalf("find by client id: %v", err)
+	}
+	if len(found.Roles) != 1 || len(found.Roles[0].Permissions) != 1 {
+		t.Fatalf("expected role with permissions to be preloaded, got %+v", found.Roles)
+	}
+
+	disabledAt := time.Now().UTC()
+	found.Description = "nightly export"
+	found.DisabledAt = &disabledAt
+	if err := repo.Update(found); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if err := repo.SetRoles(account.ID, []uint{other.ID}); err != nil {
+		t.Fatalf("set roles: %v", err)
+	}
+	if err := repo.UpdateSecretHash(account.ID, "hash-2"); err != nil {
+		t.Fatalf("update secret: %v", err)
+	}
+	updated, err := repo.FindByID(account.ID)
+	if err != nil {
+		t.Fatalf("find by id: %v", err)
+	}
+	if updated.DisabledAt == nil || updated.SecretHash != "hash-2" || len(updated.Roles) != 1 || updated.Roles[0].ID != other.ID {
+		t.Fatalf("unexpected updated account: %+v", updated)
+	}
+
+	if err := repo.DeleteByID(account.ID); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if err := repo.DeleteByID(account.ID); !errors.Is(err, ErrServiceAccountNotFound) {
+		t.Fatalf("expected second delete to be not found, got %v", err)
+	}
+	var joins int64
+	if err := db.Table("service_account_roles").Where("service_account_id = ?", account.ID).Count(&joins).Error; err != nil || joins != 0 {
+		t.Fatalf("expected role bindings to be removed, got %d err=%v", joins, err)
+	}
+}
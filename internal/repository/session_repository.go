@@ -20,8 +20,10 @@ type SessionRepository interface {
 	FindActiveByTokenIDForUser(userID uint, tokenID string) (*domain.Session, error)
 	FindByIDForUser(userID, sessionID uint) (*domain.Session, error)
 	ListActiveByUserID(userID uint) ([]domain.Session, error)
+	ListIssuedSince(userID uint, since time.Time) ([]domain.Session, error)
 	RotateSession(oldHash string, newSession *domain.Session) (*domain.Session, error)
 	UpdateTokenLineageByHash(hash, tokenID, familyID string) error
+	MarkReauthenticated(userID uint, familyID string, at time.Time) (*domain.Session, error)
 	MarkReuseDetectedByHash(hash string) error
 	RevokeByHash(hash, reason string) error
 	RevokeByIDForUser(userID, sessionID uint, reason string) (bool, error)
@@ -104,6 +106,22 @@ func (r *GormSessionRepository) ListActiveByUserID(userID uint) ([]domain.Sessio
 	return sessions, err
 }
 
+// ListIssuedSince returns sessions whose latest access token was signed
+// after since. It includes revoked and rotated rows: the access token minted
+// with a session stays valid until it expires, whatever happened to the row.
+func (r *GormSessionRepository) ListIssuedSince(userID uint, since time.Time) ([]domain.Session, error) {
+	var sessions []domain.Session
+	err := r.db.Where("user_id = ? AND COALESCE(access_issued_at, created_at) > ?", userID, since).
+		Order("created_at DESC").
+		Find(&sessions).Error
+	if err != nil {
+		observability.RecordRepositoryOperation(context.Background(), "session", "list_issued_since", "error")
+		return sessions, err
+	}
+	observability.RecordRepositoryOperation(context.Background(), "session", "list_issued_since", "success")
+	return sessions, nil
+}
+
 func (r *GormSessionRepository) RotateSession(oldHash string, newSession *domain.Session) (*domain.Session, error) {
 	var rotated *domain.Session
 	err := r.db.Transaction(func(tx *gorm.DB) error {
@@ -160,6 +178,38 @@ func (r *GormSessionRepository) UpdateTokenLineageByHash(hash, tokenID, familyID
 	return nil
 }
 
+// MarkReauthenticated moves auth_time forward on the live session of a
+// refresh-token family and returns that session. The access token re-signed
+// for it is issued at the same instant, so access_issued_at moves too.
+func (r *GormSessionRepository) MarkReauthenticated(userID uint, familyID string, at time.Time) (*domain.Session, error) {
+	var s domain.Session
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("user_id = ? AND family_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, familyID, time.Now()).
+			First(&s).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrSessionNotFound
+			}
+			return err
+		}
+		s.AuthTime = &at
+		s.AccessIssuedAt = &at
+		return tx.Model(&domain.Session{}).Where("id = ?", s.ID).
+			Updates(map[string]any{"auth_time": at, "access_issued_at": at}).Error
+	})
+	if err != nil {
+		if errors.Is(err, ErrSessionNotFound) {
+			observability.RecordRepositoryOperation(context.Background(), "session", "mark_reauthenticated", "not_found")
+		} else {
+			observability.RecordRepositoryOperation(context.Background(), "session", "mark_reauthenticated", "error")
+		}
+		return nil, err
+	}
+	observability.RecordRepositoryOperation(context.Background(), "session", "mark_reauthenticated", "success")
+	return &s, nil
+}
+
 func (r *GormSessionRepository) MarkReuseDetectedByHash(hash string) error {
 	now := time.Now().UTC()
 	reason := "reuse_detected"
@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 	"testing"
@@ -71,6 +72,31 @@ func TestSessionRepositoryListActiveByUserID(t *testing.T) {
 	}
 }
 
+func TestSessionRepositoryListIssuedSinceIncludesRevokedRows(t *testing.T) {
+	repo := newSessionRepoForTest(t)
+
+	revokedAt := time.Now().UTC()
+	rows := []*domain.Session{
+		{UserID: 1, RefreshTokenHash: "h1", TokenID: strPtr("tok-1"), ExpiresAt: time.Now().Add(time.Hour), CreatedAt: time.Now().Add(-time.Minute)},
+		{UserID: 1, RefreshTokenHash: "h2", TokenID: strPtr("tok-2"), ExpiresAt: time.Now().Add(time.Hour), RevokedAt: &revokedAt, CreatedAt: time.Now().Add(-2 * time.Minute)},
+		{UserID: 1, RefreshTokenHash: "h3", TokenID: strPtr("tok-3"), ExpiresAt: time.Now().Add(time.Hour), CreatedAt: time.Now().Add(-time.Hour)},
+		{UserID: 2, RefreshTokenHash: "h4", TokenID: strPtr("tok-4"), ExpiresAt: time.Now().Add(time.Hour), CreatedAt: time.Now().Add(-time.Minute)},
+	}
+	for _, row := range rows {
+		if err := repo.Create(row); err != nil {
+			t.Fatalf("create %s: %v", row.RefreshTokenHash, err)
+		}
+	}
+
+	sessions, err := repo.ListIssuedSince(1, time.Now().Add(-15*time.Minute))
+	if err != nil {
+		t.Fatalf("list issued since: %v", err)
+	}
+	if len(sessions) != 2 || sessions[0].RefreshTokenHash != "h1" || sessions[1].RefreshTokenHash != "h2" {
+		t.Fatalf("unexpected sessions: %+v", sessions)
+	}
+}
+
 func TestSessionRepositoryRevokeScopeByUser(t *testing.T) {
 	repo := newSessionRepoForTest(t)
 
@@ -145,3 +171,31 @@ func newSessionRepoForTest(t *testing.T) SessionRepository {
 }
 
 func strPtr(v string) *string { return &v }
+
+func TestSessionRepositoryMarkReauthenticatedTargetsLiveFamilyMember(t *testing.T) {
+	repo := newSessionRepoForTest(t)
+	rotated := time.Now().Add(-time.Minute)
+	old := &domain.Session{UserID: 1, RefreshTokenHash: "fam-a-1", TokenID: strPtr("tok-a-1"), FamilyID: strPtr("fam-a"), ExpiresAt: time.Now().Add(time.Hour), RevokedAt: &rotated}
+	live := &domain.Session{UserID: 1, RefreshTokenHash: "fam-a-2", TokenID: strPtr("tok-a-2"), FamilyID: strPtr("fam-a"), ExpiresAt: time.Now().Add(time.Hour)}
+	for _, s := range []*domain.Session{old, live} {
+		if err := repo.Create(s); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+	}
+
+	if _, err := repo.MarkReauthenticated(2, "fam-a", time.Now()); !errors.Is(err, ErrSessionNotFound) {
+		t.Fatalf("expected another user's family to be out of reach, got %v", err)
+	}
+	at := time.Now().UTC().Truncate(time.Second)
+	got, err := repo.MarkReauthenticated(1, "fam-a", at)
+	if err != nil {
+		t.Fatalf("mark reauthenticated: %v", err)
+	}
+	if got.ID != live.ID || got.TokenID == nil || *got.TokenID != "tok-a-2" {
+		t.Fatalf("expected the live session, got %+v", got)
+	}
+	stored, err := repo.FindActiveByTokenIDForUser(1, "tok-a-2")
+	if err != nil || stored.AuthTime == nil || !stored.AuthTime.Equal(at) {
+		t.Fatalf("expected auth_time to be stored, got %+v err=%v", stored, err)
+	}
+}
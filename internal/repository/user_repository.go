@@ -3,13 +3,17 @@ package repository
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/sandeepkv93/everything-backend-starter-kit/internal/domain"
 	"github.com/sandeepkv93/everything-backend-starter-kit/internal/observability"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+var ErrEmailTaken = errors.New("email already in use")
+
 type UserListQuery struct {
 	PageRequest
 	SortBy    string
@@ -17,6 +21,9 @@ type UserListQuery struct {
 	Email     string
 	Status    string
 	Role      string
+	// OrganizationID, when set, limits the list to that organization's
+	// members.
+	OrganizationID uint
 }
 
 type UserRepository interface {
@@ -24,10 +31,19 @@ type UserRepository interface {
 	FindByEmail(email string) (*domain.User, error)
 	Create(user *domain.User) error
 	Update(user *domain.User) error
+	UpdateStatus(userID uint, status string) error
+	UpdateEmail(userID uint, email string) error
+	ScheduleDeletion(userID uint, at time.Time) error
+	CancelDeletion(userID uint) error
+	ListDueForDeletion(now time.Time, limit int) ([]uint, error)
+	Erase(userID uint) error
 	List() ([]domain.User, error)
 	ListPaged(query UserListQuery) (PageResult[domain.User], error)
 	SetRoles(userID uint, roleIDs []uint) error
+	SetRoleBindings(userID uint, bindings []domain.UserRole) error
 	AddRole(userID, roleID uint) error
+	ListExpiredRoleBindings(now time.Time, limit int) ([]domain.UserRole, error)
+	DeleteExpiredRoleBinding(userID, roleID uint, now time.Time) (bool, error)
 }
 
 type GormUserRepository struct{ db *gorm.DB }
@@ -36,7 +52,10 @@ func NewUserRepository(db *gorm.DB) UserRepository { return &GormUserRepository{
 
 func (r *GormUserRepository) FindByID(id uint) (*domain.User, error) {
 	var u domain.User
-	err := r.db.Preload("Roles.Permissions").First(&u, id).Error
+	err := r.db.First(&u, id).Error
+	if err == nil {
+		err = r.attachActiveRoles(&u)
+	}
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			observability.RecordRepositoryOperation(context.Background(), "user", "find_by_id", "not_found")
@@ -51,7 +70,10 @@ func (r *GormUserRepository) FindByID(id uint) (*domain.User, error) {
 
 func (r *GormUserRepository) FindByEmail(email string) (*domain.User, error) {
 	var u domain.User
-	err := r.db.Preload("Roles.Permissions").Where("email = ?", email).First(&u).Error
+	err := r.db.Where("email = ?", email).First(&u).Error
+	if err == nil {
+		err = r.attachActiveRoles(&u)
+	}
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			observability.RecordRepositoryOperation(context.Background(), "user", "find_by_email", "not_found")
@@ -83,9 +105,174 @@ func (r *GormUserRepository) Update(user *domain.User) error {
 	return nil
 }
 
+// UpdateStatus writes only the status column so a concurrent role change is
+// not overwritten by a stale copy of the user.
+func (r *GormUserRepository) UpdateStatus(userID uint, status string) error {
+	res := r.db.Model(&domain.User{}).Where("id = ?", userID).Updates(map[string]any{"status": status, "updated_at": time.Now().UTC()})
+	if res.Error != nil {
+		observability.RecordRepositoryOperation(context.Background(), "user", "update_status", "error")
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		observability.RecordRepositoryOperation(context.Background(), "user", "update_status", "not_found")
+		return gorm.ErrRecordNotFound
+	}
+	observability.RecordRepositoryOperation(context.Background(), "user", "update_status", "success")
+	return nil
+}
+
+// UpdateEmail swaps the address and clears the local credential's verified
+// flag in one transaction, so a failed swap never leaves them out of step.
+func (r *GormUserRepository) UpdateEmail(userID uint, email string) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var taken int64
+		if err := tx.Model(&domain.User{}).Where("email = ? AND id <> ?", email, userID).Count(&taken).Error; err != nil {
+			return err
+		}
+		if taken > 0 {
+			return ErrEmailTaken
+		}
+		now := time.Now().UTC()
+		res := tx.Model(&domain.User{}).Where("id = ?", userID).Updates(map[string]any{"email": email, "updated_at": now})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return tx.Model(&domain.LocalCredential{}).Where("user_id = ?", userID).
+			Updates(map[string]any{"email_verified": false, "email_verified_at": nil, "updated_at": now}).Error
+	})
+	switch {
+	case err == nil:
+		observability.RecordRepositoryOperation(context.Background(), "user", "update_email", "success")
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		observability.RecordRepositoryOperation(context.Background(), "user", "update_email", "not_found")
+	case errors.Is(err, ErrEmailTaken):
+		observability.RecordRepositoryOperation(context.Background(), "user", "update_email", "conflict")
+	default:
+		observability.RecordRepositoryOperation(context.Background(), "user", "update_email", "error")
+	}
+	return err
+}
+
+// ScheduleDeletion parks the account in pending_deletion until at.
+func (r *GormUserRepository) ScheduleDeletion(userID uint, at time.Time) error {
+	res := r.db.Model(&domain.User{}).Where("id = ?", userID).Updates(map[string]any{
+		"status":                domain.UserStatusPendingDeletion,
+		"deletion_scheduled_at": at,
+		"updated_at":            time.Now().UTC(),
+	})
+	if res.Error != nil {
+		observability.RecordRepositoryOperation(context.Background(), "user", "schedule_deletion", "error")
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		observability.RecordRepositoryOperation(context.Background(), "user", "schedule_deletion", "not_found")
+		return gorm.ErrRecordNotFound
+	}
+	observability.RecordRepositoryOperation(context.Background(), "user", "schedule_deletion", "success")
+	return nil
+}
+
+// CancelDeletion only touches accounts still pending deletion, so it cannot
+// resurrect one the sweeper already erased.
+func (r *GormUserRepository) CancelDeletion(userID uint) error {
+	res := r.db.Model(&domain.User{}).
+		Where("id = ? AND status = ?", userID, domain.UserStatusPendingDeletion).
+		Updates(map[string]any{
+			"status":                domain.UserStatusActive,
+			"deletion_scheduled_at": nil,
+			"updated_at":            time.Now().UTC(),
+		})
+	if res.Error != nil {
+		observability.RecordRepositoryOperation(context.Background(), "user", "cancel_deletion", "error")
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		observability.RecordRepositoryOperation(context.Background(), "user", "cancel_deletion", "not_found")
+		return gorm.ErrRecordNotFound
+	}
+	observability.RecordRepositoryOperation(context.Background(), "user", "cancel_deletion", "success")
+	return nil
+}
+
+func (r *GormUserRepository) ListDueForDeletion(now time.Time, limit int) ([]uint, error) {
+	var ids []uint
+	err := r.db.Model(&domain.User{}).
+		Where("status = ? AND deletion_scheduled_at <= ?", domain.UserStatusPendingDeletion, now).
+		Order("deletion_scheduled_at ASC").
+		Limit(limit).
+		Pluck("id", &ids).Error
+	if err != nil {
+		observability.RecordRepositoryOperation(context.Background(), "user", "list_due_for_deletion", "error")
+		return nil, err
+	}
+	observability.RecordRepositoryOperation(context.Background(), "user", "list_due_for_deletion", "success")
+	return ids, nil
+}
+
+// Erase deletes every credential, identity, session and token the user owns
+// and anonymizes the user row in one transaction. The row itself is kept so
+// IDs referenced by audit logs still resolve to a (now anonymous) account.
+func (r *GormUserRepository) Erase(userID uint) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		owned := []any{
+			&domain.Session{},
+			&domain.VerificationToken{},
+			&domain.OAuthAccount{},
+			&domain.LocalCredential{},
+			&domain.PasswordHistory{},
+			&domain.MFATOTPCredential{},
+			&domain.MFARecoveryCode{},
+			&domain.WebAuthnCredential{},
+			&domain.APIKey{},
+			&domain.OutboxMessage{},
+			&domain.LoginEvent{},
+			&domain.OrganizationRoleBinding{},
+			&domain.OrganizationMember{},
+		}
+		for _, model := range owned {
+			if err := tx.Where("user_id = ?", userID).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Model(&domain.User{ID: userID}).Association("Roles").Clear(); err != nil {
+			return err
+		}
+		res := tx.Model(&domain.User{}).Where("id = ?", userID).Updates(map[string]any{
+			"email":                 domain.ErasedUserEmail(userID),
+			"name":                  "Deleted user",
+			"avatar_url":            "",
+			"status":                domain.UserStatusDeleted,
+			"deletion_scheduled_at": nil,
+			"updated_at":            time.Now().UTC(),
+		})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+	switch {
+	case err == nil:
+		observability.RecordRepositoryOperation(context.Background(), "user", "erase", "success")
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		observability.RecordRepositoryOperation(context.Background(), "user", "erase", "not_found")
+	default:
+		observability.RecordRepositoryOperation(context.Background(), "user", "erase", "error")
+	}
+	return err
+}
+
 func (r *GormUserRepository) List() ([]domain.User, error) {
 	var users []domain.User
-	err := r.db.Preload("Roles").Find(&users).Error
+	err := r.db.Find(&users).Error
+	if err == nil {
+		err = r.attachActiveRoleList(users)
+	}
 	if err != nil {
 		observability.RecordRepositoryOperation(context.Background(), "user", "list", "error")
 		return users, err
@@ -108,8 +295,11 @@ func (r *GormUserRepository) ListPaged(query UserListQuery) (PageResult[domain.U
 	if query.Status != "" {
 		base = base.Where("users.status = ?", query.Status)
 	}
+	if query.OrganizationID != 0 {
+		base = base.Joins("JOIN organization_members om ON om.user_id = users.id AND om.organization_id = ?", query.OrganizationID)
+	}
 	if query.Role != "" {
-		base = base.Joins("JOIN user_roles ur ON ur.user_id = users.id").
+		base = base.Joins("JOIN user_roles ur ON ur.user_id = users.id AND (ur.expires_at IS NULL OR ur.expires_at > ?)", time.Now().UTC()).
 			Joins("JOIN roles r ON r.id = ur.role_id").
 			Where("r.name = ?", query.Role)
 	}
@@ -123,7 +313,7 @@ func (r *GormUserRepository) ListPaged(query UserListQuery) (PageResult[domain.U
 		return PageResult[domain.User]{}, err
 	}
 
-	listQuery := base.Preload("Roles")
+	listQuery := base.Session(&gorm.Session{})
 	if query.Role != "" {
 		listQuery = listQuery.Distinct("users.*")
 	}
@@ -137,21 +327,66 @@ func (r *GormUserRepository) ListPaged(query UserListQuery) (PageResult[domain.U
 		observability.RecordRepositoryOperation(context.Background(), "user", "list_paged", "error")
 		return PageResult[domain.User]{}, err
 	}
+	if err := r.attachActiveRoleList(result.Items); err != nil {
+		observability.RecordRepositoryOperation(context.Background(), "user", "list_paged", "error")
+		return PageResult[domain.User]{}, err
+	}
 	result.TotalPages = calcTotalPages(result.Total, req.PageSize)
 	observability.RecordRepositoryOperation(context.Background(), "user", "list_paged", "success")
 	return result, nil
 }
 
+// SetRoles replaces the user's role bindings with permanent ones.
 func (r *GormUserRepository) SetRoles(userID uint, roleIDs []uint) error {
-	var roles []domain.Role
-	if len(roleIDs) > 0 {
-		if err := r.db.Where("id IN ?", roleIDs).Find(&roles).Error; err != nil {
-			observability.RecordRepositoryOperation(context.Background(), "user", "set_roles", "error")
+	bindings := make([]domain.UserRole, 0, len(roleIDs))
+	for _, id := range roleIDs {
+		bindings = append(bindings, domain.UserRole{RoleID: id})
+	}
+	return r.SetRoleBindings(userID, bindings)
+}
+
+// SetRoleBindings replaces the user's role bindings. Bindings kept from the
+// previous set keep their CreatedAt but take the new expiry and reason;
+// bindings for unknown roles are dropped.
+func (r *GormUserRepository) SetRoleBindings(userID uint, bindings []domain.UserRole) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		roleIDs := make([]uint, 0, len(bindings))
+		for _, b := range bindings {
+			roleIDs = append(roleIDs, b.RoleID)
+		}
+		var known []uint
+		if len(roleIDs) > 0 {
+			if err := tx.Model(&domain.Role{}).Where("id IN ?", roleIDs).Pluck("id", &known).Error; err != nil {
+				return err
+			}
+		}
+		stale := tx.Where("user_id = ?", userID)
+		if len(known) > 0 {
+			stale = stale.Where("role_id NOT IN ?", known)
+		}
+		if err := stale.Delete(&domain.UserRole{}).Error; err != nil {
 			return err
 		}
-	}
-	u := domain.User{ID: userID}
-	if err := r.db.Model(&u).Association("Roles").Replace(roles); err != nil {
+		exists := make(map[uint]struct{}, len(known))
+		for _, id := range known {
+			exists[id] = struct{}{}
+		}
+		for _, b := range bindings {
+			if _, ok := exists[b.RoleID]; !ok {
+				continue
+			}
+			row := domain.UserRole{UserID: userID, RoleID: b.RoleID, ExpiresAt: b.ExpiresAt, Reason: b.Reason}
+			err := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "user_id"}, {Name: "role_id"}},
+				DoUpdates: clause.AssignmentColumns([]string{"expires_at", "reason"}),
+			}).Create(&row).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
 		observability.RecordRepositoryOperation(context.Background(), "user", "set_roles", "error")
 		return err
 	}
@@ -169,3 +404,101 @@ func (r *GormUserRepository) AddRole(userID, roleID uint) error {
 	observability.RecordRepositoryOperation(context.Background(), "user", "add_role", "success")
 	return nil
 }
+
+func (r *GormUserRepository) ListExpiredRoleBindings(now time.Time, limit int) ([]domain.UserRole, error) {
+	var bindings []domain.UserRole
+	err := r.db.Where("expires_at IS NOT NULL AND expires_at <= ?", now).
+		Order("expires_at ASC").
+		Limit(limit).
+		Find(&bindings).Error
+	if err != nil {
+		observability.RecordRepositoryOperation(context.Background(), "user", "list_expired_role_bindings", "error")
+		return nil, err
+	}
+	observability.RecordRepositoryOperation(context.Background(), "user", "list_expired_role_bindings", "success")
+	return bindings, nil
+}
+
+// DeleteExpiredRoleBinding deletes the binding only if it is still expired at
+// now, so a binding an admin extended after it was listed survives. It
+// reports whether a row was deleted.
+func (r *GormUserRepository) DeleteExpiredRoleBinding(userID, roleID uint, now time.Time) (bool, error) {
+	res := r.db.Where("user_id = ? AND role_id = ? AND expires_at IS NOT NULL AND expires_at <= ?", userID, roleID, now).
+		Delete(&domain.UserRole{})
+	if res.Error != nil {
+		observability.RecordRepositoryOperation(context.Background(), "user", "delete_expired_role_binding", "error")
+		return false, res.Error
+	}
+	observability.RecordRepositoryOperation(context.Background(), "user", "delete_expired_role_binding", "success")
+	return res.RowsAffected > 0, nil
+}
+
+// attachActiveRoles loads u's unexpired roles with their direct and inherited
+// permissions, and records when the first of those bindings expires. Preload
+// cannot filter on the binding: GORM reads many2many join rows without the
+// caller's conditions.
+func (r *GormUserRepository) attachActiveRoles(u *domain.User) error {
+	var bindings []domain.UserRole
+	if err := r.db.Where("user_id = ? AND (expires_at IS NULL OR expires_at > ?)", u.ID, time.Now().UTC()).Find(&bindings).Error; err != nil {
+		return err
+	}
+	roleIDs := make([]uint, 0, len(bindings))
+	u.RolesExpireAt = nil
+	for _, b := range bindings {
+		roleIDs = append(roleIDs, b.RoleID)
+		if b.ExpiresAt != nil && (u.RolesExpireAt == nil || b.ExpiresAt.Before(*u.RolesExpireAt)) {
+			expiresAt := *b.ExpiresAt
+			u.RolesExpireAt = &expiresAt
+		}
+	}
+	var roles []domain.Role
+	if len(roleIDs) > 0 {
+		if err := r.db.Preload("Permissions").Where("id IN ?", roleIDs).Order("id ASC").Find(&roles).Error; err != nil {
+			return err
+		}
+		if err := attachInheritedPermissions(r.db, roles); err != nil {
+			return err
+		}
+	}
+	u.Roles = roles
+	return nil
+}
+
+// attachActiveRoleList sets each user's unexpired roles, without permissions.
+func (r *GormUserRepository) attachActiveRoleList(users []domain.User) error {
+	if len(users) == 0 {
+		return nil
+	}
+	userIDs := make([]uint, 0, len(users))
+	for _, u := range users {
+		userIDs = append(userIDs, u.ID)
+	}
+	var bindings []domain.UserRole
+	if err := r.db.Where("user_id IN ? AND (expires_at IS NULL OR expires_at > ?)", userIDs, time.Now().UTC()).Order("role_id ASC").Find(&bindings).Error; err != nil {
+		return err
+	}
+	roleIDs := make([]uint, 0, len(bindings))
+	for _, b := range bindings {
+		roleIDs = append(roleIDs, b.RoleID)
+	}
+	var roles []domain.Role
+	if len(roleIDs) > 0 {
+		if err := r.db.Where("id IN ?", roleIDs).Order("id ASC").Find(&roles).Error; err != nil {
+			return err
+		}
+	}
+	rolesByID := make(map[uint]domain.Role, len(roles))
+	for _, role := range roles {
+		rolesByID[role.ID] = role
+	}
+	rolesByUser := make(map[uint][]domain.Role, len(users))
+	for _, b := range bindings {
+		if role, ok := rolesByID[b.RoleID]; ok {
+			rolesByUser[b.UserID] = append(rolesByUser[b.UserID], role)
+		}
+	}
+	for i := range users {
+		users[i].Roles = rolesByUser[users[i].ID]
+	}
+	return nil
+}
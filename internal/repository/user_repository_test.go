@@ -1,9 +1,12 @@
 package repository
 
 import (
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/sandeepkv93/everything-backend-starter-kit/internal/domain"
+	"gorm.io/gorm"
 )
 
 func TestUserRepositoryListPagedFiltersSortAndRoleAssociations(t *testing.T) {
@@ -17,10 +20,10 @@ func TestUserRepositoryListPagedFiltersSortAndRoleAssociations(t *testing.T) {
 	}
 	adminRole := &domain.Role{Name: "admin"}
 	userRole := &domain.Role{Name: "user"}
-	if err := roleRepo.Create(adminRole, []uint{permRead.ID}); err != nil {
+	if err := roleRepo.Create(adminRole, []uint{permRead.ID}, nil); err != nil {
 		t.Fatalf("create admin role: %v", err)
 	}
-	if err := roleRepo.Create(userRole, nil); err != nil {
+	if err := roleRepo.Create(userRole, nil, nil); err != nil {
 		t.Fatalf("create user role: %v", err)
 	}
 
@@ -80,3 +83,237 @@ func TestUserRepositoryListPagedFiltersSortAndRoleAssociations(t *testing.T) {
 		t.Fatalf("expected roles replaced to [user], got %+v", updated.Roles)
 	}
 }
+
+func TestUserRepositoryUpdateStatus(t *testing.T) {
+	db := newRepositoryDBForTest(t)
+	userRepo := NewUserRepository(db)
+
+	u := &domain.User{Email: "status@example.com", Name: "Status", Status: domain.UserStatusActive}
+	if err := userRepo.Create(u); err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	if err := userRepo.UpdateStatus(u.ID, domain.UserStatusSuspended); err != nil {
+		t.Fatalf("update status: %v", err)
+	}
+	got, err := userRepo.FindByID(u.ID)
+	if err != nil || got.Status != domain.UserStatusSuspended {
+		t.Fatalf("expected suspended user, got %+v err=%v", got, err)
+	}
+	if err := userRepo.UpdateStatus(u.ID+100, domain.UserStatusActive); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("expected not found for missing user, got %v", err)
+	}
+}
+
+func TestUserRepositoryUpdateEmail(t *testing.T) {
+	db := newRepositoryDBForTest(t)
+	userRepo := NewUserRepository(db)
+	credRepo := NewLocalCredentialRepository(db)
+
+	u := &domain.User{Email: "old@example.com", Name: "Mover", Status: domain.UserStatusActive}
+	other := &domain.User{Email: "taken@example.com", Name: "Other", Status: domain.UserStatusActive}
+	for _, user := range []*domain.User{u, other} {
+		if err := userRepo.Create(user); err != nil {
+			t.Fatalf("create user: %v", err)
+		}
+	}
+	if err := credRepo.Create(&domain.LocalCredential{UserID: u.ID, PasswordHash: "hash", EmailVerified: true}); err != nil {
+		t.Fatalf("create credential: %v", err)
+	}
+
+	if err := userRepo.UpdateEmail(u.ID, "taken@example.com"); !errors.Is(err, ErrEmailTaken) {
+		t.Fatalf("expected ErrEmailTaken, got %v", err)
+	}
+	if cred, _ := credRepo.FindByUserID(u.ID); cred == nil || !cred.EmailVerified {
+		t.Fatalf("expected rejected swap to leave verification intact, got %+v", cred)
+	}
+	if err := userRepo.UpdateEmail(u.ID, "new@example.com"); err != nil {
+		t.Fatalf("update email: %v", err)
+	}
+	got, err := userRepo.FindByEmail("new@example.com")
+	if err != nil || got.ID != u.ID {
+		t.Fatalf("expected user under new email, got %+v err=%v", got, err)
+	}
+	if cred, _ := credRepo.FindByUserID(u.ID); cred == nil || cred.EmailVerified || cred.EmailVerifiedAt != nil {
+		t.Fatalf("expected local credential to return to unverified, got %+v", cred)
+	}
+	if err := userRepo.UpdateEmail(u.ID+100, "ghost@example.com"); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("expected not found for missing user, got %v", err)
+	}
+}
+
+func TestUserRepositoryDeletionLifecycle(t *testing.T) {
+	db := newRepositoryDBForTest(t)
+	userRepo := NewUserRepository(db)
+	roleRepo := NewRoleRepository(db)
+
+	role := &domain.Role{Name: "user"}
+	if err := roleRepo.Create(role, nil, nil); err != nil {
+		t.Fatalf("create role: %v", err)
+	}
+	u := &domain.User{Email: "leaving@example.com", Name: "Leaving", AvatarURL: "https://img", Status: domain.UserStatusActive}
+	keep := &domain.User{Email: "staying@example.com", Name: "Staying", Status: domain.UserStatusActive}
+	for _, user := range []*domain.User{u, keep} {
+		if err := userRepo.Create(user); err != nil {
+			t.Fatalf("create user: %v", err)
+		}
+		if err := userRepo.AddRole(user.ID, role.ID); err != nil {
+			t.Fatalf("add role: %v", err)
+		}
+	}
+	owned := []any{
+		&domain.LocalCredential{UserID: u.ID, PasswordHash: "hash"},
+		&domain.OAuthAccount{UserID: u.ID, Provider: "google", ProviderUserID: "g-1"},
+		&domain.Session{UserID: u.ID, RefreshTokenHash: "rt-1", ExpiresAt: time.Now().Add(time.Hour)},
+		&domain.VerificationToken{UserID: u.ID, TokenHash: "vt-1", Purpose: "email_verify", ExpiresAt: time.Now().Add(time.Hour)},
+		&domain.APIKey{UserID: u.ID, Name: "ci", Prefix: "pfx", KeyHash: "kh", Scopes: "[]"},
+		&domain.OutboxMessage{UserID: u.ID, Kind: "password_reset", Payload: "{}", Status: domain.OutboxStatusPending, NextAttemptAt: time.Now()},
+		&domain.LoginEvent{UserID: u.ID, Event: domain.LoginEventLogin, Outcome: domain.LoginOutcomeFailure, IP: "198.51.100.7"},
+		&domain.Session{UserID: keep.ID, RefreshTokenHash: "rt-2", ExpiresAt: time.Now().Add(time.Hour)},
+	}
+	for _, row := range owned {
+		if err := db.Create(row).Error; err != nil {
+			t.Fatalf("seed %T: %v", row, err)
+		}
+	}
+
+	now := time.Now().UTC()
+	if err := userRepo.ScheduleDeletion(u.ID, now.Add(time.Hour)); err != nil {
+		t.Fatalf("schedule deletion: %v", err)
+	}
+	if ids, err := userRepo.ListDueForDeletion(now, 10); err != nil || len(ids) != 0 {
+		t.Fatalf("expected nothing due before the grace period ends, got %v err=%v", ids, err)
+	}
+	if err := userRepo.CancelDeletion(u.ID); err != nil {
+		t.Fatalf("cancel deletion: %v", err)
+	}
+	if err := userRepo.CancelDeletion(u.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("expected cancel of an active user to report not found, got %v", err)
+	}
+	if err := userRepo.ScheduleDeletion(u.ID, now.Add(-time.Minute)); err != nil {
+		t.Fatalf("reschedule deletion: %v", err)
+	}
+	ids, err := userRepo.ListDueForDeletion(now, 10)
+	if err != nil || len(ids) != 1 || ids[0] != u.ID {
+		t.Fatalf("expected user to be due, got %v err=%v", ids, err)
+	}
+
+	if err := userRepo.Erase(u.ID); err != nil {
+		t.Fatalf("erase: %v", err)
+	}
+	erased, err := userRepo.FindByID(u.ID)
+	if err != nil {
+		t.Fatalf("find erased user: %v", err)
+	}
+	if erased.Email != domain.ErasedUserEmail(u.ID) || erased.Name != "Deleted user" || erased.AvatarURL != "" ||
+		erased.Status != domain.UserStatusDeleted || erased.DeletionScheduledAt != nil || len(erased.Roles) != 0 {
+		t.Fatalf("expected anonymized user, got %+v", erased)
+	}
+	for _, model := range []any{&domain.LocalCredential{}, &domain.OAuthAccount{}, &domain.Session{}, &domain.VerificationToken{}, &domain.APIKey{}, &domain.OutboxMessage{}, &domain.LoginEvent{}} {
+		var count int64
+		if err := db.Model(model).Where("user_id = ?", u.ID).Count(&count).Error; err != nil || count != 0 {
+			t.Fatalf("expected %T rows to be deleted, count=%d err=%v", model, count, err)
+		}
+	}
+	var kept int64
+	db.Model(&domain.Session{}).Where("user_id = ?", keep.ID).Count(&kept)
+	if kept != 1 {
+		t.Fatalf("expected other users' sessions to survive, got %d", kept)
+	}
+	if other, _ := userRepo.FindByID(keep.ID); other == nil || len(other.Roles) != 1 {
+		t.Fatalf("expected other users' roles to survive, got %+v", other)
+	}
+	if ids, _ := userRepo.ListDueForDeletion(now, 10); len(ids) != 0 {
+		t.Fatalf("expected erased user to leave the queue, got %v", ids)
+	}
+	if err := userRepo.Erase(u.ID + 100); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("expected not found for missing user, got %v", err)
+	}
+}
+
+func TestUserRepositoryRoleBindingExpiry(t *testing.T) {
+	db := newRepositoryDBForTest(t)
+	userRepo := NewUserRepository(db)
+	roleRepo := NewRoleRepository(db)
+
+	permWrite := &domain.Permission{Resource: "users", Action: "write"}
+	if err := db.Create(permWrite).Error; err != nil {
+		t.Fatalf("create permission: %v", err)
+	}
+	base := &domain.Role{Name: "user"}
+	oncall := &domain.Role{Name: "oncall"}
+	lapsed := &domain.Role{Name: "lapsed"}
+	if err := roleRepo.Create(base, nil, nil); err != nil {
+		t.Fatalf("create base role: %v", err)
+	}
+	if err := roleRepo.Create(oncall, []uint{permWrite.ID}, nil); err != nil {
+		t.Fatalf("create oncall role: %v", err)
+	}
+	if err := roleRepo.Create(lapsed, nil, nil); err != nil {
+		t.Fatalf("create lapsed role: %v", err)
+	}
+	u := &domain.User{Email: "oncall@example.com", Name: "On Call", Status: domain.UserStatusActive}
+	if err := userRepo.Create(u); err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+
+	now := time.Now().UTC()
+	shiftEnd := now.Add(time.Hour)
+	if err := userRepo.SetRoleBindings(u.ID, []domain.UserRole{
+		{RoleID: base.ID},
+		{RoleID: oncall.ID, ExpiresAt: &shiftEnd, Reason: "INC-42"},
+		{RoleID: 9999},
+	}); err != nil {
+		t.Fatalf("set role bindings: %v", err)
+	}
+	// Bindings are normally created in the future; backdate one directly to
+	// simulate a lapsed grant.
+	past := now.Add(-time.Minute)
+	if err := db.Create(&domain.UserRole{UserID: u.ID, RoleID: lapsed.ID, ExpiresAt: &past}).Error; err != nil {
+		t.Fatalf("insert lapsed binding: %v", err)
+	}
+
+	found, err := userRepo.FindByID(u.ID)
+	if err != nil {
+		t.Fatalf("find user: %v", err)
+	}
+	if len(found.Roles) != 2 || found.Roles[0].Name != "user" || found.Roles[1].Name != "oncall" || len(found.Roles[1].Permissions) != 1 {
+		t.Fatalf("expected only unexpired roles with permissions, got %+v", found.Roles)
+	}
+	if found.RolesExpireAt == nil || !found.RolesExpireAt.Equal(shiftEnd) {
+		t.Fatalf("expected roles to expire at %v, got %v", shiftEnd, found.RolesExpireAt)
+	}
+	page, err := userRepo.ListPaged(UserListQuery{PageRequest: PageRequest{Page: 1, PageSize: 10}, SortOrder: "asc", Role: "lapsed"})
+	if err != nil || page.Total != 0 {
+		t.Fatalf("expected expired binding to be ignored by role filter, got total=%d err=%v", page.Total, err)
+	}
+
+	expired, err := userRepo.ListExpiredRoleBindings(now, 10)
+	if err != nil {
+		t.Fatalf("list expired: %v", err)
+	}
+	if len(expired) != 1 || expired[0].RoleID != lapsed.ID {
+		t.Fatalf("expected only the lapsed binding, got %+v", expired)
+	}
+	if ok, err := userRepo.DeleteExpiredRoleBinding(u.ID, oncall.ID, now); err != nil || ok {
+		t.Fatalf("expected unexpired binding to survive, got deleted=%v err=%v", ok, err)
+	}
+	if ok, err := userRepo.DeleteExpiredRoleBinding(u.ID, lapsed.ID, now); err != nil || !ok {
+		t.Fatalf("expected lapsed binding to be deleted, got deleted=%v err=%v", ok, err)
+	}
+
+	// Replacing the set keeps the binding but drops its expiry.
+	if err := userRepo.SetRoles(u.ID, []uint{oncall.ID}); err != nil {
+		t.Fatalf("set roles: %v", err)
+	}
+	var binding domain.UserRole
+	if err := db.Where("user_id = ? AND role_id = ?", u.ID, oncall.ID).First(&binding).Error; err != nil {
+		t.Fatalf("load binding: %v", err)
+	}
+	if binding.ExpiresAt != nil || binding.Reason != "" {
+		t.Fatalf("expected permanent binding after SetRoles, got %+v", binding)
+	}
+	var count int64
+	if err := db.Model(&domain.UserRole{}).Where("user_id = ?", u.ID).Count(&count).Error; err != nil || count != 1 {
+		t.Fatalf("expected one binding left, got %d err=%v", count, err)
+	}
+}
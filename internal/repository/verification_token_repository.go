@@ -12,6 +12,7 @@ var ErrVerificationTokenNotFound = errors.New("verification token not found")
 
 type VerificationTokenRepository interface {
 	Create(token *domain.VerificationToken) error
+	CreateWithOutbox(token *domain.VerificationToken, msg *domain.OutboxMessage) error
 	InvalidateActiveByUserPurpose(userID uint, purpose string, now time.Time) error
 	FindActiveByHashPurpose(hash, purpose string, now time.Time) (*domain.VerificationToken, error)
 	Consume(tokenID, userID uint, now time.Time) error
@@ -29,6 +30,17 @@ func (r *GormVerificationTokenRepository) Create(token *domain.VerificationToken
 	return r.db.Create(token).Error
 }
 
+// CreateWithOutbox stores the token and the notification that carries it in
+// one transaction, so a token is never issued without its mail queued.
+func (r *GormVerificationTokenRepository) CreateWithOutbox(token *domain.VerificationToken, msg *domain.OutboxMessage) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(token).Error; err != nil {
+			return err
+		}
+		return tx.Create(msg).Error
+	})
+}
+
 func (r *GormVerificationTokenRepository) InvalidateActiveByUserPurpose(userID uint, purpose string, now time.Time) error {
 	return r.db.Model(&domain.VerificationToken{}).
 		Where("user_id = ? AND purpose = ? AND used_at IS NULL AND expires_at > ?", userID, purpose, now).
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+var ErrWebAuthnCredentialNotFound = errors.New("webauthn credential not found")
+
+type WebAuthnCredentialRepository interface {
+	Create(cred *domain.WebAuthnCredential) error
+	ListByUserID(userID uint) ([]domain.WebAuthnCredential, error)
+	FindByCredentialID(credentialID string) (*domain.WebAuthnCredential, error)
+	UpdateUsage(id uint, signCount uint32, flags uint8, usedAt time.Time) error
+	DeleteForUser(id, userID uint) error
+}
+
+type GormWebAuthnCredentialRepository struct {
+	db *gorm.DB
+}
+
+func NewWebAuthnCredentialRepository(db *gorm.DB) WebAuthnCredentialRepository {
+	return &GormWebAuthnCredentialRepository{db: db}
+}
+
+func (r *GormWebAuthnCredentialRepository) Create(cred *domain.WebAuthnCredential) error {
+	return r.db.Create(cred).Error
+}
+
+func (r *GormWebAuthnCredentialRepository) ListByUserID(userID uint) ([]domain.WebAuthnCredential, error) {
+	var creds []domain.WebAuthnCredential
+	err := r.db.Where("user_id = ?", userID).Order("id ASC").Find(&creds).Error
+	return creds, err
+}
+
+func (r *GormWebAuthnCredentialRepository) FindByCredentialID(credentialID string) (*domain.WebAuthnCredential, error) {
+	var c domain.WebAuthnCredential
+	if err := r.db.Where("credential_id = ?", credentialID).First(&c).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrWebAuthnCredentialNotFound
+		}
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (r *GormWebAuthnCredentialRepository) UpdateUsage(id uint, signCount uint32, flags uint8, usedAt time.Time) error {
+	res := r.db.Model(&domain.WebAuthnCredential{}).
+		Where("id = ?", id).
+		Updates(map[string]any{"sign_count": signCount, "flags": flags, "last_used_at": usedAt, "updated_at": usedAt})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrWebAuthnCredentialNotFound
+	}
+	return nil
+}
+
+// DeleteForUser scopes the delete to the owner so one user cannot remove
+// another user's passkey by guessing its row ID.
+func (r *GormWebAuthnCredentialRepository) DeleteForUser(id, userID uint) error {
+	res := r.db.Where("id = ? AND user_id = ?", id, userID).Delete(&domain.WebAuthnCredential{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrWebAuthnCredentialNotFound
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/domain"
+)
+
+func TestWebAuthnCredentialRepositoryLifecycle(t *testing.T) {
+	db := newRepositoryDBForTest(t)
+	repo := NewWebAuthnCredentialRepository(db)
+
+	if _, err := repo.FindByCredentialID("missing"); !errors.Is(err, ErrWebAuthnCredentialNotFound) {
+		t.Fatalf("expected ErrWebAuthnCredentialNotFound, got %v", err)
+	}
+	cred := &domain.WebAuthnCredential{UserID: 3, CredentialID: "cred-1", PublicKey: []byte{1, 2, 3}, Name: "laptop"}
+	if err := repo.Create(cred); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if err := repo.Create(&domain.WebAuthnCredential{UserID: 4, CredentialID: "cred-1", PublicKey: []byte{4}}); err == nil {
+		t.Fatal("expected duplicate credential id to fail")
+	}
+
+	now := time.Now().UTC()
+	if err := repo.UpdateUsage(cred.ID, 7, 0x05, now); err != nil {
+		t.Fatalf("update usage: %v", err)
+	}
+	found, err := repo.FindByCredentialID("cred-1")
+	if err != nil || found.SignCount != 7 || found.Flags != 0x05 || found.LastUsedAt == nil {
+		t.Fatalf("expected updated usage, got %+v err=%v", found, err)
+	}
+	list, err := repo.ListByUserID(3)
+	if err != nil || len(list) != 1 {
+		t.Fatalf("expected one credential, got %d err=%v", len(list), err)
+	}
+
+	if err := repo.DeleteForUser(cred.ID, 4); !errors.Is(err, ErrWebAuthnCredentialNotFound) {
+		t.Fatalf("expected delete by other user to fail, got %v", err)
+	}
+	if err := repo.DeleteForUser(cred.ID, 3); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if list, _ := repo.ListByUserID(3); len(list) != 0 {
+		t.Fatalf("expected no credentials after delete, got %d", len(list))
+	}
+}
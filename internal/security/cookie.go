@@ -29,6 +29,12 @@ func (c *CookieManager) SetTokenCookies(w http.ResponseWriter, accessToken, refr
 	http.SetCookie(w, &http.Cookie{Name: "csrf_token", Value: csrf, Path: "/", HttpOnly: false, Secure: c.Secure, SameSite: c.SameSite, Domain: c.Domain, MaxAge: int(refreshTTL.Seconds())})
 }
 
+// SetAccessTokenCookie replaces only the access token, leaving the refresh
+// and CSRF cookies of the signed-in session in place.
+func (c *CookieManager) SetAccessTokenCookie(w http.ResponseWriter, accessToken string, ttl time.Duration) {
+	http.SetCookie(w, &http.Cookie{Name: "access_token", Value: accessToken, Path: "/", HttpOnly: true, Secure: c.Secure, SameSite: c.SameSite, Domain: c.Domain, MaxAge: int(ttl.Seconds())})
+}
+
 func (c *CookieManager) ClearTokenCookies(w http.ResponseWriter) {
 	clear := func(name, path string, httpOnly bool) {
 		http.SetCookie(w, &http.Cookie{Name: name, Path: path, Value: "", MaxAge: -1, HttpOnly: httpOnly, Secure: c.Secure, SameSite: c.SameSite, Domain: c.Domain})
@@ -9,18 +9,58 @@ import (
 	"github.com/google/uuid"
 )
 
+// PrincipalServiceAccount marks access tokens minted through the
+// client_credentials grant. Their subject is not a user ID.
+const PrincipalServiceAccount = "service_account"
+
 type Claims struct {
-	TokenType   string   `json:"token_type"`
-	Roles       []string `json:"roles,omitempty"`
-	Permissions []string `json:"permissions,omitempty"`
+	TokenType     string   `json:"token_type"`
+	PrincipalType string   `json:"principal_type,omitempty"`
+	ClientID      string   `json:"client_id,omitempty"`
+	Roles         []string `json:"roles,omitempty"`
+	Permissions   []string `json:"permissions,omitempty"`
+	Act           *Actor   `json:"act,omitempty"`
+	// AuthTime is when the user last proved a credential for this session
+	// (OIDC auth_time). It survives refresh and is bumped by reauthentication.
+	AuthTime *jwt.NumericDate `json:"auth_time,omitempty"`
 	jwt.RegisteredClaims
 }
 
+// Actor is the RFC 8693 "act" claim: the party acting on behalf of the
+// token's subject. It is only set on impersonation tokens.
+type Actor struct {
+	Subject string `json:"sub"`
+}
+
+func (c *Claims) IsServiceAccount() bool {
+	return c != nil && c.PrincipalType == PrincipalServiceAccount
+}
+
+func (c *Claims) IsImpersonated() bool {
+	return c != nil && c.Act != nil
+}
+
+// AuthenticatedAt reports the auth_time claim; tokens minted before the claim
+// existed, API keys and impersonation tokens have none.
+func (c *Claims) AuthenticatedAt() (time.Time, bool) {
+	if c == nil || c.AuthTime == nil {
+		return time.Time{}, false
+	}
+	return c.AuthTime.Time, true
+}
+
+// ServiceAccountSubject keeps service account subjects out of the numeric
+// user ID space so user-only code paths reject them.
+func ServiceAccountSubject(accountID uint) string {
+	return fmt.Sprintf("%s:%d", PrincipalServiceAccount, accountID)
+}
+
 type JWTManager struct {
 	issuer        string
 	audience      string
 	accessSecret  []byte
 	refreshSecret []byte
+	keyring       *Keyring
 }
 
 func NewJWTManager(issuer, audience, accessSecret, refreshSecret string) *JWTManager {
@@ -32,11 +72,28 @@ func NewJWTManager(issuer, audience, accessSecret, refreshSecret string) *JWTMan
 	}
 }
 
+// NewJWTManagerWithKeyring signs access tokens with the keyring's active
+// asymmetric key; refresh tokens never leave this service and stay HS256.
+func NewJWTManagerWithKeyring(issuer, audience, refreshSecret string, keyring *Keyring) *JWTManager {
+	return &JWTManager{
+		issuer:        issuer,
+		audience:      audience,
+		refreshSecret: []byte(refreshSecret),
+		keyring:       keyring,
+	}
+}
+
+func (m *JWTManager) Keyring() *Keyring {
+	return m.keyring
+}
+
 func (m *JWTManager) SignAccessToken(userID uint, roles, perms []string, ttl time.Duration) (string, error) {
-	return m.SignAccessTokenWithJTI(userID, roles, perms, ttl, uuid.NewString())
+	return m.SignAccessTokenWithJTI(userID, roles, perms, ttl, uuid.NewString(), time.Now())
 }
 
-func (m *JWTManager) SignAccessTokenWithJTI(userID uint, roles, perms []string, ttl time.Duration, jti string) (string, error) {
+// SignAccessTokenWithJTI signs a session access token; a zero authTime
+// leaves the auth_time claim out.
+func (m *JWTManager) SignAccessTokenWithJTI(userID uint, roles, perms []string, ttl time.Duration, jti string, authTime time.Time) (string, error) {
 	if jti == "" {
 		jti = uuid.NewString()
 	}
@@ -44,6 +101,7 @@ func (m *JWTManager) SignAccessTokenWithJTI(userID uint, roles, perms []string,
 		TokenType:   "access",
 		Roles:       roles,
 		Permissions: perms,
+		AuthTime:    numericDateOrNil(authTime),
 		RegisteredClaims: jwt.RegisteredClaims{
 			Issuer:    m.issuer,
 			Subject:   fmt.Sprintf("%d", userID),
@@ -53,7 +111,74 @@ func (m *JWTManager) SignAccessTokenWithJTI(userID uint, roles, perms []string,
 			ID:        jti,
 		},
 	}
-	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(m.accessSecret)
+	return m.signAccess(claims)
+}
+
+// SignImpersonationToken mints an access token for userID that names
+// actorID in its act claim. The jti is the impersonation session's TokenID.
+func (m *JWTManager) SignImpersonationToken(userID, actorID uint, roles, perms []string, ttl time.Duration, jti string) (string, error) {
+	now := time.Now()
+	claims := Claims{
+		TokenType:   "access",
+		Roles:       roles,
+		Permissions: perms,
+		Act:         &Actor{Subject: fmt.Sprintf("%d", actorID)},
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    m.issuer,
+			Subject:   fmt.Sprintf("%d", userID),
+			Audience:  []string{m.audience},
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ID:        jti,
+		},
+	}
+	return m.signAccess(claims)
+}
+
+// SignServiceAccountToken mints an access token for a service account. The
+// roles and permissions are fixed at issuance.
+func (m *JWTManager) SignServiceAccountToken(accountID uint, clientID string, roles, perms []string, ttl time.Duration) (string, error) {
+	now := time.Now()
+	claims := Claims{
+		TokenType:     "access",
+		PrincipalType: PrincipalServiceAccount,
+		ClientID:      clientID,
+		Roles:         roles,
+		Permissions:   perms,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    m.issuer,
+			Subject:   ServiceAccountSubject(accountID),
+			Audience:  []string{m.audience},
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ID:        uuid.NewString(),
+		},
+	}
+	return m.signAccess(claims)
+}
+
+func numericDateOrNil(t time.Time) *jwt.NumericDate {
+	if t.IsZero() {
+		return nil
+	}
+	return jwt.NewNumericDate(t)
+}
+
+func (m *JWTManager) signAccess(claims Claims) (string, error) {
+	if m.keyring == nil {
+		return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(m.accessSecret)
+	}
+	key, ok := m.keyring.Active()
+	if !ok {
+		return "", ErrNoActiveSigningKey
+	}
+	method, err := signingMethod(key.Algorithm)
+	if err != nil {
+		return "", err
+	}
+	tok := jwt.NewWithClaims(method, claims)
+	tok.Header["kid"] = key.KID
+	return tok.SignedString(key.Private)
 }
 
 func (m *JWTManager) SignRefreshToken(userID uint, ttl time.Duration) (string, error) {
@@ -72,21 +197,40 @@ func (m *JWTManager) SignRefreshToken(userID uint, ttl time.Duration) (string, e
 }
 
 func (m *JWTManager) ParseAccessToken(raw string) (*Claims, error) {
-	return m.parse(raw, m.accessSecret, "access")
+	if m.keyring == nil {
+		return m.parse(raw, hmacKeyFunc(m.accessSecret), "access")
+	}
+	return m.parse(raw, m.keyringKeyFunc, "access")
 }
 
 func (m *JWTManager) ParseRefreshToken(raw string) (*Claims, error) {
-	return m.parse(raw, m.refreshSecret, "refresh")
+	return m.parse(raw, hmacKeyFunc(m.refreshSecret), "refresh")
 }
 
-func (m *JWTManager) parse(raw string, secret []byte, tokenType string) (*Claims, error) {
-	claims := &Claims{}
-	tok, err := jwt.ParseWithClaims(raw, claims, func(token *jwt.Token) (any, error) {
+func hmacKeyFunc(secret []byte) jwt.Keyfunc {
+	return func(token *jwt.Token) (any, error) {
 		if token.Method != jwt.SigningMethodHS256 {
 			return nil, errors.New("unexpected signing algorithm")
 		}
 		return secret, nil
-	}, jwt.WithIssuer(m.issuer), jwt.WithAudience(m.audience))
+	}
+}
+
+func (m *JWTManager) keyringKeyFunc(token *jwt.Token) (any, error) {
+	kid, _ := token.Header["kid"].(string)
+	key, ok := m.keyring.Lookup(kid)
+	if !ok {
+		return nil, ErrUnknownSigningKey
+	}
+	if token.Method.Alg() != key.Algorithm {
+		return nil, errors.New("unexpected signing algorithm")
+	}
+	return key.Private.Public(), nil
+}
+
+func (m *JWTManager) parse(raw string, keyFunc jwt.Keyfunc, tokenType string) (*Claims, error) {
+	claims := &Claims{}
+	tok, err := jwt.ParseWithClaims(raw, claims, keyFunc, jwt.WithIssuer(m.issuer), jwt.WithAudience(m.audience))
 	if err != nil {
 		return nil, err
 	}
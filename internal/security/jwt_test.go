@@ -28,6 +28,64 @@ func TestJWTAccessAndRefreshParsing(t *testing.T) {
 	}
 }
 
+func TestJWTServiceAccountTokenCarriesPrincipalType(t *testing.T) {
+	mgr := NewJWTManager("iss", "aud", "abcdefghijklmnopqrstuvwxyz123456", "abcdefghijklmnopqrstuvwxyz654321")
+	raw, err := mgr.SignServiceAccountToken(7, "sa_client", []string{"reporter"}, []string{"users:read"}, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, err := mgr.ParseAccessToken(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !claims.IsServiceAccount() || claims.Subject != "service_account:7" || claims.ClientID != "sa_client" {
+		t.Fatalf("unexpected service account claims: %+v", claims)
+	}
+	user, _ := mgr.SignAccessToken(7, nil, nil, time.Minute)
+	if uc, _ := mgr.ParseAccessToken(user); uc.IsServiceAccount() {
+		t.Fatal("expected user token not to be a service account")
+	}
+}
+
+func TestJWTImpersonationTokenCarriesActor(t *testing.T) {
+	mgr := NewJWTManager("iss", "aud", "abcdefghijklmnopqrstuvwxyz123456", "abcdefghijklmnopqrstuvwxyz654321")
+	raw, err := mgr.SignImpersonationToken(42, 7, []string{"user"}, nil, time.Minute, "imp-jti")
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, err := mgr.ParseAccessToken(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !claims.IsImpersonated() || claims.Act.Subject != "7" || claims.Subject != "42" || claims.ID != "imp-jti" {
+		t.Fatalf("unexpected impersonation claims: %+v", claims)
+	}
+	user, _ := mgr.SignAccessToken(42, nil, nil, time.Minute)
+	if uc, _ := mgr.ParseAccessToken(user); uc.IsImpersonated() {
+		t.Fatal("expected a regular token not to be impersonated")
+	}
+}
+
+func TestJWTAccessTokenCarriesAuthTime(t *testing.T) {
+	mgr := NewJWTManager("iss", "aud", "abcdefghijklmnopqrstuvwxyz123456", "abcdefghijklmnopqrstuvwxyz654321")
+	authTime := time.Now().Add(-time.Hour)
+	raw, err := mgr.SignAccessTokenWithJTI(42, nil, nil, time.Minute, "jti-1", authTime)
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, err := mgr.ParseAccessToken(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, ok := claims.AuthenticatedAt(); !ok || got.Unix() != authTime.Unix() {
+		t.Fatalf("expected auth_time %v, got %v ok=%v", authTime, got, ok)
+	}
+	raw, _ = mgr.SignAccessTokenWithJTI(42, nil, nil, time.Minute, "jti-2", time.Time{})
+	if claims, _ := mgr.ParseAccessToken(raw); claims.AuthTime != nil {
+		t.Fatalf("expected no auth_time for a zero time, got %v", claims.AuthTime)
+	}
+}
+
 func FuzzParseAccessTokenRobustness(f *testing.F) {
 	mgr := NewJWTManager("iss", "aud", "abcdefghijklmnopqrstuvwxyz123456", "abcdefghijklmnopqrstuvwxyz654321")
 	validAccess, _ := mgr.SignAccessToken(42, []string{"admin"}, []string{"users:read"}, time.Minute)
@@ -0,0 +1,223 @@
+package security
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	SigningAlgHS256 = "HS256"
+	SigningAlgRS256 = "RS256"
+	SigningAlgES256 = "ES256"
+	SigningAlgEdDSA = "EdDSA"
+)
+
+var (
+	ErrNoActiveSigningKey       = errors.New("no active signing key")
+	ErrUnknownSigningKey        = errors.New("unknown signing key")
+	ErrUnsupportedSigningMethod = errors.New("unsupported signing algorithm")
+)
+
+// SigningKey is one asymmetric access-token key. A key signs only once
+// ActivatesAt has passed and verifies until RetiresAt, which lets new keys be
+// published before use and old keys outlive the tokens they signed.
+type SigningKey struct {
+	KID         string
+	Algorithm   string
+	Private     crypto.Signer
+	ActivatesAt time.Time
+	RetiresAt   *time.Time
+}
+
+func (k SigningKey) retired(now time.Time) bool {
+	return k.RetiresAt != nil && !now.Before(*k.RetiresAt)
+}
+
+// Keyring holds the asymmetric access-token keys loaded for this process.
+type Keyring struct {
+	mu   sync.RWMutex
+	keys []SigningKey
+	now  func() time.Time
+}
+
+func NewKeyring(keys ...SigningKey) *Keyring {
+	k := &Keyring{now: time.Now}
+	k.Replace(keys)
+	return k
+}
+
+func (k *Keyring) Replace(keys []SigningKey) {
+	sorted := append([]SigningKey(nil), keys...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].ActivatesAt.Before(sorted[j].ActivatesAt)
+	})
+	k.mu.Lock()
+	k.keys = sorted
+	k.mu.Unlock()
+}
+
+// Active returns the most recently activated key that has not retired.
+func (k *Keyring) Active() (SigningKey, bool) {
+	if k == nil {
+		return SigningKey{}, false
+	}
+	now := k.now()
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	for i := len(k.keys) - 1; i >= 0; i-- {
+		key := k.keys[i]
+		if !key.ActivatesAt.After(now) && !key.retired(now) {
+			return key, true
+		}
+	}
+	return SigningKey{}, false
+}
+
+func (k *Keyring) Lookup(kid string) (SigningKey, bool) {
+	if k == nil {
+		return SigningKey{}, false
+	}
+	now := k.now()
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	for _, key := range k.keys {
+		if key.KID == kid && !key.retired(now) {
+			return key, true
+		}
+	}
+	return SigningKey{}, false
+}
+
+type JWK struct {
+	KTY string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	KID string `json:"kid"`
+	Crv string `json:"crv,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS publishes every key that can still verify tokens, including keys that
+// are scheduled to activate, so verifiers learn about them ahead of time.
+func (k *Keyring) JWKS() JWKSet {
+	set := JWKSet{Keys: []JWK{}}
+	if k == nil {
+		return set
+	}
+	now := k.now()
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	for _, key := range k.keys {
+		if key.retired(now) {
+			continue
+		}
+		jwk, err := publicJWK(key)
+		if err != nil {
+			continue
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set
+}
+
+func publicJWK(key SigningKey) (JWK, error) {
+	out := JWK{Use: "sig", Alg: key.Algorithm, KID: key.KID}
+	b64 := base64.RawURLEncoding.EncodeToString
+	switch pub := key.Private.Public().(type) {
+	case *rsa.PublicKey:
+		out.KTY = "RSA"
+		out.N = b64(pub.N.Bytes())
+		out.E = b64(big.NewInt(int64(pub.E)).Bytes())
+	case *ecdsa.PublicKey:
+		ecdhPub, err := pub.ECDH()
+		if err != nil {
+			return JWK{}, err
+		}
+		raw := ecdhPub.Bytes()
+		size := (len(raw) - 1) / 2
+		out.KTY = "EC"
+		out.Crv = pub.Curve.Params().Name
+		out.X = b64(raw[1 : 1+size])
+		out.Y = b64(raw[1+size:])
+	case ed25519.PublicKey:
+		out.KTY = "OKP"
+		out.Crv = "Ed25519"
+		out.X = b64(pub)
+	default:
+		return JWK{}, ErrUnsupportedSigningMethod
+	}
+	return out, nil
+}
+
+func GenerateSigningKey(alg string) (crypto.Signer, error) {
+	switch alg {
+	case SigningAlgRS256:
+		return rsa.GenerateKey(rand.Reader, 3072)
+	case SigningAlgES256:
+		return ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case SigningAlgEdDSA:
+		_, priv, err := ed25519.GenerateKey(rand.Reader)
+		return priv, err
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedSigningMethod, alg)
+	}
+}
+
+func MarshalSigningKeyPEM(key crypto.Signer) (string, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return "", err
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})), nil
+}
+
+func ParseSigningKeyPEM(raw string) (crypto.Signer, error) {
+	block, _ := pem.Decode([]byte(raw))
+	if block == nil {
+		return nil, errors.New("invalid signing key pem")
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, ErrUnsupportedSigningMethod
+	}
+	return signer, nil
+}
+
+func signingMethod(alg string) (jwt.SigningMethod, error) {
+	switch alg {
+	case SigningAlgRS256:
+		return jwt.SigningMethodRS256, nil
+	case SigningAlgES256:
+		return jwt.SigningMethodES256, nil
+	case SigningAlgEdDSA:
+		return jwt.SigningMethodEdDSA, nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedSigningMethod, alg)
+	}
+}
@@ -0,0 +1,136 @@
+package security
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestSigningKey(t *testing.T, kid, alg string, activatesAt time.Time) SigningKey {
+	t.Helper()
+	priv, err := GenerateSigningKey(alg)
+	if err != nil {
+		t.Fatalf("generate %s key: %v", alg, err)
+	}
+	return SigningKey{KID: kid, Algorithm: alg, Private: priv, ActivatesAt: activatesAt}
+}
+
+func TestJWTManagerKeyringSignsWithKIDAndVerifiesEachAlgorithm(t *testing.T) {
+	for _, alg := range []string{SigningAlgRS256, SigningAlgES256, SigningAlgEdDSA} {
+		t.Run(alg, func(t *testing.T) {
+			keyring := NewKeyring(newTestSigningKey(t, "k1", alg, time.Now().Add(-time.Minute)))
+			mgr := NewJWTManagerWithKeyring("iss", "aud", "abcdefghijklmnopqrstuvwxyz654321", keyring)
+
+			access, err := mgr.SignAccessToken(42, []string{"admin"}, nil, time.Minute)
+			if err != nil {
+				t.Fatalf("sign access: %v", err)
+			}
+			claims, err := mgr.ParseAccessToken(access)
+			if err != nil || claims.Subject != "42" {
+				t.Fatalf("parse access: claims=%+v err=%v", claims, err)
+			}
+
+			refresh, err := mgr.SignRefreshToken(42, time.Minute)
+			if err != nil {
+				t.Fatalf("sign refresh: %v", err)
+			}
+			if _, err := mgr.ParseRefreshToken(refresh); err != nil {
+				t.Fatalf("parse refresh: %v", err)
+			}
+			if _, err := mgr.ParseAccessToken(refresh); err == nil {
+				t.Fatal("expected HS256 refresh token to fail access parse")
+			}
+
+			jwks := keyring.JWKS()
+			if len(jwks.Keys) != 1 || jwks.Keys[0].KID != "k1" || jwks.Keys[0].Alg != alg || jwks.Keys[0].Use != "sig" {
+				t.Fatalf("unexpected jwks: %+v", jwks)
+			}
+		})
+	}
+}
+
+func TestKeyringRotationKeepsLiveTokensValid(t *testing.T) {
+	now := time.Now()
+	oldKey := newTestSigningKey(t, "old", SigningAlgES256, now.Add(-time.Hour))
+	keyring := NewKeyring(oldKey)
+	mgr := NewJWTManagerWithKeyring("iss", "aud", "abcdefghijklmnopqrstuvwxyz654321", keyring)
+	issuedByOld, err := mgr.SignAccessToken(1, nil, nil, time.Hour)
+	if err != nil {
+		t.Fatalf("sign with old key: %v", err)
+	}
+
+	retireAt := now.Add(30 * time.Minute)
+	oldKey.RetiresAt = &retireAt
+	nextKey := newTestSigningKey(t, "next", SigningAlgRS256, now.Add(time.Minute))
+	keyring.Replace([]SigningKey{nextKey, oldKey})
+	if active, ok := keyring.Active(); !ok || active.KID != "old" {
+		t.Fatalf("expected old key to keep signing until the next key activates, got %+v", active)
+	}
+	if n := len(keyring.JWKS().Keys); n != 2 {
+		t.Fatalf("expected scheduled key to be published early, got %d keys", n)
+	}
+
+	keyring.now = func() time.Time { return now.Add(2 * time.Minute) }
+	if active, ok := keyring.Active(); !ok || active.KID != "next" {
+		t.Fatalf("expected next key to sign after activation, got %+v", active)
+	}
+	issuedByNext, err := mgr.SignAccessToken(1, nil, nil, time.Hour)
+	if err != nil {
+		t.Fatalf("sign with next key: %v", err)
+	}
+	for _, tok := range []string{issuedByOld, issuedByNext} {
+		if _, err := mgr.ParseAccessToken(tok); err != nil {
+			t.Fatalf("expected token to verify during overlap: %v", err)
+		}
+	}
+
+	keyring.now = func() time.Time { return retireAt.Add(time.Second) }
+	if _, err := mgr.ParseAccessToken(issuedByOld); err == nil || !errors.Is(err, ErrUnknownSigningKey) {
+		t.Fatalf("expected retired key to stop verifying, got %v", err)
+	}
+	if n := len(keyring.JWKS().Keys); n != 1 {
+		t.Fatalf("expected retired key to be unpublished, got %d keys", n)
+	}
+}
+
+func TestKeyringRejectsAlgorithmMismatchAndEmptyRing(t *testing.T) {
+	keyring := NewKeyring(newTestSigningKey(t, "k1", SigningAlgRS256, time.Now().Add(-time.Minute)))
+	hmacMgr := NewJWTManager("iss", "aud", "abcdefghijklmnopqrstuvwxyz123456", "abcdefghijklmnopqrstuvwxyz654321")
+	forged, err := hmacMgr.SignAccessToken(1, nil, nil, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mgr := NewJWTManagerWithKeyring("iss", "aud", "abcdefghijklmnopqrstuvwxyz654321", keyring)
+	if _, err := mgr.ParseAccessToken(forged); err == nil {
+		t.Fatal("expected HS256 access token to be rejected by keyring manager")
+	}
+
+	empty := NewJWTManagerWithKeyring("iss", "aud", "abcdefghijklmnopqrstuvwxyz654321", NewKeyring())
+	if _, err := empty.SignAccessToken(1, nil, nil, time.Minute); !errors.Is(err, ErrNoActiveSigningKey) {
+		t.Fatalf("expected ErrNoActiveSigningKey, got %v", err)
+	}
+}
+
+func TestSigningKeyPEMRoundTrip(t *testing.T) {
+	priv, err := GenerateSigningKey(SigningAlgEdDSA)
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, err := MarshalSigningKeyPEM(priv)
+	if err != nil || !strings.Contains(raw, "PRIVATE KEY") {
+		t.Fatalf("marshal pem: %q err=%v", raw, err)
+	}
+	parsed, err := ParseSigningKeyPEM(raw)
+	if err != nil {
+		t.Fatalf("parse pem: %v", err)
+	}
+	a, _ := publicJWK(SigningKey{Algorithm: SigningAlgEdDSA, Private: priv})
+	b, _ := publicJWK(SigningKey{Algorithm: SigningAlgEdDSA, Private: parsed})
+	if a.X == "" || a.X != b.X {
+		t.Fatalf("expected round-tripped key to match: %+v %+v", a, b)
+	}
+	if _, err := GenerateSigningKey("PS512"); !errors.Is(err, ErrUnsupportedSigningMethod) {
+		t.Fatalf("expected unsupported algorithm error, got %v", err)
+	}
+}
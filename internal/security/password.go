@@ -4,11 +4,14 @@ import (
 	"crypto/rand"
 	"crypto/subtle"
 	"encoding/base64"
+	"errors"
 	"fmt"
 	"math"
 	"strings"
 
 	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/crypto/scrypt"
 )
 
 const (
@@ -17,6 +20,17 @@ const (
 	argonThreads uint8  = 2
 	argonKeyLen  uint32 = 32
 	argonSaltLen        = 16
+
+	// Upper bounds for imported scrypt hashes so a malformed row cannot make
+	// a single login allocate gigabytes.
+	scryptMaxLogN   = 20
+	scryptMaxMemory = 1 << 30
+)
+
+const (
+	PasswordAlgorithmArgon2id = "argon2id"
+	PasswordAlgorithmBcrypt   = "bcrypt"
+	PasswordAlgorithmScrypt   = "scrypt"
 )
 
 func HashPassword(password string) (string, error) {
@@ -31,7 +45,63 @@ func HashPassword(password string) (string, error) {
 		base64.RawStdEncoding.EncodeToString(hash)), nil
 }
 
+// VerifyPassword checks password against an encoded hash. Besides the
+// argon2id hashes written by HashPassword it accepts bcrypt ($2a$, $2b$,
+// $2y$) and scrypt ($scrypt$ln=..,r=..,p=..$salt$hash) hashes imported from
+// other systems; callers upgrade those with PasswordNeedsRehash. An empty
+// hash marks a credential whose password has not been set yet and never
+// matches.
 func VerifyPassword(encoded, password string) (bool, error) {
+	if encoded == "" {
+		return false, nil
+	}
+	switch PasswordHashAlgorithm(encoded) {
+	case PasswordAlgorithmArgon2id:
+		return verifyArgon2id(encoded, password)
+	case PasswordAlgorithmBcrypt:
+		err := bcrypt.CompareHashAndPassword([]byte(encoded), []byte(password))
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			return false, nil
+		}
+		return err == nil, err
+	case PasswordAlgorithmScrypt:
+		return verifyScrypt(encoded, password)
+	default:
+		return false, fmt.Errorf("invalid password hash format")
+	}
+}
+
+// PasswordHashAlgorithm names the scheme of an encoded hash, or returns ""
+// when it is not one VerifyPassword understands.
+func PasswordHashAlgorithm(encoded string) string {
+	switch {
+	case strings.HasPrefix(encoded, "$argon2id$"):
+		return PasswordAlgorithmArgon2id
+	case strings.HasPrefix(encoded, "$2a$"), strings.HasPrefix(encoded, "$2b$"), strings.HasPrefix(encoded, "$2y$"):
+		return PasswordAlgorithmBcrypt
+	case strings.HasPrefix(encoded, "$scrypt$"):
+		return PasswordAlgorithmScrypt
+	default:
+		return ""
+	}
+}
+
+// PasswordNeedsRehash reports whether encoded should be replaced by a fresh
+// HashPassword result: it is not argon2id, or it was produced with
+// parameters other than the current ones.
+func PasswordNeedsRehash(encoded string) bool {
+	if PasswordHashAlgorithm(encoded) != PasswordAlgorithmArgon2id {
+		return true
+	}
+	memory, timeCost, threads, salt, hash, err := decodeHash(encoded)
+	if err != nil {
+		return true
+	}
+	return memory != argonMemory || timeCost != argonTime || threads != argonThreads ||
+		len(salt) != argonSaltLen || uint64(len(hash)) != uint64(argonKeyLen)
+}
+
+func verifyArgon2id(encoded, password string) (bool, error) {
 	memory, timeCost, threads, salt, expected, err := decodeHash(encoded)
 	if err != nil {
 		return false, err
@@ -46,6 +116,40 @@ func VerifyPassword(encoded, password string) (bool, error) {
 	return subtle.ConstantTimeCompare(actual, expected) == 1, nil
 }
 
+// verifyScrypt accepts the passlib layout, $scrypt$ln=<log2 N>,r=<r>,p=<p>$
+// followed by salt and key. Both may use standard or passlib's adapted
+// base64 ("." for "+"), padded or not.
+func verifyScrypt(encoded, password string) (bool, error) {
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 5 || parts[1] != "scrypt" {
+		return false, fmt.Errorf("invalid password hash format")
+	}
+	var logN, r, p int
+	if _, err := fmt.Sscanf(parts[2], "ln=%d,r=%d,p=%d", &logN, &r, &p); err != nil {
+		return false, fmt.Errorf("invalid hash params")
+	}
+	if logN < 1 || logN > scryptMaxLogN || r < 1 || p < 1 || 128*r*p > scryptMaxMemory || (128*r)<<logN > scryptMaxMemory {
+		return false, fmt.Errorf("invalid hash params")
+	}
+	salt, err := decodeScryptBase64(parts[3])
+	if err != nil {
+		return false, fmt.Errorf("invalid hash salt")
+	}
+	expected, err := decodeScryptBase64(parts[4])
+	if err != nil || len(expected) == 0 {
+		return false, fmt.Errorf("invalid hash payload")
+	}
+	actual, err := scrypt.Key([]byte(password), salt, 1<<logN, r, p, len(expected))
+	if err != nil {
+		return false, err
+	}
+	return subtle.ConstantTimeCompare(actual, expected) == 1, nil
+}
+
+func decodeScryptBase64(s string) ([]byte, error) {
+	return base64.RawStdEncoding.DecodeString(strings.ReplaceAll(strings.TrimRight(s, "="), ".", "+"))
+}
+
 func decodeHash(encoded string) (memory uint32, timeCost uint32, threads uint8, salt, hash []byte, err error) {
 	parts := strings.Split(encoded, "$")
 	if len(parts) != 6 || parts[1] != "argon2id" || parts[2] != "v=19" {
@@ -1,6 +1,15 @@
 package security
 
-import "testing"
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/crypto/scrypt"
+)
 
 func TestHashAndVerifyPassword(t *testing.T) {
 	hash, err := HashPassword("Stronger#Pass123")
@@ -22,3 +31,62 @@ func TestHashAndVerifyPassword(t *testing.T) {
 		t.Fatal("expected password verification failure")
 	}
 }
+
+func TestVerifyPasswordAcceptsImportedHashes(t *testing.T) {
+	bcryptHash, err := bcrypt.GenerateFromPassword([]byte("Legacy#Pass123"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("bcrypt: %v", err)
+	}
+	salt := []byte("0123456789abcdef")
+	key, err := scrypt.Key([]byte("Legacy#Pass123"), salt, 1<<10, 8, 1, 32)
+	if err != nil {
+		t.Fatalf("scrypt: %v", err)
+	}
+	passlibKey := strings.ReplaceAll(base64.RawStdEncoding.EncodeToString(key), "+", ".")
+	scryptHash := "$scrypt$ln=10,r=8,p=1$" + base64.StdEncoding.EncodeToString(salt) + "$" + passlibKey
+
+	for name, encoded := range map[string]string{"bcrypt": string(bcryptHash), "scrypt": scryptHash} {
+		if got := PasswordHashAlgorithm(encoded); got != name {
+			t.Fatalf("%s: expected algorithm %q, got %q", name, name, got)
+		}
+		if ok, err := VerifyPassword(encoded, "Legacy#Pass123"); err != nil || !ok {
+			t.Fatalf("%s: expected match, got ok=%v err=%v", name, ok, err)
+		}
+		if ok, err := VerifyPassword(encoded, "wrong-pass"); err != nil || ok {
+			t.Fatalf("%s: expected mismatch, got ok=%v err=%v", name, ok, err)
+		}
+		if !PasswordNeedsRehash(encoded) {
+			t.Fatalf("%s: expected imported hash to need a rehash", name)
+		}
+	}
+
+	if _, err := VerifyPassword("$scrypt$ln=40,r=8,p=1$c2FsdA$a2V5", "x"); err == nil {
+		t.Fatal("expected oversized scrypt parameters to be rejected")
+	}
+	if ok, err := VerifyPassword("", ""); ok || err != nil {
+		t.Fatalf("expected an unset password never to match, got ok=%v err=%v", ok, err)
+	}
+	if _, err := VerifyPassword("$md5$abc", "x"); err == nil {
+		t.Fatal("expected unknown hash format to be rejected")
+	}
+}
+
+func TestPasswordNeedsRehashTracksArgon2Parameters(t *testing.T) {
+	current, err := HashPassword("Stronger#Pass123")
+	if err != nil {
+		t.Fatalf("hash failed: %v", err)
+	}
+	if PasswordNeedsRehash(current) {
+		t.Fatal("expected a hash with current parameters to be kept")
+	}
+	salt := []byte("0123456789abcdef")
+	legacy := fmt.Sprintf("$argon2id$v=19$m=%d,t=%d,p=%d$%s$%s", 32*1024, 2, 1,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(argon2.IDKey([]byte("Stronger#Pass123"), salt, 2, 32*1024, 1, 32)))
+	if ok, err := VerifyPassword(legacy, "Stronger#Pass123"); err != nil || !ok {
+		t.Fatalf("expected hash with older parameters to verify, got ok=%v err=%v", ok, err)
+	}
+	if !PasswordNeedsRehash(legacy) {
+		t.Fatal("expected a hash with older parameters to need a rehash")
+	}
+}
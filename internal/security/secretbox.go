@@ -0,0 +1,69 @@
+package security
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+)
+
+var ErrSecretBoxUnavailable = errors.New("secret box key is not configured")
+
+// SecretBox encrypts small secrets at rest with AES-256-GCM under a key derived
+// from the configured passphrase.
+type SecretBox struct {
+	aead cipher.AEAD
+}
+
+func NewSecretBox(key string) (*SecretBox, error) {
+	if key == "" {
+		return &SecretBox{}, nil
+	}
+	sum := sha256.Sum256([]byte(key))
+	block, err := aes.NewCipher(sum[:])
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &SecretBox{aead: aead}, nil
+}
+
+func (b *SecretBox) Available() bool {
+	return b != nil && b.aead != nil
+}
+
+func (b *SecretBox) Seal(plaintext string) (string, error) {
+	if !b.Available() {
+		return "", ErrSecretBoxUnavailable
+	}
+	nonce := make([]byte, b.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	out := b.aead.Seal(nonce, nonce, []byte(plaintext), nil)
+	return base64.RawStdEncoding.EncodeToString(out), nil
+}
+
+func (b *SecretBox) Open(ciphertext string) (string, error) {
+	if !b.Available() {
+		return "", ErrSecretBoxUnavailable
+	}
+	raw, err := base64.RawStdEncoding.DecodeString(ciphertext)
+	if err != nil {
+		return "", err
+	}
+	if len(raw) < b.aead.NonceSize() {
+		return "", errors.New("secret box ciphertext too short")
+	}
+	nonce, sealed := raw[:b.aead.NonceSize()], raw[b.aead.NonceSize():]
+	plain, err := b.aead.Open(nil, nonce, sealed, nil)
+	if err != nil {
+		return "", err
+	}
+	return string(plain), nil
+}
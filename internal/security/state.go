@@ -7,6 +7,7 @@ import (
 	"encoding/base64"
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 )
 
@@ -37,6 +38,95 @@ func VerifySignedState(raw, secret string) (string, bool) {
 	return parts[0], true
 }
 
+// OAuthFlowState is the per-login material that must survive the redirect to
+// the provider: the CSRF state, the PKCE code verifier and the OIDC nonce. It is
+// stored in the signed oauth_state cookie and bound to a single provider.
+// LinkUserID is set when an authenticated user started the flow to attach the
+// provider identity to their own account instead of logging in.
+// ReauthUserID and ReauthFamilyID are set when the flow re-verifies the
+// provider identity for an existing session instead of logging in.
+type OAuthFlowState struct {
+	Provider       string
+	State          string
+	CodeVerifier   string
+	Nonce          string
+	LinkUserID     uint
+	ReauthUserID   uint
+	ReauthFamilyID string
+}
+
+const oauthFlowStateSeparator = "~"
+
+func NewOAuthFlowState(provider string) (OAuthFlowState, error) {
+	state, err := NewRandomString(24)
+	if err != nil {
+		return OAuthFlowState{}, err
+	}
+	// 32 random bytes encode to a 43 char verifier, the RFC 7636 minimum.
+	verifier, err := NewRandomString(32)
+	if err != nil {
+		return OAuthFlowState{}, err
+	}
+	nonce, err := NewRandomString(24)
+	if err != nil {
+		return OAuthFlowState{}, err
+	}
+	return OAuthFlowState{Provider: provider, State: state, CodeVerifier: verifier, Nonce: nonce}, nil
+}
+
+func SignOAuthFlowState(flow OAuthFlowState, secret string) string {
+	payload := strings.Join([]string{
+		flow.Provider,
+		flow.State,
+		flow.CodeVerifier,
+		flow.Nonce,
+		strconv.FormatUint(uint64(flow.LinkUserID), 10),
+		strconv.FormatUint(uint64(flow.ReauthUserID), 10),
+		flow.ReauthFamilyID,
+	}, oauthFlowStateSeparator)
+	return SignState(payload, secret)
+}
+
+func VerifyOAuthFlowState(raw, secret string) (OAuthFlowState, bool) {
+	payload, ok := VerifySignedState(raw, secret)
+	if !ok {
+		return OAuthFlowState{}, false
+	}
+	parts := strings.Split(payload, oauthFlowStateSeparator)
+	// Five-part cookies were issued before reauthentication existed and are
+	// still honoured for their short lifetime.
+	if len(parts) == 5 {
+		parts = append(parts, "0", "")
+	}
+	if len(parts) != 7 {
+		return OAuthFlowState{}, false
+	}
+	linkUserID, err := strconv.ParseUint(parts[4], 10, 64)
+	if err != nil {
+		return OAuthFlowState{}, false
+	}
+	reauthUserID, err := strconv.ParseUint(parts[5], 10, 64)
+	if err != nil {
+		return OAuthFlowState{}, false
+	}
+	flow := OAuthFlowState{
+		Provider:       parts[0],
+		State:          parts[1],
+		CodeVerifier:   parts[2],
+		Nonce:          parts[3],
+		LinkUserID:     uint(linkUserID),
+		ReauthUserID:   uint(reauthUserID),
+		ReauthFamilyID: parts[6],
+	}
+	if flow.Provider == "" || flow.State == "" || flow.CodeVerifier == "" || flow.Nonce == "" {
+		return OAuthFlowState{}, false
+	}
+	if (flow.ReauthUserID == 0) != (flow.ReauthFamilyID == "") {
+		return OAuthFlowState{}, false
+	}
+	return flow, true
+}
+
 func NewCSRFToken() (string, error) {
 	return NewRandomString(24)
 }
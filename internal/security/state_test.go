@@ -56,3 +56,50 @@ func FuzzVerifySignedStateRobustness(f *testing.F) {
 		_, _ = VerifySignedState(arbitraryRaw, secret)
 	})
 }
+
+func TestOAuthFlowStateSignAndVerify(t *testing.T) {
+	flow, err := NewOAuthFlowState("github")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(flow.CodeVerifier) < 43 || flow.Nonce == "" || flow.State == "" {
+		t.Fatalf("unexpected flow state: %+v", flow)
+	}
+
+	signed := SignOAuthFlowState(flow, "state-secret-123456")
+	parsed, ok := VerifyOAuthFlowState(signed, "state-secret-123456")
+	if !ok || parsed != flow {
+		t.Fatalf("verify failed: ok=%v parsed=%+v want=%+v", ok, parsed, flow)
+	}
+	if _, ok := VerifyOAuthFlowState(signed, "wrong-secret"); ok {
+		t.Fatal("expected verification failure with wrong secret")
+	}
+
+	link := flow
+	link.LinkUserID = 42
+	parsed, ok = VerifyOAuthFlowState(SignOAuthFlowState(link, "state-secret-123456"), "state-secret-123456")
+	if !ok || parsed.LinkUserID != 42 {
+		t.Fatalf("expected link user to round-trip, got ok=%v parsed=%+v", ok, parsed)
+	}
+	reauth := flow
+	reauth.ReauthUserID = 42
+	reauth.ReauthFamilyID = "family-1"
+	parsed, ok = VerifyOAuthFlowState(SignOAuthFlowState(reauth, "state-secret-123456"), "state-secret-123456")
+	if !ok || parsed != reauth {
+		t.Fatalf("expected reauth binding to round-trip, got ok=%v parsed=%+v", ok, parsed)
+	}
+	legacy := strings.Join([]string{flow.Provider, flow.State, flow.CodeVerifier, flow.Nonce, "0"}, "~")
+	if parsed, ok = VerifyOAuthFlowState(SignState(legacy, "state-secret-123456"), "state-secret-123456"); !ok || parsed != flow {
+		t.Fatalf("expected a five-part cookie to stay valid, got ok=%v parsed=%+v", ok, parsed)
+	}
+
+	// A legacy state-only cookie carries no verifier or nonce and must be rejected.
+	if _, ok := VerifyOAuthFlowState(SignState(flow.State, "state-secret-123456"), "state-secret-123456"); ok {
+		t.Fatal("expected state-only cookie to be rejected")
+	}
+	incomplete := flow
+	incomplete.Nonce = ""
+	if _, ok := VerifyOAuthFlowState(SignOAuthFlowState(incomplete, "state-secret-123456"), "state-secret-123456"); ok {
+		t.Fatal("expected flow state without nonce to be rejected")
+	}
+}
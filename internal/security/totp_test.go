@@ -0,0 +1,93 @@
+package security
+
+import (
+	"encoding/base32"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestTOTPCodeMatchesRFC6238Vectors(t *testing.T) {
+	// RFC 6238 appendix B SHA1 seed, truncated to six digits.
+	secret := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+	cases := []struct {
+		unix int64
+		want string
+	}{
+		{unix: 59, want: "287082"},
+		{unix: 1111111109, want: "081804"},
+		{unix: 1234567890, want: "005924"},
+		{unix: 2000000000, want: "279037"},
+	}
+	for _, tc := range cases {
+		got, err := TOTPCode(secret, TOTPStep(time.Unix(tc.unix, 0)))
+		if err != nil {
+			t.Fatalf("totp code: %v", err)
+		}
+		if got != tc.want {
+			t.Fatalf("unix=%d: expected %s, got %s", tc.unix, tc.want, got)
+		}
+	}
+}
+
+func TestValidateTOTPSkewAndStep(t *testing.T) {
+	secret, err := NewTOTPSecret()
+	if err != nil {
+		t.Fatalf("new secret: %v", err)
+	}
+	now := time.Unix(1_700_000_000, 0)
+	prev, _ := TOTPCode(secret, TOTPStep(now)-1)
+
+	step, ok := ValidateTOTP(secret, prev, now, 1)
+	if !ok || step != TOTPStep(now)-1 {
+		t.Fatalf("expected previous step code to validate with skew, got step=%d ok=%v", step, ok)
+	}
+	if _, ok := ValidateTOTP(secret, prev, now, 0); ok {
+		t.Fatal("expected previous step code to fail without skew")
+	}
+	if _, ok := ValidateTOTP(secret, "12345", now, 1); ok {
+		t.Fatal("expected short code to fail")
+	}
+}
+
+func TestTOTPProvisioningURI(t *testing.T) {
+	raw := TOTPProvisioningURI("Starter Kit", "user@example.com", "ABCDEF")
+	u, err := url.Parse(raw)
+	if err != nil {
+		t.Fatalf("parse uri: %v", err)
+	}
+	if u.Scheme != "otpauth" || u.Host != "totp" {
+		t.Fatalf("unexpected otpauth uri: %s", raw)
+	}
+	if u.Query().Get("secret") != "ABCDEF" || u.Query().Get("issuer") != "Starter Kit" {
+		t.Fatalf("unexpected otpauth query: %s", raw)
+	}
+}
+
+func TestSecretBoxRoundTrip(t *testing.T) {
+	box, err := NewSecretBox("mfa-encryption-key-0123456789abcdef")
+	if err != nil {
+		t.Fatalf("new secret box: %v", err)
+	}
+	sealed, err := box.Seal("JBSWY3DPEHPK3PXP")
+	if err != nil {
+		t.Fatalf("seal: %v", err)
+	}
+	if sealed == "JBSWY3DPEHPK3PXP" {
+		t.Fatal("expected ciphertext to differ from plaintext")
+	}
+	opened, err := box.Open(sealed)
+	if err != nil || opened != "JBSWY3DPEHPK3PXP" {
+		t.Fatalf("expected round trip, got %q err=%v", opened, err)
+	}
+
+	other, _ := NewSecretBox("another-encryption-key-0123456789ab")
+	if _, err := other.Open(sealed); err == nil {
+		t.Fatal("expected open with different key to fail")
+	}
+
+	empty, _ := NewSecretBox("")
+	if _, err := empty.Seal("x"); err != ErrSecretBoxUnavailable {
+		t.Fatalf("expected ErrSecretBoxUnavailable, got %v", err)
+	}
+}
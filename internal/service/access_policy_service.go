@@ -0,0 +1,450 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/domain"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/policy"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/repository"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/security"
+)
+
+var (
+	ErrInvalidAccessPolicy  = errors.New("invalid access policy")
+	ErrAccessPolicyNotFound = errors.New("access policy not found")
+	ErrAccessPolicyConflict = errors.New("access policy name already exists")
+	// ErrPolicyResourceNotFound is returned by a ResourceAttributeFetcher when
+	// the resource does not exist. Its attributes then evaluate to null.
+	ErrPolicyResourceNotFound = errors.New("policy resource not found")
+)
+
+// AccessPolicyResourceUser is the built-in fetcher for user resources. It
+// also backs subject attributes that are not carried in the token.
+const AccessPolicyResourceUser = "user"
+
+// Policy names are recorded as metric attributes, so they are kept short and
+// predictable.
+var accessPolicyNameRe = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]{0,63}$`)
+
+// ResourceAttributeFetcher loads the attributes a condition sees under
+// resource.* for the resource with the given ID.
+type ResourceAttributeFetcher interface {
+	FetchAttributes(ctx context.Context, id string) (map[string]any, error)
+}
+
+type ResourceAttributeFetcherFunc func(ctx context.Context, id string) (map[string]any, error)
+
+func (f ResourceAttributeFetcherFunc) FetchAttributes(ctx context.Context, id string) (map[string]any, error) {
+	return f(ctx, id)
+}
+
+type AccessPolicyInput struct {
+	Name          string
+	Description   string
+	Permission    string
+	Effect        string
+	Condition     string
+	ResourceType  string
+	ResourceParam string
+	// Enabled defaults to true on create and is left unchanged on update
+	// when nil.
+	Enabled *bool
+}
+
+// AccessRequest describes a request that already holds Permission.
+type AccessRequest struct {
+	Permission  string
+	Claims      *security.Claims
+	Permissions []string
+	IP          string
+	Method      string
+	Path        string
+	Params      map[string]string
+}
+
+// AccessDecision reports the outcome of policy evaluation. Policy names the
+// policy that denied the request or failed to evaluate.
+type AccessDecision struct {
+	Allowed bool
+	Policy  string
+}
+
+type compiledAccessPolicy struct {
+	domain.AccessPolicy
+	program *policy.Program
+}
+
+type AccessPolicyService struct {
+	repo     repository.AccessPolicyRepository
+	ttl      time.Duration
+	now      func() time.Time
+	fetchers map[string]ResourceAttributeFetcher
+
+	mu       sync.RWMutex
+	active   []compiledAccessPolicy
+	loadedAt time.Time
+	loaded   bool
+	// generation changes on every Invalidate so that a load racing with a
+	// mutation does not cache the stale set.
+	generation uint64
+}
+
+// NewAccessPolicyService keeps enabled policies in memory for ttl. Mutations
+// through the service refresh the local copy immediately; other instances
+// pick them up once ttl expires. A zero ttl reloads on every evaluation.
+func NewAccessPolicyService(repo repository.AccessPolicyRepository, ttl time.Duration) *AccessPolicyService {
+	return &AccessPolicyService{
+		repo:     repo,
+		ttl:      ttl,
+		now:      time.Now,
+		fetchers: map[string]ResourceAttributeFetcher{},
+	}
+}
+
+// RegisterFetcher makes resourceType available to policies. It must be
+// called before the service starts evaluating requests.
+func (s *AccessPolicyService) RegisterFetcher(resourceType string, fetcher ResourceAttributeFetcher) {
+	s.fetchers[strings.ToLower(strings.TrimSpace(resourceType))] = fetcher
+}
+
+func (s *AccessPolicyService) List() ([]domain.AccessPolicy, error) {
+	return s.repo.List()
+}
+
+func (s *AccessPolicyService) Get(id uint) (*domain.AccessPolicy, error) {
+	p, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, mapAccessPolicyError(err)
+	}
+	return p, nil
+}
+
+func (s *AccessPolicyService) Create(input AccessPolicyInput) (*domain.AccessPolicy, error) {
+	p := &domain.AccessPolicy{Enabled: true}
+	if err := s.apply(p, input); err != nil {
+		return nil, err
+	}
+	if _, err := s.repo.FindByName(p.Name); err == nil {
+		return nil, ErrAccessPolicyConflict
+	} else if !errors.Is(err, repository.ErrAccessPolicyNotFound) {
+		return nil, err
+	}
+	if err := s.repo.Create(p); err != nil {
+		return nil, err
+	}
+	s.Invalidate()
+	return p, nil
+}
+
+func (s *AccessPolicyService) Update(id uint, input AccessPolicyInput) (*domain.AccessPolicy, error) {
+	p, err := s.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.apply(p, input); err != nil {
+		return nil, err
+	}
+	if existing, err := s.repo.FindByName(p.Name); err == nil && existing.ID != p.ID {
+		return nil, ErrAccessPolicyConflict
+	} else if err != nil && !errors.Is(err, repository.ErrAccessPolicyNotFound) {
+		return nil, err
+	}
+	if err := s.repo.Update(p); err != nil {
+		return nil, err
+	}
+	s.Invalidate()
+	return p, nil
+}
+
+func (s *AccessPolicyService) Delete(id uint) error {
+	if err := s.repo.DeleteByID(id); err != nil {
+		return mapAccessPolicyError(err)
+	}
+	s.Invalidate()
+	return nil
+}
+
+// Invalidate drops the in-memory policy set so the next evaluation reloads
+// it from the database.
+func (s *AccessPolicyService) Invalidate() {
+	s.mu.Lock()
+	s.loaded = false
+	s.active = nil
+	s.generation++
+	s.mu.Unlock()
+}
+
+func (s *AccessPolicyService) apply(p *domain.AccessPolicy, input AccessPolicyInput) error {
+	name := strings.ToLower(strings.TrimSpace(input.Name))
+	permission := strings.ToLower(strings.TrimSpace(input.Permission))
+	effect := strings.ToLower(strings.TrimSpace(input.Effect))
+	resourceType := strings.ToLower(strings.TrimSpace(input.ResourceType))
+	resourceParam := strings.TrimSpace(input.ResourceParam)
+	description := strings.TrimSpace(input.Description)
+	if !accessPolicyNameRe.MatchString(name) || len(description) > 255 {
+		return ErrInvalidAccessPolicy
+	}
+	if _, _, ok := splitPermission(permission); !ok || len(permission) > 128 {
+		return ErrInvalidAccessPolicy
+	}
+	if effect != domain.AccessPolicyEffectRequire && effect != domain.AccessPolicyEffectDeny {
+		return ErrInvalidAccessPolicy
+	}
+	if resourceType != "" {
+		if _, ok := s.fetchers[resourceType]; !ok || resourceParam == "" || len(resourceParam) > 64 {
+			return ErrInvalidAccessPolicy
+		}
+	} else if resourceParam != "" {
+		return ErrInvalidAccessPolicy
+	}
+	program, err := policy.Compile(input.Condition)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidAccessPolicy, err)
+	}
+	p.Name = name
+	p.Description = description
+	p.Permission = permission
+	p.Effect = effect
+	p.Condition = program.String()
+	p.ResourceType = resourceType
+	p.ResourceParam = resourceParam
+	if input.Enabled != nil {
+		p.Enabled = *input.Enabled
+	}
+	return nil
+}
+
+// Evaluate runs every enabled policy whose permission covers
+// req.Permission. All applicable policies must pass; the first denial wins.
+// Errors fail closed and name the policy that could not be evaluated.
+func (s *AccessPolicyService) Evaluate(ctx context.Context, req AccessRequest) (AccessDecision, error) {
+	policies, err := s.activePolicies()
+	if err != nil {
+		return AccessDecision{}, err
+	}
+	env := &accessPolicyEnv{ctx: ctx, svc: s, req: req, now: s.now(), fetched: map[string]map[string]any{}}
+	for i := range policies {
+		p := &policies[i]
+		if !PermissionGrants(p.Permission, req.Permission) {
+			continue
+		}
+		env.policy = p
+		holds, err := p.program.Eval(env)
+		if err != nil {
+			return AccessDecision{Policy: p.Name}, err
+		}
+		if holds == (p.Effect == domain.AccessPolicyEffectDeny) {
+			return AccessDecision{Policy: p.Name}, nil
+		}
+	}
+	return AccessDecision{Allowed: true}, nil
+}
+
+func (s *AccessPolicyService) activePolicies() ([]compiledAccessPolicy, error) {
+	s.mu.RLock()
+	if s.loaded && s.ttl > 0 && s.now().Sub(s.loadedAt) < s.ttl {
+		active := s.active
+		s.mu.RUnlock()
+		return active, nil
+	}
+	generation := s.generation
+	s.mu.RUnlock()
+
+	rows, err := s.repo.ListEnabled()
+	if err != nil {
+		return nil, err
+	}
+	active := make([]compiledAccessPolicy, 0, len(rows))
+	for _, row := range rows {
+		program, err := policy.Compile(row.Condition)
+		if err != nil {
+			// Conditions are validated on write, so this only happens when a
+			// row was edited out of band. Failing closed keeps the policy's
+			// intent without silently dropping it.
+			return nil, fmt.Errorf("compile access policy %q: %w", row.Name, err)
+		}
+		active = append(active, compiledAccessPolicy{AccessPolicy: row, program: program})
+	}
+	s.mu.Lock()
+	if s.generation == generation {
+		s.active = active
+		s.loadedAt = s.now()
+		s.loaded = true
+	}
+	s.mu.Unlock()
+	return active, nil
+}
+
+func (s *AccessPolicyService) fetch(ctx context.Context, resourceType, id string) (map[string]any, error) {
+	fetcher, ok := s.fetchers[resourceType]
+	if !ok || id == "" {
+		return nil, nil
+	}
+	attrs, err := fetcher.FetchAttributes(ctx, id)
+	if errors.Is(err, ErrPolicyResourceNotFound) {
+		return nil, nil
+	}
+	return attrs, err
+}
+
+// accessPolicyEnv resolves attributes for one request. Fetched resources are
+// shared by every policy evaluated for the request.
+type accessPolicyEnv struct {
+	ctx     context.Context
+	svc     *AccessPolicyService
+	req     AccessRequest
+	policy  *compiledAccessPolicy
+	now     time.Time
+	fetched map[string]map[string]any
+}
+
+func (e *accessPolicyEnv) Now() time.Time { return e.now }
+
+func (e *accessPolicyEnv) Lookup(path []string) (any, error) {
+	switch path[0] {
+	case "subject":
+		return e.subject(path[1:])
+	case "request":
+		return e.request(path[1:]), nil
+	case "resource":
+		if e.policy == nil || e.policy.ResourceType == "" || len(path) != 2 {
+			return nil, nil
+		}
+		attrs, err := e.load(e.policy.ResourceType, e.req.Params[e.policy.ResourceParam])
+		if err != nil {
+			return nil, err
+		}
+		return attrs[path[1]], nil
+	}
+	return nil, nil
+}
+
+func (e *accessPolicyEnv) subject(path []string) (any, error) {
+	claims := e.req.Claims
+	if claims == nil || len(path) != 1 {
+		return nil, nil
+	}
+	switch path[0] {
+	case "id":
+		if id, err := strconv.ParseUint(claims.Subject, 10, 64); err == nil {
+			return uint(id), nil
+		}
+		return claims.Subject, nil
+	case "type":
+		switch {
+		case claims.IsServiceAccount():
+			return "service_account", nil
+		case claims.TokenType == APIKeyClaimsTokenType:
+			return "api_key", nil
+		}
+		return "user", nil
+	case "roles":
+		return claims.Roles, nil
+	case "permissions":
+		return e.req.Permissions, nil
+	case "impersonated":
+		return claims.IsImpersonated(), nil
+	}
+	// Anything else is looked up on the subject's user record.
+	if claims.IsServiceAccount() {
+		return nil, nil
+	}
+	attrs, err := e.load(AccessPolicyResourceUser, claims.Subject)
+	if err != nil {
+		return nil, err
+	}
+	return attrs[path[0]], nil
+}
+
+func (e *accessPolicyEnv) request(path []string) any {
+	if len(path) == 2 && path[0] == "params" {
+		if v, ok := e.req.Params[path[1]]; ok {
+			return v
+		}
+		return nil
+	}
+	if len(path) != 1 {
+		return nil
+	}
+	switch path[0] {
+	case "ip":
+		return e.req.IP
+	case "method":
+		return e.req.Method
+	case "path":
+		return e.req.Path
+	case "org_id":
+		if orgID, ok := OrganizationFromContext(e.ctx); ok {
+			return orgID
+		}
+	}
+	return nil
+}
+
+func (e *accessPolicyEnv) load(resourceType, id string) (map[string]any, error) {
+	key := resourceType + ":" + id
+	if attrs, ok := e.fetched[key]; ok {
+		return attrs, nil
+	}
+	attrs, err := e.svc.fetch(e.ctx, resourceType, id)
+	if err != nil {
+		return nil, err
+	}
+	e.fetched[key] = attrs
+	return attrs, nil
+}
+
+// NewUserAttributeFetcher exposes id, email, status, roles and org_ids of a
+// user. orgs may be nil when organizations are disabled.
+func NewUserAttributeFetcher(users repository.UserRepository, orgs repository.OrganizationRepository) ResourceAttributeFetcher {
+	return ResourceAttributeFetcherFunc(func(_ context.Context, id string) (map[string]any, error) {
+		userID, err := strconv.ParseUint(id, 10, 64)
+		if err != nil {
+			return nil, ErrPolicyResourceNotFound
+		}
+		user, err := users.FindByID(uint(userID))
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, ErrPolicyResourceNotFound
+			}
+			return nil, err
+		}
+		roles := make([]string, 0, len(user.Roles))
+		for _, role := range user.Roles {
+			roles = append(roles, role.Name)
+		}
+		orgIDs := []uint{}
+		if orgs != nil {
+			memberships, err := orgs.ListForUser(user.ID)
+			if err != nil {
+				return nil, err
+			}
+			for _, org := range memberships {
+				orgIDs = append(orgIDs, org.ID)
+			}
+		}
+		return map[string]any{
+			"id":      user.ID,
+			"email":   user.Email,
+			"status":  user.Status,
+			"roles":   roles,
+			"org_ids": orgIDs,
+		}, nil
+	})
+}
+
+func mapAccessPolicyError(err error) error {
+	if errors.Is(err, repository.ErrAccessPolicyNotFound) {
+		return ErrAccessPolicyNotFound
+	}
+	return err
+}
@@ -0,0 +1,188 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/domain"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/repository"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/security"
+)
+
+func newAccessPolicyServiceForTest(t *testing.T, ttl time.Duration) (*AccessPolicyService, *gorm.DB) {
+	t.Helper()
+	dsn := fmt.Sprintf("file:%s?mode=memory&cache=shared", strings.ReplaceAll(t.Name(), "/", "_"))
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	if err := db.AutoMigrate(&domain.Permission{}, &domain.Role{}, &domain.RoleParent{}, &domain.User{}, &domain.UserRole{}, &domain.Organization{}, &domain.OrganizationMember{}, &domain.OrganizationRoleBinding{}, &domain.AccessPolicy{}); err != nil {
+		t.Fatalf("migrate access policy models: %v", err)
+	}
+	svc := NewAccessPolicyService(repository.NewAccessPolicyRepository(db), ttl)
+	svc.RegisterFetcher(AccessPolicyResourceUser, NewUserAttributeFetcher(repository.NewUserRepository(db), repository.NewOrganizationRepository(db)))
+	return svc, db
+}
+
+func accessRequestForTest(subject, permission, ip string, params map[string]string) AccessRequest {
+	claims := &security.Claims{Roles: []string{"admin"}}
+	claims.Subject = subject
+	return AccessRequest{Permission: permission, Claims: claims, IP: ip, Method: "PATCH", Params: params}
+}
+
+func TestAccessPolicyServiceValidatesInput(t *testing.T) {
+	svc, _ := newAccessPolicyServiceForTest(t, 0)
+	valid := AccessPolicyInput{Name: "Office-Network", Permission: "users:write", Effect: "require", Condition: ` cidr(request.ip, "10.0.0.0/8") `}
+	p, err := svc.Create(valid)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if p.Name != "office-network" || !p.Enabled || p.Condition != `cidr(request.ip, "10.0.0.0/8")` {
+		t.Fatalf("expected normalized policy, got %+v", p)
+	}
+	if _, err := svc.Create(valid); !errors.Is(err, ErrAccessPolicyConflict) {
+		t.Fatalf("expected duplicate name to conflict, got %v", err)
+	}
+	for _, input := range []AccessPolicyInput{
+		{Name: "bad name", Permission: "users:write", Effect: "require", Condition: "true"},
+		{Name: "p", Permission: "users", Effect: "require", Condition: "true"},
+		{Name: "p", Permission: "users:write", Effect: "allow", Condition: "true"},
+		{Name: "p", Permission: "users:write", Effect: "deny", Condition: "user.id == 1"},
+		{Name: "p", Permission: "users:write", Effect: "deny", Condition: "true", ResourceType: "invoice", ResourceParam: "id"},
+		{Name: "p", Permission: "users:write", Effect: "deny", Condition: "true", ResourceType: "user"},
+		{Name: "p", Permission: "users:write", Effect: "deny", Condition: "true", ResourceParam: "id"},
+	} {
+		if _, err := svc.Create(input); !errors.Is(err, ErrInvalidAccessPolicy) {
+			t.Fatalf("expected %+v to be rejected, got %v", input, err)
+		}
+	}
+	disabled := false
+	updated, err := svc.Update(p.ID, AccessPolicyInput{Name: "office-network", Permission: "users:*", Effect: "deny", Condition: "false", Enabled: &disabled})
+	if err != nil || updated.Enabled || updated.Permission != "users:*" {
+		t.Fatalf("expected update to apply, got %+v err=%v", updated, err)
+	}
+	if err := svc.Delete(p.ID); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if err := svc.Delete(p.ID); !errors.Is(err, ErrAccessPolicyNotFound) {
+		t.Fatalf("expected missing policy, got %v", err)
+	}
+}
+
+func TestAccessPolicyServiceEvaluate(t *testing.T) {
+	svc, db := newAccessPolicyServiceForTest(t, 0)
+	acme := domain.Organization{Slug: "acme", Name: "Acme"}
+	globex := domain.Organization{Slug: "globex", Name: "Globex"}
+	for _, org := range []*domain.Organization{&acme, &globex} {
+		if err := db.Create(org).Error; err != nil {
+			t.Fatalf("create org: %v", err)
+		}
+	}
+	actor := domain.User{Email: "actor@example.com", Name: "Actor", Status: domain.UserStatusActive}
+	colleague := domain.User{Email: "colleague@example.com", Name: "Colleague", Status: domain.UserStatusActive}
+	stranger := domain.User{Email: "stranger@example.com", Name: "Stranger", Status: domain.UserStatusActive}
+	for _, u := range []*domain.User{&actor, &colleague, &stranger} {
+		if err := db.Create(u).Error; err != nil {
+			t.Fatalf("create user: %v", err)
+		}
+	}
+	for _, m := range []domain.OrganizationMember{
+		{OrganizationID: acme.ID, UserID: actor.ID},
+		{OrganizationID: acme.ID, UserID: colleague.ID},
+		{OrganizationID: globex.ID, UserID: stranger.ID},
+	} {
+		if err := db.Create(&m).Error; err != nil {
+			t.Fatalf("create member: %v", err)
+		}
+	}
+
+	if _, err := svc.Create(AccessPolicyInput{
+		Name: "same-org", Permission: "users:write", Effect: "require",
+		Condition:    `intersects(subject.org_ids, resource.org_ids)`,
+		ResourceType: "user", ResourceParam: "id",
+	}); err != nil {
+		t.Fatalf("create same-org: %v", err)
+	}
+	if _, err := svc.Create(AccessPolicyInput{
+		Name: "no-external-writes", Permission: "*:write", Effect: "deny",
+		Condition: `!cidr(request.ip, "10.0.0.0/8")`,
+	}); err != nil {
+		t.Fatalf("create no-external-writes: %v", err)
+	}
+
+	actorID := fmt.Sprint(actor.ID)
+	cases := []struct {
+		name   string
+		req    AccessRequest
+		allow  bool
+		policy string
+	}{
+		{"same org from office", accessRequestForTest(actorID, "users:write", "10.0.0.5", map[string]string{"id": fmt.Sprint(colleague.ID)}), true, ""},
+		{"other org", accessRequestForTest(actorID, "users:write", "10.0.0.5", map[string]string{"id": fmt.Sprint(stranger.ID)}), false, "same-org"},
+		{"missing target", accessRequestForTest(actorID, "users:write", "10.0.0.5", map[string]string{"id": "9999"}), false, "same-org"},
+		{"outside network", accessRequestForTest(actorID, "roles:write", "203.0.113.9", nil), false, "no-external-writes"},
+		{"unrelated permission", accessRequestForTest(actorID, "users:read", "203.0.113.9", nil), true, ""},
+	}
+	for _, tc := range cases {
+		decision, err := svc.Evaluate(context.Background(), tc.req)
+		if err != nil {
+			t.Fatalf("%s: evaluate: %v", tc.name, err)
+		}
+		if decision.Allowed != tc.allow || decision.Policy != tc.policy {
+			t.Fatalf("%s: expected allow=%v policy=%q, got %+v", tc.name, tc.allow, tc.policy, decision)
+		}
+	}
+
+	if _, err := svc.Create(AccessPolicyInput{Name: "broken", Permission: "users:read", Effect: "require", Condition: `subject.id`}); err != nil {
+		t.Fatalf("create broken: %v", err)
+	}
+	decision, err := svc.Evaluate(context.Background(), accessRequestForTest(actorID, "users:read", "10.0.0.5", nil))
+	if err == nil || decision.Allowed || decision.Policy != "broken" {
+		t.Fatalf("expected evaluation error to fail closed naming the policy, got %+v err=%v", decision, err)
+	}
+}
+
+func TestAccessPolicyServiceCachesPoliciesUntilInvalidated(t *testing.T) {
+	svc, db := newAccessPolicyServiceForTest(t, time.Hour)
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+	req := accessRequestForTest("1", "users:write", "10.0.0.1", nil)
+
+	if d, err := svc.Evaluate(context.Background(), req); err != nil || !d.Allowed {
+		t.Fatalf("expected allow with no policies, got %+v err=%v", d, err)
+	}
+	// Rows written behind the service's back stay invisible until the TTL
+	// expires.
+	if err := db.Create(&domain.AccessPolicy{Name: "lockdown", Permission: "*:*", Effect: "deny", Condition: "true", Enabled: true}).Error; err != nil {
+		t.Fatalf("insert policy: %v", err)
+	}
+	if d, _ := svc.Evaluate(context.Background(), req); !d.Allowed {
+		t.Fatal("expected cached policy set to be used")
+	}
+	now = now.Add(time.Hour)
+	if d, _ := svc.Evaluate(context.Background(), req); d.Allowed || d.Policy != "lockdown" {
+		t.Fatalf("expected reload after TTL, got %+v", d)
+	}
+
+	lockdown, err := repository.NewAccessPolicyRepository(db).FindByName("lockdown")
+	if err != nil {
+		t.Fatalf("find policy: %v", err)
+	}
+	disabled := false
+	if _, err := svc.Update(lockdown.ID, AccessPolicyInput{Name: "lockdown", Permission: "*:*", Effect: "deny", Condition: "true", Enabled: &disabled}); err != nil {
+		t.Fatalf("disable policy: %v", err)
+	}
+	if d, _ := svc.Evaluate(context.Background(), req); !d.Allowed {
+		t.Fatalf("expected mutation through the service to refresh immediately, got %+v", d)
+	}
+}
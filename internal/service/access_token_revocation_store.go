@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// AccessTokenRevocationStore is a denylist of access-token IDs (jti). Entries
+// only need to outlive the token they block, so callers pass the remaining
+// token lifetime as ttl.
+type AccessTokenRevocationStore interface {
+	Revoke(ctx context.Context, jti string, ttl time.Duration) error
+	IsRevoked(ctx context.Context, jti string) (bool, error)
+}
+
+type InMemoryAccessTokenRevocationStore struct {
+	mu      sync.Mutex
+	entries map[string]time.Time
+	now     func() time.Time
+}
+
+func NewInMemoryAccessTokenRevocationStore() *InMemoryAccessTokenRevocationStore {
+	return &InMemoryAccessTokenRevocationStore{
+		entries: make(map[string]time.Time),
+		now:     time.Now,
+	}
+}
+
+func (s *InMemoryAccessTokenRevocationStore) Revoke(_ context.Context, jti string, ttl time.Duration) error {
+	if jti == "" || ttl <= 0 {
+		return nil
+	}
+	now := s.now().UTC()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key, expiresAt := range s.entries {
+		if !now.Before(expiresAt) {
+			delete(s.entries, key)
+		}
+	}
+	expiresAt := now.Add(ttl)
+	if current, ok := s.entries[jti]; !ok || expiresAt.After(current) {
+		s.entries[jti] = expiresAt
+	}
+	return nil
+}
+
+func (s *InMemoryAccessTokenRevocationStore) IsRevoked(_ context.Context, jti string) (bool, error) {
+	if jti == "" {
+		return false, nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	expiresAt, ok := s.entries[jti]
+	if !ok {
+		return false, nil
+	}
+	if !s.now().UTC().Before(expiresAt) {
+		delete(s.entries, jti)
+		return false, nil
+	}
+	return true, nil
+}
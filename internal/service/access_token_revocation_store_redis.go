@@ -0,0 +1,42 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+type RedisAccessTokenRevocationStore struct {
+	client redis.UniversalClient
+	prefix string
+}
+
+func NewRedisAccessTokenRevocationStore(client redis.UniversalClient, prefix string) *RedisAccessTokenRevocationStore {
+	if prefix == "" {
+		prefix = "access_revoked"
+	}
+	return &RedisAccessTokenRevocationStore{client: client, prefix: prefix}
+}
+
+func (s *RedisAccessTokenRevocationStore) Revoke(ctx context.Context, jti string, ttl time.Duration) error {
+	if s.client == nil || jti == "" || ttl <= 0 {
+		return nil
+	}
+	return s.client.Set(ctx, s.key(jti), "1", ttl).Err()
+}
+
+func (s *RedisAccessTokenRevocationStore) IsRevoked(ctx context.Context, jti string) (bool, error) {
+	if s.client == nil || jti == "" {
+		return false, nil
+	}
+	n, err := s.client.Exists(ctx, s.key(jti)).Result()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+func (s *RedisAccessTokenRevocationStore) key(jti string) string {
+	return s.prefix + ":jti:" + jti
+}
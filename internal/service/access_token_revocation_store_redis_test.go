@@ -0,0 +1,49 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRedisAccessTokenRevocationStoreExpiresWithToken(t *testing.T) {
+	ctx := context.Background()
+	server, client := newRedisClientForTest(t)
+	store := NewRedisAccessTokenRevocationStore(client, "revoked_test")
+
+	if err := store.Revoke(ctx, "jti-1", time.Minute); err != nil {
+		t.Fatalf("revoke: %v", err)
+	}
+	if !server.Exists("revoked_test:jti:jti-1") {
+		t.Fatal("expected prefixed jti key")
+	}
+	if revoked, err := store.IsRevoked(ctx, "jti-1"); err != nil || !revoked {
+		t.Fatalf("expected jti to be revoked, got %v err=%v", revoked, err)
+	}
+	if revoked, err := store.IsRevoked(ctx, "jti-2"); err != nil || revoked {
+		t.Fatalf("expected unknown jti to pass, got %v err=%v", revoked, err)
+	}
+
+	server.FastForward(2 * time.Minute)
+	if revoked, err := store.IsRevoked(ctx, "jti-1"); err != nil || revoked {
+		t.Fatalf("expected denylist entry to expire with the token, got %v err=%v", revoked, err)
+	}
+}
+
+func TestInMemoryAccessTokenRevocationStoreExpiresWithToken(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	store := NewInMemoryAccessTokenRevocationStore()
+	store.now = func() time.Time { return now }
+
+	if err := store.Revoke(ctx, "jti-1", time.Minute); err != nil {
+		t.Fatalf("revoke: %v", err)
+	}
+	if revoked, _ := store.IsRevoked(ctx, "jti-1"); !revoked {
+		t.Fatal("expected jti to be revoked")
+	}
+	store.now = func() time.Time { return now.Add(2 * time.Minute) }
+	if revoked, _ := store.IsRevoked(ctx, "jti-1"); revoked {
+		t.Fatal("expected denylist entry to expire with the token")
+	}
+}
@@ -0,0 +1,77 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/domain"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/observability"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/repository"
+)
+
+// AccessTokenRevoker denylists the access tokens minted alongside a user's
+// sessions. An access token carries its session's TokenID as jti, so every
+// session whose access token was signed within the access TTL may still have
+// a live token.
+type AccessTokenRevoker struct {
+	store       AccessTokenRevocationStore
+	sessionRepo repository.SessionRepository
+	accessTTL   time.Duration
+	now         func() time.Time
+}
+
+func NewAccessTokenRevoker(store AccessTokenRevocationStore, sessionRepo repository.SessionRepository, accessTTL time.Duration) *AccessTokenRevoker {
+	if store == nil {
+		store = NewInMemoryAccessTokenRevocationStore()
+	}
+	return &AccessTokenRevoker{store: store, sessionRepo: sessionRepo, accessTTL: accessTTL, now: time.Now}
+}
+
+func (r *AccessTokenRevoker) RevokeUser(ctx context.Context, userID uint, reason string) error {
+	return r.revokeMatching(ctx, userID, reason, func(domain.Session) bool { return true })
+}
+
+// RevokeFamily covers every rotation of one login, since the access tokens of
+// earlier rotations stay valid until they expire.
+func (r *AccessTokenRevoker) RevokeFamily(ctx context.Context, userID uint, familyID, reason string) error {
+	if familyID == "" {
+		return nil
+	}
+	return r.revokeMatching(ctx, userID, reason, func(s domain.Session) bool {
+		return getString(s.FamilyID) == familyID
+	})
+}
+
+func (r *AccessTokenRevoker) RevokeOtherFamilies(ctx context.Context, userID uint, keepFamilyID, reason string) error {
+	return r.revokeMatching(ctx, userID, reason, func(s domain.Session) bool {
+		return keepFamilyID == "" || getString(s.FamilyID) != keepFamilyID
+	})
+}
+
+func (r *AccessTokenRevoker) revokeMatching(ctx context.Context, userID uint, reason string, match func(domain.Session) bool) error {
+	if r == nil || r.accessTTL <= 0 {
+		return nil
+	}
+	now := r.now()
+	sessions, err := r.sessionRepo.ListIssuedSince(userID, now.Add(-r.accessTTL))
+	if err != nil {
+		observability.RecordAccessTokenRevocation(ctx, reason, "error")
+		return err
+	}
+	for _, session := range sessions {
+		jti := getString(session.TokenID)
+		if jti == "" || !match(session) {
+			continue
+		}
+		ttl := session.AccessTokenIssuedAt().Add(r.accessTTL).Sub(now)
+		if ttl <= 0 {
+			continue
+		}
+		if err := r.store.Revoke(ctx, jti, ttl); err != nil {
+			observability.RecordAccessTokenRevocation(ctx, reason, "error")
+			return err
+		}
+		observability.RecordAccessTokenRevocation(ctx, reason, "success")
+	}
+	return nil
+}
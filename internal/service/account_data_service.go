@@ -0,0 +1,349 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/config"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/domain"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/observability"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+var ErrReauthRequired = errors.New("recent authentication required")
+
+const accountDeletionSweepBatch = 100
+
+// AccountDeletionReauthMaxAge is how recently the current session must have
+// authenticated for the user to delete the account. Unlike
+// AUTH_REAUTH_MAX_AGE it cannot be turned off.
+const AccountDeletionReauthMaxAge = 10 * time.Minute
+
+// AccountExport is the personal data archive served by GET /me/export.
+// Login history is derived from session rows: every sign-in starts a new
+// session family. Auth events add the attempts that did not start one:
+// failed sign-ins, MFA challenges, step-ups and password changes.
+type AccountExport struct {
+	GeneratedAt     time.Time                 `json:"generated_at"`
+	Profile         AccountExportProfile      `json:"profile"`
+	Roles           []string                  `json:"roles"`
+	LocalCredential *AccountExportCredential  `json:"local_credential,omitempty"`
+	Identities      []AccountExportIdentity   `json:"identities"`
+	Sessions        []AccountExportSession    `json:"sessions"`
+	LoginHistory    []AccountExportLoginEvent `json:"login_history"`
+	AuthEvents      []AccountExportAuthEvent  `json:"auth_events"`
+}
+
+type AccountExportProfile struct {
+	ID                  uint       `json:"id"`
+	Email               string     `json:"email"`
+	Name                string     `json:"name"`
+	AvatarURL           string     `json:"avatar_url"`
+	Status              string     `json:"status"`
+	LastLoginAt         time.Time  `json:"last_login_at"`
+	DeletionScheduledAt *time.Time `json:"deletion_scheduled_at,omitempty"`
+	CreatedAt           time.Time  `json:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at"`
+}
+
+type AccountExportCredential struct {
+	EmailVerified   bool       `json:"email_verified"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+type AccountExportIdentity struct {
+	ID             uint      `json:"id"`
+	Provider       string    `json:"provider"`
+	ProviderUserID string    `json:"provider_user_id"`
+	EmailVerified  bool      `json:"email_verified"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+type AccountExportSession struct {
+	ID            uint       `json:"id"`
+	FamilyID      string     `json:"family_id,omitempty"`
+	UserAgent     string     `json:"user_agent"`
+	IP            string     `json:"ip"`
+	CreatedAt     time.Time  `json:"created_at"`
+	ExpiresAt     time.Time  `json:"expires_at"`
+	RevokedAt     *time.Time `json:"revoked_at,omitempty"`
+	RevokedReason string     `json:"revoked_reason,omitempty"`
+}
+
+type AccountExportLoginEvent struct {
+	At        time.Time `json:"at"`
+	SessionID uint      `json:"session_id"`
+	UserAgent string    `json:"user_agent"`
+	IP        string    `json:"ip"`
+}
+
+type AccountExportAuthEvent struct {
+	At        time.Time `json:"at"`
+	Event     string    `json:"event"`
+	Method    string    `json:"method,omitempty"`
+	Outcome   string    `json:"outcome"`
+	Reason    string    `json:"reason,omitempty"`
+	UserAgent string    `json:"user_agent,omitempty"`
+	IP        string    `json:"ip,omitempty"`
+}
+
+// AccountDataService serves a user's data export and runs account deletion:
+// self-service requests wait out a grace period before the sweeper erases
+// them, admin erasure is immediate.
+type AccountDataService struct {
+	cfg           *config.Config
+	userRepo      repository.UserRepository
+	localCredRepo repository.LocalCredentialRepository
+	oauthRepo     repository.OAuthRepository
+	sessionRepo   repository.SessionRepository
+	loginEvents   repository.LoginEventRepository
+	tokenSvc      *TokenService
+}
+
+func NewAccountDataService(
+	cfg *config.Config,
+	userRepo repository.UserRepository,
+	localCredRepo repository.LocalCredentialRepository,
+	oauthRepo repository.OAuthRepository,
+	sessionRepo repository.SessionRepository,
+	loginEvents repository.LoginEventRepository,
+	tokenSvc *TokenService,
+) *AccountDataService {
+	return &AccountDataService{
+		cfg:           cfg,
+		userRepo:      userRepo,
+		localCredRepo: localCredRepo,
+		oauthRepo:     oauthRepo,
+		sessionRepo:   sessionRepo,
+		loginEvents:   loginEvents,
+		tokenSvc:      tokenSvc,
+	}
+}
+
+func (s *AccountDataService) Export(userID uint) (*AccountExport, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	export := &AccountExport{
+		GeneratedAt: time.Now().UTC(),
+		Profile: AccountExportProfile{
+			ID:                  user.ID,
+			Email:               user.Email,
+			Name:                user.Name,
+			AvatarURL:           user.AvatarURL,
+			Status:              user.Status,
+			LastLoginAt:         user.LastLoginAt,
+			DeletionScheduledAt: user.DeletionScheduledAt,
+			CreatedAt:           user.CreatedAt,
+			UpdatedAt:           user.UpdatedAt,
+		},
+		Roles:        make([]string, 0, len(user.Roles)),
+		Identities:   []AccountExportIdentity{},
+		Sessions:     []AccountExportSession{},
+		LoginHistory: []AccountExportLoginEvent{},
+		AuthEvents:   []AccountExportAuthEvent{},
+	}
+	for _, role := range user.Roles {
+		export.Roles = append(export.Roles, role.Name)
+	}
+
+	cred, err := s.localCredRepo.FindByUserID(userID)
+	switch {
+	case err == nil:
+		export.LocalCredential = &AccountExportCredential{
+			EmailVerified:   cred.EmailVerified,
+			EmailVerifiedAt: cred.EmailVerifiedAt,
+			CreatedAt:       cred.CreatedAt,
+			UpdatedAt:       cred.UpdatedAt,
+		}
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		return nil, err
+	}
+
+	accounts, err := s.oauthRepo.ListByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, account := range accounts {
+		export.Identities = append(export.Identities, AccountExportIdentity{
+			ID:             account.ID,
+			Provider:       account.Provider,
+			ProviderUserID: account.ProviderUserID,
+			EmailVerified:  account.EmailVerified,
+			CreatedAt:      account.CreatedAt,
+		})
+	}
+
+	sessions, err := s.sessionRepo.ListIssuedSince(userID, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+	for _, session := range sessions {
+		export.Sessions = append(export.Sessions, AccountExportSession{
+			ID:            session.ID,
+			FamilyID:      getString(session.FamilyID),
+			UserAgent:     session.UserAgent,
+			IP:            session.IP,
+			CreatedAt:     session.CreatedAt,
+			ExpiresAt:     session.ExpiresAt,
+			RevokedAt:     session.RevokedAt,
+			RevokedReason: getString(session.RevokedReason),
+		})
+		if getString(session.ParentTokenID) == "" {
+			export.LoginHistory = append(export.LoginHistory, AccountExportLoginEvent{
+				At:        session.CreatedAt,
+				SessionID: session.ID,
+				UserAgent: session.UserAgent,
+				IP:        session.IP,
+			})
+		}
+	}
+
+	events, err := s.loginEvents.ListByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, event := range events {
+		export.AuthEvents = append(export.AuthEvents, AccountExportAuthEvent{
+			At:        event.CreatedAt,
+			Event:     event.Event,
+			Method:    event.Method,
+			Outcome:   event.Outcome,
+			Reason:    event.Reason,
+			UserAgent: event.UserAgent,
+			IP:        event.IP,
+		})
+	}
+	return export, nil
+}
+
+// ScheduleDeletion re-authenticates the caller, parks the account until the
+// grace period ends and signs it out everywhere. With no grace period the
+// account is erased on the spot.
+func (s *AccountDataService) ScheduleDeletion(userID, currentSessionID uint) (*domain.User, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.IsActive() {
+		return nil, ErrAccountInactive
+	}
+	if err := s.reauthenticated(userID, currentSessionID); err != nil {
+		return nil, err
+	}
+	if s.cfg.AuthAccountDeletionGracePeriod <= 0 {
+		erased, _, err := s.Erase(userID)
+		return erased, err
+	}
+	at := time.Now().UTC().Add(s.cfg.AuthAccountDeletionGracePeriod)
+	if err := s.userRepo.ScheduleDeletion(userID, at); err != nil {
+		return nil, err
+	}
+	if err := s.tokenSvc.RevokeAll(userID, "account_deletion_scheduled"); err != nil {
+		return nil, err
+	}
+	user.Status = domain.UserStatusPendingDeletion
+	user.DeletionScheduledAt = &at
+	return user, nil
+}
+
+// reauthenticated wants the caller's own session to have presented
+// credentials within AccountDeletionReauthMaxAge, by signing in or through
+// /auth/reauth. API keys and service accounts have no session and never pass.
+func (s *AccountDataService) reauthenticated(userID, currentSessionID uint) error {
+	if currentSessionID == 0 {
+		return ErrReauthRequired
+	}
+	session, err := s.sessionRepo.FindByIDForUser(userID, currentSessionID)
+	if err != nil {
+		if errors.Is(err, repository.ErrSessionNotFound) {
+			return ErrReauthRequired
+		}
+		return err
+	}
+	if session.RevokedAt != nil || session.AuthTime == nil || time.Since(*session.AuthTime) > AccountDeletionReauthMaxAge {
+		return ErrReauthRequired
+	}
+	return nil
+}
+
+// Erase revokes every session and anonymizes the account immediately. It
+// returns the anonymized user and the status the account had before; erasing
+// an already erased account is a no-op.
+func (s *AccountDataService) Erase(userID uint) (*domain.User, string, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, "", err
+	}
+	previous := user.Status
+	if previous == domain.UserStatusDeleted {
+		return user, previous, nil
+	}
+	// Revoke first: the denylist is built from session rows, which the erase
+	// deletes.
+	if err := s.tokenSvc.RevokeAll(userID, "account_erased"); err != nil {
+		return nil, previous, err
+	}
+	if err := s.userRepo.Erase(userID); err != nil {
+		return nil, previous, err
+	}
+	erased, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, previous, err
+	}
+	return erased, previous, nil
+}
+
+// PurgeDue erases accounts whose grace period ended before now. A failure on
+// one account does not stop the rest of the batch.
+func (s *AccountDataService) PurgeDue(ctx context.Context, now time.Time) (int, error) {
+	ids, err := s.userRepo.ListDueForDeletion(now, accountDeletionSweepBatch)
+	if err != nil {
+		observability.RecordUserAccountEvent(ctx, "purge", "error")
+		return 0, err
+	}
+	erased := 0
+	var errs []error
+	for _, id := range ids {
+		if _, _, err := s.Erase(id); err != nil {
+			observability.RecordUserAccountEvent(ctx, "purge", "error")
+			errs = append(errs, err)
+			continue
+		}
+		observability.RecordUserAccountEvent(ctx, "purge", "success")
+		erased++
+	}
+	return erased, errors.Join(errs...)
+}
+
+func (s *AccountDataService) RunPurgeLoop(ctx context.Context, logger *slog.Logger) {
+	interval := s.cfg.AuthAccountDeletionSweepInterval
+	if interval <= 0 {
+		interval = time.Hour
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			erased, err := s.PurgeDue(ctx, time.Now().UTC())
+			if err != nil && logger != nil {
+				logger.Warn("account deletion sweep failed", "error", err, "erased", erased)
+				continue
+			}
+			if erased > 0 && logger != nil {
+				logger.Info("account deletion sweep erased accounts", "erased", erased)
+			}
+		}
+	}
+}
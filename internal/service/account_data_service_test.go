@@ -0,0 +1,211 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/domain"
+)
+
+func newAccountDataServiceForTest(fx *authServiceFixture, sessionRepo *inMemorySessionRepo) *AccountDataService {
+	return NewAccountDataService(fx.cfg, fx.userRepo, fx.localRepo, fx.oauthRepo, sessionRepo, fx.loginEvents, newTestTokenService(sessionRepo))
+}
+
+func TestAccountDataServiceExport(t *testing.T) {
+	sessionRepo := newInMemorySessionRepo()
+	fx := newAuthServiceFixtureWithSessionRepo(sessionRepo)
+	svc := newAccountDataServiceForTest(fx, sessionRepo)
+	uid := fx.seedLocalUser("export@example.com", "Exporter", "StrongPass123!", true)
+	if err := fx.userRepo.AddRole(uid, 1); err != nil {
+		t.Fatalf("add role: %v", err)
+	}
+	if err := fx.oauthRepo.Create(&domain.OAuthAccount{UserID: uid, Provider: "google", ProviderUserID: "g-export", EmailVerified: true}); err != nil {
+		t.Fatalf("link identity: %v", err)
+	}
+
+	first, err := fx.auth.LoginWithLocalPassword("export@example.com", "StrongPass123!", "ua-1", "10.0.0.1")
+	if err != nil {
+		t.Fatalf("first login: %v", err)
+	}
+	if _, err := fx.auth.Refresh(first.RefreshToken, "ua-1", "10.0.0.1"); err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+	if _, err := fx.auth.LoginWithLocalPassword("export@example.com", "WrongPass123!", "ua-3", "10.0.0.3"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("expected failed login, got %v", err)
+	}
+	if _, err := fx.auth.LoginWithLocalPassword("export@example.com", "StrongPass123!", "ua-2", "10.0.0.2"); err != nil {
+		t.Fatalf("second login: %v", err)
+	}
+	if err := fx.auth.ChangeLocalPassword(uid, "StrongPass123!", "EvenStronger123!"); err != nil {
+		t.Fatalf("change password: %v", err)
+	}
+
+	export, err := svc.Export(uid)
+	if err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	if export.Profile.ID != uid || export.Profile.Email != "export@example.com" {
+		t.Fatalf("unexpected profile: %+v", export.Profile)
+	}
+	if len(export.Roles) != 1 || export.Roles[0] != "user" {
+		t.Fatalf("expected user role, got %v", export.Roles)
+	}
+	if export.LocalCredential == nil || !export.LocalCredential.EmailVerified {
+		t.Fatalf("expected verified local credential, got %+v", export.LocalCredential)
+	}
+	if len(export.Identities) != 1 || export.Identities[0].ProviderUserID != "g-export" {
+		t.Fatalf("expected linked identity, got %+v", export.Identities)
+	}
+	if len(export.Sessions) != 3 {
+		t.Fatalf("expected rotated and revoked sessions to be exported, got %d", len(export.Sessions))
+	}
+	if len(export.LoginHistory) != 2 {
+		t.Fatalf("expected one login event per sign-in, got %+v", export.LoginHistory)
+	}
+	want := []AccountExportAuthEvent{
+		{Event: "login", Method: "password", Outcome: "success", UserAgent: "ua-1", IP: "10.0.0.1"},
+		{Event: "login", Method: "password", Outcome: "failure", Reason: "invalid_credentials", UserAgent: "ua-3", IP: "10.0.0.3"},
+		{Event: "login", Method: "password", Outcome: "success", UserAgent: "ua-2", IP: "10.0.0.2"},
+		{Event: "password_change", Method: "password", Outcome: "success"},
+	}
+	if len(export.AuthEvents) != len(want) {
+		t.Fatalf("expected %d auth events, got %+v", len(want), export.AuthEvents)
+	}
+	for i, event := range export.AuthEvents {
+		if event.At.IsZero() {
+			t.Fatalf("expected auth event %d to carry a timestamp", i)
+		}
+		event.At = time.Time{}
+		if event != want[i] {
+			t.Fatalf("auth event %d: expected %+v, got %+v", i, want[i], event)
+		}
+	}
+}
+
+func TestAccountDataServiceScheduleDeletionAndPurge(t *testing.T) {
+	sessionRepo := newInMemorySessionRepo()
+	fx := newAuthServiceFixtureWithSessionRepo(sessionRepo)
+	fx.cfg.AuthAccountDeletionGracePeriod = 24 * time.Hour
+	svc := newAccountDataServiceForTest(fx, sessionRepo)
+	statusSvc := NewUserStatusService(fx.userRepo, newTestTokenService(sessionRepo))
+	uid := fx.seedLocalUser("leaving@example.com", "Leaving", "StrongPass123!", true)
+
+	login, err := fx.auth.LoginWithLocalPassword("leaving@example.com", "StrongPass123!", "ua", "127.0.0.1")
+	if err != nil {
+		t.Fatalf("login: %v", err)
+	}
+	active, err := sessionRepo.ListActiveByUserID(uid)
+	if err != nil || len(active) != 1 {
+		t.Fatalf("expected the login session, got %d err=%v", len(active), err)
+	}
+	before := time.Now().UTC()
+	user, err := svc.ScheduleDeletion(uid, active[0].ID)
+	if err != nil {
+		t.Fatalf("schedule deletion: %v", err)
+	}
+	if user.Status != domain.UserStatusPendingDeletion || user.DeletionScheduledAt == nil || user.DeletionScheduledAt.Before(before.Add(24*time.Hour)) {
+		t.Fatalf("expected deletion scheduled after the grace period, got %+v", user)
+	}
+	if _, err := fx.auth.Refresh(login.RefreshToken, "ua", "127.0.0.1"); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Fatalf("expected scheduling to revoke sessions, got %v", err)
+	}
+	if _, err := fx.auth.LoginWithLocalPassword("leaving@example.com", "StrongPass123!", "ua", "127.0.0.1"); !errors.Is(err, ErrAccountInactive) {
+		t.Fatalf("expected pending account to be unable to sign in, got %v", err)
+	}
+	if _, _, err := statusSvc.Suspend(uid); !errors.Is(err, ErrInvalidUserStatusTransition) {
+		t.Fatalf("expected suspend of pending deletion to fail, got %v", err)
+	}
+
+	if erased, err := svc.PurgeDue(context.Background(), time.Now().UTC()); err != nil || erased != 0 {
+		t.Fatalf("expected nothing erased inside the grace period, got %d err=%v", erased, err)
+	}
+	if erased, err := svc.PurgeDue(context.Background(), time.Now().UTC().Add(25*time.Hour)); err != nil || erased != 1 {
+		t.Fatalf("expected due account to be erased, got %d err=%v", erased, err)
+	}
+	got, err := fx.userRepo.FindByID(uid)
+	if err != nil {
+		t.Fatalf("find erased user: %v", err)
+	}
+	if got.Status != domain.UserStatusDeleted || got.Email != domain.ErasedUserEmail(uid) {
+		t.Fatalf("expected anonymized user, got %+v", got)
+	}
+	if _, err := fx.localRepo.FindByUserID(uid); err == nil {
+		t.Fatal("expected local credential to be removed")
+	}
+	if _, previous, err := svc.Erase(uid); err != nil || previous != domain.UserStatusDeleted {
+		t.Fatalf("expected repeated erase to be a no-op, got previous=%q err=%v", previous, err)
+	}
+	if _, _, err := statusSvc.Reactivate(uid); !errors.Is(err, ErrInvalidUserStatusTransition) {
+		t.Fatalf("expected erased account to stay erased, got %v", err)
+	}
+}
+
+func TestAccountDataServiceCancelScheduledDeletion(t *testing.T) {
+	sessionRepo := newInMemorySessionRepo()
+	fx := newAuthServiceFixtureWithSessionRepo(sessionRepo)
+	fx.cfg.AuthAccountDeletionGracePeriod = time.Hour
+	svc := newAccountDataServiceForTest(fx, sessionRepo)
+	statusSvc := NewUserStatusService(fx.userRepo, newTestTokenService(sessionRepo))
+	uid := fx.seedUser("oauth-only@example.com", "OAuth Only")
+	now := time.Now().UTC()
+	session := &domain.Session{UserID: uid, RefreshTokenHash: "oauth-only", AuthTime: &now, ExpiresAt: now.Add(time.Hour)}
+	if err := sessionRepo.Create(session); err != nil {
+		t.Fatalf("create session: %v", err)
+	}
+
+	if _, err := svc.ScheduleDeletion(uid, session.ID); err != nil {
+		t.Fatalf("schedule deletion: %v", err)
+	}
+
+	reactivated, previous, err := statusSvc.Reactivate(uid)
+	if err != nil || previous != domain.UserStatusPendingDeletion || reactivated.Status != domain.UserStatusActive || reactivated.DeletionScheduledAt != nil {
+		t.Fatalf("expected reactivate to cancel deletion, got user=%+v previous=%q err=%v", reactivated, previous, err)
+	}
+	if erased, err := svc.PurgeDue(context.Background(), time.Now().UTC().Add(2*time.Hour)); err != nil || erased != 0 {
+		t.Fatalf("expected cancelled account to survive the sweep, got %d err=%v", erased, err)
+	}
+}
+
+func TestAccountDataServiceScheduleDeletionRequiresRecentAuth(t *testing.T) {
+	sessionRepo := newInMemorySessionRepo()
+	fx := newAuthServiceFixtureWithSessionRepo(sessionRepo)
+	fx.cfg.AuthReauthMaxAge = 0
+	fx.cfg.AuthAccountDeletionGracePeriod = time.Hour
+	svc := newAccountDataServiceForTest(fx, sessionRepo)
+	uid := fx.seedUser("leaving@example.com", "Leaving")
+	other := fx.seedUser("other@example.com", "Other")
+
+	now := time.Now().UTC()
+	stale := now.Add(-AccountDeletionReauthMaxAge - time.Minute)
+	newSession := func(userID uint, hash string, authTime *time.Time) uint {
+		s := &domain.Session{UserID: userID, RefreshTokenHash: hash, AuthTime: authTime, ExpiresAt: now.Add(time.Hour)}
+		if err := sessionRepo.Create(s); err != nil {
+			t.Fatalf("create session: %v", err)
+		}
+		return s.ID
+	}
+	staleID := newSession(uid, "stale", &stale)
+	legacyID := newSession(uid, "legacy", nil)
+	othersID := newSession(other, "others", &now)
+	revokedID := newSession(uid, "revoked", &now)
+	if _, err := sessionRepo.RevokeByIDForUser(uid, revokedID, "logout"); err != nil {
+		t.Fatalf("revoke: %v", err)
+	}
+	// No session (API keys, service accounts), an old sign-in, a session that
+	// predates auth_time, someone else's session and a revoked one.
+	for _, sessionID := range []uint{0, staleID, legacyID, othersID, revokedID} {
+		if _, err := svc.ScheduleDeletion(uid, sessionID); !errors.Is(err, ErrReauthRequired) {
+			t.Fatalf("session %d: expected ErrReauthRequired with AUTH_REAUTH_MAX_AGE=0, got %v", sessionID, err)
+		}
+	}
+	if got, _ := fx.userRepo.FindByID(uid); got.Status != domain.UserStatusActive {
+		t.Fatalf("expected the account to stay active, got %q", got.Status)
+	}
+
+	freshID := newSession(uid, "fresh", &now)
+	if user, err := svc.ScheduleDeletion(uid, freshID); err != nil || user.Status != domain.UserStatusPendingDeletion {
+		t.Fatalf("expected a fresh session to schedule deletion, got user=%+v err=%v", user, err)
+	}
+}
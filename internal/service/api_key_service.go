@@ -0,0 +1,277 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/config"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/domain"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/repository"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/security"
+)
+
+const (
+	// APIKeyTokenPrefix marks bearer credentials that are API keys rather than
+	// JWTs, so the auth middleware can route them without trying to parse them.
+	APIKeyTokenPrefix = "ebsk_"
+	// APIKeyClaimsTokenType is the TokenType of claims built for an API key.
+	APIKeyClaimsTokenType = "api_key"
+
+	apiKeyTouchInterval = time.Minute
+)
+
+var (
+	ErrAPIKeysDisabled       = errors.New("api keys are disabled")
+	ErrInvalidAPIKey         = errors.New("invalid api key")
+	ErrInvalidAPIKeyRequest  = errors.New("invalid api key request")
+	ErrAPIKeyScopeNotAllowed = errors.New("api key scope exceeds owner permissions")
+	ErrAPIKeyLimitReached    = errors.New("api key limit reached")
+	ErrAPIKeyNotFound        = errors.New("api key not found")
+)
+
+type APIKeyInput struct {
+	Name      string
+	Scopes    []string
+	ExpiresAt *time.Time
+	// CallerKeyPermissions is set when another API key creates the key; the
+	// new key cannot grant more than the calling key. Nil means the owner.
+	CallerKeyPermissions []string
+}
+
+type APIKeyView struct {
+	ID         uint       `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// CreatedAPIKey carries the plaintext key; it is only returned at creation.
+type CreatedAPIKey struct {
+	APIKeyView
+	Key string `json:"key"`
+}
+
+// APIKeyPrincipal is the caller behind an API key. Permissions are already
+// capped to the key's scopes and the owner's current permissions.
+type APIKeyPrincipal struct {
+	KeyID       uint
+	UserID      uint
+	Permissions []string
+}
+
+// Claims shapes the principal like a verified access token so handlers and
+// RequirePermission work unchanged. The ID never matches a session.
+func (p *APIKeyPrincipal) Claims() *security.Claims {
+	return &security.Claims{
+		TokenType:   APIKeyClaimsTokenType,
+		Permissions: p.Permissions,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject: strconv.FormatUint(uint64(p.UserID), 10),
+			ID:      APIKeyClaimsTokenType + ":" + strconv.FormatUint(uint64(p.KeyID), 10),
+		},
+	}
+}
+
+type APIKeyService struct {
+	cfg     *config.Config
+	repo    repository.APIKeyRepository
+	userSvc UserServiceInterface
+	now     func() time.Time
+}
+
+func NewAPIKeyService(cfg *config.Config, repo repository.APIKeyRepository, userSvc UserServiceInterface) *APIKeyService {
+	return &APIKeyService{cfg: cfg, repo: repo, userSvc: userSvc, now: time.Now}
+}
+
+func (s *APIKeyService) CreateKey(userID uint, input APIKeyInput) (*CreatedAPIKey, error) {
+	if !s.cfg.AuthAPIKeysEnabled {
+		return nil, ErrAPIKeysDisabled
+	}
+	now := s.now().UTC()
+	name := strings.TrimSpace(input.Name)
+	if name == "" || len(name) > 128 {
+		return nil, ErrInvalidAPIKeyRequest
+	}
+	if input.ExpiresAt != nil && !input.ExpiresAt.After(now) {
+		return nil, ErrInvalidAPIKeyRequest
+	}
+	scopes := normalizeAPIKeyScopes(input.Scopes)
+	if len(scopes) == 0 {
+		return nil, ErrInvalidAPIKeyRequest
+	}
+	_, perms, err := s.userSvc.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if input.CallerKeyPermissions != nil {
+		perms = CapPermissions(perms, input.CallerKeyPermissions)
+	}
+	if !grantsAll(perms, scopes) {
+		return nil, ErrAPIKeyScopeNotAllowed
+	}
+	active, err := s.repo.CountActiveByUserID(userID, now)
+	if err != nil {
+		return nil, err
+	}
+	if active >= int64(s.cfg.AuthAPIKeyMaxPerUser) {
+		return nil, ErrAPIKeyLimitReached
+	}
+
+	prefix, err := newAPIKeyPrefix()
+	if err != nil {
+		return nil, err
+	}
+	secret, err := security.NewRandomString(32)
+	if err != nil {
+		return nil, err
+	}
+	raw := APIKeyTokenPrefix + prefix + "_" + secret
+	key := &domain.APIKey{
+		UserID:    userID,
+		Name:      name,
+		Prefix:    prefix,
+		KeyHash:   security.HashRefreshToken(raw, s.cfg.RefreshTokenPepper),
+		Scopes:    strings.Join(scopes, ","),
+		ExpiresAt: input.ExpiresAt,
+	}
+	if err := s.repo.Create(key); err != nil {
+		return nil, err
+	}
+	return &CreatedAPIKey{APIKeyView: apiKeyView(*key), Key: raw}, nil
+}
+
+func (s *APIKeyService) ListKeys(userID uint) ([]APIKeyView, error) {
+	keys, err := s.repo.ListByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	views := make([]APIKeyView, 0, len(keys))
+	for _, key := range keys {
+		views = append(views, apiKeyView(key))
+	}
+	return views, nil
+}
+
+func (s *APIKeyService) RevokeKey(userID, keyID uint) (bool, error) {
+	changed, err := s.repo.RevokeForUser(keyID, userID)
+	if errors.Is(err, repository.ErrAPIKeyNotFound) {
+		return false, ErrAPIKeyNotFound
+	}
+	return changed, err
+}
+
+// AuthenticateAPIKey resolves a presented key to its owner. The owner's
+// permissions are read on every call so a role change narrows existing keys
+// immediately.
+func (s *APIKeyService) AuthenticateAPIKey(_ context.Context, raw string) (*APIKeyPrincipal, error) {
+	if !s.cfg.AuthAPIKeysEnabled || !strings.HasPrefix(raw, APIKeyTokenPrefix) {
+		return nil, ErrInvalidAPIKey
+	}
+	key, err := s.repo.FindByHash(security.HashRefreshToken(raw, s.cfg.RefreshTokenPepper))
+	if err != nil {
+		if errors.Is(err, repository.ErrAPIKeyNotFound) {
+			return nil, ErrInvalidAPIKey
+		}
+		return nil, err
+	}
+	now := s.now().UTC()
+	if key.RevokedAt != nil || (key.ExpiresAt != nil && !key.ExpiresAt.After(now)) {
+		return nil, ErrInvalidAPIKey
+	}
+	owner, perms, err := s.userSvc.GetByID(key.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if !owner.IsActive() {
+		return nil, ErrAccountInactive
+	}
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= apiKeyTouchInterval {
+		_ = s.repo.TouchLastUsed(key.ID, now)
+	}
+	return &APIKeyPrincipal{
+		KeyID:       key.ID,
+		UserID:      key.UserID,
+		Permissions: CapPermissions(perms, splitAPIKeyScopes(key.Scopes)),
+	}, nil
+}
+
+// CapPermissions returns what perms and scopes both grant. A wildcard on one
+// side narrows to the other, so "users:*" capped by "users:read" yields
+// "users:read".
+func CapPermissions(perms, scopes []string) []string {
+	out := make([]string, 0, len(scopes))
+	seen := make(map[string]struct{}, len(perms))
+	for _, perm := range perms {
+		for _, scope := range scopes {
+			capped, ok := intersectPermission(perm, scope)
+			if !ok {
+				continue
+			}
+			if _, dup := seen[capped]; dup {
+				continue
+			}
+			seen[capped] = struct{}{}
+			out = append(out, capped)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+func apiKeyView(key domain.APIKey) APIKeyView {
+	return APIKeyView{
+		ID:         key.ID,
+		Name:       key.Name,
+		Prefix:     APIKeyTokenPrefix + key.Prefix,
+		Scopes:     splitAPIKeyScopes(key.Scopes),
+		ExpiresAt:  key.ExpiresAt,
+		LastUsedAt: key.LastUsedAt,
+		RevokedAt:  key.RevokedAt,
+		CreatedAt:  key.CreatedAt,
+	}
+}
+
+func normalizeAPIKeyScopes(scopes []string) []string {
+	seen := make(map[string]struct{}, len(scopes))
+	out := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		scope = strings.TrimSpace(scope)
+		if scope == "" {
+			continue
+		}
+		if _, dup := seen[scope]; dup {
+			continue
+		}
+		seen[scope] = struct{}{}
+		out = append(out, scope)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func splitAPIKeyScopes(raw string) []string {
+	if raw == "" {
+		return []string{}
+	}
+	return strings.Split(raw, ",")
+}
+
+func newAPIKeyPrefix() (string, error) {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,137 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/config"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/domain"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/repository"
+)
+
+func newAPIKeyServiceForTest(t *testing.T, perms []string) (*APIKeyService, *stubUserService) {
+	t.Helper()
+	dsn := fmt.Sprintf("file:%s?mode=memory&cache=shared", strings.ReplaceAll(t.Name(), "/", "_"))
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	if err := db.AutoMigrate(&domain.APIKey{}); err != nil {
+		t.Fatalf("migrate api keys: %v", err)
+	}
+	users := &stubUserService{perms: perms}
+	cfg := &config.Config{AuthAPIKeysEnabled: true, AuthAPIKeyMaxPerUser: 2, RefreshTokenPepper: "pepper-1234567890"}
+	return NewAPIKeyService(cfg, repository.NewAPIKeyRepository(db), users), users
+}
+
+func TestAPIKeyServiceCreateValidatesScopesAndLimit(t *testing.T) {
+	svc, _ := newAPIKeyServiceForTest(t, []string{"users:read", "roles:read"})
+	past := time.Now().Add(-time.Hour)
+
+	cases := []struct {
+		name  string
+		input APIKeyInput
+		want  error
+	}{
+		{name: "missing name", input: APIKeyInput{Scopes: []string{"users:read"}}, want: ErrInvalidAPIKeyRequest},
+		{name: "missing scopes", input: APIKeyInput{Name: "ci"}, want: ErrInvalidAPIKeyRequest},
+		{name: "past expiry", input: APIKeyInput{Name: "ci", Scopes: []string{"users:read"}, ExpiresAt: &past}, want: ErrInvalidAPIKeyRequest},
+		{name: "scope beyond owner", input: APIKeyInput{Name: "ci", Scopes: []string{"users:write"}}, want: ErrAPIKeyScopeNotAllowed},
+		{name: "scope beyond calling key", input: APIKeyInput{Name: "ci", Scopes: []string{"roles:read"}, CallerKeyPermissions: []string{"users:read"}}, want: ErrAPIKeyScopeNotAllowed},
+		{name: "calling key without permissions", input: APIKeyInput{Name: "ci", Scopes: []string{"users:read"}, CallerKeyPermissions: []string{}}, want: ErrAPIKeyScopeNotAllowed},
+	}
+	for _, tc := range cases {
+		if _, err := svc.CreateKey(1, tc.input); !errors.Is(err, tc.want) {
+			t.Fatalf("%s: expected %v, got %v", tc.name, tc.want, err)
+		}
+	}
+
+	for i := 0; i < 2; i++ {
+		created, err := svc.CreateKey(1, APIKeyInput{Name: "ci", Scopes: []string{" users:read ", "users:read"}})
+		if err != nil {
+			t.Fatalf("create: %v", err)
+		}
+		if !strings.HasPrefix(created.Key, created.Prefix+"_") || len(created.Scopes) != 1 {
+			t.Fatalf("unexpected created key: %+v", created)
+		}
+	}
+	if _, err := svc.CreateKey(1, APIKeyInput{Name: "ci", Scopes: []string{"users:read"}}); !errors.Is(err, ErrAPIKeyLimitReached) {
+		t.Fatalf("expected limit reached, got %v", err)
+	}
+
+	wildcard, _ := newAPIKeyServiceForTest(t, []string{"users:*"})
+	if _, err := wildcard.CreateKey(2, APIKeyInput{Name: "ci", Scopes: []string{"users:write"}}); err != nil {
+		t.Fatalf("expected users:* to allow a users:write scope, got %v", err)
+	}
+	if _, err := wildcard.CreateKey(2, APIKeyInput{Name: "ci", Scopes: []string{"*:write"}}); !errors.Is(err, ErrAPIKeyScopeNotAllowed) {
+		t.Fatalf("expected *:write to exceed users:*, got %v", err)
+	}
+}
+
+func TestAPIKeyServiceAuthenticateCapsToOwnerPermissions(t *testing.T) {
+	ctx := context.Background()
+	svc, users := newAPIKeyServiceForTest(t, []string{"users:read", "roles:read", "roles:write"})
+	created, err := svc.CreateKey(7, APIKeyInput{Name: "ci", Scopes: []string{"roles:read", "users:read"}})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	principal, err := svc.AuthenticateAPIKey(ctx, created.Key)
+	if err != nil {
+		t.Fatalf("authenticate: %v", err)
+	}
+	if principal.UserID != 7 || strings.Join(principal.Permissions, ",") != "roles:read,users:read" {
+		t.Fatalf("unexpected principal: %+v", principal)
+	}
+	if claims := principal.Claims(); claims.Subject != "7" || claims.TokenType != APIKeyClaimsTokenType {
+		t.Fatalf("unexpected claims: %+v", claims)
+	}
+
+	users.perms = []string{"users:read"}
+	principal, err = svc.AuthenticateAPIKey(ctx, created.Key)
+	if err != nil || strings.Join(principal.Permissions, ",") != "users:read" {
+		t.Fatalf("expected permissions narrowed with the owner, got %+v err=%v", principal, err)
+	}
+
+	users.status = domain.UserStatusSuspended
+	if _, err := svc.AuthenticateAPIKey(ctx, created.Key); !errors.Is(err, ErrAccountInactive) {
+		t.Fatalf("expected suspended owner to be rejected, got %v", err)
+	}
+	users.status = domain.UserStatusActive
+
+	if _, err := svc.AuthenticateAPIKey(ctx, created.Key+"x"); !errors.Is(err, ErrInvalidAPIKey) {
+		t.Fatalf("expected tampered key to fail, got %v", err)
+	}
+	if changed, err := svc.RevokeKey(7, created.ID); err != nil || !changed {
+		t.Fatalf("revoke: changed=%v err=%v", changed, err)
+	}
+	if _, err := svc.AuthenticateAPIKey(ctx, created.Key); !errors.Is(err, ErrInvalidAPIKey) {
+		t.Fatalf("expected revoked key to fail, got %v", err)
+	}
+	if _, err := svc.RevokeKey(8, created.ID); !errors.Is(err, ErrAPIKeyNotFound) {
+		t.Fatalf("expected other user revoke to be not found, got %v", err)
+	}
+}
+
+func TestAPIKeyServiceRejectsExpiredKey(t *testing.T) {
+	svc, _ := newAPIKeyServiceForTest(t, []string{"users:read"})
+	expires := time.Now().Add(time.Hour)
+	created, err := svc.CreateKey(1, APIKeyInput{Name: "short", Scopes: []string{"users:read"}, ExpiresAt: &expires})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	svc.now = func() time.Time { return expires.Add(time.Second) }
+	if _, err := svc.AuthenticateAPIKey(context.Background(), created.Key); !errors.Is(err, ErrInvalidAPIKey) {
+		t.Fatalf("expected expired key to fail, got %v", err)
+	}
+}
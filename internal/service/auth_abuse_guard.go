@@ -14,8 +14,11 @@ import (
 type AuthAbuseScope string
 
 const (
-	AuthAbuseScopeLogin  AuthAbuseScope = "login"
-	AuthAbuseScopeForgot AuthAbuseScope = "forgot"
+	AuthAbuseScopeLogin     AuthAbuseScope = "login"
+	AuthAbuseScopeForgot    AuthAbuseScope = "forgot"
+	AuthAbuseScopeMFA       AuthAbuseScope = "mfa"
+	AuthAbuseScopeMagicLink AuthAbuseScope = "magic_link"
+	AuthAbuseScopeReauth    AuthAbuseScope = "reauth"
 )
 
 type AuthAbusePolicy struct {
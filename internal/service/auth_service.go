@@ -8,13 +8,13 @@ import (
 	"fmt"
 	"net/mail"
 	"net/url"
-	"regexp"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/sandeepkv93/everything-backend-starter-kit/internal/config"
 	"github.com/sandeepkv93/everything-backend-starter-kit/internal/domain"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/observability"
 	"github.com/sandeepkv93/everything-backend-starter-kit/internal/repository"
 	"github.com/sandeepkv93/everything-backend-starter-kit/internal/security"
 
@@ -31,6 +31,11 @@ type AuthService struct {
 	verificationTokenRepo repository.VerificationTokenRepository
 	verificationNotifier  EmailVerificationNotifier
 	passwordResetNotifier PasswordResetNotifier
+	magicLinkNotifier     MagicLinkNotifier
+	mfaSvc                *MFAService
+	webauthnSvc           *WebAuthnService
+	passwordPolicy        *PasswordPolicy
+	loginEventRepo        repository.LoginEventRepository
 }
 
 type LoginResult struct {
@@ -40,6 +45,9 @@ type LoginResult struct {
 	CSRFToken            string       `json:"csrf_token,omitempty"`
 	ExpiresAt            time.Time    `json:"expires_at,omitempty"`
 	RequiresVerification bool         `json:"requires_verification,omitempty"`
+	MFARequired          bool         `json:"mfa_required,omitempty"`
+	MFAToken             string       `json:"mfa_token,omitempty"`
+	MFAMethod            string       `json:"-"`
 }
 
 var (
@@ -49,14 +57,28 @@ var (
 	ErrInvalidCredentials   = errors.New("invalid credentials")
 	ErrWeakPassword         = errors.New("password does not meet policy requirements")
 	ErrInvalidVerifyToken   = errors.New("invalid or expired verification token")
+	ErrIdentityNotFound     = errors.New("identity not found")
+	ErrLastCredential       = errors.New("cannot remove the last sign-in method")
+	ErrAccountInactive      = errors.New("account is not active")
+	ErrMagicLinkDisabled    = errors.New("magic link sign-in is disabled")
+	ErrPasswordExpired      = errors.New("password has expired and must be reset")
+	ErrReauthSessionInvalid = errors.New("session cannot be reauthenticated")
 )
 
-var (
-	uppercaseRe = regexp.MustCompile(`[A-Z]`)
-	lowercaseRe = regexp.MustCompile(`[a-z]`)
-	digitRe     = regexp.MustCompile(`[0-9]`)
-	specialRe   = regexp.MustCompile(`[^A-Za-z0-9]`)
-)
+// ReauthResult is a step-up outcome: a replacement access token for the same
+// session whose auth_time is AuthTime.
+type ReauthResult struct {
+	AccessToken string    `json:"-"`
+	AuthTime    time.Time `json:"auth_time"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// LinkedIdentities is what a user sees under /me/identities: the provider
+// accounts bound to them and whether they can also sign in with a password.
+type LinkedIdentities struct {
+	Identities      []domain.OAuthAccount `json:"identities"`
+	LocalCredential bool                  `json:"local_credential"`
+}
 
 func NewAuthService(
 	cfg *config.Config,
@@ -68,6 +90,11 @@ func NewAuthService(
 	verificationTokenRepo repository.VerificationTokenRepository,
 	verificationNotifier EmailVerificationNotifier,
 	passwordResetNotifier PasswordResetNotifier,
+	magicLinkNotifier MagicLinkNotifier,
+	mfaSvc *MFAService,
+	webauthnSvc *WebAuthnService,
+	passwordPolicy *PasswordPolicy,
+	loginEventRepo repository.LoginEventRepository,
 ) *AuthService {
 	return &AuthService{
 		cfg:                   cfg,
@@ -79,21 +106,33 @@ func NewAuthService(
 		verificationTokenRepo: verificationTokenRepo,
 		verificationNotifier:  verificationNotifier,
 		passwordResetNotifier: passwordResetNotifier,
+		magicLinkNotifier:     magicLinkNotifier,
+		mfaSvc:                mfaSvc,
+		webauthnSvc:           webauthnSvc,
+		passwordPolicy:        passwordPolicy,
+		loginEventRepo:        loginEventRepo,
 	}
 }
 
-func (s *AuthService) GoogleLoginURL(state string) string {
-	if !s.cfg.AuthGoogleEnabled {
-		return ""
+func (s *AuthService) OAuthProviderEnabled(provider string) bool {
+	if normalizeOAuthProviderName(provider) == "google" && !s.cfg.AuthGoogleEnabled {
+		return false
 	}
-	return s.oauthSvc.LoginURL(state)
+	return s.oauthSvc.ProviderEnabled(provider)
 }
 
-func (s *AuthService) LoginWithGoogleCode(code, ua, ip string) (*LoginResult, error) {
-	if !s.cfg.AuthGoogleEnabled {
+func (s *AuthService) OAuthLoginURL(flow security.OAuthFlowState) (string, error) {
+	if normalizeOAuthProviderName(flow.Provider) == "google" && !s.cfg.AuthGoogleEnabled {
+		return "", ErrGoogleAuthDisabled
+	}
+	return s.oauthSvc.LoginURL(flow)
+}
+
+func (s *AuthService) LoginWithOAuthCode(flow security.OAuthFlowState, code, ua, ip string) (*LoginResult, error) {
+	if normalizeOAuthProviderName(flow.Provider) == "google" && !s.cfg.AuthGoogleEnabled {
 		return nil, ErrGoogleAuthDisabled
 	}
-	user, err := s.oauthSvc.HandleGoogleCallback(context.Background(), code)
+	user, err := s.oauthSvc.HandleCallback(context.Background(), flow, code)
 	if err != nil {
 		return nil, err
 	}
@@ -104,11 +143,203 @@ func (s *AuthService) LoginWithGoogleCode(code, ua, ip string) (*LoginResult, er
 	if err != nil {
 		return nil, err
 	}
-	access, refresh, csrf, err := s.tokenSvc.Issue(user, perms, ua, ip)
+	return s.completeLogin(user, perms, domain.LoginMethodOAuth, ua, ip)
+}
+
+// LinkOAuthIdentity completes a link flow; the flow's LinkUserID was bound
+// into the signed state cookie by an authenticated, CSRF-checked request.
+func (s *AuthService) LinkOAuthIdentity(flow security.OAuthFlowState, code string) (*domain.OAuthAccount, error) {
+	if normalizeOAuthProviderName(flow.Provider) == "google" && !s.cfg.AuthGoogleEnabled {
+		return nil, ErrGoogleAuthDisabled
+	}
+	if _, _, err := s.userSvc.GetByID(flow.LinkUserID); err != nil {
+		return nil, err
+	}
+	return s.oauthSvc.LinkIdentity(context.Background(), flow, code)
+}
+
+// BeginOAuthReauth binds flow to the caller's session so the callback
+// refreshes auth_time instead of logging in, and returns the provider URL.
+func (s *AuthService) BeginOAuthReauth(userID uint, tokenID string, flow *security.OAuthFlowState) (string, error) {
+	familyID, err := s.tokenSvc.SessionFamilyID(userID, tokenID)
 	if err != nil {
+		if errors.Is(err, repository.ErrSessionNotFound) {
+			return "", ErrReauthSessionInvalid
+		}
+		return "", err
+	}
+	flow.ReauthUserID = userID
+	flow.ReauthFamilyID = familyID
+	return s.OAuthLoginURL(*flow)
+}
+
+// ReauthenticateWithOAuthCode completes a flow started by BeginOAuthReauth.
+// The provider identity must be one already linked to the session's user.
+func (s *AuthService) ReauthenticateWithOAuthCode(flow security.OAuthFlowState, code string) (*ReauthResult, error) {
+	if normalizeOAuthProviderName(flow.Provider) == "google" && !s.cfg.AuthGoogleEnabled {
+		return nil, ErrGoogleAuthDisabled
+	}
+	if err := s.oauthSvc.VerifyIdentity(context.Background(), flow, code); err != nil {
+		s.recordLoginEvent(flow.ReauthUserID, domain.LoginEventReauth, domain.LoginMethodOAuth, domain.LoginOutcomeFailure, "identity_mismatch", "", "")
 		return nil, err
 	}
-	return &LoginResult{User: user, AccessToken: access, RefreshToken: refresh, CSRFToken: csrf, ExpiresAt: time.Now().Add(s.cfg.JWTAccessTTL)}, nil
+	return s.reauthenticate(flow.ReauthUserID, flow.ReauthFamilyID, domain.LoginMethodOAuth)
+}
+
+// ReauthenticateWithPassword is the local-password step-up for the session
+// behind tokenID.
+func (s *AuthService) ReauthenticateWithPassword(userID uint, tokenID, password string) (*ReauthResult, error) {
+	if !s.cfg.AuthLocalEnabled {
+		return nil, ErrLocalAuthDisabled
+	}
+	familyID, err := s.tokenSvc.SessionFamilyID(userID, tokenID)
+	if err != nil {
+		if errors.Is(err, repository.ErrSessionNotFound) {
+			return nil, ErrReauthSessionInvalid
+		}
+		return nil, err
+	}
+	cred, err := s.localCredsRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, ErrInvalidCredentials
+	}
+	ok, err := security.VerifyPassword(cred.PasswordHash, password)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		s.recordLoginEvent(userID, domain.LoginEventReauth, domain.LoginMethodPassword, domain.LoginOutcomeFailure, "invalid_credentials", "", "")
+		return nil, ErrInvalidCredentials
+	}
+	s.upgradePasswordHash(cred, password)
+	return s.reauthenticate(userID, familyID, domain.LoginMethodPassword)
+}
+
+func (s *AuthService) reauthenticate(userID uint, familyID, method string) (*ReauthResult, error) {
+	user, perms, err := s.userSvc.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.IsActive() {
+		s.recordLoginEvent(userID, domain.LoginEventReauth, method, domain.LoginOutcomeFailure, "account_inactive", "", "")
+		return nil, ErrAccountInactive
+	}
+	access, authTime, err := s.tokenSvc.Reauthenticate(user, perms, familyID)
+	if err != nil {
+		if errors.Is(err, repository.ErrSessionNotFound) {
+			return nil, ErrReauthSessionInvalid
+		}
+		return nil, err
+	}
+	s.recordLoginEvent(userID, domain.LoginEventReauth, method, domain.LoginOutcomeSuccess, "", "", "")
+	return &ReauthResult{AccessToken: access, AuthTime: authTime, ExpiresAt: time.Now().Add(s.cfg.JWTAccessTTL)}, nil
+}
+
+func (s *AuthService) ListIdentities(userID uint) (*LinkedIdentities, error) {
+	identities, err := s.oauthSvc.ListIdentities(userID)
+	if err != nil {
+		return nil, err
+	}
+	hasLocal, err := s.hasLocalCredential(userID)
+	if err != nil {
+		return nil, err
+	}
+	return &LinkedIdentities{Identities: identities, LocalCredential: hasLocal}, nil
+}
+
+// UnlinkIdentity refuses to remove the identity when nothing else could sign
+// the user in afterwards: a password (with local auth on), a passkey (with
+// WebAuthn on) or another identity from an enabled provider.
+func (s *AuthService) UnlinkIdentity(userID, identityID uint) error {
+	identities, err := s.oauthSvc.ListIdentities(userID)
+	if err != nil {
+		return err
+	}
+	found := false
+	for _, identity := range identities {
+		if identity.ID == identityID {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return ErrIdentityNotFound
+	}
+	if err := s.ensureOtherSignInMethod(userID, identityID, 0); err != nil {
+		return err
+	}
+	return s.oauthSvc.UnlinkIdentity(userID, identityID)
+}
+
+// DeleteWebAuthnCredential removes a passkey under the same rule as
+// UnlinkIdentity.
+func (s *AuthService) DeleteWebAuthnCredential(userID, credentialID uint) error {
+	if !s.webauthnSvc.Enabled() {
+		return ErrWebAuthnDisabled
+	}
+	passkeys, err := s.webauthnSvc.ListCredentials(userID)
+	if err != nil {
+		return err
+	}
+	found := false
+	for _, passkey := range passkeys {
+		if passkey.ID == credentialID {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return ErrWebAuthnCredentialNotFound
+	}
+	if err := s.ensureOtherSignInMethod(userID, 0, credentialID); err != nil {
+		return err
+	}
+	return s.webauthnSvc.DeleteCredential(userID, credentialID)
+}
+
+// ensureOtherSignInMethod returns ErrLastCredential unless a sign-in method
+// other than the identity or passkey being removed remains.
+func (s *AuthService) ensureOtherSignInMethod(userID, removingIdentityID, removingPasskeyID uint) error {
+	identities, err := s.oauthSvc.ListIdentities(userID)
+	if err != nil {
+		return err
+	}
+	for _, identity := range identities {
+		if identity.ID != removingIdentityID && s.OAuthProviderEnabled(identity.Provider) {
+			return nil
+		}
+	}
+	if s.cfg.AuthLocalEnabled {
+		hasLocal, err := s.hasLocalCredential(userID)
+		if err != nil {
+			return err
+		}
+		if hasLocal {
+			return nil
+		}
+	}
+	if s.webauthnSvc.Enabled() {
+		passkeys, err := s.webauthnSvc.ListCredentials(userID)
+		if err != nil {
+			return err
+		}
+		for _, passkey := range passkeys {
+			if passkey.ID != removingPasskeyID {
+				return nil
+			}
+		}
+	}
+	return ErrLastCredential
+}
+
+func (s *AuthService) hasLocalCredential(userID uint) (bool, error) {
+	if _, err := s.localCredsRepo.FindByUserID(userID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
 }
 
 func (s *AuthService) RegisterLocal(email, name, password, ua, ip string) (*LoginResult, error) {
@@ -123,7 +354,7 @@ func (s *AuthService) RegisterLocal(email, name, password, ua, ip string) (*Logi
 	if name == "" {
 		return nil, fmt.Errorf("name is required")
 	}
-	if err := validatePassword(password); err != nil {
+	if err := s.passwordPolicy.Validate(password, PasswordSubject{Email: email, Name: name}); err != nil {
 		return nil, err
 	}
 	if _, err := s.userSvc.userRepo.FindByEmail(email); err == nil {
@@ -132,7 +363,7 @@ func (s *AuthService) RegisterLocal(email, name, password, ua, ip string) (*Logi
 		return nil, err
 	}
 
-	user := &domain.User{Email: email, Name: name, Status: "active"}
+	user := &domain.User{Email: email, Name: name, Status: domain.UserStatusActive}
 	if err := s.userSvc.userRepo.Create(user); err != nil {
 		return nil, err
 	}
@@ -146,13 +377,14 @@ func (s *AuthService) RegisterLocal(email, name, password, ua, ip string) (*Logi
 		return nil, err
 	}
 	verified := !s.cfg.AuthLocalRequireEmailVerification
+	now := time.Now().UTC()
 	credential := &domain.LocalCredential{
-		UserID:        user.ID,
-		PasswordHash:  hash,
-		EmailVerified: verified,
+		UserID:            user.ID,
+		PasswordHash:      hash,
+		PasswordChangedAt: &now,
+		EmailVerified:     verified,
 	}
 	if verified {
-		now := time.Now().UTC()
 		credential.EmailVerifiedAt = &now
 	}
 	if err := s.localCredsRepo.Create(credential); err != nil {
@@ -195,23 +427,148 @@ func (s *AuthService) LoginWithLocalPassword(email, password, ua, ip string) (*L
 		return nil, err
 	}
 	if !ok {
+		s.recordLoginEvent(cred.UserID, domain.LoginEventLogin, domain.LoginMethodPassword, domain.LoginOutcomeFailure, "invalid_credentials", ua, ip)
 		return nil, ErrInvalidCredentials
 	}
+	s.upgradePasswordHash(cred, password)
 	if s.cfg.AuthLocalRequireEmailVerification && !cred.EmailVerified {
+		s.recordLoginEvent(cred.UserID, domain.LoginEventLogin, domain.LoginMethodPassword, domain.LoginOutcomeFailure, "email_unverified", ua, ip)
 		return nil, ErrLocalEmailUnverified
 	}
+	if s.passwordPolicy.Expired(cred.PasswordChangedOrCreatedAt(), time.Now().UTC()) {
+		s.recordLoginEvent(cred.UserID, domain.LoginEventLogin, domain.LoginMethodPassword, domain.LoginOutcomeFailure, "password_expired", ua, ip)
+		return nil, ErrPasswordExpired
+	}
 	user, perms, err := s.userSvc.GetByID(cred.UserID)
 	if err != nil {
 		return nil, err
 	}
+	return s.completeLogin(user, perms, domain.LoginMethodPassword, ua, ip)
+}
+
+// completeLogin issues session tokens once the first factor has passed, or an
+// MFA challenge instead when the user has a confirmed second factor.
+func (s *AuthService) completeLogin(user *domain.User, perms []string, method, ua, ip string) (*LoginResult, error) {
+	if !user.IsActive() {
+		s.recordLoginEvent(user.ID, domain.LoginEventLogin, method, domain.LoginOutcomeFailure, "account_inactive", ua, ip)
+		return nil, ErrAccountInactive
+	}
+	if s.mfaSvc != nil {
+		enabled, err := s.mfaSvc.MFAEnabled(context.Background(), user.ID)
+		if err != nil {
+			return nil, err
+		}
+		if enabled {
+			token, expiresAt, err := s.mfaSvc.IssueChallenge(user.ID)
+			if err != nil {
+				return nil, err
+			}
+			s.recordLoginEvent(user.ID, domain.LoginEventLogin, method, domain.LoginOutcomeMFARequired, "", ua, ip)
+			return &LoginResult{User: user, MFARequired: true, MFAToken: token, ExpiresAt: expiresAt}, nil
+		}
+	}
+	access, refresh, csrf, err := s.tokenSvc.Issue(user, perms, ua, ip)
+	if err != nil {
+		return nil, err
+	}
+	s.recordLoginEvent(user.ID, domain.LoginEventLogin, method, domain.LoginOutcomeSuccess, "", ua, ip)
+	return &LoginResult{User: user, AccessToken: access, RefreshToken: refresh, CSRFToken: csrf, ExpiresAt: time.Now().Add(s.cfg.JWTAccessTTL)}, nil
+}
+
+func (s *AuthService) ResolveMFAChallenge(challengeToken string) (uint, error) {
+	if s.mfaSvc == nil {
+		return 0, ErrMFAUnavailable
+	}
+	record, err := s.mfaSvc.ResolveChallenge(challengeToken)
+	if err != nil {
+		return 0, err
+	}
+	return record.UserID, nil
+}
+
+// VerifyMFALogin completes a two-step login. A wrong code leaves the challenge
+// active so the user can retry until it expires; the abuse guard in the handler
+// bounds the number of attempts.
+func (s *AuthService) VerifyMFALogin(challengeToken, code, ua, ip string) (*LoginResult, error) {
+	if s.mfaSvc == nil {
+		return nil, ErrMFAUnavailable
+	}
+	record, err := s.mfaSvc.ResolveChallenge(challengeToken)
+	if err != nil {
+		return nil, err
+	}
+	method, err := s.mfaSvc.VerifyCode(record.UserID, code)
+	if err != nil {
+		if errors.Is(err, ErrInvalidMFACode) {
+			s.recordLoginEvent(record.UserID, domain.LoginEventMFAChallenge, "", domain.LoginOutcomeFailure, "invalid_code", ua, ip)
+		}
+		return nil, err
+	}
+	if err := s.mfaSvc.ConsumeChallenge(record); err != nil {
+		return nil, err
+	}
+	user, perms, err := s.userSvc.GetByID(record.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.IsActive() {
+		s.recordLoginEvent(user.ID, domain.LoginEventMFAChallenge, method, domain.LoginOutcomeFailure, "account_inactive", ua, ip)
+		return nil, ErrAccountInactive
+	}
+	access, refresh, csrf, err := s.tokenSvc.Issue(user, perms, ua, ip)
+	if err != nil {
+		return nil, err
+	}
+	s.recordLoginEvent(user.ID, domain.LoginEventMFAChallenge, method, domain.LoginOutcomeSuccess, "", ua, ip)
+	return &LoginResult{User: user, AccessToken: access, RefreshToken: refresh, CSRFToken: csrf, ExpiresAt: time.Now().Add(s.cfg.JWTAccessTTL), MFAMethod: method}, nil
+}
+
+// LoginWithWebAuthn completes a passkey login. Registration and assertion both
+// require user verification, so the passkey already counts as two factors and
+// no TOTP challenge is issued.
+func (s *AuthService) LoginWithWebAuthn(challengeID string, credential []byte, ua, ip string) (*LoginResult, error) {
+	if !s.webauthnSvc.Enabled() {
+		return nil, ErrWebAuthnDisabled
+	}
+	userID, err := s.webauthnSvc.FinishLogin(context.Background(), challengeID, credential)
+	if err != nil {
+		return nil, err
+	}
+	user, perms, err := s.userSvc.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.IsActive() {
+		s.recordLoginEvent(user.ID, domain.LoginEventLogin, domain.LoginMethodPasskey, domain.LoginOutcomeFailure, "account_inactive", ua, ip)
+		return nil, ErrAccountInactive
+	}
 	access, refresh, csrf, err := s.tokenSvc.Issue(user, perms, ua, ip)
 	if err != nil {
 		return nil, err
 	}
+	s.recordLoginEvent(user.ID, domain.LoginEventLogin, domain.LoginMethodPasskey, domain.LoginOutcomeSuccess, "", ua, ip)
 	return &LoginResult{User: user, AccessToken: access, RefreshToken: refresh, CSRFToken: csrf, ExpiresAt: time.Now().Add(s.cfg.JWTAccessTTL)}, nil
 }
 
-func (s *AuthService) RequestLocalEmailVerification(email string) error {
+func (s *AuthService) BeginTOTPEnrollment(userID uint) (*TOTPEnrollment, error) {
+	if s.mfaSvc == nil {
+		return nil, ErrMFAUnavailable
+	}
+	user, _, err := s.userSvc.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.mfaSvc.BeginTOTPEnrollment(userID, user.Email)
+}
+
+func (s *AuthService) ConfirmTOTPEnrollment(userID uint, code string) ([]string, error) {
+	if s.mfaSvc == nil {
+		return nil, ErrMFAUnavailable
+	}
+	return s.mfaSvc.ConfirmTOTPEnrollment(userID, code)
+}
+
+func (s *AuthService) RequestLocalEmailVerification(email, locale string) error {
 	if !s.cfg.AuthLocalEnabled {
 		return ErrLocalAuthDisabled
 	}
@@ -241,16 +598,6 @@ func (s *AuthService) RequestLocalEmailVerification(email string) error {
 		return err
 	}
 	expiresAt := now.Add(s.cfg.AuthEmailVerifyTokenTTL)
-	hash := hashVerificationToken(rawToken)
-	if err := s.verificationTokenRepo.Create(&domain.VerificationToken{
-		UserID:    cred.UserID,
-		TokenHash: hash,
-		Purpose:   "email_verify",
-		ExpiresAt: expiresAt,
-	}); err != nil {
-		return err
-	}
-
 	verifyURL := ""
 	if strings.TrimSpace(s.cfg.AuthEmailVerifyBaseURL) != "" {
 		u, err := url.Parse(s.cfg.AuthEmailVerifyBaseURL)
@@ -263,13 +610,27 @@ func (s *AuthService) RequestLocalEmailVerification(email string) error {
 		verifyURL = u.String()
 	}
 
-	return s.verificationNotifier.SendEmailVerification(context.Background(), VerificationNotification{
+	token := &domain.VerificationToken{
+		UserID:    cred.UserID,
+		TokenHash: hashVerificationToken(rawToken),
+		Purpose:   "email_verify",
+		ExpiresAt: expiresAt,
+	}
+	notification := VerificationNotification{
 		UserID:          cred.UserID,
 		Email:           email,
 		Token:           rawToken,
 		ExpiresAt:       expiresAt,
 		VerificationURL: verifyURL,
-	})
+		Locale:          locale,
+	}
+	if s.cfg.NotificationOutboxEnabled {
+		return s.enqueueNotification(token, OutboxKindEmailVerification, notification, now)
+	}
+	if err := s.verificationTokenRepo.Create(token); err != nil {
+		return err
+	}
+	return s.verificationNotifier.SendEmailVerification(context.Background(), notification)
 }
 
 func (s *AuthService) ConfirmLocalEmailVerification(token string) error {
@@ -300,7 +661,7 @@ func (s *AuthService) ConfirmLocalEmailVerification(token string) error {
 	return nil
 }
 
-func (s *AuthService) ForgotLocalPassword(email string) error {
+func (s *AuthService) ForgotLocalPassword(email, locale string) error {
 	if !s.cfg.AuthLocalEnabled {
 		return ErrLocalAuthDisabled
 	}
@@ -321,25 +682,31 @@ func (s *AuthService) ForgotLocalPassword(email string) error {
 		return err
 	}
 
-	rawToken, err := security.NewRandomString(32)
+	token, notification, err := newPasswordReset(s.cfg, cred.UserID, email, s.cfg.AuthPasswordResetTokenTTL, now)
 	if err != nil {
 		return err
 	}
-	expiresAt := now.Add(s.cfg.AuthPasswordResetTokenTTL)
-	if err := s.verificationTokenRepo.Create(&domain.VerificationToken{
-		UserID:    cred.UserID,
-		TokenHash: hashVerificationToken(rawToken),
-		Purpose:   "password_reset",
-		ExpiresAt: expiresAt,
-	}); err != nil {
+	notification.Locale = locale
+	if s.cfg.NotificationOutboxEnabled {
+		return s.enqueueNotification(token, OutboxKindPasswordReset, notification, now)
+	}
+	if err := s.verificationTokenRepo.Create(token); err != nil {
 		return err
 	}
+	return s.passwordResetNotifier.SendPasswordReset(context.Background(), notification)
+}
 
+func newPasswordReset(cfg *config.Config, userID uint, email string, ttl time.Duration, now time.Time) (*domain.VerificationToken, PasswordResetNotification, error) {
+	rawToken, err := security.NewRandomString(32)
+	if err != nil {
+		return nil, PasswordResetNotification{}, err
+	}
+	expiresAt := now.Add(ttl)
 	resetURL := ""
-	if strings.TrimSpace(s.cfg.AuthPasswordResetBaseURL) != "" {
-		u, err := url.Parse(s.cfg.AuthPasswordResetBaseURL)
+	if strings.TrimSpace(cfg.AuthPasswordResetBaseURL) != "" {
+		u, err := url.Parse(cfg.AuthPasswordResetBaseURL)
 		if err != nil {
-			return fmt.Errorf("invalid AUTH_PASSWORD_RESET_BASE_URL: %w", err)
+			return nil, PasswordResetNotification{}, fmt.Errorf("invalid AUTH_PASSWORD_RESET_BASE_URL: %w", err)
 		}
 		q := u.Query()
 		q.Set("token", rawToken)
@@ -347,22 +714,157 @@ func (s *AuthService) ForgotLocalPassword(email string) error {
 		resetURL = u.String()
 	}
 
-	return s.passwordResetNotifier.SendPasswordReset(context.Background(), PasswordResetNotification{
-		UserID:      cred.UserID,
+	token := &domain.VerificationToken{
+		UserID:    userID,
+		TokenHash: hashVerificationToken(rawToken),
+		Purpose:   "password_reset",
+		ExpiresAt: expiresAt,
+	}
+	notification := PasswordResetNotification{
+		UserID:      userID,
 		Email:       email,
 		Token:       rawToken,
 		ExpiresAt:   expiresAt,
 		PasswordURL: resetURL,
-	})
+	}
+	return token, notification, nil
+}
+
+// NewPasswordResetInvite builds a password-reset token valid for ttl and the
+// outbox message that mails its link, for bulk tools that write both inside
+// their own transaction and leave delivery to the API's dispatcher.
+func NewPasswordResetInvite(cfg *config.Config, userID uint, email string, ttl time.Duration, now time.Time) (*domain.VerificationToken, *domain.OutboxMessage, error) {
+	token, notification, err := newPasswordReset(cfg, userID, email, ttl, now)
+	if err != nil {
+		return nil, nil, err
+	}
+	msg, err := newOutboxMessage(cfg, OutboxKindPasswordReset, userID, notification, token.ExpiresAt, now)
+	if err != nil {
+		return nil, nil, err
+	}
+	return token, msg, nil
+}
+
+// RequestMagicLink mails a one-time sign-in link. Unknown and inactive
+// accounts return nil so the endpoint cannot be used to enumerate users.
+func (s *AuthService) RequestMagicLink(email, locale string) error {
+	if !s.cfg.AuthMagicLinkEnabled {
+		return ErrMagicLinkDisabled
+	}
+	email = strings.TrimSpace(strings.ToLower(email))
+	if email == "" {
+		return nil
+	}
+	user, err := s.userSvc.FindByEmail(email)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	if !user.IsActive() {
+		return nil
+	}
+
+	now := time.Now().UTC()
+	if err := s.verificationTokenRepo.InvalidateActiveByUserPurpose(user.ID, "magic_login", now); err != nil {
+		return err
+	}
+
+	rawToken, err := security.NewRandomString(32)
+	if err != nil {
+		return err
+	}
+	expiresAt := now.Add(s.cfg.AuthMagicLinkTokenTTL)
+	loginURL := ""
+	if strings.TrimSpace(s.cfg.AuthMagicLinkBaseURL) != "" {
+		u, err := url.Parse(s.cfg.AuthMagicLinkBaseURL)
+		if err != nil {
+			return fmt.Errorf("invalid AUTH_MAGIC_LINK_BASE_URL: %w", err)
+		}
+		q := u.Query()
+		q.Set("token", rawToken)
+		u.RawQuery = q.Encode()
+		loginURL = u.String()
+	}
+
+	token := &domain.VerificationToken{
+		UserID:    user.ID,
+		TokenHash: hashVerificationToken(rawToken),
+		Purpose:   "magic_login",
+		ExpiresAt: expiresAt,
+	}
+	notification := MagicLinkNotification{
+		UserID:    user.ID,
+		Email:     email,
+		Token:     rawToken,
+		ExpiresAt: expiresAt,
+		LoginURL:  loginURL,
+		Locale:    locale,
+	}
+	if s.cfg.NotificationOutboxEnabled {
+		return s.enqueueNotification(token, OutboxKindMagicLink, notification, now)
+	}
+	if err := s.verificationTokenRepo.Create(token); err != nil {
+		return err
+	}
+	return s.magicLinkNotifier.SendMagicLink(context.Background(), notification)
+}
+
+// enqueueNotification stores the token together with its outbox message so
+// the request succeeds even while the mail provider is down; the dispatcher
+// delivers it later.
+func (s *AuthService) enqueueNotification(token *domain.VerificationToken, kind string, notification any, now time.Time) error {
+	msg, err := newOutboxMessage(s.cfg, kind, token.UserID, notification, token.ExpiresAt, now)
+	if err != nil {
+		return err
+	}
+	if err := s.verificationTokenRepo.CreateWithOutbox(token, msg); err != nil {
+		return err
+	}
+	observability.RecordNotificationOutboxEvent(context.Background(), kind, "enqueued")
+	return nil
+}
+
+// ConfirmMagicLink consumes a magic-link token and signs the user in. The
+// link proves control of the mailbox, so a local credential is marked
+// verified; MFA still applies through completeLogin.
+func (s *AuthService) ConfirmMagicLink(token, ua, ip string) (*LoginResult, error) {
+	if !s.cfg.AuthMagicLinkEnabled {
+		return nil, ErrMagicLinkDisabled
+	}
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return nil, ErrInvalidVerifyToken
+	}
+	now := time.Now().UTC()
+	record, err := s.verificationTokenRepo.FindActiveByHashPurpose(hashVerificationToken(token), "magic_login", now)
+	if err != nil {
+		if errors.Is(err, repository.ErrVerificationTokenNotFound) {
+			return nil, ErrInvalidVerifyToken
+		}
+		return nil, err
+	}
+	if err := s.verificationTokenRepo.Consume(record.ID, record.UserID, now); err != nil {
+		if errors.Is(err, repository.ErrVerificationTokenNotFound) {
+			return nil, ErrInvalidVerifyToken
+		}
+		return nil, err
+	}
+	user, perms, err := s.userSvc.GetByID(record.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.localCredsRepo.MarkEmailVerified(user.ID); err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	return s.completeLogin(user, perms, domain.LoginMethodMagicLink, ua, ip)
 }
 
 func (s *AuthService) ResetLocalPassword(token, newPassword string) error {
 	if !s.cfg.AuthLocalEnabled {
 		return ErrLocalAuthDisabled
 	}
-	if err := validatePassword(newPassword); err != nil {
-		return err
-	}
 	token = strings.TrimSpace(token)
 	if token == "" {
 		return ErrInvalidVerifyToken
@@ -375,6 +877,18 @@ func (s *AuthService) ResetLocalPassword(token, newPassword string) error {
 		}
 		return err
 	}
+	// Validate before consuming so a rejected password leaves the link usable.
+	user, err := s.userSvc.userRepo.FindByID(record.UserID)
+	if err != nil {
+		return err
+	}
+	cred, err := s.localCredsRepo.FindByUserID(record.UserID)
+	if err != nil {
+		return err
+	}
+	if err := s.validateNewPassword(newPassword, user, cred); err != nil {
+		return err
+	}
 	if err := s.verificationTokenRepo.Consume(record.ID, record.UserID, now); err != nil {
 		if errors.Is(err, repository.ErrVerificationTokenNotFound) {
 			return ErrInvalidVerifyToken
@@ -386,9 +900,10 @@ func (s *AuthService) ResetLocalPassword(token, newPassword string) error {
 	if err != nil {
 		return err
 	}
-	if err := s.localCredsRepo.UpdatePassword(record.UserID, newHash); err != nil {
+	if err := s.localCredsRepo.UpdatePassword(record.UserID, newHash, s.passwordPolicy.HistoryCount()); err != nil {
 		return err
 	}
+	s.recordLoginEvent(record.UserID, domain.LoginEventPasswordReset, domain.LoginMethodPassword, domain.LoginOutcomeSuccess, "", "", "")
 	return s.tokenSvc.RevokeAll(record.UserID, "password_reset")
 }
 
@@ -396,9 +911,6 @@ func (s *AuthService) ChangeLocalPassword(userID uint, currentPassword, newPassw
 	if !s.cfg.AuthLocalEnabled {
 		return ErrLocalAuthDisabled
 	}
-	if err := validatePassword(newPassword); err != nil {
-		return err
-	}
 	cred, err := s.localCredsRepo.FindByUserID(userID)
 	if err != nil {
 		return ErrInvalidCredentials
@@ -408,18 +920,27 @@ func (s *AuthService) ChangeLocalPassword(userID uint, currentPassword, newPassw
 		return err
 	}
 	if !ok {
+		s.recordLoginEvent(userID, domain.LoginEventPasswordChange, domain.LoginMethodPassword, domain.LoginOutcomeFailure, "invalid_credentials", "", "")
 		return ErrInvalidCredentials
 	}
 	if currentPassword == newPassword {
 		return fmt.Errorf("new password must differ from current password")
 	}
+	user, err := s.userSvc.userRepo.FindByID(userID)
+	if err != nil {
+		return err
+	}
+	if err := s.validateNewPassword(newPassword, user, cred); err != nil {
+		return err
+	}
 	newHash, err := security.HashPassword(newPassword)
 	if err != nil {
 		return err
 	}
-	if err := s.localCredsRepo.UpdatePassword(userID, newHash); err != nil {
+	if err := s.localCredsRepo.UpdatePassword(userID, newHash, s.passwordPolicy.HistoryCount()); err != nil {
 		return err
 	}
+	s.recordLoginEvent(userID, domain.LoginEventPasswordChange, domain.LoginMethodPassword, domain.LoginOutcomeSuccess, "", "", "")
 	return s.tokenSvc.RevokeAll(userID, "password_change")
 }
 
@@ -471,12 +992,65 @@ func validateEmail(email string) error {
 	return nil
 }
 
-func validatePassword(password string) error {
-	if len(password) < 12 || !uppercaseRe.MatchString(password) ||
-		!lowercaseRe.MatchString(password) || !digitRe.MatchString(password) || !specialRe.MatchString(password) {
-		return ErrWeakPassword
+// recordLoginEvent appends to the user's sign-in history. Like the audit log
+// it is best effort: a failed write is counted and never fails the request.
+func (s *AuthService) recordLoginEvent(userID uint, event, method, outcome, reason, ua, ip string) {
+	if s.loginEventRepo == nil || userID == 0 {
+		return
+	}
+	err := s.loginEventRepo.Create(&domain.LoginEvent{
+		UserID:    userID,
+		Event:     event,
+		Method:    method,
+		Outcome:   outcome,
+		Reason:    reason,
+		IP:        ip,
+		UserAgent: ua,
+	})
+	if err != nil {
+		observability.RecordRepositoryOperation(context.Background(), "login_event", "create", "error")
 	}
-	return nil
+}
+
+// upgradePasswordHash re-encodes a just-verified password when the stored hash
+// is an imported bcrypt/scrypt hash or uses outdated argon2id parameters.
+// It is best effort: a failure leaves the old hash, which still verifies, and
+// the next login tries again.
+func (s *AuthService) upgradePasswordHash(cred *domain.LocalCredential, password string) {
+	if !security.PasswordNeedsRehash(cred.PasswordHash) {
+		return
+	}
+	ctx := context.Background()
+	from := security.PasswordHashAlgorithm(cred.PasswordHash)
+	newHash, err := security.HashPassword(password)
+	if err != nil {
+		observability.RecordPasswordRehash(ctx, from, "error")
+		return
+	}
+	upgraded, err := s.localCredsRepo.UpgradePasswordHash(cred.UserID, cred.PasswordHash, newHash)
+	switch {
+	case err != nil:
+		observability.RecordPasswordRehash(ctx, from, "error")
+	case !upgraded:
+		observability.RecordPasswordRehash(ctx, from, "skipped")
+	default:
+		cred.PasswordHash = newHash
+		observability.RecordPasswordRehash(ctx, from, "upgraded")
+	}
+}
+
+// validateNewPassword checks a replacement password against the policy. When
+// history is kept, the current password counts as the most recent entry.
+func (s *AuthService) validateNewPassword(password string, user *domain.User, cred *domain.LocalCredential) error {
+	subject := PasswordSubject{Email: user.Email, Name: user.Name}
+	if limit := s.passwordPolicy.HistoryCount(); limit > 0 {
+		history, err := s.localCredsRepo.ListPasswordHistory(user.ID, limit)
+		if err != nil {
+			return err
+		}
+		subject.PreviousHashes = append([]string{cred.PasswordHash}, history...)
+	}
+	return s.passwordPolicy.Validate(password, subject)
 }
 
 func hashVerificationToken(raw string) string {
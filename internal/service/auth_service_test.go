@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
 	"testing"
@@ -13,6 +14,7 @@ import (
 	"github.com/sandeepkv93/everything-backend-starter-kit/internal/domain"
 	"github.com/sandeepkv93/everything-backend-starter-kit/internal/repository"
 	"github.com/sandeepkv93/everything-backend-starter-kit/internal/security"
+	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 )
 
@@ -161,6 +163,52 @@ func TestAuthServiceLoginWithLocalPasswordMatrix(t *testing.T) {
 		}
 	})
 
+	t.Run("expired password rejected", func(t *testing.T) {
+		fx := newAuthServiceFixture()
+		fx.cfg.PasswordMaxAge = 90 * 24 * time.Hour
+		fx.auth.passwordPolicy, _ = NewPasswordPolicy(fx.cfg)
+		uid := fx.seedLocalUser("user@example.com", "User", "StrongPass123!", true)
+		changedAt := time.Now().UTC().Add(-91 * 24 * time.Hour)
+		fx.localRepo.byUserID[uid].PasswordChangedAt = &changedAt
+
+		_, err := fx.auth.LoginWithLocalPassword("user@example.com", "StrongPass123!", "ua", "127.0.0.1")
+		if !errors.Is(err, ErrPasswordExpired) {
+			t.Fatalf("expected ErrPasswordExpired, got %v", err)
+		}
+	})
+
+	t.Run("imported bcrypt hash is upgraded on login", func(t *testing.T) {
+		fx := newAuthServiceFixture()
+		uid := fx.seedUser("legacy@example.com", "Legacy")
+		legacy, err := bcrypt.GenerateFromPassword([]byte("StrongPass123!"), bcrypt.MinCost)
+		if err != nil {
+			t.Fatalf("bcrypt: %v", err)
+		}
+		if err := fx.localRepo.Create(&domain.LocalCredential{UserID: uid, PasswordHash: string(legacy), EmailVerified: true}); err != nil {
+			t.Fatalf("create credential: %v", err)
+		}
+
+		if _, err := fx.auth.LoginWithLocalPassword("legacy@example.com", "wrong-pass", "ua", "127.0.0.1"); !errors.Is(err, ErrInvalidCredentials) {
+			t.Fatalf("expected ErrInvalidCredentials, got %v", err)
+		}
+		if fx.localRepo.byUserID[uid].PasswordHash != string(legacy) {
+			t.Fatal("expected a failed login to keep the imported hash")
+		}
+		if _, err := fx.auth.LoginWithLocalPassword("legacy@example.com", "StrongPass123!", "ua", "127.0.0.1"); err != nil {
+			t.Fatalf("login: %v", err)
+		}
+		upgraded := fx.localRepo.byUserID[uid]
+		if security.PasswordHashAlgorithm(upgraded.PasswordHash) != security.PasswordAlgorithmArgon2id || security.PasswordNeedsRehash(upgraded.PasswordHash) {
+			t.Fatalf("expected the hash to be upgraded to current argon2id, got %q", upgraded.PasswordHash)
+		}
+		if upgraded.PasswordChangedAt != nil {
+			t.Fatal("expected an upgrade not to count as a password change")
+		}
+		if _, err := fx.auth.LoginWithLocalPassword("legacy@example.com", "StrongPass123!", "ua", "127.0.0.1"); err != nil {
+			t.Fatalf("login after upgrade: %v", err)
+		}
+	})
+
 	t.Run("success issues tokens", func(t *testing.T) {
 		fx := newAuthServiceFixture()
 		fx.seedLocalUser("user@example.com", "User", "StrongPass123!", true)
@@ -178,7 +226,7 @@ func TestAuthServiceLoginWithLocalPasswordMatrix(t *testing.T) {
 func TestAuthServiceRequestAndConfirmEmailVerificationMatrix(t *testing.T) {
 	t.Run("request unknown email is no-op", func(t *testing.T) {
 		fx := newAuthServiceFixture()
-		if err := fx.auth.RequestLocalEmailVerification("unknown@example.com"); err != nil {
+		if err := fx.auth.RequestLocalEmailVerification("unknown@example.com", ""); err != nil {
 			t.Fatalf("expected no-op success, got %v", err)
 		}
 		if len(fx.emailNotifier.calls) != 0 {
@@ -190,7 +238,7 @@ func TestAuthServiceRequestAndConfirmEmailVerificationMatrix(t *testing.T) {
 		fx := newAuthServiceFixture()
 		fx.seedLocalUser("verified@example.com", "User", "StrongPass123!", true)
 
-		if err := fx.auth.RequestLocalEmailVerification("verified@example.com"); err != nil {
+		if err := fx.auth.RequestLocalEmailVerification("verified@example.com", ""); err != nil {
 			t.Fatalf("expected no-op success, got %v", err)
 		}
 		if fx.verifyRepo.invalidateCalls != 0 {
@@ -203,7 +251,7 @@ func TestAuthServiceRequestAndConfirmEmailVerificationMatrix(t *testing.T) {
 		fx.cfg.AuthEmailVerifyBaseURL = "://bad"
 		fx.seedLocalUser("verify@example.com", "User", "StrongPass123!", false)
 
-		err := fx.auth.RequestLocalEmailVerification("verify@example.com")
+		err := fx.auth.RequestLocalEmailVerification("verify@example.com", "")
 		if err == nil || !strings.Contains(err.Error(), "invalid AUTH_EMAIL_VERIFY_BASE_URL") {
 			t.Fatalf("expected invalid base URL error, got %v", err)
 		}
@@ -215,7 +263,7 @@ func TestAuthServiceRequestAndConfirmEmailVerificationMatrix(t *testing.T) {
 		fx.seedLocalUser("verify@example.com", "User", "StrongPass123!", false)
 		fx.emailNotifier.err = errors.New("smtp down")
 
-		err := fx.auth.RequestLocalEmailVerification("verify@example.com")
+		err := fx.auth.RequestLocalEmailVerification("verify@example.com", "")
 		if err == nil || !strings.Contains(err.Error(), "smtp down") {
 			t.Fatalf("expected notifier error, got %v", err)
 		}
@@ -227,7 +275,7 @@ func TestAuthServiceRequestAndConfirmEmailVerificationMatrix(t *testing.T) {
 		uid := fx.seedLocalUser("verify@example.com", "User", "StrongPass123!", false)
 		fx.verifyRepo.seedToken(uid, "email_verify", hashVerificationToken("old-token"), time.Now().Add(5*time.Minute), false)
 
-		err := fx.auth.RequestLocalEmailVerification("verify@example.com")
+		err := fx.auth.RequestLocalEmailVerification("verify@example.com", "")
 		if err != nil {
 			t.Fatalf("request: %v", err)
 		}
@@ -292,7 +340,7 @@ func TestAuthServiceRequestAndConfirmEmailVerificationMatrix(t *testing.T) {
 func TestAuthServiceForgotAndResetPasswordMatrix(t *testing.T) {
 	t.Run("forgot unknown email is no-op", func(t *testing.T) {
 		fx := newAuthServiceFixture()
-		if err := fx.auth.ForgotLocalPassword("unknown@example.com"); err != nil {
+		if err := fx.auth.ForgotLocalPassword("unknown@example.com", ""); err != nil {
 			t.Fatalf("expected no-op success, got %v", err)
 		}
 		if len(fx.passwordNotifier.calls) != 0 {
@@ -305,7 +353,7 @@ func TestAuthServiceForgotAndResetPasswordMatrix(t *testing.T) {
 		fx.cfg.AuthPasswordResetBaseURL = "://bad"
 		fx.seedLocalUser("user@example.com", "User", "StrongPass123!", true)
 
-		err := fx.auth.ForgotLocalPassword("user@example.com")
+		err := fx.auth.ForgotLocalPassword("user@example.com", "")
 		if err == nil || !strings.Contains(err.Error(), "invalid AUTH_PASSWORD_RESET_BASE_URL") {
 			t.Fatalf("expected malformed reset URL error, got %v", err)
 		}
@@ -317,18 +365,49 @@ func TestAuthServiceForgotAndResetPasswordMatrix(t *testing.T) {
 		fx.seedLocalUser("user@example.com", "User", "StrongPass123!", true)
 		fx.passwordNotifier.err = errors.New("provider down")
 
-		err := fx.auth.ForgotLocalPassword("user@example.com")
+		err := fx.auth.ForgotLocalPassword("user@example.com", "")
 		if err == nil || !strings.Contains(err.Error(), "provider down") {
 			t.Fatalf("expected notifier failure, got %v", err)
 		}
 	})
 
+	t.Run("forgot queues mail in the outbox when enabled", func(t *testing.T) {
+		fx := newAuthServiceFixture()
+		fx.cfg.NotificationOutboxEnabled = true
+		fx.cfg.NotificationOutboxEncryptionKey = testOutboxEncryptionKey
+		fx.cfg.AuthPasswordResetBaseURL = "https://example.com/reset"
+		fx.seedLocalUser("user@example.com", "User", "StrongPass123!", true)
+		fx.passwordNotifier.err = errors.New("provider down")
+
+		if err := fx.auth.ForgotLocalPassword("user@example.com", "fr-ca"); err != nil {
+			t.Fatalf("expected provider outage not to fail the request, got %v", err)
+		}
+		if len(fx.passwordNotifier.calls) != 0 {
+			t.Fatalf("expected no inline send, got %d", len(fx.passwordNotifier.calls))
+		}
+		if fx.verifyRepo.createCalls != 1 || len(fx.verifyRepo.outbox) != 1 {
+			t.Fatalf("expected token and outbox message to be stored together, got creates=%d outbox=%d", fx.verifyRepo.createCalls, len(fx.verifyRepo.outbox))
+		}
+		msg := fx.verifyRepo.outbox[0]
+		if msg.Kind != OutboxKindPasswordReset || msg.Status != domain.OutboxStatusPending || msg.ExpiresAt == nil {
+			t.Fatalf("unexpected outbox message: %+v", msg)
+		}
+		if strings.Contains(msg.Payload, "token=") || strings.Contains(msg.Payload, "user@example.com") {
+			t.Fatalf("expected the queued payload to be sealed, got %q", msg.Payload)
+		}
+		var queued PasswordResetNotification
+		outbox := NewNotificationOutbox(fx.cfg, nil, nil)
+		if err := outbox.openPayload(msg, &queued); err != nil || !strings.HasPrefix(queued.PasswordURL, "https://example.com/reset?token=") || queued.Locale != "fr-ca" {
+			t.Fatalf("expected queued notification with reset link and locale, got %+v err=%v", queued, err)
+		}
+	})
+
 	t.Run("forgot success issues token", func(t *testing.T) {
 		fx := newAuthServiceFixture()
 		fx.cfg.AuthPasswordResetBaseURL = "https://example.com/reset"
 		fx.seedLocalUser("user@example.com", "User", "StrongPass123!", true)
 
-		err := fx.auth.ForgotLocalPassword("user@example.com")
+		err := fx.auth.ForgotLocalPassword("user@example.com", "")
 		if err != nil {
 			t.Fatalf("forgot: %v", err)
 		}
@@ -342,9 +421,14 @@ func TestAuthServiceForgotAndResetPasswordMatrix(t *testing.T) {
 
 	t.Run("reset validates password policy and token", func(t *testing.T) {
 		fx := newAuthServiceFixture()
+		uid := fx.seedLocalUser("user@example.com", "User", "StrongPass123!", true)
+		fx.verifyRepo.seedToken(uid, "password_reset", hashVerificationToken("token"), time.Now().Add(10*time.Minute), false)
 		if err := fx.auth.ResetLocalPassword("token", "weak"); !errors.Is(err, ErrWeakPassword) {
 			t.Fatalf("expected ErrWeakPassword, got %v", err)
 		}
+		if fx.verifyRepo.consumeCalls != 0 {
+			t.Fatalf("expected a rejected password to leave the token unconsumed, got %d consume calls", fx.verifyRepo.consumeCalls)
+		}
 		if err := fx.auth.ResetLocalPassword("   ", "StrongPass123!"); !errors.Is(err, ErrInvalidVerifyToken) {
 			t.Fatalf("expected ErrInvalidVerifyToken for empty token, got %v", err)
 		}
@@ -397,6 +481,100 @@ func TestAuthServiceForgotAndResetPasswordMatrix(t *testing.T) {
 	})
 }
 
+func TestAuthServiceMagicLinkMatrix(t *testing.T) {
+	t.Run("disabled", func(t *testing.T) {
+		fx := newAuthServiceFixture()
+		if err := fx.auth.RequestMagicLink("user@example.com", ""); !errors.Is(err, ErrMagicLinkDisabled) {
+			t.Fatalf("expected ErrMagicLinkDisabled, got %v", err)
+		}
+		if _, err := fx.auth.ConfirmMagicLink("token", "ua", "127.0.0.1"); !errors.Is(err, ErrMagicLinkDisabled) {
+			t.Fatalf("expected ErrMagicLinkDisabled, got %v", err)
+		}
+	})
+
+	t.Run("request unknown and inactive users is no-op", func(t *testing.T) {
+		fx := newAuthServiceFixture()
+		fx.cfg.AuthMagicLinkEnabled = true
+		uid := fx.seedUser("suspended@example.com", "Suspended")
+		if err := fx.userRepo.UpdateStatus(uid, domain.UserStatusSuspended); err != nil {
+			t.Fatalf("suspend: %v", err)
+		}
+		for _, email := range []string{"unknown@example.com", "suspended@example.com", "  "} {
+			if err := fx.auth.RequestMagicLink(email, ""); err != nil {
+				t.Fatalf("expected no-op for %q, got %v", email, err)
+			}
+		}
+		if len(fx.magicNotifier.calls) != 0 || fx.verifyRepo.createCalls != 0 {
+			t.Fatalf("expected no token or notification, got calls=%d creates=%d", len(fx.magicNotifier.calls), fx.verifyRepo.createCalls)
+		}
+	})
+
+	t.Run("request malformed base url", func(t *testing.T) {
+		fx := newAuthServiceFixture()
+		fx.cfg.AuthMagicLinkEnabled = true
+		fx.cfg.AuthMagicLinkBaseURL = "://bad"
+		fx.seedUser("user@example.com", "User")
+		err := fx.auth.RequestMagicLink("user@example.com", "")
+		if err == nil || !strings.Contains(err.Error(), "invalid AUTH_MAGIC_LINK_BASE_URL") {
+			t.Fatalf("expected malformed magic link URL error, got %v", err)
+		}
+	})
+
+	t.Run("confirm signs in once and verifies email", func(t *testing.T) {
+		fx := newAuthServiceFixture()
+		fx.cfg.AuthMagicLinkEnabled = true
+		fx.cfg.AuthMagicLinkBaseURL = "https://example.com/magic"
+		uid := fx.seedLocalUser("User@Example.com", "User", "StrongPass123!", false)
+
+		if err := fx.auth.RequestMagicLink("USER@example.com", "de"); err != nil {
+			t.Fatalf("request: %v", err)
+		}
+		if len(fx.magicNotifier.calls) != 1 {
+			t.Fatalf("expected one magic link notification, got %d", len(fx.magicNotifier.calls))
+		}
+		sent := fx.magicNotifier.calls[0]
+		if sent.Email != "user@example.com" || sent.Locale != "de" || !strings.HasPrefix(sent.LoginURL, "https://example.com/magic?token=") {
+			t.Fatalf("unexpected notification: %+v", sent)
+		}
+
+		result, err := fx.auth.ConfirmMagicLink(sent.Token, "ua", "127.0.0.1")
+		if err != nil {
+			t.Fatalf("confirm: %v", err)
+		}
+		if result.User.ID != uid || result.AccessToken == "" || result.RefreshToken == "" {
+			t.Fatalf("expected full session, got %+v", result)
+		}
+		if cred := fx.localRepo.byUserID[uid]; !cred.EmailVerified {
+			t.Fatal("expected magic link to mark the email verified")
+		}
+		if _, err := fx.auth.ConfirmMagicLink(sent.Token, "ua", "127.0.0.1"); !errors.Is(err, ErrInvalidVerifyToken) {
+			t.Fatalf("expected replay to fail, got %v", err)
+		}
+	})
+
+	t.Run("confirm rejects superseded token and inactive user", func(t *testing.T) {
+		fx := newAuthServiceFixture()
+		fx.cfg.AuthMagicLinkEnabled = true
+		uid := fx.seedUser("oauth-only@example.com", "OAuth")
+
+		if err := fx.auth.RequestMagicLink("oauth-only@example.com", ""); err != nil {
+			t.Fatalf("first request: %v", err)
+		}
+		if err := fx.auth.RequestMagicLink("oauth-only@example.com", ""); err != nil {
+			t.Fatalf("second request: %v", err)
+		}
+		if _, err := fx.auth.ConfirmMagicLink(fx.magicNotifier.calls[0].Token, "ua", "127.0.0.1"); !errors.Is(err, ErrInvalidVerifyToken) {
+			t.Fatalf("expected superseded token to fail, got %v", err)
+		}
+		if err := fx.userRepo.UpdateStatus(uid, domain.UserStatusSuspended); err != nil {
+			t.Fatalf("suspend: %v", err)
+		}
+		if _, err := fx.auth.ConfirmMagicLink(fx.magicNotifier.calls[1].Token, "ua", "127.0.0.1"); !errors.Is(err, ErrAccountInactive) {
+			t.Fatalf("expected inactive user to fail, got %v", err)
+		}
+	})
+}
+
 func TestAuthServiceChangeLocalPasswordMatrix(t *testing.T) {
 	t.Run("invalid current credentials", func(t *testing.T) {
 		fx := newAuthServiceFixture()
@@ -424,6 +602,22 @@ func TestAuthServiceChangeLocalPasswordMatrix(t *testing.T) {
 		}
 	})
 
+	t.Run("recent password reuse rejected", func(t *testing.T) {
+		fx := newAuthServiceFixture()
+		fx.cfg.PasswordHistoryCount = 2
+		fx.auth.passwordPolicy, _ = NewPasswordPolicy(fx.cfg)
+		uid := fx.seedLocalUser("user@example.com", "User", "StrongPass123!", true)
+
+		if err := fx.auth.ChangeLocalPassword(uid, "StrongPass123!", "EvenStronger123!"); err != nil {
+			t.Fatalf("change password: %v", err)
+		}
+		err := fx.auth.ChangeLocalPassword(uid, "EvenStronger123!", "StrongPass123!")
+		var policyErr *PasswordPolicyError
+		if !errors.As(err, &policyErr) || policyErr.Violations[0].Rule != PasswordRuleReused {
+			t.Fatalf("expected reuse violation, got %v", err)
+		}
+	})
+
 	t.Run("success updates password and revokes sessions", func(t *testing.T) {
 		fx := newAuthServiceFixture()
 		uid := fx.seedLocalUser("user@example.com", "User", "StrongPass123!", true)
@@ -443,15 +637,87 @@ func TestAuthServiceChangeLocalPasswordMatrix(t *testing.T) {
 	})
 }
 
+func TestAuthServiceIdentityLinking(t *testing.T) {
+	linkFlow := func(provider string, userID uint) security.OAuthFlowState {
+		flow := testOAuthFlow(provider)
+		flow.LinkUserID = userID
+		return flow
+	}
+
+	t.Run("auto link by email can be disabled", func(t *testing.T) {
+		fx := newAuthServiceFixture()
+		fx.cfg.AuthOAuthAutoLinkByEmail = false
+		fx.seedLocalUser("user@example.com", "Existing", "StrongPass123!", true)
+
+		if _, err := fx.auth.LoginWithOAuthCode(testOAuthFlow("github"), "code", "ua", "127.0.0.1"); !errors.Is(err, ErrOAuthLinkRequired) {
+			t.Fatalf("expected ErrOAuthLinkRequired, got %v", err)
+		}
+		if _, err := fx.oauthRepo.FindByProvider("github", "provider-id"); !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Fatalf("expected no identity to be attached, got %v", err)
+		}
+	})
+
+	t.Run("explicit link attaches to the flow user", func(t *testing.T) {
+		fx := newAuthServiceFixture()
+		fx.cfg.AuthOAuthAutoLinkByEmail = false
+		uid := fx.seedLocalUser("someone-else@example.com", "Owner", "StrongPass123!", true)
+
+		account, err := fx.auth.LinkOAuthIdentity(linkFlow("github", uid), "code")
+		if err != nil || account.UserID != uid || account.Provider != "github" {
+			t.Fatalf("expected github identity linked to %d, got %+v err=%v", uid, account, err)
+		}
+		if _, err := fx.auth.LinkOAuthIdentity(linkFlow("github", uid), "code"); err != nil {
+			t.Fatalf("expected relinking an owned identity to be a no-op, got %v", err)
+		}
+		other := fx.seedUser("other@example.com", "Other")
+		if _, err := fx.auth.LinkOAuthIdentity(linkFlow("github", other), "code"); !errors.Is(err, ErrOAuthIdentityLinkedElsewhere) {
+			t.Fatalf("expected ErrOAuthIdentityLinkedElsewhere, got %v", err)
+		}
+
+		linked, err := fx.auth.ListIdentities(uid)
+		if err != nil || len(linked.Identities) != 1 || !linked.LocalCredential {
+			t.Fatalf("unexpected identities %+v err=%v", linked, err)
+		}
+	})
+
+	t.Run("unlink refuses the last sign-in method", func(t *testing.T) {
+		fx := newAuthServiceFixture()
+		uid := fx.seedUser("oauth-only@example.com", "OAuth Only")
+		account, err := fx.auth.LinkOAuthIdentity(linkFlow("github", uid), "code")
+		if err != nil {
+			t.Fatalf("link: %v", err)
+		}
+		if err := fx.auth.UnlinkIdentity(uid, account.ID); !errors.Is(err, ErrLastCredential) {
+			t.Fatalf("expected ErrLastCredential, got %v", err)
+		}
+		if err := fx.auth.UnlinkIdentity(uid, account.ID+100); !errors.Is(err, ErrIdentityNotFound) {
+			t.Fatalf("expected ErrIdentityNotFound, got %v", err)
+		}
+
+		withPassword := fx.seedLocalUser("both@example.com", "Both", "StrongPass123!", true)
+		fx.oauthRepo.byProviderUser = map[string]*domain.OAuthAccount{}
+		account, err = fx.auth.LinkOAuthIdentity(linkFlow("github", withPassword), "code")
+		if err != nil {
+			t.Fatalf("link: %v", err)
+		}
+		if err := fx.auth.UnlinkIdentity(withPassword, account.ID); err != nil {
+			t.Fatalf("expected unlink with a password fallback to succeed, got %v", err)
+		}
+		if linked, _ := fx.auth.ListIdentities(withPassword); len(linked.Identities) != 0 {
+			t.Fatalf("expected identity to be removed, got %+v", linked.Identities)
+		}
+	})
+}
+
 func TestAuthServiceGoogleAndParseUserID(t *testing.T) {
 	t.Run("google login URL and code disabled gate", func(t *testing.T) {
 		fx := newAuthServiceFixture()
 		fx.cfg.AuthGoogleEnabled = false
 
-		if got := fx.auth.GoogleLoginURL("state"); got != "" {
-			t.Fatalf("expected empty login URL when disabled, got %q", got)
+		if _, err := fx.auth.OAuthLoginURL(testOAuthFlow("google")); !errors.Is(err, ErrGoogleAuthDisabled) {
+			t.Fatalf("expected ErrGoogleAuthDisabled for login url, got %v", err)
 		}
-		_, err := fx.auth.LoginWithGoogleCode("code", "ua", "127.0.0.1")
+		_, err := fx.auth.LoginWithOAuthCode(testOAuthFlow("google"), "code", "ua", "127.0.0.1")
 		if !errors.Is(err, ErrGoogleAuthDisabled) {
 			t.Fatalf("expected ErrGoogleAuthDisabled, got %v", err)
 		}
@@ -462,7 +728,7 @@ func TestAuthServiceGoogleAndParseUserID(t *testing.T) {
 		fx.cfg.AuthGoogleEnabled = true
 		fx.roleRepo.byName["user"] = &domain.Role{ID: 10, Name: "user"}
 
-		res, err := fx.auth.LoginWithGoogleCode("oauth-code", "ua", "127.0.0.1")
+		res, err := fx.auth.LoginWithOAuthCode(testOAuthFlow("google"), "oauth-code", "ua", "127.0.0.1")
 		if err != nil {
 			t.Fatalf("google login: %v", err)
 		}
@@ -474,6 +740,38 @@ func TestAuthServiceGoogleAndParseUserID(t *testing.T) {
 		}
 	})
 
+	t.Run("generic provider login links oauth account", func(t *testing.T) {
+		fx := newAuthServiceFixture()
+		fx.cfg.AuthGoogleEnabled = false
+
+		if !fx.auth.OAuthProviderEnabled("GitHub") {
+			t.Fatal("expected github provider to be enabled")
+		}
+		if fx.auth.OAuthProviderEnabled("google") {
+			t.Fatal("expected google provider to be gated by config")
+		}
+		res, err := fx.auth.LoginWithOAuthCode(testOAuthFlow("github"), "oauth-code", "ua", "127.0.0.1")
+		if err != nil {
+			t.Fatalf("github login: %v", err)
+		}
+		if res.AccessToken == "" || res.RefreshToken == "" {
+			t.Fatal("expected issued tokens for github login")
+		}
+		if _, err := fx.oauthRepo.FindByProvider("github", "provider-id"); err != nil {
+			t.Fatalf("expected github oauth account link, got %v", err)
+		}
+	})
+
+	t.Run("unknown provider is rejected", func(t *testing.T) {
+		fx := newAuthServiceFixture()
+		if _, err := fx.auth.OAuthLoginURL(testOAuthFlow("gitlab")); !errors.Is(err, ErrOAuthProviderNotFound) {
+			t.Fatalf("expected ErrOAuthProviderNotFound for login url, got %v", err)
+		}
+		if _, err := fx.auth.LoginWithOAuthCode(testOAuthFlow("gitlab"), "code", "ua", "127.0.0.1"); !errors.Is(err, ErrOAuthProviderNotFound) {
+			t.Fatalf("expected ErrOAuthProviderNotFound for callback, got %v", err)
+		}
+	})
+
 	t.Run("parse user id edge cases", func(t *testing.T) {
 		fx := newAuthServiceFixture()
 		id, err := fx.auth.ParseUserID("123")
@@ -586,6 +884,8 @@ type authServiceFixture struct {
 	oauthRepo        *fakeOAuthRepo
 	emailNotifier    *fakeEmailVerificationNotifier
 	passwordNotifier *fakePasswordResetNotifier
+	magicNotifier    *fakeMagicLinkNotifier
+	loginEvents      *fakeLoginEventRepo
 }
 
 func newAuthServiceFixture() *authServiceFixture {
@@ -599,7 +899,9 @@ func newAuthServiceFixtureWithSessionRepo(sessionRepo repository.SessionReposito
 		AuthLocalRequireEmailVerification: false,
 		AuthEmailVerifyTokenTTL:           30 * time.Minute,
 		AuthPasswordResetTokenTTL:         15 * time.Minute,
+		AuthMagicLinkTokenTTL:             15 * time.Minute,
 		JWTAccessTTL:                      15 * time.Minute,
+		AuthOAuthAutoLinkByEmail:          true,
 	}
 
 	userRepo := newFakeUserRepo()
@@ -610,10 +912,19 @@ func newAuthServiceFixtureWithSessionRepo(sessionRepo repository.SessionReposito
 	oauthRepo := newFakeOAuthRepo()
 	emailNotifier := &fakeEmailVerificationNotifier{}
 	passwordNotifier := &fakePasswordResetNotifier{}
-	oauthSvc := NewOAuthService(testOAuthProvider{}, userRepo, oauthRepo, roleRepo)
+	magicNotifier := &fakeMagicLinkNotifier{}
+	oauthSvc := NewOAuthService(cfg, NewOAuthProviderRegistry(map[string]OAuthProvider{
+		"google": testOAuthProvider{},
+		"github": testOAuthProvider{},
+	}), userRepo, oauthRepo, roleRepo)
 	tokenSvc := newTestTokenService(sessionRepo)
 	userSvc := NewUserService(userRepo, NewRBACService())
-	authSvc := NewAuthService(cfg, oauthSvc, tokenSvc, userSvc, roleRepo, localRepo, verifyRepo, emailNotifier, passwordNotifier)
+	passwordPolicy, err := NewPasswordPolicy(cfg)
+	if err != nil {
+		panic(err)
+	}
+	loginEvents := &fakeLoginEventRepo{}
+	authSvc := NewAuthService(cfg, oauthSvc, tokenSvc, userSvc, roleRepo, localRepo, verifyRepo, emailNotifier, passwordNotifier, magicNotifier, nil, nil, passwordPolicy, loginEvents)
 
 	return &authServiceFixture{
 		cfg:              cfg,
@@ -625,6 +936,8 @@ func newAuthServiceFixtureWithSessionRepo(sessionRepo repository.SessionReposito
 		oauthRepo:        oauthRepo,
 		emailNotifier:    emailNotifier,
 		passwordNotifier: passwordNotifier,
+		magicNotifier:    magicNotifier,
+		loginEvents:      loginEvents,
 	}
 }
 
@@ -664,6 +977,10 @@ type fakeUserRepo struct {
 	updateErr      error
 	setRolesErr    error
 	addRoleErr     error
+
+	// credentials is shared with fakeLocalCredentialRepo so UpdateEmail can
+	// reset verification the way the real transaction does.
+	credentials map[uint]*domain.LocalCredential
 }
 
 func newFakeUserRepo() *fakeUserRepo {
@@ -730,6 +1047,90 @@ func (r *fakeUserRepo) Update(user *domain.User) error {
 	return nil
 }
 
+func (r *fakeUserRepo) UpdateStatus(userID uint, status string) error {
+	if r.updateErr != nil {
+		return r.updateErr
+	}
+	u, ok := r.byID[userID]
+	if !ok {
+		return gorm.ErrRecordNotFound
+	}
+	u.Status = status
+	return nil
+}
+
+func (r *fakeUserRepo) UpdateEmail(userID uint, email string) error {
+	if r.updateErr != nil {
+		return r.updateErr
+	}
+	u, ok := r.byID[userID]
+	if !ok {
+		return gorm.ErrRecordNotFound
+	}
+	if id, taken := r.byMail[email]; taken && id != userID {
+		return repository.ErrEmailTaken
+	}
+	delete(r.byMail, u.Email)
+	u.Email = email
+	r.byMail[email] = userID
+	if cred, ok := r.credentials[userID]; ok {
+		cred.EmailVerified = false
+		cred.EmailVerifiedAt = nil
+	}
+	return nil
+}
+
+func (r *fakeUserRepo) ScheduleDeletion(userID uint, at time.Time) error {
+	u, ok := r.byID[userID]
+	if !ok {
+		return gorm.ErrRecordNotFound
+	}
+	u.Status = domain.UserStatusPendingDeletion
+	u.DeletionScheduledAt = &at
+	return nil
+}
+
+func (r *fakeUserRepo) CancelDeletion(userID uint) error {
+	u, ok := r.byID[userID]
+	if !ok || u.Status != domain.UserStatusPendingDeletion {
+		return gorm.ErrRecordNotFound
+	}
+	u.Status = domain.UserStatusActive
+	u.DeletionScheduledAt = nil
+	return nil
+}
+
+func (r *fakeUserRepo) ListDueForDeletion(now time.Time, limit int) ([]uint, error) {
+	ids := make([]uint, 0)
+	for id, u := range r.byID {
+		if u.Status == domain.UserStatusPendingDeletion && u.DeletionScheduledAt != nil && !u.DeletionScheduledAt.After(now) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	if len(ids) > limit {
+		ids = ids[:limit]
+	}
+	return ids, nil
+}
+
+func (r *fakeUserRepo) Erase(userID uint) error {
+	u, ok := r.byID[userID]
+	if !ok {
+		return gorm.ErrRecordNotFound
+	}
+	delete(r.byMail, u.Email)
+	delete(r.credentials, userID)
+	u.Email = domain.ErasedUserEmail(userID)
+	u.Name = "Deleted user"
+	u.AvatarURL = ""
+	u.Status = domain.UserStatusDeleted
+	u.DeletionScheduledAt = nil
+	u.Roles = nil
+	r.byMail[u.Email] = userID
+	return nil
+}
+
 func (r *fakeUserRepo) List() ([]domain.User, error) {
 	out := make([]domain.User, 0, len(r.byID))
 	for _, u := range r.byID {
@@ -781,6 +1182,22 @@ func (r *fakeUserRepo) SetRoles(userID uint, roleIDs []uint) error {
 	return nil
 }
 
+func (r *fakeUserRepo) SetRoleBindings(userID uint, bindings []domain.UserRole) error {
+	roleIDs := make([]uint, 0, len(bindings))
+	for _, b := range bindings {
+		roleIDs = append(roleIDs, b.RoleID)
+	}
+	return r.SetRoles(userID, roleIDs)
+}
+
+func (r *fakeUserRepo) ListExpiredRoleBindings(now time.Time, limit int) ([]domain.UserRole, error) {
+	return nil, nil
+}
+
+func (r *fakeUserRepo) DeleteExpiredRoleBinding(userID, roleID uint, now time.Time) (bool, error) {
+	return false, nil
+}
+
 func (r *fakeUserRepo) AddRole(userID, roleID uint) error {
 	if r.addRoleErr != nil {
 		return r.addRoleErr
@@ -864,15 +1281,16 @@ func (r *fakeRoleRepo) ListPaged(req repository.PageRequest, sortBy, sortOrder,
 	return repository.PageResult[domain.Role]{}, nil
 }
 
-func (r *fakeRoleRepo) Create(role *domain.Role, permissionIDs []uint) error { return nil }
+func (r *fakeRoleRepo) Create(role *domain.Role, permissionIDs, parentIDs []uint) error { return nil }
 
-func (r *fakeRoleRepo) Update(role *domain.Role, permissionIDs []uint) error { return nil }
+func (r *fakeRoleRepo) Update(role *domain.Role, permissionIDs, parentIDs []uint) error { return nil }
 
 func (r *fakeRoleRepo) DeleteByID(id uint) error { return nil }
 
 type fakeLocalCredentialRepo struct {
 	userRepo *fakeUserRepo
 	byUserID map[uint]*domain.LocalCredential
+	history  map[uint][]string
 
 	createErr            error
 	updatePasswordErr    error
@@ -882,7 +1300,9 @@ type fakeLocalCredentialRepo struct {
 }
 
 func newFakeLocalCredentialRepo(userRepo *fakeUserRepo) *fakeLocalCredentialRepo {
-	return &fakeLocalCredentialRepo{userRepo: userRepo, byUserID: map[uint]*domain.LocalCredential{}}
+	repo := &fakeLocalCredentialRepo{userRepo: userRepo, byUserID: map[uint]*domain.LocalCredential{}, history: map[uint][]string{}}
+	userRepo.credentials = repo.byUserID
+	return repo
 }
 
 func (r *fakeLocalCredentialRepo) Create(credential *domain.LocalCredential) error {
@@ -922,7 +1342,7 @@ func (r *fakeLocalCredentialRepo) FindByEmail(email string) (*domain.LocalCreden
 	return &copy, nil
 }
 
-func (r *fakeLocalCredentialRepo) UpdatePassword(userID uint, newHash string) error {
+func (r *fakeLocalCredentialRepo) UpdatePassword(userID uint, newHash string, historyLimit int) error {
 	if r.updatePasswordErr != nil {
 		return r.updatePasswordErr
 	}
@@ -930,10 +1350,40 @@ func (r *fakeLocalCredentialRepo) UpdatePassword(userID uint, newHash string) er
 	if !ok {
 		return gorm.ErrRecordNotFound
 	}
+	if historyLimit > 0 {
+		r.history[userID] = append([]string{cred.PasswordHash}, r.history[userID]...)
+		if len(r.history[userID]) > historyLimit {
+			r.history[userID] = r.history[userID][:historyLimit]
+		}
+	} else {
+		delete(r.history, userID)
+	}
+	now := time.Now().UTC()
 	cred.PasswordHash = newHash
+	cred.PasswordChangedAt = &now
 	return nil
 }
 
+func (r *fakeLocalCredentialRepo) UpgradePasswordHash(userID uint, oldHash, newHash string) (bool, error) {
+	if r.updatePasswordErr != nil {
+		return false, r.updatePasswordErr
+	}
+	cred, ok := r.byUserID[userID]
+	if !ok || cred.PasswordHash != oldHash {
+		return false, nil
+	}
+	cred.PasswordHash = newHash
+	return true, nil
+}
+
+func (r *fakeLocalCredentialRepo) ListPasswordHistory(userID uint, limit int) ([]string, error) {
+	history := r.history[userID]
+	if len(history) > limit {
+		history = history[:limit]
+	}
+	return append([]string(nil), history...), nil
+}
+
 func (r *fakeLocalCredentialRepo) MarkEmailVerified(userID uint) error {
 	if r.markEmailVerifiedErr != nil {
 		return r.markEmailVerifiedErr
@@ -951,6 +1401,7 @@ func (r *fakeLocalCredentialRepo) MarkEmailVerified(userID uint) error {
 type fakeVerificationTokenRepo struct {
 	nextID uint
 	tokens map[uint]*domain.VerificationToken
+	outbox []*domain.OutboxMessage
 
 	invalidateCalls int
 	createCalls     int
@@ -995,6 +1446,14 @@ func (r *fakeVerificationTokenRepo) Create(token *domain.VerificationToken) erro
 	return nil
 }
 
+func (r *fakeVerificationTokenRepo) CreateWithOutbox(token *domain.VerificationToken, msg *domain.OutboxMessage) error {
+	if err := r.Create(token); err != nil {
+		return err
+	}
+	r.outbox = append(r.outbox, msg)
+	return nil
+}
+
 func (r *fakeVerificationTokenRepo) InvalidateActiveByUserPurpose(userID uint, purpose string, now time.Time) error {
 	if r.invalidateErr != nil {
 		return r.invalidateErr
@@ -1056,7 +1515,39 @@ func (n *fakePasswordResetNotifier) SendPasswordReset(ctx context.Context, notif
 	return n.err
 }
 
+type fakeMagicLinkNotifier struct {
+	calls []MagicLinkNotification
+	err   error
+}
+
+func (n *fakeMagicLinkNotifier) SendMagicLink(ctx context.Context, notification MagicLinkNotification) error {
+	n.calls = append(n.calls, notification)
+	return n.err
+}
+
+type fakeLoginEventRepo struct {
+	events []domain.LoginEvent
+}
+
+func (r *fakeLoginEventRepo) Create(event *domain.LoginEvent) error {
+	event.ID = uint(len(r.events) + 1)
+	event.CreatedAt = time.Now().UTC()
+	r.events = append(r.events, *event)
+	return nil
+}
+
+func (r *fakeLoginEventRepo) ListByUserID(userID uint) ([]domain.LoginEvent, error) {
+	out := make([]domain.LoginEvent, 0)
+	for _, event := range r.events {
+		if event.UserID == userID {
+			out = append(out, event)
+		}
+	}
+	return out, nil
+}
+
 type fakeOAuthRepo struct {
+	nextID         uint
 	byProviderUser map[string]*domain.OAuthAccount
 	createErr      error
 	findErr        error
@@ -1082,11 +1573,34 @@ func (r *fakeOAuthRepo) Create(account *domain.OAuthAccount) error {
 	if r.createErr != nil {
 		return r.createErr
 	}
+	r.nextID++
+	account.ID = r.nextID
 	copy := *account
 	r.byProviderUser[account.Provider+"|"+account.ProviderUserID] = &copy
 	return nil
 }
 
+func (r *fakeOAuthRepo) ListByUserID(userID uint) ([]domain.OAuthAccount, error) {
+	out := make([]domain.OAuthAccount, 0)
+	for _, account := range r.byProviderUser {
+		if account.UserID == userID {
+			out = append(out, *account)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out, nil
+}
+
+func (r *fakeOAuthRepo) DeleteForUser(id, userID uint) error {
+	for key, account := range r.byProviderUser {
+		if account.ID == id && account.UserID == userID {
+			delete(r.byProviderUser, key)
+			return nil
+		}
+	}
+	return repository.ErrOAuthAccountNotFound
+}
+
 type failingRevokeSessionRepo struct {
 	revokeByUserErr error
 }
@@ -1109,6 +1623,10 @@ func (r *failingRevokeSessionRepo) ListActiveByUserID(userID uint) ([]domain.Ses
 	return nil, nil
 }
 
+func (r *failingRevokeSessionRepo) ListIssuedSince(userID uint, since time.Time) ([]domain.Session, error) {
+	return nil, nil
+}
+
 func (r *failingRevokeSessionRepo) RotateSession(oldHash string, newSession *domain.Session) (*domain.Session, error) {
 	return nil, repository.ErrSessionNotFound
 }
@@ -1117,6 +1635,10 @@ func (r *failingRevokeSessionRepo) UpdateTokenLineageByHash(hash, tokenID, famil
 	return nil
 }
 
+func (r *failingRevokeSessionRepo) MarkReauthenticated(userID uint, familyID string, at time.Time) (*domain.Session, error) {
+	return nil, repository.ErrSessionNotFound
+}
+
 func (r *failingRevokeSessionRepo) MarkReuseDetectedByHash(hash string) error { return nil }
 
 func (r *failingRevokeSessionRepo) RevokeByHash(hash, reason string) error { return nil }
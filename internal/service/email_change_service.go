@@ -0,0 +1,173 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/config"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/domain"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/repository"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/security"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidEmailChange = errors.New("invalid email change request")
+	ErrEmailTaken         = errors.New("email already in use")
+)
+
+// emailChangeSupersededPurposes are the tokens mailed to the old address
+// that must stop working once the address moves.
+var emailChangeSupersededPurposes = []string{"email_verify", "password_reset", "magic_login"}
+
+type EmailChangeResult struct {
+	User          *domain.User
+	PreviousEmail string
+}
+
+// EmailChangeService moves a user to a new address once they prove control
+// of it. The current address is told about every request.
+type EmailChangeService struct {
+	cfg                   *config.Config
+	userRepo              repository.UserRepository
+	verificationTokenRepo repository.VerificationTokenRepository
+	notifier              EmailChangeNotifier
+}
+
+func NewEmailChangeService(
+	cfg *config.Config,
+	userRepo repository.UserRepository,
+	verificationTokenRepo repository.VerificationTokenRepository,
+	notifier EmailChangeNotifier,
+) *EmailChangeService {
+	return &EmailChangeService{
+		cfg:                   cfg,
+		userRepo:              userRepo,
+		verificationTokenRepo: verificationTokenRepo,
+		notifier:              notifier,
+	}
+}
+
+// RequestEmailChange mails a confirmation token to the new address and a
+// notice to the current one. A newer request supersedes any pending one.
+func (s *EmailChangeService) RequestEmailChange(userID uint, newEmail, locale string) error {
+	newEmail = strings.TrimSpace(strings.ToLower(newEmail))
+	if err := validateEmail(newEmail); err != nil {
+		return ErrInvalidEmailChange
+	}
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return err
+	}
+	if strings.EqualFold(user.Email, newEmail) {
+		return ErrInvalidEmailChange
+	}
+	if _, err := s.userRepo.FindByEmail(newEmail); err == nil {
+		return ErrEmailTaken
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	now := time.Now().UTC()
+	if err := s.verificationTokenRepo.InvalidateActiveByUserPurpose(userID, "email_change", now); err != nil {
+		return err
+	}
+	rawToken, err := security.NewRandomString(32)
+	if err != nil {
+		return err
+	}
+	expiresAt := now.Add(s.cfg.AuthEmailVerifyTokenTTL)
+	if err := s.verificationTokenRepo.Create(&domain.VerificationToken{
+		UserID:    userID,
+		TokenHash: hashVerificationToken(rawToken),
+		Purpose:   "email_change",
+		NewEmail:  newEmail,
+		ExpiresAt: expiresAt,
+	}); err != nil {
+		return err
+	}
+
+	confirmURL := ""
+	if strings.TrimSpace(s.cfg.AuthEmailChangeBaseURL) != "" {
+		u, err := url.Parse(s.cfg.AuthEmailChangeBaseURL)
+		if err != nil {
+			return fmt.Errorf("invalid AUTH_EMAIL_CHANGE_BASE_URL: %w", err)
+		}
+		q := u.Query()
+		q.Set("token", rawToken)
+		u.RawQuery = q.Encode()
+		confirmURL = u.String()
+	}
+
+	// The notice goes first: a change must never be confirmable without the
+	// current owner having been told. That ordering is why these mails are
+	// sent inline rather than through the notification outbox.
+	if err := s.notifier.SendEmailChangeNotice(context.Background(), EmailChangeNotice{
+		UserID:   userID,
+		OldEmail: user.Email,
+		NewEmail: newEmail,
+		Locale:   locale,
+	}); err != nil {
+		return err
+	}
+	return s.notifier.SendEmailChangeVerification(context.Background(), EmailChangeNotification{
+		UserID:     userID,
+		Email:      newEmail,
+		Token:      rawToken,
+		ExpiresAt:  expiresAt,
+		ConfirmURL: confirmURL,
+		Locale:     locale,
+	})
+}
+
+// ConfirmEmailChange consumes the token and swaps the address. Only the user
+// who requested the change can confirm it.
+func (s *EmailChangeService) ConfirmEmailChange(userID uint, token string) (*EmailChangeResult, error) {
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return nil, ErrInvalidVerifyToken
+	}
+	now := time.Now().UTC()
+	record, err := s.verificationTokenRepo.FindActiveByHashPurpose(hashVerificationToken(token), "email_change", now)
+	if err != nil {
+		if errors.Is(err, repository.ErrVerificationTokenNotFound) {
+			return nil, ErrInvalidVerifyToken
+		}
+		return nil, err
+	}
+	if record.UserID != userID || record.NewEmail == "" {
+		return nil, ErrInvalidVerifyToken
+	}
+	if err := s.verificationTokenRepo.Consume(record.ID, record.UserID, now); err != nil {
+		if errors.Is(err, repository.ErrVerificationTokenNotFound) {
+			return nil, ErrInvalidVerifyToken
+		}
+		return nil, err
+	}
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	previous := user.Email
+	if err := s.userRepo.UpdateEmail(userID, record.NewEmail); err != nil {
+		if errors.Is(err, repository.ErrEmailTaken) {
+			return nil, ErrEmailTaken
+		}
+		return nil, err
+	}
+	for _, purpose := range emailChangeSupersededPurposes {
+		if err := s.verificationTokenRepo.InvalidateActiveByUserPurpose(userID, purpose, now); err != nil {
+			return nil, err
+		}
+	}
+	updated, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	return &EmailChangeResult{User: updated, PreviousEmail: previous}, nil
+}
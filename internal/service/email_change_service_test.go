@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/domain"
+)
+
+type fakeEmailChangeNotifier struct {
+	verifications []EmailChangeNotification
+	notices       []EmailChangeNotice
+	noticeErr     error
+}
+
+func (n *fakeEmailChangeNotifier) SendEmailChangeVerification(_ context.Context, notification EmailChangeNotification) error {
+	n.verifications = append(n.verifications, notification)
+	return nil
+}
+
+func (n *fakeEmailChangeNotifier) SendEmailChangeNotice(_ context.Context, notice EmailChangeNotice) error {
+	n.notices = append(n.notices, notice)
+	return n.noticeErr
+}
+
+func TestEmailChangeServiceRequestAndConfirm(t *testing.T) {
+	fx := newAuthServiceFixture()
+	fx.cfg.AuthEmailChangeBaseURL = "https://example.com/email/confirm"
+	notifier := &fakeEmailChangeNotifier{}
+	svc := NewEmailChangeService(fx.cfg, fx.userRepo, fx.verifyRepo, notifier)
+	uid := fx.seedLocalUser("old@example.com", "Mover", "StrongPass123!", true)
+	otherID := fx.seedUser("taken@example.com", "Other")
+
+	for _, email := range []string{"not-an-email", "OLD@example.com"} {
+		if err := svc.RequestEmailChange(uid, email, ""); !errors.Is(err, ErrInvalidEmailChange) {
+			t.Fatalf("expected ErrInvalidEmailChange for %q, got %v", email, err)
+		}
+	}
+	if err := svc.RequestEmailChange(uid, "taken@example.com", ""); !errors.Is(err, ErrEmailTaken) {
+		t.Fatalf("expected ErrEmailTaken, got %v", err)
+	}
+
+	if err := fx.auth.ForgotLocalPassword("old@example.com", ""); err != nil {
+		t.Fatalf("forgot: %v", err)
+	}
+	resetToken := fx.passwordNotifier.calls[0].Token
+
+	if err := svc.RequestEmailChange(uid, " New@Example.com ", ""); err != nil {
+		t.Fatalf("request: %v", err)
+	}
+	if len(notifier.notices) != 1 || notifier.notices[0].OldEmail != "old@example.com" || notifier.notices[0].NewEmail != "new@example.com" {
+		t.Fatalf("expected notice to the old address, got %+v", notifier.notices)
+	}
+	if len(notifier.verifications) != 1 || notifier.verifications[0].Email != "new@example.com" || notifier.verifications[0].ConfirmURL == "" {
+		t.Fatalf("expected verification to the new address, got %+v", notifier.verifications)
+	}
+	token := notifier.verifications[0].Token
+
+	if _, err := svc.ConfirmEmailChange(otherID, token); !errors.Is(err, ErrInvalidVerifyToken) {
+		t.Fatalf("expected another user's confirm to fail, got %v", err)
+	}
+	result, err := svc.ConfirmEmailChange(uid, token)
+	if err != nil {
+		t.Fatalf("confirm: %v", err)
+	}
+	if result.User.Email != "new@example.com" || result.PreviousEmail != "old@example.com" {
+		t.Fatalf("unexpected result: user=%+v previous=%q", result.User, result.PreviousEmail)
+	}
+	if cred := fx.localRepo.byUserID[uid]; cred.EmailVerified {
+		t.Fatal("expected local credential to return to unverified")
+	}
+	if _, err := svc.ConfirmEmailChange(uid, token); !errors.Is(err, ErrInvalidVerifyToken) {
+		t.Fatalf("expected replay to fail, got %v", err)
+	}
+	if err := fx.auth.ResetLocalPassword(resetToken, "AnotherPass123!"); !errors.Is(err, ErrInvalidVerifyToken) {
+		t.Fatalf("expected reset token mailed to the old address to be invalidated, got %v", err)
+	}
+	if _, err := fx.auth.LoginWithLocalPassword("new@example.com", "StrongPass123!", "ua", "127.0.0.1"); err != nil {
+		t.Fatalf("expected login with new email, got %v", err)
+	}
+}
+
+func TestEmailChangeServiceConfirmRaceAndNoticeFailure(t *testing.T) {
+	fx := newAuthServiceFixture()
+	notifier := &fakeEmailChangeNotifier{}
+	svc := NewEmailChangeService(fx.cfg, fx.userRepo, fx.verifyRepo, notifier)
+	uid := fx.seedUser("first@example.com", "First")
+
+	if err := svc.RequestEmailChange(uid, "contested@example.com", ""); err != nil {
+		t.Fatalf("request: %v", err)
+	}
+	if err := fx.userRepo.Create(&domain.User{Email: "contested@example.com", Name: "Late", Status: domain.UserStatusActive}); err != nil {
+		t.Fatalf("create contender: %v", err)
+	}
+	if _, err := svc.ConfirmEmailChange(uid, notifier.verifications[0].Token); !errors.Is(err, ErrEmailTaken) {
+		t.Fatalf("expected ErrEmailTaken when the address was claimed meanwhile, got %v", err)
+	}
+
+	notifier.noticeErr = errors.New("smtp down")
+	if err := svc.RequestEmailChange(uid, "other@example.com", ""); err == nil {
+		t.Fatal("expected notice failure to fail the request")
+	}
+	if len(notifier.verifications) != 1 {
+		t.Fatalf("expected no verification without a notice, got %d", len(notifier.verifications))
+	}
+}
@@ -0,0 +1,173 @@
+package service
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+	texttemplate "text/template"
+	"time"
+)
+
+//go:embed email_templates
+var defaultEmailTemplatesFS embed.FS
+
+const defaultEmailLocale = "en"
+
+// emailTemplateData is what notification templates can reference. Link is
+// empty when the matching *_BASE_URL is not configured; templates then show
+// the raw token.
+type emailTemplateData struct {
+	Email     string
+	NewEmail  string
+	Link      string
+	Token     string
+	ExpiresAt time.Time
+}
+
+type renderedEmail struct {
+	Subject string
+	Text    string
+	HTML    string
+}
+
+type emailTemplatePart interface {
+	Execute(w io.Writer, data any) error
+}
+
+// EmailTemplates renders notification mails from
+// <locale>/<name>.{subject,txt,html}.tmpl files. Each part is looked up on its
+// own, first in the override directory and then in the embedded defaults,
+// trying the notification's Locale, its base language, the default locale and
+// finally en. An override directory only needs the files it changes.
+type EmailTemplates struct {
+	defaultLocale string
+	sources       []map[string]emailTemplatePart
+}
+
+// NewEmailTemplates parses the embedded defaults and every template in
+// overrideDir up front so a broken override fails at startup, not on send.
+func NewEmailTemplates(overrideDir, defaultLocale string) (*EmailTemplates, error) {
+	defaultLocale = strings.ToLower(strings.TrimSpace(defaultLocale))
+	if defaultLocale == "" {
+		defaultLocale = defaultEmailLocale
+	}
+	t := &EmailTemplates{defaultLocale: defaultLocale}
+	if dir := strings.TrimSpace(overrideDir); dir != "" {
+		if info, err := os.Stat(dir); err != nil {
+			return nil, fmt.Errorf("load email templates: %w", err)
+		} else if !info.IsDir() {
+			return nil, fmt.Errorf("load email templates: %s is not a directory", dir)
+		}
+		overrides, err := loadEmailTemplates(os.DirFS(dir))
+		if err != nil {
+			return nil, fmt.Errorf("load email templates from %s: %w", dir, err)
+		}
+		t.sources = append(t.sources, overrides)
+	}
+	embedded, err := fs.Sub(defaultEmailTemplatesFS, "email_templates")
+	if err != nil {
+		return nil, err
+	}
+	defaults, err := loadEmailTemplates(embedded)
+	if err != nil {
+		return nil, fmt.Errorf("load default email templates: %w", err)
+	}
+	t.sources = append(t.sources, defaults)
+	return t, nil
+}
+
+func loadEmailTemplates(fsys fs.FS) (map[string]emailTemplatePart, error) {
+	parts := map[string]emailTemplatePart{}
+	matches, err := fs.Glob(fsys, "*/*.tmpl")
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range matches {
+		raw, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, err
+		}
+		key := strings.ToLower(strings.TrimSuffix(file, ".tmpl"))
+		var part emailTemplatePart
+		switch path.Ext(key) {
+		case ".html":
+			part, err = htmltemplate.New(key).Parse(string(raw))
+		case ".subject", ".txt":
+			part, err = texttemplate.New(key).Parse(string(raw))
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		parts[key] = part
+	}
+	return parts, nil
+}
+
+func (t *EmailTemplates) Render(locale, name string, data emailTemplateData) (renderedEmail, error) {
+	var out renderedEmail
+	subject, err := t.renderPart(locale, name, "subject", data, true)
+	if err != nil {
+		return out, err
+	}
+	out.Subject = strings.Join(strings.Fields(subject), " ")
+	if out.Text, err = t.renderPart(locale, name, "txt", data, true); err != nil {
+		return out, err
+	}
+	if out.HTML, err = t.renderPart(locale, name, "html", data, false); err != nil {
+		return out, err
+	}
+	return out, nil
+}
+
+func (t *EmailTemplates) renderPart(locale, name, part string, data emailTemplateData, required bool) (string, error) {
+	for _, candidate := range t.localeCandidates(locale) {
+		key := candidate + "/" + name + "." + part
+		for _, source := range t.sources {
+			tmpl, ok := source[key]
+			if !ok {
+				continue
+			}
+			var buf bytes.Buffer
+			if err := tmpl.Execute(&buf, data); err != nil {
+				return "", fmt.Errorf("render email template %s: %w", key, err)
+			}
+			return buf.String(), nil
+		}
+	}
+	if required {
+		return "", fmt.Errorf("email template %s.%s not found", name, part)
+	}
+	return "", nil
+}
+
+func (t *EmailTemplates) localeCandidates(locale string) []string {
+	locale = strings.ToLower(strings.TrimSpace(locale))
+	out := make([]string, 0, 5)
+	add := func(l string) {
+		if l == "" || strings.ContainsAny(l, "/\\.") {
+			return
+		}
+		for _, seen := range out {
+			if seen == l {
+				return
+			}
+		}
+		out = append(out, l)
+	}
+	for _, l := range []string{locale, t.defaultLocale} {
+		add(l)
+		if base, _, ok := strings.Cut(l, "-"); ok {
+			add(base)
+		}
+	}
+	add(defaultEmailLocale)
+	return out
+}
@@ -14,6 +14,7 @@ type VerificationNotification struct {
 	Token           string
 	ExpiresAt       time.Time
 	VerificationURL string
+	Locale          string
 }
 
 type EmailVerificationNotifier interface {
@@ -26,12 +27,58 @@ type PasswordResetNotification struct {
 	Token       string
 	ExpiresAt   time.Time
 	PasswordURL string
+	Locale      string
 }
 
 type PasswordResetNotifier interface {
 	SendPasswordReset(ctx context.Context, notification PasswordResetNotification) error
 }
 
+type MagicLinkNotification struct {
+	UserID    uint
+	Email     string
+	Token     string
+	ExpiresAt time.Time
+	LoginURL  string
+	Locale    string
+}
+
+type MagicLinkNotifier interface {
+	SendMagicLink(ctx context.Context, notification MagicLinkNotification) error
+}
+
+// EmailChangeNotification carries the confirmation token to the new address.
+type EmailChangeNotification struct {
+	UserID     uint
+	Email      string
+	Token      string
+	ExpiresAt  time.Time
+	ConfirmURL string
+	Locale     string
+}
+
+// EmailChangeNotice tells the current address that a change was requested.
+type EmailChangeNotice struct {
+	UserID   uint
+	OldEmail string
+	NewEmail string
+	Locale   string
+}
+
+type EmailChangeNotifier interface {
+	SendEmailChangeVerification(ctx context.Context, notification EmailChangeNotification) error
+	SendEmailChangeNotice(ctx context.Context, notice EmailChangeNotice) error
+}
+
+// EmailNotifier delivers every kind of account mail; DI picks the dev or SMTP
+// implementation from EMAIL_NOTIFIER.
+type EmailNotifier interface {
+	EmailVerificationNotifier
+	PasswordResetNotifier
+	MagicLinkNotifier
+	EmailChangeNotifier
+}
+
 type DevEmailVerificationNotifier struct {
 	logger *slog.Logger
 }
@@ -67,3 +114,40 @@ func (n *DevEmailVerificationNotifier) SendPasswordReset(ctx context.Context, no
 	)
 	return nil
 }
+
+func (n *DevEmailVerificationNotifier) SendMagicLink(ctx context.Context, notification MagicLinkNotification) error {
+	link := notification.LoginURL
+	if strings.TrimSpace(link) == "" {
+		link = fmt.Sprintf("token=%s", notification.Token)
+	}
+	n.logger.InfoContext(ctx, "magic link token issued",
+		"user_id", notification.UserID,
+		"email", notification.Email,
+		"expires_at", notification.ExpiresAt,
+		"login", link,
+	)
+	return nil
+}
+
+func (n *DevEmailVerificationNotifier) SendEmailChangeVerification(ctx context.Context, notification EmailChangeNotification) error {
+	link := notification.ConfirmURL
+	if strings.TrimSpace(link) == "" {
+		link = fmt.Sprintf("token=%s", notification.Token)
+	}
+	n.logger.InfoContext(ctx, "email change token issued",
+		"user_id", notification.UserID,
+		"email", notification.Email,
+		"expires_at", notification.ExpiresAt,
+		"confirm", link,
+	)
+	return nil
+}
+
+func (n *DevEmailVerificationNotifier) SendEmailChangeNotice(ctx context.Context, notice EmailChangeNotice) error {
+	n.logger.InfoContext(ctx, "email change requested",
+		"user_id", notice.UserID,
+		"email", notice.OldEmail,
+		"new_email", notice.NewEmail,
+	)
+	return nil
+}
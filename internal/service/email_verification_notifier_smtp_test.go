@@ -0,0 +1,116 @@
+package service
+
+import (
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeEmailTemplate(t *testing.T, dir, file, content string) {
+	t.Helper()
+	path := filepath.Join(dir, file)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write template: %v", err)
+	}
+}
+
+func TestEmailTemplatesLocaleFallbackAndOverrides(t *testing.T) {
+	dir := t.TempDir()
+	writeEmailTemplate(t, dir, "pt/password_reset.subject.tmpl", "Redefina sua senha\nagora")
+	writeEmailTemplate(t, dir, "de/password_reset.txt.tmpl", "Hallo {{.Email}}, {{.Link}}")
+
+	templates, err := NewEmailTemplates(dir, "de")
+	if err != nil {
+		t.Fatalf("load templates: %v", err)
+	}
+	data := emailTemplateData{Email: "user@example.com", Link: "https://app.example/reset?token=abc", ExpiresAt: time.Now().Add(time.Hour)}
+
+	email, err := templates.Render("pt-BR", "password_reset", data)
+	if err != nil {
+		t.Fatalf("render pt-BR: %v", err)
+	}
+	if email.Subject != "Redefina sua senha agora" {
+		t.Fatalf("expected base-language override with a single-line subject, got %q", email.Subject)
+	}
+	if email.Text != "Hallo user@example.com, https://app.example/reset?token=abc" {
+		t.Fatalf("expected default-locale text override, got %q", email.Text)
+	}
+	if !strings.Contains(email.HTML, `href="https://app.example/reset?token=abc"`) {
+		t.Fatalf("expected embedded html to carry the link, got %q", email.HTML)
+	}
+
+	email, err = templates.Render("", "magic_link", emailTemplateData{Email: "user@example.com", Token: "raw-token"})
+	if err != nil {
+		t.Fatalf("render magic link: %v", err)
+	}
+	if email.Subject != "Your sign-in link" || !strings.Contains(email.Text, "raw-token") {
+		t.Fatalf("expected embedded en template with the raw token, got %+v", email)
+	}
+
+	if _, err := templates.Render("en", "unknown", data); err == nil {
+		t.Fatal("expected unknown template to fail")
+	}
+
+	writeEmailTemplate(t, dir, "en/magic_link.html.tmpl", "{{if}")
+	if _, err := NewEmailTemplates(dir, "en"); err == nil {
+		t.Fatal("expected a broken override to fail at load")
+	}
+}
+
+func TestBuildEmailMessage(t *testing.T) {
+	from := &mail.Address{Name: "Accounts", Address: "no-reply@example.com"}
+	to := &mail.Address{Address: "user@example.com"}
+	raw, err := buildEmailMessage(from, to, renderedEmail{
+		Subject: "Vérifiez votre adresse",
+		Text:    "Bonjour,\nhttps://app.example/verify?token=" + strings.Repeat("x", 120),
+		HTML:    "<p>Bonjour</p>",
+	}, time.Now())
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+
+	msg, err := mail.ReadMessage(strings.NewReader(string(raw)))
+	if err != nil {
+		t.Fatalf("parse message: %v", err)
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	if err != nil || subject != "Vérifiez votre adresse" {
+		t.Fatalf("unexpected subject %q err=%v", subject, err)
+	}
+	if got := msg.Header.Get("From"); got != `"Accounts" <no-reply@example.com>` {
+		t.Fatalf("unexpected from %q", got)
+	}
+	if !strings.HasSuffix(msg.Header.Get("Message-Id"), "@example.com>") {
+		t.Fatalf("expected message id on the sender domain, got %q", msg.Header.Get("Message-Id"))
+	}
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("unexpected content type %q err=%v", msg.Header.Get("Content-Type"), err)
+	}
+
+	reader := multipart.NewReader(msg.Body, params["boundary"])
+	var bodies []string
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("next part: %v", err)
+		}
+		body, _ := io.ReadAll(part)
+		bodies = append(bodies, string(body))
+	}
+	if len(bodies) != 2 || !strings.Contains(bodies[0], strings.Repeat("x", 120)) || bodies[1] != "<p>Bonjour</p>" {
+		t.Fatalf("unexpected parts: %q", bodies)
+	}
+}
@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/config"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/domain"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/observability"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/security"
+
+	"gorm.io/gorm"
+)
+
+// PermissionImpersonate gates POST /admin/users/{id}/impersonate.
+const PermissionImpersonate = "users:impersonate"
+
+var (
+	ErrImpersonationNotAllowed = errors.New("impersonation requires an interactive user session")
+	ErrImpersonateSelf         = errors.New("cannot impersonate own account")
+	ErrImpersonationPrivileged = errors.New("target holds permissions the actor lacks")
+	ErrNotImpersonating        = errors.New("session is not an impersonation session")
+)
+
+type ImpersonationResult struct {
+	User           *domain.User `json:"user"`
+	AccessToken    string       `json:"-"`
+	ImpersonatorID uint         `json:"impersonator_id"`
+	ExpiresAt      time.Time    `json:"expires_at"`
+}
+
+type ImpersonationService struct {
+	userSvc  UserServiceInterface
+	tokenSvc *TokenService
+	rbac     RBACAuthorizer
+	ttl      time.Duration
+}
+
+func NewImpersonationService(cfg *config.Config, userSvc UserServiceInterface, tokenSvc *TokenService, rbac RBACAuthorizer) *ImpersonationService {
+	return &ImpersonationService{userSvc: userSvc, tokenSvc: tokenSvc, rbac: rbac, ttl: cfg.AuthImpersonationTTL}
+}
+
+// Start opens an impersonation session for targetID on behalf of the caller
+// behind actor. Only a user signed in with a regular access token may
+// impersonate, and only users whose permissions the actor already holds and
+// who cannot impersonate others themselves.
+func (s *ImpersonationService) Start(actor *security.Claims, actorPerms []string, targetID uint, ua, ip string) (*ImpersonationResult, error) {
+	result, err := s.start(actor, actorPerms, targetID, ua, ip)
+	observability.RecordImpersonationEvent(context.Background(), "start", impersonationOutcome(err))
+	return result, err
+}
+
+func (s *ImpersonationService) start(actor *security.Claims, actorPerms []string, targetID uint, ua, ip string) (*ImpersonationResult, error) {
+	if actor == nil || actor.TokenType != "access" || actor.IsServiceAccount() || actor.IsImpersonated() {
+		return nil, ErrImpersonationNotAllowed
+	}
+	actorID, err := strconv.ParseUint(actor.Subject, 10, 64)
+	if err != nil {
+		return nil, ErrImpersonationNotAllowed
+	}
+	if uint(actorID) == targetID {
+		return nil, ErrImpersonateSelf
+	}
+	user, perms, err := s.userSvc.GetByID(targetID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.IsActive() {
+		return nil, ErrAccountInactive
+	}
+	if s.rbac.HasPermission(perms, PermissionImpersonate) {
+		return nil, ErrImpersonationPrivileged
+	}
+	for _, perm := range perms {
+		if !s.rbac.HasPermission(actorPerms, perm) {
+			return nil, ErrImpersonationPrivileged
+		}
+	}
+	access, expiresAt, err := s.tokenSvc.IssueImpersonation(user, perms, uint(actorID), s.ttl, ua, ip)
+	if err != nil {
+		return nil, err
+	}
+	return &ImpersonationResult{User: user, AccessToken: access, ImpersonatorID: uint(actorID), ExpiresAt: expiresAt}, nil
+}
+
+// End revokes the impersonation session behind claims and returns the
+// impersonating admin's user ID.
+func (s *ImpersonationService) End(claims *security.Claims) (uint, error) {
+	actorID, err := s.end(claims)
+	observability.RecordImpersonationEvent(context.Background(), "end", impersonationOutcome(err))
+	return actorID, err
+}
+
+func (s *ImpersonationService) end(claims *security.Claims) (uint, error) {
+	if !claims.IsImpersonated() {
+		return 0, ErrNotImpersonating
+	}
+	userID, err := strconv.ParseUint(claims.Subject, 10, 64)
+	if err != nil {
+		return 0, ErrNotImpersonating
+	}
+	actorID, err := strconv.ParseUint(claims.Act.Subject, 10, 64)
+	if err != nil {
+		return 0, ErrNotImpersonating
+	}
+	if err := s.tokenSvc.EndImpersonation(uint(userID), claims.ID); err != nil {
+		return 0, err
+	}
+	return uint(actorID), nil
+}
+
+func impersonationOutcome(err error) string {
+	switch {
+	case err == nil:
+		return "success"
+	case errors.Is(err, ErrImpersonationNotAllowed), errors.Is(err, ErrImpersonateSelf),
+		errors.Is(err, ErrImpersonationPrivileged), errors.Is(err, ErrNotImpersonating),
+		errors.Is(err, ErrAccountInactive):
+		return "rejected"
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return "not_found"
+	default:
+		return "error"
+	}
+}
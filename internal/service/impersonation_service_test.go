@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/config"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/domain"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/security"
+)
+
+func TestImpersonationStartGuards(t *testing.T) {
+	repo := newInMemorySessionRepo()
+	tokenSvc := newTestTokenService(repo)
+	users := &stubUserService{perms: []string{"users:read"}}
+	svc := NewImpersonationService(&config.Config{AuthImpersonationTTL: time.Hour}, users, tokenSvc, NewRBACService())
+	admin := &security.Claims{TokenType: "access"}
+	admin.Subject = "7"
+	adminPerms := []string{"users:read", PermissionImpersonate}
+
+	if _, err := svc.Start(admin, adminPerms, 7, "ua", "ip"); !errors.Is(err, ErrImpersonateSelf) {
+		t.Fatalf("expected ErrImpersonateSelf, got %v", err)
+	}
+	if _, err := svc.Start(&security.Claims{TokenType: "access", PrincipalType: security.PrincipalServiceAccount}, adminPerms, 42, "ua", "ip"); !errors.Is(err, ErrImpersonationNotAllowed) {
+		t.Fatalf("expected service accounts to be refused, got %v", err)
+	}
+	if _, err := svc.Start(admin, []string{PermissionImpersonate}, 42, "ua", "ip"); !errors.Is(err, ErrImpersonationPrivileged) {
+		t.Fatalf("expected a target with extra permissions to be refused, got %v", err)
+	}
+	users.status = domain.UserStatusSuspended
+	if _, err := svc.Start(admin, adminPerms, 42, "ua", "ip"); !errors.Is(err, ErrAccountInactive) {
+		t.Fatalf("expected an inactive target to be refused, got %v", err)
+	}
+	users.status = ""
+	users.perms = []string{PermissionImpersonate}
+	if _, err := svc.Start(admin, adminPerms, 42, "ua", "ip"); !errors.Is(err, ErrImpersonationPrivileged) {
+		t.Fatalf("expected a target who can impersonate to be refused, got %v", err)
+	}
+}
+
+func TestImpersonationStartAndEnd(t *testing.T) {
+	repo := newInMemorySessionRepo()
+	tokenSvc := newTestTokenService(repo)
+	svc := NewImpersonationService(&config.Config{AuthImpersonationTTL: time.Hour}, &stubUserService{perms: []string{"users:read"}}, tokenSvc, NewRBACService())
+	admin := &security.Claims{TokenType: "access"}
+	admin.Subject = "7"
+
+	result, err := svc.Start(admin, []string{"users:read", PermissionImpersonate}, 42, "ua", "ip")
+	if err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	if time.Until(result.ExpiresAt) > 15*time.Minute {
+		t.Fatalf("expected the ttl to be capped at the access ttl, got expiry %v", result.ExpiresAt)
+	}
+	claims, err := tokenSvc.jwtMgr.ParseAccessToken(result.AccessToken)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if claims.Subject != "42" || !claims.IsImpersonated() || claims.Act.Subject != "7" {
+		t.Fatalf("unexpected impersonation claims: %+v", claims)
+	}
+	session, err := repo.FindActiveByTokenIDForUser(42, claims.ID)
+	if err != nil || session.ImpersonatorID == nil || *session.ImpersonatorID != 7 {
+		t.Fatalf("expected an impersonation session row, got %+v err=%v", session, err)
+	}
+	if _, err := svc.Start(claims, []string{"users:read", PermissionImpersonate}, 43, "ua", "ip"); !errors.Is(err, ErrImpersonationNotAllowed) {
+		t.Fatalf("expected nested impersonation to be refused, got %v", err)
+	}
+
+	if _, err := svc.End(admin); !errors.Is(err, ErrNotImpersonating) {
+		t.Fatalf("expected ErrNotImpersonating, got %v", err)
+	}
+	actorID, err := svc.End(claims)
+	if err != nil || actorID != 7 {
+		t.Fatalf("end: actor=%d err=%v", actorID, err)
+	}
+	if _, err := repo.FindActiveByTokenIDForUser(42, claims.ID); err == nil {
+		t.Fatal("expected the impersonation session to be revoked")
+	}
+	if revoked, err := tokenSvc.revoker.store.IsRevoked(context.Background(), claims.ID); err != nil || !revoked {
+		t.Fatalf("expected the impersonation token to be denylisted, got %v err=%v", revoked, err)
+	}
+}
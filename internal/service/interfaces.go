@@ -9,15 +9,30 @@ import (
 )
 
 type AuthServiceInterface interface {
-	GoogleLoginURL(state string) string
-	LoginWithGoogleCode(code, ua, ip string) (*LoginResult, error)
+	OAuthProviderEnabled(provider string) bool
+	OAuthLoginURL(flow security.OAuthFlowState) (string, error)
+	LoginWithOAuthCode(flow security.OAuthFlowState, code, ua, ip string) (*LoginResult, error)
+	LinkOAuthIdentity(flow security.OAuthFlowState, code string) (*domain.OAuthAccount, error)
+	ListIdentities(userID uint) (*LinkedIdentities, error)
+	UnlinkIdentity(userID, identityID uint) error
+	DeleteWebAuthnCredential(userID, credentialID uint) error
+	BeginOAuthReauth(userID uint, tokenID string, flow *security.OAuthFlowState) (string, error)
+	ReauthenticateWithOAuthCode(flow security.OAuthFlowState, code string) (*ReauthResult, error)
+	ReauthenticateWithPassword(userID uint, tokenID, password string) (*ReauthResult, error)
 	RegisterLocal(email, name, password, ua, ip string) (*LoginResult, error)
 	LoginWithLocalPassword(email, password, ua, ip string) (*LoginResult, error)
-	RequestLocalEmailVerification(email string) error
+	RequestLocalEmailVerification(email, locale string) error
 	ConfirmLocalEmailVerification(token string) error
-	ForgotLocalPassword(email string) error
+	ForgotLocalPassword(email, locale string) error
 	ResetLocalPassword(token, newPassword string) error
+	RequestMagicLink(email, locale string) error
+	ConfirmMagicLink(token, ua, ip string) (*LoginResult, error)
 	ChangeLocalPassword(userID uint, currentPassword, newPassword string) error
+	BeginTOTPEnrollment(userID uint) (*TOTPEnrollment, error)
+	ConfirmTOTPEnrollment(userID uint, code string) ([]string, error)
+	ResolveMFAChallenge(challengeToken string) (uint, error)
+	VerifyMFALogin(challengeToken, code, ua, ip string) (*LoginResult, error)
+	LoginWithWebAuthn(challengeID string, credential []byte, ua, ip string) (*LoginResult, error)
 	Refresh(refreshToken, ua, ip string) (*LoginResult, error)
 	Logout(userID uint) error
 	ParseUserID(subject string) (uint, error)
@@ -26,7 +41,28 @@ type AuthServiceInterface interface {
 type UserServiceInterface interface {
 	GetByID(id uint) (*domain.User, []string, error)
 	List() ([]domain.User, error)
-	SetRoles(userID uint, roleIDs []uint) error
+	SetRoleBindings(userID uint, bindings []domain.UserRole) error
+}
+
+type UserStatusManager interface {
+	Suspend(userID uint) (*domain.User, string, error)
+	Reactivate(userID uint) (*domain.User, string, error)
+}
+
+type EmailChangeManager interface {
+	RequestEmailChange(userID uint, newEmail, locale string) error
+	ConfirmEmailChange(userID uint, token string) (*EmailChangeResult, error)
+}
+
+type AccountDataManager interface {
+	Export(userID uint) (*AccountExport, error)
+	ScheduleDeletion(userID, currentSessionID uint) (*domain.User, error)
+	Erase(userID uint) (*domain.User, string, error)
+}
+
+type ImpersonationManager interface {
+	Start(actor *security.Claims, actorPerms []string, targetID uint, ua, ip string) (*ImpersonationResult, error)
+	End(claims *security.Claims) (uint, error)
 }
 
 type RBACAuthorizer interface {
@@ -39,6 +75,68 @@ type PermissionResolver interface {
 	InvalidateAll(ctx context.Context) error
 }
 
+type WebAuthnServiceInterface interface {
+	BeginRegistration(ctx context.Context, userID uint) (*WebAuthnCeremony, error)
+	FinishRegistration(ctx context.Context, userID uint, challengeID, name string, response []byte) (*domain.WebAuthnCredential, error)
+	BeginLogin(ctx context.Context) (*WebAuthnCeremony, error)
+	ListCredentials(userID uint) ([]domain.WebAuthnCredential, error)
+}
+
+type APIKeyServiceInterface interface {
+	CreateKey(userID uint, input APIKeyInput) (*CreatedAPIKey, error)
+	ListKeys(userID uint) ([]APIKeyView, error)
+	RevokeKey(userID, keyID uint) (bool, error)
+}
+
+type APIKeyAuthenticator interface {
+	AuthenticateAPIKey(ctx context.Context, raw string) (*APIKeyPrincipal, error)
+}
+
+type ServiceAccountServiceInterface interface {
+	Create(input ServiceAccountInput) (*ServiceAccountCredentials, error)
+	List() ([]ServiceAccountView, error)
+	Get(id uint) (*ServiceAccountView, error)
+	Update(id uint, update ServiceAccountUpdate) (*ServiceAccountView, error)
+	RotateSecret(id uint) (*ServiceAccountCredentials, error)
+	Delete(id uint) error
+	IssueToken(ctx context.Context, clientID, clientSecret, scope string) (*ServiceAccountToken, error)
+}
+
+type OrganizationServiceInterface interface {
+	Create(input OrganizationInput) (*OrganizationView, error)
+	List() ([]OrganizationView, error)
+	ListForUser(userID uint) ([]OrganizationView, error)
+	Get(id uint) (*OrganizationView, error)
+	Members(orgID uint) ([]OrganizationMemberView, error)
+	SetMember(orgID, userID uint, roleIDs []uint, actorPerms []string) (*OrganizationMemberView, error)
+	RemoveMember(orgID, userID uint) error
+}
+
+type OrganizationMembershipChecker interface {
+	Exists(orgID uint) (bool, error)
+	IsMember(orgID, userID uint) (bool, error)
+}
+
+type OrganizationPermissionSource interface {
+	MemberPermissions(orgID, userID uint) ([]string, error)
+}
+
+type AccessPolicyServiceInterface interface {
+	List() ([]domain.AccessPolicy, error)
+	Get(id uint) (*domain.AccessPolicy, error)
+	Create(input AccessPolicyInput) (*domain.AccessPolicy, error)
+	Update(id uint, input AccessPolicyInput) (*domain.AccessPolicy, error)
+	Delete(id uint) error
+}
+
+type AccessPolicyEvaluator interface {
+	Evaluate(ctx context.Context, req AccessRequest) (AccessDecision, error)
+}
+
+type MFAStatusChecker interface {
+	MFAEnabled(ctx context.Context, userID uint) (bool, error)
+}
+
 type SessionServiceInterface interface {
 	ListActiveSessions(userID uint, currentSessionID uint) ([]SessionView, error)
 	ResolveCurrentSessionID(r *http.Request, claims *security.Claims, userID uint) (uint, error)
@@ -0,0 +1,179 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/config"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/domain"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/repository"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/security"
+)
+
+var ErrJWTKeyringDisabled = errors.New("asymmetric jwt signing is disabled")
+
+// JWTKeyService owns the asymmetric access-token keyring. Keys live in the
+// database so every API instance and the rotation CLI share one keyring; each
+// instance reloads it periodically.
+type JWTKeyService struct {
+	cfg       *config.Config
+	repo      repository.JWTSigningKeyRepository
+	secretBox *security.SecretBox
+	keyring   *security.Keyring
+	now       func() time.Time
+}
+
+func NewJWTKeyService(cfg *config.Config, repo repository.JWTSigningKeyRepository) (*JWTKeyService, error) {
+	box, err := security.NewSecretBox(cfg.JWTSigningKeyEncryptionKey)
+	if err != nil {
+		return nil, err
+	}
+	s := &JWTKeyService{cfg: cfg, repo: repo, secretBox: box, now: time.Now}
+	if s.Enabled() {
+		s.keyring = security.NewKeyring()
+	}
+	return s, nil
+}
+
+// Enabled reports whether access tokens are signed asymmetrically; in HS256
+// mode the keyring is nil and JWKS is empty.
+func (s *JWTKeyService) Enabled() bool {
+	return s != nil && s.cfg.JWTSigningAlg != "" && s.cfg.JWTSigningAlg != security.SigningAlgHS256
+}
+
+func (s *JWTKeyService) Keyring() *security.Keyring {
+	if s == nil {
+		return nil
+	}
+	return s.keyring
+}
+
+// Bootstrap loads the keyring and creates a first key when none is usable, so
+// a fresh deployment can start signing without a manual rotation.
+func (s *JWTKeyService) Bootstrap(ctx context.Context) error {
+	if !s.Enabled() {
+		return nil
+	}
+	if err := s.Reload(ctx); err != nil {
+		return err
+	}
+	if _, ok := s.keyring.Active(); ok {
+		return nil
+	}
+	key, err := s.newKey(s.now().UTC())
+	if err != nil {
+		return err
+	}
+	if err := s.repo.Create(key); err != nil {
+		return err
+	}
+	return s.Reload(ctx)
+}
+
+func (s *JWTKeyService) Reload(_ context.Context) error {
+	if !s.Enabled() {
+		return nil
+	}
+	rows, err := s.repo.ListUsable(s.now().UTC())
+	if err != nil {
+		return err
+	}
+	keys := make([]security.SigningKey, 0, len(rows))
+	for _, row := range rows {
+		raw, err := s.secretBox.Open(row.PrivateKeyCiphertext)
+		if err != nil {
+			return fmt.Errorf("open signing key %s: %w", row.KID, err)
+		}
+		priv, err := security.ParseSigningKeyPEM(raw)
+		if err != nil {
+			return fmt.Errorf("parse signing key %s: %w", row.KID, err)
+		}
+		keys = append(keys, security.SigningKey{
+			KID:         row.KID,
+			Algorithm:   row.Algorithm,
+			Private:     priv,
+			ActivatesAt: row.ActivatesAt,
+			RetiresAt:   row.RetiresAt,
+		})
+	}
+	s.keyring.Replace(keys)
+	return nil
+}
+
+// Rotate publishes a new key that starts signing after activationDelay. Older
+// keys keep verifying until the last token they could have signed expires.
+// The delay should cover the keyring refresh interval so every instance has
+// loaded the new key before any of them signs with it.
+func (s *JWTKeyService) Rotate(ctx context.Context, activationDelay time.Duration) (*domain.JWTSigningKey, error) {
+	if !s.Enabled() {
+		return nil, ErrJWTKeyringDisabled
+	}
+	if activationDelay < 0 {
+		activationDelay = 0
+	}
+	activatesAt := s.now().UTC().Add(activationDelay)
+	key, err := s.newKey(activatesAt)
+	if err != nil {
+		return nil, err
+	}
+	retireAt := activatesAt.Add(s.cfg.LongestAccessTokenTTL() + s.cfg.JWTKeyringRefreshInterval)
+	if err := s.repo.Rotate(key, retireAt); err != nil {
+		return nil, err
+	}
+	if err := s.Reload(ctx); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+func (s *JWTKeyService) ListKeys() ([]domain.JWTSigningKey, error) {
+	return s.repo.List()
+}
+
+func (s *JWTKeyService) RunRefreshLoop(ctx context.Context, logger *slog.Logger) {
+	interval := s.cfg.JWTKeyringRefreshInterval
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.Reload(ctx); err != nil && logger != nil {
+				logger.Warn("jwt keyring reload failed", "error", err)
+			}
+		}
+	}
+}
+
+func (s *JWTKeyService) newKey(activatesAt time.Time) (*domain.JWTSigningKey, error) {
+	priv, err := security.GenerateSigningKey(s.cfg.JWTSigningAlg)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := security.MarshalSigningKeyPEM(priv)
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := s.secretBox.Seal(raw)
+	if err != nil {
+		return nil, err
+	}
+	kid, err := security.NewRandomString(12)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.JWTSigningKey{
+		KID:                  kid,
+		Algorithm:            s.cfg.JWTSigningAlg,
+		PrivateKeyCiphertext: sealed,
+		ActivatesAt:          activatesAt,
+	}, nil
+}
@@ -0,0 +1,129 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/config"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/domain"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/repository"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/security"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestJWTKeyServiceBootstrapAndRotate(t *testing.T) {
+	ctx := context.Background()
+	svc := newJWTKeyServiceForTest(t, security.SigningAlgES256)
+
+	if err := svc.Bootstrap(ctx); err != nil {
+		t.Fatalf("bootstrap: %v", err)
+	}
+	first, ok := svc.Keyring().Active()
+	if !ok {
+		t.Fatal("expected bootstrap to create an active key")
+	}
+	if err := svc.Bootstrap(ctx); err != nil {
+		t.Fatalf("second bootstrap: %v", err)
+	}
+	if keys, _ := svc.ListKeys(); len(keys) != 1 {
+		t.Fatalf("expected bootstrap to be idempotent, got %d keys", len(keys))
+	}
+
+	mgr := security.NewJWTManagerWithKeyring("iss", "aud", "abcdefghijklmnopqrstuvwxyz654321", svc.Keyring())
+	live, err := mgr.SignAccessToken(7, nil, nil, time.Minute)
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+
+	next, err := svc.Rotate(ctx, time.Minute)
+	if err != nil {
+		t.Fatalf("rotate: %v", err)
+	}
+	if active, _ := svc.Keyring().Active(); active.KID != first.KID {
+		t.Fatalf("expected previous key to sign until activation, got %s", active.KID)
+	}
+	if _, ok := svc.Keyring().Lookup(next.KID); !ok {
+		t.Fatal("expected rotated key to be loaded for verification before activation")
+	}
+	if _, err := mgr.ParseAccessToken(live); err != nil {
+		t.Fatalf("expected live token to survive rotation: %v", err)
+	}
+	if n := len(svc.Keyring().JWKS().Keys); n != 2 {
+		t.Fatalf("expected both keys in jwks, got %d", n)
+	}
+
+	keys, err := svc.ListKeys()
+	if err != nil || len(keys) != 2 {
+		t.Fatalf("expected 2 stored keys, got %d err=%v", len(keys), err)
+	}
+	if keys[0].RetiresAt == nil || !keys[0].RetiresAt.After(next.ActivatesAt) {
+		t.Fatalf("expected previous key to retire after the next key activates, got %v", keys[0].RetiresAt)
+	}
+	if strings.Contains(keys[1].PrivateKeyCiphertext, "PRIVATE KEY") {
+		t.Fatal("expected private key to be sealed at rest")
+	}
+}
+
+func TestJWTKeyServiceRotateOutlivesServiceAccountTokens(t *testing.T) {
+	ctx := context.Background()
+	svc := newJWTKeyServiceForTest(t, security.SigningAlgES256)
+	svc.cfg.AuthServiceAccountsEnabled = true
+	svc.cfg.AuthServiceAccountTokenTTL = time.Hour
+	if err := svc.Bootstrap(ctx); err != nil {
+		t.Fatalf("bootstrap: %v", err)
+	}
+	next, err := svc.Rotate(ctx, 0)
+	if err != nil {
+		t.Fatalf("rotate: %v", err)
+	}
+	keys, err := svc.ListKeys()
+	if err != nil || len(keys) != 2 || keys[0].RetiresAt == nil {
+		t.Fatalf("expected the previous key to be retiring, got %+v err=%v", keys, err)
+	}
+	if want := next.ActivatesAt.Add(time.Hour + time.Minute); keys[0].RetiresAt.Before(want) {
+		t.Fatalf("expected previous key to outlive a 1h service-account token, retires at %v want >= %v", keys[0].RetiresAt, want)
+	}
+}
+
+func TestJWTKeyServiceDisabledForHS256(t *testing.T) {
+	svc := newJWTKeyServiceForTest(t, security.SigningAlgHS256)
+	if svc.Enabled() || svc.Keyring() != nil {
+		t.Fatal("expected HS256 mode to have no keyring")
+	}
+	if err := svc.Bootstrap(context.Background()); err != nil {
+		t.Fatalf("bootstrap should be a no-op: %v", err)
+	}
+	if _, err := svc.Rotate(context.Background(), 0); !errors.Is(err, ErrJWTKeyringDisabled) {
+		t.Fatalf("expected ErrJWTKeyringDisabled, got %v", err)
+	}
+}
+
+func newJWTKeyServiceForTest(t *testing.T, alg string) *JWTKeyService {
+	t.Helper()
+	dsn := fmt.Sprintf("file:%s?mode=memory&cache=shared", strings.ReplaceAll(t.Name(), "/", "_"))
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	if err := db.AutoMigrate(&domain.JWTSigningKey{}); err != nil {
+		t.Fatalf("migrate jwt signing keys: %v", err)
+	}
+	svc, err := NewJWTKeyService(&config.Config{
+		JWTSigningAlg:              alg,
+		JWTSigningKeyEncryptionKey: "0123456789abcdef0123456789abcdef",
+		JWTAccessTTL:               15 * time.Minute,
+		JWTKeyringRefreshInterval:  time.Minute,
+	}, repository.NewJWTSigningKeyRepository(db))
+	if err != nil {
+		t.Fatalf("new jwt key service: %v", err)
+	}
+	return svc
+}
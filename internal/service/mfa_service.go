@@ -0,0 +1,240 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/config"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/domain"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/repository"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/security"
+)
+
+const (
+	mfaChallengePurpose = "mfa_challenge"
+	mfaRecoveryCodes    = 10
+	mfaTOTPSkew         = 1
+)
+
+var (
+	ErrMFAUnavailable      = errors.New("mfa is not configured")
+	ErrMFAAlreadyEnabled   = errors.New("mfa is already enabled")
+	ErrMFANotEnrolled      = errors.New("mfa is not enrolled")
+	ErrInvalidMFACode      = errors.New("invalid mfa code")
+	ErrInvalidMFAChallenge = errors.New("invalid or expired mfa challenge")
+)
+
+type TOTPEnrollment struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"`
+}
+
+type MFAService struct {
+	cfg           *config.Config
+	repo          repository.MFARepository
+	challengeRepo repository.VerificationTokenRepository
+	secretBox     *security.SecretBox
+	now           func() time.Time
+}
+
+func NewMFAService(cfg *config.Config, repo repository.MFARepository, challengeRepo repository.VerificationTokenRepository) (*MFAService, error) {
+	box, err := security.NewSecretBox(cfg.AuthMFAEncryptionKey)
+	if err != nil {
+		return nil, err
+	}
+	return &MFAService{cfg: cfg, repo: repo, challengeRepo: challengeRepo, secretBox: box, now: time.Now}, nil
+}
+
+func (s *MFAService) MFAEnabled(_ context.Context, userID uint) (bool, error) {
+	cred, err := s.repo.FindTOTPByUserID(userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrMFACredentialNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return cred.ConfirmedAt != nil, nil
+}
+
+func (s *MFAService) BeginTOTPEnrollment(userID uint, account string) (*TOTPEnrollment, error) {
+	if !s.secretBox.Available() {
+		return nil, ErrMFAUnavailable
+	}
+	secret, err := security.NewTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := s.secretBox.Seal(secret)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.SavePendingTOTP(userID, sealed); err != nil {
+		if errors.Is(err, repository.ErrMFACredentialNotFound) {
+			return nil, ErrMFAAlreadyEnabled
+		}
+		return nil, err
+	}
+	return &TOTPEnrollment{
+		Secret:     secret,
+		OTPAuthURI: security.TOTPProvisioningURI(s.cfg.AuthMFAIssuer, account, secret),
+	}, nil
+}
+
+// ConfirmTOTPEnrollment activates a pending enrollment and returns the plain
+// recovery codes; only their hashes are stored, so this is the only time they
+// can be shown to the user.
+func (s *MFAService) ConfirmTOTPEnrollment(userID uint, code string) ([]string, error) {
+	cred, err := s.repo.FindTOTPByUserID(userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrMFACredentialNotFound) {
+			return nil, ErrMFANotEnrolled
+		}
+		return nil, err
+	}
+	if cred.ConfirmedAt != nil {
+		return nil, ErrMFAAlreadyEnabled
+	}
+	secret, err := s.secretBox.Open(cred.SecretCiphertext)
+	if err != nil {
+		if errors.Is(err, security.ErrSecretBoxUnavailable) {
+			return nil, ErrMFAUnavailable
+		}
+		return nil, err
+	}
+	step, ok := security.ValidateTOTP(secret, code, s.now(), mfaTOTPSkew)
+	if !ok {
+		return nil, ErrInvalidMFACode
+	}
+
+	codes := make([]string, 0, mfaRecoveryCodes)
+	hashes := make([]string, 0, mfaRecoveryCodes)
+	for range mfaRecoveryCodes {
+		code, err := newRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		codes = append(codes, code)
+		hashes = append(hashes, hashVerificationToken(normalizeRecoveryCode(code)))
+	}
+	if err := s.repo.ConfirmTOTP(userID, step, hashes, s.now().UTC()); err != nil {
+		if errors.Is(err, repository.ErrMFACredentialNotFound) {
+			return nil, ErrMFAAlreadyEnabled
+		}
+		return nil, err
+	}
+	return codes, nil
+}
+
+// VerifyCode accepts either a current TOTP code or an unused recovery code and
+// reports which one matched.
+func (s *MFAService) VerifyCode(userID uint, code string) (string, error) {
+	cred, err := s.repo.FindTOTPByUserID(userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrMFACredentialNotFound) {
+			return "", ErrMFANotEnrolled
+		}
+		return "", err
+	}
+	if cred.ConfirmedAt == nil {
+		return "", ErrMFANotEnrolled
+	}
+
+	code = strings.TrimSpace(code)
+	if len(code) == security.TOTPDigits {
+		secret, err := s.secretBox.Open(cred.SecretCiphertext)
+		if err != nil {
+			if errors.Is(err, security.ErrSecretBoxUnavailable) {
+				return "", ErrMFAUnavailable
+			}
+			return "", err
+		}
+		step, ok := security.ValidateTOTP(secret, code, s.now(), mfaTOTPSkew)
+		if !ok {
+			return "", ErrInvalidMFACode
+		}
+		if err := s.repo.MarkTOTPStepUsed(userID, step); err != nil {
+			if errors.Is(err, repository.ErrMFATOTPStepReused) {
+				return "", ErrInvalidMFACode
+			}
+			return "", err
+		}
+		return "totp", nil
+	}
+
+	normalized := normalizeRecoveryCode(code)
+	if normalized == "" {
+		return "", ErrInvalidMFACode
+	}
+	if err := s.repo.ConsumeRecoveryCode(userID, hashVerificationToken(normalized), s.now().UTC()); err != nil {
+		if errors.Is(err, repository.ErrMFARecoveryCodeNotFound) {
+			return "", ErrInvalidMFACode
+		}
+		return "", err
+	}
+	return "recovery_code", nil
+}
+
+func (s *MFAService) IssueChallenge(userID uint) (string, time.Time, error) {
+	now := s.now().UTC()
+	if err := s.challengeRepo.InvalidateActiveByUserPurpose(userID, mfaChallengePurpose, now); err != nil {
+		return "", time.Time{}, err
+	}
+	raw, err := security.NewRandomString(32)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	expiresAt := now.Add(s.cfg.AuthMFAChallengeTTL)
+	if err := s.challengeRepo.Create(&domain.VerificationToken{
+		UserID:    userID,
+		TokenHash: hashVerificationToken(raw),
+		Purpose:   mfaChallengePurpose,
+		ExpiresAt: expiresAt,
+	}); err != nil {
+		return "", time.Time{}, err
+	}
+	return raw, expiresAt, nil
+}
+
+func (s *MFAService) ResolveChallenge(token string) (*domain.VerificationToken, error) {
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return nil, ErrInvalidMFAChallenge
+	}
+	record, err := s.challengeRepo.FindActiveByHashPurpose(hashVerificationToken(token), mfaChallengePurpose, s.now().UTC())
+	if err != nil {
+		if errors.Is(err, repository.ErrVerificationTokenNotFound) {
+			return nil, ErrInvalidMFAChallenge
+		}
+		return nil, err
+	}
+	return record, nil
+}
+
+func (s *MFAService) ConsumeChallenge(record *domain.VerificationToken) error {
+	if err := s.challengeRepo.Consume(record.ID, record.UserID, s.now().UTC()); err != nil {
+		if errors.Is(err, repository.ErrVerificationTokenNotFound) {
+			return ErrInvalidMFAChallenge
+		}
+		return err
+	}
+	return nil
+}
+
+func newRecoveryCode() (string, error) {
+	b := make([]byte, 7)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	raw := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(b)[:10]
+	return strings.ToLower(raw[:5] + "-" + raw[5:]), nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	code = strings.ReplaceAll(code, "-", "")
+	return strings.ReplaceAll(code, " ", "")
+}
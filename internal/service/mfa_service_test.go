@@ -0,0 +1,141 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/config"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/domain"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/repository"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/security"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func newMFAServiceForTest(t *testing.T, verifyRepo repository.VerificationTokenRepository) *MFAService {
+	t.Helper()
+	dsn := fmt.Sprintf("file:%s?mode=memory&cache=shared", strings.ReplaceAll(t.Name(), "/", "_"))
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	if err := db.AutoMigrate(&domain.MFATOTPCredential{}, &domain.MFARecoveryCode{}); err != nil {
+		t.Fatalf("migrate mfa models: %v", err)
+	}
+	cfg := &config.Config{
+		AuthMFAEncryptionKey: "mfa-encryption-key-0123456789abcdef",
+		AuthMFAIssuer:        "starter-kit-test",
+		AuthMFAChallengeTTL:  5 * time.Minute,
+	}
+	svc, err := NewMFAService(cfg, repository.NewMFARepository(db), verifyRepo)
+	if err != nil {
+		t.Fatalf("new mfa service: %v", err)
+	}
+	return svc
+}
+
+func enrollTOTPForTest(t *testing.T, svc *MFAService, userID uint) (string, []string) {
+	t.Helper()
+	enrollment, err := svc.BeginTOTPEnrollment(userID, "user@example.com")
+	if err != nil {
+		t.Fatalf("begin enrollment: %v", err)
+	}
+	code, err := security.TOTPCode(enrollment.Secret, security.TOTPStep(svc.now()))
+	if err != nil {
+		t.Fatalf("totp code: %v", err)
+	}
+	recovery, err := svc.ConfirmTOTPEnrollment(userID, code)
+	if err != nil {
+		t.Fatalf("confirm enrollment: %v", err)
+	}
+	return enrollment.Secret, recovery
+}
+
+func TestMFAServiceEnrollmentAndVerification(t *testing.T) {
+	svc := newMFAServiceForTest(t, newFakeVerificationTokenRepo())
+	now := time.Unix(1_700_000_000, 0)
+	svc.now = func() time.Time { return now }
+
+	if enabled, err := svc.MFAEnabled(context.Background(), 3); err != nil || enabled {
+		t.Fatalf("expected mfa disabled before enrollment, got %v err=%v", enabled, err)
+	}
+	if _, err := svc.VerifyCode(3, "123456"); !errors.Is(err, ErrMFANotEnrolled) {
+		t.Fatalf("expected ErrMFANotEnrolled, got %v", err)
+	}
+
+	secret, recovery := enrollTOTPForTest(t, svc, 3)
+	if len(recovery) != mfaRecoveryCodes {
+		t.Fatalf("expected %d recovery codes, got %d", mfaRecoveryCodes, len(recovery))
+	}
+	if enabled, err := svc.MFAEnabled(context.Background(), 3); err != nil || !enabled {
+		t.Fatalf("expected mfa enabled after confirmation, got %v err=%v", enabled, err)
+	}
+	if _, err := svc.BeginTOTPEnrollment(3, "user@example.com"); !errors.Is(err, ErrMFAAlreadyEnabled) {
+		t.Fatalf("expected ErrMFAAlreadyEnabled, got %v", err)
+	}
+
+	// The confirmation code's step is burned, so the same code cannot log in.
+	current, _ := security.TOTPCode(secret, security.TOTPStep(now))
+	if _, err := svc.VerifyCode(3, current); !errors.Is(err, ErrInvalidMFACode) {
+		t.Fatalf("expected replayed confirmation code to fail, got %v", err)
+	}
+	now = now.Add(security.TOTPPeriod)
+	next, _ := security.TOTPCode(secret, security.TOTPStep(now))
+	if method, err := svc.VerifyCode(3, next); err != nil || method != "totp" {
+		t.Fatalf("expected totp verification, got %q err=%v", method, err)
+	}
+
+	if method, err := svc.VerifyCode(3, strings.ToUpper(recovery[0])); err != nil || method != "recovery_code" {
+		t.Fatalf("expected recovery code verification, got %q err=%v", method, err)
+	}
+	if _, err := svc.VerifyCode(3, recovery[0]); !errors.Is(err, ErrInvalidMFACode) {
+		t.Fatalf("expected used recovery code to fail, got %v", err)
+	}
+}
+
+func TestMFAServiceUnavailableWithoutKey(t *testing.T) {
+	svc, err := NewMFAService(&config.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("new mfa service: %v", err)
+	}
+	if _, err := svc.BeginTOTPEnrollment(1, "user@example.com"); !errors.Is(err, ErrMFAUnavailable) {
+		t.Fatalf("expected ErrMFAUnavailable, got %v", err)
+	}
+}
+
+func TestAuthServiceLocalLoginRequiresMFAChallenge(t *testing.T) {
+	fx := newAuthServiceFixture()
+	mfa := newMFAServiceForTest(t, fx.verifyRepo)
+	fx.auth.mfaSvc = mfa
+	uid := fx.seedLocalUser("mfa@example.com", "MFA", "Valid#Pass1234", true)
+	_, recovery := enrollTOTPForTest(t, mfa, uid)
+
+	result, err := fx.auth.LoginWithLocalPassword("mfa@example.com", "Valid#Pass1234", "ua", "127.0.0.1")
+	if err != nil {
+		t.Fatalf("login: %v", err)
+	}
+	if !result.MFARequired || result.MFAToken == "" || result.AccessToken != "" {
+		t.Fatalf("expected mfa challenge without tokens, got %+v", result)
+	}
+
+	if _, err := fx.auth.VerifyMFALogin(result.MFAToken, "000000", "ua", "127.0.0.1"); !errors.Is(err, ErrInvalidMFACode) {
+		t.Fatalf("expected ErrInvalidMFACode, got %v", err)
+	}
+	verified, err := fx.auth.VerifyMFALogin(result.MFAToken, recovery[1], "ua", "127.0.0.1")
+	if err != nil {
+		t.Fatalf("verify mfa login: %v", err)
+	}
+	if verified.AccessToken == "" || verified.RefreshToken == "" || verified.MFAMethod != "recovery_code" {
+		t.Fatalf("expected issued tokens after mfa, got %+v", verified)
+	}
+	if _, err := fx.auth.VerifyMFALogin(result.MFAToken, recovery[2], "ua", "127.0.0.1"); !errors.Is(err, ErrInvalidMFAChallenge) {
+		t.Fatalf("expected consumed challenge to be rejected, got %v", err)
+	}
+}
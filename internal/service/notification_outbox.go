@@ -0,0 +1,257 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"os"
+	"time"
+
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/config"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/domain"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/observability"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/repository"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/security"
+)
+
+const (
+	OutboxKindEmailVerification = "email_verification"
+	OutboxKindPasswordReset     = "password_reset"
+	OutboxKindMagicLink         = "magic_link"
+)
+
+var errOutboxUndeliverable = errors.New("outbox message cannot be delivered")
+
+// newOutboxMessage serializes a notification for the dispatcher and seals it
+// with NOTIFICATION_OUTBOX_ENCRYPTION_KEY, since it carries a raw token. The
+// message expires with the token; delivering a dead link helps nobody.
+func newOutboxMessage(cfg *config.Config, kind string, userID uint, notification any, expiresAt time.Time, now time.Time) (*domain.OutboxMessage, error) {
+	payload, err := json.Marshal(notification)
+	if err != nil {
+		return nil, err
+	}
+	box, err := security.NewSecretBox(cfg.NotificationOutboxEncryptionKey)
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := box.Seal(string(payload))
+	if err != nil {
+		return nil, err
+	}
+	return &domain.OutboxMessage{
+		Kind:          kind,
+		UserID:        userID,
+		Payload:       sealed,
+		Status:        domain.OutboxStatusPending,
+		NextAttemptAt: now,
+		ExpiresAt:     &expiresAt,
+	}, nil
+}
+
+// NotificationOutbox delivers queued notifications in the background. Every
+// replica runs a dispatcher; ClaimDue leases rows so each message has one
+// sender at a time. Failed sends are retried with exponential backoff until
+// NOTIFICATION_OUTBOX_MAX_ATTEMPTS, then parked as dead for an operator to
+// inspect and replay with the outbox tool.
+type NotificationOutbox struct {
+	cfg      *config.Config
+	repo     repository.OutboxRepository
+	notifier EmailNotifier
+	workerID string
+}
+
+func NewNotificationOutbox(cfg *config.Config, repo repository.OutboxRepository, notifier EmailNotifier) *NotificationOutbox {
+	return &NotificationOutbox{
+		cfg:      cfg,
+		repo:     repo,
+		notifier: notifier,
+		workerID: newOutboxWorkerID(),
+	}
+}
+
+func newOutboxWorkerID() string {
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		host = "worker"
+	}
+	suffix, err := security.NewRandomString(6)
+	if err != nil {
+		return host
+	}
+	id := host + "-" + suffix
+	if len(id) > 64 {
+		id = id[len(id)-64:]
+	}
+	return id
+}
+
+// DispatchDue delivers one batch of due messages and reports how many were
+// sent.
+func (o *NotificationOutbox) DispatchDue(ctx context.Context, now time.Time) (int, error) {
+	messages, err := o.repo.ClaimDue(now, o.cfg.NotificationOutboxBatchSize, o.cfg.NotificationOutboxLease, o.workerID)
+	if err != nil {
+		return 0, err
+	}
+	sent := 0
+	var errs []error
+	for i := range messages {
+		ok, err := o.dispatch(ctx, &messages[i], now)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		if ok {
+			sent++
+		}
+	}
+	return sent, errors.Join(errs...)
+}
+
+func (o *NotificationOutbox) dispatch(ctx context.Context, msg *domain.OutboxMessage, now time.Time) (bool, error) {
+	if msg.ExpiresAt != nil && !msg.ExpiresAt.After(now) {
+		observability.RecordNotificationOutboxEvent(ctx, msg.Kind, "expired")
+		return false, o.repo.MarkDead(msg.ID, o.workerID, "expired before delivery", true, now)
+	}
+
+	sendErr := o.deliver(ctx, msg)
+	if sendErr == nil {
+		observability.RecordNotificationOutboxEvent(ctx, msg.Kind, "sent")
+		return true, o.repo.MarkSent(msg.ID, o.workerID, now)
+	}
+	if errors.Is(sendErr, errOutboxUndeliverable) || msg.Attempts >= o.cfg.NotificationOutboxMaxAttempts {
+		observability.RecordNotificationOutboxEvent(ctx, msg.Kind, "dead")
+		return false, o.repo.MarkDead(msg.ID, o.workerID, sendErr.Error(), false, now)
+	}
+	observability.RecordNotificationOutboxEvent(ctx, msg.Kind, "retry")
+	return false, o.repo.MarkRetry(msg.ID, o.workerID, sendErr.Error(), now.Add(o.backoff(msg.Attempts)), now)
+}
+
+func (o *NotificationOutbox) deliver(ctx context.Context, msg *domain.OutboxMessage) error {
+	switch msg.Kind {
+	case OutboxKindEmailVerification:
+		var n VerificationNotification
+		if err := o.openPayload(msg, &n); err != nil {
+			return err
+		}
+		return o.notifier.SendEmailVerification(ctx, n)
+	case OutboxKindPasswordReset:
+		var n PasswordResetNotification
+		if err := o.openPayload(msg, &n); err != nil {
+			return err
+		}
+		return o.notifier.SendPasswordReset(ctx, n)
+	case OutboxKindMagicLink:
+		var n MagicLinkNotification
+		if err := o.openPayload(msg, &n); err != nil {
+			return err
+		}
+		return o.notifier.SendMagicLink(ctx, n)
+	default:
+		return fmt.Errorf("%w: unknown kind %q", errOutboxUndeliverable, msg.Kind)
+	}
+}
+
+// openPayload unseals and decodes a message built by newOutboxMessage. A
+// payload that does not open under the current key will never open, so the
+// message is reported undeliverable rather than retried.
+func (o *NotificationOutbox) openPayload(msg *domain.OutboxMessage, notification any) error {
+	box, err := security.NewSecretBox(o.cfg.NotificationOutboxEncryptionKey)
+	if err != nil {
+		return err
+	}
+	payload, err := box.Open(msg.Payload)
+	if err != nil {
+		return fmt.Errorf("%w: open payload: %v", errOutboxUndeliverable, err)
+	}
+	if err := json.Unmarshal([]byte(payload), notification); err != nil {
+		return fmt.Errorf("%w: %v", errOutboxUndeliverable, err)
+	}
+	return nil
+}
+
+// backoff doubles the delay per attempt from the base up to the cap and adds
+// up to 10% jitter so messages that failed together do not retry together.
+func (o *NotificationOutbox) backoff(attempts int) time.Duration {
+	delay := o.cfg.NotificationOutboxBaseBackoff
+	for i := 1; i < attempts && delay < o.cfg.NotificationOutboxMaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > o.cfg.NotificationOutboxMaxBackoff {
+		delay = o.cfg.NotificationOutboxMaxBackoff
+	}
+	if jitter := int64(delay / 10); jitter > 0 {
+		// #nosec G404 -- Retry jitter does not need a cryptographic source.
+		delay += time.Duration(rand.Int64N(jitter))
+	}
+	return delay
+}
+
+func (o *NotificationOutbox) PurgeSent(now time.Time) (int64, error) {
+	return o.repo.DeleteSentBefore(now.Add(-o.cfg.NotificationOutboxRetention), o.cfg.NotificationOutboxBatchSize)
+}
+
+func (o *NotificationOutbox) List(status string, limit int) ([]domain.OutboxMessage, error) {
+	return o.repo.List(status, limit)
+}
+
+func (o *NotificationOutbox) Replay(ctx context.Context, id uint, now time.Time) error {
+	msg, err := o.repo.FindByID(id)
+	if err != nil {
+		return err
+	}
+	if err := o.repo.Replay(id, now); err != nil {
+		return err
+	}
+	observability.RecordNotificationOutboxEvent(ctx, msg.Kind, "replayed")
+	return nil
+}
+
+// ReplayDead requeues up to limit dead messages, newest first, and reports how
+// many could be replayed.
+func (o *NotificationOutbox) ReplayDead(ctx context.Context, now time.Time, limit int) (int, error) {
+	messages, err := o.repo.List(domain.OutboxStatusDead, limit)
+	if err != nil {
+		return 0, err
+	}
+	replayed := 0
+	for _, msg := range messages {
+		if err := o.repo.Replay(msg.ID, now); err != nil {
+			if errors.Is(err, repository.ErrOutboxNotReplayable) {
+				continue
+			}
+			return replayed, err
+		}
+		observability.RecordNotificationOutboxEvent(ctx, msg.Kind, "replayed")
+		replayed++
+	}
+	return replayed, nil
+}
+
+func (o *NotificationOutbox) RunDispatchLoop(ctx context.Context, logger *slog.Logger) {
+	interval := o.cfg.NotificationOutboxPollInterval
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			now := time.Now().UTC()
+			sent, err := o.DispatchDue(ctx, now)
+			if err != nil && logger != nil {
+				logger.Warn("notification outbox dispatch failed", "error", err, "sent", sent)
+			}
+			if purged, err := o.PurgeSent(now); err != nil && logger != nil {
+				logger.Warn("notification outbox purge failed", "error", err)
+			} else if purged > 0 && logger != nil {
+				logger.Info("notification outbox purged sent messages", "purged", purged)
+			}
+		}
+	}
+}
@@ -0,0 +1,235 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/config"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/domain"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/repository"
+)
+
+type flakyEmailNotifier struct {
+	failures int
+	resets   []PasswordResetNotification
+	magic    []MagicLinkNotification
+}
+
+func (n *flakyEmailNotifier) fail() error {
+	if n.failures > 0 {
+		n.failures--
+		return errors.New("smtp dial: connection refused")
+	}
+	return nil
+}
+
+func (n *flakyEmailNotifier) SendEmailVerification(context.Context, VerificationNotification) error {
+	return n.fail()
+}
+
+func (n *flakyEmailNotifier) SendPasswordReset(_ context.Context, notification PasswordResetNotification) error {
+	if err := n.fail(); err != nil {
+		return err
+	}
+	n.resets = append(n.resets, notification)
+	return nil
+}
+
+func (n *flakyEmailNotifier) SendMagicLink(_ context.Context, notification MagicLinkNotification) error {
+	if err := n.fail(); err != nil {
+		return err
+	}
+	n.magic = append(n.magic, notification)
+	return nil
+}
+
+func (n *flakyEmailNotifier) SendEmailChangeVerification(context.Context, EmailChangeNotification) error {
+	return n.fail()
+}
+
+func (n *flakyEmailNotifier) SendEmailChangeNotice(context.Context, EmailChangeNotice) error {
+	return n.fail()
+}
+
+func newNotificationOutboxForTest(t *testing.T, notifier EmailNotifier) (*NotificationOutbox, *gorm.DB) {
+	t.Helper()
+	dsn := fmt.Sprintf("file:%s?mode=memory&cache=shared", strings.ReplaceAll(t.Name(), "/", "_"))
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	if err := db.AutoMigrate(&domain.OutboxMessage{}); err != nil {
+		t.Fatalf("migrate outbox: %v", err)
+	}
+	cfg := &config.Config{
+		NotificationOutboxEnabled:       true,
+		NotificationOutboxEncryptionKey: testOutboxEncryptionKey,
+		NotificationOutboxBatchSize:     10,
+		NotificationOutboxMaxAttempts:   3,
+		NotificationOutboxBaseBackoff:   30 * time.Second,
+		NotificationOutboxMaxBackoff:    time.Hour,
+		NotificationOutboxLease:         time.Minute,
+		NotificationOutboxRetention:     24 * time.Hour,
+	}
+	return NewNotificationOutbox(cfg, repository.NewOutboxRepository(db), notifier), db
+}
+
+const testOutboxEncryptionKey = "outbox-encryption-key-0123456789abcdef"
+
+func enqueueForTest(t *testing.T, db *gorm.DB, kind string, notification any, expiresAt, now time.Time) *domain.OutboxMessage {
+	t.Helper()
+	msg, err := newOutboxMessage(&config.Config{NotificationOutboxEncryptionKey: testOutboxEncryptionKey}, kind, 7, notification, expiresAt, now)
+	if err != nil {
+		t.Fatalf("build message: %v", err)
+	}
+	if err := db.Create(msg).Error; err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	return msg
+}
+
+func loadOutboxMessage(t *testing.T, db *gorm.DB, id uint) domain.OutboxMessage {
+	t.Helper()
+	var msg domain.OutboxMessage
+	if err := db.First(&msg, id).Error; err != nil {
+		t.Fatalf("load message: %v", err)
+	}
+	return msg
+}
+
+func TestNotificationOutboxRetriesWithBackoffThenDelivers(t *testing.T) {
+	notifier := &flakyEmailNotifier{failures: 2}
+	outbox, db := newNotificationOutboxForTest(t, notifier)
+	now := time.Now().UTC()
+	msg := enqueueForTest(t, db, OutboxKindPasswordReset, PasswordResetNotification{
+		UserID: 7, Email: "user@example.com", Token: "raw-token", ExpiresAt: now.Add(time.Hour),
+	}, now.Add(time.Hour), now)
+
+	if sent, err := outbox.DispatchDue(context.Background(), now); err != nil || sent != 0 {
+		t.Fatalf("expected first attempt to fail, got sent=%d err=%v", sent, err)
+	}
+	stored := loadOutboxMessage(t, db, msg.ID)
+	firstDelay := stored.NextAttemptAt.Sub(now)
+	if stored.Status != domain.OutboxStatusPending || stored.Attempts != 1 || firstDelay < 30*time.Second || firstDelay > 33*time.Second {
+		t.Fatalf("expected retry after the base backoff, got %+v (delay %s)", stored, firstDelay)
+	}
+	if sent, _ := outbox.DispatchDue(context.Background(), now.Add(10*time.Second)); sent != 0 {
+		t.Fatal("expected the message to wait out its backoff")
+	}
+
+	now = stored.NextAttemptAt
+	if _, err := outbox.DispatchDue(context.Background(), now); err != nil {
+		t.Fatalf("second dispatch: %v", err)
+	}
+	stored = loadOutboxMessage(t, db, msg.ID)
+	if delay := stored.NextAttemptAt.Sub(now); stored.Attempts != 2 || delay < time.Minute || delay > 66*time.Second {
+		t.Fatalf("expected the backoff to double, got attempts=%d delay=%s", stored.Attempts, delay)
+	}
+
+	if sent, err := outbox.DispatchDue(context.Background(), stored.NextAttemptAt); err != nil || sent != 1 {
+		t.Fatalf("expected third attempt to deliver, got sent=%d err=%v", sent, err)
+	}
+	stored = loadOutboxMessage(t, db, msg.ID)
+	if stored.Status != domain.OutboxStatusSent || stored.Payload != "" || stored.SentAt == nil || stored.LastError != "" {
+		t.Fatalf("expected sent message with payload cleared, got %+v", stored)
+	}
+	if len(notifier.resets) != 1 || notifier.resets[0].Token != "raw-token" {
+		t.Fatalf("expected the queued notification to be delivered intact, got %+v", notifier.resets)
+	}
+}
+
+func TestNotificationOutboxSealsPayloads(t *testing.T) {
+	notifier := &flakyEmailNotifier{}
+	outbox, db := newNotificationOutboxForTest(t, notifier)
+	now := time.Now().UTC()
+	msg := enqueueForTest(t, db, OutboxKindPasswordReset, PasswordResetNotification{
+		Email: "user@example.com", Token: "raw-reset-token", PasswordURL: "https://app.example.com/reset?token=raw-reset-token", ExpiresAt: now.Add(time.Hour),
+	}, now.Add(time.Hour), now)
+
+	stored := loadOutboxMessage(t, db, msg.ID)
+	if stored.Payload == "" || strings.Contains(stored.Payload, "raw-reset-token") || strings.Contains(stored.Payload, "user@example.com") {
+		t.Fatalf("expected a sealed payload, got %q", stored.Payload)
+	}
+
+	// A dispatcher holding a different key cannot open it, so the message is
+	// dead-lettered instead of retried.
+	outbox.cfg.NotificationOutboxEncryptionKey = "another-outbox-key-0123456789abcdef"
+	if sent, err := outbox.DispatchDue(context.Background(), now); sent != 0 || err != nil {
+		t.Fatalf("expected nothing sent under the wrong key, got sent=%d err=%v", sent, err)
+	}
+	if stored := loadOutboxMessage(t, db, msg.ID); stored.Status != domain.OutboxStatusDead || !strings.Contains(stored.LastError, "open payload") {
+		t.Fatalf("expected message to be dead-lettered, got %+v", stored)
+	}
+
+	outbox.cfg.NotificationOutboxEncryptionKey = testOutboxEncryptionKey
+	if replayed, err := outbox.ReplayDead(context.Background(), now, 10); err != nil || replayed != 1 {
+		t.Fatalf("replay: %d %v", replayed, err)
+	}
+	if sent, err := outbox.DispatchDue(context.Background(), now); sent != 1 || err != nil {
+		t.Fatalf("expected delivery under the right key, got sent=%d err=%v", sent, err)
+	}
+	if len(notifier.resets) != 1 || notifier.resets[0].Token != "raw-reset-token" {
+		t.Fatalf("expected the dispatcher to deliver the opened notification, got %+v", notifier.resets)
+	}
+}
+
+func TestNotificationOutboxDeadLettersAndReplays(t *testing.T) {
+	notifier := &flakyEmailNotifier{failures: 3}
+	outbox, db := newNotificationOutboxForTest(t, notifier)
+	now := time.Now().UTC()
+	msg := enqueueForTest(t, db, OutboxKindMagicLink, MagicLinkNotification{Email: "user@example.com", Token: "raw-token"}, now.Add(24*time.Hour), now)
+	expired := enqueueForTest(t, db, OutboxKindMagicLink, MagicLinkNotification{Email: "late@example.com"}, now.Add(-time.Second), now)
+	unknown := &domain.OutboxMessage{Kind: "fax", Payload: "{}", Status: domain.OutboxStatusPending, NextAttemptAt: now}
+	if err := db.Create(unknown).Error; err != nil {
+		t.Fatalf("enqueue unknown: %v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		if _, err := outbox.DispatchDue(context.Background(), now); err != nil {
+			t.Fatalf("dispatch %d: %v", i, err)
+		}
+		now = now.Add(2 * time.Hour)
+	}
+	if stored := loadOutboxMessage(t, db, msg.ID); stored.Status != domain.OutboxStatusDead || stored.Attempts != 3 || stored.Payload == "" {
+		t.Fatalf("expected dead message with payload kept for replay, got %+v", stored)
+	}
+	if stored := loadOutboxMessage(t, db, expired.ID); stored.Status != domain.OutboxStatusDead || stored.Payload != "" || stored.LastError != "expired before delivery" {
+		t.Fatalf("expected expired message to be dropped, got %+v", stored)
+	}
+	if stored := loadOutboxMessage(t, db, unknown.ID); stored.Status != domain.OutboxStatusDead || stored.Attempts != 1 {
+		t.Fatalf("expected undeliverable message to be dead-lettered at once, got %+v", stored)
+	}
+
+	replayAt := time.Now().UTC()
+	if replayed, err := outbox.ReplayDead(context.Background(), replayAt, 10); err != nil || replayed != 2 {
+		t.Fatalf("expected the live and unknown messages to be replayed, got %d err=%v", replayed, err)
+	}
+	if err := outbox.Replay(context.Background(), expired.ID, replayAt); !errors.Is(err, repository.ErrOutboxNotReplayable) {
+		t.Fatalf("expected expired message to stay dead, got %v", err)
+	}
+	if sent, err := outbox.DispatchDue(context.Background(), replayAt); sent != 1 || err != nil {
+		t.Fatalf("expected replayed message to be delivered, got sent=%d err=%v", sent, err)
+	}
+	if len(notifier.magic) != 1 || notifier.magic[0].Token != "raw-token" {
+		t.Fatalf("expected delivery after replay, got %+v", notifier.magic)
+	}
+}
+
+func TestNotificationOutboxBackoffIsCapped(t *testing.T) {
+	outbox, _ := newNotificationOutboxForTest(t, &flakyEmailNotifier{})
+	for attempts, want := range map[int]time.Duration{1: 30 * time.Second, 4: 4 * time.Minute, 30: time.Hour} {
+		if got := outbox.backoff(attempts); got < want || got > want+want/10 {
+			t.Fatalf("attempt %d: expected ~%s, got %s", attempts, want, got)
+		}
+	}
+}
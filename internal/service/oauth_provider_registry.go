@@ -0,0 +1,71 @@
+package service
+
+import (
+	"errors"
+	"sort"
+	"strings"
+
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/config"
+)
+
+var (
+	ErrOAuthProviderNotFound    = errors.New("oauth provider is not enabled")
+	ErrOAuthProviderUnavailable = errors.New("oauth provider is unavailable")
+)
+
+type OAuthProviderRegistry struct {
+	providers map[string]OAuthProvider
+}
+
+func NewOAuthProviderRegistry(providers map[string]OAuthProvider) *OAuthProviderRegistry {
+	out := make(map[string]OAuthProvider, len(providers))
+	for name, provider := range providers {
+		name = normalizeOAuthProviderName(name)
+		if name == "" || provider == nil {
+			continue
+		}
+		out[name] = provider
+	}
+	return &OAuthProviderRegistry{providers: out}
+}
+
+func NewConfiguredOAuthProviderRegistry(cfg *config.Config) *OAuthProviderRegistry {
+	providers := make(map[string]OAuthProvider)
+	if cfg.AuthGoogleEnabled {
+		providers["google"] = NewGoogleOAuthProvider(cfg)
+	}
+	if cfg.AuthGitHubEnabled {
+		providers["github"] = NewGitHubOAuthProvider(cfg)
+	}
+	if cfg.AuthMicrosoftEnabled {
+		providers["microsoft"] = NewMicrosoftOAuthProvider(cfg)
+	}
+	for _, p := range cfg.OIDCProviders {
+		providers[p.Name] = NewOIDCOAuthProvider(p)
+	}
+	return NewOAuthProviderRegistry(providers)
+}
+
+func (r *OAuthProviderRegistry) Get(name string) (OAuthProvider, bool) {
+	if r == nil {
+		return nil, false
+	}
+	provider, ok := r.providers[normalizeOAuthProviderName(name)]
+	return provider, ok
+}
+
+func (r *OAuthProviderRegistry) Names() []string {
+	if r == nil {
+		return nil
+	}
+	names := make([]string, 0, len(r.providers))
+	for name := range r.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func normalizeOAuthProviderName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/config"
+)
+
+func TestOAuthProviderRegistryNormalizesNames(t *testing.T) {
+	reg := NewOAuthProviderRegistry(map[string]OAuthProvider{
+		" GitHub ": testOAuthProvider{},
+		"okta":     testOAuthProvider{},
+		"":         testOAuthProvider{},
+		"nil":      nil,
+	})
+
+	if _, ok := reg.Get("github"); !ok {
+		t.Fatal("expected github provider lookup to be case-insensitive")
+	}
+	if _, ok := reg.Get("nil"); ok {
+		t.Fatal("expected nil provider to be skipped")
+	}
+	if got := reg.Names(); !reflect.DeepEqual(got, []string{"github", "okta"}) {
+		t.Fatalf("unexpected provider names: %v", got)
+	}
+
+	var nilReg *OAuthProviderRegistry
+	if _, ok := nilReg.Get("github"); ok {
+		t.Fatal("expected nil registry lookup to miss")
+	}
+}
+
+func TestNewConfiguredOAuthProviderRegistry(t *testing.T) {
+	reg := NewConfiguredOAuthProviderRegistry(&config.Config{
+		AuthGoogleEnabled:    false,
+		AuthGitHubEnabled:    true,
+		AuthMicrosoftEnabled: true,
+		MicrosoftTenant:      "common",
+		OIDCProviders: []config.OIDCProviderConfig{
+			{Name: "okta", DiscoveryURL: "https://okta.example/.well-known/openid-configuration", ClientID: "c", ClientSecret: "s"},
+		},
+	})
+	if got := reg.Names(); !reflect.DeepEqual(got, []string{"github", "microsoft", "okta"}) {
+		t.Fatalf("unexpected configured providers: %v", got)
+	}
+	if _, ok := reg.Get("google"); ok {
+		t.Fatal("expected disabled google provider to be absent")
+	}
+}
+
+func TestOAuthServiceUnknownAndUnavailableProviders(t *testing.T) {
+	svc := NewOAuthService(nil, NewOAuthProviderRegistry(map[string]OAuthProvider{"okta": testOAuthProvider{}}), nil, nil, nil)
+
+	if _, err := svc.LoginURL(testOAuthFlow("gitlab")); !errors.Is(err, ErrOAuthProviderNotFound) {
+		t.Fatalf("expected ErrOAuthProviderNotFound, got %v", err)
+	}
+	if _, err := svc.HandleCallback(context.Background(), testOAuthFlow("gitlab"), "code"); !errors.Is(err, ErrOAuthProviderNotFound) {
+		t.Fatalf("expected ErrOAuthProviderNotFound on callback, got %v", err)
+	}
+	// testOAuthProvider returns an empty auth URL, which mirrors failed OIDC discovery.
+	if _, err := svc.LoginURL(testOAuthFlow("okta")); !errors.Is(err, ErrOAuthProviderUnavailable) {
+		t.Fatalf("expected ErrOAuthProviderUnavailable, got %v", err)
+	}
+}
@@ -0,0 +1,360 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/config"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/github"
+)
+
+const oidcDiscoveryTimeout = 5 * time.Second
+
+type GitHubOAuthProvider struct {
+	cfg     *oauth2.Config
+	apiBase string
+}
+
+func NewGitHubOAuthProvider(cfg *config.Config) *GitHubOAuthProvider {
+	return &GitHubOAuthProvider{
+		cfg: &oauth2.Config{
+			ClientID:     cfg.GitHubClientID,
+			ClientSecret: cfg.GitHubClientSecret,
+			RedirectURL:  cfg.GitHubRedirectURL,
+			Scopes:       []string{"read:user", "user:email"},
+			Endpoint:     github.Endpoint,
+		},
+		apiBase: "https://api.github.com",
+	}
+}
+
+func (p *GitHubOAuthProvider) AuthCodeURL(state string, opts ...oauth2.AuthCodeOption) string {
+	return p.cfg.AuthCodeURL(state, opts...)
+}
+
+func (p *GitHubOAuthProvider) Exchange(ctx context.Context, code string, opts ...oauth2.AuthCodeOption) (*oauth2.Token, error) {
+	return p.cfg.Exchange(ctx, code, opts...)
+}
+
+func (p *GitHubOAuthProvider) FetchUserInfo(ctx context.Context, token *oauth2.Token) (*OAuthUserInfo, error) {
+	client := p.cfg.Client(ctx, token)
+	var profile struct {
+		ID        int64  `json:"id"`
+		Login     string `json:"login"`
+		Name      string `json:"name"`
+		Email     string `json:"email"`
+		AvatarURL string `json:"avatar_url"`
+	}
+	if err := getJSON(ctx, client, p.apiBase+"/user", &profile); err != nil {
+		return nil, err
+	}
+	var emails []struct {
+		Email    string `json:"email"`
+		Primary  bool   `json:"primary"`
+		Verified bool   `json:"verified"`
+	}
+	if err := getJSON(ctx, client, p.apiBase+"/user/emails", &emails); err != nil {
+		return nil, err
+	}
+
+	info := &OAuthUserInfo{
+		ProviderUserID: strconv.FormatInt(profile.ID, 10),
+		Email:          strings.ToLower(strings.TrimSpace(profile.Email)),
+		Name:           profile.Name,
+		Picture:        profile.AvatarURL,
+	}
+	for _, e := range emails {
+		if e.Primary && e.Verified {
+			info.Email = strings.ToLower(strings.TrimSpace(e.Email))
+			info.EmailVerified = true
+			break
+		}
+	}
+	if info.Name == "" {
+		info.Name = profile.Login
+	}
+	if profile.ID == 0 || info.Email == "" {
+		return nil, fmt.Errorf("missing required userinfo fields")
+	}
+	return info, nil
+}
+
+type oidcDiscoveryDocument struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	UserInfoEndpoint      string `json:"userinfo_endpoint"`
+}
+
+// OIDCOAuthProvider resolves its endpoints from the issuer discovery document
+// on first use and caches them for the lifetime of the process.
+type OIDCOAuthProvider struct {
+	name         string
+	discoveryURL string
+	clientID     string
+	clientSecret string
+	redirectURL  string
+	scopes       []string
+	httpClient   *http.Client
+	now          func() time.Time
+
+	mu        sync.Mutex
+	oauthCfg  *oauth2.Config
+	discovery *oidcDiscoveryDocument
+}
+
+func NewOIDCOAuthProvider(cfg config.OIDCProviderConfig) *OIDCOAuthProvider {
+	scopes := cfg.Scopes
+	if len(scopes) == 0 {
+		scopes = []string{"openid", "email", "profile"}
+	}
+	return &OIDCOAuthProvider{
+		name:         cfg.Name,
+		discoveryURL: cfg.DiscoveryURL,
+		clientID:     cfg.ClientID,
+		clientSecret: cfg.ClientSecret,
+		redirectURL:  cfg.RedirectURL,
+		scopes:       scopes,
+		httpClient:   &http.Client{Timeout: oidcDiscoveryTimeout},
+		now:          time.Now,
+	}
+}
+
+func NewMicrosoftOAuthProvider(cfg *config.Config) *OIDCOAuthProvider {
+	return NewOIDCOAuthProvider(config.OIDCProviderConfig{
+		Name:         "microsoft",
+		DiscoveryURL: "https://login.microsoftonline.com/" + url.PathEscape(cfg.MicrosoftTenant) + "/v2.0/.well-known/openid-configuration",
+		ClientID:     cfg.MicrosoftClientID,
+		ClientSecret: cfg.MicrosoftClientSecret,
+		RedirectURL:  cfg.MicrosoftRedirectURL,
+		Scopes:       []string{"openid", "email", "profile"},
+	})
+}
+
+func (p *OIDCOAuthProvider) AuthCodeURL(state string, opts ...oauth2.AuthCodeOption) string {
+	ctx, cancel := context.WithTimeout(context.Background(), oidcDiscoveryTimeout)
+	defer cancel()
+	cfg, _, err := p.discover(ctx)
+	if err != nil {
+		return ""
+	}
+	return cfg.AuthCodeURL(state, opts...)
+}
+
+func (p *OIDCOAuthProvider) Exchange(ctx context.Context, code string, opts ...oauth2.AuthCodeOption) (*oauth2.Token, error) {
+	cfg, _, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return cfg.Exchange(ctx, code, opts...)
+}
+
+func (p *OIDCOAuthProvider) FetchUserInfo(ctx context.Context, token *oauth2.Token) (*OAuthUserInfo, error) {
+	cfg, doc, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	claims, err := p.idTokenClaims(token, doc)
+	if err != nil {
+		return nil, err
+	}
+	if doc.UserInfoEndpoint != "" && (claims.Email == "" || claims.Name == "") {
+		var body oidcUserClaims
+		if err := getJSON(ctx, cfg.Client(ctx, token), doc.UserInfoEndpoint, &body); err != nil {
+			return nil, err
+		}
+		if claims.Sub != "" && body.Sub != claims.Sub {
+			return nil, fmt.Errorf("invalid id_token: userinfo subject mismatch")
+		}
+		claims.merge(body)
+	}
+	if claims.Sub == "" || claims.Email == "" {
+		return nil, fmt.Errorf("missing required userinfo fields")
+	}
+	return &OAuthUserInfo{
+		ProviderUserID: claims.Sub,
+		Email:          strings.ToLower(strings.TrimSpace(claims.Email)),
+		Name:           claims.Name,
+		Picture:        claims.Picture,
+		EmailVerified:  bool(claims.EmailVerified) || bool(claims.EmailDomainOwnerVerified),
+	}, nil
+}
+
+func (p *OIDCOAuthProvider) discover(ctx context.Context) (*oauth2.Config, *oidcDiscoveryDocument, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.oauthCfg != nil {
+		return p.oauthCfg, p.discovery, nil
+	}
+
+	var doc oidcDiscoveryDocument
+	if err := getJSON(ctx, p.httpClient, p.discoveryURL, &doc); err != nil {
+		return nil, nil, fmt.Errorf("oidc discovery %s: %w", p.name, err)
+	}
+	if doc.Issuer == "" || doc.AuthorizationEndpoint == "" || doc.TokenEndpoint == "" {
+		return nil, nil, fmt.Errorf("oidc discovery %s: incomplete discovery document", p.name)
+	}
+	p.discovery = &doc
+	p.oauthCfg = &oauth2.Config{
+		ClientID:     p.clientID,
+		ClientSecret: p.clientSecret,
+		RedirectURL:  p.redirectURL,
+		Scopes:       p.scopes,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:  doc.AuthorizationEndpoint,
+			TokenURL: doc.TokenEndpoint,
+		},
+	}
+	return p.oauthCfg, p.discovery, nil
+}
+
+// idTokenClaims reads the ID token returned by the token endpoint. The token is
+// received directly from the issuer over TLS, so per OIDC Core 3.1.3.7 the
+// issuer, audience and expiry are checked here instead of the signature.
+func (p *OIDCOAuthProvider) idTokenClaims(token *oauth2.Token, doc *oidcDiscoveryDocument) (*oidcUserClaims, error) {
+	claims, err := decodeIDTokenClaims(token)
+	if err != nil {
+		return nil, err
+	}
+	if claims == nil {
+		return &oidcUserClaims{}, nil
+	}
+
+	issuer := doc.Issuer
+	if claims.TenantID != "" {
+		issuer = strings.ReplaceAll(issuer, "{tenantid}", claims.TenantID)
+	}
+	if claims.Issuer != issuer {
+		return nil, fmt.Errorf("invalid id_token: issuer mismatch")
+	}
+	if !claims.Audience.contains(p.clientID) {
+		return nil, fmt.Errorf("invalid id_token: audience mismatch")
+	}
+	if claims.ExpiresAt == 0 || p.now().Unix() >= claims.ExpiresAt {
+		return nil, fmt.Errorf("invalid id_token: expired")
+	}
+	return claims, nil
+}
+
+// decodeIDTokenClaims returns nil claims when the token response carries no
+// id_token, as with plain OAuth2 providers.
+func decodeIDTokenClaims(token *oauth2.Token) (*oidcUserClaims, error) {
+	if token == nil {
+		return nil, nil
+	}
+	raw, _ := token.Extra("id_token").(string)
+	if raw == "" {
+		return nil, nil
+	}
+	parts := strings.Split(raw, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("invalid id_token: malformed")
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("invalid id_token: %w", err)
+	}
+	var claims oidcUserClaims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, fmt.Errorf("invalid id_token: %w", err)
+	}
+	return &claims, nil
+}
+
+type oidcUserClaims struct {
+	Issuer                   string           `json:"iss"`
+	Sub                      string           `json:"sub"`
+	Audience                 oidcAudience     `json:"aud"`
+	ExpiresAt                int64            `json:"exp"`
+	TenantID                 string           `json:"tid"`
+	Nonce                    string           `json:"nonce"`
+	Email                    string           `json:"email"`
+	EmailVerified            oidcFlexibleBool `json:"email_verified"`
+	EmailDomainOwnerVerified oidcFlexibleBool `json:"xms_edov"`
+	Name                     string           `json:"name"`
+	Picture                  string           `json:"picture"`
+}
+
+func (c *oidcUserClaims) merge(other oidcUserClaims) {
+	if c.Sub == "" {
+		c.Sub = other.Sub
+	}
+	if c.Email == "" {
+		c.Email = other.Email
+		c.EmailVerified = other.EmailVerified
+	}
+	if c.Name == "" {
+		c.Name = other.Name
+	}
+	if c.Picture == "" {
+		c.Picture = other.Picture
+	}
+}
+
+type oidcAudience []string
+
+func (a *oidcAudience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = oidcAudience{single}
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(data, &many); err != nil {
+		return err
+	}
+	*a = many
+	return nil
+}
+
+func (a oidcAudience) contains(v string) bool {
+	for _, aud := range a {
+		if aud == v {
+			return true
+		}
+	}
+	return false
+}
+
+// oidcFlexibleBool accepts both JSON booleans and the "true"/"false" strings
+// some issuers emit for email_verified.
+type oidcFlexibleBool bool
+
+func (b *oidcFlexibleBool) UnmarshalJSON(data []byte) error {
+	s := strings.Trim(string(data), `"`)
+	v, err := strconv.ParseBool(s)
+	if err != nil {
+		*b = false
+		return nil
+	}
+	*b = oidcFlexibleBool(v)
+	return nil
+}
+
+func getJSON(ctx context.Context, client *http.Client, endpoint string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("userinfo status: %d", resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,267 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/config"
+
+	"golang.org/x/oauth2"
+)
+
+func TestGitHubOAuthProviderFetchUserInfoUsesPrimaryVerifiedEmail(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer gh-token" {
+			t.Errorf("unexpected authorization header %q", got)
+		}
+		switch r.URL.Path {
+		case "/user":
+			_, _ = w.Write([]byte(`{"id":42,"login":"octo","name":"","email":null,"avatar_url":"https://avatars.example/42"}`))
+		case "/user/emails":
+			_, _ = w.Write([]byte(`[{"email":"old@example.com","primary":false,"verified":true},{"email":"Octo@Example.com","primary":true,"verified":true}]`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	p := NewGitHubOAuthProvider(&config.Config{GitHubClientID: "id", GitHubClientSecret: "secret"})
+	p.apiBase = srv.URL
+
+	info, err := p.FetchUserInfo(context.Background(), &oauth2.Token{AccessToken: "gh-token"})
+	if err != nil {
+		t.Fatalf("fetch user info: %v", err)
+	}
+	if info.ProviderUserID != "42" || info.Email != "octo@example.com" || !info.EmailVerified {
+		t.Fatalf("unexpected user info: %+v", info)
+	}
+	if info.Name != "octo" {
+		t.Fatalf("expected login fallback for empty name, got %q", info.Name)
+	}
+}
+
+func TestGitHubOAuthProviderUnverifiedEmail(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/user":
+			_, _ = w.Write([]byte(`{"id":7,"login":"octo","email":"octo@example.com"}`))
+		case "/user/emails":
+			_, _ = w.Write([]byte(`[{"email":"octo@example.com","primary":true,"verified":false}]`))
+		}
+	}))
+	defer srv.Close()
+
+	p := NewGitHubOAuthProvider(&config.Config{})
+	p.apiBase = srv.URL
+
+	info, err := p.FetchUserInfo(context.Background(), &oauth2.Token{AccessToken: "gh-token"})
+	if err != nil {
+		t.Fatalf("fetch user info: %v", err)
+	}
+	if info.EmailVerified {
+		t.Fatal("expected unverified github email")
+	}
+}
+
+type oidcTestIssuer struct {
+	srv          *httptest.Server
+	issuer       string
+	idClaims     map[string]any
+	userinfo     map[string]any
+	codeVerifier string
+}
+
+func newOIDCTestIssuer(t *testing.T) *oidcTestIssuer {
+	t.Helper()
+	iss := &oidcTestIssuer{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 iss.issuer,
+			"authorization_endpoint": iss.srv.URL + "/authorize",
+			"token_endpoint":         iss.srv.URL + "/token",
+			"userinfo_endpoint":      iss.srv.URL + "/userinfo",
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil || r.PostForm.Get("code") != "good-code" {
+			http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+			return
+		}
+		iss.codeVerifier = r.PostForm.Get("code_verifier")
+		payload, _ := json.Marshal(iss.idClaims)
+		idToken := "e30." + base64.RawURLEncoding.EncodeToString(payload) + ".sig"
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"access_token": "oidc-access",
+			"token_type":   "Bearer",
+			"expires_in":   3600,
+			"id_token":     idToken,
+		})
+	})
+	mux.HandleFunc("/userinfo", func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(iss.userinfo)
+	})
+	iss.srv = httptest.NewServer(mux)
+	t.Cleanup(iss.srv.Close)
+	iss.issuer = iss.srv.URL
+	iss.idClaims = map[string]any{
+		"iss": iss.issuer,
+		"sub": "oidc-sub-1",
+		"aud": "client-1",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}
+	iss.userinfo = map[string]any{
+		"sub":            "oidc-sub-1",
+		"email":          "OIDC@example.com",
+		"email_verified": "true",
+		"name":           "OIDC User",
+	}
+	return iss
+}
+
+func (iss *oidcTestIssuer) provider() *OIDCOAuthProvider {
+	return NewOIDCOAuthProvider(config.OIDCProviderConfig{
+		Name:         "okta",
+		DiscoveryURL: iss.srv.URL + "/.well-known/openid-configuration",
+		ClientID:     "client-1",
+		ClientSecret: "secret-1",
+		RedirectURL:  "http://localhost:8080/api/v1/auth/okta/callback",
+	})
+}
+
+func TestOIDCOAuthProviderDiscoveryExchangeAndUserInfo(t *testing.T) {
+	iss := newOIDCTestIssuer(t)
+	p := iss.provider()
+
+	loginURL, err := url.Parse(p.AuthCodeURL("state-1"))
+	if err != nil {
+		t.Fatalf("parse auth code url: %v", err)
+	}
+	if loginURL.Path != "/authorize" || loginURL.Query().Get("state") != "state-1" || loginURL.Query().Get("client_id") != "client-1" {
+		t.Fatalf("unexpected auth code url: %s", loginURL)
+	}
+	if !strings.Contains(loginURL.Query().Get("scope"), "openid") {
+		t.Fatalf("expected default openid scope, got %q", loginURL.Query().Get("scope"))
+	}
+
+	token, err := p.Exchange(context.Background(), "good-code")
+	if err != nil {
+		t.Fatalf("exchange: %v", err)
+	}
+	info, err := p.FetchUserInfo(context.Background(), token)
+	if err != nil {
+		t.Fatalf("fetch user info: %v", err)
+	}
+	if info.ProviderUserID != "oidc-sub-1" || info.Email != "oidc@example.com" || !info.EmailVerified || info.Name != "OIDC User" {
+		t.Fatalf("unexpected user info: %+v", info)
+	}
+}
+
+func TestOIDCOAuthProviderRejectsInvalidIDTokenClaims(t *testing.T) {
+	cases := []struct {
+		name   string
+		mutate func(claims map[string]any)
+	}{
+		{name: "issuer mismatch", mutate: func(c map[string]any) { c["iss"] = "https://evil.example" }},
+		{name: "audience mismatch", mutate: func(c map[string]any) { c["aud"] = []string{"other-client"} }},
+		{name: "expired", mutate: func(c map[string]any) { c["exp"] = time.Now().Add(-time.Minute).Unix() }},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			iss := newOIDCTestIssuer(t)
+			tc.mutate(iss.idClaims)
+			p := iss.provider()
+
+			token, err := p.Exchange(context.Background(), "good-code")
+			if err != nil {
+				t.Fatalf("exchange: %v", err)
+			}
+			_, err = p.FetchUserInfo(context.Background(), token)
+			if err == nil || classifyOAuthError(err) != "invalid_id_token" {
+				t.Fatalf("expected invalid_id_token error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestOIDCOAuthProviderIDTokenClaimsSkipUserInfo(t *testing.T) {
+	iss := newOIDCTestIssuer(t)
+	iss.issuer = "https://login.example/{tenantid}/v2.0"
+	iss.idClaims["iss"] = "https://login.example/tenant-a/v2.0"
+	iss.idClaims["tid"] = "tenant-a"
+	iss.idClaims["email"] = "ms@example.com"
+	iss.idClaims["name"] = "MS User"
+	iss.idClaims["xms_edov"] = true
+	iss.userinfo = map[string]any{"sub": "someone-else"}
+	p := iss.provider()
+
+	token, err := p.Exchange(context.Background(), "good-code")
+	if err != nil {
+		t.Fatalf("exchange: %v", err)
+	}
+	info, err := p.FetchUserInfo(context.Background(), token)
+	if err != nil {
+		t.Fatalf("fetch user info: %v", err)
+	}
+	if info.Email != "ms@example.com" || !info.EmailVerified {
+		t.Fatalf("expected verified email from id_token claims, got %+v", info)
+	}
+}
+
+func TestOIDCOAuthProviderDiscoveryFailure(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	p := NewOIDCOAuthProvider(config.OIDCProviderConfig{Name: "okta", DiscoveryURL: srv.URL, ClientID: "c"})
+	if got := p.AuthCodeURL("state"); got != "" {
+		t.Fatalf("expected empty auth code url on discovery failure, got %q", got)
+	}
+	_, err := p.Exchange(context.Background(), "code")
+	if classifyOAuthError(err) != "discovery" {
+		t.Fatalf("expected discovery error class, got %v", err)
+	}
+}
+
+func TestOAuthServiceOIDCFlowSendsPKCEAndNonce(t *testing.T) {
+	iss := newOIDCTestIssuer(t)
+	flow := testOAuthFlow("okta")
+	iss.idClaims["nonce"] = flow.Nonce
+	svc := NewOAuthService(nil, NewOAuthProviderRegistry(map[string]OAuthProvider{"okta": iss.provider()}), nil, nil, nil)
+
+	rawURL, err := svc.LoginURL(flow)
+	if err != nil {
+		t.Fatalf("login url: %v", err)
+	}
+	loginURL, err := url.Parse(rawURL)
+	if err != nil {
+		t.Fatalf("parse login url: %v", err)
+	}
+	q := loginURL.Query()
+	if q.Get("code_challenge") != oauth2.S256ChallengeFromVerifier(flow.CodeVerifier) || q.Get("code_challenge_method") != "S256" {
+		t.Fatalf("expected S256 code challenge in login url, got %s", loginURL)
+	}
+	if q.Get("nonce") != flow.Nonce {
+		t.Fatalf("expected nonce in login url, got %q", q.Get("nonce"))
+	}
+
+	token, err := iss.provider().Exchange(context.Background(), "good-code", oauth2.VerifierOption(flow.CodeVerifier))
+	if err != nil {
+		t.Fatalf("exchange: %v", err)
+	}
+	if iss.codeVerifier != flow.CodeVerifier {
+		t.Fatalf("expected code_verifier %q at token endpoint, got %q", flow.CodeVerifier, iss.codeVerifier)
+	}
+	if err := verifyIDTokenNonce(token, flow.Nonce); err != nil {
+		t.Fatalf("expected matching nonce, got %v", err)
+	}
+}
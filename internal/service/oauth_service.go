@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"crypto/subtle"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -14,6 +15,7 @@ import (
 	"github.com/sandeepkv93/everything-backend-starter-kit/internal/domain"
 	"github.com/sandeepkv93/everything-backend-starter-kit/internal/observability"
 	"github.com/sandeepkv93/everything-backend-starter-kit/internal/repository"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/security"
 
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/google"
@@ -29,8 +31,8 @@ type OAuthUserInfo struct {
 }
 
 type OAuthProvider interface {
-	AuthCodeURL(state string) string
-	Exchange(ctx context.Context, code string) (*oauth2.Token, error)
+	AuthCodeURL(state string, opts ...oauth2.AuthCodeOption) string
+	Exchange(ctx context.Context, code string, opts ...oauth2.AuthCodeOption) (*oauth2.Token, error)
 	FetchUserInfo(ctx context.Context, token *oauth2.Token) (*OAuthUserInfo, error)
 }
 
@@ -48,12 +50,13 @@ func NewGoogleOAuthProvider(cfg *config.Config) *GoogleOAuthProvider {
 	}}
 }
 
-func (p *GoogleOAuthProvider) AuthCodeURL(state string) string {
-	return p.cfg.AuthCodeURL(state, oauth2.AccessTypeOffline, oauth2.SetAuthURLParam("prompt", "consent"))
+func (p *GoogleOAuthProvider) AuthCodeURL(state string, opts ...oauth2.AuthCodeOption) string {
+	opts = append([]oauth2.AuthCodeOption{oauth2.AccessTypeOffline, oauth2.SetAuthURLParam("prompt", "consent")}, opts...)
+	return p.cfg.AuthCodeURL(state, opts...)
 }
 
-func (p *GoogleOAuthProvider) Exchange(ctx context.Context, code string) (*oauth2.Token, error) {
-	return p.cfg.Exchange(ctx, code)
+func (p *GoogleOAuthProvider) Exchange(ctx context.Context, code string, opts ...oauth2.AuthCodeOption) (*oauth2.Token, error) {
+	return p.cfg.Exchange(ctx, code, opts...)
 }
 
 func (p *GoogleOAuthProvider) FetchUserInfo(ctx context.Context, token *oauth2.Token) (*OAuthUserInfo, error) {
@@ -86,51 +89,65 @@ func (p *GoogleOAuthProvider) FetchUserInfo(ctx context.Context, token *oauth2.T
 	return &OAuthUserInfo{ProviderUserID: body.Sub, Email: strings.ToLower(body.Email), Name: body.Name, Picture: body.Picture, EmailVerified: body.EmailVerified}, nil
 }
 
+var (
+	ErrOAuthFlowStateInvalid = errors.New("oauth flow state is incomplete")
+	ErrOAuthNonceMissing     = errors.New("oauth id_token nonce missing")
+	ErrOAuthNonceMismatch    = errors.New("oauth id_token nonce mismatch")
+	// ErrOAuthLinkRequired is returned when auto-linking by email is off and
+	// the provider email belongs to an existing account.
+	ErrOAuthLinkRequired            = errors.New("an account with this email already exists; sign in and link this provider")
+	ErrOAuthIdentityLinkedElsewhere = errors.New("oauth identity is linked to another account")
+	ErrOAuthIdentityMismatch        = errors.New("oauth identity does not belong to this account")
+)
+
 type OAuthService struct {
-	provider  OAuthProvider
+	cfg       *config.Config
+	providers *OAuthProviderRegistry
 	userRepo  repository.UserRepository
 	oauthRepo repository.OAuthRepository
 	roleRepo  repository.RoleRepository
 }
 
-func NewOAuthService(provider OAuthProvider, userRepo repository.UserRepository, oauthRepo repository.OAuthRepository, roleRepo repository.RoleRepository) *OAuthService {
-	return &OAuthService{provider: provider, userRepo: userRepo, oauthRepo: oauthRepo, roleRepo: roleRepo}
+func NewOAuthService(cfg *config.Config, providers *OAuthProviderRegistry, userRepo repository.UserRepository, oauthRepo repository.OAuthRepository, roleRepo repository.RoleRepository) *OAuthService {
+	return &OAuthService{cfg: cfg, providers: providers, userRepo: userRepo, oauthRepo: oauthRepo, roleRepo: roleRepo}
 }
 
-func (s *OAuthService) LoginURL(state string) string {
-	return s.provider.AuthCodeURL(state)
+func (s *OAuthService) ProviderEnabled(providerName string) bool {
+	_, ok := s.providers.Get(providerName)
+	return ok
 }
 
-func (s *OAuthService) HandleGoogleCallback(ctx context.Context, code string) (*domain.User, error) {
-	if ctx == nil {
-		ctx = context.Background()
+// LoginURL binds the authorization request to the flow's PKCE verifier and
+// nonce so the callback can only be completed by the browser holding them.
+func (s *OAuthService) LoginURL(flow security.OAuthFlowState) (string, error) {
+	providerName := normalizeOAuthProviderName(flow.Provider)
+	provider, ok := s.providers.Get(providerName)
+	if !ok {
+		return "", ErrOAuthProviderNotFound
 	}
-	exchangeStart := time.Now()
-	token, err := s.provider.Exchange(ctx, code)
-	observability.RecordGoogleOAuthRequestDuration(ctx, "exchange", oauthStatus(err), time.Since(exchangeStart))
-	if err != nil {
-		observability.RecordGoogleOAuthError(ctx, classifyOAuthError(err))
-		return nil, err
+	if flow.State == "" || flow.CodeVerifier == "" || flow.Nonce == "" {
+		return "", ErrOAuthFlowStateInvalid
 	}
-	userInfoStart := time.Now()
-	info, err := s.provider.FetchUserInfo(ctx, token)
-	observability.RecordGoogleOAuthRequestDuration(ctx, "userinfo", oauthStatus(err), time.Since(userInfoStart))
-	if err != nil {
-		observability.RecordGoogleOAuthError(ctx, classifyOAuthError(err))
-		return nil, err
-	}
-	if info == nil {
-		observability.RecordGoogleOAuthError(ctx, "invalid_userinfo")
-		return nil, fmt.Errorf("missing required userinfo fields")
+	loginURL := provider.AuthCodeURL(
+		flow.State,
+		oauth2.S256ChallengeOption(flow.CodeVerifier),
+		oauth2.SetAuthURLParam("nonce", flow.Nonce),
+	)
+	if loginURL == "" {
+		observability.RecordOAuthError(context.Background(), providerName, "discovery")
+		return "", ErrOAuthProviderUnavailable
 	}
+	return loginURL, nil
+}
 
-	if !info.EmailVerified {
-		observability.RecordGoogleOAuthError(ctx, "email_not_verified")
-		return nil, fmt.Errorf("google email not verified")
+func (s *OAuthService) HandleCallback(ctx context.Context, flow security.OAuthFlowState, code string) (*domain.User, error) {
+	providerName, info, err := s.fetchVerifiedIdentity(ctx, flow, code)
+	if err != nil {
+		return nil, err
 	}
 
 	var user *domain.User
-	acct, err := s.oauthRepo.FindByProvider("google", info.ProviderUserID)
+	acct, err := s.oauthRepo.FindByProvider(providerName, info.ProviderUserID)
 	switch err {
 	case nil:
 		user, err = s.userRepo.FindByID(acct.UserID)
@@ -141,9 +158,13 @@ func (s *OAuthService) HandleGoogleCallback(ctx context.Context, code string) (*
 		u, findErr := s.userRepo.FindByEmail(info.Email)
 		switch findErr {
 		case nil:
+			if !s.cfg.AuthOAuthAutoLinkByEmail {
+				observability.RecordOAuthError(ctx, providerName, "link_required")
+				return nil, ErrOAuthLinkRequired
+			}
 			user = u
 		case gorm.ErrRecordNotFound:
-			user = &domain.User{Email: info.Email, Name: info.Name, AvatarURL: info.Picture, Status: "active"}
+			user = &domain.User{Email: info.Email, Name: info.Name, AvatarURL: info.Picture, Status: domain.UserStatusActive}
 			if err := s.userRepo.Create(user); err != nil {
 				return nil, err
 			}
@@ -154,7 +175,7 @@ func (s *OAuthService) HandleGoogleCallback(ctx context.Context, code string) (*
 		default:
 			return nil, findErr
 		}
-		if err := s.oauthRepo.Create(&domain.OAuthAccount{UserID: user.ID, Provider: "google", ProviderUserID: info.ProviderUserID, EmailVerified: true}); err != nil {
+		if err := s.oauthRepo.Create(&domain.OAuthAccount{UserID: user.ID, Provider: providerName, ProviderUserID: info.ProviderUserID, EmailVerified: true}); err != nil {
 			return nil, err
 		}
 	default:
@@ -169,6 +190,132 @@ func (s *OAuthService) HandleGoogleCallback(ctx context.Context, code string) (*
 	return s.userRepo.FindByID(user.ID)
 }
 
+// LinkIdentity completes a flow started by an authenticated user and attaches
+// the provider identity to flow.LinkUserID regardless of the provider email.
+// Linking an identity the user already owns is a no-op.
+func (s *OAuthService) LinkIdentity(ctx context.Context, flow security.OAuthFlowState, code string) (*domain.OAuthAccount, error) {
+	if flow.LinkUserID == 0 {
+		return nil, ErrOAuthFlowStateInvalid
+	}
+	providerName, info, err := s.fetchVerifiedIdentity(ctx, flow, code)
+	if err != nil {
+		return nil, err
+	}
+	acct, err := s.oauthRepo.FindByProvider(providerName, info.ProviderUserID)
+	switch err {
+	case nil:
+		if acct.UserID != flow.LinkUserID {
+			return nil, ErrOAuthIdentityLinkedElsewhere
+		}
+		return acct, nil
+	case gorm.ErrRecordNotFound:
+	default:
+		return nil, err
+	}
+	acct = &domain.OAuthAccount{UserID: flow.LinkUserID, Provider: providerName, ProviderUserID: info.ProviderUserID, EmailVerified: true}
+	if err := s.oauthRepo.Create(acct); err != nil {
+		return nil, err
+	}
+	return acct, nil
+}
+
+// VerifyIdentity completes a reauthentication flow: the provider identity
+// must already be linked to flow.ReauthUserID.
+func (s *OAuthService) VerifyIdentity(ctx context.Context, flow security.OAuthFlowState, code string) error {
+	if flow.ReauthUserID == 0 {
+		return ErrOAuthFlowStateInvalid
+	}
+	providerName, info, err := s.fetchVerifiedIdentity(ctx, flow, code)
+	if err != nil {
+		return err
+	}
+	acct, err := s.oauthRepo.FindByProvider(providerName, info.ProviderUserID)
+	switch {
+	case err == nil:
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return ErrOAuthIdentityMismatch
+	default:
+		return err
+	}
+	if acct.UserID != flow.ReauthUserID {
+		return ErrOAuthIdentityMismatch
+	}
+	return nil
+}
+
+func (s *OAuthService) ListIdentities(userID uint) ([]domain.OAuthAccount, error) {
+	return s.oauthRepo.ListByUserID(userID)
+}
+
+func (s *OAuthService) UnlinkIdentity(userID, identityID uint) error {
+	if err := s.oauthRepo.DeleteForUser(identityID, userID); err != nil {
+		if errors.Is(err, repository.ErrOAuthAccountNotFound) {
+			return ErrIdentityNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *OAuthService) fetchVerifiedIdentity(ctx context.Context, flow security.OAuthFlowState, code string) (string, *OAuthUserInfo, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	providerName := normalizeOAuthProviderName(flow.Provider)
+	provider, ok := s.providers.Get(providerName)
+	if !ok {
+		return "", nil, ErrOAuthProviderNotFound
+	}
+	if flow.CodeVerifier == "" {
+		observability.RecordOAuthError(ctx, providerName, "pkce_verifier_missing")
+		return "", nil, ErrOAuthFlowStateInvalid
+	}
+	exchangeStart := time.Now()
+	token, err := provider.Exchange(ctx, code, oauth2.VerifierOption(flow.CodeVerifier))
+	observability.RecordOAuthRequestDuration(ctx, providerName, "exchange", oauthStatus(err), time.Since(exchangeStart))
+	if err != nil {
+		observability.RecordOAuthError(ctx, providerName, classifyOAuthError(err))
+		return "", nil, err
+	}
+	if err := verifyIDTokenNonce(token, flow.Nonce); err != nil {
+		observability.RecordOAuthError(ctx, providerName, classifyOAuthError(err))
+		return "", nil, err
+	}
+	userInfoStart := time.Now()
+	info, err := provider.FetchUserInfo(ctx, token)
+	observability.RecordOAuthRequestDuration(ctx, providerName, "userinfo", oauthStatus(err), time.Since(userInfoStart))
+	if err != nil {
+		observability.RecordOAuthError(ctx, providerName, classifyOAuthError(err))
+		return "", nil, err
+	}
+	if info == nil {
+		observability.RecordOAuthError(ctx, providerName, "invalid_userinfo")
+		return "", nil, fmt.Errorf("missing required userinfo fields")
+	}
+
+	if !info.EmailVerified {
+		observability.RecordOAuthError(ctx, providerName, "email_not_verified")
+		return "", nil, fmt.Errorf("%s email not verified", providerName)
+	}
+	return providerName, info, nil
+}
+
+// verifyIDTokenNonce only applies to OIDC providers; plain OAuth2 token
+// responses without an id_token are accepted as-is.
+func verifyIDTokenNonce(token *oauth2.Token, nonce string) error {
+	claims, err := decodeIDTokenClaims(token)
+	if err != nil || claims == nil {
+		return err
+	}
+	if claims.Nonce == "" {
+		return ErrOAuthNonceMissing
+	}
+	if subtle.ConstantTimeCompare([]byte(claims.Nonce), []byte(nonce)) != 1 {
+		return ErrOAuthNonceMismatch
+	}
+	return nil
+}
+
 func oauthStatus(err error) string {
 	if err != nil {
 		return "error"
@@ -180,6 +327,12 @@ func classifyOAuthError(err error) string {
 	if err == nil {
 		return "none"
 	}
+	if errors.Is(err, ErrOAuthNonceMissing) {
+		return "nonce_missing"
+	}
+	if errors.Is(err, ErrOAuthNonceMismatch) {
+		return "nonce_mismatch"
+	}
 	if errors.Is(err, context.Canceled) {
 		return "context_canceled"
 	}
@@ -194,6 +347,12 @@ func classifyOAuthError(err error) string {
 
 	msg := strings.ToLower(err.Error())
 	switch {
+	case strings.Contains(msg, "oidc discovery"):
+		return "discovery"
+	case strings.Contains(msg, "invalid id_token"):
+		return "invalid_id_token"
+	case strings.Contains(msg, "code_verifier"), strings.Contains(msg, "code verifier"):
+		return "pkce_mismatch"
 	case strings.Contains(msg, "userinfo status:"):
 		return "userinfo_status"
 	case strings.Contains(msg, "missing required userinfo fields"):
@@ -2,11 +2,14 @@ package service
 
 import (
 	"context"
+	"encoding/base64"
 	"errors"
 	"fmt"
 	"strings"
 	"testing"
 
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/security"
+
 	"golang.org/x/oauth2"
 )
 
@@ -15,9 +18,9 @@ type testOAuthProvider struct {
 	userinfoFn func(ctx context.Context, token *oauth2.Token) (*OAuthUserInfo, error)
 }
 
-func (p testOAuthProvider) AuthCodeURL(_ string) string { return "" }
+func (p testOAuthProvider) AuthCodeURL(string, ...oauth2.AuthCodeOption) string { return "" }
 
-func (p testOAuthProvider) Exchange(ctx context.Context, code string) (*oauth2.Token, error) {
+func (p testOAuthProvider) Exchange(ctx context.Context, code string, _ ...oauth2.AuthCodeOption) (*oauth2.Token, error) {
 	if p.exchangeFn != nil {
 		return p.exchangeFn(ctx, code)
 	}
@@ -31,73 +34,139 @@ func (p testOAuthProvider) FetchUserInfo(ctx context.Context, token *oauth2.Toke
 	return &OAuthUserInfo{ProviderUserID: "provider-id", Email: "user@example.com", EmailVerified: true}, nil
 }
 
-func TestOAuthServiceHandleGoogleCallbackExchangeError(t *testing.T) {
+func googleOnlyOAuthRegistry(provider OAuthProvider) *OAuthProviderRegistry {
+	return NewOAuthProviderRegistry(map[string]OAuthProvider{"google": provider})
+}
+
+func testOAuthFlow(provider string) security.OAuthFlowState {
+	return security.OAuthFlowState{Provider: provider, State: "state", CodeVerifier: "verifier-verifier-verifier-verifier-verifier", Nonce: "nonce"}
+}
+
+func TestOAuthServiceHandleCallbackExchangeError(t *testing.T) {
 	svc := NewOAuthService(
-		testOAuthProvider{exchangeFn: func(context.Context, string) (*oauth2.Token, error) {
+		nil,
+		googleOnlyOAuthRegistry(testOAuthProvider{exchangeFn: func(context.Context, string) (*oauth2.Token, error) {
 			return nil, context.DeadlineExceeded
-		}},
+		}}),
 		nil,
 		nil,
 		nil,
 	)
 
-	_, err := svc.HandleGoogleCallback(context.Background(), "code")
+	_, err := svc.HandleCallback(context.Background(), testOAuthFlow("google"), "code")
 	if !errors.Is(err, context.DeadlineExceeded) {
 		t.Fatalf("expected deadline exceeded, got %v", err)
 	}
 }
 
-func TestOAuthServiceHandleGoogleCallbackUserInfoError(t *testing.T) {
+func TestOAuthServiceHandleCallbackUserInfoError(t *testing.T) {
 	userinfoErr := errors.New("userinfo status: 500")
 	svc := NewOAuthService(
-		testOAuthProvider{userinfoFn: func(context.Context, *oauth2.Token) (*OAuthUserInfo, error) {
+		nil,
+		googleOnlyOAuthRegistry(testOAuthProvider{userinfoFn: func(context.Context, *oauth2.Token) (*OAuthUserInfo, error) {
 			return nil, userinfoErr
-		}},
+		}}),
 		nil,
 		nil,
 		nil,
 	)
 
-	_, err := svc.HandleGoogleCallback(context.Background(), "code")
+	_, err := svc.HandleCallback(context.Background(), testOAuthFlow("google"), "code")
 	if !errors.Is(err, userinfoErr) {
 		t.Fatalf("expected userinfo error, got %v", err)
 	}
 }
 
-func TestOAuthServiceHandleGoogleCallbackEmailNotVerified(t *testing.T) {
+func TestOAuthServiceHandleCallbackEmailNotVerified(t *testing.T) {
 	svc := NewOAuthService(
-		testOAuthProvider{userinfoFn: func(context.Context, *oauth2.Token) (*OAuthUserInfo, error) {
+		nil,
+		googleOnlyOAuthRegistry(testOAuthProvider{userinfoFn: func(context.Context, *oauth2.Token) (*OAuthUserInfo, error) {
 			return &OAuthUserInfo{ProviderUserID: "provider-id", Email: "user@example.com", EmailVerified: false}, nil
-		}},
+		}}),
 		nil,
 		nil,
 		nil,
 	)
 
-	_, err := svc.HandleGoogleCallback(context.Background(), "code")
+	_, err := svc.HandleCallback(context.Background(), testOAuthFlow("google"), "code")
 	if err == nil || err.Error() != "google email not verified" {
 		t.Fatalf("expected google email not verified error, got %v", err)
 	}
 }
 
-func TestOAuthServiceHandleGoogleCallbackNilUserInfo(t *testing.T) {
+func TestOAuthServiceHandleCallbackNilUserInfo(t *testing.T) {
 	svc := NewOAuthService(
-		testOAuthProvider{
+		nil,
+		googleOnlyOAuthRegistry(testOAuthProvider{
 			userinfoFn: func(context.Context, *oauth2.Token) (*OAuthUserInfo, error) {
 				return nil, nil
 			},
-		},
+		}),
 		nil,
 		nil,
 		nil,
 	)
 
-	_, err := svc.HandleGoogleCallback(context.Background(), "code")
+	_, err := svc.HandleCallback(context.Background(), testOAuthFlow("google"), "code")
 	if err == nil || err.Error() != "missing required userinfo fields" {
 		t.Fatalf("expected missing required userinfo fields error, got %v", err)
 	}
 }
 
+func TestOAuthServiceHandleCallbackRequiresCodeVerifier(t *testing.T) {
+	exchanged := false
+	svc := NewOAuthService(
+		nil,
+		googleOnlyOAuthRegistry(testOAuthProvider{exchangeFn: func(context.Context, string) (*oauth2.Token, error) {
+			exchanged = true
+			return &oauth2.Token{AccessToken: "token"}, nil
+		}}),
+		nil,
+		nil,
+		nil,
+	)
+
+	flow := testOAuthFlow("google")
+	flow.CodeVerifier = ""
+	if _, err := svc.HandleCallback(context.Background(), flow, "code"); !errors.Is(err, ErrOAuthFlowStateInvalid) {
+		t.Fatalf("expected ErrOAuthFlowStateInvalid, got %v", err)
+	}
+	if exchanged {
+		t.Fatal("expected exchange to be skipped without a code verifier")
+	}
+}
+
+func TestOAuthServiceHandleCallbackIDTokenNonce(t *testing.T) {
+	idToken := func(claims string) *oauth2.Token {
+		token := &oauth2.Token{AccessToken: "token"}
+		return token.WithExtra(map[string]any{"id_token": "e30." + base64.RawURLEncoding.EncodeToString([]byte(claims)) + ".sig"})
+	}
+	cases := []struct {
+		name    string
+		claims  string
+		wantErr error
+	}{
+		{name: "missing nonce", claims: `{"sub":"s1"}`, wantErr: ErrOAuthNonceMissing},
+		{name: "mismatched nonce", claims: `{"sub":"s1","nonce":"other"}`, wantErr: ErrOAuthNonceMismatch},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := NewOAuthService(
+				nil,
+				googleOnlyOAuthRegistry(testOAuthProvider{exchangeFn: func(context.Context, string) (*oauth2.Token, error) {
+					return idToken(tc.claims), nil
+				}}),
+				nil,
+				nil,
+				nil,
+			)
+			if _, err := svc.HandleCallback(context.Background(), testOAuthFlow("google"), "code"); !errors.Is(err, tc.wantErr) {
+				t.Fatalf("expected %v, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
 func TestClassifyOAuthError(t *testing.T) {
 	if got := classifyOAuthError(context.Canceled); got != "context_canceled" {
 		t.Fatalf("expected context_canceled, got %q", got)
@@ -114,6 +183,21 @@ func TestClassifyOAuthError(t *testing.T) {
 	if got := classifyOAuthError(errors.New("oauth2: cannot fetch token")); got != "oauth2_exchange" {
 		t.Fatalf("expected oauth2_exchange, got %q", got)
 	}
+	if got := classifyOAuthError(errors.New("oidc discovery okta: userinfo status: 503")); got != "discovery" {
+		t.Fatalf("expected discovery, got %q", got)
+	}
+	if got := classifyOAuthError(errors.New("invalid id_token: audience mismatch")); got != "invalid_id_token" {
+		t.Fatalf("expected invalid_id_token, got %q", got)
+	}
+	if got := classifyOAuthError(errors.New(`oauth2: "invalid_grant" "PKCE verification failed: code_verifier mismatch"`)); got != "pkce_mismatch" {
+		t.Fatalf("expected pkce_mismatch, got %q", got)
+	}
+	if got := classifyOAuthError(fmt.Errorf("callback: %w", ErrOAuthNonceMismatch)); got != "nonce_mismatch" {
+		t.Fatalf("expected nonce_mismatch, got %q", got)
+	}
+	if got := classifyOAuthError(ErrOAuthNonceMissing); got != "nonce_missing" {
+		t.Fatalf("expected nonce_missing, got %q", got)
+	}
 }
 
 func FuzzClassifyOAuthErrorRobustness(f *testing.F) {
@@ -146,7 +230,7 @@ func FuzzClassifyOAuthErrorRobustness(f *testing.F) {
 
 		got := classifyOAuthError(err)
 		switch got {
-		case "none", "context_canceled", "timeout", "userinfo_status", "invalid_userinfo", "oauth2_exchange", "other":
+		case "none", "context_canceled", "timeout", "discovery", "invalid_id_token", "pkce_mismatch", "userinfo_status", "invalid_userinfo", "oauth2_exchange", "other":
 		default:
 			t.Fatalf("unexpected classification %q for err=%v", got, err)
 		}
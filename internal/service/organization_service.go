@@ -0,0 +1,234 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/domain"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/repository"
+)
+
+var (
+	ErrInvalidOrganizationRequest = errors.New("invalid organization request")
+	ErrOrganizationNotFound       = errors.New("organization not found")
+	ErrOrganizationConflict       = errors.New("organization slug already exists")
+	ErrOrganizationMemberNotFound = errors.New("organization member not found")
+	ErrOrganizationRoleNotAllowed = errors.New("role grants permissions the caller lacks")
+)
+
+// PermissionOrganizationsWrite lets a global operator create organizations and
+// enter any organization's scope without being a member.
+const PermissionOrganizationsWrite = "orgs:write"
+
+var organizationSlugRe = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,62}[a-z0-9])?$`)
+
+type OrganizationInput struct {
+	Slug string
+	Name string
+}
+
+type OrganizationView struct {
+	ID        uint      `json:"id"`
+	Slug      string    `json:"slug"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type OrganizationMemberView struct {
+	UserID      uint      `json:"user_id"`
+	Roles       []string  `json:"roles"`
+	Permissions []string  `json:"permissions"`
+	JoinedAt    time.Time `json:"joined_at"`
+}
+
+type OrganizationService struct {
+	repo  repository.OrganizationRepository
+	users repository.UserRepository
+	roles repository.RoleRepository
+	rbac  *RBACService
+}
+
+func NewOrganizationService(repo repository.OrganizationRepository, users repository.UserRepository, roles repository.RoleRepository, rbac *RBACService) *OrganizationService {
+	return &OrganizationService{repo: repo, users: users, roles: roles, rbac: rbac}
+}
+
+func (s *OrganizationService) Create(input OrganizationInput) (*OrganizationView, error) {
+	slug := strings.ToLower(strings.TrimSpace(input.Slug))
+	name := strings.TrimSpace(input.Name)
+	if !organizationSlugRe.MatchString(slug) || name == "" || len(name) > 255 {
+		return nil, ErrInvalidOrganizationRequest
+	}
+	if _, err := s.repo.FindBySlug(slug); err == nil {
+		return nil, ErrOrganizationConflict
+	} else if !errors.Is(err, repository.ErrOrganizationNotFound) {
+		return nil, err
+	}
+	org := &domain.Organization{Slug: slug, Name: name}
+	if err := s.repo.Create(org); err != nil {
+		return nil, err
+	}
+	view := organizationView(*org)
+	return &view, nil
+}
+
+func (s *OrganizationService) List() ([]OrganizationView, error) {
+	orgs, err := s.repo.List()
+	if err != nil {
+		return nil, err
+	}
+	return organizationViews(orgs), nil
+}
+
+func (s *OrganizationService) ListForUser(userID uint) ([]OrganizationView, error) {
+	orgs, err := s.repo.ListForUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	return organizationViews(orgs), nil
+}
+
+func (s *OrganizationService) Get(id uint) (*OrganizationView, error) {
+	org, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, mapOrganizationError(err)
+	}
+	view := organizationView(*org)
+	return &view, nil
+}
+
+func (s *OrganizationService) Exists(id uint) (bool, error) {
+	if _, err := s.repo.FindByID(id); err != nil {
+		if errors.Is(err, repository.ErrOrganizationNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+func (s *OrganizationService) IsMember(orgID, userID uint) (bool, error) {
+	return s.repo.IsMember(orgID, userID)
+}
+
+func (s *OrganizationService) Members(orgID uint) ([]OrganizationMemberView, error) {
+	if _, err := s.Get(orgID); err != nil {
+		return nil, err
+	}
+	members, err := s.repo.ListMembers(orgID)
+	if err != nil {
+		return nil, err
+	}
+	views := make([]OrganizationMemberView, 0, len(members))
+	for _, member := range members {
+		views = append(views, s.memberView(member))
+	}
+	return views, nil
+}
+
+// SetMember adds userID to the organization with exactly roleIDs bound. A
+// caller may only bind roles whose permissions it already holds in the
+// organization, so members:write cannot be used to escalate.
+func (s *OrganizationService) SetMember(orgID, userID uint, roleIDs []uint, actorPerms []string) (*OrganizationMemberView, error) {
+	if _, err := s.Get(orgID); err != nil {
+		return nil, err
+	}
+	user, err := s.users.FindByID(userID)
+	if err != nil || user.Status == domain.UserStatusDeleted {
+		return nil, ErrInvalidOrganizationRequest
+	}
+	roles := make([]domain.Role, 0, len(roleIDs))
+	for _, id := range roleIDs {
+		role, err := s.roles.FindByID(id)
+		if err != nil {
+			if errors.Is(err, repository.ErrRoleNotFound) {
+				return nil, ErrInvalidOrganizationRequest
+			}
+			return nil, err
+		}
+		roles = append(roles, *role)
+	}
+	if !grantsAll(actorPerms, s.rbac.PermissionsFromRoles(roles)) {
+		return nil, ErrOrganizationRoleNotAllowed
+	}
+	if err := s.repo.SetMember(orgID, userID, roleIDs); err != nil {
+		if errors.Is(err, repository.ErrRoleNotFound) {
+			return nil, ErrInvalidOrganizationRequest
+		}
+		return nil, mapOrganizationError(err)
+	}
+	member, err := s.repo.FindMember(orgID, userID)
+	if err != nil {
+		return nil, mapOrganizationError(err)
+	}
+	view := s.memberView(*member)
+	return &view, nil
+}
+
+func (s *OrganizationService) RemoveMember(orgID, userID uint) error {
+	return mapOrganizationError(s.repo.RemoveMember(orgID, userID))
+}
+
+// MemberPermissions returns the permissions userID holds through its role
+// bindings in the organization. Global roles are not included.
+func (s *OrganizationService) MemberPermissions(orgID, userID uint) ([]string, error) {
+	roles, err := s.repo.MemberRoles(orgID, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.rbac.PermissionsFromRoles(roles), nil
+}
+
+func (s *OrganizationService) memberView(member domain.OrganizationMember) OrganizationMemberView {
+	perms := s.rbac.PermissionsFromRoles(member.Roles)
+	sort.Strings(perms)
+	names := make([]string, 0, len(member.Roles))
+	for _, role := range member.Roles {
+		names = append(names, role.Name)
+	}
+	sort.Strings(names)
+	return OrganizationMemberView{
+		UserID:      member.UserID,
+		Roles:       names,
+		Permissions: perms,
+		JoinedAt:    member.CreatedAt,
+	}
+}
+
+func organizationView(org domain.Organization) OrganizationView {
+	return OrganizationView{ID: org.ID, Slug: org.Slug, Name: org.Name, CreatedAt: org.CreatedAt}
+}
+
+func organizationViews(orgs []domain.Organization) []OrganizationView {
+	views := make([]OrganizationView, 0, len(orgs))
+	for _, org := range orgs {
+		views = append(views, organizationView(org))
+	}
+	return views
+}
+
+func mapOrganizationError(err error) error {
+	switch {
+	case errors.Is(err, repository.ErrOrganizationNotFound):
+		return ErrOrganizationNotFound
+	case errors.Is(err, repository.ErrOrganizationMemberNotFound):
+		return ErrOrganizationMemberNotFound
+	}
+	return err
+}
+
+type organizationContextKey struct{}
+
+// WithOrganization scopes ctx to an organization. Permission resolution adds
+// the caller's bindings in that organization to its global permissions.
+func WithOrganization(ctx context.Context, orgID uint) context.Context {
+	return context.WithValue(ctx, organizationContextKey{}, orgID)
+}
+
+func OrganizationFromContext(ctx context.Context) (uint, bool) {
+	orgID, ok := ctx.Value(organizationContextKey{}).(uint)
+	return orgID, ok && orgID != 0
+}
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/domain"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/repository"
+)
+
+func newOrganizationServiceForTest(t *testing.T) (*OrganizationService, *gorm.DB) {
+	t.Helper()
+	dsn := fmt.Sprintf("file:%s?mode=memory&cache=shared", strings.ReplaceAll(t.Name(), "/", "_"))
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	if err := db.AutoMigrate(&domain.Permission{}, &domain.Role{}, &domain.RoleParent{}, &domain.User{}, &domain.UserRole{}, &domain.Organization{}, &domain.OrganizationMember{}, &domain.OrganizationRoleBinding{}); err != nil {
+		t.Fatalf("migrate organization models: %v", err)
+	}
+	svc := NewOrganizationService(repository.NewOrganizationRepository(db), repository.NewUserRepository(db), repository.NewRoleRepository(db), NewRBACService())
+	return svc, db
+}
+
+func TestOrganizationServiceCreateValidatesSlug(t *testing.T) {
+	svc, _ := newOrganizationServiceForTest(t)
+
+	org, err := svc.Create(OrganizationInput{Slug: " Acme-Corp ", Name: " Acme Corp "})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if org.Slug != "acme-corp" || org.Name != "Acme Corp" {
+		t.Fatalf("expected normalized organization, got %+v", org)
+	}
+	if _, err := svc.Create(OrganizationInput{Slug: "acme-corp", Name: "Other"}); !errors.Is(err, ErrOrganizationConflict) {
+		t.Fatalf("expected slug conflict, got %v", err)
+	}
+	for _, input := range []OrganizationInput{
+		{Slug: "", Name: "Empty"},
+		{Slug: "-acme", Name: "Leading dash"},
+		{Slug: "acme_corp", Name: "Underscore"},
+		{Slug: "acme", Name: " "},
+	} {
+		if _, err := svc.Create(input); !errors.Is(err, ErrInvalidOrganizationRequest) {
+			t.Fatalf("expected %+v to be rejected, got %v", input, err)
+		}
+	}
+}
+
+func TestOrganizationServiceSetMemberCannotEscalate(t *testing.T) {
+	svc, db := newOrganizationServiceForTest(t)
+	org, err := svc.Create(OrganizationInput{Slug: "acme", Name: "Acme"})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	viewer := domain.Role{Name: "org-viewer", Permissions: []domain.Permission{{Resource: "members", Action: "read"}}}
+	owner := domain.Role{Name: "org-owner", Permissions: []domain.Permission{{Resource: "members", Action: "write"}, {Resource: "billing", Action: "write"}}}
+	for _, role := range []*domain.Role{&viewer, &owner} {
+		if err := db.Create(role).Error; err != nil {
+			t.Fatalf("create role: %v", err)
+		}
+	}
+	user := domain.User{Email: "member@example.com", Name: "Member", Status: domain.UserStatusActive}
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+
+	actorPerms := []string{"members:read", "members:write"}
+	if _, err := svc.SetMember(org.ID, user.ID, []uint{owner.ID}, actorPerms); !errors.Is(err, ErrOrganizationRoleNotAllowed) {
+		t.Fatalf("expected binding a role beyond the caller's permissions to fail, got %v", err)
+	}
+	if _, err := svc.SetMember(org.ID, user.ID, []uint{owner.ID}, []string{"*:*"}); err != nil {
+		t.Fatalf("expected a wildcard holder to bind any role, got %v", err)
+	}
+	member, err := svc.SetMember(org.ID, user.ID, []uint{viewer.ID}, actorPerms)
+	if err != nil {
+		t.Fatalf("set member: %v", err)
+	}
+	if strings.Join(member.Roles, ",") != "org-viewer" || strings.Join(member.Permissions, ",") != "members:read" {
+		t.Fatalf("unexpected member view: %+v", member)
+	}
+	if _, err := svc.SetMember(org.ID, 999, nil, actorPerms); !errors.Is(err, ErrInvalidOrganizationRequest) {
+		t.Fatalf("expected unknown user to be rejected, got %v", err)
+	}
+	if _, err := svc.SetMember(999, user.ID, nil, actorPerms); !errors.Is(err, ErrOrganizationNotFound) {
+		t.Fatalf("expected unknown organization, got %v", err)
+	}
+
+	perms, err := svc.MemberPermissions(org.ID, user.ID)
+	if err != nil || strings.Join(perms, ",") != "members:read" {
+		t.Fatalf("expected org-scoped permissions, got %v err=%v", perms, err)
+	}
+	if err := svc.RemoveMember(org.ID, user.ID); err != nil {
+		t.Fatalf("remove member: %v", err)
+	}
+	if err := svc.RemoveMember(org.ID, user.ID); !errors.Is(err, ErrOrganizationMemberNotFound) {
+		t.Fatalf("expected missing member, got %v", err)
+	}
+}
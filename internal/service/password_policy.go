@@ -0,0 +1,278 @@
+package service
+
+import (
+	"bufio"
+	"crypto/sha1" // #nosec G505 -- SHA-1 is the lookup key of breached-password corpora, not used for hashing secrets.
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/config"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/security"
+)
+
+const (
+	PasswordRuleMinLength    = "min_length"
+	PasswordRuleMaxLength    = "max_length"
+	PasswordRuleUpper        = "upper"
+	PasswordRuleLower        = "lower"
+	PasswordRuleDigit        = "digit"
+	PasswordRuleSymbol       = "symbol"
+	PasswordRulePersonalInfo = "personal_info"
+	PasswordRuleBreached     = "breached"
+	PasswordRuleReused       = "reused"
+
+	defaultPasswordMinLength = 12
+	defaultPasswordMaxLength = 128
+	minBreachedPrefixLength  = 5
+	minPersonalTokenLength   = 3
+)
+
+var defaultPasswordClasses = []string{PasswordRuleUpper, PasswordRuleLower, PasswordRuleDigit, PasswordRuleSymbol}
+
+// PasswordViolation is one failed rule, shaped for the API error details so
+// clients can point at what to fix.
+type PasswordViolation struct {
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// PasswordPolicyError lists every rule a candidate password failed. It
+// matches ErrWeakPassword with errors.Is.
+type PasswordPolicyError struct {
+	Violations []PasswordViolation
+}
+
+func (e *PasswordPolicyError) Error() string {
+	return ErrWeakPassword.Error()
+}
+
+func (e *PasswordPolicyError) Is(target error) bool {
+	return target == ErrWeakPassword
+}
+
+// PasswordSubject is what the policy knows about the account a password is
+// for. PreviousHashes holds the current hash and the retained history.
+type PasswordSubject struct {
+	Email          string
+	Name           string
+	PreviousHashes []string
+}
+
+// PasswordPolicy checks candidate passwords against the PASSWORD_* settings.
+// Zero-valued settings fall back to the defaults; PASSWORD_REQUIRED_CLASSES
+// of none drops the character-class rules.
+type PasswordPolicy struct {
+	minLength      int
+	maxLength      int
+	classes        []string
+	rejectPersonal bool
+	historyCount   int
+	maxAge         time.Duration
+	breached       map[int]map[string]struct{}
+}
+
+func NewPasswordPolicy(cfg *config.Config) (*PasswordPolicy, error) {
+	p := &PasswordPolicy{
+		minLength:      cfg.PasswordMinLength,
+		maxLength:      cfg.PasswordMaxLength,
+		classes:        cfg.PasswordRequiredClasses,
+		rejectPersonal: cfg.PasswordRejectPersonalInfo,
+		historyCount:   cfg.PasswordHistoryCount,
+		maxAge:         cfg.PasswordMaxAge,
+	}
+	if p.minLength <= 0 {
+		p.minLength = defaultPasswordMinLength
+	}
+	if p.maxLength <= 0 {
+		p.maxLength = defaultPasswordMaxLength
+	}
+	if len(p.classes) == 0 {
+		p.classes = defaultPasswordClasses
+	}
+	for _, class := range p.classes {
+		if class == "none" {
+			p.classes = nil
+			break
+		}
+	}
+	if cfg.PasswordBreachedListFile != "" {
+		breached, err := loadBreachedPrefixes(cfg.PasswordBreachedListFile)
+		if err != nil {
+			return nil, err
+		}
+		p.breached = breached
+	}
+	return p, nil
+}
+
+// loadBreachedPrefixes reads one hex SHA-1 prefix per line. Lines may carry a
+// ":count" suffix as in the Have I Been Pwned exports; blank lines and lines
+// starting with # are skipped. Prefixes are grouped by length so a lookup is
+// one map probe per distinct length.
+func loadBreachedPrefixes(path string) (map[int]map[string]struct{}, error) {
+	f, err := os.Open(path) // #nosec G304 -- path comes from operator configuration.
+	if err != nil {
+		return nil, fmt.Errorf("open breached password list: %w", err)
+	}
+	defer f.Close()
+
+	prefixes := map[int]map[string]struct{}{}
+	scanner := bufio.NewScanner(f)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if i := strings.IndexByte(line, ':'); i >= 0 {
+			line = line[:i]
+		}
+		prefix := strings.ToLower(line)
+		if len(prefix) < minBreachedPrefixLength || len(prefix) > sha1.Size*2 {
+			return nil, fmt.Errorf("breached password list line %d: prefix must be %d to %d hex characters", lineNo, minBreachedPrefixLength, sha1.Size*2)
+		}
+		if _, err := hex.DecodeString(prefix + strings.Repeat("0", len(prefix)%2)); err != nil {
+			return nil, fmt.Errorf("breached password list line %d: prefix is not hex", lineNo)
+		}
+		if prefixes[len(prefix)] == nil {
+			prefixes[len(prefix)] = map[string]struct{}{}
+		}
+		prefixes[len(prefix)][prefix] = struct{}{}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read breached password list: %w", err)
+	}
+	return prefixes, nil
+}
+
+// HistoryCount is how many replaced hashes to keep for reuse checks.
+func (p *PasswordPolicy) HistoryCount() int {
+	return p.historyCount
+}
+
+// Expired reports whether a password set at changedAt is past PASSWORD_MAX_AGE.
+func (p *PasswordPolicy) Expired(changedAt, now time.Time) bool {
+	return p.maxAge > 0 && !changedAt.IsZero() && now.Sub(changedAt) > p.maxAge
+}
+
+// Validate returns a *PasswordPolicyError listing every failed rule, or nil.
+// The reuse check runs last and only when everything else passed, since each
+// previous hash costs a full password verification.
+func (p *PasswordPolicy) Validate(password string, subject PasswordSubject) error {
+	var violations []PasswordViolation
+	length := utf8.RuneCountInString(password)
+	if length < p.minLength {
+		violations = append(violations, PasswordViolation{Rule: PasswordRuleMinLength, Message: fmt.Sprintf("must be at least %d characters", p.minLength)})
+	}
+	if length > p.maxLength {
+		violations = append(violations, PasswordViolation{Rule: PasswordRuleMaxLength, Message: fmt.Sprintf("must be at most %d characters", p.maxLength)})
+	}
+	for _, class := range p.classes {
+		if !containsPasswordClass(password, class) {
+			violations = append(violations, PasswordViolation{Rule: class, Message: passwordClassMessages[class]})
+		}
+	}
+	if p.rejectPersonal && containsPersonalInfo(password, subject) {
+		violations = append(violations, PasswordViolation{Rule: PasswordRulePersonalInfo, Message: "must not contain your email address or name"})
+	}
+	if p.isBreached(password) {
+		violations = append(violations, PasswordViolation{Rule: PasswordRuleBreached, Message: "appears in a list of breached passwords"})
+	}
+	if len(violations) == 0 {
+		reused, err := passwordMatchesAny(password, subject.PreviousHashes)
+		if err != nil {
+			return err
+		}
+		if reused {
+			violations = append(violations, PasswordViolation{Rule: PasswordRuleReused, Message: "must not match a recently used password"})
+		}
+	}
+	if len(violations) > 0 {
+		return &PasswordPolicyError{Violations: violations}
+	}
+	return nil
+}
+
+var passwordClassMessages = map[string]string{
+	PasswordRuleUpper:  "must contain an uppercase letter",
+	PasswordRuleLower:  "must contain a lowercase letter",
+	PasswordRuleDigit:  "must contain a digit",
+	PasswordRuleSymbol: "must contain a symbol",
+}
+
+func containsPasswordClass(password, class string) bool {
+	for _, r := range password {
+		switch class {
+		case PasswordRuleUpper:
+			if unicode.IsUpper(r) {
+				return true
+			}
+		case PasswordRuleLower:
+			if unicode.IsLower(r) {
+				return true
+			}
+		case PasswordRuleDigit:
+			if unicode.IsDigit(r) {
+				return true
+			}
+		case PasswordRuleSymbol:
+			if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// containsPersonalInfo matches the email's local part and each word of the
+// name, ignoring case. Very short tokens are skipped so a name like "Al"
+// does not rule out half the dictionary.
+func containsPersonalInfo(password string, subject PasswordSubject) bool {
+	lowered := strings.ToLower(password)
+	tokens := strings.FieldsFunc(strings.ToLower(subject.Name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if local, _, ok := strings.Cut(strings.ToLower(strings.TrimSpace(subject.Email)), "@"); ok {
+		tokens = append(tokens, local)
+	}
+	for _, token := range tokens {
+		if utf8.RuneCountInString(token) >= minPersonalTokenLength && strings.Contains(lowered, token) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *PasswordPolicy) isBreached(password string) bool {
+	if len(p.breached) == 0 {
+		return false
+	}
+	sum := sha1.Sum([]byte(password)) // #nosec G401 -- matched against SHA-1 keyed breach data.
+	digest := hex.EncodeToString(sum[:])
+	for length, prefixes := range p.breached {
+		if _, ok := prefixes[digest[:length]]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+func passwordMatchesAny(password string, hashes []string) (bool, error) {
+	for _, hash := range hashes {
+		if hash == "" {
+			continue
+		}
+		ok, err := security.VerifyPassword(hash, password)
+		if err != nil {
+			return false, err
+		}
+		if ok {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,152 @@
+package service
+
+import (
+	"crypto/sha1" // #nosec G505 -- builds breached-list fixtures.
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/config"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/security"
+)
+
+func TestValidatePasswordPolicy(t *testing.T) {
+	policy, err := NewPasswordPolicy(&config.Config{})
+	if err != nil {
+		t.Fatalf("new policy: %v", err)
+	}
+	tests := []struct {
+		name     string
+		password string
+		wantErr  bool
+	}{
+		{name: "valid", password: "Valid#Pass123", wantErr: false},
+		{name: "too_short", password: "Aa1#short", wantErr: true},
+		{name: "missing_upper", password: "valid#pass1234", wantErr: true},
+		{name: "missing_lower", password: "VALID#PASS1234", wantErr: true},
+		{name: "missing_digit", password: "Valid#Password", wantErr: true},
+		{name: "missing_special", password: "ValidPass1234", wantErr: true},
+	}
+	for _, tc := range tests {
+		err := policy.Validate(tc.password, PasswordSubject{})
+		if tc.wantErr && !errors.Is(err, ErrWeakPassword) {
+			t.Fatalf("%s: expected ErrWeakPassword, got %v", tc.name, err)
+		}
+		if !tc.wantErr && err != nil {
+			t.Fatalf("%s: unexpected error: %v", tc.name, err)
+		}
+	}
+}
+
+func TestPasswordPolicyReportsEveryViolation(t *testing.T) {
+	policy, err := NewPasswordPolicy(&config.Config{
+		PasswordMinLength:          10,
+		PasswordMaxLength:          16,
+		PasswordRequiredClasses:    []string{"digit", "symbol"},
+		PasswordRejectPersonalInfo: true,
+	})
+	if err != nil {
+		t.Fatalf("new policy: %v", err)
+	}
+
+	err = policy.Validate("JORDANpassword-more", PasswordSubject{Email: "jordan.lee@example.com", Name: "Jordan Lee"})
+	var policyErr *PasswordPolicyError
+	if !errors.As(err, &policyErr) {
+		t.Fatalf("expected *PasswordPolicyError, got %v", err)
+	}
+	got := map[string]bool{}
+	for _, v := range policyErr.Violations {
+		got[v.Rule] = true
+	}
+	want := []string{PasswordRuleMaxLength, PasswordRuleDigit, PasswordRulePersonalInfo}
+	if len(policyErr.Violations) != len(want) {
+		t.Fatalf("expected violations %v, got %+v", want, policyErr.Violations)
+	}
+	for _, rule := range want {
+		if !got[rule] {
+			t.Fatalf("expected %s violation, got %+v", rule, policyErr.Violations)
+		}
+	}
+
+	if err := policy.Validate("Al-Bo-is-fine-99", PasswordSubject{Name: "Al Bo"}); err != nil {
+		t.Fatalf("expected short name tokens to be ignored, got %v", err)
+	}
+}
+
+func TestPasswordPolicyClassesCanBeDisabled(t *testing.T) {
+	policy, err := NewPasswordPolicy(&config.Config{PasswordRequiredClasses: []string{"none"}})
+	if err != nil {
+		t.Fatalf("new policy: %v", err)
+	}
+	if err := policy.Validate("correct horse battery", PasswordSubject{}); err != nil {
+		t.Fatalf("expected a long passphrase to pass, got %v", err)
+	}
+}
+
+func TestPasswordPolicyRejectsBreachedPasswords(t *testing.T) {
+	breached := "Summer2024!Summer"
+	sum := sha1.Sum([]byte(breached)) // #nosec G401 -- fixture for SHA-1 keyed breach data.
+	digest := hex.EncodeToString(sum[:])
+	path := filepath.Join(t.TempDir(), "breached.txt")
+	content := "# test corpus\n\n" + digest[:7] + ":42\nABCDEF0123\n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write list: %v", err)
+	}
+
+	policy, err := NewPasswordPolicy(&config.Config{PasswordBreachedListFile: path})
+	if err != nil {
+		t.Fatalf("new policy: %v", err)
+	}
+	var policyErr *PasswordPolicyError
+	if err := policy.Validate(breached, PasswordSubject{}); !errors.As(err, &policyErr) || policyErr.Violations[0].Rule != PasswordRuleBreached {
+		t.Fatalf("expected breached violation, got %v", err)
+	}
+	if err := policy.Validate("Winter2024!Winter", PasswordSubject{}); err != nil {
+		t.Fatalf("expected unlisted password to pass, got %v", err)
+	}
+
+	if err := os.WriteFile(path, []byte("abc\n"), 0o600); err != nil {
+		t.Fatalf("write list: %v", err)
+	}
+	if _, err := NewPasswordPolicy(&config.Config{PasswordBreachedListFile: path}); err == nil {
+		t.Fatal("expected a too-short prefix to be rejected")
+	}
+}
+
+func TestPasswordPolicyRejectsReuse(t *testing.T) {
+	policy, err := NewPasswordPolicy(&config.Config{PasswordHistoryCount: 3})
+	if err != nil {
+		t.Fatalf("new policy: %v", err)
+	}
+	old, err := security.HashPassword("Previous#Pass123")
+	if err != nil {
+		t.Fatalf("hash: %v", err)
+	}
+	subject := PasswordSubject{PreviousHashes: []string{old}}
+
+	var policyErr *PasswordPolicyError
+	if err := policy.Validate("Previous#Pass123", subject); !errors.As(err, &policyErr) || policyErr.Violations[0].Rule != PasswordRuleReused {
+		t.Fatalf("expected reuse violation, got %v", err)
+	}
+	if err := policy.Validate("Brand#NewPass456", subject); err != nil {
+		t.Fatalf("expected new password to pass, got %v", err)
+	}
+}
+
+func TestPasswordPolicyExpiry(t *testing.T) {
+	now := time.Now().UTC()
+	disabled, _ := NewPasswordPolicy(&config.Config{})
+	if disabled.Expired(now.Add(-10*365*24*time.Hour), now) {
+		t.Fatal("expected no expiry when PASSWORD_MAX_AGE is unset")
+	}
+	policy, _ := NewPasswordPolicy(&config.Config{PasswordMaxAge: 90 * 24 * time.Hour})
+	if policy.Expired(now.Add(-89*24*time.Hour), now) {
+		t.Fatal("expected a recent password to be valid")
+	}
+	if !policy.Expired(now.Add(-91*24*time.Hour), now) {
+		t.Fatal("expected an old password to be expired")
+	}
+}
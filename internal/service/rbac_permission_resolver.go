@@ -15,14 +15,18 @@ import (
 type CachedPermissionResolver struct {
 	cacheStore RBACPermissionCacheStore
 	userSvc    UserServiceInterface
+	orgs       OrganizationPermissionSource
 	ttl        time.Duration
 	sf         singleflight.Group
 }
 
-func NewCachedPermissionResolver(cacheStore RBACPermissionCacheStore, userSvc UserServiceInterface, ttl time.Duration) *CachedPermissionResolver {
+// NewCachedPermissionResolver builds a resolver over the user's global roles.
+// orgs may be nil, in which case organization scope adds nothing.
+func NewCachedPermissionResolver(cacheStore RBACPermissionCacheStore, userSvc UserServiceInterface, orgs OrganizationPermissionSource, ttl time.Duration) *CachedPermissionResolver {
 	return &CachedPermissionResolver{
 		cacheStore: cacheStore,
 		userSvc:    userSvc,
+		orgs:       orgs,
 		ttl:        ttl,
 	}
 }
@@ -39,6 +43,13 @@ func (r *CachedPermissionResolver) ResolvePermissions(ctx context.Context, claim
 	if sessionTokenID == "" {
 		sessionTokenID = "none"
 	}
+	orgID, scoped := OrganizationFromContext(ctx)
+	scoped = scoped && r.orgs != nil
+	if scoped {
+		// Cache entries are per session and per organization so org-scoped
+		// grants never leak into global (or another org's) lookups.
+		sessionTokenID = fmt.Sprintf("%s:org:%d", sessionTokenID, orgID)
+	}
 	if r.cacheStore != nil && r.ttl > 0 {
 		cached, ok, err := r.cacheStore.Get(ctx, uint(userID), sessionTokenID)
 		if err == nil && ok {
@@ -54,12 +65,27 @@ func (r *CachedPermissionResolver) ResolvePermissions(ctx context.Context, claim
 				return cached, nil
 			}
 		}
-		_, perms, err := r.userSvc.GetByID(uint(userID))
+		user, perms, err := r.userSvc.GetByID(uint(userID))
 		if err != nil {
 			return nil, err
 		}
-		if r.cacheStore != nil && r.ttl > 0 {
-			_ = r.cacheStore.Set(ctx, uint(userID), sessionTokenID, perms, r.ttl)
+		ttl := r.ttl
+		if user != nil && user.RolesExpireAt != nil {
+			// Never cache past the first binding expiry, so a lapsed
+			// role stops granting on time rather than at the next sweep.
+			if untilExpiry := time.Until(*user.RolesExpireAt); untilExpiry < ttl {
+				ttl = untilExpiry
+			}
+		}
+		if scoped {
+			orgPerms, err := r.orgs.MemberPermissions(orgID, uint(userID))
+			if err != nil {
+				return nil, err
+			}
+			perms = mergePermissions(perms, orgPerms)
+		}
+		if r.cacheStore != nil && ttl > 0 {
+			_ = r.cacheStore.Set(ctx, uint(userID), sessionTokenID, perms, ttl)
 		}
 		return perms, nil
 	})
@@ -92,6 +118,21 @@ func (r *CachedPermissionResolver) InvalidateAll(ctx context.Context) error {
 	return r.cacheStore.InvalidateAll(ctx)
 }
 
+func mergePermissions(a, b []string) []string {
+	seen := make(map[string]struct{}, len(a)+len(b))
+	out := make([]string, 0, len(a)+len(b))
+	for _, list := range [][]string{a, b} {
+		for _, p := range list {
+			if _, ok := seen[p]; ok {
+				continue
+			}
+			seen[p] = struct{}{}
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
 func buildRBACPermissionCacheKey(globalEpoch, userEpoch uint64, userID uint, sessionTokenID string) string {
 	if sessionTokenID == "" {
 		sessionTokenID = "none"
@@ -12,10 +12,12 @@ import (
 )
 
 type stubUserService struct {
-	perms []string
-	delay time.Duration
-	mu    sync.Mutex
-	calls int
+	perms         []string
+	status        string
+	rolesExpireAt *time.Time
+	delay         time.Duration
+	mu            sync.Mutex
+	calls         int
 }
 
 func (s *stubUserService) GetByID(id uint) (*domain.User, []string, error) {
@@ -25,14 +27,14 @@ func (s *stubUserService) GetByID(id uint) (*domain.User, []string, error) {
 	s.mu.Lock()
 	s.calls++
 	s.mu.Unlock()
-	return &domain.User{ID: id}, append([]string(nil), s.perms...), nil
+	return &domain.User{ID: id, Status: s.status, RolesExpireAt: s.rolesExpireAt}, append([]string(nil), s.perms...), nil
 }
 
 func (s *stubUserService) List() ([]domain.User, error) {
 	return nil, nil
 }
 
-func (s *stubUserService) SetRoles(uint, []uint) error {
+func (s *stubUserService) SetRoleBindings(uint, []domain.UserRole) error {
 	return nil
 }
 
@@ -45,7 +47,7 @@ func (s *stubUserService) Calls() int {
 func TestCachedPermissionResolverCachesBySession(t *testing.T) {
 	store := NewInMemoryRBACPermissionCacheStore()
 	userSvc := &stubUserService{perms: []string{"users:read"}}
-	resolver := NewCachedPermissionResolver(store, userSvc, time.Minute)
+	resolver := NewCachedPermissionResolver(store, userSvc, nil, time.Minute)
 
 	claims := &security.Claims{}
 	claims.Subject = "42"
@@ -77,7 +79,7 @@ func TestCachedPermissionResolverCachesBySession(t *testing.T) {
 func TestCachedPermissionResolverInvalidateUser(t *testing.T) {
 	store := NewInMemoryRBACPermissionCacheStore()
 	userSvc := &stubUserService{perms: []string{"roles:read"}}
-	resolver := NewCachedPermissionResolver(store, userSvc, time.Minute)
+	resolver := NewCachedPermissionResolver(store, userSvc, nil, time.Minute)
 
 	claims := &security.Claims{}
 	claims.Subject = "7"
@@ -97,13 +99,35 @@ func TestCachedPermissionResolverInvalidateUser(t *testing.T) {
 	}
 }
 
+func TestCachedPermissionResolverDoesNotCachePastRoleExpiry(t *testing.T) {
+	store := NewInMemoryRBACPermissionCacheStore()
+	expiresAt := time.Now().Add(50 * time.Millisecond)
+	userSvc := &stubUserService{perms: []string{"users:write"}, rolesExpireAt: &expiresAt}
+	resolver := NewCachedPermissionResolver(store, userSvc, nil, time.Minute)
+
+	claims := &security.Claims{}
+	claims.Subject = "9"
+	claims.ID = "jti-oncall"
+
+	if _, err := resolver.ResolvePermissions(context.Background(), claims); err != nil {
+		t.Fatalf("resolve permissions: %v", err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	if _, err := resolver.ResolvePermissions(context.Background(), claims); err != nil {
+		t.Fatalf("resolve permissions after expiry: %v", err)
+	}
+	if userSvc.Calls() != 2 {
+		t.Fatalf("expected cache entry to lapse with the role binding, got user service calls=%d", userSvc.Calls())
+	}
+}
+
 func TestCachedPermissionResolverSingleflightDedupesConcurrentMisses(t *testing.T) {
 	store := NewInMemoryRBACPermissionCacheStore()
 	userSvc := &stubUserService{
 		perms: []string{"roles:read", "roles:write"},
 		delay: 40 * time.Millisecond,
 	}
-	resolver := NewCachedPermissionResolver(store, userSvc, time.Minute)
+	resolver := NewCachedPermissionResolver(store, userSvc, nil, time.Minute)
 
 	claims := &security.Claims{}
 	claims.Subject = "55"
@@ -139,3 +163,40 @@ func TestCachedPermissionResolverSingleflightDedupesConcurrentMisses(t *testing.
 		t.Fatalf("expected singleflight dedupe to one GetByID call, got %d", userSvc.Calls())
 	}
 }
+
+type stubOrganizationPermissions map[uint][]string
+
+func (s stubOrganizationPermissions) MemberPermissions(orgID, _ uint) ([]string, error) {
+	return append([]string(nil), s[orgID]...), nil
+}
+
+func TestCachedPermissionResolverAddsOrganizationBindingsOnlyInScope(t *testing.T) {
+	store := NewInMemoryRBACPermissionCacheStore()
+	userSvc := &stubUserService{perms: []string{"users:read"}}
+	orgs := stubOrganizationPermissions{1: {"members:write", "users:read"}, 2: {"billing:read"}}
+	resolver := NewCachedPermissionResolver(store, userSvc, orgs, time.Minute)
+
+	claims := &security.Claims{}
+	claims.Subject = "9"
+	claims.ID = "jti-org"
+
+	global, err := resolver.ResolvePermissions(context.Background(), claims)
+	if err != nil || len(global) != 1 || global[0] != "users:read" {
+		t.Fatalf("expected global permissions only, got %v err=%v", global, err)
+	}
+	scoped, err := resolver.ResolvePermissions(WithOrganization(context.Background(), 1), claims)
+	if err != nil || len(scoped) != 2 || scoped[0] != "users:read" || scoped[1] != "members:write" {
+		t.Fatalf("expected global plus org 1 permissions, got %v err=%v", scoped, err)
+	}
+	other, err := resolver.ResolvePermissions(WithOrganization(context.Background(), 2), claims)
+	if err != nil || len(other) != 2 || other[1] != "billing:read" {
+		t.Fatalf("expected org 2 bindings only, got %v err=%v", other, err)
+	}
+	// A cached scoped entry must not answer the global lookup.
+	if again, _ := resolver.ResolvePermissions(context.Background(), claims); len(again) != 1 {
+		t.Fatalf("expected global lookup to stay unscoped, got %v", again)
+	}
+	if userSvc.Calls() != 3 {
+		t.Fatalf("expected one lookup per scope, got %d", userSvc.Calls())
+	}
+}
@@ -1,6 +1,13 @@
 package service
 
-import "github.com/sandeepkv93/everything-backend-starter-kit/internal/domain"
+import (
+	"strings"
+
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/domain"
+)
+
+// PermissionWildcard matches any resource or any action in a grant.
+const PermissionWildcard = "*"
 
 type RBACService struct{}
 
@@ -12,6 +19,9 @@ func (s *RBACService) PermissionsFromRoles(roles []domain.Role) []string {
 		for _, p := range r.Permissions {
 			set[p.Resource+":"+p.Action] = struct{}{}
 		}
+		for _, p := range r.InheritedPermissions {
+			set[p.Resource+":"+p.Action] = struct{}{}
+		}
 	}
 	out := make([]string, 0, len(set))
 	for k := range set {
@@ -22,9 +32,85 @@ func (s *RBACService) PermissionsFromRoles(roles []domain.Role) []string {
 
 func (s *RBACService) HasPermission(permissions []string, required string) bool {
 	for _, p := range permissions {
-		if p == required {
+		if PermissionGrants(p, required) {
 			return true
 		}
 	}
 	return false
 }
+
+// PermissionGrants reports whether grant covers required. Either half of a
+// grant may be "*", and a resource covers its dotted sub-resources, so
+// "billing:read" covers "billing.invoices:read". required may itself be a
+// wildcard grant, in which case grant must be at least as broad.
+func PermissionGrants(grant, required string) bool {
+	grantResource, grantAction, ok := splitPermission(grant)
+	if !ok {
+		return false
+	}
+	resource, action, ok := splitPermission(required)
+	if !ok {
+		return false
+	}
+	if grantAction != PermissionWildcard && grantAction != action {
+		return false
+	}
+	return grantResource == PermissionWildcard ||
+		grantResource == resource ||
+		strings.HasPrefix(resource, grantResource+".")
+}
+
+// grantsAll reports whether every entry of required is covered by perms.
+func grantsAll(perms, required []string) bool {
+	for _, r := range required {
+		covered := false
+		for _, p := range perms {
+			if PermissionGrants(p, r) {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			return false
+		}
+	}
+	return true
+}
+
+// intersectPermission returns the grant covered by both a and b, if any.
+func intersectPermission(a, b string) (string, bool) {
+	resourceA, actionA, ok := splitPermission(a)
+	if !ok {
+		return "", false
+	}
+	resourceB, actionB, ok := splitPermission(b)
+	if !ok {
+		return "", false
+	}
+	var resource, action string
+	switch {
+	case PermissionGrants(resourceA+":*", resourceB+":*"):
+		resource = resourceB
+	case PermissionGrants(resourceB+":*", resourceA+":*"):
+		resource = resourceA
+	default:
+		return "", false
+	}
+	switch {
+	case actionA == PermissionWildcard:
+		action = actionB
+	case actionB == PermissionWildcard || actionA == actionB:
+		action = actionA
+	default:
+		return "", false
+	}
+	return resource + ":" + action, true
+}
+
+func splitPermission(token string) (resource, action string, ok bool) {
+	resource, action, ok = strings.Cut(strings.ToLower(strings.TrimSpace(token)), ":")
+	if !ok || resource == "" || action == "" {
+		return "", "", false
+	}
+	return resource, action, true
+}
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/sandeepkv93/everything-backend-starter-kit/internal/domain"
@@ -17,3 +18,42 @@ func TestRBACPermissionEvaluation(t *testing.T) {
 		t.Fatal("did not expect users:write")
 	}
 }
+
+func TestPermissionGrantsWildcardsAndSubResources(t *testing.T) {
+	cases := []struct {
+		grant, required string
+		want            bool
+	}{
+		{"users:read", "users:read", true},
+		{"users:read", "users:write", false},
+		{"users:*", "users:write", true},
+		{"users:*", "roles:write", false},
+		{"*:read", "roles:read", true},
+		{"*:read", "roles:write", false},
+		{"*:*", "billing.invoices:delete", true},
+		{"billing:read", "billing.invoices:read", true},
+		{"billing:read", "billing.invoices.lines:read", true},
+		{"billing.invoices:read", "billing:read", false},
+		{"billing:read", "billingx:read", false},
+		{"users:*", "users:*", true},
+		{"users:read", "users:*", false},
+		{"*:*", "*:read", true},
+		{"users:read", "*:read", false},
+		{"users", "users:read", false},
+	}
+	for _, tc := range cases {
+		if got := PermissionGrants(tc.grant, tc.required); got != tc.want {
+			t.Fatalf("PermissionGrants(%q, %q) = %v, want %v", tc.grant, tc.required, got, tc.want)
+		}
+	}
+	if !NewRBACService().HasPermission([]string{"roles:read", "users:*"}, "users:delete") {
+		t.Fatal("expected users:* to grant users:delete")
+	}
+}
+
+func TestCapPermissionsNarrowsWildcards(t *testing.T) {
+	got := CapPermissions([]string{"users:*", "billing:read"}, []string{"users:read", "billing.invoices:*", "roles:read"})
+	if want := "billing.invoices:read,users:read"; strings.Join(got, ",") != want {
+		t.Fatalf("expected %s, got %v", want, got)
+	}
+}
@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/observability"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/repository"
+)
+
+const roleBindingExpirySweepBatch = 100
+
+// RoleBindingExpiryService deletes user role bindings whose expiry has
+// passed. Expired bindings already grant nothing; the sweep removes the rows,
+// drops cached permissions and records the expiry in the audit log.
+type RoleBindingExpiryService struct {
+	users    repository.UserRepository
+	resolver PermissionResolver
+	interval time.Duration
+}
+
+// NewRoleBindingExpiryService builds the sweeper. resolver may be nil when
+// the permission cache is disabled.
+func NewRoleBindingExpiryService(users repository.UserRepository, resolver PermissionResolver, interval time.Duration) *RoleBindingExpiryService {
+	return &RoleBindingExpiryService{users: users, resolver: resolver, interval: interval}
+}
+
+// SweepExpired removes one batch of bindings that expired at or before now
+// and returns how many were deleted. A failure on one binding does not stop
+// the rest of the batch.
+func (s *RoleBindingExpiryService) SweepExpired(ctx context.Context, now time.Time) (int, error) {
+	bindings, err := s.users.ListExpiredRoleBindings(now, roleBindingExpirySweepBatch)
+	if err != nil {
+		observability.RecordAdminRBACMutation(ctx, "user_role", "expire", "error")
+		return 0, err
+	}
+	deleted := 0
+	touched := make(map[uint]struct{})
+	var errs []error
+	for _, b := range bindings {
+		ok, err := s.users.DeleteExpiredRoleBinding(b.UserID, b.RoleID, now)
+		if err != nil {
+			observability.RecordAdminRBACMutation(ctx, "user_role", "expire", "error")
+			errs = append(errs, err)
+			continue
+		}
+		if !ok {
+			// Extended or removed since it was listed.
+			continue
+		}
+		deleted++
+		touched[b.UserID] = struct{}{}
+		observability.EmitSystemAudit(ctx, observability.AuditInput{
+			EventName:  "admin.user_roles.expire",
+			TargetType: "user",
+			TargetID:   strconv.FormatUint(uint64(b.UserID), 10),
+			Action:     "expire",
+			Outcome:    "success",
+			Reason:     "binding_expired",
+		}, "role_id", b.RoleID, "expires_at", b.ExpiresAt, "binding_reason", b.Reason)
+		observability.RecordAdminRBACMutation(ctx, "user_role", "expire", "success")
+	}
+	for userID := range touched {
+		if s.resolver == nil {
+			observability.RecordRBACPermissionCacheEvent(ctx, "invalidate_user_skipped")
+			continue
+		}
+		if err := s.resolver.InvalidateUser(ctx, userID); err != nil {
+			observability.RecordRBACPermissionCacheEvent(ctx, "invalidate_user_error")
+			errs = append(errs, err)
+			continue
+		}
+		observability.RecordRBACPermissionCacheEvent(ctx, "invalidate_user")
+	}
+	return deleted, errors.Join(errs...)
+}
+
+func (s *RoleBindingExpiryService) RunSweepLoop(ctx context.Context, logger *slog.Logger) {
+	interval := s.interval
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			deleted, err := s.SweepExpired(ctx, time.Now().UTC())
+			if err != nil && logger != nil {
+				logger.Warn("role binding expiry sweep failed", "error", err, "deleted", deleted)
+				continue
+			}
+			if deleted > 0 && logger != nil {
+				logger.Info("role binding expiry sweep removed bindings", "deleted", deleted)
+			}
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/domain"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/repository"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/security"
+)
+
+type recordingPermissionResolver struct {
+	invalidated []uint
+}
+
+func (r *recordingPermissionResolver) ResolvePermissions(context.Context, *security.Claims) ([]string, error) {
+	return nil, nil
+}
+
+func (r *recordingPermissionResolver) InvalidateUser(_ context.Context, userID uint) error {
+	r.invalidated = append(r.invalidated, userID)
+	return nil
+}
+
+func (r *recordingPermissionResolver) InvalidateAll(context.Context) error { return nil }
+
+func TestRoleBindingExpiryServiceSweepsExpiredBindings(t *testing.T) {
+	dsn := fmt.Sprintf("file:%s?mode=memory&cache=shared", strings.ReplaceAll(t.Name(), "/", "_"))
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	if err := db.AutoMigrate(&domain.Permission{}, &domain.Role{}, &domain.RoleParent{}, &domain.User{}, &domain.UserRole{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	role := domain.Role{Name: "oncall"}
+	if err := db.Create(&role).Error; err != nil {
+		t.Fatalf("create role: %v", err)
+	}
+	alice := domain.User{Email: "alice@example.com", Name: "Alice"}
+	bob := domain.User{Email: "bob@example.com", Name: "Bob"}
+	for _, u := range []*domain.User{&alice, &bob} {
+		if err := db.Create(u).Error; err != nil {
+			t.Fatalf("create user: %v", err)
+		}
+	}
+	now := time.Now().UTC()
+	lapsed, later := now.Add(-time.Minute), now.Add(time.Hour)
+	for _, b := range []domain.UserRole{
+		{UserID: alice.ID, RoleID: role.ID, ExpiresAt: &lapsed, Reason: "INC-42"},
+		{UserID: bob.ID, RoleID: role.ID, ExpiresAt: &later},
+	} {
+		if err := db.Create(&b).Error; err != nil {
+			t.Fatalf("create binding: %v", err)
+		}
+	}
+
+	resolver := &recordingPermissionResolver{}
+	svc := NewRoleBindingExpiryService(repository.NewUserRepository(db), resolver, time.Minute)
+	deleted, err := svc.SweepExpired(context.Background(), now)
+	if err != nil || deleted != 1 {
+		t.Fatalf("expected one binding swept, got deleted=%d err=%v", deleted, err)
+	}
+	if len(resolver.invalidated) != 1 || resolver.invalidated[0] != alice.ID {
+		t.Fatalf("expected permission cache invalidation for alice only, got %v", resolver.invalidated)
+	}
+	var remaining []domain.UserRole
+	if err := db.Find(&remaining).Error; err != nil {
+		t.Fatalf("list bindings: %v", err)
+	}
+	if len(remaining) != 1 || remaining[0].UserID != bob.ID {
+		t.Fatalf("expected bob's unexpired binding to remain, got %+v", remaining)
+	}
+	if deleted, err := svc.SweepExpired(context.Background(), now); err != nil || deleted != 0 {
+		t.Fatalf("expected second sweep to be a no-op, got deleted=%d err=%v", deleted, err)
+	}
+}
@@ -0,0 +1,322 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/config"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/domain"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/repository"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/security"
+)
+
+const serviceAccountClientIDPrefix = "sa_"
+
+var (
+	ErrServiceAccountsDisabled      = errors.New("service accounts are disabled")
+	ErrInvalidServiceAccountRequest = errors.New("invalid service account request")
+	ErrServiceAccountNotFound       = errors.New("service account not found")
+	ErrServiceAccountConflict       = errors.New("service account name already exists")
+	ErrInvalidClient                = errors.New("invalid client credentials")
+	ErrInvalidScope                 = errors.New("requested scope exceeds service account permissions")
+)
+
+type ServiceAccountInput struct {
+	Name        string
+	Description string
+	RoleIDs     []uint
+}
+
+// ServiceAccountUpdate is a partial update; nil fields are left unchanged.
+type ServiceAccountUpdate struct {
+	Name        *string
+	Description *string
+	Disabled    *bool
+	RoleIDs     *[]uint
+}
+
+type ServiceAccountView struct {
+	ID          uint       `json:"id"`
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	ClientID    string     `json:"client_id"`
+	Roles       []string   `json:"roles"`
+	Permissions []string   `json:"permissions"`
+	Disabled    bool       `json:"disabled"`
+	LastUsedAt  *time.Time `json:"last_used_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// ServiceAccountCredentials carries the plaintext client secret; it is only
+// returned at creation and rotation.
+type ServiceAccountCredentials struct {
+	ServiceAccountView
+	ClientSecret string `json:"client_secret"`
+}
+
+type ServiceAccountToken struct {
+	AccountID   uint   `json:"-"`
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int64  `json:"expires_in"`
+	Scope       string `json:"scope,omitempty"`
+}
+
+type ServiceAccountService struct {
+	cfg  *config.Config
+	repo repository.ServiceAccountRepository
+	rbac *RBACService
+	jwt  *security.JWTManager
+	now  func() time.Time
+}
+
+func NewServiceAccountService(cfg *config.Config, repo repository.ServiceAccountRepository, rbac *RBACService, jwt *security.JWTManager) *ServiceAccountService {
+	return &ServiceAccountService{cfg: cfg, repo: repo, rbac: rbac, jwt: jwt, now: time.Now}
+}
+
+func (s *ServiceAccountService) Create(input ServiceAccountInput) (*ServiceAccountCredentials, error) {
+	if !s.cfg.AuthServiceAccountsEnabled {
+		return nil, ErrServiceAccountsDisabled
+	}
+	name, ok := normalizeServiceAccountName(input.Name)
+	if !ok || len(strings.TrimSpace(input.Description)) > 255 {
+		return nil, ErrInvalidServiceAccountRequest
+	}
+	if err := s.ensureNameAvailable(name, 0); err != nil {
+		return nil, err
+	}
+	clientID, err := newServiceAccountClientID()
+	if err != nil {
+		return nil, err
+	}
+	secret, err := security.NewRandomString(32)
+	if err != nil {
+		return nil, err
+	}
+	account := &domain.ServiceAccount{
+		Name:        name,
+		Description: strings.TrimSpace(input.Description),
+		ClientID:    clientID,
+		SecretHash:  security.HashRefreshToken(secret, s.cfg.RefreshTokenPepper),
+	}
+	if err := s.repo.Create(account, input.RoleIDs); err != nil {
+		if errors.Is(err, repository.ErrRoleNotFound) {
+			return nil, ErrInvalidServiceAccountRequest
+		}
+		return nil, err
+	}
+	view, err := s.Get(account.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &ServiceAccountCredentials{ServiceAccountView: *view, ClientSecret: secret}, nil
+}
+
+func (s *ServiceAccountService) List() ([]ServiceAccountView, error) {
+	accounts, err := s.repo.List()
+	if err != nil {
+		return nil, err
+	}
+	views := make([]ServiceAccountView, 0, len(accounts))
+	for _, account := range accounts {
+		views = append(views, s.view(account))
+	}
+	return views, nil
+}
+
+func (s *ServiceAccountService) Get(id uint) (*ServiceAccountView, error) {
+	account, err := s.find(id)
+	if err != nil {
+		return nil, err
+	}
+	view := s.view(*account)
+	return &view, nil
+}
+
+func (s *ServiceAccountService) Update(id uint, update ServiceAccountUpdate) (*ServiceAccountView, error) {
+	account, err := s.find(id)
+	if err != nil {
+		return nil, err
+	}
+	if update.Name != nil {
+		name, ok := normalizeServiceAccountName(*update.Name)
+		if !ok {
+			return nil, ErrInvalidServiceAccountRequest
+		}
+		if err := s.ensureNameAvailable(name, id); err != nil {
+			return nil, err
+		}
+		account.Name = name
+	}
+	if update.Description != nil {
+		description := strings.TrimSpace(*update.Description)
+		if len(description) > 255 {
+			return nil, ErrInvalidServiceAccountRequest
+		}
+		account.Description = description
+	}
+	if update.Disabled != nil {
+		switch {
+		case *update.Disabled && account.DisabledAt == nil:
+			now := s.now().UTC()
+			account.DisabledAt = &now
+		case !*update.Disabled:
+			account.DisabledAt = nil
+		}
+	}
+	if err := s.repo.Update(account); err != nil {
+		return nil, s.mapNotFound(err)
+	}
+	if update.RoleIDs != nil {
+		if err := s.repo.SetRoles(id, *update.RoleIDs); err != nil {
+			if errors.Is(err, repository.ErrRoleNotFound) {
+				return nil, ErrInvalidServiceAccountRequest
+			}
+			return nil, err
+		}
+	}
+	return s.Get(id)
+}
+
+// RotateSecret replaces the client secret. Tokens already minted with the old
+// secret stay valid until they expire.
+func (s *ServiceAccountService) RotateSecret(id uint) (*ServiceAccountCredentials, error) {
+	account, err := s.find(id)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := security.NewRandomString(32)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.UpdateSecretHash(id, security.HashRefreshToken(secret, s.cfg.RefreshTokenPepper)); err != nil {
+		return nil, s.mapNotFound(err)
+	}
+	return &ServiceAccountCredentials{ServiceAccountView: s.view(*account), ClientSecret: secret}, nil
+}
+
+func (s *ServiceAccountService) Delete(id uint) error {
+	return s.mapNotFound(s.repo.DeleteByID(id))
+}
+
+// IssueToken implements the client_credentials grant. scope is the RFC 6749
+// space-delimited list; when empty the token carries every permission the
+// account's roles grant.
+func (s *ServiceAccountService) IssueToken(_ context.Context, clientID, clientSecret, scope string) (*ServiceAccountToken, error) {
+	if !s.cfg.AuthServiceAccountsEnabled {
+		return nil, ErrServiceAccountsDisabled
+	}
+	if clientID == "" || clientSecret == "" {
+		return nil, ErrInvalidClient
+	}
+	account, err := s.repo.FindByClientID(clientID)
+	if err != nil {
+		if errors.Is(err, repository.ErrServiceAccountNotFound) {
+			return nil, ErrInvalidClient
+		}
+		return nil, err
+	}
+	presented := security.HashRefreshToken(clientSecret, s.cfg.RefreshTokenPepper)
+	if subtle.ConstantTimeCompare([]byte(presented), []byte(account.SecretHash)) != 1 || account.DisabledAt != nil {
+		return nil, ErrInvalidClient
+	}
+
+	perms := s.rbac.PermissionsFromRoles(account.Roles)
+	granted := perms
+	if requested := strings.Fields(scope); len(requested) > 0 {
+		if !grantsAll(perms, requested) {
+			return nil, ErrInvalidScope
+		}
+		granted = CapPermissions(perms, requested)
+	}
+	sort.Strings(granted)
+	ttl := s.cfg.AuthServiceAccountTokenTTL
+	raw, err := s.jwt.SignServiceAccountToken(account.ID, account.ClientID, serviceAccountRoleNames(account.Roles), granted, ttl)
+	if err != nil {
+		return nil, err
+	}
+	_ = s.repo.TouchLastUsed(account.ID, s.now().UTC())
+	return &ServiceAccountToken{
+		AccountID:   account.ID,
+		AccessToken: raw,
+		TokenType:   "Bearer",
+		ExpiresIn:   int64(ttl.Seconds()),
+		Scope:       strings.Join(granted, " "),
+	}, nil
+}
+
+func (s *ServiceAccountService) find(id uint) (*domain.ServiceAccount, error) {
+	account, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, s.mapNotFound(err)
+	}
+	return account, nil
+}
+
+func (s *ServiceAccountService) ensureNameAvailable(name string, selfID uint) error {
+	existing, err := s.repo.FindByName(name)
+	if err != nil {
+		if errors.Is(err, repository.ErrServiceAccountNotFound) {
+			return nil
+		}
+		return err
+	}
+	if existing.ID != selfID {
+		return ErrServiceAccountConflict
+	}
+	return nil
+}
+
+func (s *ServiceAccountService) mapNotFound(err error) error {
+	if errors.Is(err, repository.ErrServiceAccountNotFound) {
+		return ErrServiceAccountNotFound
+	}
+	return err
+}
+
+func (s *ServiceAccountService) view(account domain.ServiceAccount) ServiceAccountView {
+	perms := s.rbac.PermissionsFromRoles(account.Roles)
+	sort.Strings(perms)
+	return ServiceAccountView{
+		ID:          account.ID,
+		Name:        account.Name,
+		Description: account.Description,
+		ClientID:    account.ClientID,
+		Roles:       serviceAccountRoleNames(account.Roles),
+		Permissions: perms,
+		Disabled:    account.DisabledAt != nil,
+		LastUsedAt:  account.LastUsedAt,
+		CreatedAt:   account.CreatedAt,
+	}
+}
+
+func serviceAccountRoleNames(roles []domain.Role) []string {
+	names := make([]string, 0, len(roles))
+	for _, role := range roles {
+		names = append(names, role.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func normalizeServiceAccountName(raw string) (string, bool) {
+	name := strings.ToLower(strings.TrimSpace(raw))
+	if name == "" || len(name) > 128 {
+		return "", false
+	}
+	return name, true
+}
+
+func newServiceAccountClientID() (string, error) {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return serviceAccountClientIDPrefix + hex.EncodeToString(b), nil
+}
@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/config"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/domain"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/repository"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/security"
+)
+
+func newServiceAccountServiceForTest(t *testing.T) (*ServiceAccountService, *security.JWTManager, *gorm.DB) {
+	t.Helper()
+	dsn := fmt.Sprintf("file:%s?mode=memory&cache=shared", strings.ReplaceAll(t.Name(), "/", "_"))
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	if err := db.AutoMigrate(&domain.Permission{}, &domain.Role{}, &domain.ServiceAccount{}); err != nil {
+		t.Fatalf("migrate service account models: %v", err)
+	}
+	jwtMgr := security.NewJWTManager("iss", "aud", "abcdefghijklmnopqrstuvwxyz123456", "abcdefghijklmnopqrstuvwxyz654321")
+	cfg := &config.Config{AuthServiceAccountsEnabled: true, AuthServiceAccountTokenTTL: 10 * time.Minute, RefreshTokenPepper: "pepper-1234567890"}
+	return NewServiceAccountService(cfg, repository.NewServiceAccountRepository(db), NewRBACService(), jwtMgr), jwtMgr, db
+}
+
+func TestServiceAccountServiceIssueTokenClientCredentials(t *testing.T) {
+	ctx := context.Background()
+	svc, jwtMgr, db := newServiceAccountServiceForTest(t)
+	role := domain.Role{Name: "exporter", Permissions: []domain.Permission{{Resource: "users", Action: "read"}, {Resource: "roles", Action: "read"}}}
+	if err := db.Create(&role).Error; err != nil {
+		t.Fatalf("create role: %v", err)
+	}
+
+	created, err := svc.Create(ServiceAccountInput{Name: " Nightly-Export ", RoleIDs: []uint{role.ID}})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if created.Name != "nightly-export" || created.ClientSecret == "" || strings.Join(created.Permissions, ",") != "roles:read,users:read" {
+		t.Fatalf("unexpected created account: %+v", created)
+	}
+
+	token, err := svc.IssueToken(ctx, created.ClientID, created.ClientSecret, "")
+	if err != nil {
+		t.Fatalf("issue token: %v", err)
+	}
+	claims, err := jwtMgr.ParseAccessToken(token.AccessToken)
+	if err != nil {
+		t.Fatalf("parse token: %v", err)
+	}
+	if !claims.IsServiceAccount() || claims.ClientID != created.ClientID || len(claims.Permissions) != 2 || token.ExpiresIn != 600 {
+		t.Fatalf("unexpected token claims=%+v token=%+v", claims, token)
+	}
+
+	scoped, err := svc.IssueToken(ctx, created.ClientID, created.ClientSecret, "users:read")
+	if err != nil || scoped.Scope != "users:read" {
+		t.Fatalf("expected down-scoped token, got %+v err=%v", scoped, err)
+	}
+	if _, err := svc.IssueToken(ctx, created.ClientID, created.ClientSecret, "users:write"); !errors.Is(err, ErrInvalidScope) {
+		t.Fatalf("expected invalid scope, got %v", err)
+	}
+	if _, err := svc.IssueToken(ctx, created.ClientID, "wrong", ""); !errors.Is(err, ErrInvalidClient) {
+		t.Fatalf("expected invalid client for wrong secret, got %v", err)
+	}
+	if _, err := svc.IssueToken(ctx, "sa_missing", created.ClientSecret, ""); !errors.Is(err, ErrInvalidClient) {
+		t.Fatalf("expected invalid client for unknown id, got %v", err)
+	}
+
+	rotated, err := svc.RotateSecret(created.ID)
+	if err != nil {
+		t.Fatalf("rotate: %v", err)
+	}
+	if _, err := svc.IssueToken(ctx, created.ClientID, created.ClientSecret, ""); !errors.Is(err, ErrInvalidClient) {
+		t.Fatalf("expected old secret to stop working, got %v", err)
+	}
+	disabled := true
+	if _, err := svc.Update(created.ID, ServiceAccountUpdate{Disabled: &disabled}); err != nil {
+		t.Fatalf("disable: %v", err)
+	}
+	if _, err := svc.IssueToken(ctx, created.ClientID, rotated.ClientSecret, ""); !errors.Is(err, ErrInvalidClient) {
+		t.Fatalf("expected disabled account to be rejected, got %v", err)
+	}
+}
+
+func TestServiceAccountServiceValidatesInput(t *testing.T) {
+	svc, _, _ := newServiceAccountServiceForTest(t)
+	if _, err := svc.Create(ServiceAccountInput{Name: "  "}); !errors.Is(err, ErrInvalidServiceAccountRequest) {
+		t.Fatalf("expected invalid name, got %v", err)
+	}
+	if _, err := svc.Create(ServiceAccountInput{Name: "job", RoleIDs: []uint{42}}); !errors.Is(err, ErrInvalidServiceAccountRequest) {
+		t.Fatalf("expected unknown role to be rejected, got %v", err)
+	}
+	if _, err := svc.Create(ServiceAccountInput{Name: "job"}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := svc.Create(ServiceAccountInput{Name: "JOB"}); !errors.Is(err, ErrServiceAccountConflict) {
+		t.Fatalf("expected name conflict, got %v", err)
+	}
+	if _, err := svc.Update(999, ServiceAccountUpdate{}); !errors.Is(err, ErrServiceAccountNotFound) {
+		t.Fatalf("expected not found, got %v", err)
+	}
+}
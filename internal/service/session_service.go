@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"net/http"
 	"time"
@@ -22,12 +23,14 @@ type SessionView struct {
 type SessionService struct {
 	sessionRepo repository.SessionRepository
 	pepper      string
+	revoker     *AccessTokenRevoker
 }
 
-func NewSessionService(sessionRepo repository.SessionRepository, pepper string) *SessionService {
+func NewSessionService(sessionRepo repository.SessionRepository, pepper string, revoker *AccessTokenRevoker) *SessionService {
 	return &SessionService{
 		sessionRepo: sessionRepo,
 		pepper:      pepper,
+		revoker:     revoker,
 	}
 }
 
@@ -85,9 +88,26 @@ func (s *SessionService) RevokeSession(userID, sessionID uint) (string, error) {
 	if !changed {
 		return "already_revoked", nil
 	}
+	if s.revoker != nil {
+		session, err := s.sessionRepo.FindByIDForUser(userID, sessionID)
+		if err != nil {
+			return "", err
+		}
+		if err := s.revoker.RevokeFamily(context.Background(), userID, getString(session.FamilyID), "user_session_revoked"); err != nil {
+			return "", err
+		}
+	}
 	return "revoked", nil
 }
 
 func (s *SessionService) RevokeOtherSessions(userID, currentSessionID uint) (int64, error) {
-	return s.sessionRepo.RevokeOthersByUser(userID, currentSessionID, "user_revoke_others")
+	count, err := s.sessionRepo.RevokeOthersByUser(userID, currentSessionID, "user_revoke_others")
+	if err != nil || s.revoker == nil {
+		return count, err
+	}
+	current, err := s.sessionRepo.FindByIDForUser(userID, currentSessionID)
+	if err != nil {
+		return count, err
+	}
+	return count, s.revoker.RevokeOtherFamilies(context.Background(), userID, getString(current.FamilyID), "user_revoke_others")
 }
@@ -19,6 +19,7 @@ type stubSessionRepository struct {
 	findByHashFn                 func(hash string) (*domain.Session, error)
 	revokeByIDForUserFn          func(userID, sessionID uint, reason string) (bool, error)
 	revokeOthersByUserFn         func(userID, keepSessionID uint, reason string) (int64, error)
+	listIssuedSinceFn            func(userID uint, since time.Time) ([]domain.Session, error)
 }
 
 func (s *stubSessionRepository) Create(_ *domain.Session) error { return errors.New("not implemented") }
@@ -43,12 +44,21 @@ func (s *stubSessionRepository) ListActiveByUserID(userID uint) ([]domain.Sessio
 	}
 	return s.listActiveByUserIDFn(userID)
 }
+func (s *stubSessionRepository) ListIssuedSince(userID uint, since time.Time) ([]domain.Session, error) {
+	if s.listIssuedSinceFn == nil {
+		return nil, errors.New("not implemented")
+	}
+	return s.listIssuedSinceFn(userID, since)
+}
 func (s *stubSessionRepository) RotateSession(_ string, _ *domain.Session) (*domain.Session, error) {
 	return nil, errors.New("not implemented")
 }
 func (s *stubSessionRepository) UpdateTokenLineageByHash(_, _, _ string) error {
 	return errors.New("not implemented")
 }
+func (s *stubSessionRepository) MarkReauthenticated(_ uint, _ string, _ time.Time) (*domain.Session, error) {
+	return nil, errors.New("not implemented")
+}
 func (s *stubSessionRepository) MarkReuseDetectedByHash(_ string) error {
 	return errors.New("not implemented")
 }
@@ -90,7 +100,7 @@ func TestSessionServiceListActiveSessions(t *testing.T) {
 			}, nil
 		},
 	}
-	svc := NewSessionService(repo, "pepper")
+	svc := NewSessionService(repo, "pepper", nil)
 
 	views, err := svc.ListActiveSessions(42, 11)
 	if err != nil {
@@ -117,7 +127,7 @@ func TestSessionServiceListActiveSessionsRepoError(t *testing.T) {
 			return nil, expected
 		},
 	}
-	svc := NewSessionService(repo, "pepper")
+	svc := NewSessionService(repo, "pepper", nil)
 
 	_, err := svc.ListActiveSessions(1, 0)
 	if !errors.Is(err, expected) {
@@ -135,7 +145,7 @@ func TestSessionServiceResolveCurrentSessionID(t *testing.T) {
 				return &domain.Session{ID: 77}, nil
 			},
 		}
-		svc := NewSessionService(repo, "pepper")
+		svc := NewSessionService(repo, "pepper", nil)
 		req := httptest.NewRequest("GET", "/", nil)
 
 		id, err := svc.ResolveCurrentSessionID(req, &security.Claims{
@@ -156,7 +166,7 @@ func TestSessionServiceResolveCurrentSessionID(t *testing.T) {
 				return nil, expected
 			},
 		}
-		svc := NewSessionService(repo, "pepper")
+		svc := NewSessionService(repo, "pepper", nil)
 		req := httptest.NewRequest("GET", "/", nil)
 
 		_, err := svc.ResolveCurrentSessionID(req, &security.Claims{
@@ -183,7 +193,7 @@ func TestSessionServiceResolveCurrentSessionID(t *testing.T) {
 				return &domain.Session{ID: 42, UserID: 7, ExpiresAt: time.Now().Add(time.Hour)}, nil
 			},
 		}
-		svc := NewSessionService(repo, pepper)
+		svc := NewSessionService(repo, pepper, nil)
 		req := httptest.NewRequest("GET", "/", nil)
 		req.AddCookie(&http.Cookie{Name: "refresh_token", Value: refreshToken})
 
@@ -200,7 +210,7 @@ func TestSessionServiceResolveCurrentSessionID(t *testing.T) {
 
 	t.Run("missing cookie returns not found", func(t *testing.T) {
 		repo := &stubSessionRepository{}
-		svc := NewSessionService(repo, "pepper")
+		svc := NewSessionService(repo, "pepper", nil)
 		req := httptest.NewRequest("GET", "/", nil)
 
 		_, err := svc.ResolveCurrentSessionID(req, nil, 7)
@@ -216,7 +226,7 @@ func TestSessionServiceResolveCurrentSessionID(t *testing.T) {
 				return nil, expected
 			},
 		}
-		svc := NewSessionService(repo, "pepper")
+		svc := NewSessionService(repo, "pepper", nil)
 		req := httptest.NewRequest("GET", "/", nil)
 		req.AddCookie(&http.Cookie{Name: "refresh_token", Value: "refresh-token"})
 
@@ -243,7 +253,7 @@ func TestSessionServiceResolveCurrentSessionID(t *testing.T) {
 						return tc.session, nil
 					},
 				}
-				svc := NewSessionService(repo, "pepper")
+				svc := NewSessionService(repo, "pepper", nil)
 				req := httptest.NewRequest("GET", "/", nil)
 				req.AddCookie(&http.Cookie{Name: "refresh_token", Value: "refresh-token"})
 
@@ -262,7 +272,7 @@ func TestSessionServiceRevokeSession(t *testing.T) {
 		repo := &stubSessionRepository{
 			revokeByIDForUserFn: func(_, _ uint, _ string) (bool, error) { return false, expected },
 		}
-		svc := NewSessionService(repo, "pepper")
+		svc := NewSessionService(repo, "pepper", nil)
 
 		_, err := svc.RevokeSession(1, 2)
 		if !errors.Is(err, expected) {
@@ -279,7 +289,7 @@ func TestSessionServiceRevokeSession(t *testing.T) {
 				return false, nil
 			},
 		}
-		svc := NewSessionService(repo, "pepper")
+		svc := NewSessionService(repo, "pepper", nil)
 
 		status, err := svc.RevokeSession(1, 2)
 		if err != nil {
@@ -294,7 +304,7 @@ func TestSessionServiceRevokeSession(t *testing.T) {
 		repo := &stubSessionRepository{
 			revokeByIDForUserFn: func(_, _ uint, _ string) (bool, error) { return true, nil },
 		}
-		svc := NewSessionService(repo, "pepper")
+		svc := NewSessionService(repo, "pepper", nil)
 
 		status, err := svc.RevokeSession(1, 2)
 		if err != nil {
@@ -318,7 +328,7 @@ func TestSessionServiceRevokeOtherSessions(t *testing.T) {
 			return 4, nil
 		},
 	}
-	svc := NewSessionService(repo, "pepper")
+	svc := NewSessionService(repo, "pepper", nil)
 
 	n, err := svc.RevokeOtherSessions(9, 3)
 	if err != nil {
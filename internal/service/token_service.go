@@ -18,6 +18,7 @@ type TokenService struct {
 	pepper      string
 	accessTTL   time.Duration
 	refreshTTL  time.Duration
+	revoker     *AccessTokenRevoker
 }
 
 var (
@@ -25,12 +26,13 @@ var (
 	ErrRefreshTokenReuseDetected = errors.New("refresh token reuse detected")
 )
 
-func NewTokenService(jwtMgr *security.JWTManager, sessionRepo repository.SessionRepository, pepper string, accessTTL, refreshTTL time.Duration) *TokenService {
-	return &TokenService{jwtMgr: jwtMgr, sessionRepo: sessionRepo, pepper: pepper, accessTTL: accessTTL, refreshTTL: refreshTTL}
+func NewTokenService(jwtMgr *security.JWTManager, sessionRepo repository.SessionRepository, pepper string, accessTTL, refreshTTL time.Duration, revoker *AccessTokenRevoker) *TokenService {
+	return &TokenService{jwtMgr: jwtMgr, sessionRepo: sessionRepo, pepper: pepper, accessTTL: accessTTL, refreshTTL: refreshTTL, revoker: revoker}
 }
 
 func (s *TokenService) Issue(user *domain.User, permissions []string, ua, ip string) (access string, refresh string, csrf string, err error) {
-	access, refresh, refreshClaims, csrf, err := s.mintTokenPair(user, permissions)
+	authTime := time.Now().UTC()
+	access, refresh, refreshClaims, csrf, err := s.mintTokenPair(user, permissions, authTime)
 	if err != nil {
 		return "", "", "", err
 	}
@@ -46,6 +48,8 @@ func (s *TokenService) Issue(user *domain.User, permissions []string, ua, ip str
 		UserAgent:        ua,
 		IP:               ip,
 		ExpiresAt:        time.Now().Add(s.refreshTTL),
+		AuthTime:         &authTime,
+		AccessIssuedAt:   &authTime,
 	}); err != nil {
 		return "", "", "", err
 	}
@@ -107,6 +111,7 @@ func (s *TokenService) Rotate(refreshToken string, userFetcher func(id uint) (*d
 			_ = s.sessionRepo.MarkReuseDetectedByHash(hash)
 			if familyID != "" {
 				_, _ = s.sessionRepo.RevokeByFamilyID(familyID, "reuse_detected")
+				_ = s.revoker.RevokeFamily(context.Background(), userID, familyID, "reuse_detected")
 			}
 			observability.RecordRefreshSecurityEvent(context.Background(), "reuse_detected")
 			return "", "", "", 0, ErrRefreshTokenReuseDetected
@@ -118,11 +123,22 @@ func (s *TokenService) Rotate(refreshToken string, userFetcher func(id uint) (*d
 	if err != nil {
 		return "", "", "", 0, err
 	}
-	access, newRefresh, newClaims, csrf, err := s.mintTokenPair(user, perms)
+	if !user.IsActive() {
+		_, _ = s.sessionRepo.RevokeByFamilyID(familyID, "account_inactive")
+		observability.RecordRefreshSecurityEvent(context.Background(), "account_inactive")
+		return "", "", "", 0, ErrAccountInactive
+	}
+	// Rotation is not a fresh authentication: the family keeps its auth_time.
+	var authTime time.Time
+	if session.AuthTime != nil {
+		authTime = *session.AuthTime
+	}
+	access, newRefresh, newClaims, csrf, err := s.mintTokenPair(user, perms, authTime)
 	if err != nil {
 		return "", "", "", 0, err
 	}
 	newHash := security.HashRefreshToken(newRefresh, s.pepper)
+	issuedAt := time.Now().UTC()
 	_, err = s.sessionRepo.RotateSession(hash, &domain.Session{
 		UserID:           userID,
 		RefreshTokenHash: newHash,
@@ -132,6 +148,8 @@ func (s *TokenService) Rotate(refreshToken string, userFetcher func(id uint) (*d
 		UserAgent:        ua,
 		IP:               ip,
 		ExpiresAt:        time.Now().Add(s.refreshTTL),
+		AuthTime:         session.AuthTime,
+		AccessIssuedAt:   &issuedAt,
 	})
 	if err != nil {
 		if errors.Is(err, repository.ErrSessionNotFound) {
@@ -144,11 +162,108 @@ func (s *TokenService) Rotate(refreshToken string, userFetcher func(id uint) (*d
 	return access, newRefresh, csrf, userID, nil
 }
 
+// IssueImpersonation records a session for user that actorID is driving and
+// returns its access token. The session has no usable refresh token, so it
+// ends when the token expires; ttl is capped at the access TTL so that
+// revoking the user's sessions also reaches the impersonation token.
+func (s *TokenService) IssueImpersonation(user *domain.User, permissions []string, actorID uint, ttl time.Duration, ua, ip string) (string, time.Time, error) {
+	if ttl <= 0 || ttl > s.accessTTL {
+		ttl = s.accessTTL
+	}
+	roles := make([]string, 0, len(user.Roles))
+	for _, r := range user.Roles {
+		roles = append(roles, r.Name)
+	}
+	// The refresh token is only minted for a unique session hash and jti; it
+	// is never handed out.
+	refresh, err := s.jwtMgr.SignRefreshToken(user.ID, ttl)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	refreshClaims, err := s.jwtMgr.ParseRefreshToken(refresh)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	tokenID := refreshClaims.ID
+	access, err := s.jwtMgr.SignImpersonationToken(user.ID, actorID, roles, permissions, ttl, tokenID)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	issuedAt := time.Now().UTC()
+	expiresAt := issuedAt.Add(ttl)
+	if err := s.sessionRepo.Create(&domain.Session{
+		UserID:           user.ID,
+		RefreshTokenHash: security.HashRefreshToken(refresh, s.pepper),
+		TokenID:          ptr(tokenID),
+		FamilyID:         ptr(tokenID),
+		UserAgent:        ua,
+		IP:               ip,
+		ExpiresAt:        expiresAt,
+		ImpersonatorID:   &actorID,
+		AccessIssuedAt:   &issuedAt,
+	}); err != nil {
+		return "", time.Time{}, err
+	}
+	return access, expiresAt, nil
+}
+
+// EndImpersonation revokes the impersonation session behind tokenID and
+// denylists its access token.
+func (s *TokenService) EndImpersonation(userID uint, tokenID string) error {
+	session, err := s.sessionRepo.FindActiveByTokenIDForUser(userID, tokenID)
+	if err != nil && !errors.Is(err, repository.ErrSessionNotFound) {
+		return err
+	}
+	if session != nil {
+		if _, err := s.sessionRepo.RevokeByIDForUser(userID, session.ID, "impersonation_ended"); err != nil {
+			return err
+		}
+	}
+	return s.revoker.RevokeFamily(context.Background(), userID, tokenID, "impersonation_ended")
+}
+
+// SessionFamilyID returns the refresh-token family behind an access token's
+// jti. Reauthentication targets the family because the session row changes
+// on every refresh.
+func (s *TokenService) SessionFamilyID(userID uint, tokenID string) (string, error) {
+	session, err := s.sessionRepo.FindActiveByTokenIDForUser(userID, tokenID)
+	if err != nil {
+		return "", err
+	}
+	if session.ImpersonatorID != nil || getString(session.FamilyID) == "" {
+		return "", repository.ErrSessionNotFound
+	}
+	return *session.FamilyID, nil
+}
+
+// Reauthenticate records a fresh authentication on the live session of
+// familyID and returns an access token carrying the new auth_time. The
+// refresh token is untouched, so no session is created or rotated.
+func (s *TokenService) Reauthenticate(user *domain.User, permissions []string, familyID string) (string, time.Time, error) {
+	authTime := time.Now().UTC()
+	session, err := s.sessionRepo.MarkReauthenticated(user.ID, familyID, authTime)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	roles := make([]string, 0, len(user.Roles))
+	for _, r := range user.Roles {
+		roles = append(roles, r.Name)
+	}
+	access, err := s.jwtMgr.SignAccessTokenWithJTI(user.ID, roles, permissions, s.accessTTL, getString(session.TokenID), authTime)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return access, authTime, nil
+}
+
 func (s *TokenService) RevokeAll(userID uint, reason string) error {
-	return s.sessionRepo.RevokeByUserID(userID, reason)
+	if err := s.sessionRepo.RevokeByUserID(userID, reason); err != nil {
+		return err
+	}
+	return s.revoker.RevokeUser(context.Background(), userID, reason)
 }
 
-func (s *TokenService) mintTokenPair(user *domain.User, permissions []string) (access string, refresh string, refreshClaims *security.Claims, csrf string, err error) {
+func (s *TokenService) mintTokenPair(user *domain.User, permissions []string, authTime time.Time) (access string, refresh string, refreshClaims *security.Claims, csrf string, err error) {
 	roles := make([]string, 0, len(user.Roles))
 	for _, r := range user.Roles {
 		roles = append(roles, r.Name)
@@ -161,7 +276,7 @@ func (s *TokenService) mintTokenPair(user *domain.User, permissions []string) (a
 	if err != nil {
 		return "", "", nil, "", err
 	}
-	access, err = s.jwtMgr.SignAccessTokenWithJTI(user.ID, roles, permissions, s.accessTTL, refreshClaims.ID)
+	access, err = s.jwtMgr.SignAccessTokenWithJTI(user.ID, roles, permissions, s.accessTTL, refreshClaims.ID, authTime)
 	if err != nil {
 		return "", "", nil, "", err
 	}
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"sync"
 	"testing"
@@ -33,7 +34,11 @@ func (r *inMemorySessionRepo) Create(s *domain.Session) error {
 	defer r.mu.Unlock()
 	copy := *s
 	copy.ID = r.nextID
+	if copy.CreatedAt.IsZero() {
+		copy.CreatedAt = time.Now()
+	}
 	r.nextID++
+	s.ID = copy.ID
 	r.byHash[copy.RefreshTokenHash] = &copy
 	r.byID[copy.ID] = &copy
 	if copy.TokenID != nil {
@@ -88,6 +93,19 @@ func (r *inMemorySessionRepo) ListActiveByUserID(userID uint) ([]domain.Session,
 	return out, nil
 }
 
+func (r *inMemorySessionRepo) ListIssuedSince(userID uint, since time.Time) ([]domain.Session, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make([]domain.Session, 0)
+	for _, s := range r.byID {
+		if s.UserID != userID || !s.AccessTokenIssuedAt().After(since) {
+			continue
+		}
+		out = append(out, *s)
+	}
+	return out, nil
+}
+
 func (r *inMemorySessionRepo) RotateSession(oldHash string, newSession *domain.Session) (*domain.Session, error) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
@@ -102,6 +120,7 @@ func (r *inMemorySessionRepo) RotateSession(oldHash string, newSession *domain.S
 
 	copy := *newSession
 	copy.ID = r.nextID
+	copy.CreatedAt = now
 	r.nextID++
 	r.byHash[copy.RefreshTokenHash] = &copy
 	r.byID[copy.ID] = &copy
@@ -129,6 +148,21 @@ func (r *inMemorySessionRepo) UpdateTokenLineageByHash(hash, tokenID, familyID s
 	return nil
 }
 
+func (r *inMemorySessionRepo) MarkReauthenticated(userID uint, familyID string, at time.Time) (*domain.Session, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, s := range r.byID {
+		if s.UserID != userID || s.FamilyID == nil || *s.FamilyID != familyID || s.RevokedAt != nil || s.ExpiresAt.Before(time.Now()) {
+			continue
+		}
+		s.AuthTime = &at
+		s.AccessIssuedAt = &at
+		cp := *s
+		return &cp, nil
+	}
+	return nil, repository.ErrSessionNotFound
+}
+
 func (r *inMemorySessionRepo) MarkReuseDetectedByHash(hash string) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
@@ -347,6 +381,126 @@ func TestTokenRotateBackfillsLegacyLineage(t *testing.T) {
 	}
 }
 
+func TestTokenRevokeAllDenylistsLiveAccessTokens(t *testing.T) {
+	ctx := context.Background()
+	repo := newInMemorySessionRepo()
+	svc := newTestTokenService(repo)
+	user := testUser()
+
+	accessA, refreshA, _, err := svc.Issue(user, []string{"users:read"}, "ua", "127.0.0.1")
+	if err != nil {
+		t.Fatalf("issue: %v", err)
+	}
+	accessB, _, _, _, err := svc.Rotate(refreshA, testFetcher(user), "ua", "127.0.0.1")
+	if err != nil {
+		t.Fatalf("rotate: %v", err)
+	}
+	if err := svc.RevokeAll(user.ID, "logout"); err != nil {
+		t.Fatalf("revoke all: %v", err)
+	}
+
+	for _, access := range []string{accessA, accessB} {
+		claims, err := svc.jwtMgr.ParseAccessToken(access)
+		if err != nil {
+			t.Fatalf("parse access: %v", err)
+		}
+		if revoked, err := svc.revoker.store.IsRevoked(ctx, claims.ID); err != nil || !revoked {
+			t.Fatalf("expected access token %s to be denylisted, got %v err=%v", claims.ID, revoked, err)
+		}
+	}
+}
+
+func TestTokenRevokeAllDenylistsReauthTokenOnOldSession(t *testing.T) {
+	ctx := context.Background()
+	repo := newInMemorySessionRepo()
+	svc := newTestTokenService(repo)
+	user := testUser()
+
+	_, refresh, _, err := svc.Issue(user, []string{"users:read"}, "ua", "127.0.0.1")
+	if err != nil {
+		t.Fatalf("issue: %v", err)
+	}
+	// Age the session well past the access TTL, as for a long-lived login.
+	session := repo.byHash[security.HashRefreshToken(refresh, svc.pepper)]
+	old := time.Now().Add(-24 * time.Hour)
+	session.CreatedAt = old
+	session.AccessIssuedAt = &old
+
+	access, _, err := svc.Reauthenticate(user, []string{"users:read"}, *session.FamilyID)
+	if err != nil {
+		t.Fatalf("reauthenticate: %v", err)
+	}
+	if err := svc.RevokeAll(user.ID, "logout"); err != nil {
+		t.Fatalf("revoke all: %v", err)
+	}
+	claims, err := svc.jwtMgr.ParseAccessToken(access)
+	if err != nil {
+		t.Fatalf("parse access: %v", err)
+	}
+	if revoked, err := svc.revoker.store.IsRevoked(ctx, claims.ID); err != nil || !revoked {
+		t.Fatalf("expected stepped-up access token to be denylisted, got %v err=%v", revoked, err)
+	}
+}
+
+func TestTokenReauthenticateRefreshesAuthTimeAcrossRotation(t *testing.T) {
+	repo := newInMemorySessionRepo()
+	svc := newTestTokenService(repo)
+	user := testUser()
+
+	_, refreshA, _, err := svc.Issue(user, []string{"users:read"}, "ua", "127.0.0.1")
+	if err != nil {
+		t.Fatalf("issue: %v", err)
+	}
+	hashA := security.HashRefreshToken(refreshA, svc.pepper)
+	if sA, err := repo.FindByHash(hashA); err != nil || sA.AuthTime == nil {
+		t.Fatalf("expected issued session to record auth_time, err=%v", err)
+	}
+	stale := time.Now().Add(-time.Hour).UTC()
+	repo.byHash[hashA].AuthTime = &stale
+
+	accessB, refreshB, _, _, err := svc.Rotate(refreshA, testFetcher(user), "ua", "127.0.0.1")
+	if err != nil {
+		t.Fatalf("rotate: %v", err)
+	}
+	claimsB, err := svc.jwtMgr.ParseAccessToken(accessB)
+	if err != nil {
+		t.Fatalf("parse rotated access: %v", err)
+	}
+	if got, ok := claimsB.AuthenticatedAt(); !ok || got.Unix() != stale.Unix() {
+		t.Fatalf("expected rotation to keep auth_time %v, got %v", stale, got)
+	}
+
+	familyID, err := svc.SessionFamilyID(user.ID, claimsB.ID)
+	if err != nil || familyID != *repo.byHash[hashA].FamilyID {
+		t.Fatalf("expected the issued family, got %q err=%v", familyID, err)
+	}
+	accessC, authTime, err := svc.Reauthenticate(user, []string{"users:read"}, familyID)
+	if err != nil {
+		t.Fatalf("reauthenticate: %v", err)
+	}
+	if time.Since(authTime) > time.Minute {
+		t.Fatalf("expected a fresh auth_time, got %v", authTime)
+	}
+	claimsC, err := svc.jwtMgr.ParseAccessToken(accessC)
+	if err != nil {
+		t.Fatalf("parse reauth access: %v", err)
+	}
+	if claimsC.ID != claimsB.ID {
+		t.Fatalf("expected reauth to keep jti %q, got %q", claimsB.ID, claimsC.ID)
+	}
+	sB, err := repo.FindByHash(security.HashRefreshToken(refreshB, svc.pepper))
+	if err != nil || sB.AuthTime == nil || !sB.AuthTime.Equal(authTime) {
+		t.Fatalf("expected live session auth_time %v, got %+v err=%v", authTime, sB, err)
+	}
+	if sA, _ := repo.FindByHash(hashA); !sA.AuthTime.Equal(stale) {
+		t.Fatal("expected the rotated-out session to keep its old auth_time")
+	}
+
+	if _, _, err := svc.Reauthenticate(user, nil, "unknown-family"); !errors.Is(err, repository.ErrSessionNotFound) {
+		t.Fatalf("expected ErrSessionNotFound for an unknown family, got %v", err)
+	}
+}
+
 func newTestTokenService(repo repository.SessionRepository) *TokenService {
 	jwtMgr := security.NewJWTManager(
 		"iss",
@@ -354,7 +508,8 @@ func newTestTokenService(repo repository.SessionRepository) *TokenService {
 		"abcdefghijklmnopqrstuvwxyz123456",
 		"abcdefghijklmnopqrstuvwxyz654321",
 	)
-	return NewTokenService(jwtMgr, repo, "pepper-1234567890", 15*time.Minute, 24*time.Hour)
+	revoker := NewAccessTokenRevoker(NewInMemoryAccessTokenRevocationStore(), repo, 15*time.Minute)
+	return NewTokenService(jwtMgr, repo, "pepper-1234567890", 15*time.Minute, 24*time.Hour, revoker)
 }
 
 func testUser() *domain.User {
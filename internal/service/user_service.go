@@ -22,6 +22,10 @@ func (s *UserService) GetByID(id uint) (*domain.User, []string, error) {
 	return u, s.rbac.PermissionsFromRoles(u.Roles), nil
 }
 
+func (s *UserService) FindByEmail(email string) (*domain.User, error) {
+	return s.userRepo.FindByEmail(email)
+}
+
 func (s *UserService) List() ([]domain.User, error) {
 	return s.userRepo.List()
 }
@@ -30,6 +34,10 @@ func (s *UserService) SetRoles(userID uint, roleIDs []uint) error {
 	return s.userRepo.SetRoles(userID, roleIDs)
 }
 
+func (s *UserService) SetRoleBindings(userID uint, bindings []domain.UserRole) error {
+	return s.userRepo.SetRoleBindings(userID, bindings)
+}
+
 func (s *UserService) AddRole(userID, roleID uint) error {
 	return s.userRepo.AddRole(userID, roleID)
 }
@@ -3,16 +3,18 @@ package service
 import (
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/sandeepkv93/everything-backend-starter-kit/internal/domain"
 	"github.com/sandeepkv93/everything-backend-starter-kit/internal/repository"
 )
 
 type stubUserRepository struct {
-	findByIDFn func(id uint) (*domain.User, error)
-	listFn     func() ([]domain.User, error)
-	setRolesFn func(userID uint, roleIDs []uint) error
-	addRoleFn  func(userID, roleID uint) error
+	findByIDFn        func(id uint) (*domain.User, error)
+	listFn            func() ([]domain.User, error)
+	setRolesFn        func(userID uint, roleIDs []uint) error
+	setRoleBindingsFn func(userID uint, bindings []domain.UserRole) error
+	addRoleFn         func(userID, roleID uint) error
 }
 
 func (s *stubUserRepository) FindByID(id uint) (*domain.User, error) {
@@ -30,6 +32,30 @@ func (s *stubUserRepository) Create(_ *domain.User) error { return errors.New("n
 
 func (s *stubUserRepository) Update(_ *domain.User) error { return errors.New("not implemented") }
 
+func (s *stubUserRepository) UpdateStatus(_ uint, _ string) error {
+	return errors.New("not implemented")
+}
+
+func (s *stubUserRepository) UpdateEmail(_ uint, _ string) error {
+	return errors.New("not implemented")
+}
+
+func (s *stubUserRepository) ScheduleDeletion(_ uint, _ time.Time) error {
+	return errors.New("not implemented")
+}
+
+func (s *stubUserRepository) CancelDeletion(_ uint) error {
+	return errors.New("not implemented")
+}
+
+func (s *stubUserRepository) ListDueForDeletion(_ time.Time, _ int) ([]uint, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (s *stubUserRepository) Erase(_ uint) error {
+	return errors.New("not implemented")
+}
+
 func (s *stubUserRepository) List() ([]domain.User, error) {
 	if s.listFn == nil {
 		return nil, errors.New("not implemented")
@@ -48,6 +74,21 @@ func (s *stubUserRepository) SetRoles(userID uint, roleIDs []uint) error {
 	return s.setRolesFn(userID, roleIDs)
 }
 
+func (s *stubUserRepository) SetRoleBindings(userID uint, bindings []domain.UserRole) error {
+	if s.setRoleBindingsFn == nil {
+		return errors.New("not implemented")
+	}
+	return s.setRoleBindingsFn(userID, bindings)
+}
+
+func (s *stubUserRepository) ListExpiredRoleBindings(_ time.Time, _ int) ([]domain.UserRole, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (s *stubUserRepository) DeleteExpiredRoleBinding(_, _ uint, _ time.Time) (bool, error) {
+	return false, errors.New("not implemented")
+}
+
 func (s *stubUserRepository) AddRole(userID, roleID uint) error {
 	if s.addRoleFn == nil {
 		return errors.New("not implemented")
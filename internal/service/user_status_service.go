@@ -0,0 +1,75 @@
+package service
+
+import (
+	"errors"
+
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/domain"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/repository"
+)
+
+var ErrInvalidUserStatusTransition = errors.New("invalid user status transition")
+
+// UserStatusService moves users through the account lifecycle. Login, refresh
+// and API key authentication reject any user that is not active.
+type UserStatusService struct {
+	userRepo repository.UserRepository
+	tokenSvc *TokenService
+}
+
+func NewUserStatusService(userRepo repository.UserRepository, tokenSvc *TokenService) *UserStatusService {
+	return &UserStatusService{userRepo: userRepo, tokenSvc: tokenSvc}
+}
+
+// Suspend blocks the user and revokes every session, which also denylists
+// the access tokens still in flight. Suspending a suspended user re-runs the
+// revocation; disabled accounts and accounts being deleted cannot be
+// suspended.
+func (s *UserStatusService) Suspend(userID uint) (*domain.User, string, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, "", err
+	}
+	previous := user.Status
+	switch previous {
+	case domain.UserStatusDisabled, domain.UserStatusPendingDeletion, domain.UserStatusDeleted:
+		return nil, previous, ErrInvalidUserStatusTransition
+	}
+	if previous != domain.UserStatusSuspended {
+		if err := s.userRepo.UpdateStatus(userID, domain.UserStatusSuspended); err != nil {
+			return nil, previous, err
+		}
+		user.Status = domain.UserStatusSuspended
+	}
+	if err := s.tokenSvc.RevokeAll(userID, "account_suspended"); err != nil {
+		return nil, previous, err
+	}
+	return user, previous, nil
+}
+
+// Reactivate returns a suspended, disabled or pending user to active and
+// cancels a pending self-deletion. Erased accounts cannot be reactivated.
+func (s *UserStatusService) Reactivate(userID uint) (*domain.User, string, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, "", err
+	}
+	previous := user.Status
+	if user.IsActive() {
+		return user, previous, nil
+	}
+	switch previous {
+	case domain.UserStatusSuspended, domain.UserStatusDisabled, domain.UserStatusPending:
+		if err := s.userRepo.UpdateStatus(userID, domain.UserStatusActive); err != nil {
+			return nil, previous, err
+		}
+	case domain.UserStatusPendingDeletion:
+		if err := s.userRepo.CancelDeletion(userID); err != nil {
+			return nil, previous, err
+		}
+		user.DeletionScheduledAt = nil
+	default:
+		return nil, previous, ErrInvalidUserStatusTransition
+	}
+	user.Status = domain.UserStatusActive
+	return user, previous, nil
+}
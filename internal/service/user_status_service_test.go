@@ -0,0 +1,80 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/domain"
+	"gorm.io/gorm"
+)
+
+func TestUserStatusServiceBlocksLoginAndRefresh(t *testing.T) {
+	sessionRepo := newInMemorySessionRepo()
+	fx := newAuthServiceFixtureWithSessionRepo(sessionRepo)
+	statusSvc := NewUserStatusService(fx.userRepo, newTestTokenService(sessionRepo))
+	uid := fx.seedLocalUser("status@example.com", "Status", "StrongPass123!", true)
+
+	login, err := fx.auth.LoginWithLocalPassword("status@example.com", "StrongPass123!", "ua", "127.0.0.1")
+	if err != nil {
+		t.Fatalf("login: %v", err)
+	}
+
+	user, previous, err := statusSvc.Suspend(uid)
+	if err != nil || user.Status != domain.UserStatusSuspended || previous != domain.UserStatusActive {
+		t.Fatalf("expected suspend from active, got user=%+v previous=%q err=%v", user, previous, err)
+	}
+	if _, err := fx.auth.Refresh(login.RefreshToken, "ua", "127.0.0.1"); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Fatalf("expected suspension to revoke the session, got %v", err)
+	}
+	if _, err := fx.auth.LoginWithLocalPassword("status@example.com", "StrongPass123!", "ua", "127.0.0.1"); !errors.Is(err, ErrAccountInactive) {
+		t.Fatalf("expected suspended login to fail, got %v", err)
+	}
+	if _, previous, err := statusSvc.Suspend(uid); err != nil || previous != domain.UserStatusSuspended {
+		t.Fatalf("expected repeated suspend to succeed, got previous=%q err=%v", previous, err)
+	}
+
+	user, previous, err = statusSvc.Reactivate(uid)
+	if err != nil || user.Status != domain.UserStatusActive || previous != domain.UserStatusSuspended {
+		t.Fatalf("expected reactivate from suspended, got user=%+v previous=%q err=%v", user, previous, err)
+	}
+	login, err = fx.auth.LoginWithLocalPassword("status@example.com", "StrongPass123!", "ua", "127.0.0.1")
+	if err != nil {
+		t.Fatalf("login after reactivate: %v", err)
+	}
+
+	// A status set outside the admin flow leaves sessions alive; refresh
+	// must still refuse to rotate them.
+	if err := fx.userRepo.UpdateStatus(uid, domain.UserStatusDisabled); err != nil {
+		t.Fatalf("disable: %v", err)
+	}
+	if _, err := fx.auth.Refresh(login.RefreshToken, "ua", "127.0.0.1"); !errors.Is(err, ErrAccountInactive) {
+		t.Fatalf("expected refresh of disabled user to fail, got %v", err)
+	}
+	if _, err := fx.auth.Refresh(login.RefreshToken, "ua", "127.0.0.1"); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Fatalf("expected rejected refresh to revoke the session, got %v", err)
+	}
+	if _, _, err := statusSvc.Suspend(uid); !errors.Is(err, ErrInvalidUserStatusTransition) {
+		t.Fatalf("expected suspend of disabled user to fail, got %v", err)
+	}
+	if user, previous, err := statusSvc.Reactivate(uid); err != nil || user.Status != domain.UserStatusActive || previous != domain.UserStatusDisabled {
+		t.Fatalf("expected reactivate from disabled, got user=%+v previous=%q err=%v", user, previous, err)
+	}
+	if err := fx.userRepo.UpdateStatus(uid, domain.UserStatusPending); err != nil {
+		t.Fatalf("mark pending: %v", err)
+	}
+	if user, previous, err := statusSvc.Reactivate(uid); err != nil || user.Status != domain.UserStatusActive || previous != domain.UserStatusPending {
+		t.Fatalf("expected reactivate from pending, got user=%+v previous=%q err=%v", user, previous, err)
+	}
+	if _, err := fx.auth.LoginWithLocalPassword("status@example.com", "StrongPass123!", "ua", "127.0.0.1"); err != nil {
+		t.Fatalf("login after reactivating pending user: %v", err)
+	}
+	if err := fx.userRepo.UpdateStatus(uid, domain.UserStatusDeleted); err != nil {
+		t.Fatalf("mark deleted: %v", err)
+	}
+	if _, _, err := statusSvc.Reactivate(uid); !errors.Is(err, ErrInvalidUserStatusTransition) {
+		t.Fatalf("expected reactivate of deleted user to fail, got %v", err)
+	}
+	if _, _, err := statusSvc.Suspend(uid + 100); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("expected missing user to fail, got %v", err)
+	}
+}
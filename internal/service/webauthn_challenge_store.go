@@ -0,0 +1,17 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var ErrWebAuthnChallengeNotFound = errors.New("webauthn challenge not found")
+
+// WebAuthnChallengeStore holds ceremony session data between the begin and
+// finish requests. Consume must be single-use: a challenge that has been read
+// once cannot be replayed.
+type WebAuthnChallengeStore interface {
+	Save(ctx context.Context, key string, sessionData []byte, ttl time.Duration) error
+	Consume(ctx context.Context, key string) ([]byte, error)
+}
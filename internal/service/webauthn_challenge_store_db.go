@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+type DBWebAuthnChallengeStore struct {
+	db  *gorm.DB
+	now func() time.Time
+}
+
+func NewDBWebAuthnChallengeStore(db *gorm.DB) *DBWebAuthnChallengeStore {
+	return &DBWebAuthnChallengeStore{db: db, now: time.Now}
+}
+
+// Save also prunes expired rows; challenges live for minutes, so piggybacking
+// cleanup on writes keeps the table small without a background loop.
+func (s *DBWebAuthnChallengeStore) Save(ctx context.Context, key string, sessionData []byte, ttl time.Duration) error {
+	now := s.now().UTC()
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("expires_at <= ?", now).Delete(&domain.WebAuthnChallenge{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&domain.WebAuthnChallenge{
+			ChallengeID: key,
+			SessionData: sessionData,
+			ExpiresAt:   now.Add(ttl),
+		}).Error
+	})
+}
+
+func (s *DBWebAuthnChallengeStore) Consume(ctx context.Context, key string) ([]byte, error) {
+	var data []byte
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var rec domain.WebAuthnChallenge
+		if err := tx.Where("challenge_id = ? AND expires_at > ?", key, s.now().UTC()).First(&rec).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrWebAuthnChallengeNotFound
+			}
+			return err
+		}
+		res := tx.Where("id = ?", rec.ID).Delete(&domain.WebAuthnChallenge{})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrWebAuthnChallengeNotFound
+		}
+		data = rec.SessionData
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
@@ -0,0 +1,66 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/domain"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestDBWebAuthnChallengeStoreIsSingleUseAndExpires(t *testing.T) {
+	ctx := context.Background()
+	store, db := newDBWebAuthnChallengeStoreForTest(t)
+	now := time.Now().UTC()
+	store.now = func() time.Time { return now }
+
+	if err := store.Save(ctx, "k1", []byte("session-1"), time.Minute); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	if err := store.Save(ctx, "k2", []byte("session-2"), time.Second); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	data, err := store.Consume(ctx, "k1")
+	if err != nil || string(data) != "session-1" {
+		t.Fatalf("expected stored session data, got %q err=%v", data, err)
+	}
+	if _, err := store.Consume(ctx, "k1"); !errors.Is(err, ErrWebAuthnChallengeNotFound) {
+		t.Fatalf("expected consumed challenge to be gone, got %v", err)
+	}
+
+	now = now.Add(2 * time.Second)
+	if _, err := store.Consume(ctx, "k2"); !errors.Is(err, ErrWebAuthnChallengeNotFound) {
+		t.Fatalf("expected expired challenge to be rejected, got %v", err)
+	}
+	if err := store.Save(ctx, "k3", []byte("session-3"), time.Minute); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	var count int64
+	if err := db.Model(&domain.WebAuthnChallenge{}).Count(&count).Error; err != nil {
+		t.Fatalf("count: %v", err)
+	}
+	if count != 1 {
+		t.Fatalf("expected expired rows to be pruned on save, got %d rows", count)
+	}
+}
+
+func newDBWebAuthnChallengeStoreForTest(t *testing.T) (*DBWebAuthnChallengeStore, *gorm.DB) {
+	t.Helper()
+	dsn := fmt.Sprintf("file:%s?mode=memory&cache=shared", strings.ReplaceAll(t.Name(), "/", "_"))
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	if err := db.AutoMigrate(&domain.WebAuthnChallenge{}, &domain.WebAuthnCredential{}, &domain.User{}, &domain.UserRole{}, &domain.Role{}, &domain.Permission{}); err != nil {
+		t.Fatalf("migrate webauthn models: %v", err)
+	}
+	return NewDBWebAuthnChallengeStore(db), db
+}
@@ -0,0 +1,40 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+type RedisWebAuthnChallengeStore struct {
+	client redis.UniversalClient
+	prefix string
+}
+
+func NewRedisWebAuthnChallengeStore(client redis.UniversalClient, prefix string) *RedisWebAuthnChallengeStore {
+	if prefix == "" {
+		prefix = "webauthn"
+	}
+	return &RedisWebAuthnChallengeStore{client: client, prefix: prefix}
+}
+
+func (s *RedisWebAuthnChallengeStore) redisKey(key string) string {
+	return s.prefix + ":challenge:" + key
+}
+
+func (s *RedisWebAuthnChallengeStore) Save(ctx context.Context, key string, sessionData []byte, ttl time.Duration) error {
+	return s.client.Set(ctx, s.redisKey(key), sessionData, ttl).Err()
+}
+
+func (s *RedisWebAuthnChallengeStore) Consume(ctx context.Context, key string) ([]byte, error) {
+	raw, err := s.client.GetDel(ctx, s.redisKey(key)).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, ErrWebAuthnChallengeNotFound
+		}
+		return nil, err
+	}
+	return raw, nil
+}
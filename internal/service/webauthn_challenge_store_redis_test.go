@@ -0,0 +1,36 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRedisWebAuthnChallengeStoreIsSingleUseAndExpires(t *testing.T) {
+	ctx := context.Background()
+	server, client := newRedisClientForTest(t)
+	store := NewRedisWebAuthnChallengeStore(client, "webauthn_test")
+
+	if err := store.Save(ctx, "k1", []byte(`{"challenge":"abc"}`), time.Minute); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	if !server.Exists("webauthn_test:challenge:k1") {
+		t.Fatal("expected prefixed challenge key")
+	}
+	data, err := store.Consume(ctx, "k1")
+	if err != nil || string(data) != `{"challenge":"abc"}` {
+		t.Fatalf("expected stored session data, got %q err=%v", data, err)
+	}
+	if _, err := store.Consume(ctx, "k1"); !errors.Is(err, ErrWebAuthnChallengeNotFound) {
+		t.Fatalf("expected consumed challenge to be gone, got %v", err)
+	}
+
+	if err := store.Save(ctx, "k2", []byte("x"), time.Second); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	server.FastForward(2 * time.Second)
+	if _, err := store.Consume(ctx, "k2"); !errors.Is(err, ErrWebAuthnChallengeNotFound) {
+		t.Fatalf("expected expired challenge to be gone, got %v", err)
+	}
+}
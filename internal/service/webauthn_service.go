@@ -0,0 +1,347 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/config"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/domain"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/repository"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/security"
+)
+
+const (
+	webauthnDefaultCredentialName = "Passkey"
+	webauthnMaxCredentialName     = 128
+)
+
+var (
+	ErrWebAuthnDisabled           = errors.New("webauthn is disabled")
+	ErrInvalidWebAuthnChallenge   = errors.New("invalid or expired webauthn challenge")
+	ErrInvalidWebAuthnCredential  = errors.New("invalid webauthn credential")
+	ErrWebAuthnCredentialNotFound = errors.New("webauthn credential not found")
+)
+
+// WebAuthnCeremony is returned by the begin endpoints. Options is passed to
+// navigator.credentials.create/get as-is; ChallengeID must be echoed back on
+// the matching finish call.
+type WebAuthnCeremony struct {
+	ChallengeID string `json:"challenge_id"`
+	Options     any    `json:"options"`
+}
+
+type WebAuthnService struct {
+	cfg        *config.Config
+	wa         *webauthn.WebAuthn
+	repo       repository.WebAuthnCredentialRepository
+	userRepo   repository.UserRepository
+	challenges WebAuthnChallengeStore
+	now        func() time.Time
+}
+
+func NewWebAuthnService(
+	cfg *config.Config,
+	repo repository.WebAuthnCredentialRepository,
+	userRepo repository.UserRepository,
+	challenges WebAuthnChallengeStore,
+) (*WebAuthnService, error) {
+	svc := &WebAuthnService{cfg: cfg, repo: repo, userRepo: userRepo, challenges: challenges, now: time.Now}
+	if !cfg.AuthWebAuthnEnabled {
+		return svc, nil
+	}
+	timeout := webauthn.TimeoutConfig{Enforce: true, Timeout: cfg.AuthWebAuthnChallengeTTL, TimeoutUVD: cfg.AuthWebAuthnChallengeTTL}
+	wa, err := webauthn.New(&webauthn.Config{
+		RPID:          cfg.AuthWebAuthnRPID,
+		RPDisplayName: cfg.AuthWebAuthnRPDisplayName,
+		RPOrigins:     cfg.AuthWebAuthnRPOrigins,
+		Timeouts:      webauthn.TimeoutsConfig{Login: timeout, Registration: timeout},
+	})
+	if err != nil {
+		return nil, err
+	}
+	svc.wa = wa
+	return svc, nil
+}
+
+func (s *WebAuthnService) Enabled() bool {
+	return s != nil && s.wa != nil
+}
+
+// BeginRegistration requires a resident key with user verification so the
+// resulting passkey can later be used for username-less login on its own.
+func (s *WebAuthnService) BeginRegistration(ctx context.Context, userID uint) (*WebAuthnCeremony, error) {
+	if !s.Enabled() {
+		return nil, ErrWebAuthnDisabled
+	}
+	user, err := s.loadUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	requireResidentKey := true
+	creation, session, err := s.wa.BeginRegistration(user,
+		webauthn.WithAuthenticatorSelection(protocol.AuthenticatorSelection{
+			RequireResidentKey: &requireResidentKey,
+			ResidentKey:        protocol.ResidentKeyRequirementRequired,
+			UserVerification:   protocol.VerificationRequired,
+		}),
+		webauthn.WithExclusions(webauthn.Credentials(user.creds).CredentialDescriptors()),
+	)
+	if err != nil {
+		return nil, err
+	}
+	challengeID, err := s.saveSession(ctx, session)
+	if err != nil {
+		return nil, err
+	}
+	return &WebAuthnCeremony{ChallengeID: challengeID, Options: creation}, nil
+}
+
+func (s *WebAuthnService) FinishRegistration(ctx context.Context, userID uint, challengeID, name string, response []byte) (*domain.WebAuthnCredential, error) {
+	if !s.Enabled() {
+		return nil, ErrWebAuthnDisabled
+	}
+	session, err := s.consumeSession(ctx, challengeID)
+	if err != nil {
+		return nil, err
+	}
+	user, err := s.loadUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := protocol.ParseCredentialCreationResponseBytes(response)
+	if err != nil {
+		return nil, ErrInvalidWebAuthnCredential
+	}
+	cred, err := s.wa.CreateCredential(user, *session, parsed)
+	if err != nil {
+		return nil, ErrInvalidWebAuthnCredential
+	}
+	credentialID := encodeWebAuthnID(cred.ID)
+	if _, err := s.repo.FindByCredentialID(credentialID); err == nil {
+		return nil, ErrInvalidWebAuthnCredential
+	} else if !errors.Is(err, repository.ErrWebAuthnCredentialNotFound) {
+		return nil, err
+	}
+
+	transports := make([]string, 0, len(cred.Transport))
+	for _, t := range cred.Transport {
+		transports = append(transports, string(t))
+	}
+	record := &domain.WebAuthnCredential{
+		UserID:          userID,
+		CredentialID:    credentialID,
+		PublicKey:       cred.PublicKey,
+		AttestationType: cred.AttestationType,
+		AAGUID:          cred.Authenticator.AAGUID,
+		SignCount:       cred.Authenticator.SignCount,
+		Transports:      strings.Join(transports, ","),
+		Flags:           uint8(cred.Flags.ProtocolValue()),
+		Name:            normalizeWebAuthnCredentialName(name),
+	}
+	if err := s.repo.Create(record); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// BeginLogin starts a discoverable (username-less) assertion: the
+// authenticator picks the credential and reports the user handle.
+func (s *WebAuthnService) BeginLogin(ctx context.Context) (*WebAuthnCeremony, error) {
+	if !s.Enabled() {
+		return nil, ErrWebAuthnDisabled
+	}
+	assertion, session, err := s.wa.BeginDiscoverableLogin(webauthn.WithUserVerification(protocol.VerificationRequired))
+	if err != nil {
+		return nil, err
+	}
+	challengeID, err := s.saveSession(ctx, session)
+	if err != nil {
+		return nil, err
+	}
+	return &WebAuthnCeremony{ChallengeID: challengeID, Options: assertion}, nil
+}
+
+// FinishLogin verifies an assertion and returns the authenticated user ID.
+// Assertions that trip the clone warning (a non-increasing sign counter) are
+// rejected rather than merely flagged.
+func (s *WebAuthnService) FinishLogin(ctx context.Context, challengeID string, response []byte) (uint, error) {
+	if !s.Enabled() {
+		return 0, ErrWebAuthnDisabled
+	}
+	session, err := s.consumeSession(ctx, challengeID)
+	if err != nil {
+		return 0, err
+	}
+	parsed, err := protocol.ParseCredentialRequestResponseBytes(response)
+	if err != nil {
+		return 0, ErrInvalidWebAuthnCredential
+	}
+
+	var record *domain.WebAuthnCredential
+	lookup := func(rawID, userHandle []byte) (webauthn.User, error) {
+		rec, err := s.repo.FindByCredentialID(encodeWebAuthnID(rawID))
+		if err != nil {
+			return nil, err
+		}
+		if !bytes.Equal(userHandle, webauthnUserHandle(rec.UserID)) {
+			return nil, ErrInvalidWebAuthnCredential
+		}
+		user, err := s.loadUser(rec.UserID)
+		if err != nil {
+			return nil, err
+		}
+		record = rec
+		return user, nil
+	}
+	cred, err := s.wa.ValidateDiscoverableLogin(lookup, *session, parsed)
+	if err != nil || record == nil {
+		return 0, ErrInvalidWebAuthnCredential
+	}
+	if cred.Authenticator.CloneWarning {
+		return 0, ErrInvalidWebAuthnCredential
+	}
+	if err := s.repo.UpdateUsage(record.ID, cred.Authenticator.SignCount, uint8(cred.Flags.ProtocolValue()), s.now().UTC()); err != nil {
+		return 0, err
+	}
+	return record.UserID, nil
+}
+
+func (s *WebAuthnService) ListCredentials(userID uint) ([]domain.WebAuthnCredential, error) {
+	return s.repo.ListByUserID(userID)
+}
+
+func (s *WebAuthnService) DeleteCredential(userID, credentialID uint) error {
+	if err := s.repo.DeleteForUser(credentialID, userID); err != nil {
+		if errors.Is(err, repository.ErrWebAuthnCredentialNotFound) {
+			return ErrWebAuthnCredentialNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *WebAuthnService) saveSession(ctx context.Context, session *webauthn.SessionData) (string, error) {
+	raw, err := json.Marshal(session)
+	if err != nil {
+		return "", err
+	}
+	challengeID, err := security.NewRandomString(32)
+	if err != nil {
+		return "", err
+	}
+	if err := s.challenges.Save(ctx, hashVerificationToken(challengeID), raw, s.cfg.AuthWebAuthnChallengeTTL); err != nil {
+		return "", err
+	}
+	return challengeID, nil
+}
+
+func (s *WebAuthnService) consumeSession(ctx context.Context, challengeID string) (*webauthn.SessionData, error) {
+	challengeID = strings.TrimSpace(challengeID)
+	if challengeID == "" {
+		return nil, ErrInvalidWebAuthnChallenge
+	}
+	raw, err := s.challenges.Consume(ctx, hashVerificationToken(challengeID))
+	if err != nil {
+		if errors.Is(err, ErrWebAuthnChallengeNotFound) {
+			return nil, ErrInvalidWebAuthnChallenge
+		}
+		return nil, err
+	}
+	var session webauthn.SessionData
+	if err := json.Unmarshal(raw, &session); err != nil {
+		return nil, ErrInvalidWebAuthnChallenge
+	}
+	return &session, nil
+}
+
+func (s *WebAuthnService) loadUser(userID uint) (*webauthnUser, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	records, err := s.repo.ListByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	creds := make([]webauthn.Credential, 0, len(records))
+	for _, rec := range records {
+		cred, err := toWebAuthnCredential(rec)
+		if err != nil {
+			return nil, err
+		}
+		creds = append(creds, cred)
+	}
+	return &webauthnUser{user: user, creds: creds}, nil
+}
+
+type webauthnUser struct {
+	user  *domain.User
+	creds []webauthn.Credential
+}
+
+func (u *webauthnUser) WebAuthnID() []byte { return webauthnUserHandle(u.user.ID) }
+
+func (u *webauthnUser) WebAuthnName() string { return u.user.Email }
+
+func (u *webauthnUser) WebAuthnDisplayName() string {
+	if strings.TrimSpace(u.user.Name) != "" {
+		return u.user.Name
+	}
+	return u.user.Email
+}
+
+func (u *webauthnUser) WebAuthnCredentials() []webauthn.Credential { return u.creds }
+
+// webauthnUserHandle is the opaque user handle stored on the authenticator;
+// it carries no personal data, only the numeric user ID.
+func webauthnUserHandle(userID uint) []byte {
+	return []byte(strconv.FormatUint(uint64(userID), 10))
+}
+
+func toWebAuthnCredential(rec domain.WebAuthnCredential) (webauthn.Credential, error) {
+	id, err := base64.RawURLEncoding.DecodeString(rec.CredentialID)
+	if err != nil {
+		return webauthn.Credential{}, err
+	}
+	var transports []protocol.AuthenticatorTransport
+	for _, t := range strings.Split(rec.Transports, ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			transports = append(transports, protocol.AuthenticatorTransport(t))
+		}
+	}
+	return webauthn.Credential{
+		ID:              id,
+		PublicKey:       rec.PublicKey,
+		AttestationType: rec.AttestationType,
+		Transport:       transports,
+		Flags:           webauthn.NewCredentialFlags(protocol.AuthenticatorFlags(rec.Flags)),
+		Authenticator: webauthn.Authenticator{
+			AAGUID:    rec.AAGUID,
+			SignCount: rec.SignCount,
+		},
+	}, nil
+}
+
+func encodeWebAuthnID(raw []byte) string {
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func normalizeWebAuthnCredentialName(name string) string {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return webauthnDefaultCredentialName
+	}
+	if r := []rune(name); len(r) > webauthnMaxCredentialName {
+		name = string(r[:webauthnMaxCredentialName])
+	}
+	return name
+}
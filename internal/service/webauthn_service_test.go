@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/config"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/domain"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/repository"
+)
+
+func newWebAuthnServiceForTest(t *testing.T, enabled bool) (*WebAuthnService, repository.WebAuthnCredentialRepository) {
+	t.Helper()
+	store, db := newDBWebAuthnChallengeStoreForTest(t)
+	cfg := &config.Config{
+		AuthWebAuthnEnabled:       enabled,
+		AuthWebAuthnRPID:          "localhost",
+		AuthWebAuthnRPDisplayName: "Starter Kit",
+		AuthWebAuthnRPOrigins:     []string{"http://localhost:3000"},
+		AuthWebAuthnChallengeTTL:  5 * time.Minute,
+	}
+	repo := repository.NewWebAuthnCredentialRepository(db)
+	svc, err := NewWebAuthnService(cfg, repo, repository.NewUserRepository(db), store)
+	if err != nil {
+		t.Fatalf("new webauthn service: %v", err)
+	}
+	if err := db.Create(&domain.User{ID: 1, Email: "user@example.com", Name: "User"}).Error; err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	return svc, repo
+}
+
+func TestWebAuthnServiceDisabled(t *testing.T) {
+	svc, _ := newWebAuthnServiceForTest(t, false)
+	ctx := context.Background()
+	if svc.Enabled() {
+		t.Fatal("expected disabled service")
+	}
+	if _, err := svc.BeginRegistration(ctx, 1); !errors.Is(err, ErrWebAuthnDisabled) {
+		t.Fatalf("expected ErrWebAuthnDisabled, got %v", err)
+	}
+	if _, err := svc.BeginLogin(ctx); !errors.Is(err, ErrWebAuthnDisabled) {
+		t.Fatalf("expected ErrWebAuthnDisabled, got %v", err)
+	}
+	if _, err := svc.FinishLogin(ctx, "challenge", []byte("{}")); !errors.Is(err, ErrWebAuthnDisabled) {
+		t.Fatalf("expected ErrWebAuthnDisabled, got %v", err)
+	}
+}
+
+func TestWebAuthnServiceRejectsInvalidConfig(t *testing.T) {
+	cfg := &config.Config{AuthWebAuthnEnabled: true, AuthWebAuthnChallengeTTL: time.Minute}
+	if _, err := NewWebAuthnService(cfg, nil, nil, nil); err == nil {
+		t.Fatal("expected missing rp settings to fail")
+	}
+}
+
+func TestWebAuthnServiceChallengeIsConsumedOnFailedFinish(t *testing.T) {
+	svc, _ := newWebAuthnServiceForTest(t, true)
+	ctx := context.Background()
+
+	ceremony, err := svc.BeginLogin(ctx)
+	if err != nil {
+		t.Fatalf("begin login: %v", err)
+	}
+	if ceremony.ChallengeID == "" || ceremony.Options == nil {
+		t.Fatalf("expected challenge id and options, got %+v", ceremony)
+	}
+	if _, err := svc.FinishLogin(ctx, ceremony.ChallengeID, []byte(`{"id":"x"}`)); !errors.Is(err, ErrInvalidWebAuthnCredential) {
+		t.Fatalf("expected ErrInvalidWebAuthnCredential, got %v", err)
+	}
+	if _, err := svc.FinishLogin(ctx, ceremony.ChallengeID, []byte(`{"id":"x"}`)); !errors.Is(err, ErrInvalidWebAuthnChallenge) {
+		t.Fatalf("expected consumed challenge to be rejected, got %v", err)
+	}
+
+	reg, err := svc.BeginRegistration(ctx, 1)
+	if err != nil {
+		t.Fatalf("begin registration: %v", err)
+	}
+	if _, err := svc.FinishRegistration(ctx, 1, reg.ChallengeID, "", []byte(`{}`)); !errors.Is(err, ErrInvalidWebAuthnCredential) {
+		t.Fatalf("expected ErrInvalidWebAuthnCredential, got %v", err)
+	}
+	if _, err := svc.FinishRegistration(ctx, 1, "", "", []byte(`{}`)); !errors.Is(err, ErrInvalidWebAuthnChallenge) {
+		t.Fatalf("expected ErrInvalidWebAuthnChallenge for blank id, got %v", err)
+	}
+}
+
+func TestWebAuthnServiceDeleteCredentialIsOwnerScoped(t *testing.T) {
+	svc, repo := newWebAuthnServiceForTest(t, true)
+	cred := &domain.WebAuthnCredential{UserID: 1, CredentialID: "AQID", PublicKey: []byte{1}}
+	if err := repo.Create(cred); err != nil {
+		t.Fatalf("create credential: %v", err)
+	}
+	if err := svc.DeleteCredential(2, cred.ID); !errors.Is(err, ErrWebAuthnCredentialNotFound) {
+		t.Fatalf("expected ErrWebAuthnCredentialNotFound, got %v", err)
+	}
+	if err := svc.DeleteCredential(1, cred.ID); err != nil {
+		t.Fatalf("delete credential: %v", err)
+	}
+	if got := normalizeWebAuthnCredentialName("  "); got != webauthnDefaultCredentialName {
+		t.Fatalf("expected default credential name, got %q", got)
+	}
+}
+
+func TestAuthServiceDeleteWebAuthnCredentialRefusesLastSignInMethod(t *testing.T) {
+	svc, repo := newWebAuthnServiceForTest(t, true)
+	fx := newAuthServiceFixture()
+	fx.auth.webauthnSvc = svc
+	first := &domain.WebAuthnCredential{UserID: 1, CredentialID: "AQID", PublicKey: []byte{1}}
+	second := &domain.WebAuthnCredential{UserID: 1, CredentialID: "BAUG", PublicKey: []byte{2}}
+	for _, cred := range []*domain.WebAuthnCredential{first, second} {
+		if err := repo.Create(cred); err != nil {
+			t.Fatalf("create credential: %v", err)
+		}
+	}
+
+	if err := fx.auth.DeleteWebAuthnCredential(2, first.ID); !errors.Is(err, ErrWebAuthnCredentialNotFound) {
+		t.Fatalf("expected another user's passkey to be not found, got %v", err)
+	}
+	if err := fx.auth.DeleteWebAuthnCredential(1, first.ID); err != nil {
+		t.Fatalf("expected a passkey with another remaining to be deleted, got %v", err)
+	}
+	if err := fx.auth.DeleteWebAuthnCredential(1, second.ID); !errors.Is(err, ErrLastCredential) {
+		t.Fatalf("expected ErrLastCredential for the only sign-in method, got %v", err)
+	}
+	if remaining, _ := svc.ListCredentials(1); len(remaining) != 1 {
+		t.Fatalf("expected the last passkey to be kept, got %d", len(remaining))
+	}
+}
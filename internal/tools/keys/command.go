@@ -0,0 +1,169 @@
+package keys
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"gorm.io/gorm"
+
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/config"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/database"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/observability"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/repository"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/service"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/tools/common"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/tools/ui"
+)
+
+type options struct {
+	envFile string
+	ci      bool
+}
+
+func NewRootCommand() *cobra.Command {
+	opts := &options{}
+	cmd := &cobra.Command{Use: "keys", Short: "JWT signing key tooling"}
+	cmd.PersistentFlags().StringVar(&opts.envFile, "env-file", ".env", "path to env file")
+	cmd.PersistentFlags().BoolVar(&opts.ci, "ci", false, "non-interactive machine-readable output")
+	cmd.AddCommand(newRotateCommand(opts), newListCommand(opts))
+	return cmd
+}
+
+func newRotateCommand(opts *options) *cobra.Command {
+	var activationDelay time.Duration
+	cmd := &cobra.Command{
+		Use:   "rotate",
+		Short: "Publish a new access-token signing key and schedule the current one for retirement",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			details, err := run(opts, "keys rotate", "rotate", func(ctx context.Context) ([]string, error) {
+				cfg, svc, closeDB, err := loadKeyService(opts.envFile)
+				if err != nil {
+					return nil, err
+				}
+				defer closeDB()
+				delay := cfg.JWTKeyringRefreshInterval
+				if cmd.Flags().Changed("activation-delay") {
+					delay = activationDelay
+				}
+				key, err := svc.Rotate(ctx, delay)
+				if err != nil {
+					return nil, err
+				}
+				return []string{
+					fmt.Sprintf("published key %s (%s)", key.KID, key.Algorithm),
+					"signs from: " + key.ActivatesAt.Format(time.RFC3339),
+					"previous keys retire at: " + key.ActivatesAt.Add(cfg.LongestAccessTokenTTL()+cfg.JWTKeyringRefreshInterval).Format(time.RFC3339),
+				}, nil
+			})
+			if opts.ci {
+				common.PrintCIResult(err == nil, "keys rotate", details, err)
+			}
+			if err != nil {
+				os.Exit(3)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().DurationVar(&activationDelay, "activation-delay", 0, "delay before the new key signs (default JWT_KEYRING_REFRESH_INTERVAL)")
+	return cmd
+}
+
+func newListCommand(opts *options) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List stored signing keys and their lifecycle",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			details, err := run(opts, "keys list", "list", func(ctx context.Context) ([]string, error) {
+				_, svc, closeDB, err := loadKeyService(opts.envFile)
+				if err != nil {
+					return nil, err
+				}
+				defer closeDB()
+				keys, err := svc.ListKeys()
+				if err != nil {
+					return nil, err
+				}
+				details := make([]string, 0, len(keys))
+				for _, k := range keys {
+					retires := "never"
+					if k.RetiresAt != nil {
+						retires = k.RetiresAt.Format(time.RFC3339)
+					}
+					details = append(details, fmt.Sprintf("%s %s activates=%s retires=%s", k.KID, k.Algorithm, k.ActivatesAt.Format(time.RFC3339), retires))
+				}
+				if len(details) == 0 {
+					details = append(details, "no signing keys stored")
+				}
+				return details, nil
+			})
+			if opts.ci {
+				common.PrintCIResult(err == nil, "keys list", details, err)
+			}
+			if err != nil {
+				os.Exit(3)
+			}
+			return nil
+		},
+	}
+}
+
+func run(opts *options, title, command string, fn func(context.Context) ([]string, error)) ([]string, error) {
+	if opts.ci {
+		ctx := context.Background()
+		start := time.Now()
+		details, err := fn(ctx)
+		recordToolMetrics(ctx, command, start, err)
+		return details, err
+	}
+	return ui.Run(title, func(ctx context.Context) ([]string, error) {
+		start := time.Now()
+		details, err := fn(ctx)
+		recordToolMetrics(ctx, command, start, err)
+		return details, err
+	})
+}
+
+func recordToolMetrics(ctx context.Context, command string, start time.Time, err error) {
+	outcome := "success"
+	if err != nil {
+		outcome = "error"
+	}
+	observability.RecordToolCommandRun(ctx, "keys", command, outcome)
+	observability.RecordToolCommandDuration(ctx, "keys", command, outcome, time.Since(start))
+}
+
+func loadKeyService(envFile string) (*config.Config, *service.JWTKeyService, func(), error) {
+	cfg, db, err := loadConfigDB(envFile)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	closeDB := func() {
+		if sqlDB, err := db.DB(); err == nil {
+			_ = sqlDB.Close()
+		}
+	}
+	svc, err := service.NewJWTKeyService(cfg, repository.NewJWTSigningKeyRepository(db))
+	if err != nil {
+		closeDB()
+		return nil, nil, nil, err
+	}
+	return cfg, svc, closeDB, nil
+}
+
+func loadConfigDB(envFile string) (*config.Config, *gorm.DB, error) {
+	if err := common.LoadEnvFile(envFile); err != nil {
+		return nil, nil, err
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, nil, err
+	}
+	db, err := database.Open(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cfg, db, nil
+}
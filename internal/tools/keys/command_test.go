@@ -0,0 +1,53 @@
+package keys
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestNewRootCommandStructure(t *testing.T) {
+	cmd := NewRootCommand()
+	if cmd.Use != "keys" {
+		t.Fatalf("unexpected root use: %s", cmd.Use)
+	}
+	for _, name := range []string{"rotate", "list"} {
+		if c, _, err := cmd.Find([]string{name}); err != nil || c == nil {
+			t.Fatalf("expected subcommand %q: err=%v", name, err)
+		}
+	}
+	rotate, _, err := cmd.Find([]string{"rotate"})
+	if err != nil {
+		t.Fatalf("find rotate: %v", err)
+	}
+	if f := rotate.Flags().Lookup("activation-delay"); f == nil {
+		t.Fatal("expected --activation-delay flag on rotate")
+	}
+}
+
+func TestRunCIPathSuccessAndError(t *testing.T) {
+	opts := &options{ci: true}
+	details, err := run(opts, "title", "list", func(ctx context.Context) ([]string, error) {
+		return []string{"ok"}, nil
+	})
+	if err != nil || len(details) != 1 {
+		t.Fatalf("expected success details, got details=%v err=%v", details, err)
+	}
+	if _, err := run(opts, "title", "rotate", func(ctx context.Context) ([]string, error) {
+		return nil, errors.New("boom")
+	}); err == nil {
+		t.Fatal("expected propagated error")
+	}
+}
+
+func TestLoadKeyServiceEnvParseError(t *testing.T) {
+	envFile := t.TempDir() + "/bad.env"
+	if err := os.WriteFile(envFile, []byte("JWT_KEYRING_REFRESH_INTERVAL=soon\n"), 0o600); err != nil {
+		t.Fatalf("write env file: %v", err)
+	}
+	if _, _, _, err := loadKeyService(envFile); err == nil || !strings.Contains(err.Error(), "JWT_KEYRING_REFRESH_INTERVAL") {
+		t.Fatalf("expected config parse error, got %v", err)
+	}
+}
@@ -0,0 +1,192 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/config"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/database"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/domain"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/observability"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/repository"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/service"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/tools/common"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/tools/ui"
+)
+
+type options struct {
+	envFile string
+	ci      bool
+}
+
+func NewRootCommand() *cobra.Command {
+	opts := &options{}
+	cmd := &cobra.Command{Use: "outbox", Short: "Notification outbox tooling"}
+	cmd.PersistentFlags().StringVar(&opts.envFile, "env-file", ".env", "path to env file")
+	cmd.PersistentFlags().BoolVar(&opts.ci, "ci", false, "non-interactive machine-readable output")
+	cmd.AddCommand(newListCommand(opts), newReplayCommand(opts))
+	return cmd
+}
+
+func newListCommand(opts *options) *cobra.Command {
+	var status string
+	var limit int
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List outbox messages, newest first",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			details, err := run(opts, "outbox list", "list", func(ctx context.Context) ([]string, error) {
+				if err := validateStatus(status); err != nil {
+					return nil, err
+				}
+				svc, closeDB, err := loadOutbox(opts.envFile)
+				if err != nil {
+					return nil, err
+				}
+				defer closeDB()
+				messages, err := svc.List(status, limit)
+				if err != nil {
+					return nil, err
+				}
+				details := make([]string, 0, len(messages))
+				for _, msg := range messages {
+					details = append(details, describeMessage(msg))
+				}
+				if len(details) == 0 {
+					details = append(details, "no outbox messages")
+				}
+				return details, nil
+			})
+			if opts.ci {
+				common.PrintCIResult(err == nil, "outbox list", details, err)
+			}
+			if err != nil {
+				os.Exit(3)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&status, "status", domain.OutboxStatusDead, "pending, sending, sent, dead, or empty for all")
+	cmd.Flags().IntVar(&limit, "limit", 50, "maximum messages to print")
+	return cmd
+}
+
+func newReplayCommand(opts *options) *cobra.Command {
+	var id uint
+	var allDead bool
+	var limit int
+	cmd := &cobra.Command{
+		Use:   "replay",
+		Short: "Requeue dead messages with a fresh attempt budget",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			details, err := run(opts, "outbox replay", "replay", func(ctx context.Context) ([]string, error) {
+				if (id == 0) == !allDead {
+					return nil, errors.New("pass exactly one of --id or --all-dead")
+				}
+				svc, closeDB, err := loadOutbox(opts.envFile)
+				if err != nil {
+					return nil, err
+				}
+				defer closeDB()
+				now := time.Now().UTC()
+				if allDead {
+					replayed, err := svc.ReplayDead(ctx, now, limit)
+					if err != nil {
+						return nil, err
+					}
+					return []string{fmt.Sprintf("replayed %d dead messages", replayed)}, nil
+				}
+				if err := svc.Replay(ctx, id, now); err != nil {
+					if errors.Is(err, repository.ErrOutboxNotReplayable) {
+						return nil, fmt.Errorf("message %d is not dead, or its token expired or was already cleared", id)
+					}
+					return nil, err
+				}
+				return []string{fmt.Sprintf("replayed message %d", id)}, nil
+			})
+			if opts.ci {
+				common.PrintCIResult(err == nil, "outbox replay", details, err)
+			}
+			if err != nil {
+				os.Exit(3)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().UintVar(&id, "id", 0, "replay a single message")
+	cmd.Flags().BoolVar(&allDead, "all-dead", false, "replay every replayable dead message, up to --limit")
+	cmd.Flags().IntVar(&limit, "limit", 500, "maximum messages considered by --all-dead")
+	return cmd
+}
+
+func validateStatus(status string) error {
+	switch status {
+	case "", domain.OutboxStatusPending, domain.OutboxStatusSending, domain.OutboxStatusSent, domain.OutboxStatusDead:
+		return nil
+	default:
+		return fmt.Errorf("unknown status %q", status)
+	}
+}
+
+func describeMessage(msg domain.OutboxMessage) string {
+	line := fmt.Sprintf("#%d %s user=%d status=%s attempts=%d next=%s", msg.ID, msg.Kind, msg.UserID, msg.Status, msg.Attempts, msg.NextAttemptAt.UTC().Format(time.RFC3339))
+	if msg.ExpiresAt != nil {
+		line += " expires=" + msg.ExpiresAt.UTC().Format(time.RFC3339)
+	}
+	if msg.LastError != "" {
+		line += fmt.Sprintf(" last_error=%q", msg.LastError)
+	}
+	return line
+}
+
+func run(opts *options, title, command string, fn func(context.Context) ([]string, error)) ([]string, error) {
+	if opts.ci {
+		ctx := context.Background()
+		start := time.Now()
+		details, err := fn(ctx)
+		recordToolMetrics(ctx, command, start, err)
+		return details, err
+	}
+	return ui.Run(title, func(ctx context.Context) ([]string, error) {
+		start := time.Now()
+		details, err := fn(ctx)
+		recordToolMetrics(ctx, command, start, err)
+		return details, err
+	})
+}
+
+func recordToolMetrics(ctx context.Context, command string, start time.Time, err error) {
+	outcome := "success"
+	if err != nil {
+		outcome = "error"
+	}
+	observability.RecordToolCommandRun(ctx, "outbox", command, outcome)
+	observability.RecordToolCommandDuration(ctx, "outbox", command, outcome, time.Since(start))
+}
+
+// loadOutbox opens the outbox for inspection only; the CLI never delivers
+// mail itself, replayed messages are picked up by the API's dispatcher.
+func loadOutbox(envFile string) (*service.NotificationOutbox, func(), error) {
+	if err := common.LoadEnvFile(envFile); err != nil {
+		return nil, nil, err
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, nil, err
+	}
+	db, err := database.Open(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	closeDB := func() {
+		if sqlDB, err := db.DB(); err == nil {
+			_ = sqlDB.Close()
+		}
+	}
+	return service.NewNotificationOutbox(cfg, repository.NewOutboxRepository(db), nil), closeDB, nil
+}
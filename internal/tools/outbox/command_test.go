@@ -0,0 +1,81 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/domain"
+)
+
+func TestNewRootCommandStructure(t *testing.T) {
+	cmd := NewRootCommand()
+	if cmd.Use != "outbox" {
+		t.Fatalf("unexpected root use: %s", cmd.Use)
+	}
+	for _, name := range []string{"list", "replay"} {
+		if c, _, err := cmd.Find([]string{name}); err != nil || c == nil {
+			t.Fatalf("expected subcommand %q: err=%v", name, err)
+		}
+	}
+	replay, _, err := cmd.Find([]string{"replay"})
+	if err != nil {
+		t.Fatalf("find replay: %v", err)
+	}
+	for _, flag := range []string{"id", "all-dead", "limit"} {
+		if f := replay.Flags().Lookup(flag); f == nil {
+			t.Fatalf("expected --%s flag on replay", flag)
+		}
+	}
+	list, _, _ := cmd.Find([]string{"list"})
+	if f := list.Flags().Lookup("status"); f == nil || f.DefValue != domain.OutboxStatusDead {
+		t.Fatalf("expected --status to default to dead, got %+v", f)
+	}
+}
+
+func TestRunCIPathSuccessAndError(t *testing.T) {
+	opts := &options{ci: true}
+	details, err := run(opts, "title", "list", func(ctx context.Context) ([]string, error) {
+		return []string{"ok"}, nil
+	})
+	if err != nil || len(details) != 1 {
+		t.Fatalf("expected success details, got details=%v err=%v", details, err)
+	}
+	if _, err := run(opts, "title", "replay", func(ctx context.Context) ([]string, error) {
+		return nil, errors.New("boom")
+	}); err == nil {
+		t.Fatal("expected propagated error")
+	}
+}
+
+func TestValidateStatusAndDescribeMessage(t *testing.T) {
+	if err := validateStatus("failed"); err == nil {
+		t.Fatal("expected unknown status to be rejected")
+	}
+	if err := validateStatus(""); err != nil {
+		t.Fatalf("expected empty status to list everything, got %v", err)
+	}
+	line := describeMessage(domain.OutboxMessage{
+		ID: 4, Kind: "password_reset", UserID: 9, Status: domain.OutboxStatusDead, Attempts: 8,
+		NextAttemptAt: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), LastError: "smtp dial: refused", Payload: "secret-token",
+	})
+	if !strings.Contains(line, "#4 password_reset user=9 status=dead attempts=8") || !strings.Contains(line, `last_error="smtp dial: refused"`) {
+		t.Fatalf("unexpected description %q", line)
+	}
+	if strings.Contains(line, "secret-token") {
+		t.Fatal("payload must never be printed")
+	}
+}
+
+func TestLoadOutboxEnvParseError(t *testing.T) {
+	envFile := t.TempDir() + "/bad.env"
+	if err := os.WriteFile(envFile, []byte("NOTIFICATION_OUTBOX_LEASE=soon\n"), 0o600); err != nil {
+		t.Fatalf("write env file: %v", err)
+	}
+	if _, _, err := loadOutbox(envFile); err == nil || !strings.Contains(err.Error(), "NOTIFICATION_OUTBOX_LEASE") {
+		t.Fatalf("expected config parse error, got %v", err)
+	}
+}
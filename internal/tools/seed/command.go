@@ -29,7 +29,7 @@ func NewRootCommand() *cobra.Command {
 	cmd.PersistentFlags().StringVar(&opts.envFile, "env-file", ".env", "path to env file")
 	cmd.PersistentFlags().StringVar(&opts.bootstrapAdminEmail, "bootstrap-admin-email", "", "override bootstrap admin email")
 	cmd.PersistentFlags().BoolVar(&opts.ci, "ci", false, "non-interactive machine-readable output")
-	cmd.AddCommand(newApplyCommand(opts), newDryRunCommand(opts), newVerifyLocalEmailCommand(opts))
+	cmd.AddCommand(newApplyCommand(opts), newDryRunCommand(opts), newVerifyLocalEmailCommand(opts), newImportUsersCommand(opts))
 	return cmd
 }
 
@@ -82,7 +82,7 @@ func newDryRunCommand(opts *options) *cobra.Command {
 					email = opts.bootstrapAdminEmail
 				}
 				details := []string{
-					"would ensure permissions: users:read, users:write, roles:read, roles:write, permissions:read",
+					"would ensure permissions: users:read, users:write, users:impersonate, roles:read, roles:write, permissions:read",
 					"would ensure roles: user, admin",
 					"would map admin role to all default permissions",
 				}
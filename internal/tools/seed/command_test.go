@@ -10,7 +10,7 @@ func TestNewRootCommandStructure(t *testing.T) {
 	if cmd.Use != "seed" {
 		t.Fatalf("unexpected root use: %s", cmd.Use)
 	}
-	for _, name := range []string{"apply", "dry-run", "verify-local-email"} {
+	for _, name := range []string{"apply", "dry-run", "verify-local-email", "import-users"} {
 		if c, _, err := cmd.Find([]string{name}); err != nil || c == nil {
 			t.Fatalf("expected subcommand %q: err=%v", name, err)
 		}
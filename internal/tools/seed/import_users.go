@@ -0,0 +1,668 @@
+package seed
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"gorm.io/gorm"
+
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/config"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/domain"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/observability"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/security"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/service"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/tools/common"
+)
+
+const (
+	onConflictSkip   = "skip"
+	onConflictUpdate = "update"
+	onConflictFail   = "fail"
+
+	importLookupChunk   = 500
+	maxImportErrorsKept = 10
+)
+
+var importCSVColumns = map[string]bool{
+	"email": true, "name": true, "status": true, "password_hash": true,
+	"email_verified": true, "roles": true, "oauth_accounts": true,
+}
+
+type importUsersOptions struct {
+	file          string
+	dryRun        bool
+	onConflict    string
+	batchSize     int
+	resetTokenTTL time.Duration
+}
+
+type importOAuthAccount struct {
+	Provider string `json:"provider"`
+	Subject  string `json:"subject"`
+}
+
+// importUserRecord is one row of the import file. CSV files use the same
+// column names, with roles separated by ";" and oauth_accounts written as
+// "provider:subject" pairs separated by ";".
+type importUserRecord struct {
+	Email         string               `json:"email"`
+	Name          string               `json:"name"`
+	Status        string               `json:"status"`
+	PasswordHash  string               `json:"password_hash"`
+	EmailVerified bool                 `json:"email_verified"`
+	Roles         []string             `json:"roles"`
+	OAuthAccounts []importOAuthAccount `json:"oauth_accounts"`
+
+	line int
+}
+
+type importAction int
+
+const (
+	importCreate importAction = iota
+	importUpdate
+	importSkip
+)
+
+type importPlanRow struct {
+	record      importUserRecord
+	action      importAction
+	existing    *domain.User
+	hasCred     bool
+	setPassword bool
+	invite      bool
+}
+
+type importUsersReport struct {
+	Created int
+	Updated int
+	Skipped int
+	Invited int
+	Notes   []string
+}
+
+func (r *importUsersReport) details(dryRun bool) []string {
+	prefix := ""
+	if dryRun {
+		prefix = "dry run: would have "
+	}
+	details := []string{fmt.Sprintf("%screated=%d updated=%d skipped=%d invited=%d", prefix, r.Created, r.Updated, r.Skipped, r.Invited)}
+	return append(details, r.Notes...)
+}
+
+func newImportUsersCommand(opts *options) *cobra.Command {
+	importOpts := &importUsersOptions{}
+	cmd := &cobra.Command{
+		Use:   "import-users",
+		Short: "Import users, credentials, identities and roles from CSV or JSONL",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			details, err := run(opts, "seed import-users", "import_users", func(ctx context.Context) ([]string, error) {
+				if err := importOpts.validate(); err != nil {
+					return nil, err
+				}
+				records, err := readImportFile(importOpts.file)
+				if err != nil {
+					return nil, err
+				}
+				cfg, db, err := loadConfigDB(opts.envFile)
+				if err != nil {
+					return nil, err
+				}
+				report, err := importUsers(ctx, cfg, db, records, importOpts, time.Now().UTC())
+				if report == nil {
+					return nil, err
+				}
+				return report.details(importOpts.dryRun), err
+			})
+			if opts.ci {
+				common.PrintCIResult(err == nil, "seed import-users", details, err)
+			}
+			if err != nil {
+				os.Exit(3)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&importOpts.file, "file", "", "users file (.csv or .jsonl)")
+	cmd.Flags().BoolVar(&importOpts.dryRun, "dry-run", false, "validate and report without writing")
+	cmd.Flags().StringVar(&importOpts.onConflict, "on-conflict", onConflictFail, "existing email handling: skip, update, or fail")
+	cmd.Flags().IntVar(&importOpts.batchSize, "batch-size", 500, "rows committed per transaction")
+	cmd.Flags().DurationVar(&importOpts.resetTokenTTL, "reset-token-ttl", 72*time.Hour, "lifetime of reset links mailed to users imported without a password")
+	return cmd
+}
+
+func (o *importUsersOptions) validate() error {
+	if strings.TrimSpace(o.file) == "" {
+		return errors.New("--file is required")
+	}
+	switch o.onConflict {
+	case onConflictSkip, onConflictUpdate, onConflictFail:
+	default:
+		return fmt.Errorf("--on-conflict must be skip, update, or fail")
+	}
+	if o.batchSize < 1 || o.batchSize > 5000 {
+		return errors.New("--batch-size must be between 1 and 5000")
+	}
+	if o.resetTokenTTL < time.Hour || o.resetTokenTTL > 30*24*time.Hour {
+		return errors.New("--reset-token-ttl must be between 1h and 720h")
+	}
+	return nil
+}
+
+func readImportFile(path string) ([]importUserRecord, error) {
+	f, err := os.Open(filepath.Clean(path)) // #nosec G304 -- import file path is an explicit operator input.
+	if err != nil {
+		return nil, fmt.Errorf("open import file: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return readImportCSV(f)
+	case ".jsonl", ".ndjson":
+		return readImportJSONL(f)
+	default:
+		return nil, fmt.Errorf("import file must end in .csv or .jsonl")
+	}
+}
+
+func readImportCSV(r io.Reader) ([]importUserRecord, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("read csv header: %w", err)
+	}
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if !importCSVColumns[name] {
+			return nil, fmt.Errorf("unknown csv column %q", name)
+		}
+		index[name] = i
+	}
+	if _, ok := index["email"]; !ok {
+		return nil, errors.New("csv header must include email")
+	}
+
+	var records []importUserRecord
+	for {
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		line, _ := reader.FieldPos(0)
+		if err != nil {
+			return nil, fmt.Errorf("read csv: %w", err)
+		}
+		field := func(name string) string {
+			if i, ok := index[name]; ok && i < len(row) {
+				return strings.TrimSpace(row[i])
+			}
+			return ""
+		}
+		rec := importUserRecord{
+			Email:        field("email"),
+			Name:         field("name"),
+			Status:       field("status"),
+			PasswordHash: field("password_hash"),
+			Roles:        splitList(field("roles")),
+			line:         line,
+		}
+		if v := field("email_verified"); v != "" {
+			verified, err := strconv.ParseBool(v)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: email_verified must be true or false", line)
+			}
+			rec.EmailVerified = verified
+		}
+		for _, pair := range splitList(field("oauth_accounts")) {
+			provider, subject, ok := strings.Cut(pair, ":")
+			if !ok {
+				return nil, fmt.Errorf("line %d: oauth_accounts entries must be provider:subject", line)
+			}
+			rec.OAuthAccounts = append(rec.OAuthAccounts, importOAuthAccount{Provider: provider, Subject: subject})
+		}
+		records = append(records, rec)
+	}
+	return records, nil
+}
+
+func readImportJSONL(r io.Reader) ([]importUserRecord, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	var records []importUserRecord
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		dec := json.NewDecoder(strings.NewReader(text))
+		dec.DisallowUnknownFields()
+		var rec importUserRecord
+		if err := dec.Decode(&rec); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		rec.line = line
+		records = append(records, rec)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read jsonl: %w", err)
+	}
+	return records, nil
+}
+
+func splitList(value string) []string {
+	var out []string
+	for _, part := range strings.Split(value, ";") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
+// normalize cleans a record in place and reports the first problem with it.
+func (rec *importUserRecord) normalize() error {
+	rec.Email = strings.TrimSpace(strings.ToLower(rec.Email))
+	if rec.Email == "" {
+		return errors.New("email is required")
+	}
+	if _, err := mail.ParseAddress(rec.Email); err != nil {
+		return fmt.Errorf("invalid email %q", rec.Email)
+	}
+	rec.Name = strings.TrimSpace(rec.Name)
+	if rec.Name == "" {
+		return errors.New("name is required")
+	}
+	rec.Status = strings.TrimSpace(strings.ToLower(rec.Status))
+	switch rec.Status {
+	case "", domain.UserStatusActive, domain.UserStatusSuspended, domain.UserStatusDisabled, domain.UserStatusPending:
+	default:
+		return fmt.Errorf("status %q cannot be imported", rec.Status)
+	}
+	rec.PasswordHash = strings.TrimSpace(rec.PasswordHash)
+	if rec.PasswordHash != "" && security.PasswordHashAlgorithm(rec.PasswordHash) == "" {
+		return errors.New("password_hash must be argon2id, bcrypt or scrypt")
+	}
+	roles := rec.Roles[:0]
+	seen := map[string]bool{}
+	for _, role := range rec.Roles {
+		if role = strings.TrimSpace(role); role != "" && !seen[role] {
+			seen[role] = true
+			roles = append(roles, role)
+		}
+	}
+	rec.Roles = roles
+	for i := range rec.OAuthAccounts {
+		account := &rec.OAuthAccounts[i]
+		account.Provider = strings.TrimSpace(strings.ToLower(account.Provider))
+		account.Subject = strings.TrimSpace(account.Subject)
+		if account.Provider == "" || len(account.Provider) > 32 || account.Subject == "" || len(account.Subject) > 255 {
+			return errors.New("oauth accounts need a provider (max 32 chars) and subject (max 255 chars)")
+		}
+	}
+	return nil
+}
+
+// importUsers validates every row and resolves conflicts before writing, so a
+// bad file or an --on-conflict=fail hit changes nothing. Rows are then written
+// in --batch-size transactions; a failed batch rolls back on its own and
+// stops the import, leaving earlier batches committed.
+func importUsers(ctx context.Context, cfg *config.Config, db *gorm.DB, records []importUserRecord, opts *importUsersOptions, now time.Time) (*importUsersReport, error) {
+	if len(records) == 0 {
+		return nil, errors.New("import file has no rows")
+	}
+	var problems []string
+	emails := make(map[string]int, len(records))
+	identities := map[string]int{}
+	roleNames := map[string]bool{}
+	for i := range records {
+		rec := &records[i]
+		if err := rec.normalize(); err != nil {
+			problems = append(problems, fmt.Sprintf("line %d: %v", rec.line, err))
+			continue
+		}
+		if first, ok := emails[rec.Email]; ok {
+			problems = append(problems, fmt.Sprintf("line %d: duplicate of line %d", rec.line, first))
+		}
+		emails[rec.Email] = rec.line
+		for _, account := range rec.OAuthAccounts {
+			key := account.Provider + ":" + account.Subject
+			if first, ok := identities[key]; ok && first != rec.line {
+				problems = append(problems, fmt.Sprintf("line %d: oauth account %s also on line %d", rec.line, key, first))
+			}
+			identities[key] = rec.line
+		}
+		for _, role := range rec.Roles {
+			roleNames[role] = true
+		}
+	}
+	if len(problems) > 0 {
+		return nil, importProblems("invalid rows", problems)
+	}
+
+	roles, err := loadImportRoles(db, roleNames)
+	if err != nil {
+		return nil, err
+	}
+	plan, err := planImport(db, records, opts.onConflict)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &importUsersReport{}
+	for _, row := range plan {
+		switch row.action {
+		case importCreate:
+			report.Created++
+		case importUpdate:
+			report.Updated++
+			report.Notes = append(report.Notes, fmt.Sprintf("line %d: updated %s", row.record.line, row.record.Email))
+		case importSkip:
+			report.Skipped++
+			report.Notes = append(report.Notes, fmt.Sprintf("line %d: skipped existing %s", row.record.line, row.record.Email))
+		}
+		if row.invite {
+			report.Invited++
+		}
+	}
+	if report.Invited > 0 && !cfg.NotificationOutboxEnabled {
+		return nil, fmt.Errorf("%d rows have no password_hash or oauth account and need a reset link, which requires NOTIFICATION_OUTBOX_ENABLED", report.Invited)
+	}
+	if opts.dryRun {
+		return report, nil
+	}
+
+	for start := 0; start < len(plan); start += opts.batchSize {
+		end := min(start+opts.batchSize, len(plan))
+		batch := plan[start:end]
+		invites := 0
+		err := db.Transaction(func(tx *gorm.DB) error {
+			for _, row := range batch {
+				if row.action == importSkip {
+					continue
+				}
+				if err := applyImportRow(tx, cfg, row, roles, opts.resetTokenTTL, now); err != nil {
+					return fmt.Errorf("line %d: %w", row.record.line, err)
+				}
+				if row.invite {
+					invites++
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			report.Notes = append(report.Notes, fmt.Sprintf("rows before line %d were committed; later rows were not imported", batch[0].record.line))
+			return report, fmt.Errorf("import batch starting at line %d failed: %w", batch[0].record.line, err)
+		}
+		for i := 0; i < invites; i++ {
+			observability.RecordNotificationOutboxEvent(ctx, service.OutboxKindPasswordReset, "enqueued")
+		}
+	}
+	return report, nil
+}
+
+func importProblems(what string, problems []string) error {
+	listed := problems
+	if len(listed) > maxImportErrorsKept {
+		listed = listed[:maxImportErrorsKept]
+	}
+	msg := fmt.Sprintf("%d %s: %s", len(problems), what, strings.Join(listed, "; "))
+	if len(problems) > len(listed) {
+		msg += fmt.Sprintf("; and %d more", len(problems)-len(listed))
+	}
+	return errors.New(msg)
+}
+
+// loadImportRoles resolves role names, plus the default "user" role that
+// rows without roles receive, as self-registration does.
+func loadImportRoles(db *gorm.DB, names map[string]bool) (map[string]domain.Role, error) {
+	lookup := []string{"user"}
+	for name := range names {
+		lookup = append(lookup, name)
+	}
+	var found []domain.Role
+	if err := db.Where("name IN ?", lookup).Find(&found).Error; err != nil {
+		return nil, err
+	}
+	roles := make(map[string]domain.Role, len(found))
+	for _, role := range found {
+		roles[role.Name] = role
+	}
+	var missing []string
+	for name := range names {
+		if _, ok := roles[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("unknown roles: %s", strings.Join(missing, ", "))
+	}
+	return roles, nil
+}
+
+func planImport(db *gorm.DB, records []importUserRecord, onConflict string) ([]importPlanRow, error) {
+	emails := make([]string, 0, len(records))
+	for _, rec := range records {
+		emails = append(emails, rec.Email)
+	}
+	existing := map[string]*domain.User{}
+	for _, chunk := range chunkStrings(emails) {
+		var users []domain.User
+		if err := db.Where("email IN ?", chunk).Find(&users).Error; err != nil {
+			return nil, err
+		}
+		for i := range users {
+			existing[users[i].Email] = &users[i]
+		}
+	}
+
+	userIDs := make([]uint, 0, len(existing))
+	for _, u := range existing {
+		userIDs = append(userIDs, u.ID)
+	}
+	credHashes := map[uint]string{}
+	for start := 0; start < len(userIDs); start += importLookupChunk {
+		var creds []domain.LocalCredential
+		chunk := userIDs[start:min(start+importLookupChunk, len(userIDs))]
+		if err := db.Select("user_id", "password_hash").Where("user_id IN ?", chunk).Find(&creds).Error; err != nil {
+			return nil, err
+		}
+		for _, cred := range creds {
+			credHashes[cred.UserID] = cred.PasswordHash
+		}
+	}
+
+	linked, err := loadLinkedIdentities(db, records)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := make([]importPlanRow, 0, len(records))
+	var conflicts []string
+	for _, rec := range records {
+		row := importPlanRow{record: rec, action: importCreate, setPassword: rec.PasswordHash != ""}
+		if u, ok := existing[rec.Email]; ok {
+			row.existing = u
+			currentHash, hasCred := credHashes[u.ID]
+			row.hasCred = hasCred
+			// Only an invited credential, which has no usable password yet,
+			// takes a hash on update.
+			row.setPassword = rec.PasswordHash != "" && currentHash == ""
+			switch {
+			case onConflict == onConflictFail:
+				conflicts = append(conflicts, fmt.Sprintf("line %d: %s already exists", rec.line, rec.Email))
+			case onConflict == onConflictSkip, u.Status == domain.UserStatusPendingDeletion:
+				row.action = importSkip
+			default:
+				row.action = importUpdate
+				// Changing either would have to revoke the user's sessions and
+				// be audited, which the admin endpoints and password reset do.
+				if rec.Status != "" && rec.Status != u.Status {
+					conflicts = append(conflicts, fmt.Sprintf("line %d: cannot change status of existing %s from %s to %s; use the admin status endpoints", rec.line, rec.Email, u.Status, rec.Status))
+				}
+				if rec.PasswordHash != "" && currentHash != "" && rec.PasswordHash != currentHash {
+					conflicts = append(conflicts, fmt.Sprintf("line %d: cannot replace the password of existing %s; use password reset", rec.line, rec.Email))
+				}
+			}
+		}
+		if row.action != importSkip {
+			for _, account := range rec.OAuthAccounts {
+				owner, ok := linked[account.Provider+":"+account.Subject]
+				if !ok || (row.existing != nil && owner == row.existing.ID) {
+					continue
+				}
+				if onConflict == onConflictSkip {
+					row.action = importSkip
+					break
+				}
+				conflicts = append(conflicts, fmt.Sprintf("line %d: oauth account %s:%s belongs to another user", rec.line, account.Provider, account.Subject))
+			}
+		}
+		row.invite = row.action != importSkip && rec.PasswordHash == "" && len(rec.OAuthAccounts) == 0 && !row.hasCred
+		plan = append(plan, row)
+	}
+	if len(conflicts) > 0 {
+		return nil, importProblems("conflicts", conflicts)
+	}
+	return plan, nil
+}
+
+func loadLinkedIdentities(db *gorm.DB, records []importUserRecord) (map[string]uint, error) {
+	byProvider := map[string][]string{}
+	for _, rec := range records {
+		for _, account := range rec.OAuthAccounts {
+			byProvider[account.Provider] = append(byProvider[account.Provider], account.Subject)
+		}
+	}
+	linked := map[string]uint{}
+	for provider, subjects := range byProvider {
+		for _, chunk := range chunkStrings(subjects) {
+			var accounts []domain.OAuthAccount
+			if err := db.Where("provider = ? AND provider_user_id IN ?", provider, chunk).Find(&accounts).Error; err != nil {
+				return nil, err
+			}
+			for _, account := range accounts {
+				linked[account.Provider+":"+account.ProviderUserID] = account.UserID
+			}
+		}
+	}
+	return linked, nil
+}
+
+func chunkStrings(values []string) [][]string {
+	var chunks [][]string
+	for start := 0; start < len(values); start += importLookupChunk {
+		chunks = append(chunks, values[start:min(start+importLookupChunk, len(values))])
+	}
+	return chunks
+}
+
+// applyImportRow writes one planned row. Updates are additive: roles and
+// identities are added, never removed. Planning has already refused status
+// and password changes for existing users, so an update never writes status
+// and only sets a password on a credential that has none.
+func applyImportRow(tx *gorm.DB, cfg *config.Config, row importPlanRow, roles map[string]domain.Role, resetTTL time.Duration, now time.Time) error {
+	rec := row.record
+	var user domain.User
+	if row.action == importCreate {
+		status := rec.Status
+		if status == "" {
+			status = domain.UserStatusActive
+		}
+		user = domain.User{Email: rec.Email, Name: rec.Name, Status: status}
+		if err := tx.Create(&user).Error; err != nil {
+			return err
+		}
+	} else {
+		user = *row.existing
+		if err := tx.Model(&domain.User{}).Where("id = ?", user.ID).
+			Updates(map[string]any{"name": rec.Name, "updated_at": now}).Error; err != nil {
+			return err
+		}
+	}
+
+	var verifiedAt *time.Time
+	if rec.EmailVerified {
+		verifiedAt = &now
+	}
+	switch {
+	case row.hasCred && rec.PasswordHash != "":
+		updates := map[string]any{"updated_at": now}
+		if row.setPassword {
+			updates["password_hash"] = rec.PasswordHash
+			updates["password_changed_at"] = now
+		}
+		if rec.EmailVerified {
+			updates["email_verified"] = true
+			updates["email_verified_at"] = verifiedAt
+		}
+		if err := tx.Model(&domain.LocalCredential{}).Where("user_id = ?", user.ID).Updates(updates).Error; err != nil {
+			return err
+		}
+	case !row.hasCred && (rec.PasswordHash != "" || row.invite):
+		// An invited user gets a credential with no usable password; the
+		// reset link is the only way to set one.
+		credential := &domain.LocalCredential{
+			UserID:          user.ID,
+			PasswordHash:    rec.PasswordHash,
+			EmailVerified:   rec.EmailVerified,
+			EmailVerifiedAt: verifiedAt,
+		}
+		if rec.PasswordHash != "" {
+			credential.PasswordChangedAt = &now
+		}
+		if err := tx.Create(credential).Error; err != nil {
+			return err
+		}
+	}
+
+	for _, account := range rec.OAuthAccounts {
+		link := domain.OAuthAccount{UserID: user.ID, Provider: account.Provider, ProviderUserID: account.Subject, EmailVerified: rec.EmailVerified}
+		if err := tx.Where("provider = ? AND provider_user_id = ?", account.Provider, account.Subject).FirstOrCreate(&link).Error; err != nil {
+			return err
+		}
+	}
+
+	assign := make([]domain.Role, 0, len(rec.Roles))
+	for _, name := range rec.Roles {
+		assign = append(assign, roles[name])
+	}
+	if len(rec.Roles) == 0 && row.action == importCreate {
+		if role, ok := roles["user"]; ok {
+			assign = append(assign, role)
+		}
+	}
+	if len(assign) > 0 {
+		if err := tx.Model(&user).Association("Roles").Append(&assign); err != nil {
+			return err
+		}
+	}
+
+	if row.invite {
+		token, msg, err := service.NewPasswordResetInvite(cfg, user.ID, user.Email, resetTTL, now)
+		if err != nil {
+			return err
+		}
+		if err := tx.Create(token).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(msg).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,247 @@
+package seed
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/config"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/database"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/domain"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/security"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/service"
+)
+
+func newImportTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	dsn := fmt.Sprintf("file:%s?mode=memory&cache=shared", strings.ReplaceAll(t.Name(), "/", "_"))
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	if err := database.Migrate(db); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	if err := database.Seed(db, ""); err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+	return db
+}
+
+func writeImportFile(t *testing.T, name, content string) []importUserRecord {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write import file: %v", err)
+	}
+	records, err := readImportFile(path)
+	if err != nil {
+		t.Fatalf("read import file: %v", err)
+	}
+	return records
+}
+
+func defaultImportOptions(onConflict string) *importUsersOptions {
+	return &importUsersOptions{onConflict: onConflict, batchSize: 2, resetTokenTTL: 72 * time.Hour}
+}
+
+func TestImportUsersFromCSV(t *testing.T) {
+	db := newImportTestDB(t)
+	hash, err := bcrypt.GenerateFromPassword([]byte("Legacy#Pass123"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("bcrypt: %v", err)
+	}
+	records := writeImportFile(t, "users.csv", "email,name,password_hash,email_verified,roles,oauth_accounts\n"+
+		"Alice@Example.com,Alice,"+string(hash)+",true,admin;user,\n"+
+		"bob@example.com,Bob,,false,,google:bob-123\n"+
+		"carol@example.com,Carol,,,,\n")
+	cfg := &config.Config{NotificationOutboxEnabled: true, NotificationOutboxEncryptionKey: "outbox-encryption-key-0123456789abcdef", AuthPasswordResetBaseURL: "https://app.example.com/reset"}
+
+	report, err := importUsers(context.Background(), cfg, db, records, defaultImportOptions(onConflictFail), time.Now().UTC())
+	if err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	if report.Created != 3 || report.Invited != 1 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+
+	var alice domain.User
+	if err := db.Preload("Roles").Where("email = ?", "alice@example.com").First(&alice).Error; err != nil {
+		t.Fatalf("load alice: %v", err)
+	}
+	if len(alice.Roles) != 2 {
+		t.Fatalf("expected alice to have two roles, got %+v", alice.Roles)
+	}
+	var cred domain.LocalCredential
+	if err := db.Where("user_id = ?", alice.ID).First(&cred).Error; err != nil {
+		t.Fatalf("load credential: %v", err)
+	}
+	if ok, err := security.VerifyPassword(cred.PasswordHash, "Legacy#Pass123"); err != nil || !ok || !cred.EmailVerified {
+		t.Fatalf("expected imported bcrypt hash to verify: ok=%v err=%v cred=%+v", ok, err, cred)
+	}
+
+	var bob domain.User
+	if err := db.Preload("Roles").Where("email = ?", "bob@example.com").First(&bob).Error; err != nil {
+		t.Fatalf("load bob: %v", err)
+	}
+	if len(bob.Roles) != 1 || bob.Roles[0].Name != "user" {
+		t.Fatalf("expected bob to get the default user role, got %+v", bob.Roles)
+	}
+	var links int64
+	db.Model(&domain.OAuthAccount{}).Where("user_id = ? AND provider = ? AND provider_user_id = ?", bob.ID, "google", "bob-123").Count(&links)
+	if links != 1 {
+		t.Fatalf("expected bob's google identity to be linked, got %d", links)
+	}
+
+	var carol domain.User
+	if err := db.Where("email = ?", "carol@example.com").First(&carol).Error; err != nil {
+		t.Fatalf("load carol: %v", err)
+	}
+	var tokens, messages int64
+	db.Model(&domain.VerificationToken{}).Where("user_id = ? AND purpose = ?", carol.ID, "password_reset").Count(&tokens)
+	db.Model(&domain.OutboxMessage{}).Where("user_id = ? AND kind = ?", carol.ID, service.OutboxKindPasswordReset).Count(&messages)
+	if tokens != 1 || messages != 1 {
+		t.Fatalf("expected a reset token and outbox message for carol, got tokens=%d messages=%d", tokens, messages)
+	}
+}
+
+func TestImportUsersConflictModes(t *testing.T) {
+	db := newImportTestDB(t)
+	existing := domain.User{Email: "dana@example.com", Name: "Dana", Status: domain.UserStatusActive}
+	if err := db.Create(&existing).Error; err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	content := `{"email":"dana@example.com","name":"Dana Renamed","roles":["admin"],"oauth_accounts":[{"provider":"github","subject":"dana"}]}
+{"email":"erin@example.com","name":"Erin","oauth_accounts":[{"provider":"github","subject":"erin"}]}
+`
+	cfg := &config.Config{}
+	now := time.Now().UTC()
+
+	records := writeImportFile(t, "users.jsonl", content)
+	if _, err := importUsers(context.Background(), cfg, db, records, defaultImportOptions(onConflictFail), now); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Fatalf("expected fail mode to reject existing email, got %v", err)
+	}
+	var count int64
+	db.Model(&domain.User{}).Where("email = ?", "erin@example.com").Count(&count)
+	if count != 0 {
+		t.Fatal("expected nothing to be written when a conflict fails the import")
+	}
+
+	records = writeImportFile(t, "users.jsonl", content)
+	dryRun := defaultImportOptions(onConflictSkip)
+	dryRun.dryRun = true
+	report, err := importUsers(context.Background(), cfg, db, records, dryRun, now)
+	if err != nil || report.Created != 1 || report.Skipped != 1 {
+		t.Fatalf("unexpected dry-run result: report=%+v err=%v", report, err)
+	}
+	db.Model(&domain.User{}).Where("email = ?", "erin@example.com").Count(&count)
+	if count != 0 {
+		t.Fatal("expected dry run to write nothing")
+	}
+
+	records = writeImportFile(t, "users.jsonl", content)
+	report, err = importUsers(context.Background(), cfg, db, records, defaultImportOptions(onConflictUpdate), now)
+	if err != nil || report.Created != 1 || report.Updated != 1 {
+		t.Fatalf("unexpected update result: report=%+v err=%v", report, err)
+	}
+	var dana domain.User
+	if err := db.Preload("Roles").First(&dana, existing.ID).Error; err != nil {
+		t.Fatalf("load dana: %v", err)
+	}
+	if dana.Name != "Dana Renamed" || len(dana.Roles) != 1 || dana.Roles[0].Name != "admin" {
+		t.Fatalf("expected dana to be updated, got %+v", dana)
+	}
+}
+
+func TestImportUsersRejectsInvalidRows(t *testing.T) {
+	db := newImportTestDB(t)
+	records := writeImportFile(t, "users.jsonl", `{"email":"not-an-email","name":"X"}
+{"email":"frank@example.com","name":"Frank","password_hash":"plaintext"}
+{"email":"gina@example.com","name":"Gina","roles":["nope"]}
+{"email":"gina@example.com","name":"Gina Again"}
+`)
+	_, err := importUsers(context.Background(), &config.Config{}, db, records, defaultImportOptions(onConflictFail), time.Now().UTC())
+	if err == nil {
+		t.Fatal("expected invalid rows to be rejected")
+	}
+	for _, want := range []string{"line 1:", "line 2:", "line 4: duplicate of line 3"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Fatalf("expected %q in error, got %v", want, err)
+		}
+	}
+
+	records = writeImportFile(t, "users.csv", "email,name\nhal@example.com,Hal\n")
+	if _, err := importUsers(context.Background(), &config.Config{}, db, records, defaultImportOptions(onConflictFail), time.Now().UTC()); err == nil || !strings.Contains(err.Error(), "NOTIFICATION_OUTBOX_ENABLED") {
+		t.Fatalf("expected invites to require the outbox, got %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "users.csv")
+	if err := os.WriteFile(path, []byte("email,name,nickname\n"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if _, err := readImportFile(path); err == nil {
+		t.Fatal("expected unknown csv column to be rejected")
+	}
+}
+
+func TestImportUsersLeavesStatusAndPasswordOfExistingUsers(t *testing.T) {
+	db := newImportTestDB(t)
+	hash, err := bcrypt.GenerateFromPassword([]byte("Legacy#Pass123"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("hash: %v", err)
+	}
+	ivy := domain.User{Email: "ivy@example.com", Name: "Ivy", Status: domain.UserStatusSuspended}
+	if err := db.Create(&ivy).Error; err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	if err := db.Create(&domain.LocalCredential{UserID: ivy.ID, PasswordHash: string(hash)}).Error; err != nil {
+		t.Fatalf("create credential: %v", err)
+	}
+	cfg := &config.Config{}
+	now := time.Now().UTC()
+
+	records := writeImportFile(t, "users.jsonl", fmt.Sprintf(`{"email":"ivy@example.com","name":"Ivy Renamed","password_hash":%q}`+"\n", hash))
+	if report, err := importUsers(context.Background(), cfg, db, records, defaultImportOptions(onConflictUpdate), now); err != nil || report.Updated != 1 {
+		t.Fatalf("expected re-import of the same hash to update, got report=%+v err=%v", report, err)
+	}
+	var got domain.User
+	if err := db.First(&got, ivy.ID).Error; err != nil {
+		t.Fatalf("load ivy: %v", err)
+	}
+	if got.Name != "Ivy Renamed" || got.Status != domain.UserStatusSuspended {
+		t.Fatalf("expected a row without status to leave ivy suspended, got %+v", got)
+	}
+
+	other, err := bcrypt.GenerateFromPassword([]byte("Other#Pass1234"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("hash: %v", err)
+	}
+	for _, tc := range []struct {
+		row  string
+		want string
+	}{
+		{row: `{"email":"ivy@example.com","name":"Ivy","status":"active"}`, want: "cannot change status"},
+		{row: fmt.Sprintf(`{"email":"ivy@example.com","name":"Ivy","password_hash":%q}`, other), want: "cannot replace the password"},
+	} {
+		records := writeImportFile(t, "users.jsonl", tc.row+"\n")
+		if _, err := importUsers(context.Background(), cfg, db, records, defaultImportOptions(onConflictUpdate), now); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Fatalf("expected %q, got %v", tc.want, err)
+		}
+	}
+	var cred domain.LocalCredential
+	if err := db.Where("user_id = ?", ivy.ID).First(&cred).Error; err != nil {
+		t.Fatalf("load credential: %v", err)
+	}
+	if cred.PasswordHash != string(hash) {
+		t.Fatal("expected the existing password to be kept")
+	}
+}
@@ -50,6 +50,7 @@ GOTOOLCHAIN=go1.26.0 ./bin/govulncheck ./...
 echo "ci: cli smoke"
 go run ./cmd/migrate --help >/dev/null
 go run ./cmd/seed --help >/dev/null
+go run ./cmd/keys --help >/dev/null
 go run ./cmd/loadgen --help >/dev/null
 go run ./cmd/obscheck --help >/dev/null
 
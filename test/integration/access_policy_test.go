@@ -0,0 +1,154 @@
+package integration
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/config"
+)
+
+func TestAccessPoliciesNarrowPermissionChecks(t *testing.T) {
+	baseURL, adminClient, closeFn := newAuthTestServerWithOptions(t, authTestServerOptions{
+		cfgOverride: func(cfg *config.Config) {
+			cfg.BootstrapAdminEmail = "admin-policies@example.com"
+			cfg.OrganizationsEnabled = true
+			cfg.AccessPoliciesEnabled = true
+		},
+	})
+	defer closeFn()
+
+	registerAndLogin(t, adminClient, baseURL, "admin-policies@example.com", "Valid#Pass1234")
+	adminID := meID(t, adminClient, baseURL)
+	aliceClient := newSessionClient(t)
+	registerAndLogin(t, aliceClient, baseURL, "alice-policies@example.com", "Valid#Pass1234")
+	aliceID := meID(t, aliceClient, baseURL)
+	bobClient := newSessionClient(t)
+	registerAndLogin(t, bobClient, baseURL, "bob-policies@example.com", "Valid#Pass1234")
+	bobID := meID(t, bobClient, baseURL)
+
+	resp, env := doJSON(t, adminClient, http.MethodPost, baseURL+"/api/v1/admin/orgs", map[string]string{"slug": "acme", "name": "Acme"}, nil)
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("create org failed: status=%d err=%#v", resp.StatusCode, env.Error)
+	}
+	var org struct {
+		ID uint `json:"id"`
+	}
+	if err := json.Unmarshal(env.Data, &org); err != nil {
+		t.Fatalf("decode org: %v", err)
+	}
+	for _, userID := range []uint{adminID, aliceID} {
+		if resp, env := doJSON(t, adminClient, http.MethodPut, baseURL+"/api/v1/orgs/"+itoa(org.ID)+"/members/"+itoa(userID), map[string]any{"role_ids": []uint{}}, nil); resp.StatusCode != http.StatusOK {
+			t.Fatalf("add member failed: status=%d err=%#v", resp.StatusCode, env.Error)
+		}
+	}
+
+	if resp, _ := doJSON(t, aliceClient, http.MethodGet, baseURL+"/api/v1/admin/policies", nil, nil); resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("expected non-admin to be denied policy reads, got %d", resp.StatusCode)
+	}
+	if resp, env := doJSON(t, adminClient, http.MethodPost, baseURL+"/api/v1/admin/policies", map[string]any{
+		"name": "bad", "permission": "users:write", "effect": "require", "condition": "user.id == 1",
+	}, nil); resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected invalid condition to be rejected, got status=%d err=%#v", resp.StatusCode, env.Error)
+	}
+	resp, env = doJSON(t, adminClient, http.MethodPost, baseURL+"/api/v1/admin/policies", map[string]any{
+		"name":           "same-org-user-writes",
+		"permission":     "users:write",
+		"effect":         "require",
+		"condition":      "intersects(subject.org_ids, resource.org_ids)",
+		"resource_type":  "user",
+		"resource_param": "id",
+	}, nil)
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("create policy failed: status=%d err=%#v", resp.StatusCode, env.Error)
+	}
+	var sameOrg struct {
+		ID uint `json:"id"`
+	}
+	if err := json.Unmarshal(env.Data, &sameOrg); err != nil {
+		t.Fatalf("decode policy: %v", err)
+	}
+
+	suspend := map[string]string{"reason": "policy test"}
+	resp, env = doJSON(t, adminClient, http.MethodPost, baseURL+"/api/v1/admin/users/"+itoa(bobID)+"/suspend", suspend, nil)
+	if resp.StatusCode != http.StatusForbidden || env.Error == nil || !strings.Contains(string(env.Error.Details), "same-org-user-writes") {
+		t.Fatalf("expected policy to block writes outside the admin's org, got status=%d err=%#v", resp.StatusCode, env.Error)
+	}
+	if resp, env := doJSON(t, adminClient, http.MethodPost, baseURL+"/api/v1/admin/users/"+itoa(aliceID)+"/suspend", suspend, nil); resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected write on a same-org user to pass, got status=%d err=%#v", resp.StatusCode, env.Error)
+	}
+
+	if resp, env := doJSON(t, adminClient, http.MethodPatch, baseURL+"/api/v1/admin/policies/"+itoa(sameOrg.ID), map[string]any{"enabled": false}, nil); resp.StatusCode != http.StatusOK {
+		t.Fatalf("disable policy failed: status=%d err=%#v", resp.StatusCode, env.Error)
+	}
+	if resp, env := doJSON(t, adminClient, http.MethodPost, baseURL+"/api/v1/admin/users/"+itoa(bobID)+"/suspend", suspend, nil); resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected disabled policy to stop applying, got status=%d err=%#v", resp.StatusCode, env.Error)
+	}
+
+	// A deny policy matching every request cannot lock administrators out of
+	// the policy API itself.
+	resp, env = doJSON(t, adminClient, http.MethodPost, baseURL+"/api/v1/admin/policies", map[string]any{
+		"name": "loopback-lockdown", "permission": "*:*", "effect": "deny", "condition": `cidr(request.ip, "127.0.0.0/8", "::1/128")`,
+	}, nil)
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("create deny policy failed: status=%d err=%#v", resp.StatusCode, env.Error)
+	}
+	var lockdown struct {
+		ID uint `json:"id"`
+	}
+	if err := json.Unmarshal(env.Data, &lockdown); err != nil {
+		t.Fatalf("decode policy: %v", err)
+	}
+	if resp, _ := doJSON(t, adminClient, http.MethodGet, baseURL+"/api/v1/admin/users", nil, nil); resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("expected deny policy to block admin reads, got %d", resp.StatusCode)
+	}
+	if resp, env := doJSON(t, adminClient, http.MethodDelete, baseURL+"/api/v1/admin/policies/"+itoa(lockdown.ID), nil, nil); resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected policy API to stay reachable, got status=%d err=%#v", resp.StatusCode, env.Error)
+	}
+	if resp, _ := doJSON(t, adminClient, http.MethodGet, baseURL+"/api/v1/admin/users", nil, nil); resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected admin reads to recover after deleting the policy, got %d", resp.StatusCode)
+	}
+}
+
+func TestAccessPolicyRequestIPIgnoresSpoofedForwardingHeaders(t *testing.T) {
+	spoofed := map[string]string{
+		"X-Forwarded-For": "203.0.113.9",
+		"X-Real-IP":       "203.0.113.9",
+		"True-Client-IP":  "203.0.113.9",
+	}
+	for _, tt := range []struct {
+		name    string
+		proxies []string
+		want    int
+	}{
+		{name: "direct peer", want: http.StatusForbidden},
+		{name: "trusted proxy", proxies: []string{"127.0.0.0/8", "::1/128"}, want: http.StatusOK},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			baseURL, client, closeFn := newAuthTestServerWithOptions(t, authTestServerOptions{
+				cfgOverride: func(cfg *config.Config) {
+					cfg.BootstrapAdminEmail = "admin-policy-ip@example.com"
+					cfg.AccessPoliciesEnabled = true
+					cfg.AccessPolicyTrustedProxyCIDRs = tt.proxies
+				},
+			})
+			defer closeFn()
+			registerAndLogin(t, client, baseURL, "admin-policy-ip@example.com", "Valid#Pass1234")
+
+			resp, env := doJSON(t, client, http.MethodPost, baseURL+"/api/v1/admin/policies", map[string]any{
+				"name": "office-only", "permission": "users:read", "effect": "require", "condition": `cidr(request.ip, "203.0.113.0/24")`,
+			}, nil)
+			if resp.StatusCode != http.StatusCreated {
+				t.Fatalf("create policy failed: status=%d err=%#v", resp.StatusCode, env.Error)
+			}
+			if resp, _ := doJSON(t, client, http.MethodGet, baseURL+"/api/v1/admin/users", nil, nil); resp.StatusCode != http.StatusForbidden {
+				t.Fatalf("expected a loopback caller to be outside the office range, got %d", resp.StatusCode)
+			}
+			resp, env = doJSON(t, client, http.MethodGet, baseURL+"/api/v1/admin/users", nil, spoofed)
+			if resp.StatusCode != tt.want {
+				t.Fatalf("expected %d with forwarding headers, got status=%d err=%#v", tt.want, resp.StatusCode, env.Error)
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package integration
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestAccessTokensAreRejectedImmediatelyAfterRevocation(t *testing.T) {
+	baseURL, client, closeFn := newAuthTestServer(t)
+	defer closeFn()
+
+	registerAndLogin(t, client, baseURL, "revocation@example.com", "Valid#Pass1234")
+	accessA := cookieValue(t, client, baseURL, "access_token")
+
+	resp, env := doJSON(t, client, http.MethodPost, baseURL+"/api/v1/auth/local/login", map[string]string{
+		"email":    "revocation@example.com",
+		"password": "Valid#Pass1234",
+	}, nil)
+	if resp.StatusCode != http.StatusOK || !env.Success {
+		t.Fatalf("second login failed: status=%d err=%#v", resp.StatusCode, env.Error)
+	}
+	accessB := cookieValue(t, client, baseURL, "access_token")
+
+	bearerMe := func(token string) int {
+		resp, _ := doJSON(t, &http.Client{}, http.MethodGet, baseURL+"/api/v1/me", nil, map[string]string{
+			"Authorization": "Bearer " + token,
+		})
+		return resp.StatusCode
+	}
+	if code := bearerMe(accessA); code != http.StatusOK {
+		t.Fatalf("expected first access token to be valid, got %d", code)
+	}
+
+	resp, env = doJSON(t, client, http.MethodPost, baseURL+"/api/v1/me/sessions/revoke-others", nil, map[string]string{
+		"X-CSRF-Token": cookieValue(t, client, baseURL, "csrf_token"),
+	})
+	if resp.StatusCode != http.StatusOK || !env.Success {
+		t.Fatalf("revoke others failed: status=%d err=%#v", resp.StatusCode, env.Error)
+	}
+	if code := bearerMe(accessA); code != http.StatusUnauthorized {
+		t.Fatalf("expected access token of revoked session to be rejected, got %d", code)
+	}
+	if code := bearerMe(accessB); code != http.StatusOK {
+		t.Fatalf("expected current session access token to stay valid, got %d", code)
+	}
+
+	resp, env = doJSON(t, client, http.MethodPost, baseURL+"/api/v1/auth/logout", nil, map[string]string{
+		"X-CSRF-Token": cookieValue(t, client, baseURL, "csrf_token"),
+	})
+	if resp.StatusCode != http.StatusOK || !env.Success {
+		t.Fatalf("logout failed: status=%d err=%#v", resp.StatusCode, env.Error)
+	}
+	if code := bearerMe(accessB); code != http.StatusUnauthorized {
+		t.Fatalf("expected access token to be rejected right after logout, got %d", code)
+	}
+}
@@ -0,0 +1,214 @@
+package integration
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/config"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/security"
+)
+
+func TestAccountExportAndSelfServiceDeletion(t *testing.T) {
+	baseURL, client, closeFn := newAuthTestServerWithOptions(t, authTestServerOptions{
+		cfgOverride: func(cfg *config.Config) {
+			cfg.AuthAccountDeletionGracePeriod = 24 * time.Hour
+		},
+	})
+	defer closeFn()
+
+	registerAndLogin(t, client, baseURL, "leaving@example.com", "Valid#Pass1234")
+	access := cookieValue(t, client, baseURL, "access_token")
+
+	resp, env := doJSON(t, client, http.MethodGet, baseURL+"/api/v1/me/export", nil, nil)
+	if resp.StatusCode != http.StatusOK || !env.Success {
+		t.Fatalf("export failed: status=%d err=%#v", resp.StatusCode, env.Error)
+	}
+	var export struct {
+		Profile struct {
+			Email string `json:"email"`
+		} `json:"profile"`
+		Roles           []string          `json:"roles"`
+		LocalCredential *json.RawMessage  `json:"local_credential"`
+		Sessions        []json.RawMessage `json:"sessions"`
+		LoginHistory    []json.RawMessage `json:"login_history"`
+	}
+	if err := json.Unmarshal(env.Data, &export); err != nil {
+		t.Fatalf("decode export: %v", err)
+	}
+	if export.Profile.Email != "leaving@example.com" || export.LocalCredential == nil || len(export.Roles) == 0 {
+		t.Fatalf("unexpected export: %s", string(env.Data))
+	}
+	// Registration and the following login each start a session.
+	if len(export.Sessions) != 2 || len(export.LoginHistory) != 2 {
+		t.Fatalf("expected both sign-ins to appear in the export, got %s", string(env.Data))
+	}
+
+	csrf := map[string]string{"X-CSRF-Token": cookieValue(t, client, baseURL, "csrf_token")}
+	resp, env = doJSON(t, client, http.MethodDelete, baseURL+"/api/v1/me", nil, csrf)
+	if resp.StatusCode != http.StatusAccepted || !env.Success {
+		t.Fatalf("delete failed: status=%d err=%#v", resp.StatusCode, env.Error)
+	}
+	var scheduled struct {
+		Status              string     `json:"status"`
+		DeletionScheduledAt *time.Time `json:"deletion_scheduled_at"`
+	}
+	if err := json.Unmarshal(env.Data, &scheduled); err != nil {
+		t.Fatalf("decode delete: %v", err)
+	}
+	if scheduled.Status != "pending_deletion" || scheduled.DeletionScheduledAt == nil || time.Until(*scheduled.DeletionScheduledAt) < 23*time.Hour {
+		t.Fatalf("expected deletion after the grace period, got %+v", scheduled)
+	}
+
+	resp, _ = doJSON(t, &http.Client{}, http.MethodGet, baseURL+"/api/v1/me", nil, map[string]string{
+		"Authorization": "Bearer " + access,
+	})
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected access token to be revoked once deletion is scheduled, got %d", resp.StatusCode)
+	}
+	resp, env = doJSON(t, newSessionClient(t), http.MethodPost, baseURL+"/api/v1/auth/local/login", map[string]string{
+		"email":    "leaving@example.com",
+		"password": "Valid#Pass1234",
+	}, nil)
+	if resp.StatusCode != http.StatusForbidden || env.Error == nil || env.Error.Code != "ACCOUNT_INACTIVE" {
+		t.Fatalf("expected pending account to be unable to sign in, got status=%d err=%#v", resp.StatusCode, env.Error)
+	}
+}
+
+func TestAdminEraseUser(t *testing.T) {
+	baseURL, adminClient, closeFn := newAuthTestServerWithOptions(t, authTestServerOptions{
+		cfgOverride: func(cfg *config.Config) {
+			cfg.BootstrapAdminEmail = "admin-erase@example.com"
+		},
+	})
+	defer closeFn()
+
+	registerAndLogin(t, adminClient, baseURL, "admin-erase@example.com", "Valid#Pass1234")
+	userClient := newSessionClient(t)
+	registerAndLogin(t, userClient, baseURL, "erase-me@example.com", "Valid#Pass1234")
+
+	resp, env := doJSON(t, userClient, http.MethodGet, baseURL+"/api/v1/me", nil, nil)
+	if resp.StatusCode != http.StatusOK || !env.Success {
+		t.Fatalf("me failed: status=%d err=%#v", resp.StatusCode, env.Error)
+	}
+	var me struct {
+		ID uint `json:"id"`
+	}
+	if err := json.Unmarshal(env.Data, &me); err != nil {
+		t.Fatalf("decode me: %v", err)
+	}
+	adminCSRF := map[string]string{"X-CSRF-Token": cookieValue(t, adminClient, baseURL, "csrf_token")}
+
+	resp, env = doJSON(t, adminClient, http.MethodPost, baseURL+"/api/v1/admin/users/"+itoa(me.ID)+"/erase", map[string]string{"reason": "erasure request"}, adminCSRF)
+	if resp.StatusCode != http.StatusOK || !env.Success {
+		t.Fatalf("erase failed: status=%d err=%#v", resp.StatusCode, env.Error)
+	}
+	resp, _ = doJSON(t, userClient, http.MethodGet, baseURL+"/api/v1/me", nil, nil)
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected erased user's session to be revoked, got %d", resp.StatusCode)
+	}
+	resp, env = doJSON(t, newSessionClient(t), http.MethodPost, baseURL+"/api/v1/auth/local/login", map[string]string{
+		"email":    "erase-me@example.com",
+		"password": "Valid#Pass1234",
+	}, nil)
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected erased credentials to be gone, got status=%d err=%#v", resp.StatusCode, env.Error)
+	}
+
+	// The address is free again once the account is erased.
+	registerAndLogin(t, newSessionClient(t), baseURL, "erase-me@example.com", "Valid#Pass1234")
+}
+
+func TestAccountExportIncludesAuthEvents(t *testing.T) {
+	baseURL, client, closeFn := newAuthTestServer(t)
+	defer closeFn()
+
+	registerAndLogin(t, client, baseURL, "history@example.com", "Valid#Pass1234")
+	resp, env := doJSON(t, newSessionClient(t), http.MethodPost, baseURL+"/api/v1/auth/local/login", map[string]string{
+		"email":    "history@example.com",
+		"password": "Wrong#Pass1234",
+	}, nil)
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected failed login, got status=%d err=%#v", resp.StatusCode, env.Error)
+	}
+
+	csrf := map[string]string{"X-CSRF-Token": cookieValue(t, client, baseURL, "csrf_token")}
+	resp, env = doJSON(t, client, http.MethodPost, baseURL+"/api/v1/me/mfa/totp/setup", nil, csrf)
+	if resp.StatusCode != http.StatusOK || !env.Success {
+		t.Fatalf("totp setup failed: status=%d err=%#v", resp.StatusCode, env.Error)
+	}
+	var enrollment struct {
+		Secret string `json:"secret"`
+	}
+	if err := json.Unmarshal(env.Data, &enrollment); err != nil || enrollment.Secret == "" {
+		t.Fatalf("decode enrollment: %v %+v", err, enrollment)
+	}
+	code, err := security.TOTPCode(enrollment.Secret, security.TOTPStep(time.Now()))
+	if err != nil {
+		t.Fatalf("totp code: %v", err)
+	}
+	var confirmed struct {
+		RecoveryCodes []string `json:"recovery_codes"`
+	}
+	resp, env = doJSON(t, client, http.MethodPost, baseURL+"/api/v1/me/mfa/totp/confirm", map[string]string{"code": code}, csrf)
+	if resp.StatusCode != http.StatusOK || json.Unmarshal(env.Data, &confirmed) != nil || len(confirmed.RecoveryCodes) == 0 {
+		t.Fatalf("totp confirm failed: status=%d err=%#v", resp.StatusCode, env.Error)
+	}
+
+	fresh := newSessionClient(t)
+	resp, env = doJSON(t, fresh, http.MethodPost, baseURL+"/api/v1/auth/local/login", map[string]string{
+		"email":    "history@example.com",
+		"password": "Valid#Pass1234",
+	}, nil)
+	var challenge struct {
+		MFAToken string `json:"mfa_token"`
+	}
+	if resp.StatusCode != http.StatusOK || json.Unmarshal(env.Data, &challenge) != nil || challenge.MFAToken == "" {
+		t.Fatalf("expected mfa challenge, got status=%d err=%#v", resp.StatusCode, env.Error)
+	}
+	resp, _ = doJSON(t, fresh, http.MethodPost, baseURL+"/api/v1/auth/mfa/verify", map[string]string{"mfa_token": challenge.MFAToken, "code": "000000"}, nil)
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected wrong mfa code to fail, got %d", resp.StatusCode)
+	}
+	resp, env = doJSON(t, fresh, http.MethodPost, baseURL+"/api/v1/auth/mfa/verify", map[string]string{"mfa_token": challenge.MFAToken, "code": confirmed.RecoveryCodes[0]}, nil)
+	if resp.StatusCode != http.StatusOK || !env.Success {
+		t.Fatalf("mfa verify failed: status=%d err=%#v", resp.StatusCode, env.Error)
+	}
+	freshCSRF := map[string]string{"X-CSRF-Token": cookieValue(t, fresh, baseURL, "csrf_token")}
+	if resp, env := doJSON(t, fresh, http.MethodPost, baseURL+"/api/v1/auth/reauth", map[string]string{"password": "Valid#Pass1234"}, freshCSRF); resp.StatusCode != http.StatusOK {
+		t.Fatalf("reauth failed: status=%d err=%#v", resp.StatusCode, env.Error)
+	}
+
+	resp, env = doJSON(t, fresh, http.MethodGet, baseURL+"/api/v1/me/export", nil, nil)
+	if resp.StatusCode != http.StatusOK || !env.Success {
+		t.Fatalf("export failed: status=%d err=%#v", resp.StatusCode, env.Error)
+	}
+	var export struct {
+		AuthEvents []struct {
+			Event   string `json:"event"`
+			Method  string `json:"method"`
+			Outcome string `json:"outcome"`
+			Reason  string `json:"reason"`
+		} `json:"auth_events"`
+	}
+	if err := json.Unmarshal(env.Data, &export); err != nil {
+		t.Fatalf("decode export: %v", err)
+	}
+	var got []string
+	for _, event := range export.AuthEvents {
+		got = append(got, event.Event+"/"+event.Method+"/"+event.Outcome+"/"+event.Reason)
+	}
+	want := []string{
+		"login/password/success/",
+		"login/password/failure/invalid_credentials",
+		"login/password/mfa_required/",
+		"mfa_challenge//failure/invalid_code",
+		"mfa_challenge/recovery_code/success/",
+		"reauth/password/success/",
+	}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("expected auth events %v, got %v", want, got)
+	}
+}
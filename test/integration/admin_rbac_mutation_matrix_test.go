@@ -33,8 +33,8 @@ func (s failingSetRolesUserService) List() ([]domain.User, error) {
 	return s.delegate.List()
 }
 
-func (s failingSetRolesUserService) SetRoles(userID uint, roleIDs []uint) error {
-	return errors.New("forced SetRoles failure")
+func (s failingSetRolesUserService) SetRoleBindings(userID uint, bindings []domain.UserRole) error {
+	return errors.New("forced SetRoleBindings failure")
 }
 
 func TestAdminRoleUpdateMutationMatrix(t *testing.T) {
@@ -120,4 +120,142 @@ func TestAdminRBACSyncIdempotent(t *testing.T) {
 	_ = first
 }
 
+func TestAdminRBACWildcardPermissionGrant(t *testing.T) {
+	baseURL, adminClient, closeFn := newAuthTestServerWithOptions(t, authTestServerOptions{
+		cfgOverride: func(cfg *config.Config) {
+			cfg.BootstrapAdminEmail = "admin-wildcard@example.com"
+		},
+	})
+	defer closeFn()
+
+	registerAndLogin(t, adminClient, baseURL, "admin-wildcard@example.com", "Valid#Pass1234")
+	userClient := newSessionClient(t)
+	registerAndLogin(t, userClient, baseURL, "auditor-wildcard@example.com", "Valid#Pass1234")
+	auditorID := meID(t, userClient, baseURL)
+
+	resp, env := doJSON(t, adminClient, http.MethodPost, baseURL+"/api/v1/admin/permissions", map[string]string{
+		"resource": "*",
+		"action":   "read",
+	}, nil)
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected *:read to be accepted, got status=%d err=%#v", resp.StatusCode, env.Error)
+	}
+	resp, env = doJSON(t, adminClient, http.MethodPost, baseURL+"/api/v1/admin/roles", map[string]any{
+		"name":        "auditor",
+		"permissions": []string{"*:read"},
+	}, nil)
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("create role failed: status=%d err=%#v", resp.StatusCode, env.Error)
+	}
+	var role roleView
+	if err := json.Unmarshal(env.Data, &role); err != nil || role.ID == 0 {
+		t.Fatalf("decode role: %v %s", err, env.Data)
+	}
+	resp, env = doJSON(t, adminClient, http.MethodPatch, baseURL+"/api/v1/admin/users/"+itoa(auditorID)+"/roles", map[string]any{
+		"role_ids": []uint{role.ID},
+	}, nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("assign role failed: status=%d err=%#v", resp.StatusCode, env.Error)
+	}
+
+	for _, path := range []string{"/api/v1/admin/users", "/api/v1/admin/roles", "/api/v1/admin/permissions"} {
+		if resp, env := doJSON(t, userClient, http.MethodGet, baseURL+path, nil, nil); resp.StatusCode != http.StatusOK {
+			t.Fatalf("expected *:read to grant GET %s, got status=%d err=%#v", path, resp.StatusCode, env.Error)
+		}
+	}
+	resp, env = doJSON(t, userClient, http.MethodPost, baseURL+"/api/v1/admin/permissions", map[string]string{
+		"resource": "reports",
+		"action":   "read",
+	}, nil)
+	if resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("expected *:read not to grant writes, got status=%d err=%#v", resp.StatusCode, env.Error)
+	}
+}
+
+func TestAdminRBACRoleInheritance(t *testing.T) {
+	baseURL, adminClient, closeFn := newAuthTestServerWithOptions(t, authTestServerOptions{
+		cfgOverride: func(cfg *config.Config) {
+			cfg.BootstrapAdminEmail = "admin-inherit@example.com"
+		},
+	})
+	defer closeFn()
+
+	registerAndLogin(t, adminClient, baseURL, "admin-inherit@example.com", "Valid#Pass1234")
+	userClient := newSessionClient(t)
+	registerAndLogin(t, userClient, baseURL, "lead-inherit@example.com", "Valid#Pass1234")
+	leadUserID := meID(t, userClient, baseURL)
+
+	createRole := func(body map[string]any) roleView {
+		t.Helper()
+		resp, env := doJSON(t, adminClient, http.MethodPost, baseURL+"/api/v1/admin/roles", body, nil)
+		if resp.StatusCode != http.StatusCreated {
+			t.Fatalf("create role failed: status=%d err=%#v", resp.StatusCode, env.Error)
+		}
+		var role roleView
+		if err := json.Unmarshal(env.Data, &role); err != nil {
+			t.Fatalf("decode role: %v", err)
+		}
+		return role
+	}
+	support := createRole(map[string]any{"name": "support", "permissions": []string{"users:read"}})
+	lead := createRole(map[string]any{"name": "support-lead", "permissions": []string{"roles:read"}, "parent_ids": []uint{support.ID}})
+
+	resp, env := doJSON(t, adminClient, http.MethodPatch, baseURL+"/api/v1/admin/users/"+itoa(leadUserID)+"/roles", map[string]any{
+		"role_ids": []uint{lead.ID},
+	}, nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("assign role failed: status=%d err=%#v", resp.StatusCode, env.Error)
+	}
+	if resp, env := doJSON(t, userClient, http.MethodGet, baseURL+"/api/v1/admin/users", nil, nil); resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected users:read inherited from support, got status=%d err=%#v", resp.StatusCode, env.Error)
+	}
+
+	resp, env = doJSON(t, adminClient, http.MethodGet, baseURL+"/api/v1/admin/roles?name=support-lead", nil, nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("list roles failed: status=%d", resp.StatusCode)
+	}
+	var page struct {
+		Items []struct {
+			Name                 string     `json:"name"`
+			Parents              []roleView `json:"parents"`
+			InheritedPermissions []struct {
+				Resource string `json:"resource"`
+				Action   string `json:"action"`
+			} `json:"inherited_permissions"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal(env.Data, &page); err != nil || len(page.Items) != 1 {
+		t.Fatalf("decode roles: %v %s", err, env.Data)
+	}
+	listed := page.Items[0]
+	if len(listed.Parents) != 1 || listed.Parents[0].Name != "support" ||
+		len(listed.InheritedPermissions) != 1 || listed.InheritedPermissions[0].Resource != "users" {
+		t.Fatalf("expected support-lead to show its parent and inherited users:read, got %+v", listed)
+	}
+
+	resp, env = doJSON(t, adminClient, http.MethodPatch, baseURL+"/api/v1/admin/roles/"+itoa(support.ID), map[string]any{
+		"parent_ids": []uint{lead.ID},
+	}, nil)
+	if resp.StatusCode != http.StatusConflict {
+		t.Fatalf("expected a cycle to be rejected, got status=%d err=%#v", resp.StatusCode, env.Error)
+	}
+	resp, env = doJSON(t, adminClient, http.MethodDelete, baseURL+"/api/v1/admin/roles/"+itoa(support.ID), nil, nil)
+	if resp.StatusCode != http.StatusConflict {
+		t.Fatalf("expected deleting an inherited role to be rejected, got status=%d err=%#v", resp.StatusCode, env.Error)
+	}
+
+	resp, env = doJSON(t, adminClient, http.MethodPatch, baseURL+"/api/v1/admin/roles/"+itoa(lead.ID), map[string]any{
+		"parent_ids": []uint{},
+	}, nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("detach parent failed: status=%d err=%#v", resp.StatusCode, env.Error)
+	}
+	if resp, _ := doJSON(t, userClient, http.MethodGet, baseURL+"/api/v1/admin/users", nil, nil); resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("expected users:read to be gone once the parent is detached, got %d", resp.StatusCode)
+	}
+	if resp, env := doJSON(t, adminClient, http.MethodDelete, baseURL+"/api/v1/admin/roles/"+itoa(support.ID), nil, nil); resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected the detached parent to be deletable, got status=%d err=%#v", resp.StatusCode, env.Error)
+	}
+}
+
 func itoa(v uint) string { return fmt.Sprintf("%d", v) }
@@ -3,6 +3,7 @@ package integration
 import (
 	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"net/http"
@@ -27,14 +28,17 @@ type oauthProviderFuncStub struct {
 	userInfoFn    func(ctx context.Context, token *oauth2.Token) (*service.OAuthUserInfo, error)
 }
 
-func (s oauthProviderFuncStub) AuthCodeURL(state string) string {
-	if s.authCodeURLFn != nil {
-		return s.authCodeURLFn(state)
+// AuthCodeURL applies the PKCE and nonce options on top of the stubbed URL so
+// tests can assert on the parameters a real provider would receive.
+func (s oauthProviderFuncStub) AuthCodeURL(state string, opts ...oauth2.AuthCodeOption) string {
+	if s.authCodeURLFn == nil {
+		return ""
 	}
-	return ""
+	cfg := oauth2.Config{Endpoint: oauth2.Endpoint{AuthURL: s.authCodeURLFn(state)}}
+	return cfg.AuthCodeURL(state, opts...)
 }
 
-func (s oauthProviderFuncStub) Exchange(ctx context.Context, code string) (*oauth2.Token, error) {
+func (s oauthProviderFuncStub) Exchange(ctx context.Context, code string, _ ...oauth2.AuthCodeOption) (*oauth2.Token, error) {
 	if s.exchangeFn != nil {
 		return s.exchangeFn(ctx, code)
 	}
@@ -48,6 +52,15 @@ func (s oauthProviderFuncStub) FetchUserInfo(ctx context.Context, token *oauth2.
 	return nil, errors.New("userinfo not configured")
 }
 
+func signedGoogleOAuthFlowState(state string) string {
+	return security.SignOAuthFlowState(security.OAuthFlowState{
+		Provider:     "google",
+		State:        state,
+		CodeVerifier: "integration-code-verifier-integration-code-verifier",
+		Nonce:        "integration-nonce",
+	}, oauthStateSigningKey)
+}
+
 func TestGoogleLoginRedirectAndDisabled(t *testing.T) {
 	events := captureAuditEvents(t, func() {
 		baseURL, client, closeFn := newAuthTestServerWithOptions(t, authTestServerOptions{
@@ -161,6 +174,12 @@ func TestGoogleCallbackSuccessSetsCookiesAndClearsState(t *testing.T) {
 		if strings.TrimSpace(state) == "" {
 			t.Fatalf("expected non-empty oauth state in redirect URL: %q", location)
 		}
+		if redirectURL.Query().Get("code_challenge") == "" || redirectURL.Query().Get("code_challenge_method") != "S256" {
+			t.Fatalf("expected S256 PKCE challenge in redirect URL: %q", location)
+		}
+		if redirectURL.Query().Get("nonce") == "" {
+			t.Fatalf("expected nonce in redirect URL: %q", location)
+		}
 
 		resp, env := doJSON(t, client, http.MethodGet, baseURL+"/api/v1/auth/google/callback?state="+url.QueryEscape(state)+"&code=google-code-1", nil, nil)
 		if resp.StatusCode != http.StatusOK || !env.Success {
@@ -186,7 +205,7 @@ func TestGoogleCallbackDisabledAndProviderErrors(t *testing.T) {
 		defer closeFn()
 
 		state := "disabled-state"
-		signed := security.SignState(state, oauthStateSigningKey)
+		signed := signedGoogleOAuthFlowState(state)
 		resp, env := doRaw(t, client, http.MethodGet, baseURL+"/api/v1/auth/google/callback?state="+state+"&code=abc", nil, nil, []*http.Cookie{
 			{Name: "oauth_state", Value: signed, Path: "/api/v1/auth/google"},
 		})
@@ -202,9 +221,11 @@ func TestGoogleCallbackDisabledAndProviderErrors(t *testing.T) {
 		cases := []struct {
 			name     string
 			provider service.OAuthProvider
+			reason   string
 		}{
 			{
-				name: "exchange error",
+				name:   "exchange error",
+				reason: "oauth_exchange_error",
 				provider: oauthProviderFuncStub{
 					exchangeFn: func(context.Context, string) (*oauth2.Token, error) {
 						return nil, errors.New("oauth2: cannot fetch token")
@@ -212,7 +233,8 @@ func TestGoogleCallbackDisabledAndProviderErrors(t *testing.T) {
 				},
 			},
 			{
-				name: "userinfo error",
+				name:   "userinfo error",
+				reason: "oauth_exchange_error",
 				provider: oauthProviderFuncStub{
 					exchangeFn: func(context.Context, string) (*oauth2.Token, error) {
 						return &oauth2.Token{AccessToken: "oauth-token"}, nil
@@ -222,6 +244,17 @@ func TestGoogleCallbackDisabledAndProviderErrors(t *testing.T) {
 					},
 				},
 			},
+			{
+				name:   "id_token nonce mismatch",
+				reason: "nonce_mismatch",
+				provider: oauthProviderFuncStub{
+					exchangeFn: func(context.Context, string) (*oauth2.Token, error) {
+						payload := base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"google-user-1","nonce":"replayed-nonce"}`))
+						token := &oauth2.Token{AccessToken: "oauth-token"}
+						return token.WithExtra(map[string]any{"id_token": "e30." + payload + ".sig"}), nil
+					},
+				},
+			},
 		}
 
 		for _, tc := range cases {
@@ -233,7 +266,7 @@ func TestGoogleCallbackDisabledAndProviderErrors(t *testing.T) {
 				defer closeFn()
 
 				state := "error-state"
-				signed := security.SignState(state, oauthStateSigningKey)
+				signed := signedGoogleOAuthFlowState(state)
 				events := captureAuditEvents(t, func() {
 					resp, env := doRaw(t, client, http.MethodGet, baseURL+"/api/v1/auth/google/callback?state="+state+"&code=abc", nil, nil, []*http.Cookie{
 						{Name: "oauth_state", Value: signed, Path: "/api/v1/auth/google"},
@@ -245,7 +278,7 @@ func TestGoogleCallbackDisabledAndProviderErrors(t *testing.T) {
 						t.Fatalf("expected OAUTH_FAILED envelope, got %#v", env.Error)
 					}
 				})
-				requireAuditEvent(t, events, "auth.google.callback", "failure", "oauth_exchange_error")
+				requireAuditEvent(t, events, "auth.google.callback", "failure", tc.reason)
 			})
 		}
 	})
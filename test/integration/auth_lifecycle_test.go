@@ -33,8 +33,8 @@ import (
 
 type oauthProviderStub struct{}
 
-func (oauthProviderStub) AuthCodeURL(string) string { return "" }
-func (oauthProviderStub) Exchange(context.Context, string) (*oauth2.Token, error) {
+func (oauthProviderStub) AuthCodeURL(string, ...oauth2.AuthCodeOption) string { return "" }
+func (oauthProviderStub) Exchange(context.Context, string, ...oauth2.AuthCodeOption) (*oauth2.Token, error) {
 	return nil, errors.New("not implemented")
 }
 func (oauthProviderStub) FetchUserInfo(context.Context, *oauth2.Token) (*service.OAuthUserInfo, error) {
@@ -45,15 +45,19 @@ type apiEnvelope struct {
 	Success bool            `json:"success"`
 	Data    json.RawMessage `json:"data"`
 	Error   *struct {
-		Code    string `json:"code"`
-		Message string `json:"message"`
+		Code    string          `json:"code"`
+		Message string          `json:"message"`
+		Details json.RawMessage `json:"details"`
 	} `json:"error"`
 }
 
 type verificationCaptureNotifier struct {
-	mu    sync.Mutex
-	token string
-	reset string
+	mu     sync.Mutex
+	token  string
+	reset  string
+	magic  string
+	change string
+	notice string
 }
 
 func (n *verificationCaptureNotifier) SendEmailVerification(_ context.Context, notification service.VerificationNotification) error {
@@ -82,16 +86,52 @@ func (n *verificationCaptureNotifier) LastResetToken() string {
 	return n.reset
 }
 
+func (n *verificationCaptureNotifier) SendMagicLink(_ context.Context, notification service.MagicLinkNotification) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.magic = notification.Token
+	return nil
+}
+
+func (n *verificationCaptureNotifier) LastMagicToken() string {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.magic
+}
+
+func (n *verificationCaptureNotifier) SendEmailChangeVerification(_ context.Context, notification service.EmailChangeNotification) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.change = notification.Token
+	return nil
+}
+
+func (n *verificationCaptureNotifier) SendEmailChangeNotice(_ context.Context, notice service.EmailChangeNotice) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.notice = notice.OldEmail
+	return nil
+}
+
+func (n *verificationCaptureNotifier) LastEmailChange() (token, noticedEmail string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.change, n.notice
+}
+
 type authTestServerOptions struct {
-	cfgOverride    func(cfg *config.Config)
-	verifyNotifier service.EmailVerificationNotifier
-	resetNotifier  service.PasswordResetNotifier
-	adminListCache service.AdminListCacheStore
-	negativeCache  service.NegativeLookupCacheStore
-	rbacPermCache  service.RBACPermissionCacheStore
-	routePolicies  router.RouteRateLimitPolicies
-	oauthProvider  service.OAuthProvider
-	adminUserSvc   service.UserServiceInterface
+	cfgOverride         func(cfg *config.Config)
+	verifyNotifier      service.EmailVerificationNotifier
+	resetNotifier       service.PasswordResetNotifier
+	magicNotifier       service.MagicLinkNotifier
+	emailChangeNotifier service.EmailChangeNotifier
+	adminListCache      service.AdminListCacheStore
+	negativeCache       service.NegativeLookupCacheStore
+	rbacPermCache       service.RBACPermissionCacheStore
+	routePolicies       router.RouteRateLimitPolicies
+	oauthProvider       service.OAuthProvider
+	oauthProviders      map[string]service.OAuthProvider
+	adminUserSvc        service.UserServiceInterface
 }
 
 func TestAuthLifecycleLoginRefreshLogoutRevoked(t *testing.T) {
@@ -272,6 +312,7 @@ func newAuthTestServerWithOptions(t *testing.T, opts authTestServerOptions) (str
 		AuthGoogleEnabled:                 false,
 		AuthLocalEnabled:                  true,
 		AuthLocalRequireEmailVerification: false,
+		AuthOAuthAutoLinkByEmail:          true,
 		IdempotencyEnabled:                false,
 		IdempotencyRedisEnabled:           false,
 		IdempotencyTTL:                    24 * time.Hour,
@@ -279,6 +320,7 @@ func newAuthTestServerWithOptions(t *testing.T, opts authTestServerOptions) (str
 		AuthEmailVerifyBaseURL:            "http://localhost:3000/verify-email",
 		AuthPasswordResetTokenTTL:         15 * time.Minute,
 		AuthPasswordResetBaseURL:          "http://localhost:3000/reset-password",
+		NotificationOutboxEncryptionKey:   "outbox-encryption-key-0123456789abcdef",
 		AuthPasswordForgotRateLimitPerMin: 5,
 		AuthAbuseProtectionEnabled:        true,
 		AuthAbuseFreeAttempts:             3,
@@ -291,6 +333,9 @@ func newAuthTestServerWithOptions(t *testing.T, opts authTestServerOptions) (str
 		BootstrapAdminEmail:               "",
 		JWTAccessTTL:                      15 * time.Minute,
 		JWTRefreshTTL:                     24 * time.Hour,
+		AuthMFAEncryptionKey:              "mfa-encryption-key-0123456789abcdef",
+		AuthMFAIssuer:                     "everything-backend-starter-kit",
+		AuthMFAChallengeTTL:               5 * time.Minute,
 	}
 	if opts.cfgOverride != nil {
 		opts.cfgOverride(cfg)
@@ -311,16 +356,34 @@ func newAuthTestServerWithOptions(t *testing.T, opts authTestServerOptions) (str
 		"abcdefghijklmnopqrstuvwxyz123456",
 		"abcdefghijklmnopqrstuvwxyz654321",
 	)
-	tokenSvc := service.NewTokenService(jwtMgr, sessionRepo, "pepper-1234567890", 15*time.Minute, 24*time.Hour)
-	sessionSvc := service.NewSessionService(sessionRepo, "pepper-1234567890")
+	jwtKeys, err := service.NewJWTKeyService(cfg, repository.NewJWTSigningKeyRepository(db))
+	if err != nil {
+		t.Fatalf("jwt key service: %v", err)
+	}
+	if jwtKeys.Enabled() {
+		if err := jwtKeys.Bootstrap(context.Background()); err != nil {
+			t.Fatalf("bootstrap jwt keyring: %v", err)
+		}
+		jwtMgr = security.NewJWTManagerWithKeyring("iss", "aud", "abcdefghijklmnopqrstuvwxyz654321", jwtKeys.Keyring())
+	}
+	accessRevocations := service.NewInMemoryAccessTokenRevocationStore()
+	accessRevoker := service.NewAccessTokenRevoker(accessRevocations, sessionRepo, 15*time.Minute)
+	tokenSvc := service.NewTokenService(jwtMgr, sessionRepo, "pepper-1234567890", 15*time.Minute, 24*time.Hour, accessRevoker)
+	sessionSvc := service.NewSessionService(sessionRepo, "pepper-1234567890", accessRevoker)
 	oauthProvider := opts.oauthProvider
 	if oauthProvider == nil {
 		oauthProvider = oauthProviderStub{}
 	}
-	oauthSvc := service.NewOAuthService(oauthProvider, userRepo, oauthRepo, roleRepo)
+	oauthProviders := map[string]service.OAuthProvider{"google": oauthProvider}
+	for name, provider := range opts.oauthProviders {
+		oauthProviders[name] = provider
+	}
+	oauthSvc := service.NewOAuthService(cfg, service.NewOAuthProviderRegistry(oauthProviders), userRepo, oauthRepo, roleRepo)
 	verifyNotifier := opts.verifyNotifier
 	resetNotifier := opts.resetNotifier
-	if verifyNotifier == nil || resetNotifier == nil {
+	magicNotifier := opts.magicNotifier
+	emailChangeNotifier := opts.emailChangeNotifier
+	if verifyNotifier == nil || resetNotifier == nil || magicNotifier == nil || emailChangeNotifier == nil {
 		dev := service.NewDevEmailVerificationNotifier(slog.New(slog.NewTextHandler(os.Stdout, nil)))
 		if verifyNotifier == nil {
 			verifyNotifier = dev
@@ -328,9 +391,28 @@ func newAuthTestServerWithOptions(t *testing.T, opts authTestServerOptions) (str
 		if resetNotifier == nil {
 			resetNotifier = dev
 		}
+		if magicNotifier == nil {
+			magicNotifier = dev
+		}
+		if emailChangeNotifier == nil {
+			emailChangeNotifier = dev
+		}
 	}
 	verificationTokenRepo := repository.NewVerificationTokenRepository(db)
-	authSvc := service.NewAuthService(cfg, oauthSvc, tokenSvc, userSvc, roleRepo, localCredRepo, verificationTokenRepo, verifyNotifier, resetNotifier)
+	loginEventRepo := repository.NewLoginEventRepository(db)
+	mfaSvc, err := service.NewMFAService(cfg, repository.NewMFARepository(db), verificationTokenRepo)
+	if err != nil {
+		t.Fatalf("mfa service: %v", err)
+	}
+	webauthnSvc, err := service.NewWebAuthnService(cfg, repository.NewWebAuthnCredentialRepository(db), userRepo, service.NewDBWebAuthnChallengeStore(db))
+	if err != nil {
+		t.Fatalf("webauthn service: %v", err)
+	}
+	passwordPolicy, err := service.NewPasswordPolicy(cfg)
+	if err != nil {
+		t.Fatalf("password policy: %v", err)
+	}
+	authSvc := service.NewAuthService(cfg, oauthSvc, tokenSvc, userSvc, roleRepo, localCredRepo, verificationTokenRepo, verifyNotifier, resetNotifier, magicNotifier, mfaSvc, webauthnSvc, passwordPolicy, loginEventRepo)
 	cookieMgr := security.NewCookieManager("", false, "lax")
 	if cfg.AuthAbuseBaseDelay <= 0 {
 		cfg.AuthAbuseBaseDelay = 2 * time.Second
@@ -359,7 +441,8 @@ func newAuthTestServerWithOptions(t *testing.T, opts authTestServerOptions) (str
 	}, jwtMgr)
 
 	authHandler := handler.NewAuthHandler(authSvc, abuseGuard, cookieMgr, bypassEvaluator, "0123456789abcdef0123456789abcdef", cfg.JWTRefreshTTL)
-	userHandler := handler.NewUserHandler(userSvc, sessionSvc)
+	accountSvc := service.NewAccountDataService(cfg, userRepo, localCredRepo, oauthRepo, sessionRepo, loginEventRepo, tokenSvc)
+	userHandler := handler.NewUserHandler(userSvc, sessionSvc, service.NewEmailChangeService(cfg, userRepo, verificationTokenRepo, emailChangeNotifier), accountSvc)
 	adminUserSvc := opts.adminUserSvc
 	if adminUserSvc == nil {
 		adminUserSvc = userSvc
@@ -368,16 +451,22 @@ func newAuthTestServerWithOptions(t *testing.T, opts authTestServerOptions) (str
 	if permissionCache == nil {
 		permissionCache = service.NewInMemoryRBACPermissionCacheStore()
 	}
-	permissionResolver := service.NewCachedPermissionResolver(permissionCache, userSvc, 5*time.Minute)
+	var orgSvc *service.OrganizationService
+	var orgPerms service.OrganizationPermissionSource
+	if cfg.OrganizationsEnabled {
+		orgSvc = service.NewOrganizationService(repository.NewOrganizationRepository(db), userRepo, roleRepo, rbac)
+		orgPerms = orgSvc
+	}
+	permissionResolver := service.NewCachedPermissionResolver(permissionCache, userSvc, orgPerms, 5*time.Minute)
 	negativeCache := opts.negativeCache
 	if negativeCache == nil {
 		negativeCache = service.NewNoopNegativeLookupCacheStore()
 	}
 	var adminHandler *handler.AdminHandler
 	if opts.adminListCache != nil {
-		adminHandler = handler.NewAdminHandler(adminUserSvc, userRepo, roleRepo, permRepo, rbac, permissionResolver, opts.adminListCache, negativeCache, db, cfg)
+		adminHandler = handler.NewAdminHandler(adminUserSvc, service.NewUserStatusService(userRepo, tokenSvc), accountSvc, userRepo, roleRepo, permRepo, rbac, permissionResolver, opts.adminListCache, negativeCache, db, cfg)
 	} else {
-		adminHandler = handler.NewAdminHandler(adminUserSvc, userRepo, roleRepo, permRepo, rbac, permissionResolver, service.NewNoopAdminListCacheStore(), negativeCache, db, cfg)
+		adminHandler = handler.NewAdminHandler(adminUserSvc, service.NewUserStatusService(userRepo, tokenSvc), accountSvc, userRepo, roleRepo, permRepo, rbac, permissionResolver, service.NewNoopAdminListCacheStore(), negativeCache, db, cfg)
 	}
 	var idempotencyFactory router.IdempotencyMiddlewareFactory
 	if cfg.IdempotencyEnabled {
@@ -388,13 +477,70 @@ func newAuthTestServerWithOptions(t *testing.T, opts authTestServerOptions) (str
 		}
 	}
 
+	var webauthnHandler *handler.WebAuthnHandler
+	if cfg.AuthWebAuthnEnabled {
+		webauthnHandler = handler.NewWebAuthnHandler(webauthnSvc, authSvc, cookieMgr, cfg.JWTRefreshTTL)
+	}
+	var adminMFAChecker service.MFAStatusChecker
+	if cfg.AuthMFARequireForAdmin {
+		adminMFAChecker = mfaSvc
+	}
+	var apiKeyHandler *handler.APIKeyHandler
+	var apiKeyAuthenticator service.APIKeyAuthenticator
+	if cfg.AuthAPIKeysEnabled {
+		apiKeySvc := service.NewAPIKeyService(cfg, repository.NewAPIKeyRepository(db), userSvc)
+		apiKeyHandler = handler.NewAPIKeyHandler(apiKeySvc)
+		apiKeyAuthenticator = apiKeySvc
+	}
+	var serviceAccountHandler *handler.ServiceAccountHandler
+	if cfg.AuthServiceAccountsEnabled {
+		serviceAccountSvc := service.NewServiceAccountService(cfg, repository.NewServiceAccountRepository(db), rbac, jwtMgr)
+		serviceAccountHandler = handler.NewServiceAccountHandler(serviceAccountSvc)
+	}
+	var impersonationHandler *handler.ImpersonationHandler
+	if cfg.AuthImpersonationEnabled {
+		impersonationSvc := service.NewImpersonationService(cfg, userSvc, tokenSvc, rbac)
+		impersonationHandler = handler.NewImpersonationHandler(impersonationSvc, authSvc, permissionResolver, cookieMgr, cfg.JWTRefreshTTL)
+	}
+	var organizationHandler *handler.OrganizationHandler
+	var organizationMembership service.OrganizationMembershipChecker
+	if orgSvc != nil {
+		organizationHandler = handler.NewOrganizationHandler(orgSvc, permissionResolver)
+		organizationMembership = orgSvc
+	}
+	var accessPolicyHandler *handler.AccessPolicyHandler
+	var accessPolicies service.AccessPolicyEvaluator
+	if cfg.AccessPoliciesEnabled {
+		var orgRepo repository.OrganizationRepository
+		if cfg.OrganizationsEnabled {
+			orgRepo = repository.NewOrganizationRepository(db)
+		}
+		policySvc := service.NewAccessPolicyService(repository.NewAccessPolicyRepository(db), cfg.AccessPolicyCacheTTL)
+		policySvc.RegisterFetcher(service.AccessPolicyResourceUser, service.NewUserAttributeFetcher(userRepo, orgRepo))
+		accessPolicyHandler = handler.NewAccessPolicyHandler(policySvc)
+		accessPolicies = policySvc
+	}
 	r := router.NewRouter(router.Dependencies{
 		AuthHandler:                authHandler,
 		UserHandler:                userHandler,
 		AdminHandler:               adminHandler,
+		WebAuthnHandler:            webauthnHandler,
+		APIKeyHandler:              apiKeyHandler,
+		ServiceAccountHandler:      serviceAccountHandler,
+		ImpersonationHandler:       impersonationHandler,
+		OrganizationHandler:        organizationHandler,
+		OrganizationMembership:     organizationMembership,
+		AccessPolicyHandler:        accessPolicyHandler,
+		AccessPolicies:             accessPolicies,
+		TrustedProxyCIDRs:          cfg.AccessPolicyTrustedProxyCIDRs,
+		JWKSHandler:                handler.NewJWKSHandler(jwtMgr.Keyring()),
 		JWTManager:                 jwtMgr,
+		AccessTokenRevocations:     accessRevocations,
+		APIKeyAuthenticator:        apiKeyAuthenticator,
 		RBACService:                rbac,
 		PermissionResolver:         permissionResolver,
+		AdminMFAChecker:            adminMFAChecker,
+		ReauthMaxAge:               cfg.AuthReauthMaxAge,
 		CORSOrigins:                []string{"http://localhost"},
 		AuthRateLimitRPM:           1000,
 		PasswordForgotRateLimitRPM: 1000,
@@ -15,7 +15,7 @@ import (
 func TestProtectedRouteRequiresToken(t *testing.T) {
 	mgr := security.NewJWTManager("iss", "aud", "abcdefghijklmnopqrstuvwxyz123456", "abcdefghijklmnopqrstuvwxyz654321")
 	r := chi.NewRouter()
-	r.With(middleware.AuthMiddleware(mgr)).Get("/me", func(w http.ResponseWriter, r *http.Request) {
+	r.With(middleware.AuthMiddleware(mgr, nil, nil)).Get("/me", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 	})
 
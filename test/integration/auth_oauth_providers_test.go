@@ -0,0 +1,135 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"golang.org/x/oauth2"
+
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/service"
+)
+
+func newGenericOAuthProviderStub(name, providerUserID, email string) oauthProviderFuncStub {
+	return oauthProviderFuncStub{
+		authCodeURLFn: func(state string) string {
+			return "https://" + name + ".example/authorize?state=" + state
+		},
+		exchangeFn: func(context.Context, string) (*oauth2.Token, error) {
+			return &oauth2.Token{AccessToken: name + "-token"}, nil
+		},
+		userInfoFn: func(context.Context, *oauth2.Token) (*service.OAuthUserInfo, error) {
+			return &service.OAuthUserInfo{
+				ProviderUserID: providerUserID,
+				Email:          email,
+				Name:           strings.ToUpper(name) + " User",
+				EmailVerified:  true,
+			}, nil
+		},
+	}
+}
+
+func TestGenericOAuthProviderLoginAndCallback(t *testing.T) {
+	baseURL, client, closeFn := newAuthTestServerWithOptions(t, authTestServerOptions{
+		oauthProviders: map[string]service.OAuthProvider{
+			"github": newGenericOAuthProviderStub("github", "gh-1001", "multi-sso@example.com"),
+			"okta":   newGenericOAuthProviderStub("okta", "okta-2002", "multi-sso@example.com"),
+		},
+	})
+	defer closeFn()
+
+	loginViaProvider := func(provider string) uint {
+		t.Helper()
+		loginResp, _ := doRawTextNoRedirect(t, client, http.MethodGet, baseURL+"/api/v1/auth/"+provider+"/login", nil, nil, nil)
+		if loginResp.StatusCode != http.StatusFound {
+			t.Fatalf("expected %s login redirect 302, got %d", provider, loginResp.StatusCode)
+		}
+		assertCookieProps(t, loginResp, "oauth_state", "/api/v1/auth/"+provider, true)
+		redirectURL, err := url.Parse(loginResp.Header.Get("Location"))
+		if err != nil {
+			t.Fatalf("parse redirect URL: %v", err)
+		}
+		if !strings.HasPrefix(redirectURL.String(), "https://"+provider+".example/authorize") {
+			t.Fatalf("unexpected %s redirect: %s", provider, redirectURL)
+		}
+		state := redirectURL.Query().Get("state")
+
+		resp, env := doJSON(t, client, http.MethodGet, baseURL+"/api/v1/auth/"+provider+"/callback?state="+url.QueryEscape(state)+"&code=code-1", nil, nil)
+		if resp.StatusCode != http.StatusOK || !env.Success {
+			t.Fatalf("expected %s callback success, got status=%d error=%#v", provider, resp.StatusCode, env.Error)
+		}
+		assertClearingCookie(t, resp, "oauth_state")
+		var data struct {
+			User struct {
+				ID uint `json:"id"`
+			} `json:"user"`
+		}
+		if err := json.Unmarshal(env.Data, &data); err != nil {
+			t.Fatalf("decode %s callback payload: %v", provider, err)
+		}
+		return data.User.ID
+	}
+
+	var githubUserID, oktaUserID uint
+	events := captureAuditEvents(t, func() {
+		githubUserID = loginViaProvider("github")
+		oktaUserID = loginViaProvider("okta")
+	})
+	requireAuditEvent(t, events, "auth.oauth.login", "success", "redirect_issued")
+	requireAuditEvent(t, events, "auth.login", "success", "oauth_github")
+	requireAuditEvent(t, events, "auth.login", "success", "oauth_okta")
+
+	if githubUserID == 0 || githubUserID != oktaUserID {
+		t.Fatalf("expected both providers to link to the same user by verified email, got github=%v okta=%v", githubUserID, oktaUserID)
+	}
+}
+
+func TestGenericOAuthProviderRejectsUnknownAndCrossProviderState(t *testing.T) {
+	baseURL, client, closeFn := newAuthTestServerWithOptions(t, authTestServerOptions{
+		oauthProviders: map[string]service.OAuthProvider{
+			"github": newGenericOAuthProviderStub("github", "gh-1", "cross@example.com"),
+			"okta":   newGenericOAuthProviderStub("okta", "okta-1", "cross@example.com"),
+		},
+	})
+	defer closeFn()
+
+	events := captureAuditEvents(t, func() {
+		resp, env := doJSON(t, client, http.MethodGet, baseURL+"/api/v1/auth/gitlab/login", nil, nil)
+		if resp.StatusCode != http.StatusNotFound || env.Error == nil || env.Error.Code != "NOT_ENABLED" {
+			t.Fatalf("expected NOT_ENABLED for unknown provider, got status=%d error=%#v", resp.StatusCode, env.Error)
+		}
+	})
+	requireAuditEvent(t, events, "auth.oauth.login", "rejected", "provider_disabled")
+
+	events = captureAuditEvents(t, func() {
+		loginResp, _ := doRawTextNoRedirect(t, client, http.MethodGet, baseURL+"/api/v1/auth/github/login", nil, nil, nil)
+		if loginResp.StatusCode != http.StatusFound {
+			t.Fatalf("expected github login redirect 302, got %d", loginResp.StatusCode)
+		}
+		redirectURL, err := url.Parse(loginResp.Header.Get("Location"))
+		if err != nil {
+			t.Fatalf("parse redirect URL: %v", err)
+		}
+		var githubState *http.Cookie
+		for _, c := range loginResp.Cookies() {
+			if c.Name == "oauth_state" {
+				githubState = c
+			}
+		}
+		if githubState == nil {
+			t.Fatal("expected github oauth_state cookie")
+		}
+		// Replay the github-bound flow state against the okta callback.
+		state := redirectURL.Query().Get("state")
+		resp, env := doRaw(t, client, http.MethodGet, baseURL+"/api/v1/auth/okta/callback?state="+url.QueryEscape(state)+"&code=abc", nil, nil, []*http.Cookie{
+			{Name: "oauth_state", Value: githubState.Value, Path: "/api/v1/auth/okta"},
+		})
+		if resp.StatusCode != http.StatusUnauthorized || env.Error == nil || env.Error.Code != "UNAUTHORIZED" {
+			t.Fatalf("expected github state to be rejected by okta callback, got status=%d error=%#v", resp.StatusCode, env.Error)
+		}
+	})
+	requireAuditEvent(t, events, "auth.oauth.callback", "failure", "invalid_state")
+}
@@ -0,0 +1,84 @@
+package integration
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestEmailChangeSwapsAddressAndRevokesOtherSessions(t *testing.T) {
+	notifier := &verificationCaptureNotifier{}
+	baseURL, client, closeFn := newAuthTestServerWithOptions(t, authTestServerOptions{
+		verifyNotifier:      notifier,
+		resetNotifier:       notifier,
+		emailChangeNotifier: notifier,
+	})
+	defer closeFn()
+
+	registerAndLogin(t, client, baseURL, "before@example.com", "Valid#Pass1234")
+	otherDevice := newSessionClient(t)
+	resp, env := doJSON(t, otherDevice, http.MethodPost, baseURL+"/api/v1/auth/local/login", map[string]string{
+		"email":    "before@example.com",
+		"password": "Valid#Pass1234",
+	}, nil)
+	if resp.StatusCode != http.StatusOK || !env.Success {
+		t.Fatalf("second device login failed: status=%d err=%#v", resp.StatusCode, env.Error)
+	}
+	registerAndLogin(t, newSessionClient(t), baseURL, "occupied@example.com", "Valid#Pass1234")
+	csrf := map[string]string{"X-CSRF-Token": cookieValue(t, client, baseURL, "csrf_token")}
+
+	resp, env = doJSON(t, client, http.MethodPost, baseURL+"/api/v1/me/email/change", map[string]string{
+		"new_email": "occupied@example.com",
+	}, csrf)
+	if resp.StatusCode != http.StatusConflict {
+		t.Fatalf("expected taken address to conflict, got status=%d err=%#v", resp.StatusCode, env.Error)
+	}
+	resp, env = doJSON(t, client, http.MethodPost, baseURL+"/api/v1/me/email/change", map[string]string{
+		"new_email": "after@example.com",
+	}, csrf)
+	if resp.StatusCode != http.StatusAccepted || !env.Success {
+		t.Fatalf("email change request failed: status=%d err=%#v", resp.StatusCode, env.Error)
+	}
+	token, noticed := notifier.LastEmailChange()
+	if token == "" || noticed != "before@example.com" {
+		t.Fatalf("expected token for new address and notice to old, got token=%q notice=%q", token, noticed)
+	}
+
+	resp, env = doJSON(t, client, http.MethodPost, baseURL+"/api/v1/me/email/confirm", map[string]any{
+		"token":                 token,
+		"revoke_other_sessions": true,
+	}, csrf)
+	if resp.StatusCode != http.StatusOK || !env.Success {
+		t.Fatalf("email change confirm failed: status=%d err=%#v", resp.StatusCode, env.Error)
+	}
+
+	resp, env = doJSON(t, client, http.MethodGet, baseURL+"/api/v1/me", nil, nil)
+	if resp.StatusCode != http.StatusOK || !env.Success {
+		t.Fatalf("expected current session to survive, got status=%d", resp.StatusCode)
+	}
+	resp, _ = doJSON(t, otherDevice, http.MethodGet, baseURL+"/api/v1/me", nil, nil)
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected other session to be revoked, got %d", resp.StatusCode)
+	}
+
+	resp, _ = doJSON(t, newSessionClient(t), http.MethodPost, baseURL+"/api/v1/auth/local/login", map[string]string{
+		"email":    "before@example.com",
+		"password": "Valid#Pass1234",
+	}, nil)
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected old address login to fail, got %d", resp.StatusCode)
+	}
+	resp, env = doJSON(t, newSessionClient(t), http.MethodPost, baseURL+"/api/v1/auth/local/login", map[string]string{
+		"email":    "after@example.com",
+		"password": "Valid#Pass1234",
+	}, nil)
+	if resp.StatusCode != http.StatusOK || !env.Success {
+		t.Fatalf("expected new address login to succeed, got status=%d err=%#v", resp.StatusCode, env.Error)
+	}
+
+	resp, env = doJSON(t, client, http.MethodPost, baseURL+"/api/v1/me/email/confirm", map[string]any{
+		"token": token,
+	}, csrf)
+	if resp.StatusCode != http.StatusBadRequest || env.Error == nil || env.Error.Code != "INVALID_OR_EXPIRED_TOKEN" {
+		t.Fatalf("expected replayed token to fail, got status=%d err=%#v", resp.StatusCode, env.Error)
+	}
+}
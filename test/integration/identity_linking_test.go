@@ -0,0 +1,95 @@
+package integration
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/config"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/service"
+)
+
+type linkedIdentities struct {
+	Identities []struct {
+		ID       uint   `json:"id"`
+		Provider string `json:"provider"`
+	} `json:"identities"`
+	LocalCredential bool `json:"local_credential"`
+}
+
+func TestIdentityLinkListAndUnlink(t *testing.T) {
+	baseURL, client, closeFn := newAuthTestServerWithOptions(t, authTestServerOptions{
+		cfgOverride: func(cfg *config.Config) {
+			cfg.AuthOAuthAutoLinkByEmail = false
+		},
+		oauthProviders: map[string]service.OAuthProvider{
+			"github": newGenericOAuthProviderStub("github", "gh-linked", "someone-else@example.com"),
+			"okta":   newGenericOAuthProviderStub("okta", "okta-1", "linker@example.com"),
+		},
+	})
+	defer closeFn()
+
+	registerAndLogin(t, client, baseURL, "linker@example.com", "Valid#Pass1234")
+	csrf := map[string]string{"X-CSRF-Token": cookieValue(t, client, baseURL, "csrf_token")}
+
+	listIdentities := func() linkedIdentities {
+		t.Helper()
+		resp, env := doJSON(t, client, http.MethodGet, baseURL+"/api/v1/me/identities", nil, nil)
+		if resp.StatusCode != http.StatusOK || !env.Success {
+			t.Fatalf("list identities failed: status=%d err=%#v", resp.StatusCode, env.Error)
+		}
+		var out linkedIdentities
+		if err := json.Unmarshal(env.Data, &out); err != nil {
+			t.Fatalf("decode identities: %v", err)
+		}
+		return out
+	}
+	if got := listIdentities(); len(got.Identities) != 0 || !got.LocalCredential {
+		t.Fatalf("expected only a local credential, got %+v", got)
+	}
+
+	// With auto-linking off, a provider login whose email matches an existing
+	// account must not attach to it.
+	loginResp, _ := doRawTextNoRedirect(t, client, http.MethodGet, baseURL+"/api/v1/auth/okta/login", nil, nil, nil)
+	redirectURL, err := url.Parse(loginResp.Header.Get("Location"))
+	if err != nil {
+		t.Fatalf("parse okta redirect: %v", err)
+	}
+	resp, env := doJSON(t, client, http.MethodGet, baseURL+"/api/v1/auth/okta/callback?state="+url.QueryEscape(redirectURL.Query().Get("state"))+"&code=abc", nil, nil)
+	if resp.StatusCode != http.StatusConflict || env.Error == nil || env.Error.Code != "LINK_REQUIRED" {
+		t.Fatalf("expected LINK_REQUIRED, got status=%d err=%#v", resp.StatusCode, env.Error)
+	}
+
+	resp, env = doJSON(t, client, http.MethodPost, baseURL+"/api/v1/me/identities/github/link", nil, csrf)
+	if resp.StatusCode != http.StatusOK || !env.Success {
+		t.Fatalf("link begin failed: status=%d err=%#v", resp.StatusCode, env.Error)
+	}
+	var begin struct {
+		AuthorizationURL string `json:"authorization_url"`
+	}
+	if err := json.Unmarshal(env.Data, &begin); err != nil {
+		t.Fatalf("decode link begin: %v", err)
+	}
+	authURL, err := url.Parse(begin.AuthorizationURL)
+	if err != nil {
+		t.Fatalf("parse authorization url: %v", err)
+	}
+	resp, env = doJSON(t, client, http.MethodGet, baseURL+"/api/v1/auth/github/callback?state="+url.QueryEscape(authURL.Query().Get("state"))+"&code=abc", nil, nil)
+	if resp.StatusCode != http.StatusOK || !env.Success {
+		t.Fatalf("link callback failed: status=%d err=%#v", resp.StatusCode, env.Error)
+	}
+
+	linked := listIdentities()
+	if len(linked.Identities) != 1 || linked.Identities[0].Provider != "github" {
+		t.Fatalf("expected github identity after linking, got %+v", linked)
+	}
+
+	resp, env = doJSON(t, client, http.MethodDelete, baseURL+"/api/v1/me/identities/"+itoa(linked.Identities[0].ID), nil, csrf)
+	if resp.StatusCode != http.StatusOK || !env.Success {
+		t.Fatalf("unlink failed: status=%d err=%#v", resp.StatusCode, env.Error)
+	}
+	if got := listIdentities(); len(got.Identities) != 0 {
+		t.Fatalf("expected identity to be removed, got %+v", got)
+	}
+}
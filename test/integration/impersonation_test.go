@@ -0,0 +1,100 @@
+package integration
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/config"
+)
+
+func TestAdminImpersonationLifecycle(t *testing.T) {
+	baseURL, adminClient, closeFn := newAuthTestServerWithOptions(t, authTestServerOptions{
+		cfgOverride: func(cfg *config.Config) {
+			cfg.BootstrapAdminEmail = "admin-imp@example.com"
+			cfg.AuthImpersonationEnabled = true
+			cfg.AuthImpersonationTTL = cfg.JWTAccessTTL
+		},
+	})
+	defer closeFn()
+
+	registerAndLogin(t, adminClient, baseURL, "admin-imp@example.com", "Valid#Pass1234")
+	adminID := meID(t, adminClient, baseURL)
+	userClient := newSessionClient(t)
+	registerAndLogin(t, userClient, baseURL, "support-case@example.com", "Valid#Pass1234")
+	userID := meID(t, userClient, baseURL)
+	adminCSRF := map[string]string{"X-CSRF-Token": cookieValue(t, adminClient, baseURL, "csrf_token")}
+
+	resp, env := doJSON(t, userClient, http.MethodPost, baseURL+"/api/v1/admin/users/"+itoa(adminID)+"/impersonate", map[string]string{"reason": "x"}, nil)
+	if resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("expected a plain user to be denied impersonation, got %d", resp.StatusCode)
+	}
+	resp, _ = doJSON(t, adminClient, http.MethodPost, baseURL+"/api/v1/admin/users/"+itoa(adminID)+"/impersonate", map[string]string{"reason": "self"}, nil)
+	if resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("expected self impersonation to be rejected, got %d", resp.StatusCode)
+	}
+
+	resp, env = doJSON(t, adminClient, http.MethodPost, baseURL+"/api/v1/admin/users/"+itoa(userID)+"/impersonate", map[string]string{"reason": "ticket 4411"}, nil)
+	if resp.StatusCode != http.StatusOK || !env.Success {
+		t.Fatalf("impersonate failed: status=%d err=%#v", resp.StatusCode, env.Error)
+	}
+	var started struct {
+		ImpersonatorID uint `json:"impersonator_id"`
+	}
+	if err := json.Unmarshal(env.Data, &started); err != nil || started.ImpersonatorID != adminID {
+		t.Fatalf("expected impersonator_id %d, got %s", adminID, env.Data)
+	}
+	impersonationToken := cookieValue(t, adminClient, baseURL, "access_token")
+	if got := meID(t, adminClient, baseURL); got != userID {
+		t.Fatalf("expected /me to show the impersonated user %d, got %d", userID, got)
+	}
+
+	for _, tc := range []struct{ method, path string }{
+		{http.MethodPost, "/api/v1/auth/local/change-password"},
+		{http.MethodPost, "/api/v1/auth/logout"},
+		{http.MethodPost, "/api/v1/me/sessions/revoke-others"},
+		{http.MethodGet, "/api/v1/admin/users"},
+		{http.MethodPatch, "/api/v1/admin/users/" + itoa(userID) + "/roles"},
+	} {
+		resp, env = doJSON(t, adminClient, tc.method, baseURL+tc.path, map[string]any{}, adminCSRF)
+		if resp.StatusCode != http.StatusForbidden || env.Error == nil || env.Error.Code != "IMPERSONATION_FORBIDDEN" {
+			t.Fatalf("%s %s: expected IMPERSONATION_FORBIDDEN, got status=%d err=%#v", tc.method, tc.path, resp.StatusCode, env.Error)
+		}
+	}
+
+	resp, env = doJSON(t, adminClient, http.MethodPost, baseURL+"/api/v1/auth/impersonation/end", nil, adminCSRF)
+	if resp.StatusCode != http.StatusOK || !env.Success {
+		t.Fatalf("end impersonation failed: status=%d err=%#v", resp.StatusCode, env.Error)
+	}
+	if got := meID(t, adminClient, baseURL); got != adminID {
+		t.Fatalf("expected the admin session to be restored, got user %d", got)
+	}
+	resp, _ = doJSON(t, &http.Client{}, http.MethodGet, baseURL+"/api/v1/me", nil, map[string]string{
+		"Authorization": "Bearer " + impersonationToken,
+	})
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected the impersonation token to be revoked, got %d", resp.StatusCode)
+	}
+
+	resp, env = doJSON(t, adminClient, http.MethodPost, baseURL+"/api/v1/auth/impersonation/end", nil, map[string]string{
+		"X-CSRF-Token": cookieValue(t, adminClient, baseURL, "csrf_token"),
+	})
+	if resp.StatusCode != http.StatusBadRequest || env.Error == nil || env.Error.Code != "NOT_IMPERSONATING" {
+		t.Fatalf("expected NOT_IMPERSONATING outside impersonation, got status=%d err=%#v", resp.StatusCode, env.Error)
+	}
+}
+
+func meID(t *testing.T, client *http.Client, baseURL string) uint {
+	t.Helper()
+	resp, env := doJSON(t, client, http.MethodGet, baseURL+"/api/v1/me", nil, nil)
+	if resp.StatusCode != http.StatusOK || !env.Success {
+		t.Fatalf("me failed: status=%d err=%#v", resp.StatusCode, env.Error)
+	}
+	var me struct {
+		ID uint `json:"id"`
+	}
+	if err := json.Unmarshal(env.Data, &me); err != nil {
+		t.Fatalf("decode me: %v", err)
+	}
+	return me.ID
+}
@@ -0,0 +1,101 @@
+package integration
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"math/big"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/config"
+)
+
+func TestJWKSVerifiesAsymmetricAccessTokens(t *testing.T) {
+	baseURL, client, closeFn := newAuthTestServerWithOptions(t, authTestServerOptions{
+		cfgOverride: func(cfg *config.Config) {
+			cfg.JWTSigningAlg = "ES256"
+			cfg.JWTSigningKeyEncryptionKey = "jwt-key-encryption-key-0123456789abcdef"
+			cfg.JWTKeyringRefreshInterval = time.Minute
+		},
+	})
+	defer closeFn()
+
+	registerAndLogin(t, client, baseURL, "jwks@example.com", "Valid#Pass1234")
+	access := cookieValue(t, client, baseURL, "access_token")
+
+	resp, err := client.Get(baseURL + "/.well-known/jwks.json")
+	if err != nil {
+		t.Fatalf("fetch jwks: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	raw, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected jwks 200, got %d body=%s", resp.StatusCode, raw)
+	}
+	var set struct {
+		Keys []struct {
+			KID string `json:"kid"`
+			KTY string `json:"kty"`
+			Crv string `json:"crv"`
+			X   string `json:"x"`
+			Y   string `json:"y"`
+		} `json:"keys"`
+	}
+	if err := json.Unmarshal(raw, &set); err != nil || len(set.Keys) != 1 {
+		t.Fatalf("expected one published key, got %s err=%v", raw, err)
+	}
+	published := set.Keys[0]
+	pub := &ecdsa.PublicKey{Curve: elliptic.P256(), X: decodeBigInt(t, published.X), Y: decodeBigInt(t, published.Y)}
+
+	// Verify exactly as a downstream service would: public key only, picked by kid.
+	claims := jwt.MapClaims{}
+	tok, err := jwt.ParseWithClaims(access, claims, func(tok *jwt.Token) (any, error) {
+		if tok.Header["kid"] != published.KID {
+			t.Fatalf("expected kid %q, got %v", published.KID, tok.Header["kid"])
+		}
+		return pub, nil
+	}, jwt.WithValidMethods([]string{"ES256"}), jwt.WithAudience("aud"), jwt.WithIssuer("iss"))
+	if err != nil || !tok.Valid || claims["token_type"] != "access" {
+		t.Fatalf("expected access token to verify against jwks: err=%v claims=%v", err, claims)
+	}
+
+	resp2, env := doJSON(t, client, http.MethodGet, baseURL+"/api/v1/me", nil, nil)
+	if resp2.StatusCode != http.StatusOK || !env.Success {
+		t.Fatalf("expected asymmetric access token to authenticate /me, got %d", resp2.StatusCode)
+	}
+	csrf := cookieValue(t, client, baseURL, "csrf_token")
+	resp2, env = doJSON(t, client, http.MethodPost, baseURL+"/api/v1/auth/refresh", nil, map[string]string{"X-CSRF-Token": csrf})
+	if resp2.StatusCode != http.StatusOK || !env.Success {
+		t.Fatalf("expected refresh to keep working with asymmetric access tokens, got %d", resp2.StatusCode)
+	}
+}
+
+func TestJWKSEmptyForSharedSecretSigning(t *testing.T) {
+	baseURL, client, closeFn := newAuthTestServer(t)
+	defer closeFn()
+
+	resp, err := client.Get(baseURL + "/.well-known/jwks.json")
+	if err != nil {
+		t.Fatalf("fetch jwks: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	raw, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK || string(raw) != "{\"keys\":[]}\n" {
+		t.Fatalf("expected empty jwks in HS256 mode, got %d %s", resp.StatusCode, raw)
+	}
+}
+
+func decodeBigInt(t *testing.T, v string) *big.Int {
+	t.Helper()
+	b, err := base64.RawURLEncoding.DecodeString(v)
+	if err != nil {
+		t.Fatalf("decode jwk coordinate: %v", err)
+	}
+	return new(big.Int).SetBytes(b)
+}
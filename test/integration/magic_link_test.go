@@ -0,0 +1,98 @@
+package integration
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/config"
+)
+
+func TestMagicLinkSignInIssuesSession(t *testing.T) {
+	notifier := &verificationCaptureNotifier{}
+	baseURL, client, closeFn := newAuthTestServerWithOptions(t, authTestServerOptions{
+		cfgOverride: func(cfg *config.Config) {
+			cfg.AuthMagicLinkEnabled = true
+			cfg.AuthMagicLinkTokenTTL = 15 * time.Minute
+			cfg.IdempotencyEnabled = true
+			cfg.IdempotencyRedisEnabled = false
+		},
+		verifyNotifier: notifier,
+		resetNotifier:  notifier,
+		magicNotifier:  notifier,
+	})
+	defer closeFn()
+
+	resp, env := doJSON(t, newSessionClient(t), http.MethodPost, baseURL+"/api/v1/auth/local/register", map[string]string{
+		"email":    "magic@example.com",
+		"name":     "Magic User",
+		"password": "Valid#Pass1234",
+	}, map[string]string{"Idempotency-Key": "magic-register-001"})
+	if resp.StatusCode != http.StatusCreated || !env.Success {
+		t.Fatalf("register failed: status=%d err=%#v", resp.StatusCode, env.Error)
+	}
+
+	resp, env = doJSON(t, client, http.MethodPost, baseURL+"/api/v1/auth/magic/request", map[string]string{
+		"email": "nobody@example.com",
+	}, map[string]string{"Idempotency-Key": "magic-request-unknown"})
+	if resp.StatusCode != http.StatusOK || !env.Success {
+		t.Fatalf("expected enumeration-safe 200 for unknown email, got status=%d err=%#v", resp.StatusCode, env.Error)
+	}
+	if notifier.LastMagicToken() != "" {
+		t.Fatal("expected no magic link for unknown email")
+	}
+
+	idem := map[string]string{"Idempotency-Key": "magic-request-001"}
+	resp, env = doJSON(t, client, http.MethodPost, baseURL+"/api/v1/auth/magic/request", map[string]string{
+		"email": "magic@example.com",
+	}, idem)
+	if resp.StatusCode != http.StatusOK || !env.Success {
+		t.Fatalf("magic request failed: status=%d err=%#v", resp.StatusCode, env.Error)
+	}
+	token := notifier.LastMagicToken()
+	if token == "" {
+		t.Fatal("expected magic link token to be sent")
+	}
+	resp, _ = doJSON(t, client, http.MethodPost, baseURL+"/api/v1/auth/magic/request", map[string]string{
+		"email": "magic@example.com",
+	}, idem)
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("X-Idempotency-Replayed") != "true" {
+		t.Fatalf("expected replayed magic request, got status=%d", resp.StatusCode)
+	}
+	if notifier.LastMagicToken() != token {
+		t.Fatal("expected replayed request not to issue a new link")
+	}
+
+	resp, env = doJSON(t, client, http.MethodPost, baseURL+"/api/v1/auth/magic/confirm", map[string]string{
+		"token": token,
+	}, nil)
+	if resp.StatusCode != http.StatusOK || !env.Success {
+		t.Fatalf("magic confirm failed: status=%d err=%#v", resp.StatusCode, env.Error)
+	}
+	if cookieValue(t, client, baseURL, "access_token") == "" {
+		t.Fatal("expected session cookies after magic confirm")
+	}
+	resp, env = doJSON(t, client, http.MethodGet, baseURL+"/api/v1/me", nil, nil)
+	if resp.StatusCode != http.StatusOK || !env.Success {
+		t.Fatalf("me after magic sign-in failed: status=%d err=%#v", resp.StatusCode, env.Error)
+	}
+
+	resp, env = doJSON(t, newSessionClient(t), http.MethodPost, baseURL+"/api/v1/auth/magic/confirm", map[string]string{
+		"token": token,
+	}, nil)
+	if resp.StatusCode != http.StatusUnauthorized || env.Error == nil || env.Error.Code != "INVALID_OR_EXPIRED_TOKEN" {
+		t.Fatalf("expected replayed token to fail, got status=%d err=%#v", resp.StatusCode, env.Error)
+	}
+}
+
+func TestMagicLinkDisabledByDefault(t *testing.T) {
+	baseURL, client, closeFn := newAuthTestServer(t)
+	defer closeFn()
+
+	resp, env := doJSON(t, client, http.MethodPost, baseURL+"/api/v1/auth/magic/request", map[string]string{
+		"email": "magic@example.com",
+	}, nil)
+	if resp.StatusCode != http.StatusNotFound || env.Error == nil || env.Error.Code != "NOT_ENABLED" {
+		t.Fatalf("expected magic link to be disabled, got status=%d err=%#v", resp.StatusCode, env.Error)
+	}
+}
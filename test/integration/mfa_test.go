@@ -0,0 +1,108 @@
+package integration
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/cookiejar"
+	"testing"
+	"time"
+
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/config"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/security"
+)
+
+func TestMFAEnrollmentTwoStepLoginAndAdminEnforcement(t *testing.T) {
+	baseURL, client, closeFn := newAuthTestServerWithOptions(t, authTestServerOptions{
+		cfgOverride: func(cfg *config.Config) {
+			cfg.BootstrapAdminEmail = "admin-mfa@example.com"
+			cfg.AuthMFARequireForAdmin = true
+		},
+	})
+	defer closeFn()
+
+	registerAndLogin(t, client, baseURL, "admin-mfa@example.com", "Valid#Pass1234")
+
+	resp, env := doJSON(t, client, http.MethodGet, baseURL+"/api/v1/admin/roles", nil, nil)
+	if resp.StatusCode != http.StatusForbidden || env.Error == nil || env.Error.Code != "MFA_REQUIRED" {
+		t.Fatalf("expected MFA_REQUIRED before enrollment, got status=%d err=%#v", resp.StatusCode, env.Error)
+	}
+
+	csrf := map[string]string{"X-CSRF-Token": cookieValue(t, client, baseURL, "csrf_token")}
+	resp, env = doJSON(t, client, http.MethodPost, baseURL+"/api/v1/me/mfa/totp/setup", nil, csrf)
+	if resp.StatusCode != http.StatusOK || !env.Success {
+		t.Fatalf("totp setup failed: status=%d err=%#v", resp.StatusCode, env.Error)
+	}
+	var enrollment struct {
+		Secret     string `json:"secret"`
+		OTPAuthURI string `json:"otpauth_uri"`
+	}
+	if err := json.Unmarshal(env.Data, &enrollment); err != nil || enrollment.Secret == "" || enrollment.OTPAuthURI == "" {
+		t.Fatalf("decode enrollment: %v %+v", err, enrollment)
+	}
+
+	resp, env = doJSON(t, client, http.MethodPost, baseURL+"/api/v1/me/mfa/totp/confirm", map[string]string{"code": "000000"}, csrf)
+	if resp.StatusCode != http.StatusBadRequest || env.Error == nil || env.Error.Code != "INVALID_MFA_CODE" {
+		t.Fatalf("expected INVALID_MFA_CODE, got status=%d err=%#v", resp.StatusCode, env.Error)
+	}
+	code, err := security.TOTPCode(enrollment.Secret, security.TOTPStep(time.Now()))
+	if err != nil {
+		t.Fatalf("totp code: %v", err)
+	}
+	resp, env = doJSON(t, client, http.MethodPost, baseURL+"/api/v1/me/mfa/totp/confirm", map[string]string{"code": code}, csrf)
+	if resp.StatusCode != http.StatusOK || !env.Success {
+		t.Fatalf("totp confirm failed: status=%d err=%#v", resp.StatusCode, env.Error)
+	}
+	var confirmed struct {
+		RecoveryCodes []string `json:"recovery_codes"`
+	}
+	if err := json.Unmarshal(env.Data, &confirmed); err != nil || len(confirmed.RecoveryCodes) == 0 {
+		t.Fatalf("decode recovery codes: %v %+v", err, confirmed)
+	}
+
+	resp, _ = doJSON(t, client, http.MethodGet, baseURL+"/api/v1/admin/roles", nil, nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected admin access after enrollment, got %d", resp.StatusCode)
+	}
+
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		t.Fatalf("cookie jar: %v", err)
+	}
+	fresh := &http.Client{Jar: jar}
+	resp, env = doJSON(t, fresh, http.MethodPost, baseURL+"/api/v1/auth/local/login", map[string]string{
+		"email":    "admin-mfa@example.com",
+		"password": "Valid#Pass1234",
+	}, nil)
+	if resp.StatusCode != http.StatusOK || !env.Success {
+		t.Fatalf("first factor login failed: status=%d err=%#v", resp.StatusCode, env.Error)
+	}
+	var challenge struct {
+		MFARequired bool   `json:"mfa_required"`
+		MFAToken    string `json:"mfa_token"`
+	}
+	if err := json.Unmarshal(env.Data, &challenge); err != nil || !challenge.MFARequired || challenge.MFAToken == "" {
+		t.Fatalf("expected mfa challenge: %v %+v", err, challenge)
+	}
+	resp, _ = doJSON(t, fresh, http.MethodGet, baseURL+"/api/v1/me", nil, nil)
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected no session before second factor, got %d", resp.StatusCode)
+	}
+
+	resp, env = doJSON(t, fresh, http.MethodPost, baseURL+"/api/v1/auth/mfa/verify", map[string]string{"mfa_token": challenge.MFAToken, "code": "000000"}, nil)
+	if resp.StatusCode != http.StatusUnauthorized || env.Error == nil || env.Error.Code != "INVALID_MFA_CODE" {
+		t.Fatalf("expected INVALID_MFA_CODE, got status=%d err=%#v", resp.StatusCode, env.Error)
+	}
+	resp, env = doJSON(t, fresh, http.MethodPost, baseURL+"/api/v1/auth/mfa/verify", map[string]string{"mfa_token": challenge.MFAToken, "code": confirmed.RecoveryCodes[0]}, nil)
+	if resp.StatusCode != http.StatusOK || !env.Success {
+		t.Fatalf("mfa verify failed: status=%d err=%#v", resp.StatusCode, env.Error)
+	}
+	resp, _ = doJSON(t, fresh, http.MethodGet, baseURL+"/api/v1/me", nil, nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected session after second factor, got %d", resp.StatusCode)
+	}
+
+	resp, _ = doJSON(t, fresh, http.MethodPost, baseURL+"/api/v1/auth/mfa/verify", map[string]string{"mfa_token": challenge.MFAToken, "code": confirmed.RecoveryCodes[1]}, nil)
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected consumed challenge to be rejected, got %d", resp.StatusCode)
+	}
+}
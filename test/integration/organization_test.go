@@ -0,0 +1,132 @@
+package integration
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/config"
+)
+
+func TestOrganizationScopedRoleBindings(t *testing.T) {
+	baseURL, adminClient, closeFn := newAuthTestServerWithOptions(t, authTestServerOptions{
+		cfgOverride: func(cfg *config.Config) {
+			cfg.BootstrapAdminEmail = "admin-orgs@example.com"
+			cfg.OrganizationsEnabled = true
+		},
+	})
+	defer closeFn()
+
+	registerAndLogin(t, adminClient, baseURL, "admin-orgs@example.com", "Valid#Pass1234")
+	aliceClient := newSessionClient(t)
+	registerAndLogin(t, aliceClient, baseURL, "alice-orgs@example.com", "Valid#Pass1234")
+	aliceID := meID(t, aliceClient, baseURL)
+	bobClient := newSessionClient(t)
+	registerAndLogin(t, bobClient, baseURL, "bob-orgs@example.com", "Valid#Pass1234")
+	bobID := meID(t, bobClient, baseURL)
+
+	createOrg := func(slug string) uint {
+		t.Helper()
+		resp, env := doJSON(t, adminClient, http.MethodPost, baseURL+"/api/v1/admin/orgs", map[string]string{"slug": slug, "name": slug}, nil)
+		if resp.StatusCode != http.StatusCreated {
+			t.Fatalf("create org failed: status=%d err=%#v", resp.StatusCode, env.Error)
+		}
+		var org struct {
+			ID uint `json:"id"`
+		}
+		if err := json.Unmarshal(env.Data, &org); err != nil {
+			t.Fatalf("decode org: %v", err)
+		}
+		return org.ID
+	}
+	acme := createOrg("acme")
+	globex := createOrg("globex")
+	if resp, _ := doJSON(t, adminClient, http.MethodPost, baseURL+"/api/v1/admin/orgs", map[string]string{"slug": "acme", "name": "dup"}, nil); resp.StatusCode != http.StatusConflict {
+		t.Fatalf("expected duplicate slug to conflict, got %d", resp.StatusCode)
+	}
+
+	resp, env := doJSON(t, adminClient, http.MethodPost, baseURL+"/api/v1/admin/roles", map[string]any{
+		"name":        "org-manager",
+		"permissions": []string{"members:read", "members:write", "users:read"},
+	}, nil)
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("create role failed: status=%d err=%#v", resp.StatusCode, env.Error)
+	}
+	var manager roleView
+	if err := json.Unmarshal(env.Data, &manager); err != nil {
+		t.Fatalf("decode role: %v", err)
+	}
+
+	// The admin is not a member of acme but may administer it through the
+	// global orgs:write grant.
+	resp, env = doJSON(t, adminClient, http.MethodPut, baseURL+"/api/v1/orgs/"+itoa(acme)+"/members/"+itoa(aliceID), map[string]any{"role_ids": []uint{manager.ID}}, nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("bind alice in acme failed: status=%d err=%#v", resp.StatusCode, env.Error)
+	}
+
+	resp, env = doJSON(t, aliceClient, http.MethodGet, baseURL+"/api/v1/orgs", nil, nil)
+	var mine []struct {
+		ID   uint   `json:"id"`
+		Slug string `json:"slug"`
+	}
+	if resp.StatusCode != http.StatusOK || json.Unmarshal(env.Data, &mine) != nil || len(mine) != 1 || mine[0].ID != acme {
+		t.Fatalf("expected alice to see only acme, got status=%d data=%s", resp.StatusCode, env.Data)
+	}
+	if resp, env := doJSON(t, aliceClient, http.MethodGet, baseURL+"/api/v1/orgs/"+itoa(acme)+"/members", nil, nil); resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected org-scoped members:read in acme, got status=%d err=%#v", resp.StatusCode, env.Error)
+	}
+	header := map[string]string{"X-Organization-ID": itoa(acme)}
+	if resp, env := doJSON(t, aliceClient, http.MethodGet, baseURL+"/api/v1/org/members", nil, header); resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected header-selected org scope to work, got status=%d err=%#v", resp.StatusCode, env.Error)
+	}
+	if resp, _ := doJSON(t, aliceClient, http.MethodGet, baseURL+"/api/v1/orgs/"+itoa(globex)+"/members", nil, nil); resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected non-member to get 404 for globex, got %d", resp.StatusCode)
+	}
+	// users:read is only granted inside acme: the admin user list honours it
+	// at acme scope, limited to acme's members, and not at global scope.
+	if resp, _ := doJSON(t, aliceClient, http.MethodGet, baseURL+"/api/v1/admin/users", nil, nil); resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("expected org-scoped grant not to reach global admin routes, got %d", resp.StatusCode)
+	}
+	resp, env = doJSON(t, aliceClient, http.MethodGet, baseURL+"/api/v1/admin/users", nil, header)
+	var members struct {
+		Items []struct {
+			ID uint `json:"id"`
+		} `json:"items"`
+	}
+	if resp.StatusCode != http.StatusOK || json.Unmarshal(env.Data, &members) != nil || len(members.Items) != 1 || members.Items[0].ID != aliceID {
+		t.Fatalf("expected acme-scoped user list to contain only alice, got status=%d data=%s", resp.StatusCode, env.Data)
+	}
+	resp, env = doJSON(t, aliceClient, http.MethodPost, baseURL+"/api/v1/admin/users/"+itoa(bobID)+"/suspend", nil, header)
+	if resp.StatusCode != http.StatusBadRequest || env.Error == nil || env.Error.Code != "ORGANIZATION_SCOPE_UNSUPPORTED" {
+		t.Fatalf("expected global-only admin route to refuse org scope, got status=%d err=%#v", resp.StatusCode, env.Error)
+	}
+
+	resp, env = doJSON(t, adminClient, http.MethodGet, baseURL+"/api/v1/admin/roles?name=admin", nil, nil)
+	var adminRoles struct {
+		Items []roleView `json:"items"`
+	}
+	if resp.StatusCode != http.StatusOK || json.Unmarshal(env.Data, &adminRoles) != nil || len(adminRoles.Items) != 1 {
+		t.Fatalf("lookup admin role failed: status=%d data=%s", resp.StatusCode, env.Data)
+	}
+	resp, env = doJSON(t, aliceClient, http.MethodPut, baseURL+"/api/v1/orgs/"+itoa(acme)+"/members/"+itoa(bobID), map[string]any{"role_ids": []uint{adminRoles.Items[0].ID}}, nil)
+	if resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("expected binding a more privileged role to be refused, got status=%d err=%#v", resp.StatusCode, env.Error)
+	}
+	resp, env = doJSON(t, aliceClient, http.MethodPut, baseURL+"/api/v1/orgs/"+itoa(acme)+"/members/"+itoa(bobID), map[string]any{"role_ids": []uint{}}, nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected alice to add bob to acme, got status=%d err=%#v", resp.StatusCode, env.Error)
+	}
+	if resp, _ := doJSON(t, bobClient, http.MethodGet, baseURL+"/api/v1/orgs/"+itoa(acme), nil, nil); resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected bob to see acme after joining, got %d", resp.StatusCode)
+	}
+	if resp, _ := doJSON(t, bobClient, http.MethodGet, baseURL+"/api/v1/orgs/"+itoa(acme)+"/members", nil, nil); resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("expected bob without bindings to lack members:read, got %d", resp.StatusCode)
+	}
+
+	if resp, env := doJSON(t, aliceClient, http.MethodDelete, baseURL+"/api/v1/org/members/"+itoa(bobID), nil, header); resp.StatusCode != http.StatusOK {
+		t.Fatalf("remove bob failed: status=%d err=%#v", resp.StatusCode, env.Error)
+	}
+	if resp, _ := doJSON(t, bobClient, http.MethodGet, baseURL+"/api/v1/orgs/"+itoa(acme), nil, nil); resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected removed member to lose access, got %d", resp.StatusCode)
+	}
+}
@@ -1,6 +1,7 @@
 package integration
 
 import (
+	"encoding/json"
 	"net/http"
 	"testing"
 
@@ -178,3 +179,65 @@ func TestPasswordResetReplayAndUnknownForgotResponse(t *testing.T) {
 		t.Fatalf("forgot responses should both be successful: known=%v unknown=%v", envKnown.Success, envUnknown.Success)
 	}
 }
+
+func TestPasswordResetRejectsRecentPasswordAndKeepsToken(t *testing.T) {
+	notifier := &verificationCaptureNotifier{}
+	baseURL, client, closeFn := newAuthTestServerWithOptions(t, authTestServerOptions{
+		verifyNotifier: notifier,
+		resetNotifier:  notifier,
+		cfgOverride: func(cfg *config.Config) {
+			cfg.PasswordHistoryCount = 3
+			cfg.PasswordRejectPersonalInfo = true
+		},
+	})
+	defer closeFn()
+
+	registerBody := map[string]string{
+		"email":    "policy-reset@example.com",
+		"name":     "Policy Reset",
+		"password": "Valid#Pass1234",
+	}
+	resp, env := doJSON(t, client, http.MethodPost, baseURL+"/api/v1/auth/local/register", registerBody, nil)
+	if resp.StatusCode != http.StatusCreated || !env.Success {
+		t.Fatalf("register failed: status=%d success=%v", resp.StatusCode, env.Success)
+	}
+	resp, _ = doJSON(t, client, http.MethodPost, baseURL+"/api/v1/auth/local/password/forgot", map[string]string{
+		"email": registerBody["email"],
+	}, nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("forgot failed: status=%d", resp.StatusCode)
+	}
+	token := notifier.LastResetToken()
+
+	for _, tc := range []struct {
+		password string
+		rule     string
+	}{
+		{password: registerBody["password"], rule: "reused"},
+		{password: "Policy#Pass1234", rule: "personal_info"},
+	} {
+		resp, env = doJSON(t, client, http.MethodPost, baseURL+"/api/v1/auth/local/password/reset", map[string]string{
+			"token":        token,
+			"new_password": tc.password,
+		}, nil)
+		if resp.StatusCode != http.StatusBadRequest || env.Error == nil {
+			t.Fatalf("expected %s password to be rejected, got status=%d", tc.rule, resp.StatusCode)
+		}
+		var details struct {
+			Violations []struct {
+				Rule string `json:"rule"`
+			} `json:"violations"`
+		}
+		if err := json.Unmarshal(env.Error.Details, &details); err != nil || len(details.Violations) != 1 || details.Violations[0].Rule != tc.rule {
+			t.Fatalf("expected a %s violation, got %s (err=%v)", tc.rule, env.Error.Details, err)
+		}
+	}
+
+	resp, env = doJSON(t, client, http.MethodPost, baseURL+"/api/v1/auth/local/password/reset", map[string]string{
+		"token":        token,
+		"new_password": "Fresh#Pass5678",
+	}, nil)
+	if resp.StatusCode != http.StatusOK || !env.Success {
+		t.Fatalf("expected the token to survive rejected attempts, got status=%d", resp.StatusCode)
+	}
+}
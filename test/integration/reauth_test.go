@@ -0,0 +1,139 @@
+package integration
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/config"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/domain"
+)
+
+func TestStaleSessionMustReauthenticateForAdminWrites(t *testing.T) {
+	baseURL, adminClient, closeFn := newAuthTestServerWithOptions(t, authTestServerOptions{
+		cfgOverride: func(cfg *config.Config) {
+			cfg.BootstrapAdminEmail = "admin-reauth@example.com"
+			cfg.AuthReauthMaxAge = 5 * time.Minute
+		},
+	})
+	defer closeFn()
+
+	registerAndLogin(t, adminClient, baseURL, "admin-reauth@example.com", "Valid#Pass1234")
+	createPermission := func(action string) (*http.Response, apiEnvelope) {
+		return doJSON(t, adminClient, http.MethodPost, baseURL+"/api/v1/admin/permissions", map[string]string{
+			"resource": "reauth_reports",
+			"action":   action,
+		}, nil)
+	}
+	if resp, env := createPermission("read"); resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected a fresh login to pass step-up, got status=%d err=%#v", resp.StatusCode, env.Error)
+	}
+
+	// Same shared in-memory database as the server under test. Ageing the
+	// stored auth_time and refreshing yields a token for an old login.
+	db, err := gorm.Open(sqlite.Open(fmt.Sprintf("file:%s?mode=memory&cache=shared", strings.ReplaceAll(t.Name(), "/", "_"))), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	if err := db.Model(&domain.Session{}).Where("revoked_at IS NULL").Update("auth_time", time.Now().Add(-time.Hour)).Error; err != nil {
+		t.Fatalf("age sessions: %v", err)
+	}
+	csrf := map[string]string{"X-CSRF-Token": cookieValue(t, adminClient, baseURL, "csrf_token")}
+	if resp, env := doJSON(t, adminClient, http.MethodPost, baseURL+"/api/v1/auth/refresh", nil, csrf); resp.StatusCode != http.StatusOK {
+		t.Fatalf("refresh failed: status=%d err=%#v", resp.StatusCode, env.Error)
+	}
+	csrf["X-CSRF-Token"] = cookieValue(t, adminClient, baseURL, "csrf_token")
+
+	resp, env := createPermission("write")
+	if resp.StatusCode != http.StatusForbidden || env.Error == nil || env.Error.Code != "REAUTH_REQUIRED" {
+		t.Fatalf("expected REAUTH_REQUIRED for a stale session, got status=%d err=%#v", resp.StatusCode, env.Error)
+	}
+	resp, env = doJSON(t, adminClient, http.MethodPost, baseURL+"/api/v1/me/sessions/revoke-others", nil, csrf)
+	if resp.StatusCode != http.StatusForbidden || env.Error == nil || env.Error.Code != "REAUTH_REQUIRED" {
+		t.Fatalf("expected REAUTH_REQUIRED on session revocation, got status=%d err=%#v", resp.StatusCode, env.Error)
+	}
+	resp, env = doJSON(t, adminClient, http.MethodDelete, baseURL+"/api/v1/me", nil, csrf)
+	if resp.StatusCode != http.StatusForbidden || env.Error == nil || env.Error.Code != "REAUTH_REQUIRED" {
+		t.Fatalf("expected REAUTH_REQUIRED on account deletion, got status=%d err=%#v", resp.StatusCode, env.Error)
+	}
+	if resp, _ := doJSON(t, adminClient, http.MethodGet, baseURL+"/api/v1/admin/permissions", nil, nil); resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected reads to stay available, got %d", resp.StatusCode)
+	}
+
+	resp, env = doJSON(t, adminClient, http.MethodPost, baseURL+"/api/v1/auth/reauth", map[string]string{"password": "Wrong#Pass1234"}, csrf)
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected a wrong password to be rejected, got status=%d err=%#v", resp.StatusCode, env.Error)
+	}
+	var sessionsBefore int64
+	db.Model(&domain.Session{}).Count(&sessionsBefore)
+	resp, env = doJSON(t, adminClient, http.MethodPost, baseURL+"/api/v1/auth/reauth", map[string]string{"password": "Valid#Pass1234"}, csrf)
+	if resp.StatusCode != http.StatusOK || !env.Success {
+		t.Fatalf("reauth failed: status=%d err=%#v", resp.StatusCode, env.Error)
+	}
+	var reauth struct {
+		AuthTime time.Time `json:"auth_time"`
+	}
+	if err := json.Unmarshal(env.Data, &reauth); err != nil || time.Since(reauth.AuthTime) > time.Minute {
+		t.Fatalf("expected a fresh auth_time, got %s", env.Data)
+	}
+	var sessionsAfter int64
+	db.Model(&domain.Session{}).Count(&sessionsAfter)
+	if sessionsAfter != sessionsBefore {
+		t.Fatalf("expected reauth to reuse the session, sessions %d -> %d", sessionsBefore, sessionsAfter)
+	}
+
+	if resp, env := createPermission("write"); resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected the admin write to pass after reauth, got status=%d err=%#v", resp.StatusCode, env.Error)
+	}
+	if resp, env := doJSON(t, adminClient, http.MethodPost, baseURL+"/api/v1/auth/refresh", nil, csrf); resp.StatusCode != http.StatusOK {
+		t.Fatalf("refresh after reauth failed: status=%d err=%#v", resp.StatusCode, env.Error)
+	}
+	if resp, env := createPermission("delete"); resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected the refreshed auth_time to survive rotation, got status=%d err=%#v", resp.StatusCode, env.Error)
+	}
+}
+
+func TestAccountDeletionRequiresReauthWithStepUpDisabled(t *testing.T) {
+	baseURL, client, closeFn := newAuthTestServerWithOptions(t, authTestServerOptions{
+		cfgOverride: func(cfg *config.Config) {
+			cfg.AuthReauthMaxAge = 0
+			cfg.AuthAccountDeletionGracePeriod = 24 * time.Hour
+		},
+	})
+	defer closeFn()
+
+	registerAndLogin(t, client, baseURL, "leaving-reauth@example.com", "Valid#Pass1234")
+	db, err := gorm.Open(sqlite.Open(fmt.Sprintf("file:%s?mode=memory&cache=shared", strings.ReplaceAll(t.Name(), "/", "_"))), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	if err := db.Model(&domain.Session{}).Where("revoked_at IS NULL").Update("auth_time", time.Now().Add(-time.Hour)).Error; err != nil {
+		t.Fatalf("age sessions: %v", err)
+	}
+	csrf := map[string]string{"X-CSRF-Token": cookieValue(t, client, baseURL, "csrf_token")}
+
+	// With AUTH_REAUTH_MAX_AGE=0 the step-up middleware lets everything
+	// through; deletion still checks the session itself.
+	resp, env := doJSON(t, client, http.MethodDelete, baseURL+"/api/v1/me", nil, csrf)
+	if resp.StatusCode != http.StatusForbidden || env.Error == nil || env.Error.Code != "REAUTH_REQUIRED" {
+		t.Fatalf("expected REAUTH_REQUIRED for a stale session, got status=%d err=%#v", resp.StatusCode, env.Error)
+	}
+	if resp, env := doJSON(t, client, http.MethodPost, baseURL+"/api/v1/auth/reauth", map[string]string{"password": "Valid#Pass1234"}, csrf); resp.StatusCode != http.StatusOK {
+		t.Fatalf("reauth failed: status=%d err=%#v", resp.StatusCode, env.Error)
+	}
+	resp, env = doJSON(t, client, http.MethodDelete, baseURL+"/api/v1/me", nil, csrf)
+	if resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("expected deletion after reauth, got status=%d err=%#v", resp.StatusCode, env.Error)
+	}
+}
@@ -0,0 +1,47 @@
+package integration
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/config"
+)
+
+func TestTemporaryRoleBindingStopsGrantingAtExpiry(t *testing.T) {
+	baseURL, adminClient, closeFn := newAuthTestServerWithOptions(t, authTestServerOptions{
+		cfgOverride: func(cfg *config.Config) {
+			cfg.BootstrapAdminEmail = "admin-role-expiry@example.com"
+		},
+	})
+	defer closeFn()
+
+	registerAndLogin(t, adminClient, baseURL, "admin-role-expiry@example.com", "Valid#Pass1234")
+	oncallClient := newSessionClient(t)
+	registerAndLogin(t, oncallClient, baseURL, "oncall-role-expiry@example.com", "Valid#Pass1234")
+	oncallID := meID(t, oncallClient, baseURL)
+	roleID := mustCreateRole(t, adminClient, baseURL, "oncall-reader", []string{"users:read"})
+
+	rolesURL := baseURL + "/api/v1/admin/users/" + itoa(oncallID) + "/roles"
+	if resp, _ := doJSON(t, adminClient, http.MethodPatch, rolesURL, map[string]any{
+		"bindings": []map[string]any{{"role_id": roleID, "expires_at": time.Now().Add(-time.Minute).UTC()}},
+	}, nil); resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected an expiry in the past to be rejected, got %d", resp.StatusCode)
+	}
+
+	expiresAt := time.Now().Add(1500 * time.Millisecond).UTC()
+	resp, env := doJSON(t, adminClient, http.MethodPatch, rolesURL, map[string]any{
+		"bindings": []map[string]any{{"role_id": roleID, "expires_at": expiresAt, "reason": "INC-42 on-call"}},
+	}, nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("grant temporary role failed: status=%d err=%#v", resp.StatusCode, env.Error)
+	}
+	if resp, _ := doJSON(t, oncallClient, http.MethodGet, baseURL+"/api/v1/admin/users", nil, nil); resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected temporary role to grant users:read, got %d", resp.StatusCode)
+	}
+
+	time.Sleep(time.Until(expiresAt) + 100*time.Millisecond)
+	if resp, _ := doJSON(t, oncallClient, http.MethodGet, baseURL+"/api/v1/admin/users", nil, nil); resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("expected expired role to stop granting users:read, got %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,114 @@
+package integration
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/config"
+)
+
+func TestServiceAccountClientCredentialsLifecycle(t *testing.T) {
+	baseURL, client, closeFn := newAuthTestServerWithOptions(t, authTestServerOptions{
+		cfgOverride: func(cfg *config.Config) {
+			cfg.BootstrapAdminEmail = "sa-admin@example.com"
+			cfg.AuthServiceAccountsEnabled = true
+			cfg.AuthServiceAccountTokenTTL = 5 * time.Minute
+			cfg.RefreshTokenPepper = "pepper-1234567890"
+		},
+	})
+	defer closeFn()
+
+	registerAndLogin(t, client, baseURL, "sa-admin@example.com", "Valid#Pass1234")
+	csrf := map[string]string{"X-CSRF-Token": cookieValue(t, client, baseURL, "csrf_token")}
+
+	resp, env := doJSON(t, client, http.MethodPost, baseURL+"/api/v1/admin/roles", map[string]any{
+		"name":        "sa-reader",
+		"description": "read-only automation",
+		"permissions": []string{"users:read"},
+	}, csrf)
+	if resp.StatusCode != http.StatusCreated || !env.Success {
+		t.Fatalf("create role failed: status=%d err=%#v", resp.StatusCode, env.Error)
+	}
+	var role struct {
+		ID uint `json:"id"`
+	}
+	if err := json.Unmarshal(env.Data, &role); err != nil {
+		t.Fatalf("decode role: %v", err)
+	}
+
+	resp, env = doJSON(t, client, http.MethodPost, baseURL+"/api/v1/admin/service-accounts", map[string]any{
+		"name":     "nightly-report",
+		"role_ids": []uint{role.ID},
+	}, csrf)
+	if resp.StatusCode != http.StatusCreated || !env.Success {
+		t.Fatalf("create service account failed: status=%d err=%#v", resp.StatusCode, env.Error)
+	}
+	var account struct {
+		ID           uint     `json:"id"`
+		ClientID     string   `json:"client_id"`
+		ClientSecret string   `json:"client_secret"`
+		Permissions  []string `json:"permissions"`
+	}
+	if err := json.Unmarshal(env.Data, &account); err != nil {
+		t.Fatalf("decode service account: %v", err)
+	}
+	if account.ClientID == "" || account.ClientSecret == "" || len(account.Permissions) != 1 {
+		t.Fatalf("unexpected service account %+v", account)
+	}
+
+	requestToken := func() (int, map[string]any) {
+		form := url.Values{"grant_type": {"client_credentials"}}
+		req, err := http.NewRequest(http.MethodPost, baseURL+"/api/v1/oauth/token", strings.NewReader(form.Encode()))
+		if err != nil {
+			t.Fatalf("new token request: %v", err)
+		}
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.SetBasicAuth(url.QueryEscape(account.ClientID), url.QueryEscape(account.ClientSecret))
+		resp, err := (&http.Client{}).Do(req)
+		if err != nil {
+			t.Fatalf("token request: %v", err)
+		}
+		defer resp.Body.Close()
+		var body map[string]any
+		if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+			t.Fatalf("decode token response: %v", err)
+		}
+		return resp.StatusCode, body
+	}
+
+	status, body := requestToken()
+	if status != http.StatusOK || body["token_type"] != "Bearer" {
+		t.Fatalf("token grant failed: status=%d body=%v", status, body)
+	}
+	accessToken, _ := body["access_token"].(string)
+
+	bearer := func(method, path string) int {
+		resp, _ := doJSON(t, &http.Client{}, method, baseURL+path, nil, map[string]string{
+			"Authorization": "Bearer " + accessToken,
+		})
+		return resp.StatusCode
+	}
+	if code := bearer(http.MethodGet, "/api/v1/admin/users"); code != http.StatusOK {
+		t.Fatalf("expected service account to list users, got %d", code)
+	}
+	if code := bearer(http.MethodGet, "/api/v1/admin/roles"); code != http.StatusForbidden {
+		t.Fatalf("expected permission outside role to be forbidden, got %d", code)
+	}
+	if code := bearer(http.MethodGet, "/api/v1/me"); code != http.StatusUnauthorized {
+		t.Fatalf("expected user-only endpoint to reject service account, got %d", code)
+	}
+
+	resp, env = doJSON(t, client, http.MethodPatch, baseURL+"/api/v1/admin/service-accounts/"+itoa(account.ID), map[string]any{
+		"disabled": true,
+	}, csrf)
+	if resp.StatusCode != http.StatusOK || !env.Success {
+		t.Fatalf("disable service account failed: status=%d err=%#v", resp.StatusCode, env.Error)
+	}
+	if status, body := requestToken(); status != http.StatusUnauthorized || body["error"] != "invalid_client" {
+		t.Fatalf("expected disabled account to be refused, got status=%d body=%v", status, body)
+	}
+}
@@ -0,0 +1,409 @@
+package integration
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/http"
+	"net/mail"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/config"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/domain"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/repository"
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/service"
+)
+
+type smtpMessage struct {
+	From string
+	To   []string
+	Data string
+}
+
+// smtpStandIn is a minimal plaintext SMTP server for exercising the SMTP
+// notifier end to end. It accepts AUTH PLAIN when credentials are set and
+// never offers STARTTLS.
+type smtpStandIn struct {
+	listener net.Listener
+	username string
+	password string
+
+	mu       sync.Mutex
+	messages []smtpMessage
+}
+
+func newSMTPStandIn(t *testing.T, username, password string) *smtpStandIn {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	s := &smtpStandIn{listener: ln, username: username, password: password}
+	go s.serve()
+	t.Cleanup(func() { _ = ln.Close() })
+	return s
+}
+
+func (s *smtpStandIn) Port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+
+func (s *smtpStandIn) Messages() []smtpMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]smtpMessage(nil), s.messages...)
+}
+
+func (s *smtpStandIn) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *smtpStandIn) handle(conn net.Conn) {
+	defer func() { _ = conn.Close() }()
+	r := bufio.NewReader(conn)
+	reply := func(line string) { _, _ = io.WriteString(conn, line+"\r\n") }
+	reply("220 smtp-stand-in ready")
+
+	authed := s.username == ""
+	var current smtpMessage
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		verb := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+		switch verb {
+		case "EHLO", "HELO":
+			if s.username != "" {
+				reply("250-smtp-stand-in")
+				reply("250 AUTH PLAIN")
+			} else {
+				reply("250 smtp-stand-in")
+			}
+		case "AUTH":
+			fields := strings.Fields(line)
+			raw, _ := base64.StdEncoding.DecodeString(fields[len(fields)-1])
+			parts := strings.Split(string(raw), "\x00")
+			if len(parts) == 3 && parts[1] == s.username && parts[2] == s.password {
+				authed = true
+				reply("235 authenticated")
+			} else {
+				reply("535 authentication failed")
+			}
+		case "MAIL":
+			if !authed {
+				reply("530 authentication required")
+				continue
+			}
+			current = smtpMessage{From: strings.Trim(strings.TrimPrefix(line[5:], "FROM:"), "<> ")}
+			reply("250 ok")
+		case "RCPT":
+			current.To = append(current.To, strings.Trim(strings.TrimPrefix(line[5:], "TO:"), "<> "))
+			reply("250 ok")
+		case "DATA":
+			reply("354 end with <CRLF>.<CRLF>")
+			var data strings.Builder
+			for {
+				dl, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if dl == ".\r\n" {
+					break
+				}
+				data.WriteString(strings.TrimPrefix(dl, "."))
+			}
+			current.Data = data.String()
+			s.mu.Lock()
+			s.messages = append(s.messages, current)
+			s.mu.Unlock()
+			reply("250 queued")
+		case "RSET", "NOOP":
+			reply("250 ok")
+		case "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("502 not implemented")
+		}
+	}
+}
+
+func newSMTPTestConfig(port int) *config.Config {
+	return &config.Config{
+		EmailNotifier:   "smtp",
+		SMTPHost:        "127.0.0.1",
+		SMTPPort:        port,
+		SMTPUsername:    "mailer",
+		SMTPPassword:    "mailer-secret",
+		SMTPFrom:        "Accounts <no-reply@example.com>",
+		SMTPTLSMode:     "none",
+		SMTPDialTimeout: time.Second,
+		SMTPSendTimeout: 5 * time.Second,
+	}
+}
+
+func newSMTPTestNotifier(t *testing.T, cfg *config.Config) *service.SMTPEmailNotifier {
+	t.Helper()
+	templates, err := service.NewEmailTemplates(cfg.EmailTemplateDir, cfg.EmailDefaultLocale)
+	if err != nil {
+		t.Fatalf("load templates: %v", err)
+	}
+	notifier, err := service.NewSMTPEmailNotifier(cfg, templates)
+	if err != nil {
+		t.Fatalf("new smtp notifier: %v", err)
+	}
+	return notifier
+}
+
+// readSMTPMessage returns the decoded subject and the text part of a mail
+// captured by the stand-in.
+func readSMTPMessage(t *testing.T, raw string) (*mail.Message, string, string) {
+	t.Helper()
+	msg, err := mail.ReadMessage(strings.NewReader(raw))
+	if err != nil {
+		t.Fatalf("parse message: %v", err)
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	if err != nil {
+		t.Fatalf("decode subject: %v", err)
+	}
+	_, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil {
+		t.Fatalf("parse content type: %v", err)
+	}
+	part, err := multipart.NewReader(msg.Body, params["boundary"]).NextPart()
+	if err != nil {
+		t.Fatalf("read text part: %v", err)
+	}
+	text, _ := io.ReadAll(part)
+	return msg, subject, string(text)
+}
+
+func TestSMTPNotifierDeliversPasswordResetMail(t *testing.T) {
+	stub := newSMTPStandIn(t, "mailer", "mailer-secret")
+	notifier := newSMTPTestNotifier(t, newSMTPTestConfig(stub.Port()))
+	baseURL, client, closeFn := newAuthTestServerWithOptions(t, authTestServerOptions{
+		verifyNotifier: notifier,
+		resetNotifier:  notifier,
+	})
+	defer closeFn()
+
+	registerAndLogin(t, client, baseURL, "smtp-reset@example.com", "Valid#Pass1234")
+	resp, env := doJSON(t, client, http.MethodPost, baseURL+"/api/v1/auth/local/password/forgot", map[string]string{
+		"email": "smtp-reset@example.com",
+	}, nil)
+	if resp.StatusCode != http.StatusOK || !env.Success {
+		t.Fatalf("forgot failed: status=%d err=%#v", resp.StatusCode, env.Error)
+	}
+
+	messages := stub.Messages()
+	if len(messages) != 1 {
+		t.Fatalf("expected one delivered mail, got %d", len(messages))
+	}
+	if messages[0].From != "no-reply@example.com" || len(messages[0].To) != 1 || messages[0].To[0] != "smtp-reset@example.com" {
+		t.Fatalf("unexpected envelope: %+v", messages[0])
+	}
+	msg, subject, text := readSMTPMessage(t, messages[0].Data)
+	if subject != "Reset your password" || msg.Header.Get("To") != "<smtp-reset@example.com>" {
+		t.Fatalf("unexpected headers: subject=%q to=%q", subject, msg.Header.Get("To"))
+	}
+	var resetURL *url.URL
+	for _, field := range strings.Fields(text) {
+		if strings.HasPrefix(field, "http://localhost:3000/reset-password") {
+			resetURL, _ = url.Parse(field)
+		}
+	}
+	if resetURL == nil || resetURL.Query().Get("token") == "" {
+		t.Fatalf("expected reset link in the text part, got %q", text)
+	}
+
+	resp, env = doJSON(t, client, http.MethodPost, baseURL+"/api/v1/auth/local/password/reset", map[string]string{
+		"token":        resetURL.Query().Get("token"),
+		"new_password": "New#ValidPass1234",
+	}, nil)
+	if resp.StatusCode != http.StatusOK || !env.Success {
+		t.Fatalf("reset with mailed token failed: status=%d err=%#v", resp.StatusCode, env.Error)
+	}
+}
+
+func TestSMTPNotifierUsesLocaleTemplateOverrides(t *testing.T) {
+	stub := newSMTPStandIn(t, "mailer", "mailer-secret")
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "de"), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "de", "email_verification.subject.tmpl"), []byte("Bestätigen Sie Ihre E-Mail-Adresse"), 0o600); err != nil {
+		t.Fatalf("write template: %v", err)
+	}
+	cfg := newSMTPTestConfig(stub.Port())
+	cfg.EmailTemplateDir = dir
+	cfg.EmailDefaultLocale = "de"
+	notifier := newSMTPTestNotifier(t, cfg)
+
+	err := notifier.SendEmailVerification(context.Background(), service.VerificationNotification{
+		Email:           "locale@example.com",
+		Token:           "raw-token",
+		ExpiresAt:       time.Now().Add(time.Hour),
+		VerificationURL: "https://app.example/verify?token=raw-token",
+	})
+	if err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	err = notifier.SendEmailVerification(context.Background(), service.VerificationNotification{
+		Email:     "english@example.com",
+		Token:     "raw-token",
+		ExpiresAt: time.Now().Add(time.Hour),
+		Locale:    "en-GB",
+	})
+	if err != nil {
+		t.Fatalf("send en-GB: %v", err)
+	}
+
+	messages := stub.Messages()
+	if len(messages) != 2 {
+		t.Fatalf("expected two delivered mails, got %d", len(messages))
+	}
+	if _, subject, text := readSMTPMessage(t, messages[0].Data); subject != "Bestätigen Sie Ihre E-Mail-Adresse" || !strings.Contains(text, "https://app.example/verify?token=raw-token") {
+		t.Fatalf("expected german subject override with default body, got subject=%q text=%q", subject, text)
+	}
+	if _, subject, text := readSMTPMessage(t, messages[1].Data); subject != "Verify your email address" || !strings.Contains(text, "raw-token") {
+		t.Fatalf("expected english defaults for en-GB, got subject=%q text=%q", subject, text)
+	}
+}
+
+func TestSMTPNotifierFailures(t *testing.T) {
+	stub := newSMTPStandIn(t, "mailer", "mailer-secret")
+	notification := service.PasswordResetNotification{Email: "fail@example.com", Token: "raw-token", ExpiresAt: time.Now().Add(time.Hour)}
+
+	cfg := newSMTPTestConfig(stub.Port())
+	cfg.SMTPTLSMode = "starttls"
+	if err := newSMTPTestNotifier(t, cfg).SendPasswordReset(context.Background(), notification); !errors.Is(err, service.ErrSMTPStartTLSUnsupported) {
+		t.Fatalf("expected starttls to be required, got %v", err)
+	}
+
+	cfg = newSMTPTestConfig(stub.Port())
+	cfg.SMTPPassword = "wrong"
+	if err := newSMTPTestNotifier(t, cfg).SendPasswordReset(context.Background(), notification); err == nil || !strings.Contains(err.Error(), "smtp auth") {
+		t.Fatalf("expected auth failure, got %v", err)
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer func() { _ = ln.Close() }()
+	cfg = newSMTPTestConfig(ln.Addr().(*net.TCPAddr).Port)
+	cfg.SMTPSendTimeout = 200 * time.Millisecond
+	start := time.Now()
+	if err := newSMTPTestNotifier(t, cfg).SendPasswordReset(context.Background(), notification); err == nil {
+		t.Fatal("expected a silent server to time out")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("expected send timeout to bound the exchange, took %s", elapsed)
+	}
+
+	if len(stub.Messages()) != 0 {
+		t.Fatalf("expected no mail to be delivered, got %d", len(stub.Messages()))
+	}
+}
+
+func TestNotificationOutboxSurvivesSMTPOutage(t *testing.T) {
+	baseURL, client, closeFn := newAuthTestServerWithOptions(t, authTestServerOptions{
+		cfgOverride: func(cfg *config.Config) { cfg.NotificationOutboxEnabled = true },
+	})
+	defer closeFn()
+
+	registerAndLogin(t, client, baseURL, "outbox-reset@example.com", "Valid#Pass1234")
+	resp, env := doJSON(t, client, http.MethodPost, baseURL+"/api/v1/auth/local/password/forgot", map[string]string{
+		"email": "outbox-reset@example.com",
+	}, nil)
+	if resp.StatusCode != http.StatusOK || !env.Success {
+		t.Fatalf("forgot failed: status=%d err=%#v", resp.StatusCode, env.Error)
+	}
+
+	// Same shared in-memory database as the server under test.
+	db, err := gorm.Open(sqlite.Open(fmt.Sprintf("file:%s?mode=memory&cache=shared", strings.ReplaceAll(t.Name(), "/", "_"))), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	outboxCfg := &config.Config{
+		NotificationOutboxEncryptionKey: "outbox-encryption-key-0123456789abcdef",
+		NotificationOutboxBatchSize:     10,
+		NotificationOutboxMaxAttempts:   3,
+		NotificationOutboxBaseBackoff:   time.Second,
+		NotificationOutboxMaxBackoff:    time.Minute,
+		NotificationOutboxLease:         time.Minute,
+	}
+
+	down, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	downPort := down.Addr().(*net.TCPAddr).Port
+	_ = down.Close()
+	outage := service.NewNotificationOutbox(outboxCfg, repository.NewOutboxRepository(db), newSMTPTestNotifier(t, newSMTPTestConfig(downPort)))
+	now := time.Now().UTC()
+	if sent, err := outage.DispatchDue(context.Background(), now); err != nil || sent != 0 {
+		t.Fatalf("expected delivery to fail while smtp is down, got sent=%d err=%v", sent, err)
+	}
+	var queued domain.OutboxMessage
+	if err := db.Where("kind = ?", service.OutboxKindPasswordReset).First(&queued).Error; err != nil {
+		t.Fatalf("load outbox message: %v", err)
+	}
+	if queued.Status != domain.OutboxStatusPending || queued.Attempts != 1 || !strings.Contains(queued.LastError, "smtp dial") {
+		t.Fatalf("expected message queued for retry, got %+v", queued)
+	}
+
+	stub := newSMTPStandIn(t, "mailer", "mailer-secret")
+	recovered := service.NewNotificationOutbox(outboxCfg, repository.NewOutboxRepository(db), newSMTPTestNotifier(t, newSMTPTestConfig(stub.Port())))
+	if sent, err := recovered.DispatchDue(context.Background(), queued.NextAttemptAt); err != nil || sent != 1 {
+		t.Fatalf("expected retry to deliver, got sent=%d err=%v", sent, err)
+	}
+	messages := stub.Messages()
+	if len(messages) != 1 || messages[0].To[0] != "outbox-reset@example.com" {
+		t.Fatalf("expected one delivered reset mail, got %+v", messages)
+	}
+	_, _, text := readSMTPMessage(t, messages[0].Data)
+	var resetURL *url.URL
+	for _, field := range strings.Fields(text) {
+		if strings.HasPrefix(field, "http://localhost:3000/reset-password") {
+			resetURL, _ = url.Parse(field)
+		}
+	}
+	if resetURL == nil {
+		t.Fatalf("expected reset link in the text part, got %q", text)
+	}
+	resp, env = doJSON(t, client, http.MethodPost, baseURL+"/api/v1/auth/local/password/reset", map[string]string{
+		"token":        resetURL.Query().Get("token"),
+		"new_password": "New#ValidPass1234",
+	}, nil)
+	if resp.StatusCode != http.StatusOK || !env.Success {
+		t.Fatalf("reset with queued token failed: status=%d err=%#v", resp.StatusCode, env.Error)
+	}
+}
@@ -0,0 +1,87 @@
+package integration
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/config"
+)
+
+func TestAdminSuspendAndReactivateUser(t *testing.T) {
+	baseURL, adminClient, closeFn := newAuthTestServerWithOptions(t, authTestServerOptions{
+		cfgOverride: func(cfg *config.Config) {
+			cfg.BootstrapAdminEmail = "admin-status@example.com"
+		},
+	})
+	defer closeFn()
+
+	registerAndLogin(t, adminClient, baseURL, "admin-status@example.com", "Valid#Pass1234")
+	userClient := newSessionClient(t)
+	registerAndLogin(t, userClient, baseURL, "suspend-me@example.com", "Valid#Pass1234")
+	access := cookieValue(t, userClient, baseURL, "access_token")
+
+	resp, env := doJSON(t, userClient, http.MethodGet, baseURL+"/api/v1/me", nil, nil)
+	if resp.StatusCode != http.StatusOK || !env.Success {
+		t.Fatalf("me failed: status=%d err=%#v", resp.StatusCode, env.Error)
+	}
+	var me struct {
+		ID uint `json:"id"`
+	}
+	if err := json.Unmarshal(env.Data, &me); err != nil {
+		t.Fatalf("decode me: %v", err)
+	}
+	userPath := baseURL + "/api/v1/admin/users/" + itoa(me.ID)
+	adminCSRF := map[string]string{"X-CSRF-Token": cookieValue(t, adminClient, baseURL, "csrf_token")}
+
+	resp, env = doJSON(t, adminClient, http.MethodPost, userPath+"/suspend", map[string]string{}, adminCSRF)
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected suspend without reason to fail, got %d", resp.StatusCode)
+	}
+	resp, env = doJSON(t, adminClient, http.MethodPost, userPath+"/suspend", map[string]string{"reason": "fraud review"}, adminCSRF)
+	if resp.StatusCode != http.StatusOK || !env.Success {
+		t.Fatalf("suspend failed: status=%d err=%#v", resp.StatusCode, env.Error)
+	}
+
+	resp, _ = doJSON(t, &http.Client{}, http.MethodGet, baseURL+"/api/v1/me", nil, map[string]string{
+		"Authorization": "Bearer " + access,
+	})
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected access token to be revoked on suspend, got %d", resp.StatusCode)
+	}
+	resp, env = doJSON(t, newSessionClient(t), http.MethodPost, baseURL+"/api/v1/auth/local/login", map[string]string{
+		"email":    "suspend-me@example.com",
+		"password": "Valid#Pass1234",
+	}, nil)
+	if resp.StatusCode != http.StatusForbidden || env.Error == nil || env.Error.Code != "ACCOUNT_INACTIVE" {
+		t.Fatalf("expected suspended login to be forbidden, got status=%d err=%#v", resp.StatusCode, env.Error)
+	}
+
+	resp, env = doJSON(t, adminClient, http.MethodGet, baseURL+"/api/v1/admin/users?status=suspended", nil, nil)
+	if resp.StatusCode != http.StatusOK || !env.Success {
+		t.Fatalf("list suspended users failed: status=%d err=%#v", resp.StatusCode, env.Error)
+	}
+	var suspended usersPageData
+	if err := json.Unmarshal(env.Data, &suspended); err != nil {
+		t.Fatalf("decode suspended users: %v", err)
+	}
+	if len(suspended.Items) != 1 || suspended.Items[0].ID != me.ID {
+		t.Fatalf("expected only the suspended user, got %+v", suspended.Items)
+	}
+	resp, _ = doJSON(t, adminClient, http.MethodGet, baseURL+"/api/v1/admin/users?status=banned", nil, nil)
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected unknown status filter to fail, got %d", resp.StatusCode)
+	}
+
+	resp, env = doJSON(t, adminClient, http.MethodPost, userPath+"/reactivate", map[string]string{"reason": "review cleared"}, adminCSRF)
+	if resp.StatusCode != http.StatusOK || !env.Success {
+		t.Fatalf("reactivate failed: status=%d err=%#v", resp.StatusCode, env.Error)
+	}
+	resp, env = doJSON(t, newSessionClient(t), http.MethodPost, baseURL+"/api/v1/auth/local/login", map[string]string{
+		"email":    "suspend-me@example.com",
+		"password": "Valid#Pass1234",
+	}, nil)
+	if resp.StatusCode != http.StatusOK || !env.Success {
+		t.Fatalf("expected login after reactivation, got status=%d err=%#v", resp.StatusCode, env.Error)
+	}
+}
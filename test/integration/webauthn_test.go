@@ -0,0 +1,254 @@
+package integration
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/cookiejar"
+	"testing"
+	"time"
+
+	"github.com/go-webauthn/webauthn/protocol/webauthncbor"
+
+	"github.com/sandeepkv93/everything-backend-starter-kit/internal/config"
+)
+
+const (
+	webauthnTestRPID   = "localhost"
+	webauthnTestOrigin = "http://localhost:3000"
+)
+
+func TestWebAuthnPasskeyRegistrationLoginAndDeletion(t *testing.T) {
+	baseURL, client, closeFn := newAuthTestServerWithOptions(t, authTestServerOptions{
+		cfgOverride: func(cfg *config.Config) {
+			cfg.AuthWebAuthnEnabled = true
+			cfg.AuthWebAuthnRPID = webauthnTestRPID
+			cfg.AuthWebAuthnRPDisplayName = "Starter Kit"
+			cfg.AuthWebAuthnRPOrigins = []string{webauthnTestOrigin}
+			cfg.AuthWebAuthnChallengeTTL = 5 * time.Minute
+		},
+	})
+	defer closeFn()
+
+	registerAndLogin(t, client, baseURL, "passkey@example.com", "Valid#Pass1234")
+	csrf := map[string]string{"X-CSRF-Token": cookieValue(t, client, baseURL, "csrf_token")}
+	authn := newSoftAuthenticator(t)
+
+	resp, env := doJSON(t, client, http.MethodPost, baseURL+"/api/v1/auth/webauthn/register/begin", nil, csrf)
+	if resp.StatusCode != http.StatusOK || !env.Success {
+		t.Fatalf("register begin failed: status=%d err=%#v", resp.StatusCode, env.Error)
+	}
+	var creation webauthnCeremonyResponse
+	if err := json.Unmarshal(env.Data, &creation); err != nil || creation.ChallengeID == "" {
+		t.Fatalf("decode creation options: %v %+v", err, creation)
+	}
+	attestation := authn.create(t, creation.Options.PublicKey.Challenge, creation.Options.PublicKey.User.ID, webauthnTestOrigin)
+
+	resp, env = doJSON(t, client, http.MethodPost, baseURL+"/api/v1/auth/webauthn/register/finish", map[string]any{
+		"challenge_id": creation.ChallengeID,
+		"name":         "Test key",
+		"credential":   attestation,
+	}, csrf)
+	if resp.StatusCode != http.StatusCreated || !env.Success {
+		t.Fatalf("register finish failed: status=%d err=%#v", resp.StatusCode, env.Error)
+	}
+	resp, env = doJSON(t, client, http.MethodPost, baseURL+"/api/v1/auth/webauthn/register/finish", map[string]any{
+		"challenge_id": creation.ChallengeID,
+		"credential":   attestation,
+	}, csrf)
+	if resp.StatusCode != http.StatusBadRequest || env.Error == nil || env.Error.Code != "INVALID_OR_EXPIRED_TOKEN" {
+		t.Fatalf("expected replayed registration challenge to fail, got status=%d err=%#v", resp.StatusCode, env.Error)
+	}
+
+	resp, env = doJSON(t, client, http.MethodGet, baseURL+"/api/v1/me/webauthn/credentials", nil, nil)
+	if resp.StatusCode != http.StatusOK || !env.Success {
+		t.Fatalf("list credentials failed: status=%d err=%#v", resp.StatusCode, env.Error)
+	}
+	var creds []struct {
+		ID   uint   `json:"id"`
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal(env.Data, &creds); err != nil || len(creds) != 1 || creds[0].Name != "Test key" {
+		t.Fatalf("expected one registered passkey, got %+v err=%v", creds, err)
+	}
+
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		t.Fatalf("cookie jar: %v", err)
+	}
+	fresh := &http.Client{Jar: jar}
+	login := func(origin string) (*http.Response, apiEnvelope) {
+		resp, env := doJSON(t, fresh, http.MethodPost, baseURL+"/api/v1/auth/webauthn/login/begin", nil, nil)
+		if resp.StatusCode != http.StatusOK || !env.Success {
+			t.Fatalf("login begin failed: status=%d err=%#v", resp.StatusCode, env.Error)
+		}
+		var assertion webauthnCeremonyResponse
+		if err := json.Unmarshal(env.Data, &assertion); err != nil || assertion.ChallengeID == "" {
+			t.Fatalf("decode assertion options: %v %+v", err, assertion)
+		}
+		return doJSON(t, fresh, http.MethodPost, baseURL+"/api/v1/auth/webauthn/login/finish", map[string]any{
+			"challenge_id": assertion.ChallengeID,
+			"credential":   authn.get(t, assertion.Options.PublicKey.Challenge, origin),
+		}, nil)
+	}
+
+	resp, env = login("http://evil.example.com")
+	if resp.StatusCode != http.StatusUnauthorized || env.Error == nil {
+		t.Fatalf("expected assertion from foreign origin to fail, got status=%d err=%#v", resp.StatusCode, env.Error)
+	}
+	resp, env = login(webauthnTestOrigin)
+	if resp.StatusCode != http.StatusOK || !env.Success {
+		t.Fatalf("passkey login failed: status=%d err=%#v", resp.StatusCode, env.Error)
+	}
+	resp, env = doJSON(t, fresh, http.MethodGet, baseURL+"/api/v1/me", nil, nil)
+	if resp.StatusCode != http.StatusOK || !env.Success {
+		t.Fatalf("expected passkey session to authenticate /me, got status=%d", resp.StatusCode)
+	}
+
+	path := fmt.Sprintf("%s/api/v1/me/webauthn/credentials/%d", baseURL, creds[0].ID)
+	resp, env = doJSON(t, client, http.MethodDelete, path, nil, csrf)
+	if resp.StatusCode != http.StatusOK || !env.Success {
+		t.Fatalf("delete credential failed: status=%d err=%#v", resp.StatusCode, env.Error)
+	}
+	resp, env = doJSON(t, client, http.MethodDelete, path, nil, csrf)
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected second delete to 404, got status=%d err=%#v", resp.StatusCode, env.Error)
+	}
+	resp, _ = login(webauthnTestOrigin)
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected login with deleted passkey to fail, got status=%d", resp.StatusCode)
+	}
+}
+
+func TestWebAuthnRoutesAbsentWhenDisabled(t *testing.T) {
+	baseURL, client, closeFn := newAuthTestServer(t)
+	defer closeFn()
+
+	resp, _ := doJSON(t, client, http.MethodPost, baseURL+"/api/v1/auth/webauthn/login/begin", nil, nil)
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected webauthn routes to be unregistered, got %d", resp.StatusCode)
+	}
+}
+
+type webauthnCeremonyResponse struct {
+	ChallengeID string `json:"challenge_id"`
+	Options     struct {
+		PublicKey struct {
+			Challenge string `json:"challenge"`
+			User      struct {
+				ID string `json:"id"`
+			} `json:"user"`
+		} `json:"publicKey"`
+	} `json:"options"`
+}
+
+// softAuthenticator is a minimal platform authenticator: one ES256 resident
+// credential, "none" attestation, and user verification always asserted.
+type softAuthenticator struct {
+	key          *ecdsa.PrivateKey
+	credentialID []byte
+	userHandle   []byte
+	signCount    uint32
+}
+
+func newSoftAuthenticator(t *testing.T) *softAuthenticator {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	id := make([]byte, 32)
+	if _, err := rand.Read(id); err != nil {
+		t.Fatalf("credential id: %v", err)
+	}
+	return &softAuthenticator{key: key, credentialID: id}
+}
+
+func (a *softAuthenticator) create(t *testing.T, challenge, userHandle, origin string) map[string]any {
+	t.Helper()
+	handle, err := base64.RawURLEncoding.DecodeString(userHandle)
+	if err != nil {
+		t.Fatalf("decode user handle: %v", err)
+	}
+	a.userHandle = handle
+	clientData := a.clientData(t, "webauthn.create", challenge, origin)
+
+	pub, err := a.key.PublicKey.ECDH()
+	if err != nil {
+		t.Fatalf("public key: %v", err)
+	}
+	raw := pub.Bytes()
+	coseKey, err := webauthncbor.Marshal(map[int]any{1: 2, 3: -7, -1: 1, -2: raw[1:33], -3: raw[33:65]})
+	if err != nil {
+		t.Fatalf("encode cose key: %v", err)
+	}
+	authData := a.authData(0x45)
+	authData = append(authData, make([]byte, 16)...)
+	authData = binary.BigEndian.AppendUint16(authData, uint16(len(a.credentialID)))
+	authData = append(authData, a.credentialID...)
+	authData = append(authData, coseKey...)
+	attObj, err := webauthncbor.Marshal(map[string]any{"fmt": "none", "attStmt": map[string]any{}, "authData": authData})
+	if err != nil {
+		t.Fatalf("encode attestation object: %v", err)
+	}
+	return map[string]any{
+		"id":    b64url(a.credentialID),
+		"rawId": b64url(a.credentialID),
+		"type":  "public-key",
+		"response": map[string]any{
+			"clientDataJSON":    b64url(clientData),
+			"attestationObject": b64url(attObj),
+			"transports":        []string{"internal"},
+		},
+	}
+}
+
+func (a *softAuthenticator) get(t *testing.T, challenge, origin string) map[string]any {
+	t.Helper()
+	clientData := a.clientData(t, "webauthn.get", challenge, origin)
+	authData := a.authData(0x05)
+	clientHash := sha256.Sum256(clientData)
+	digest := sha256.Sum256(append(append([]byte{}, authData...), clientHash[:]...))
+	sig, err := ecdsa.SignASN1(rand.Reader, a.key, digest[:])
+	if err != nil {
+		t.Fatalf("sign assertion: %v", err)
+	}
+	return map[string]any{
+		"id":    b64url(a.credentialID),
+		"rawId": b64url(a.credentialID),
+		"type":  "public-key",
+		"response": map[string]any{
+			"clientDataJSON":    b64url(clientData),
+			"authenticatorData": b64url(authData),
+			"signature":         b64url(sig),
+			"userHandle":        b64url(a.userHandle),
+		},
+	}
+}
+
+func (a *softAuthenticator) clientData(t *testing.T, typ, challenge, origin string) []byte {
+	t.Helper()
+	raw, err := json.Marshal(map[string]any{"type": typ, "challenge": challenge, "origin": origin, "crossOrigin": false})
+	if err != nil {
+		t.Fatalf("encode client data: %v", err)
+	}
+	return raw
+}
+
+func (a *softAuthenticator) authData(flags byte) []byte {
+	rpIDHash := sha256.Sum256([]byte(webauthnTestRPID))
+	a.signCount++
+	out := append([]byte{}, rpIDHash[:]...)
+	out = append(out, flags)
+	return binary.BigEndian.AppendUint32(out, a.signCount)
+}
+
+func b64url(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}